@@ -25,6 +25,7 @@ GO_TEST_BINS := $(GO_TEST_BINS) \
 	private/bufpkg/bufcheck/internal/cmd/buf-plugin-duplicate-category \
 	private/bufpkg/bufcheck/internal/cmd/buf-plugin-duplicate-rule
 GO_TEST_WASM_BINS := $(GO_TEST_WASM_BINS) \
+	private/buf/cmd/buf/command/generate/internal/protoc-gen-top-level-type-names-yaml \
 	private/bufpkg/bufcheck/internal/cmd/buf-plugin-suffix
 GO_MOD_VERSION := 1.22
 DOCKER_BINS := $(DOCKER_BINS) buf
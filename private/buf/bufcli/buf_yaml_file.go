@@ -19,7 +19,9 @@ import (
 	"errors"
 	"io/fs"
 
+	"github.com/bufbuild/buf/private/buf/bufworkspace"
 	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
+	"github.com/bufbuild/buf/private/pkg/app/appext"
 	"github.com/bufbuild/buf/private/pkg/storage"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 )
@@ -38,6 +40,34 @@ func GetBufYAMLFileForDirPathOrOverride(
 	return bufconfig.GetBufYAMLFileForPrefixOrOverride(ctx, bucket, ".", override)
 }
 
+// GetResolvedBufYAMLFileForDirPathOrOverride gets the buf.yaml file for either the usually-flag-based
+// override, or if the override is not set, the directory path, with its extends resolved.
+//
+// Local extended files are read relative to the directory path.
+func GetResolvedBufYAMLFileForDirPathOrOverride(
+	ctx context.Context,
+	container appext.Container,
+	dirPath string,
+	override string,
+) (bufconfig.BufYAMLFile, error) {
+	bucket, err := newOSReadWriteBucketWithSymlinks(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	bufYAMLFile, err := bufconfig.GetBufYAMLFileForPrefixOrOverride(ctx, bucket, ".", override)
+	if err != nil {
+		return nil, err
+	}
+	if len(bufYAMLFile.Extends()) == 0 {
+		return bufYAMLFile, nil
+	}
+	moduleDataProvider, err := NewModuleDataProvider(container)
+	if err != nil {
+		return nil, err
+	}
+	return bufworkspace.ResolveBufYAMLFile(ctx, bufYAMLFile, bucket, moduleDataProvider)
+}
+
 // GetBufYAMLFileForDirPath gets the buf.yaml file for the directory path.
 func GetBufYAMLFileForDirPath(
 	ctx context.Context,
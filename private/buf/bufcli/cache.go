@@ -25,6 +25,7 @@ import (
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleapi"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulecache"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulesign"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulestore"
 	"github.com/bufbuild/buf/private/bufpkg/bufplugin"
 	"github.com/bufbuild/buf/private/bufpkg/bufplugin/bufpluginapi"
@@ -33,9 +34,11 @@ import (
 	"github.com/bufbuild/buf/private/bufpkg/bufregistryapi/bufregistryapimodule"
 	"github.com/bufbuild/buf/private/bufpkg/bufregistryapi/bufregistryapiowner"
 	"github.com/bufbuild/buf/private/bufpkg/bufregistryapi/bufregistryapiplugin"
+	"github.com/bufbuild/buf/private/bufpkg/bufremotecache"
 	"github.com/bufbuild/buf/private/pkg/app/appext"
 	"github.com/bufbuild/buf/private/pkg/filelock"
 	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/storage"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 )
 
@@ -120,6 +123,14 @@ var (
 	//
 	// Normalized.
 	v3CacheWasmRuntimeRelDirPath = normalpath.Join("v3", "wasmruntime")
+	// v3CacheSignaturesRelDirPath is the relative path to the directory that signatures of
+	// modules are stored in.
+	//
+	// Signatures cannot be fetched again once deleted, so this directory is not part of
+	// AllCacheRelDirPaths and is not cleared with the cache.
+	//
+	// Normalized.
+	v3CacheSignaturesRelDirPath = normalpath.Join("v3", "signatures")
 )
 
 // NewModuleDataProvider returns a new ModuleDataProvider while creating the
@@ -204,26 +215,65 @@ func NewWKTStore(container appext.Container) (bufwktstore.Store, error) {
 	), nil
 }
 
+// NewSignatureStore returns a new bufmodulesign.SignatureStore while creating the required
+// directories.
+//
+// Signatures are stored in the cache directory, unless $BUF_SIGNATURE_DIR is set. If
+// $BUF_REMOTE_CACHE_URL is set, signatures are also read from and written to the remote
+// cache. The BSR does not store signatures, so these are the only ways to share them.
+//
+// Signatures are verified against the trusted public keys when read, so they may be read
+// from a remote cache that is not trusted.
+func NewSignatureStore(container appext.Container) (bufmodulesign.SignatureStore, error) {
+	var bucket storage.ReadWriteBucket
+	if fullDirPath := container.Env(signatureDirEnvKey); fullDirPath != "" {
+		if err := os.MkdirAll(fullDirPath, 0755); err != nil {
+			return nil, err
+		}
+		// No symlinks.
+		storageosProvider := storageos.NewProvider()
+		localBucket, err := storageosProvider.NewReadWriteBucket(fullDirPath)
+		if err != nil {
+			return nil, err
+		}
+		bucket, _, err = withRemoteCacheBucket(container, localBucket, v3CacheSignaturesRelDirPath)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		bucket, _, err = newCacheBucket(container, v3CacheSignaturesRelDirPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return bufmodulesign.NewSignatureStore(
+		container.Logger(),
+		bucket,
+	), nil
+}
+
 func newModuleDataProvider(
 	container appext.Container,
 	moduleClientProvider bufregistryapimodule.ClientProvider,
 	ownerClientProvider bufregistryapiowner.ClientProvider,
 ) (bufmodule.ModuleDataProvider, error) {
-	if err := createCacheDir(container.CacheDirPath(), v3CacheModuleRelDirPath); err != nil {
-		return nil, err
-	}
-	fullCacheDirPath := normalpath.Join(container.CacheDirPath(), v3CacheModuleRelDirPath)
 	delegateModuleDataProvider := bufmoduleapi.NewModuleDataProvider(
 		container.Logger(),
 		moduleClientProvider,
 		newGraphProvider(container, moduleClientProvider, ownerClientProvider),
 	)
-	// No symlinks.
-	storageosProvider := storageos.NewProvider()
-	cacheBucket, err := storageosProvider.NewReadWriteBucket(fullCacheDirPath)
+	cacheBucket, isRemote, err := newCacheBucket(container, v3CacheModuleRelDirPath)
 	if err != nil {
 		return nil, err
 	}
+	var moduleDataStoreOptions []bufmodulestore.ModuleDataStoreOption
+	if isRemote {
+		// The remote cache cannot be listed, so module data is stored as a single tar file per
+		// module. The remote cache may be written to by anyone, so the tar files are keyed by
+		// the digest of the module, and are only read if the module data matches this digest.
+		moduleDataStoreOptions = append(moduleDataStoreOptions, bufmodulestore.ModuleDataStoreWithDigestPaths())
+	}
 	if err := createCacheDir(container.CacheDirPath(), v3CacheModuleLockRelDirPath); err != nil {
 		return nil, err
 	}
@@ -238,6 +288,7 @@ func newModuleDataProvider(
 			container.Logger(),
 			cacheBucket,
 			filelocker,
+			moduleDataStoreOptions...,
 		),
 	), nil
 }
@@ -247,14 +298,11 @@ func newCommitProvider(
 	moduleClientProvider bufregistryapimodule.ClientProvider,
 	ownerClientProvider bufregistryapiowner.ClientProvider,
 ) (bufmodule.CommitProvider, error) {
-	if err := createCacheDir(container.CacheDirPath(), v3CacheCommitsRelDirPath); err != nil {
-		return nil, err
-	}
-	fullCacheDirPath := normalpath.Join(container.CacheDirPath(), v3CacheCommitsRelDirPath)
 	delegateReader := bufmoduleapi.NewCommitProvider(container.Logger(), moduleClientProvider, ownerClientProvider)
-	// No symlinks.
-	storageosProvider := storageos.NewProvider()
-	cacheBucket, err := storageosProvider.NewReadWriteBucket(fullCacheDirPath)
+	// Commits are not shared through the remote cache. Commits map commit IDs to digests,
+	// and these digests are used to verify module data, so they cannot be read from a remote
+	// cache that may be written to by anyone.
+	cacheBucket, err := newLocalCacheBucket(container, v3CacheCommitsRelDirPath)
 	if err != nil {
 		return nil, err
 	}
@@ -272,15 +320,16 @@ func newPluginDataProvider(
 	container appext.Container,
 	pluginClientProvider bufregistryapiplugin.ClientProvider,
 ) (bufplugin.PluginDataProvider, error) {
-	if err := createCacheDir(container.CacheDirPath(), v3CachePluginRelDirPath); err != nil {
-		return nil, err
-	}
-	fullCacheDirPath := normalpath.Join(container.CacheDirPath(), v3CachePluginRelDirPath)
-	storageosProvider := storageos.NewProvider() // No symlinks.
-	cacheBucket, err := storageosProvider.NewReadWriteBucket(fullCacheDirPath)
+	cacheBucket, isRemote, err := newCacheBucket(container, v3CachePluginRelDirPath)
 	if err != nil {
 		return nil, err
 	}
+	var pluginDataStoreOptions []bufpluginstore.PluginDataStoreOption
+	if isRemote {
+		// The remote cache may be written to by anyone, so plugin data is keyed by the digest
+		// of the plugin, and is only read if the plugin data matches this digest.
+		pluginDataStoreOptions = append(pluginDataStoreOptions, bufpluginstore.PluginDataStoreWithDigestPaths())
+	}
 	delegateModuleDataProvider := bufpluginapi.NewPluingDataProvider(
 		container.Logger(),
 		pluginClientProvider,
@@ -291,10 +340,63 @@ func newPluginDataProvider(
 		bufpluginstore.NewPluginDataStore(
 			container.Logger(),
 			cacheBucket,
+			pluginDataStoreOptions...,
 		),
 	), nil
 }
 
+// newCacheBucket returns a bucket for the cache directory at the path relative to
+// container.CacheDirPath() while creating the directory.
+//
+// If $BUF_REMOTE_CACHE_URL is set, the bucket also reads from and writes to the remote
+// cache at this URL, and isRemote is true.
+func newCacheBucket(container appext.Container, relDirPath string) (_ storage.ReadWriteBucket, isRemote bool, _ error) {
+	cacheBucket, err := newLocalCacheBucket(container, relDirPath)
+	if err != nil {
+		return nil, false, err
+	}
+	return withRemoteCacheBucket(container, cacheBucket, relDirPath)
+}
+
+// newLocalCacheBucket returns a bucket for the cache directory at the path relative to
+// container.CacheDirPath() while creating the directory.
+//
+// The bucket never reads from or writes to the remote cache.
+func newLocalCacheBucket(container appext.Container, relDirPath string) (storage.ReadWriteBucket, error) {
+	if err := createCacheDir(container.CacheDirPath(), relDirPath); err != nil {
+		return nil, err
+	}
+	fullCacheDirPath := normalpath.Join(container.CacheDirPath(), relDirPath)
+	// No symlinks.
+	storageosProvider := storageos.NewProvider()
+	return storageosProvider.NewReadWriteBucket(fullCacheDirPath)
+}
+
+// withRemoteCacheBucket returns a bucket that caches the remote cache in the local bucket
+// if $BUF_REMOTE_CACHE_URL is set, and isRemote is true. Otherwise, returns the local bucket.
+//
+// The relDirPath determines the paths of the objects on the remote cache.
+func withRemoteCacheBucket(
+	container appext.Container,
+	localBucket storage.ReadWriteBucket,
+	relDirPath string,
+) (_ storage.ReadWriteBucket, isRemote bool, _ error) {
+	remoteCacheURL := container.Env(remoteCacheURLEnvKey)
+	if remoteCacheURL == "" {
+		return localBucket, false, nil
+	}
+	remoteBucket, err := bufremotecache.NewHTTPBucket(defaultHTTPClient, remoteCacheURL)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid $%s: %w", remoteCacheURLEnvKey, err)
+	}
+	return bufremotecache.NewCacheBucket(
+		container.Logger(),
+		localBucket,
+		// Each cache directory has its own paths on the remote cache.
+		storage.MapReadWriteBucket(remoteBucket, storage.MapOnPrefix(relDirPath)),
+	), true, nil
+}
+
 func createCacheDir(baseCacheDirPath string, relDirPath string) error {
 	baseCacheDirPath = normalpath.Unnormalize(baseCacheDirPath)
 	relDirPath = normalpath.Unnormalize(relDirPath)
@@ -16,6 +16,7 @@ package bufcli
 
 import (
 	"github.com/bufbuild/buf/private/buf/bufctl"
+	"github.com/bufbuild/buf/private/buf/bufcurl"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduleapi"
 	"github.com/bufbuild/buf/private/bufpkg/bufplugin/bufpluginapi"
 	"github.com/bufbuild/buf/private/bufpkg/bufregistryapi/bufregistryapimodule"
@@ -58,6 +59,19 @@ func NewController(
 	if err != nil {
 		return nil, err
 	}
+	signatureStore, err := NewSignatureStore(container)
+	if err != nil {
+		return nil, err
+	}
+	options = append(
+		options,
+		bufctl.WithSignatureStore(signatureStore),
+		bufctl.WithReflectClientSettings(
+			bufcurl.ReflectClientSettings{
+				BufVersion: Version,
+			},
+		),
+	)
 	return bufctl.NewController(
 		container.Logger(),
 		container,
@@ -38,6 +38,20 @@ const (
 	// at a per-file level.
 	copyToInMemoryEnvKey = "BUF_BETA_COPY_FILES_TO_MEMORY"
 
+	// The directory that signatures of modules are read from and written to. The BSR does
+	// not store signatures, so this allows signatures to be shared, for example by checking
+	// them into a repository. Signatures are also shared through $BUF_REMOTE_CACHE_URL.
+	signatureDirEnvKey = "BUF_SIGNATURE_DIR"
+
+	// The base URL of an HTTP cache server that the module and plugin caches are shared
+	// through, for example between ephemeral CI runners.
+	//
+	// Module data is keyed by the digest of the module and plugin data by the digest of the
+	// plugin, and both are only read if they match this digest, which comes from buf.lock or
+	// the BSR. Commits map commit IDs to digests, so the commits cache is not shared.
+	// Signatures are shared, and are verified against the trusted public keys when read.
+	remoteCacheURLEnvKey = "BUF_REMOTE_CACHE_URL"
+
 	// This should only be used for testing. This is not part of Buf's API, and should
 	// never be documented or part of Buf's contract.
 	legacyFederationRegistryEnvKey = "BUF_TESTING_LEGACY_FEDERATION_REGISTRY"
@@ -20,7 +20,9 @@ import (
 
 	modulev1 "buf.build/gen/go/bufbuild/registry/protocolbuffers/go/buf/registry/module/v1"
 	pluginv1beta1 "buf.build/gen/go/bufbuild/registry/protocolbuffers/go/buf/registry/plugin/v1beta1"
+	"github.com/bufbuild/buf/private/buf/bufctl"
 	"github.com/bufbuild/buf/private/buf/buffetch"
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/pkg/app"
 	"github.com/bufbuild/buf/private/pkg/app/appcmd"
 	"github.com/bufbuild/buf/private/pkg/stringutil"
@@ -132,6 +134,30 @@ If specified multiple times, the union is taken`,
 	)
 }
 
+// BindExcludeTypes binds the exclude-type flag.
+func BindExcludeTypes(flagSet *pflag.FlagSet, addr *[]string, flagName string) {
+	flagSet.StringSliceVar(
+		addr,
+		flagName,
+		nil,
+		`The types (package, message, enum, extension, service, method, field, enum value) that should be excluded.
+Fields, extensions and methods that refer to excluded types are removed as well, and imports that are no longer needed are dropped.
+If specified multiple times, the union is taken`,
+	)
+}
+
+// BindExcludeOptions binds the exclude-option flag.
+func BindExcludeOptions(flagSet *pflag.FlagSet, addr *[]string, flagName string) {
+	flagSet.StringArrayVar(
+		addr,
+		flagName,
+		nil,
+		`Exclude all elements with the given custom option value, e.g. "(acme.visibility) = INTERNAL".
+If no value is given, as in "(acme.internal)", elements with the option set (and true, for bool options) are excluded.
+If specified multiple times, elements matching any of the options are excluded`,
+	)
+}
+
 // BindDisableSymlinks binds the disable-symlinks flag.
 func BindDisableSymlinks(flagSet *pflag.FlagSet, addr *bool, flagName string) {
 	flagSet.BoolVar(
@@ -179,6 +205,29 @@ func BindArchiveStatus(flagSet *pflag.FlagSet, addr *string, flagName string) {
 	)
 }
 
+// BindFailOn binds the fail-on flag.
+func BindFailOn(flagSet *pflag.FlagSet, addr *string, flagName string) {
+	flagSet.StringVar(
+		addr,
+		flagName,
+		bufanalysis.SeverityError.String(),
+		fmt.Sprintf(
+			"The minimum severity of check violations that results in a non-zero exit code. Must be one of %s",
+			stringutil.SliceToString(bufanalysis.AllSeverityStrings),
+		),
+	)
+}
+
+// BindMaxWarnings binds the max-warnings flag.
+func BindMaxWarnings(flagSet *pflag.FlagSet, addr *int, flagName string) {
+	flagSet.IntVar(
+		addr,
+		flagName,
+		-1,
+		`The maximum number of warnings before a non-zero exit code is returned. A negative value allows any number of warnings`,
+	)
+}
+
 // Binds a string pointer flag, which indicates flag presence, i.e. `--flag ""` is not the same as not passing the flag.
 //
 // This is useful for buf registry organization/module update, where we only modify the fields specified.
@@ -360,6 +409,47 @@ func ValidateErrorFormatFlagLint(errorFormatString string, errorFormatFlagName s
 	return validateErrorFormatFlag(AllLintFormatStrings, errorFormatString, errorFormatFlagName)
 }
 
+// ParseFailOnFlag parses the fail-on flag.
+func ParseFailOnFlag(failOn string, failOnFlagName string) (bufanalysis.Severity, error) {
+	severity, err := bufanalysis.ParseSeverity(failOn)
+	if err != nil {
+		return 0, appcmd.NewInvalidArgumentErrorf("--%s: %v", failOnFlagName, err)
+	}
+	return severity, nil
+}
+
+// GetFileAnnotationsError returns the error to return after the FileAnnotations have been printed.
+//
+// This returns an error with bufctl.ExitCodeFileAnnotation if any FileAnnotation is at least as
+// severe as failOn, or if maxWarnings is non-negative and there are more than maxWarnings warnings.
+// Otherwise, this returns nil.
+func GetFileAnnotationsError(
+	fileAnnotations []bufanalysis.FileAnnotation,
+	failOn bufanalysis.Severity,
+	maxWarnings int,
+	maxWarningsFlagName string,
+) error {
+	var numWarnings int
+	for _, fileAnnotation := range fileAnnotations {
+		if fileAnnotation.Severity() >= failOn {
+			return bufctl.ErrFileAnnotation
+		}
+		if fileAnnotation.Severity() == bufanalysis.SeverityWarning {
+			numWarnings++
+		}
+	}
+	if maxWarnings >= 0 && numWarnings > maxWarnings {
+		return app.NewErrorf(
+			bufctl.ExitCodeFileAnnotation,
+			"found %d warnings, which is more than --%s %d",
+			numWarnings,
+			maxWarningsFlagName,
+			maxWarnings,
+		)
+	}
+	return nil
+}
+
 func validateErrorFormatFlag(validFormatStrings []string, errorFormatString string, errorFormatFlagName string) error {
 	for _, formatString := range validFormatStrings {
 		if errorFormatString == formatString {
@@ -0,0 +1,55 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufcli
+
+import (
+	"context"
+
+	"github.com/bufbuild/buf/private/buf/bufworkspace"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/pkg/licenseheader"
+)
+
+// GetPathToLicenseHeaderTemplate gets a map from file path to the licenseheader.Template
+// for each .proto file in the ModuleReadBucket.
+//
+// The license header of a file is configured by the LintConfig of its Module within the Workspace.
+// Files of Modules without a license header configured are not included in the map.
+func GetPathToLicenseHeaderTemplate(
+	ctx context.Context,
+	workspace bufworkspace.Workspace,
+	moduleReadBucket bufmodule.ModuleReadBucket,
+) (map[string]licenseheader.Template, error) {
+	pathToLicenseHeaderTemplate := make(map[string]licenseheader.Template)
+	if err := moduleReadBucket.WalkFileInfos(
+		ctx,
+		func(fileInfo bufmodule.FileInfo) error {
+			if fileInfo.FileType() != bufmodule.FileTypeProto {
+				return nil
+			}
+			lintConfig := workspace.GetLintConfigForOpaqueID(fileInfo.Module().OpaqueID())
+			if lintConfig == nil {
+				return nil
+			}
+			if licenseHeaderConfig := lintConfig.LicenseHeaderConfig(); licenseHeaderConfig != nil {
+				pathToLicenseHeaderTemplate[fileInfo.Path()] = licenseHeaderConfig.Template()
+			}
+			return nil
+		},
+	); err != nil {
+		return nil, err
+	}
+	return pathToLicenseHeaderTemplate, nil
+}
@@ -148,8 +148,11 @@ func checkNoMessageSetWireFormat(descriptor protoreflect.MessageDescriptor, imag
 	path := descriptor.ParentFile().Path()
 	imageFile := image.GetFile(path)
 	if imageFile == nil {
-		// shouldn't actually be possible
-		return fmt.Errorf("message type %q should be in file %q but that file was not found", name, path)
+		// The descriptor was resolved by an additional resolver (see ImageWithAdditionalResolvers)
+		// and is not part of the image. Additional resolvers backed by images are responsible for
+		// doing this check themselves, and all other resolvers construct descriptors with protodesc,
+		// which rejects messages that use message-set wire format.
+		return nil
 	}
 	descriptorProto := findMessageInFile(name, imageFile.FileDescriptorProto())
 	if descriptorProto == nil {
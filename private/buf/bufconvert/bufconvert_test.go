@@ -15,6 +15,7 @@
 package bufconvert
 
 import (
+	"context"
 	"reflect"
 	"testing"
 
@@ -24,6 +25,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
 	"google.golang.org/protobuf/types/descriptorpb"
 )
 
@@ -84,6 +86,70 @@ func TestImageWithoutMessageSetWireFormatResolution(t *testing.T) {
 	checker.succeeds(noResolveImage.Resolver().FindEnumByName("foo.bar.Enum"))
 }
 
+func TestModuleTypeURLResolver(t *testing.T) {
+	t.Parallel()
+	file := getTestFileWithMessageSets()
+	imageFile, err := bufimage.NewImageFile(
+		file,
+		nil,
+		uuid.UUID{},
+		file.GetName(),
+		file.GetName(),
+		false,
+		false,
+		nil,
+	)
+	require.NoError(t, err)
+	image, err := bufimage.NewImage([]bufimage.ImageFile{imageFile})
+	require.NoError(t, err)
+
+	var moduleRefs []string
+	resolver := NewModuleTypeURLResolver(
+		context.Background(),
+		func(_ context.Context, moduleRef string) (bufimage.Image, error) {
+			moduleRefs = append(moduleRefs, moduleRef)
+			return image, nil
+		},
+	)
+	checker := resultChecker{t}
+	checker.succeeds(resolver.FindMessageByURL("buf.build/acme/foo/foo.bar.Baz"))
+	checker.fails(resolver.FindMessageByURL("buf.build/acme/foo/foo.bar.MessageSetBaz"))
+	_, err = resolver.FindMessageByURL("buf.build/acme/foo:main/foo.bar.Baz")
+	require.NoError(t, err)
+	_, err = resolver.FindMessageByURL("buf.build/acme/foo/foo.bar.Missing")
+	require.ErrorIs(t, err, protoregistry.NotFound)
+	_, err = resolver.FindMessageByURL("type.googleapis.com/foo.bar.Baz")
+	require.ErrorIs(t, err, protoregistry.NotFound)
+	_, err = resolver.FindMessageByName("foo.bar.Baz")
+	require.ErrorIs(t, err, protoregistry.NotFound)
+	// Images are only retrieved once per module reference.
+	assert.Equal(t, []string{"buf.build/acme/foo", "buf.build/acme/foo:main"}, moduleRefs)
+
+	// Types resolved by additional resolvers are resolved by the image's resolver.
+	otherFile := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("other.proto"),
+		Package: proto.String("other"),
+	}
+	otherImageFile, err := bufimage.NewImageFile(
+		otherFile,
+		nil,
+		uuid.UUID{},
+		otherFile.GetName(),
+		otherFile.GetName(),
+		false,
+		false,
+		nil,
+	)
+	require.NoError(t, err)
+	otherImage, err := bufimage.NewImage([]bufimage.ImageFile{otherImageFile})
+	require.NoError(t, err)
+	noResolveImage := ImageWithoutMessageSetWireFormatResolution(
+		ImageWithAdditionalResolvers(otherImage, resolver),
+	)
+	checker.succeeds(noResolveImage.Resolver().FindMessageByURL("buf.build/acme/foo/foo.bar.Baz"))
+	checker.fails(noResolveImage.Resolver().FindMessageByURL("buf.build/acme/foo/foo.bar.MessageSetBaz"))
+}
+
 func TestFindMessageInFile(t *testing.T) {
 	t.Parallel()
 	t.Run("no-package", func(t *testing.T) {
@@ -0,0 +1,143 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconvert
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufparse"
+	"github.com/bufbuild/buf/private/pkg/protoencoding"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// ImageWithAdditionalResolvers returns an image with the same contents as the
+// given image, but whose resolver falls back to the given resolvers, in order,
+// for any element that the image itself cannot resolve.
+//
+// Resolvers backed by images should be the resolvers of images returned by
+// ImageWithoutMessageSetWireFormatResolution.
+//
+// This is used to resolve types that are not part of the schema image, such as
+// the contents of google.protobuf.Any values or extensions in binary payloads.
+func ImageWithAdditionalResolvers(image bufimage.Image, resolvers ...protoencoding.Resolver) bufimage.Image {
+	if len(resolvers) == 0 {
+		return image
+	}
+	return &additionalResolversImage{
+		Image:    image,
+		resolver: protoencoding.CombineResolvers(append([]protoencoding.Resolver{image.Resolver()}, resolvers...)...),
+	}
+}
+
+// NewModuleTypeURLResolver returns a new Resolver that resolves message types by
+// type URL, where the prefix of the type URL is a reference to a BSR module.
+//
+// For example, the type URL "buf.build/acme/weather/acme.weather.v1.Units" will
+// result in the image for "buf.build/acme/weather" being retrieved with getImage,
+// and the message "acme.weather.v1.Units" being resolved within it. A ref may be
+// provided as part of the prefix, such as "buf.build/acme/weather:main/acme.weather.v1.Units".
+//
+// Images are retrieved lazily and cached for the lifetime of the Resolver. Message
+// types that use message-set wire format are not resolved. All methods other than
+// FindMessageByURL return protoregistry.NotFound.
+func NewModuleTypeURLResolver(
+	ctx context.Context,
+	getImage func(ctx context.Context, moduleRef string) (bufimage.Image, error),
+) protoencoding.Resolver {
+	return &moduleTypeURLResolver{
+		ctx:            ctx,
+		getImage:       getImage,
+		refToImageFunc: make(map[string]func() (bufimage.Image, error)),
+	}
+}
+
+// *** PRIVATE ***
+
+type additionalResolversImage struct {
+	bufimage.Image
+
+	resolver protoencoding.Resolver
+}
+
+func (a *additionalResolversImage) Resolver() protoencoding.Resolver {
+	return a.resolver
+}
+
+type moduleTypeURLResolver struct {
+	ctx      context.Context
+	getImage func(ctx context.Context, moduleRef string) (bufimage.Image, error)
+
+	lock           sync.Mutex
+	refToImageFunc map[string]func() (bufimage.Image, error)
+}
+
+func (m *moduleTypeURLResolver) FindFileByPath(string) (protoreflect.FileDescriptor, error) {
+	return nil, protoregistry.NotFound
+}
+
+func (m *moduleTypeURLResolver) FindDescriptorByName(protoreflect.FullName) (protoreflect.Descriptor, error) {
+	return nil, protoregistry.NotFound
+}
+
+func (m *moduleTypeURLResolver) FindEnumByName(protoreflect.FullName) (protoreflect.EnumType, error) {
+	return nil, protoregistry.NotFound
+}
+
+func (m *moduleTypeURLResolver) FindExtensionByName(protoreflect.FullName) (protoreflect.ExtensionType, error) {
+	return nil, protoregistry.NotFound
+}
+
+func (m *moduleTypeURLResolver) FindExtensionByNumber(protoreflect.FullName, protoreflect.FieldNumber) (protoreflect.ExtensionType, error) {
+	return nil, protoregistry.NotFound
+}
+
+func (m *moduleTypeURLResolver) FindMessageByName(protoreflect.FullName) (protoreflect.MessageType, error) {
+	return nil, protoregistry.NotFound
+}
+
+func (m *moduleTypeURLResolver) FindMessageByURL(url string) (protoreflect.MessageType, error) {
+	index := strings.LastIndexByte(url, '/')
+	if index < 0 {
+		return nil, protoregistry.NotFound
+	}
+	moduleRefString, typeName := url[:index], url[index+1:]
+	if _, err := bufparse.ParseRef(moduleRefString); err != nil {
+		// Not a module reference, for example "type.googleapis.com".
+		return nil, protoregistry.NotFound
+	}
+	image, err := m.getImageForModuleRef(moduleRefString)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve type URL %q: %w", url, err)
+	}
+	return ImageWithoutMessageSetWireFormatResolution(image).Resolver().FindMessageByName(protoreflect.FullName(typeName))
+}
+
+func (m *moduleTypeURLResolver) getImageForModuleRef(moduleRefString string) (bufimage.Image, error) {
+	m.lock.Lock()
+	imageFunc, ok := m.refToImageFunc[moduleRefString]
+	if !ok {
+		imageFunc = sync.OnceValues(func() (bufimage.Image, error) {
+			return m.getImage(m.ctx, moduleRefString)
+		})
+		m.refToImageFunc[moduleRefString] = imageFunc
+	}
+	m.lock.Unlock()
+	return imageFunc()
+}
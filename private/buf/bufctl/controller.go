@@ -22,10 +22,13 @@ import (
 	"io/fs"
 	"log/slog"
 	"net/http"
+	"os"
 	"sort"
 
 	"buf.build/go/protoyaml"
+	"github.com/bufbuild/buf/private/buf/bufcurl"
 	"github.com/bufbuild/buf/private/buf/buffetch"
+	"github.com/bufbuild/buf/private/buf/bufformat"
 	"github.com/bufbuild/buf/private/buf/bufwkt/bufwktstore"
 	"github.com/bufbuild/buf/private/buf/bufworkspace"
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
@@ -33,6 +36,7 @@ import (
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimageutil"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulesign"
 	"github.com/bufbuild/buf/private/bufpkg/bufparse"
 	"github.com/bufbuild/buf/private/bufpkg/bufplugin"
 	"github.com/bufbuild/buf/private/bufpkg/bufreflect"
@@ -46,10 +50,13 @@ import (
 	"github.com/bufbuild/buf/private/pkg/normalpath"
 	"github.com/bufbuild/buf/private/pkg/protoencoding"
 	"github.com/bufbuild/buf/private/pkg/slicesext"
+	"github.com/bufbuild/buf/private/pkg/storage"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"github.com/bufbuild/buf/private/pkg/syserror"
+	"github.com/bufbuild/buf/private/pkg/verbose"
 	"github.com/bufbuild/protovalidate-go"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 // ImageWithConfig pairs an Image with lint and breaking configuration.
@@ -178,6 +185,9 @@ type controller struct {
 	pluginKeyProvider  bufplugin.PluginKeyProvider
 	pluginDataProvider bufplugin.PluginDataProvider
 	wktStore           bufwktstore.Store
+	signatureStore     bufmodulesign.SignatureStore
+	// The settings of the client for inputs that use server reflection.
+	reflectClientSettings bufcurl.ReflectClientSettings
 
 	disableSymlinks           bool
 	fileAnnotationErrorFormat string
@@ -241,8 +251,10 @@ func newController(
 	controller.workspaceProvider = bufworkspace.NewWorkspaceProvider(
 		logger,
 		graphProvider,
+		moduleKeyProvider,
 		moduleDataProvider,
 		commitProvider,
+		controller.storageosProvider,
 	)
 	controller.workspaceDepManagerProvider = bufworkspace.NewWorkspaceDepManagerProvider(
 		logger,
@@ -371,58 +383,13 @@ func (c *controller) GetTargetImageWithConfigs(
 		if err != nil {
 			return nil, err
 		}
-		bucket, err := c.storageosProvider.NewReadWriteBucket(
-			".",
-			storageos.ReadWriteBucketWithSymlinksIfSupported(),
-		)
+		return c.getImageWithConfigsForImage(ctx, image, functionOptions)
+	case buffetch.ReflectRef:
+		image, err := c.getImageForReflectRef(ctx, t, functionOptions)
 		if err != nil {
 			return nil, err
 		}
-		lintConfig := bufconfig.DefaultLintConfigV1
-		breakingConfig := bufconfig.DefaultBreakingConfigV1
-		var pluginConfigs []bufconfig.PluginConfig
-		bufYAMLFile, err := bufconfig.GetBufYAMLFileForPrefixOrOverride(
-			ctx,
-			bucket,
-			".",
-			functionOptions.configOverride,
-		)
-		if err != nil {
-			if !errors.Is(err, fs.ErrNotExist) {
-				return nil, err
-			}
-			// We did not find a buf.yaml in our current directory, and there was no config override.
-			// Use the defaults.
-		} else {
-			pluginConfigs = bufYAMLFile.PluginConfigs()
-			if topLevelLintConfig := bufYAMLFile.TopLevelLintConfig(); topLevelLintConfig == nil {
-				// Ensure that this is a v2 config
-				if fileVersion := bufYAMLFile.FileVersion(); fileVersion != bufconfig.FileVersionV2 {
-					return nil, syserror.Newf("non-v2 version with no top-level lint config: %s", fileVersion)
-				}
-				// v2 config without a top-level lint config, use v2 default
-				lintConfig = bufconfig.DefaultLintConfigV2
-			} else {
-				lintConfig = topLevelLintConfig
-			}
-			if topLevelBreakingConfig := bufYAMLFile.TopLevelBreakingConfig(); topLevelBreakingConfig == nil {
-				if fileVersion := bufYAMLFile.FileVersion(); fileVersion != bufconfig.FileVersionV2 {
-					return nil, syserror.Newf("non-v2 version with no top-level breaking config: %s", fileVersion)
-				}
-				// v2 config without a top-level breaking config, use v2 default
-				breakingConfig = bufconfig.DefaultBreakingConfigV2
-			} else {
-				breakingConfig = topLevelBreakingConfig
-			}
-		}
-		return []ImageWithConfig{
-			newImageWithConfig(
-				image,
-				lintConfig,
-				breakingConfig,
-				pluginConfigs,
-			),
-		}, nil
+		return c.getImageWithConfigsForImage(ctx, image, functionOptions)
 	default:
 		// This is a system error.
 		return nil, syserror.Newf("invalid Ref: %T", ref)
@@ -494,8 +461,8 @@ func (c *controller) GetImportableImageFileInfos(
 		if err != nil {
 			return nil, err
 		}
-	case buffetch.MessageRef:
-		image, err := c.getImageForMessageRef(ctx, t, functionOptions)
+	case buffetch.MessageRef, buffetch.ReflectRef:
+		image, err := c.getImageForRef(ctx, t, functionOptions)
 		if err != nil {
 			return nil, err
 		}
@@ -536,13 +503,29 @@ func (c *controller) PutImage(
 	for _, option := range options {
 		option(functionOptions)
 	}
-	// Must be messageRefParser NOT c.buffetchRefParser as a NewMessageRefParser
-	// defaults to a defaultMessageEncoding and not dir.
-	messageRefParser := buffetch.NewMessageRefParser(c.logger)
-	messageRef, err := messageRefParser.GetMessageRef(ctx, imageOutput)
+	// Must be imageOutputRefParser NOT c.buffetchRefParser as a NewImageOutputRefParser
+	// defaults to binpb and only returns a dir when the format is set explicitly.
+	imageOutputRefParser := buffetch.NewImageOutputRefParser(c.logger)
+	imageOutputRef, err := imageOutputRefParser.GetImageOutputRef(ctx, imageOutput)
 	if err != nil {
 		return err
 	}
+	switch t := imageOutputRef.(type) {
+	case buffetch.MessageRef:
+		return c.putImageMessage(ctx, t, image, functionOptions)
+	case buffetch.DirRef:
+		return c.putImageDir(ctx, t, image, functionOptions)
+	default:
+		return syserror.Newf("invalid Ref type for image output: %T", imageOutputRef)
+	}
+}
+
+func (c *controller) putImageMessage(
+	ctx context.Context,
+	messageRef buffetch.MessageRef,
+	image bufimage.Image,
+	functionOptions *functionOptions,
+) (retErr error) {
 	// Stop short for performance.
 	if messageRef.IsNull() {
 		return nil
@@ -579,6 +562,44 @@ func (c *controller) PutImage(
 	return err
 }
 
+// putImageDir writes the image as .proto source files to the directory.
+//
+// Imported well-known types are not written, as they are provided by
+// every Protobuf compiler.
+func (c *controller) putImageDir(
+	ctx context.Context,
+	dirRef buffetch.DirRef,
+	image bufimage.Image,
+	functionOptions *functionOptions,
+) error {
+	putImage, err := filterImage(image, functionOptions, false)
+	if err != nil {
+		return err
+	}
+	var wktImportMatchers []storage.Matcher
+	for _, imageFile := range putImage.Files() {
+		if imageFile.IsImport() && datawkt.Exists(imageFile.Path()) {
+			wktImportMatchers = append(wktImportMatchers, storage.MatchPathEqual(imageFile.Path()))
+		}
+	}
+	readBucket, err := bufformat.FormatImage(ctx, putImage)
+	if err != nil {
+		return err
+	}
+	if len(wktImportMatchers) > 0 {
+		readBucket = storage.FilterReadBucket(readBucket, storage.MatchNot(storage.MatchOr(wktImportMatchers...)))
+	}
+	if err := os.MkdirAll(dirRef.DirPath(), 0755); err != nil {
+		return err
+	}
+	readWriteBucket, err := c.storageosProvider.NewReadWriteBucket(dirRef.DirPath())
+	if err != nil {
+		return err
+	}
+	_, err = storage.Copy(ctx, readBucket, readWriteBucket)
+	return err
+}
+
 func (c *controller) GetMessage(
 	ctx context.Context,
 	schemaImage bufimage.Image,
@@ -632,6 +653,9 @@ func (c *controller) GetMessage(
 			protoencoding.YAMLUnmarshalerWithValidator(validator),
 		)
 		validator = nil // Validation errors are handled by the unmarshaler.
+	case buffetch.MessageEncodingRaw:
+		// Raw messages are decoded without a schema, so every field is retained as an unknown field.
+		unmarshaler = protoencoding.NewWireUnmarshaler(nil)
 	default:
 		// This is a system error.
 		return nil, 0, syserror.Newf("unknown MessageEncoding: %v", messageEncoding)
@@ -644,12 +668,18 @@ func (c *controller) GetMessage(
 	if err != nil {
 		return nil, 0, err
 	}
-	if len(data) == 0 {
+	// An empty raw message is valid, and has no fields.
+	if len(data) == 0 && messageEncoding != buffetch.MessageEncodingRaw {
 		return nil, 0, fmt.Errorf("length of data read from %q was zero", messageInput)
 	}
-	message, err := bufreflect.NewMessage(ctx, schemaImage, typeName)
-	if err != nil {
-		return nil, 0, err
+	var message proto.Message
+	if messageEncoding == buffetch.MessageEncodingRaw {
+		message = &emptypb.Empty{}
+	} else {
+		message, err = bufreflect.NewMessage(ctx, schemaImage, typeName)
+		if err != nil {
+			return nil, 0, err
+		}
 	}
 	if err := unmarshaler.Unmarshal(data, message); err != nil {
 		return nil, 0, err
@@ -756,6 +786,8 @@ func (c *controller) getImageForRef(
 		return c.getImageForWorkspace(ctx, workspace, functionOptions)
 	case buffetch.MessageRef:
 		return c.getImageForMessageRef(ctx, t, functionOptions)
+	case buffetch.ReflectRef:
+		return c.getImageForReflectRef(ctx, t, functionOptions)
 	default:
 		// This is a system error.
 		return nil, syserror.Newf("invalid Ref: %T", ref)
@@ -827,12 +859,25 @@ func (c *controller) getWorkspaceForProtoFileRef(
 			bufworkspace.WithIgnoreAndDisallowV1BufWorkYAMLs(),
 		)
 	}
-	return c.workspaceProvider.GetWorkspaceForBucket(
+	if functionOptions.ignoreReplacements {
+		options = append(
+			options,
+			bufworkspace.WithIgnoreReplacements(),
+		)
+	}
+	workspace, err := c.workspaceProvider.GetWorkspaceForBucket(
 		ctx,
 		readBucketCloser,
 		bucketTargeting,
 		options...,
 	)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.verifyWorkspace(ctx, workspace); err != nil {
+		return nil, err
+	}
+	return workspace, nil
 }
 
 func (c *controller) getWorkspaceForSourceRef(
@@ -863,12 +908,25 @@ func (c *controller) getWorkspaceForSourceRef(
 			bufworkspace.WithIgnoreAndDisallowV1BufWorkYAMLs(),
 		)
 	}
-	return c.workspaceProvider.GetWorkspaceForBucket(
+	if functionOptions.ignoreReplacements {
+		options = append(
+			options,
+			bufworkspace.WithIgnoreReplacements(),
+		)
+	}
+	workspace, err := c.workspaceProvider.GetWorkspaceForBucket(
 		ctx,
 		readBucketCloser,
 		bucketTargeting,
 		options...,
 	)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.verifyWorkspace(ctx, workspace); err != nil {
+		return nil, err
+	}
+	return workspace, nil
 }
 
 func (c *controller) getWorkspaceDepManagerForDirRef(
@@ -916,6 +974,102 @@ func (c *controller) getWorkspaceForModuleRef(
 	)
 }
 
+// verifyWorkspace verifies that the remote Modules of the Workspace are signed by the
+// public keys trusted by the VerifyConfigs of the Workspace.
+func (c *controller) verifyWorkspace(
+	ctx context.Context,
+	workspace bufworkspace.Workspace,
+) error {
+	verifyConfigs := workspace.VerifyConfigs()
+	if len(verifyConfigs) == 0 {
+		return nil
+	}
+	if c.signatureStore == nil {
+		return syserror.New("a SignatureStore is required to verify a workspace with a verify policy")
+	}
+	return bufmodulesign.VerifyModules(
+		ctx,
+		c.signatureStore,
+		workspace.Modules(),
+		func(moduleFullName bufparse.FullName) []bufmodulesign.PublicKey {
+			for _, verifyConfig := range verifyConfigs {
+				if verifyConfig.Matches(moduleFullName) {
+					return verifyConfig.PublicKeys()
+				}
+			}
+			return nil
+		},
+	)
+}
+
+// getImageWithConfigsForImage returns the image with the lint and breaking configs of the
+// buf.yaml in the current directory, or the defaults if there is no buf.yaml.
+//
+// This is used for images that did not come from a workspace, such as images read from
+// files or downloaded using server reflection.
+func (c *controller) getImageWithConfigsForImage(
+	ctx context.Context,
+	image bufimage.Image,
+	functionOptions *functionOptions,
+) ([]ImageWithConfig, error) {
+	bucket, err := c.storageosProvider.NewReadWriteBucket(
+		".",
+		storageos.ReadWriteBucketWithSymlinksIfSupported(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	lintConfig := bufconfig.DefaultLintConfigV1
+	breakingConfig := bufconfig.DefaultBreakingConfigV1
+	var pluginConfigs []bufconfig.PluginConfig
+	bufYAMLFile, err := bufconfig.GetBufYAMLFileForPrefixOrOverride(
+		ctx,
+		bucket,
+		".",
+		functionOptions.configOverride,
+	)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		// We did not find a buf.yaml in our current directory, and there was no config override.
+		// Use the defaults.
+	} else {
+		bufYAMLFile, err = bufworkspace.ResolveBufYAMLFile(ctx, bufYAMLFile, bucket, c.moduleDataProvider)
+		if err != nil {
+			return nil, err
+		}
+		pluginConfigs = bufYAMLFile.PluginConfigs()
+		if topLevelLintConfig := bufYAMLFile.TopLevelLintConfig(); topLevelLintConfig == nil {
+			// Ensure that this is a v2 config
+			if fileVersion := bufYAMLFile.FileVersion(); fileVersion != bufconfig.FileVersionV2 {
+				return nil, syserror.Newf("non-v2 version with no top-level lint config: %s", fileVersion)
+			}
+			// v2 config without a top-level lint config, use v2 default
+			lintConfig = bufconfig.DefaultLintConfigV2
+		} else {
+			lintConfig = topLevelLintConfig
+		}
+		if topLevelBreakingConfig := bufYAMLFile.TopLevelBreakingConfig(); topLevelBreakingConfig == nil {
+			if fileVersion := bufYAMLFile.FileVersion(); fileVersion != bufconfig.FileVersionV2 {
+				return nil, syserror.Newf("non-v2 version with no top-level breaking config: %s", fileVersion)
+			}
+			// v2 config without a top-level breaking config, use v2 default
+			breakingConfig = bufconfig.DefaultBreakingConfigV2
+		} else {
+			breakingConfig = topLevelBreakingConfig
+		}
+	}
+	return []ImageWithConfig{
+		newImageWithConfig(
+			image,
+			lintConfig,
+			breakingConfig,
+			pluginConfigs,
+		),
+	}, nil
+}
+
 func (c *controller) getImageForMessageRef(
 	ctx context.Context,
 	messageRef buffetch.MessageRef,
@@ -992,6 +1146,30 @@ func (c *controller) getImageForMessageRef(
 	return filterImage(image, functionOptions, false)
 }
 
+func (c *controller) getImageForReflectRef(
+	ctx context.Context,
+	reflectRef buffetch.ReflectRef,
+	functionOptions *functionOptions,
+) (bufimage.Image, error) {
+	url := reflectRef.URL()
+	resolver, closeResolver, err := bufcurl.NewServerReflectionResolverForURL(
+		ctx,
+		url,
+		c.reflectClientSettings,
+		verbose.NopPrinter,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer closeResolver()
+	image, err := bufcurl.ImageForResolver(resolver)
+	if err != nil {
+		// Not using %w, as connect errors are otherwise misreported by the app framework.
+		return nil, fmt.Errorf("could not get schema from %s using server reflection: %v", url, err)
+	}
+	return filterImage(image, functionOptions, false)
+}
+
 func (c *controller) buildImage(
 	ctx context.Context,
 	moduleReadBucket bufmodule.ModuleReadBucket,
@@ -1223,6 +1401,22 @@ func filterImage(
 ) (bufimage.Image, error) {
 	newImage := image
 	var err error
+	// Exclusions are applied before imports are removed, so that types
+	// from imports can be excluded and the imports of files recomputed.
+	if len(functionOptions.imageExcludeTypes) > 0 || len(functionOptions.imageExcludeOptions) > 0 {
+		excludeOptions := make([]bufimageutil.ImageExcludeOption, 0, len(functionOptions.imageExcludeOptions))
+		for _, predicate := range functionOptions.imageExcludeOptions {
+			excludeOption, err := bufimageutil.ParseExcludeByOption(predicate)
+			if err != nil {
+				return nil, err
+			}
+			excludeOptions = append(excludeOptions, excludeOption)
+		}
+		newImage, err = bufimageutil.ImageWithoutTypes(newImage, functionOptions.imageExcludeTypes, excludeOptions...)
+		if err != nil {
+			return nil, err
+		}
+	}
 	if functionOptions.imageExcludeImports {
 		newImage = bufimage.ImageWithoutImports(newImage)
 	}
@@ -1279,6 +1473,8 @@ func newProtoencodingMarshaler(
 		return protoencoding.NewTxtpbMarshaler(image.Resolver()), nil
 	case buffetch.MessageEncodingYAML:
 		return newYAMLMarshaler(image.Resolver(), messageRef), nil
+	case buffetch.MessageEncodingRaw:
+		return protoencoding.NewRawMarshaler(), nil
 	default:
 		// This is a system error.
 		return nil, syserror.Newf("unknown MessageEncoding: %v", messageEncoding)
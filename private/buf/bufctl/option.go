@@ -15,7 +15,9 @@
 package bufctl
 
 import (
+	"github.com/bufbuild/buf/private/buf/bufcurl"
 	"github.com/bufbuild/buf/private/buf/buffetch"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulesign"
 )
 
 type ControllerOption func(*controller)
@@ -44,6 +46,22 @@ func WithCopyToInMemory() ControllerOption {
 	}
 }
 
+// WithSignatureStore returns a new ControllerOption that sets the SignatureStore used
+// to verify the remote Modules of workspaces with a verify policy.
+func WithSignatureStore(signatureStore bufmodulesign.SignatureStore) ControllerOption {
+	return func(controller *controller) {
+		controller.signatureStore = signatureStore
+	}
+}
+
+// WithReflectClientSettings returns a new ControllerOption that sets the settings of the
+// client for inputs that use server reflection, such as the headers and TLS settings.
+func WithReflectClientSettings(reflectClientSettings bufcurl.ReflectClientSettings) ControllerOption {
+	return func(controller *controller) {
+		controller.reflectClientSettings = reflectClientSettings
+	}
+}
+
 // TODO FUTURE: split up to per-function.
 type FunctionOption func(*functionOptions)
 
@@ -72,6 +90,22 @@ func WithImageTypes(imageTypes []string) FunctionOption {
 	}
 }
 
+// WithImageExcludeTypes excludes the given fully-qualified names, such as packages,
+// messages, enums, services and fields, and the elements that depend on them, from the image.
+func WithImageExcludeTypes(imageExcludeTypes []string) FunctionOption {
+	return func(functionOptions *functionOptions) {
+		functionOptions.imageExcludeTypes = imageExcludeTypes
+	}
+}
+
+// WithImageExcludeOptions excludes all elements matching the given option predicates,
+// such as "(acme.visibility) = INTERNAL", from the image.
+func WithImageExcludeOptions(imageExcludeOptions []string) FunctionOption {
+	return func(functionOptions *functionOptions) {
+		functionOptions.imageExcludeOptions = imageExcludeOptions
+	}
+}
+
 func WithImageAsFileDescriptorSet(imageAsFileDescriptorSet bool) FunctionOption {
 	return func(functionOptions *functionOptions) {
 		functionOptions.imageAsFileDescriptorSet = imageAsFileDescriptorSet
@@ -113,6 +147,16 @@ func WithIgnoreAndDisallowV1BufWorkYAMLs() FunctionOption {
 	}
 }
 
+// WithIgnoreReplacements returns a new FunctionOption that says to ignore the
+// dependency replacements of a v2 buf.yaml.
+//
+// See bufworkspace.WithIgnoreReplacements for more details.
+func WithIgnoreReplacements() FunctionOption {
+	return func(functionOptions *functionOptions) {
+		functionOptions.ignoreReplacements = true
+	}
+}
+
 // WithMessageValidation returns a new FunctionOption that says to validate the
 // message as it is being read.
 //
@@ -134,9 +178,12 @@ type functionOptions struct {
 	imageExcludeSourceInfo          bool
 	imageExcludeImports             bool
 	imageTypes                      []string
+	imageExcludeTypes               []string
+	imageExcludeOptions             []string
 	imageAsFileDescriptorSet        bool
 	configOverride                  string
 	ignoreAndDisallowV1BufWorkYAMLs bool
+	ignoreReplacements              bool
 	messageValidation               bool
 }
 
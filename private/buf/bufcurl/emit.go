@@ -0,0 +1,820 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufcurl
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"connectrpc.com/connect"
+	"github.com/bufbuild/buf/private/pkg/protoencoding"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const (
+	// EmitFormatCurl emits a curl command.
+	EmitFormatCurl EmitFormat = iota + 1
+	// EmitFormatGrpcurl emits a grpcurl command.
+	EmitFormatGrpcurl
+	// EmitFormatGo emits a Go program that uses a connect-go generated client.
+	EmitFormatGo
+	// EmitFormatHTTP emits a raw HTTP request.
+	EmitFormatHTTP
+)
+
+var (
+	// AllEmitFormatStrings are all format strings for EmitFormats.
+	AllEmitFormatStrings = []string{
+		"curl",
+		"grpcurl",
+		"go",
+		"http",
+	}
+
+	emitFormatToString = map[EmitFormat]string{
+		EmitFormatCurl:    "curl",
+		EmitFormatGrpcurl: "grpcurl",
+		EmitFormatGo:      "go",
+		EmitFormatHTTP:    "http",
+	}
+	stringToEmitFormat = map[string]EmitFormat{
+		"curl":    EmitFormatCurl,
+		"grpcurl": EmitFormatGrpcurl,
+		"go":      EmitFormatGo,
+		"http":    EmitFormatHTTP,
+	}
+)
+
+// EmitFormat is a format for an equivalent invocation of an RPC.
+type EmitFormat int
+
+// String implements fmt.Stringer.
+func (e EmitFormat) String() string {
+	s, ok := emitFormatToString[e]
+	if !ok {
+		return strconv.Itoa(int(e))
+	}
+	return s
+}
+
+// ParseEmitFormat parses the EmitFormat.
+//
+// The empty string is a parse error.
+func ParseEmitFormat(s string) (EmitFormat, error) {
+	e, ok := stringToEmitFormat[strings.ToLower(strings.TrimSpace(s))]
+	if ok {
+		return e, nil
+	}
+	return 0, fmt.Errorf("unknown EmitFormat: %q", s)
+}
+
+// EmitSettings contains the details of an RPC invocation that are reproduced
+// by Emit.
+type EmitSettings struct {
+	// The URL of the RPC method, including the service and method name.
+	URL string
+	// The RPC protocol, one of connect.ProtocolConnect, connect.ProtocolGRPC,
+	// or connect.ProtocolGRPCWeb.
+	Protocol string
+	// If non-empty, the path to a unix socket to use instead of the host
+	// and port in the URL.
+	UnixSocket string
+	// The TLS settings. The file names and server name are only used when
+	// the URL uses the https scheme. HTTP2PriorKnowledge and HTTP3 also
+	// apply to plain-text URLs.
+	TLS TLSSettings
+	// If true, the values of Authorization headers are printed as is. Otherwise,
+	// they are replaced with a reference to the $BUF_TOKEN environment variable,
+	// so that credentials are not leaked into shell history, bug reports or
+	// source code.
+	IncludeCredentials bool
+}
+
+// Emit writes an invocation of the method described by the given descriptor
+// in the given format to the writer. The invocation is equivalent to the one
+// that Invoker would perform with the same settings, headers, and request data.
+//
+// The request data is read from data in the same format as for Invoker, and
+// the given resolver is used to resolve Any messages and extensions in it.
+func Emit(
+	writer io.Writer,
+	format EmitFormat,
+	settings EmitSettings,
+	md protoreflect.MethodDescriptor,
+	res protoencoding.Resolver,
+	dataSource string,
+	data io.Reader,
+	headers http.Header,
+) error {
+	request, err := newEmitRequest(settings, md, res, dataSource, data, headers)
+	if err != nil {
+		return err
+	}
+	var output string
+	switch format {
+	case EmitFormatCurl:
+		output, err = request.curl()
+	case EmitFormatGrpcurl:
+		output, err = request.grpcurl()
+	case EmitFormatGo:
+		output, err = request.goProgram()
+	case EmitFormatHTTP:
+		output, err = request.http()
+	default:
+		return fmt.Errorf("unknown EmitFormat: %v", format)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(writer, output)
+	return err
+}
+
+// *** PRIVATE ***
+
+// emitCredentialsEnvKey is the environment variable that is referenced instead
+// of the value of redacted headers.
+const emitCredentialsEnvKey = "BUF_TOKEN"
+
+type emitRequest struct {
+	settings EmitSettings
+	url      *url.URL
+	isSecure bool
+	md       protoreflect.MethodDescriptor
+	res      protoencoding.Resolver
+	messages []*dynamicpb.Message
+	headers  http.Header
+}
+
+func newEmitRequest(
+	settings EmitSettings,
+	md protoreflect.MethodDescriptor,
+	res protoencoding.Resolver,
+	dataSource string,
+	data io.Reader,
+	headers http.Header,
+) (*emitRequest, error) {
+	endpointURL, err := url.Parse(settings.URL)
+	if err != nil {
+		return nil, err
+	}
+	var provider messageProvider
+	if md.IsStreamingClient() {
+		provider = newStreamMessageProvider(dataSource, data, res)
+	} else {
+		provider = newMessageProvider(dataSource, data, res)
+	}
+	var messages []*dynamicpb.Message
+	for {
+		message := dynamicpb.NewMessage(md.Input())
+		if err := provider.next(message); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	if !md.IsStreamingClient() && len(messages) != 1 {
+		return nil, fmt.Errorf("method %s accepts exactly one request message, but input contained %d", md.Name(), len(messages))
+	}
+	return &emitRequest{
+		settings: settings,
+		url:      endpointURL,
+		isSecure: endpointURL.Scheme == "https",
+		md:       md,
+		res:      res,
+		messages: messages,
+		headers:  headers,
+	}, nil
+}
+
+func (r *emitRequest) curl() (string, error) {
+	var prefix string
+	args := []string{"curl"}
+	switch {
+	case r.settings.TLS.HTTP3:
+		args = append(args, "--http3")
+	case r.settings.TLS.HTTP2PriorKnowledge:
+		args = append(args, "--http2-prior-knowledge")
+	case r.settings.Protocol == connect.ProtocolGRPC:
+		args = append(args, "--http2")
+	}
+	if r.settings.UnixSocket != "" {
+		args = append(args, "--unix-socket "+shellQuote(r.settings.UnixSocket))
+	}
+	requestURL := r.url.String()
+	if r.isSecure {
+		if r.settings.TLS.Insecure {
+			args = append(args, "--insecure")
+		}
+		if r.settings.TLS.CACertFile != "" {
+			args = append(args, "--cacert "+shellQuote(r.settings.TLS.CACertFile))
+		}
+		if r.settings.TLS.CertFile != "" {
+			args = append(args, "--cert "+shellQuote(r.settings.TLS.CertFile))
+		}
+		if r.settings.TLS.KeyFile != "" {
+			args = append(args, "--key "+shellQuote(r.settings.TLS.KeyFile))
+		}
+		if serverName := r.settings.TLS.ServerName; serverName != "" && serverName != r.url.Hostname() {
+			// curl has no flag to override SNI, so we instead address the server
+			// by the server name and tell curl where to actually connect.
+			port := r.port()
+			args = append(args, "--connect-to "+shellQuote(serverName+":"+port+":"+r.url.Hostname()+":"+port))
+			serverNameURL := *r.url
+			serverNameURL.Host = net.JoinHostPort(serverName, port)
+			requestURL = serverNameURL.String()
+			if len(r.headers.Values("host")) == 0 {
+				args = append(args, "--header "+shellQuote("Host: "+r.url.Host))
+			}
+		}
+	}
+	for _, header := range r.protocolHeaderLines() {
+		args = append(args, "--header "+shellQuote(header))
+	}
+	for _, header := range r.shellRequestHeaderLines() {
+		args = append(args, "--header "+header)
+	}
+	if r.isUnaryConnect() {
+		body, err := r.jsonMessage(r.messages[0])
+		if err != nil {
+			return "", err
+		}
+		args = append(args, "--data-binary "+shellQuote(body))
+	} else {
+		body, err := r.envelopedBody()
+		if err != nil {
+			return "", err
+		}
+		prefix = "echo " + shellQuote(base64.StdEncoding.EncodeToString(body)) + " | base64 --decode | "
+		args = append(args, "--data-binary @-", "--output -")
+	}
+	args = append(args, shellQuote(requestURL))
+	return prefix + strings.Join(args, " \\\n  ") + "\n", nil
+}
+
+func (r *emitRequest) grpcurl() (string, error) {
+	if r.settings.Protocol != connect.ProtocolGRPC {
+		return "", fmt.Errorf("grpcurl only supports the %s protocol, not %s", connect.ProtocolGRPC, r.settings.Protocol)
+	}
+	if r.settings.TLS.HTTP3 {
+		return "", errors.New("grpcurl does not support HTTP/3")
+	}
+	baseURL, err := r.baseURL()
+	if err != nil {
+		return "", err
+	}
+	if basePath := strings.TrimPrefix(baseURL, r.url.Scheme+"://"+r.url.Host); basePath != "" && basePath != "/" {
+		return "", fmt.Errorf("grpcurl does not support URLs with a path prefix: %q", basePath)
+	}
+	args := []string{"grpcurl"}
+	if r.isSecure {
+		if r.settings.TLS.Insecure {
+			args = append(args, "-insecure")
+		}
+		if r.settings.TLS.CACertFile != "" {
+			args = append(args, "-cacert "+shellQuote(r.settings.TLS.CACertFile))
+		}
+		if r.settings.TLS.CertFile != "" {
+			args = append(args, "-cert "+shellQuote(r.settings.TLS.CertFile))
+		}
+		if r.settings.TLS.KeyFile != "" {
+			args = append(args, "-key "+shellQuote(r.settings.TLS.KeyFile))
+		}
+		if r.settings.TLS.ServerName != "" {
+			args = append(args, "-servername "+shellQuote(r.settings.TLS.ServerName))
+		}
+	} else {
+		args = append(args, "-plaintext")
+	}
+	// grpcurl sets the protocol headers itself.
+	for _, header := range r.shellRequestHeaderLines() {
+		args = append(args, "-H "+header)
+	}
+	bodies := make([]string, len(r.messages))
+	for i, message := range r.messages {
+		body, err := r.jsonMessage(message)
+		if err != nil {
+			return "", err
+		}
+		bodies[i] = body
+	}
+	args = append(args, "-d "+shellQuote(strings.Join(bodies, " ")))
+	address := net.JoinHostPort(r.url.Hostname(), r.port())
+	if r.settings.UnixSocket != "" {
+		args = append(args, "-unix")
+		address = r.settings.UnixSocket
+	}
+	args = append(args, shellQuote(address), shellQuote(r.methodPath()))
+	return strings.Join(args, " \\\n  ") + "\n", nil
+}
+
+func (r *emitRequest) http() (string, error) {
+	if !r.isUnaryConnect() {
+		return "", fmt.Errorf(
+			"raw HTTP requests can only be emitted for unary RPCs using the %s protocol, since other requests have a binary body",
+			connect.ProtocolConnect,
+		)
+	}
+	body, err := r.jsonMessage(r.messages[0])
+	if err != nil {
+		return "", err
+	}
+	version := "HTTP/1.1"
+	switch {
+	case r.settings.TLS.HTTP3:
+		version = "HTTP/3"
+	case r.settings.TLS.HTTP2PriorKnowledge:
+		version = "HTTP/2"
+	}
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "%s %s %s\n", http.MethodPost, r.url.String(), version)
+	if len(r.headers.Values("host")) == 0 {
+		fmt.Fprintf(&buffer, "Host: %s\n", r.url.Host)
+	}
+	for _, header := range append(r.protocolHeaderLines(), r.requestHeaderLines()...) {
+		fmt.Fprintf(&buffer, "%s\n", header)
+	}
+	fmt.Fprintf(&buffer, "Content-Length: %d\n\n%s\n", len(body), body)
+	return buffer.String(), nil
+}
+
+func (r *emitRequest) goProgram() (string, error) {
+	if r.settings.TLS.HTTP3 {
+		return "", errors.New("emitting Go code for HTTP/3 is not supported")
+	}
+	baseURL, err := r.baseURL()
+	if err != nil {
+		return "", err
+	}
+	service, ok := r.md.Parent().(protoreflect.ServiceDescriptor)
+	if !ok {
+		return "", fmt.Errorf("method %s has no parent service", r.md.FullName())
+	}
+	serviceImportPath, servicePackageName, err := goPackageForFile(service.ParentFile())
+	if err != nil {
+		return "", err
+	}
+	inputImportPath, inputPackageName, err := goPackageForFile(r.md.Input().ParentFile())
+	if err != nil {
+		return "", err
+	}
+	connectPackageName := servicePackageName + "connect"
+	imports := map[string]string{
+		"context":                "",
+		"fmt":                    "",
+		"log":                    "",
+		"connectrpc.com/connect": "",
+		"google.golang.org/protobuf/encoding/protojson":  "",
+		path.Join(serviceImportPath, connectPackageName): "",
+		inputImportPath: inputPackageName,
+	}
+	inputType := inputPackageName + "." + goIdentForDescriptor(r.md.Input())
+	methodName := goCamelCase(string(r.md.Name()))
+
+	var body bytes.Buffer
+	body.WriteString("ctx := context.Background()\n")
+	httpClient := r.writeGoHTTPClient(&body, imports)
+	fmt.Fprintf(&body, "client := %s.New%sClient(\n%s,\n%s,\n", connectPackageName, goCamelCase(string(service.Name())), httpClient, strconv.Quote(baseURL))
+	switch r.settings.Protocol {
+	case connect.ProtocolGRPC:
+		body.WriteString("connect.WithGRPC(),\n")
+	case connect.ProtocolGRPCWeb:
+		body.WriteString("connect.WithGRPCWeb(),\n")
+	}
+	body.WriteString(")\n")
+	if !r.md.IsStreamingClient() {
+		message, err := r.jsonMessage(r.messages[0])
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&body, "request := connect.NewRequest(&%s{})\n", inputType)
+		if message != "{}" {
+			fmt.Fprintf(&body, "if err := protojson.Unmarshal([]byte(%s), request.Msg); err != nil {\nlog.Fatal(err)\n}\n", goStringLiteral(message))
+		}
+		r.writeGoHeaders(&body, imports, "request.Header()")
+		if r.md.IsStreamingServer() {
+			fmt.Fprintf(&body, "stream, err := client.%s(ctx, request)\n", methodName)
+			body.WriteString("if err != nil {\nlog.Fatal(err)\n}\n")
+			body.WriteString("for stream.Receive() {\nfmt.Println(protojson.Format(stream.Msg()))\n}\n")
+			body.WriteString("if err := stream.Err(); err != nil {\nlog.Fatal(err)\n}\n")
+		} else {
+			fmt.Fprintf(&body, "response, err := client.%s(ctx, request)\n", methodName)
+			body.WriteString("if err != nil {\nlog.Fatal(err)\n}\n")
+			body.WriteString("fmt.Println(protojson.Format(response.Msg))\n")
+		}
+	} else {
+		fmt.Fprintf(&body, "stream := client.%s(ctx)\n", methodName)
+		r.writeGoHeaders(&body, imports, "stream.RequestHeader()")
+		body.WriteString("for _, data := range []string{\n")
+		for _, message := range r.messages {
+			message, err := r.jsonMessage(message)
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintf(&body, "%s,\n", goStringLiteral(message))
+		}
+		body.WriteString("} {\n")
+		fmt.Fprintf(&body, "message := &%s{}\n", inputType)
+		body.WriteString("if err := protojson.Unmarshal([]byte(data), message); err != nil {\nlog.Fatal(err)\n}\n")
+		body.WriteString("if err := stream.Send(message); err != nil {\nlog.Fatal(err)\n}\n")
+		body.WriteString("}\n")
+		if r.md.IsStreamingServer() {
+			imports["errors"] = ""
+			imports["io"] = ""
+			body.WriteString("if err := stream.CloseRequest(); err != nil {\nlog.Fatal(err)\n}\n")
+			body.WriteString("for {\nmessage, err := stream.Receive()\nif errors.Is(err, io.EOF) {\nbreak\n}\n")
+			body.WriteString("if err != nil {\nlog.Fatal(err)\n}\nfmt.Println(protojson.Format(message))\n}\n")
+			body.WriteString("if err := stream.CloseResponse(); err != nil {\nlog.Fatal(err)\n}\n")
+		} else {
+			body.WriteString("response, err := stream.CloseAndReceive()\n")
+			body.WriteString("if err != nil {\nlog.Fatal(err)\n}\n")
+			body.WriteString("fmt.Println(protojson.Format(response.Msg))\n")
+		}
+	}
+
+	var program bytes.Buffer
+	program.WriteString("package main\n\nimport (\n")
+	importPaths := make([]string, 0, len(imports))
+	for importPath := range imports {
+		importPaths = append(importPaths, importPath)
+	}
+	// Standard library imports come first, in their own group.
+	sort.Slice(importPaths, func(i, j int) bool {
+		iIsStandard, jIsStandard := isGoStandardImportPath(importPaths[i]), isGoStandardImportPath(importPaths[j])
+		if iIsStandard != jIsStandard {
+			return iIsStandard
+		}
+		return importPaths[i] < importPaths[j]
+	})
+	for i, importPath := range importPaths {
+		if i > 0 && isGoStandardImportPath(importPaths[i-1]) && !isGoStandardImportPath(importPath) {
+			program.WriteString("\n")
+		}
+		if alias := imports[importPath]; alias != "" && alias != path.Base(importPath) {
+			fmt.Fprintf(&program, "%s %s\n", alias, strconv.Quote(importPath))
+		} else {
+			fmt.Fprintf(&program, "%s\n", strconv.Quote(importPath))
+		}
+	}
+	program.WriteString(")\n\nfunc main() {\n")
+	program.Write(body.Bytes())
+	program.WriteString("}\n")
+	formatted, err := format.Source(program.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("failed to format emitted Go code: %w", err)
+	}
+	return string(formatted), nil
+}
+
+// writeGoHTTPClient writes the statements to create the HTTP client for the
+// emitted Go program, and returns the expression for the client.
+func (r *emitRequest) writeGoHTTPClient(buffer *bytes.Buffer, imports map[string]string) string {
+	tlsSettings := r.settings.TLS
+	hasTLSConfig := r.isSecure &&
+		(tlsSettings.Insecure ||
+			tlsSettings.CACertFile != "" ||
+			tlsSettings.CertFile != "" ||
+			tlsSettings.ServerName != "")
+	if !hasTLSConfig && !tlsSettings.HTTP2PriorKnowledge && r.settings.UnixSocket == "" {
+		imports["net/http"] = ""
+		return "http.DefaultClient"
+	}
+	if r.isSecure {
+		imports["crypto/tls"] = ""
+		buffer.WriteString("tlsConfig := &tls.Config{\n")
+		if tlsSettings.Insecure {
+			buffer.WriteString("InsecureSkipVerify: true,\n")
+		}
+		if tlsSettings.ServerName != "" {
+			fmt.Fprintf(buffer, "ServerName: %s,\n", strconv.Quote(tlsSettings.ServerName))
+		}
+		buffer.WriteString("}\n")
+		if tlsSettings.CACertFile != "" {
+			imports["crypto/x509"] = ""
+			imports["os"] = ""
+			fmt.Fprintf(buffer, "caCert, err := os.ReadFile(%s)\n", strconv.Quote(tlsSettings.CACertFile))
+			buffer.WriteString("if err != nil {\nlog.Fatal(err)\n}\n")
+			buffer.WriteString("tlsConfig.RootCAs = x509.NewCertPool()\n")
+			fmt.Fprintf(buffer, "if !tlsConfig.RootCAs.AppendCertsFromPEM(caCert) {\nlog.Fatal(%s)\n}\n", strconv.Quote("no certificates found in "+tlsSettings.CACertFile))
+		}
+		if tlsSettings.CertFile != "" {
+			fmt.Fprintf(buffer, "certificate, err := tls.LoadX509KeyPair(%s, %s)\n", strconv.Quote(tlsSettings.CertFile), strconv.Quote(tlsSettings.KeyFile))
+			buffer.WriteString("if err != nil {\nlog.Fatal(err)\n}\n")
+			buffer.WriteString("tlsConfig.Certificates = []tls.Certificate{certificate}\n")
+		}
+	}
+	network, address := "network", "address"
+	if r.settings.UnixSocket != "" {
+		network, address = strconv.Quote("unix"), strconv.Quote(r.settings.UnixSocket)
+	}
+	imports["net/http"] = ""
+	buffer.WriteString("httpClient := &http.Client{\nTransport: ")
+	switch {
+	case tlsSettings.HTTP2PriorKnowledge && r.isSecure:
+		imports["golang.org/x/net/http2"] = ""
+		buffer.WriteString("&http2.Transport{\nTLSClientConfig: tlsConfig,\n")
+		if r.settings.UnixSocket != "" {
+			imports["context"] = ""
+			imports["net"] = ""
+			buffer.WriteString("DialTLSContext: func(ctx context.Context, network, address string, config *tls.Config) (net.Conn, error) {\n")
+			fmt.Fprintf(buffer, "dialer := &tls.Dialer{Config: config}\nreturn dialer.DialContext(ctx, %s, %s)\n},\n", network, address)
+		}
+		buffer.WriteString("},\n")
+	case tlsSettings.HTTP2PriorKnowledge:
+		imports["crypto/tls"] = ""
+		imports["golang.org/x/net/http2"] = ""
+		imports["net"] = ""
+		buffer.WriteString("&http2.Transport{\nAllowHTTP: true,\n")
+		buffer.WriteString("DialTLSContext: func(ctx context.Context, network, address string, _ *tls.Config) (net.Conn, error) {\n")
+		fmt.Fprintf(buffer, "var dialer net.Dialer\nreturn dialer.DialContext(ctx, %s, %s)\n},\n},\n", network, address)
+	default:
+		buffer.WriteString("&http.Transport{\n")
+		if r.isSecure {
+			buffer.WriteString("TLSClientConfig: tlsConfig,\n")
+		}
+		if r.settings.UnixSocket != "" {
+			imports["net"] = ""
+			buffer.WriteString("DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {\n")
+			fmt.Fprintf(buffer, "var dialer net.Dialer\nreturn dialer.DialContext(ctx, %s, %s)\n},\n", network, address)
+		}
+		buffer.WriteString("ForceAttemptHTTP2: true,\n},\n")
+	}
+	buffer.WriteString("}\n")
+	return "httpClient"
+}
+
+func (r *emitRequest) writeGoHeaders(buffer *bytes.Buffer, imports map[string]string, headerExpression string) {
+	for _, name := range sortedHeaderNames(r.headers) {
+		for _, value := range r.headers.Values(name) {
+			valueExpression := strconv.Quote(value)
+			if r.isRedactedHeader(name) {
+				imports["os"] = ""
+				valueExpression = fmt.Sprintf("os.Getenv(%s)", strconv.Quote(emitCredentialsEnvKey))
+			}
+			fmt.Fprintf(buffer, "%s.Add(%s, %s)\n", headerExpression, strconv.Quote(name), valueExpression)
+		}
+	}
+}
+
+// protocolHeaderLines returns the headers required by the protocol, each in
+// "Name: value" form.
+func (r *emitRequest) protocolHeaderLines() []string {
+	var lines []string
+	switch r.settings.Protocol {
+	case connect.ProtocolGRPC:
+		lines = append(lines, "Content-Type: application/grpc", "Te: trailers")
+	case connect.ProtocolGRPCWeb:
+		lines = append(lines, "Content-Type: application/grpc-web+proto", "X-Grpc-Web: 1")
+	default:
+		if r.isUnaryConnect() {
+			lines = append(lines, "Content-Type: application/json")
+		} else {
+			lines = append(lines, "Content-Type: application/connect+proto")
+		}
+		lines = append(lines, "Connect-Protocol-Version: 1")
+	}
+	return lines
+}
+
+// requestHeaderLines returns the request headers, each in "Name: value" form.
+//
+// The values of redacted headers are replaced with $BUF_TOKEN.
+func (r *emitRequest) requestHeaderLines() []string {
+	var lines []string
+	for _, name := range sortedHeaderNames(r.headers) {
+		for _, value := range r.headers.Values(name) {
+			if r.isRedactedHeader(name) {
+				value = "$" + emitCredentialsEnvKey
+			}
+			lines = append(lines, name+": "+value)
+		}
+	}
+	return lines
+}
+
+// shellRequestHeaderLines returns the request headers, each in "Name: value" form
+// and quoted for a shell.
+//
+// The values of redacted headers are double-quoted references to $BUF_TOKEN, so
+// that the shell expands them.
+func (r *emitRequest) shellRequestHeaderLines() []string {
+	var lines []string
+	for _, name := range sortedHeaderNames(r.headers) {
+		for _, value := range r.headers.Values(name) {
+			if r.isRedactedHeader(name) {
+				// The name is a casing of Authorization, so it needs no escaping.
+				lines = append(lines, `"`+name+`: $`+emitCredentialsEnvKey+`"`)
+				continue
+			}
+			lines = append(lines, shellQuote(name+": "+value))
+		}
+	}
+	return lines
+}
+
+// isRedactedHeader returns true if the value of the header with the given name
+// must not be printed.
+func (r *emitRequest) isRedactedHeader(name string) bool {
+	return !r.settings.IncludeCredentials && http.CanonicalHeaderKey(name) == "Authorization"
+}
+
+func (r *emitRequest) isUnaryConnect() bool {
+	return r.settings.Protocol == connect.ProtocolConnect && !r.md.IsStreamingClient() && !r.md.IsStreamingServer()
+}
+
+// envelopedBody returns the request messages in the binary Protobuf format,
+// each prefixed with the envelope used by the gRPC, gRPC-Web, and Connect
+// streaming protocols.
+func (r *emitRequest) envelopedBody() ([]byte, error) {
+	var body []byte
+	for _, message := range r.messages {
+		data, err := protoencoding.NewWireMarshaler().Marshal(message)
+		if err != nil {
+			return nil, err
+		}
+		// A zero flags byte, followed by the big-endian length of the message.
+		body = append(body, 0)
+		body = binary.BigEndian.AppendUint32(body, uint32(len(data)))
+		body = append(body, data...)
+	}
+	return body, nil
+}
+
+// jsonMessage returns the message in compact JSON format.
+func (r *emitRequest) jsonMessage(message *dynamicpb.Message) (string, error) {
+	data, err := protoencoding.NewJSONMarshaler(r.res).Marshal(message)
+	if err != nil {
+		return "", err
+	}
+	// The JSON marshaler does not promise stable whitespace, so we compact it.
+	var buffer bytes.Buffer
+	if err := json.Compact(&buffer, data); err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+// baseURL returns the URL without the service and method name.
+func (r *emitRequest) baseURL() (string, error) {
+	requestURL := r.url.String()
+	baseURL := strings.TrimSuffix(requestURL, "/"+r.methodPath())
+	if baseURL == requestURL {
+		return "", fmt.Errorf("URL %q does not end with %q", requestURL, r.methodPath())
+	}
+	return baseURL, nil
+}
+
+func (r *emitRequest) methodPath() string {
+	return string(r.md.Parent().FullName()) + "/" + string(r.md.Name())
+}
+
+func (r *emitRequest) port() string {
+	if port := r.url.Port(); port != "" {
+		return port
+	}
+	if r.isSecure {
+		return "443"
+	}
+	return "80"
+}
+
+func sortedHeaderNames(headers http.Header) []string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// goPackageForFile returns the Go import path and package name for the given
+// file, as determined by protoc-gen-go from its go_package option.
+func goPackageForFile(file protoreflect.FileDescriptor) (string, string, error) {
+	var goPackage string
+	if fileOptions, ok := file.Options().(interface{ GetGoPackage() string }); ok {
+		goPackage = fileOptions.GetGoPackage()
+	}
+	if goPackage == "" {
+		return "", "", fmt.Errorf("cannot emit Go code: file %q has no go_package option", file.Path())
+	}
+	importPath, packageName, found := strings.Cut(goPackage, ";")
+	if !found {
+		packageName = path.Base(importPath)
+	}
+	return importPath, goSanitized(packageName), nil
+}
+
+// goIdentForDescriptor returns the name of the Go type generated by
+// protoc-gen-go for the given message.
+func goIdentForDescriptor(descriptor protoreflect.Descriptor) string {
+	name := strings.TrimPrefix(string(descriptor.FullName()), string(descriptor.ParentFile().Package())+".")
+	return goCamelCase(name)
+}
+
+// goCamelCase camel-cases a Protobuf name for use as a Go identifier, the
+// same way as protoc-gen-go.
+func goCamelCase(s string) string {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '.' && i+1 < len(s) && isASCIILower(s[i+1]):
+			// Skip over '.' in ".{{lowercase}}".
+		case c == '.':
+			b = append(b, '_')
+		case c == '_' && (i == 0 || s[i-1] == '.'):
+			// Convert initial '_' to ensure we start with a capital letter.
+			b = append(b, 'X')
+		case c == '_' && i+1 < len(s) && isASCIILower(s[i+1]):
+			// Skip over '_' in "_{{lowercase}}".
+		case isASCIIDigit(c):
+			b = append(b, c)
+		default:
+			// Assume we have a letter now - if not, it's a bogus identifier.
+			if isASCIILower(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+			// Accept lower case sequence that follows.
+			for ; i+1 < len(s) && isASCIILower(s[i+1]); i++ {
+				b = append(b, s[i+1])
+			}
+		}
+	}
+	return string(b)
+}
+
+// goSanitized converts a string into a valid Go package name.
+func goSanitized(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r < utf8.RuneSelf && (isASCIILower(byte(r)) || isASCIIUpper(byte(r)) || isASCIIDigit(byte(r)) || r == '_') {
+			return r
+		}
+		return '_'
+	}, s)
+	if token.Lookup(s).IsKeyword() || s == "" || isASCIIDigit(s[0]) {
+		s = "_" + s
+	}
+	return s
+}
+
+func isGoStandardImportPath(importPath string) bool {
+	firstElement, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(firstElement, ".")
+}
+
+func isASCIILower(c byte) bool {
+	return 'a' <= c && c <= 'z'
+}
+
+func isASCIIUpper(c byte) bool {
+	return 'A' <= c && c <= 'Z'
+}
+
+func isASCIIDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func goStringLiteral(s string) string {
+	if strings.ContainsAny(s, "`\r") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
+
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-./:@%+=,") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
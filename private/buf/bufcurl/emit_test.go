@@ -0,0 +1,326 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufcurl
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/bufbuild/buf/private/pkg/protoencoding"
+	"github.com/bufbuild/protocompile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestEmitCurl(t *testing.T) {
+	t.Parallel()
+	output := testEmit(
+		t,
+		EmitFormatCurl,
+		EmitSettings{
+			URL:      "https://example.com/foo.v1.FooService/Say",
+			Protocol: connect.ProtocolConnect,
+			TLS: TLSSettings{
+				Insecure: true,
+			},
+		},
+		"Say",
+		`{"sentence": "it's"}`,
+		http.Header{"Authorization": []string{"Bearer token"}},
+	)
+	assert.Equal(
+		t,
+		`curl \
+  --insecure \
+  --header 'Content-Type: application/json' \
+  --header 'Connect-Protocol-Version: 1' \
+  --header "Authorization: $BUF_TOKEN" \
+  --data-binary '{"sentence":"it'\''s"}' \
+  https://example.com/foo.v1.FooService/Say
+`,
+		output,
+	)
+	output = testEmit(
+		t,
+		EmitFormatCurl,
+		EmitSettings{
+			URL:                "https://example.com/foo.v1.FooService/Say",
+			Protocol:           connect.ProtocolConnect,
+			IncludeCredentials: true,
+		},
+		"Say",
+		`{"sentence": "hi"}`,
+		http.Header{"Authorization": []string{"Bearer token"}},
+	)
+	assert.Contains(t, output, `--header 'Authorization: Bearer token'`)
+}
+
+func TestEmitCurlEnveloped(t *testing.T) {
+	t.Parallel()
+	output := testEmit(
+		t,
+		EmitFormatCurl,
+		EmitSettings{
+			URL:        "http://localhost/foo.v1.FooService/Say",
+			Protocol:   connect.ProtocolGRPC,
+			UnixSocket: "/tmp/grpc.sock",
+			TLS: TLSSettings{
+				HTTP2PriorKnowledge: true,
+			},
+		},
+		"Say",
+		`{"sentence": "hi"}`,
+		nil,
+	)
+	// A zero flags byte, a length of 4, and then field 1 with the string "hi".
+	assert.Equal(
+		t,
+		`echo AAAAAAQKAmhp | base64 --decode | curl \
+  --http2-prior-knowledge \
+  --unix-socket /tmp/grpc.sock \
+  --header 'Content-Type: application/grpc' \
+  --header 'Te: trailers' \
+  --data-binary @- \
+  --output - \
+  http://localhost/foo.v1.FooService/Say
+`,
+		output,
+	)
+}
+
+func TestEmitGrpcurl(t *testing.T) {
+	t.Parallel()
+	output := testEmit(
+		t,
+		EmitFormatGrpcurl,
+		EmitSettings{
+			URL:      "https://example.com/foo.v1.FooService/Converse",
+			Protocol: connect.ProtocolGRPC,
+			TLS: TLSSettings{
+				CACertFile: "ca.pem",
+				ServerName: "other.example.com",
+			},
+		},
+		"Converse",
+		`{"sentence": "a"} {"sentence": "b"}`,
+		http.Header{"X-Custom": []string{"value"}, "Authorization": []string{"Basic dXNlcjpwYXNz"}},
+	)
+	assert.Equal(
+		t,
+		`grpcurl \
+  -cacert ca.pem \
+  -servername other.example.com \
+  -H "Authorization: $BUF_TOKEN" \
+  -H 'X-Custom: value' \
+  -d '{"sentence":"a"} {"sentence":"b"}' \
+  example.com:443 \
+  foo.v1.FooService/Converse
+`,
+		output,
+	)
+	err := testEmitError(
+		t,
+		EmitFormatGrpcurl,
+		EmitSettings{
+			URL:      "https://example.com/foo.v1.FooService/Say",
+			Protocol: connect.ProtocolConnect,
+		},
+		"Say",
+	)
+	assert.EqualError(t, err, "grpcurl only supports the grpc protocol, not connect")
+	err = testEmitError(
+		t,
+		EmitFormatGrpcurl,
+		EmitSettings{
+			URL:      "https://example.com/api/foo.v1.FooService/Say",
+			Protocol: connect.ProtocolGRPC,
+		},
+		"Say",
+	)
+	assert.EqualError(t, err, `grpcurl does not support URLs with a path prefix: "/api"`)
+}
+
+func TestEmitHTTP(t *testing.T) {
+	t.Parallel()
+	output := testEmit(
+		t,
+		EmitFormatHTTP,
+		EmitSettings{
+			URL:      "http://localhost:8080/foo.v1.FooService/Say",
+			Protocol: connect.ProtocolConnect,
+		},
+		"Say",
+		"",
+		http.Header{"Authorization": []string{"Bearer token"}},
+	)
+	assert.Equal(
+		t,
+		`POST http://localhost:8080/foo.v1.FooService/Say HTTP/1.1
+Host: localhost:8080
+Content-Type: application/json
+Connect-Protocol-Version: 1
+Authorization: $BUF_TOKEN
+Content-Length: 2
+
+{}
+`,
+		output,
+	)
+	err := testEmitError(
+		t,
+		EmitFormatHTTP,
+		EmitSettings{
+			URL:      "http://localhost:8080/foo.v1.FooService/Converse",
+			Protocol: connect.ProtocolConnect,
+		},
+		"Converse",
+	)
+	assert.ErrorContains(t, err, "raw HTTP requests can only be emitted for unary RPCs")
+}
+
+func TestEmitGo(t *testing.T) {
+	t.Parallel()
+	output := testEmit(
+		t,
+		EmitFormatGo,
+		EmitSettings{
+			URL:      "https://example.com/foo.v1.FooService/Say",
+			Protocol: connect.ProtocolGRPCWeb,
+		},
+		"Say",
+		`{"sentence": "hi"}`,
+		http.Header{"X-Custom": []string{"value"}, "Authorization": []string{"Bearer token"}},
+	)
+	assert.Equal(
+		t,
+		`package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"connectrpc.com/connect"
+	foov1 "example.com/gen/foo/v1"
+	"example.com/gen/foo/v1/foov1connect"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func main() {
+	ctx := context.Background()
+	client := foov1connect.NewFooServiceClient(
+		http.DefaultClient,
+		"https://example.com",
+		connect.WithGRPCWeb(),
+	)
+	request := connect.NewRequest(&foov1.SayRequest{})
+	if err := protojson.Unmarshal([]byte(`+"`"+`{"sentence":"hi"}`+"`"+`), request.Msg); err != nil {
+		log.Fatal(err)
+	}
+	request.Header().Add("Authorization", os.Getenv("BUF_TOKEN"))
+	request.Header().Add("X-Custom", "value")
+	response, err := client.Say(ctx, request)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(protojson.Format(response.Msg))
+}
+`,
+		output,
+	)
+	// Streaming methods and custom transports must still produce valid Go.
+	output = testEmit(
+		t,
+		EmitFormatGo,
+		EmitSettings{
+			URL:        "https://example.com/foo.v1.FooService/Converse",
+			Protocol:   connect.ProtocolGRPC,
+			UnixSocket: "/tmp/grpc.sock",
+			TLS: TLSSettings{
+				KeyFile:             "key.pem",
+				CertFile:            "cert.pem",
+				HTTP2PriorKnowledge: true,
+			},
+		},
+		"Converse",
+		`{"sentence": "a"} {"sentence": "b"}`,
+		nil,
+	)
+	assert.Contains(t, output, `tls.LoadX509KeyPair("cert.pem", "key.pem")`)
+	assert.Contains(t, output, `dialer.DialContext(ctx, "unix", "/tmp/grpc.sock")`)
+	assert.Contains(t, output, "stream.CloseRequest()")
+}
+
+func TestGoCamelCase(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "FooBar", goCamelCase("foo_bar"))
+	assert.Equal(t, "Outer_Inner", goCamelCase("Outer.Inner"))
+	assert.Equal(t, "XFoo", goCamelCase("_foo"))
+	assert.Equal(t, "Foo2Bar", goCamelCase("foo2bar"))
+}
+
+func testEmit(
+	t *testing.T,
+	format EmitFormat,
+	settings EmitSettings,
+	methodName string,
+	data string,
+	headers http.Header,
+) string {
+	md, res := testEmitMethod(t, methodName)
+	var dataReader io.Reader
+	if data != "" {
+		dataReader = strings.NewReader(data)
+	}
+	var buffer bytes.Buffer
+	require.NoError(t, Emit(&buffer, format, settings, md, res, "(argument)", dataReader, headers))
+	return buffer.String()
+}
+
+func testEmitError(
+	t *testing.T,
+	format EmitFormat,
+	settings EmitSettings,
+	methodName string,
+) error {
+	md, res := testEmitMethod(t, methodName)
+	var buffer bytes.Buffer
+	err := Emit(&buffer, format, settings, md, res, "(argument)", nil, nil)
+	require.Error(t, err)
+	return err
+}
+
+func testEmitMethod(t *testing.T, methodName string) (protoreflect.MethodDescriptor, protoencoding.Resolver) {
+	descriptors, err := (&protocompile.Compiler{
+		Resolver: &protocompile.SourceResolver{
+			ImportPaths: []string{"./testdata"},
+		},
+	}).Compile(context.Background(), "emit.proto")
+	require.NoError(t, err)
+	res, err := protoencoding.NewResolver(protodesc.ToFileDescriptorProto(descriptors[0]))
+	require.NoError(t, err)
+	md, err := ResolveMethodDescriptor(res, "foo.v1.FooService", methodName)
+	require.NoError(t, err)
+	return md, res
+}
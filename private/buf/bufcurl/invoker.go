@@ -77,6 +77,9 @@ type invoker struct {
 	output       io.Writer
 	errOutput    io.Writer
 	printer      verbose.Printer
+	// If true, responses are not printed and RPC errors are returned as is
+	// instead of being printed to errOutput.
+	quiet bool
 }
 
 // NewInvoker creates a new invoker for invoking the method described by the
@@ -85,6 +88,10 @@ type invoker struct {
 // extensions that appear in the input or output. Other parameters are used
 // to create a Connect client, for issuing the RPC.
 func NewInvoker(container appext.Container, verbosePrinter verbose.Printer, md protoreflect.MethodDescriptor, res protoencoding.Resolver, emitDefaults bool, httpClient connect.HTTPClient, opts []connect.ClientOption, url string, out io.Writer) Invoker {
+	return newInvoker(verbosePrinter, md, res, emitDefaults, httpClient, opts, url, out, container.Stderr())
+}
+
+func newInvoker(verbosePrinter verbose.Printer, md protoreflect.MethodDescriptor, res protoencoding.Resolver, emitDefaults bool, httpClient connect.HTTPClient, opts []connect.ClientOption, url string, out io.Writer, errOut io.Writer) *invoker {
 	opts = append(opts, connect.WithCodec(protoCodec{}))
 	// TODO: could also provide custom compressor implementations that could give us
 	//  optics into when request and response messages are compressed (which could be
@@ -95,7 +102,7 @@ func NewInvoker(container appext.Container, verbosePrinter verbose.Printer, md p
 		emitDefaults: emitDefaults,
 		output:       out,
 		printer:      verbosePrinter,
-		errOutput:    container.Stderr(),
+		errOutput:    errOut,
 		client:       connect.NewClient[dynamicpb.Message, deferredMessage](httpClient, url, opts...),
 	}
 }
@@ -281,6 +288,9 @@ func (inv *invoker) handleResponse(data []byte, msg *dynamicpb.Message) error {
 	if err := protoencoding.NewWireUnmarshaler(inv.res).Unmarshal(data, msg); err != nil {
 		return err
 	}
+	if inv.quiet {
+		return nil
+	}
 	jsonMarshalerOptions := []protoencoding.JSONMarshalerOption{
 		protoencoding.JSONMarshalerWithIndent(),
 	}
@@ -367,6 +377,9 @@ func (inv *invoker) handleStreamResponse(stream serverStream) (retError error) {
 }
 
 func (inv *invoker) handleErrorResponse(connErr *connect.Error) error {
+	if inv.quiet {
+		return connErr
+	}
 	// NB: This is a nasty hack: we create a fake request that looks
 	//     like a unary Connect request, so that the ErrorWriter will
 	//     print the error in the format we want, which is just the
@@ -0,0 +1,388 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufcurl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/bufbuild/buf/private/pkg/protoencoding"
+	"github.com/bufbuild/buf/private/pkg/verbose"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// AllLoadReportFormatStrings are all format strings for PrintLoadReport.
+var AllLoadReportFormatStrings = []string{
+	"text",
+	"json",
+}
+
+// LoadSettings controls how a LoadTester invokes an RPC.
+type LoadSettings struct {
+	// The number of RPCs to invoke at the same time. Must be positive.
+	Concurrency int
+	// The total number of RPCs to invoke. Zero means there is no limit.
+	Requests int
+	// The time after which no more RPCs are started. RPCs that are in
+	// progress are allowed to complete. Zero means there is no limit.
+	Duration time.Duration
+	// The maximum number of RPCs to start per second, across all
+	// concurrent callers. Zero means there is no limit.
+	Rate float64
+}
+
+// LoadTester invokes an RPC repeatedly and in parallel, to measure the
+// performance of a server.
+type LoadTester interface {
+	// Run invokes the RPC as indicated by the given settings, each time using
+	// the given request data and headers, and reports the results. The
+	// dataSource describes the request data (e.g. a filename) and nil data
+	// means an empty request.
+	//
+	// An error is only returned if the load test itself fails. Failed RPCs
+	// are included in the report.
+	Run(ctx context.Context, settings LoadSettings, dataSource string, data []byte, headers http.Header) (*LoadReport, error)
+}
+
+// NewLoadTester creates a new LoadTester for the method described by the given
+// descriptor. All RPCs share the given HTTP client. Other parameters are used
+// the same way as for NewInvoker.
+func NewLoadTester(md protoreflect.MethodDescriptor, res protoencoding.Resolver, httpClient connect.HTTPClient, opts []connect.ClientOption, url string) LoadTester {
+	countingClient := &countingHTTPClient{client: httpClient}
+	invoker := newInvoker(verbose.NopPrinter, md, res, false, countingClient, opts, url, io.Discard, io.Discard)
+	invoker.quiet = true
+	return &loadTester{
+		invoker:        invoker,
+		countingClient: countingClient,
+	}
+}
+
+// LoadReport contains the results of a load test.
+type LoadReport struct {
+	Requests      int   `json:"requests"`
+	Succeeded     int   `json:"succeeded"`
+	Failed        int   `json:"failed"`
+	BytesSent     int64 `json:"bytes_sent"`
+	BytesReceived int64 `json:"bytes_received"`
+	// The time from the start of the first RPC to the end of the last one.
+	Duration time.Duration `json:"-"`
+	// Requests per second.
+	Throughput float64     `json:"throughput"`
+	Latency    LoadLatency `json:"-"`
+	// The number of RPCs for each status code, keyed by the code name,
+	// e.g. "ok" or "unavailable".
+	StatusCodes map[string]int `json:"status_codes"`
+	// The number of RPCs for each distinct error message.
+	Errors map[string]int `json:"errors,omitempty"`
+}
+
+// LoadLatency contains latency statistics for the RPCs in a load test.
+type LoadLatency struct {
+	Min  time.Duration
+	Mean time.Duration
+	P50  time.Duration
+	P90  time.Duration
+	P95  time.Duration
+	P99  time.Duration
+	Max  time.Duration
+}
+
+// PrintLoadReport prints the report to the writer in the given format, which
+// must be one of AllLoadReportFormatStrings.
+func PrintLoadReport(writer io.Writer, report *LoadReport, format string) error {
+	switch format {
+	case "text":
+		return printLoadReportText(writer, report)
+	case "json":
+		return printLoadReportJSON(writer, report)
+	default:
+		return fmt.Errorf("unknown load report format: %q", format)
+	}
+}
+
+// *** PRIVATE ***
+
+type loadTester struct {
+	invoker        *invoker
+	countingClient *countingHTTPClient
+}
+
+type loadResult struct {
+	latency time.Duration
+	err     error
+}
+
+func (l *loadTester) Run(ctx context.Context, settings LoadSettings, dataSource string, data []byte, headers http.Header) (*LoadReport, error) {
+	if settings.Concurrency < 1 {
+		return nil, fmt.Errorf("concurrency must be positive, got %d", settings.Concurrency)
+	}
+	if settings.Requests == 0 && settings.Duration == 0 {
+		return nil, errors.New("at least one of the number of requests or the duration must be set")
+	}
+	// The run context only controls when new RPCs are started. The RPCs
+	// themselves use the parent context so that they can complete.
+	runCtx := ctx
+	if settings.Duration > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, settings.Duration)
+		defer cancel()
+	}
+	var started atomic.Int64
+	results := make([][]loadResult, settings.Concurrency)
+	start := time.Now()
+	var waitGroup sync.WaitGroup
+	for i := range results {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for {
+				n := started.Add(1) - 1
+				if settings.Requests > 0 && n >= int64(settings.Requests) {
+					return
+				}
+				if settings.Rate > 0 {
+					scheduled := start.Add(time.Duration(float64(n) / settings.Rate * float64(time.Second)))
+					if !sleepUntil(runCtx, scheduled) {
+						return
+					}
+				}
+				if runCtx.Err() != nil {
+					return
+				}
+				var dataReader io.Reader
+				if data != nil {
+					dataReader = bytes.NewReader(data)
+				}
+				callStart := time.Now()
+				err := l.invoker.Invoke(ctx, dataSource, dataReader, headers)
+				results[i] = append(results[i], loadResult{latency: time.Since(callStart), err: err})
+			}
+		}()
+	}
+	waitGroup.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return newLoadReport(
+		results,
+		time.Since(start),
+		l.countingClient.sent.Load(),
+		l.countingClient.received.Load(),
+	), nil
+}
+
+func newLoadReport(results [][]loadResult, duration time.Duration, bytesSent int64, bytesReceived int64) *LoadReport {
+	report := &LoadReport{
+		BytesSent:     bytesSent,
+		BytesReceived: bytesReceived,
+		Duration:      duration,
+		StatusCodes:   make(map[string]int),
+		Errors:        make(map[string]int),
+	}
+	var latencies []time.Duration
+	var totalLatency time.Duration
+	for _, workerResults := range results {
+		for _, result := range workerResults {
+			latencies = append(latencies, result.latency)
+			totalLatency += result.latency
+			if result.err == nil {
+				report.Succeeded++
+				report.StatusCodes["ok"]++
+				continue
+			}
+			report.Failed++
+			// Errors that are not from the RPC, such as invalid request data,
+			// are reported as unknown.
+			report.StatusCodes[connect.CodeOf(result.err).String()]++
+			report.Errors[result.err.Error()]++
+		}
+	}
+	report.Requests = len(latencies)
+	if duration > 0 {
+		report.Throughput = float64(report.Requests) / duration.Seconds()
+	}
+	if len(latencies) > 0 {
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		report.Latency = LoadLatency{
+			Min:  latencies[0],
+			Mean: totalLatency / time.Duration(len(latencies)),
+			P50:  percentile(latencies, 50),
+			P90:  percentile(latencies, 90),
+			P95:  percentile(latencies, 95),
+			P99:  percentile(latencies, 99),
+			Max:  latencies[len(latencies)-1],
+		}
+	}
+	return report
+}
+
+// percentile returns the given percentile of the sorted latencies, using the
+// nearest-rank method.
+func percentile(sortedLatencies []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sortedLatencies))))
+	return sortedLatencies[max(rank, 1)-1]
+}
+
+// sleepUntil returns false if the context is done before the given time.
+func sleepUntil(ctx context.Context, t time.Time) bool {
+	delay := time.Until(t)
+	if delay <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func printLoadReportText(writer io.Writer, report *LoadReport) error {
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "Requests:    %d (%d succeeded, %d failed)\n", report.Requests, report.Succeeded, report.Failed)
+	fmt.Fprintf(&buffer, "Duration:    %v\n", report.Duration.Round(time.Millisecond))
+	fmt.Fprintf(&buffer, "Throughput:  %.2f requests/s\n", report.Throughput)
+	fmt.Fprintf(&buffer, "Sent:        %d bytes\n", report.BytesSent)
+	fmt.Fprintf(&buffer, "Received:    %d bytes\n", report.BytesReceived)
+	if report.Requests > 0 {
+		buffer.WriteString("\nLatency:\n")
+		for _, stat := range []struct {
+			name  string
+			value time.Duration
+		}{
+			{"min", report.Latency.Min},
+			{"mean", report.Latency.Mean},
+			{"p50", report.Latency.P50},
+			{"p90", report.Latency.P90},
+			{"p95", report.Latency.P95},
+			{"p99", report.Latency.P99},
+			{"max", report.Latency.Max},
+		} {
+			fmt.Fprintf(&buffer, "  %-5s %v\n", stat.name, stat.value.Round(time.Microsecond))
+		}
+	}
+	if len(report.StatusCodes) > 0 {
+		buffer.WriteString("\nStatus codes:\n")
+		for _, code := range sortedByCount(report.StatusCodes) {
+			fmt.Fprintf(&buffer, "  %-20s %d\n", code, report.StatusCodes[code])
+		}
+	}
+	if len(report.Errors) > 0 {
+		buffer.WriteString("\nErrors:\n")
+		for _, message := range sortedByCount(report.Errors) {
+			fmt.Fprintf(&buffer, "  %d  %s\n", report.Errors[message], strings.ReplaceAll(message, "\n", " "))
+		}
+	}
+	_, err := writer.Write(buffer.Bytes())
+	return err
+}
+
+func printLoadReportJSON(writer io.Writer, report *LoadReport) error {
+	type externalLatency struct {
+		Min  float64 `json:"min"`
+		Mean float64 `json:"mean"`
+		P50  float64 `json:"p50"`
+		P90  float64 `json:"p90"`
+		P95  float64 `json:"p95"`
+		P99  float64 `json:"p99"`
+		Max  float64 `json:"max"`
+	}
+	type externalReport struct {
+		*LoadReport
+		DurationSeconds float64         `json:"duration_seconds"`
+		LatencySeconds  externalLatency `json:"latency_seconds"`
+	}
+	data, err := json.MarshalIndent(
+		externalReport{
+			LoadReport:      report,
+			DurationSeconds: report.Duration.Seconds(),
+			LatencySeconds: externalLatency{
+				Min:  report.Latency.Min.Seconds(),
+				Mean: report.Latency.Mean.Seconds(),
+				P50:  report.Latency.P50.Seconds(),
+				P90:  report.Latency.P90.Seconds(),
+				P95:  report.Latency.P95.Seconds(),
+				P99:  report.Latency.P99.Seconds(),
+				Max:  report.Latency.Max.Seconds(),
+			},
+		},
+		"",
+		"  ",
+	)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(writer, "%s\n", data)
+	return err
+}
+
+// sortedByCount returns the keys of the map, with the highest counts first.
+func sortedByCount(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// countingHTTPClient counts the bytes in request and response bodies.
+type countingHTTPClient struct {
+	client   connect.HTTPClient
+	sent     atomic.Int64
+	received atomic.Int64
+}
+
+func (c *countingHTTPClient) Do(request *http.Request) (*http.Response, error) {
+	if request.Body != nil && request.Body != http.NoBody {
+		request.Body = &countingReadCloser{ReadCloser: request.Body, count: &c.sent}
+	}
+	response, err := c.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	response.Body = &countingReadCloser{ReadCloser: response.Body, count: &c.received}
+	return response, nil
+}
+
+type countingReadCloser struct {
+	io.ReadCloser
+	count *atomic.Int64
+}
+
+func (c *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.count.Add(int64(n))
+	return n, err
+}
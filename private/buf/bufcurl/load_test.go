@@ -0,0 +1,153 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufcurl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadTester(t *testing.T) {
+	t.Parallel()
+	var count atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		// Every other request fails.
+		if count.Add(1)%2 == 0 {
+			responseWriter.Header().Set("Content-Type", "application/json")
+			responseWriter.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = responseWriter.Write([]byte(`{"code":"unavailable","message":"try again"}`))
+			return
+		}
+		responseWriter.Header().Set("Content-Type", "application/proto")
+		// Field 1 with the string "hi".
+		_, _ = responseWriter.Write([]byte{0x0a, 0x02, 'h', 'i'})
+	}))
+	t.Cleanup(server.Close)
+	md, res := testEmitMethod(t, "Say")
+	loadTester := NewLoadTester(md, res, server.Client(), nil, server.URL+"/foo.v1.FooService/Say")
+	report, err := loadTester.Run(
+		context.Background(),
+		LoadSettings{
+			Concurrency: 3,
+			Requests:    10,
+		},
+		"(argument)",
+		[]byte(`{"sentence": "hello"}`),
+		http.Header{},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 10, report.Requests)
+	assert.Equal(t, 5, report.Succeeded)
+	assert.Equal(t, 5, report.Failed)
+	assert.Equal(t, map[string]int{"ok": 5, "unavailable": 5}, report.StatusCodes)
+	assert.Equal(t, map[string]int{"unavailable: try again": 5}, report.Errors)
+	// Each request is field 1 with the string "hello".
+	assert.Equal(t, int64(10*7), report.BytesSent)
+	assert.Equal(t, int64(5*4+5*len(`{"code":"unavailable","message":"try again"}`)), report.BytesReceived)
+	assert.LessOrEqual(t, report.Latency.Min, report.Latency.P50)
+	assert.LessOrEqual(t, report.Latency.P50, report.Latency.P99)
+	assert.LessOrEqual(t, report.Latency.P99, report.Latency.Max)
+}
+
+func TestLoadTesterDurationAndRate(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		responseWriter.Header().Set("Content-Type", "application/proto")
+	}))
+	t.Cleanup(server.Close)
+	md, res := testEmitMethod(t, "Say")
+	loadTester := NewLoadTester(md, res, server.Client(), nil, server.URL+"/foo.v1.FooService/Say")
+	report, err := loadTester.Run(
+		context.Background(),
+		LoadSettings{
+			Concurrency: 4,
+			Duration:    200 * time.Millisecond,
+			Rate:        20,
+		},
+		"(argument)",
+		nil,
+		http.Header{},
+	)
+	require.NoError(t, err)
+	// RPCs are started at most every 50ms, so at most 5 fit in 200ms.
+	assert.Positive(t, report.Requests)
+	assert.LessOrEqual(t, report.Requests, 5)
+	assert.Equal(t, report.Requests, report.Succeeded)
+}
+
+func TestPrintLoadReport(t *testing.T) {
+	t.Parallel()
+	report := newLoadReport(
+		[][]loadResult{
+			{
+				{latency: 10 * time.Millisecond},
+				{latency: 30 * time.Millisecond, err: connect.NewError(connect.CodeUnavailable, nil)},
+			},
+			{
+				{latency: 20 * time.Millisecond},
+			},
+		},
+		time.Second,
+		100,
+		200,
+	)
+	var buffer bytes.Buffer
+	require.NoError(t, PrintLoadReport(&buffer, report, "text"))
+	assert.Equal(
+		t,
+		`Requests:    3 (2 succeeded, 1 failed)
+Duration:    1s
+Throughput:  3.00 requests/s
+Sent:        100 bytes
+Received:    200 bytes
+
+Latency:
+  min   10ms
+  mean  20ms
+  p50   20ms
+  p90   30ms
+  p95   30ms
+  p99   30ms
+  max   30ms
+
+Status codes:
+  ok                   2
+  unavailable          1
+
+Errors:
+  1  unavailable
+`,
+		buffer.String(),
+	)
+	buffer.Reset()
+	require.NoError(t, PrintLoadReport(&buffer, report, "json"))
+	var external map[string]any
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &external))
+	assert.Equal(t, float64(3), external["requests"])
+	assert.Equal(t, float64(1), external["duration_seconds"])
+	assert.Equal(t, map[string]any{"ok": float64(2), "unavailable": float64(1)}, external["status_codes"])
+	assert.Equal(t, 0.02, external["latency_seconds"].(map[string]any)["p50"])
+	assert.Error(t, PrintLoadReport(&buffer, report, "yaml"))
+}
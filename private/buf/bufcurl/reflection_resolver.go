@@ -16,9 +16,12 @@ package bufcurl
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"sync"
@@ -27,6 +30,7 @@ import (
 	reflectionv1 "github.com/bufbuild/buf/private/gen/proto/go/grpc/reflection/v1"
 	"github.com/bufbuild/buf/private/pkg/protoencoding"
 	"github.com/bufbuild/buf/private/pkg/verbose"
+	"golang.org/x/net/http2"
 	"google.golang.org/protobuf/reflect/protodesc"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/reflect/protoregistry"
@@ -91,6 +95,83 @@ func ParseReflectProtocol(s string) (ReflectProtocol, error) {
 	return 0, fmt.Errorf("unknown ReflectProtocol: %q", s)
 }
 
+// ReflectClientSettings are the settings of the client of NewServerReflectionResolverForURL.
+type ReflectClientSettings struct {
+	// Protocol is the reflection protocol. If unknown, the known protocols are tried from
+	// newest to oldest.
+	Protocol ReflectProtocol
+	// Headers are sent with every reflection request. If the headers have no user-agent,
+	// the default user agent for BufVersion is sent.
+	Headers http.Header
+	// TLS is used for URLs with a scheme of https.
+	TLS TLSSettings
+	// BufVersion is the version of buf used in the default user agent.
+	BufVersion string
+}
+
+// NewServerReflectionResolverForURL creates a new resolver that asks the server at the URL
+// for descriptors using gRPC server reflection.
+//
+// The URL must have a scheme of http or https. As server reflection requires bidirectional
+// streaming, plaintext connections use HTTP/2 with prior knowledge.
+func NewServerReflectionResolverForURL(
+	ctx context.Context,
+	reflectURL string,
+	settings ReflectClientSettings,
+	printer verbose.Printer,
+) (Resolver, func(), error) {
+	parsedURL, err := url.Parse(reflectURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	var transport http.RoundTripper
+	switch parsedURL.Scheme {
+	case "https":
+		tlsConfig, err := MakeVerboseTLSConfig(&settings.TLS, parsedURL.Host, printer)
+		if err != nil {
+			return nil, nil, err
+		}
+		transport = &http.Transport{
+			Proxy:             http.ProxyFromEnvironment,
+			TLSClientConfig:   tlsConfig,
+			ForceAttemptHTTP2: true,
+		}
+	case "http":
+		transport = &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, network, addr)
+			},
+		}
+	default:
+		return nil, nil, fmt.Errorf("URL must have a scheme of http or https: %q", reflectURL)
+	}
+	headers := settings.Headers.Clone()
+	if headers == nil {
+		headers = make(http.Header)
+	}
+	if len(headers.Values("user-agent")) == 0 {
+		headers.Set("user-agent", DefaultUserAgent(connect.ProtocolGRPC, settings.BufVersion))
+	}
+	protocol := settings.Protocol
+	if protocol == 0 {
+		protocol = ReflectProtocolUnknown
+	}
+	resolver, closeResolver := NewServerReflectionResolver(
+		ctx,
+		// The verbose client restores the user-agent headers that are otherwise
+		// overwritten by the protocol.
+		NewVerboseHTTPClient(transport, printer),
+		[]connect.ClientOption{connect.WithGRPC()},
+		reflectURL,
+		protocol,
+		headers,
+		printer,
+	)
+	return resolver, closeResolver, nil
+}
+
 // NewServerReflectionResolver creates a new resolver using the given details to
 // create an RPC reflection client, to ask the server for descriptors.
 func NewServerReflectionResolver(
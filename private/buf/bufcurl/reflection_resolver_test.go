@@ -0,0 +1,115 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufcurl
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/bufbuild/buf/private/pkg/verbose"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+func TestNewServerReflectionResolverForURL(t *testing.T) {
+	t.Parallel()
+	var lock sync.Mutex
+	var paths []string
+	var headers []http.Header
+	server := httptest.NewServer(
+		h2c.NewHandler(
+			http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				lock.Lock()
+				paths = append(paths, request.URL.Path)
+				headers = append(headers, request.Header.Clone())
+				lock.Unlock()
+				http.NotFound(writer, request)
+			}),
+			&http2.Server{},
+		),
+	)
+	t.Cleanup(server.Close)
+	// popRequests returns and clears the paths and headers of the requests to the server.
+	popRequests := func() ([]string, []http.Header) {
+		lock.Lock()
+		defer lock.Unlock()
+		poppedPaths, poppedHeaders := paths, headers
+		paths, headers = nil, nil
+		return poppedPaths, poppedHeaders
+	}
+
+	resolver, closeResolver, err := NewServerReflectionResolverForURL(
+		context.Background(),
+		server.URL,
+		ReflectClientSettings{
+			Headers:    http.Header{"X-Foo": []string{"bar"}},
+			BufVersion: "1.2.3",
+		},
+		verbose.NopPrinter,
+	)
+	require.NoError(t, err)
+	_, err = resolver.ListServices()
+	closeResolver()
+	require.Error(t, err)
+	// Both protocols are tried, over HTTP/2 with prior knowledge.
+	requestPaths, requestHeaders := popRequests()
+	assert.Equal(
+		t,
+		[]string{
+			"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo",
+			"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo",
+		},
+		requestPaths,
+	)
+	for _, requestHeader := range requestHeaders {
+		assert.Equal(t, "bar", requestHeader.Get("X-Foo"))
+		assert.Contains(t, requestHeader.Get("User-Agent"), "buf/1.2.3")
+	}
+
+	resolver, closeResolver, err = NewServerReflectionResolverForURL(
+		context.Background(),
+		server.URL,
+		ReflectClientSettings{
+			Protocol: ReflectProtocolGRPCV1,
+			Headers:  http.Header{"User-Agent": []string{"custom"}},
+		},
+		verbose.NopPrinter,
+	)
+	require.NoError(t, err)
+	_, err = resolver.ListServices()
+	closeResolver()
+	require.Error(t, err)
+	requestPaths, requestHeaders = popRequests()
+	assert.Equal(t, []string{"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo"}, requestPaths)
+	require.Len(t, requestHeaders, 1)
+	assert.Equal(t, "custom", requestHeaders[0].Get("User-Agent"))
+}
+
+func TestNewServerReflectionResolverForURLInvalidScheme(t *testing.T) {
+	t.Parallel()
+	_, _, err := NewServerReflectionResolverForURL(
+		context.Background(),
+		"ftp://localhost:1234",
+		ReflectClientSettings{},
+		verbose.NopPrinter,
+	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "URL must have a scheme of http or https")
+}
@@ -16,13 +16,20 @@ package bufcurl
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
 	"github.com/bufbuild/buf/private/gen/data/datawkt"
 	"github.com/bufbuild/buf/private/pkg/protoencoding"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/reflect/protodesc"
 	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
 )
 
 // Resolver is used to resolve descriptors, types, extensions, etc. Unlike
@@ -40,6 +47,83 @@ func ResolverForImage(image bufimage.Image) Resolver {
 	}
 }
 
+// ImageForResolver returns an Image containing the files that define the
+// services listed by the given Resolver, along with all of their transitive
+// dependencies.
+//
+// The services of the gRPC server reflection protocol itself are ignored.
+// Files for the well-known types are marked as imports. Files without source code
+// info are given an empty SourceCodeInfo.
+func ImageForResolver(res Resolver) (bufimage.Image, error) {
+	serviceNames, err := res.ListServices()
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(serviceNames)
+	var imageFiles []bufimage.ImageFile
+	seenPaths := make(map[string]struct{})
+	var addFile func(protoreflect.FileDescriptor) error
+	addFile = func(fileDescriptor protoreflect.FileDescriptor) error {
+		path := fileDescriptor.Path()
+		if _, ok := seenPaths[path]; ok {
+			return nil
+		}
+		seenPaths[path] = struct{}{}
+		// Dependencies must come before the files that import them.
+		imports := fileDescriptor.Imports()
+		for i := range imports.Len() {
+			importDescriptor := imports.Get(i)
+			if importDescriptor.IsPlaceholder() {
+				return fmt.Errorf("file %q imports %q, which could not be resolved", path, importDescriptor.Path())
+			}
+			if err := addFile(importDescriptor.FileDescriptor); err != nil {
+				return err
+			}
+		}
+		fileDescriptorProto := protodesc.ToFileDescriptorProto(fileDescriptor)
+		if fileDescriptorProto.SourceCodeInfo == nil {
+			// Servers usually strip source code info. Lint and breaking change checks
+			// require it to be present, even if empty.
+			fileDescriptorProto.SourceCodeInfo = &descriptorpb.SourceCodeInfo{}
+		}
+		imageFile, err := bufimage.NewImageFile(
+			fileDescriptorProto,
+			nil,
+			uuid.Nil,
+			"",
+			"",
+			datawkt.Exists(path),
+			false,
+			nil,
+		)
+		if err != nil {
+			return err
+		}
+		imageFiles = append(imageFiles, imageFile)
+		return nil
+	}
+	for _, serviceName := range serviceNames {
+		if strings.HasPrefix(string(serviceName), "grpc.reflection.") {
+			continue
+		}
+		descriptor, err := res.FindDescriptorByName(serviceName)
+		if err != nil {
+			return nil, err
+		}
+		serviceDescriptor, ok := descriptor.(protoreflect.ServiceDescriptor)
+		if !ok {
+			return nil, fmt.Errorf("element %s is a %s, not a service", serviceName, descriptorKind(descriptor))
+		}
+		if err := addFile(serviceDescriptor.ParentFile()); err != nil {
+			return nil, err
+		}
+	}
+	if len(imageFiles) == 0 {
+		return nil, errors.New("no services found")
+	}
+	return bufimage.NewImage(imageFiles)
+}
+
 // CombineResolvers returns a Resolver backed by the given underlying
 // resolvers. For any given query, each underlying resolver is checked,
 // starting with the first resolver provided. If the first cannot answer
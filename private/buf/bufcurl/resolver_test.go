@@ -0,0 +1,116 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufcurl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/private/pkg/protoencoding"
+	"github.com/bufbuild/protocompile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestImageForResolver(t *testing.T) {
+	t.Parallel()
+	res := testNewStaticResolver(
+		t,
+		"grpc.reflection.v1.ServerReflection",
+		"foo.v1.EventService",
+	)
+	image, err := ImageForResolver(res)
+	require.NoError(t, err)
+	var paths []string
+	var importPaths []string
+	for _, imageFile := range image.Files() {
+		paths = append(paths, imageFile.Path())
+		if imageFile.IsImport() {
+			importPaths = append(importPaths, imageFile.Path())
+		}
+	}
+	// Dependencies come before the files that import them.
+	assert.Equal(
+		t,
+		[]string{
+			"google/protobuf/timestamp.proto",
+			"types.proto",
+			"service.proto",
+		},
+		paths,
+	)
+	assert.Equal(t, []string{"google/protobuf/timestamp.proto"}, importPaths)
+	assert.NotNil(t, image.GetFile("service.proto").FileDescriptorProto().GetService())
+}
+
+func TestImageForResolverNoServices(t *testing.T) {
+	t.Parallel()
+	res := testNewStaticResolver(t, "grpc.reflection.v1alpha.ServerReflection")
+	_, err := ImageForResolver(res)
+	require.EqualError(t, err, "no services found")
+}
+
+func TestImageForResolverNotAService(t *testing.T) {
+	t.Parallel()
+	res := testNewStaticResolver(t, "foo.v1.Event")
+	_, err := ImageForResolver(res)
+	require.EqualError(t, err, "element foo.v1.Event is a message, not a service")
+}
+
+type staticResolver struct {
+	protoencoding.Resolver
+	serviceNames []protoreflect.FullName
+}
+
+func (s *staticResolver) ListServices() ([]protoreflect.FullName, error) {
+	return s.serviceNames, nil
+}
+
+// testNewStaticResolver returns a Resolver for testdata/reflect/service.proto that lists the
+// given service names, whether or not they can be resolved.
+func testNewStaticResolver(t *testing.T, serviceNames ...protoreflect.FullName) Resolver {
+	descriptors, err := (&protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(
+			&protocompile.SourceResolver{
+				ImportPaths: []string{"./testdata/reflect"},
+			},
+		),
+	}).Compile(context.Background(), "service.proto")
+	require.NoError(t, err)
+	var fileDescriptorProtos []*descriptorpb.FileDescriptorProto
+	seenPaths := make(map[string]struct{})
+	var addFile func(protoreflect.FileDescriptor)
+	addFile = func(fileDescriptor protoreflect.FileDescriptor) {
+		if _, ok := seenPaths[fileDescriptor.Path()]; ok {
+			return
+		}
+		seenPaths[fileDescriptor.Path()] = struct{}{}
+		imports := fileDescriptor.Imports()
+		for i := range imports.Len() {
+			addFile(imports.Get(i).FileDescriptor)
+		}
+		fileDescriptorProtos = append(fileDescriptorProtos, protodesc.ToFileDescriptorProto(fileDescriptor))
+	}
+	addFile(descriptors[0])
+	res, err := protoencoding.NewResolver(fileDescriptorProtos...)
+	require.NoError(t, err)
+	return &staticResolver{
+		Resolver:     res,
+		serviceNames: serviceNames,
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bufeditions migrates proto2 and proto3 files to editions.
+package bufeditions
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+
+	"github.com/bufbuild/buf/private/buf/bufformat"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck"
+	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
+	"github.com/bufbuild/buf/private/pkg/wasm"
+	"github.com/bufbuild/protocompile"
+	"github.com/bufbuild/protocompile/linker"
+	"github.com/bufbuild/protocompile/parser"
+	"github.com/bufbuild/protocompile/protoutil"
+	"github.com/bufbuild/protocompile/reporter"
+)
+
+// Edition is the edition that files are migrated to.
+const Edition = "2023"
+
+// Migrate migrates the proto2 and proto3 files of the image that are not imports to
+// edition 2023, and returns a new bucket with the migrated files, formatted as with
+// bufformat.
+//
+// The source of the files is read from the bucket, files that are not in the bucket
+// are not migrated. Features are added where needed for the migrated files to have the
+// same semantics as the original files. A feature is set for the whole file when this
+// needs fewer options than setting it on each element.
+//
+// Files that already use editions are not migrated. Groups in oneofs and extend blocks
+// cannot be migrated, and return an error.
+func Migrate(ctx context.Context, image bufimage.Image, readBucket storage.ReadBucket) (storage.ReadBucket, error) {
+	readWriteBucket := storagemem.NewReadWriteBucket()
+	for _, imageFile := range image.Files() {
+		if imageFile.IsImport() {
+			continue
+		}
+		fileDescriptor := imageFile.FileDescriptorProto()
+		if syntax := fileDescriptor.GetSyntax(); syntax != "" && syntax != "proto2" && syntax != "proto3" {
+			continue
+		}
+		data, externalPath, err := readPathAndExternalPath(ctx, readBucket, imageFile.Path())
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		fileNode, err := parser.Parse(externalPath, bytes.NewReader(data), reporter.NewHandler(nil))
+		if err != nil {
+			return nil, err
+		}
+		migratedData, err := migrateFile(fileNode, fileDescriptor, data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", externalPath, err)
+		}
+		migratedFileNode, err := parser.Parse(externalPath, bytes.NewReader(migratedData), reporter.NewHandler(nil))
+		if err != nil {
+			return nil, fmt.Errorf("could not parse migrated %q: %w", externalPath, err)
+		}
+		writeObjectCloser, err := readWriteBucket.Put(ctx, imageFile.Path())
+		if err != nil {
+			return nil, err
+		}
+		if err := bufformat.FormatFileNode(writeObjectCloser, migratedFileNode); err != nil {
+			return nil, errors.Join(err, writeObjectCloser.Close())
+		}
+		if err := writeObjectCloser.SetExternalPath(externalPath); err != nil {
+			return nil, errors.Join(err, writeObjectCloser.Close())
+		}
+		if err := writeObjectCloser.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return readWriteBucket, nil
+}
+
+func readPathAndExternalPath(ctx context.Context, readBucket storage.ReadBucket, path string) (_ []byte, _ string, retErr error) {
+	readObjectCloser, err := readBucket.Get(ctx, path)
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() {
+		retErr = errors.Join(retErr, readObjectCloser.Close())
+	}()
+	data, err := io.ReadAll(readObjectCloser)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, readObjectCloser.ExternalPath(), nil
+}
+
+// BuildMigratedImage builds the migrated files in the bucket, and returns a copy of
+// the image with its files replaced by the built files with the same paths.
+//
+// This is used to verify that the migrated files have the same semantics as the
+// files of the image.
+func BuildMigratedImage(ctx context.Context, image bufimage.Image, migratedReadBucket storage.ReadBucket) (bufimage.Image, error) {
+	migratedPaths, err := storage.AllPaths(ctx, migratedReadBucket, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(migratedPaths) == 0 {
+		return image, nil
+	}
+	compiler := protocompile.Compiler{
+		SourceInfoMode: protocompile.SourceInfoStandard,
+		Resolver: protocompile.CompositeResolver{
+			&protocompile.SourceResolver{
+				Accessor: func(path string) (io.ReadCloser, error) {
+					return migratedReadBucket.Get(ctx, path)
+				},
+			},
+			protocompile.ResolverFunc(
+				func(path string) (protocompile.SearchResult, error) {
+					imageFile := image.GetFile(path)
+					if imageFile == nil {
+						return protocompile.SearchResult{}, fs.ErrNotExist
+					}
+					return protocompile.SearchResult{Proto: imageFile.FileDescriptorProto()}, nil
+				},
+			),
+		},
+	}
+	compiledFiles, err := compiler.Compile(ctx, migratedPaths...)
+	if err != nil {
+		return nil, fmt.Errorf("could not build migrated files: %w", err)
+	}
+	pathToCompiledFile := make(map[string]linker.File, len(compiledFiles))
+	for _, compiledFile := range compiledFiles {
+		pathToCompiledFile[compiledFile.Path()] = compiledFile
+	}
+	imageFiles := make([]bufimage.ImageFile, 0, len(image.Files()))
+	for _, imageFile := range image.Files() {
+		compiledFile, ok := pathToCompiledFile[imageFile.Path()]
+		if !ok {
+			imageFiles = append(imageFiles, imageFile)
+			continue
+		}
+		migratedImageFile, err := bufimage.NewImageFile(
+			protoutil.ProtoFromFileDescriptor(compiledFile),
+			imageFile.FullName(),
+			imageFile.CommitID(),
+			imageFile.ExternalPath(),
+			imageFile.LocalPath(),
+			imageFile.IsImport(),
+			false,
+			nil,
+		)
+		if err != nil {
+			return nil, err
+		}
+		imageFiles = append(imageFiles, migratedImageFile)
+	}
+	return bufimage.NewImage(imageFiles)
+}
+
+// CheckMigratedImage checks that the migrated image has the same semantics as the image,
+// using the breaking change rules of the WIRE_JSON category.
+//
+// Returns a bufanalysis.FileAnnotationSet error if the migrated image is not equivalent.
+func CheckMigratedImage(
+	ctx context.Context,
+	logger *slog.Logger,
+	image bufimage.Image,
+	migratedImage bufimage.Image,
+) error {
+	client, err := bufcheck.NewClient(logger, bufcheck.NewRunnerProvider(wasm.UnimplementedRuntime))
+	if err != nil {
+		return err
+	}
+	breakingConfig := bufconfig.NewBreakingConfig(
+		bufconfig.NewEnabledCheckConfigForUseIDsAndCategories(
+			bufconfig.FileVersionV2,
+			[]string{"WIRE_JSON"},
+			false,
+		),
+		false,
+		false,
+		false,
+	)
+	return client.Breaking(
+		ctx,
+		breakingConfig,
+		migratedImage,
+		image,
+		bufcheck.BreakingWithExcludeImports(),
+	)
+}
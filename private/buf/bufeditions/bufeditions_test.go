@@ -0,0 +1,93 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufeditions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduletesting"
+	"github.com/bufbuild/buf/private/pkg/diff"
+	"github.com/bufbuild/buf/private/pkg/slogtestext"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	image, readBucket := testBuildImage(t, "testdata/input")
+	migratedReadBucket, err := Migrate(ctx, image, readBucket)
+	require.NoError(t, err)
+	migratedPaths, err := storage.AllPaths(ctx, migratedReadBucket, "")
+	require.NoError(t, err)
+	// Files that already use editions are not migrated.
+	require.Equal(
+		t,
+		[]string{
+			"acme/optional.proto",
+			"acme/proto2.proto",
+			"acme/proto3.proto",
+		},
+		migratedPaths,
+	)
+	goldenBucket, err := storageos.NewProvider().NewReadWriteBucket("testdata/golden")
+	require.NoError(t, err)
+	for _, migratedPath := range migratedPaths {
+		migratedData, err := storage.ReadPath(ctx, migratedReadBucket, migratedPath)
+		require.NoError(t, err)
+		expectedData, err := storage.ReadPath(ctx, goldenBucket, migratedPath)
+		require.NoError(t, err)
+		fileDiff, err := diff.Diff(ctx, expectedData, migratedData, migratedPath, migratedPath+" (migrated)")
+		require.NoError(t, err)
+		require.Empty(t, string(fileDiff))
+	}
+	migratedImage, err := BuildMigratedImage(ctx, image, migratedReadBucket)
+	require.NoError(t, err)
+	for _, imageFile := range migratedImage.Files() {
+		require.Equal(t, "editions", imageFile.FileDescriptorProto().GetSyntax(), imageFile.Path())
+	}
+	require.NoError(t, CheckMigratedImage(ctx, slogtestext.NewLogger(t), image, migratedImage))
+	// The migrated files are not migrated again.
+	goldenImage, goldenReadBucket := testBuildImage(t, "testdata/golden")
+	migratedReadBucket, err = Migrate(ctx, goldenImage, goldenReadBucket)
+	require.NoError(t, err)
+	migratedPaths, err = storage.AllPaths(ctx, migratedReadBucket, "")
+	require.NoError(t, err)
+	require.Empty(t, migratedPaths)
+}
+
+func TestMigrateGroupInOneof(t *testing.T) {
+	t.Parallel()
+	image, readBucket := testBuildImage(t, "testdata/group")
+	_, err := Migrate(context.Background(), image, readBucket)
+	require.ErrorContains(t, err, `group "Value" in a oneof or extend block cannot be migrated`)
+}
+
+func testBuildImage(t *testing.T, dirPath string) (bufimage.Image, storage.ReadBucket) {
+	moduleSet, err := bufmoduletesting.NewModuleSetForDirPath(dirPath)
+	require.NoError(t, err)
+	moduleReadBucket := bufmodule.ModuleSetToModuleReadBucketWithOnlyProtoFiles(moduleSet)
+	image, err := bufimage.BuildImage(
+		context.Background(),
+		slogtestext.NewLogger(t),
+		moduleReadBucket,
+	)
+	require.NoError(t, err)
+	return image, bufmodule.ModuleReadBucketToStorageReadBucket(moduleReadBucket)
+}
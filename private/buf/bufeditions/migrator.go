@@ -0,0 +1,490 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufeditions
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bufbuild/buf/private/pkg/syserror"
+	"github.com/bufbuild/protocompile/ast"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// migrateFile returns the source of the file migrated to editions.
+//
+// The source is edited in place, so that comments and formatting are kept, and the
+// result is expected to be formatted afterwards.
+func migrateFile(
+	fileNode *ast.FileNode,
+	fileDescriptor *descriptorpb.FileDescriptorProto,
+	data []byte,
+) ([]byte, error) {
+	fileMigrator := newFileMigrator(fileNode, fileDescriptor, data)
+	if err := fileMigrator.collect(); err != nil {
+		return nil, err
+	}
+	fileMigrator.computeFeatures()
+	if err := fileMigrator.computeEdits(); err != nil {
+		return nil, err
+	}
+	return fileMigrator.apply()
+}
+
+type fileMigrator struct {
+	fileNode       *ast.FileNode
+	fileDescriptor *descriptorpb.FileDescriptorProto
+	data           []byte
+	isProto3       bool
+	nameToField    map[string]*descriptorpb.FieldDescriptorProto
+
+	// fieldElements are the fields, groups and map fields of the file.
+	fieldElements []*fieldElement
+	enumNodes     []*ast.EnumNode
+	hasMessages   bool
+
+	fileFeatures []string
+	// nodeToFeatures are the features to set on fields, groups, map fields and enums.
+	nodeToFeatures map[ast.Node][]string
+	edits          []*edit
+}
+
+type fieldElement struct {
+	// node is a *ast.FieldNode, *ast.GroupNode or *ast.MapFieldNode.
+	node  ast.Node
+	field *descriptorpb.FieldDescriptorProto
+}
+
+type edit struct {
+	start int
+	end   int
+	text  string
+}
+
+func newFileMigrator(
+	fileNode *ast.FileNode,
+	fileDescriptor *descriptorpb.FileDescriptorProto,
+	data []byte,
+) *fileMigrator {
+	nameToField := make(map[string]*descriptorpb.FieldDescriptorProto)
+	addFieldsForMessages(nameToField, fileDescriptor.GetPackage(), fileDescriptor.GetMessageType())
+	for _, extension := range fileDescriptor.GetExtension() {
+		nameToField[joinName(fileDescriptor.GetPackage(), extension.GetName())] = extension
+	}
+	return &fileMigrator{
+		fileNode:       fileNode,
+		fileDescriptor: fileDescriptor,
+		data:           data,
+		isProto3:       fileDescriptor.GetSyntax() == "proto3",
+		nameToField:    nameToField,
+		nodeToFeatures: make(map[ast.Node][]string),
+	}
+}
+
+func (m *fileMigrator) collect() error {
+	for _, decl := range m.fileNode.Decls {
+		if err := m.collectNode(decl, m.fileDescriptor.GetPackage(), false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectNode collects the fields and enums of the node.
+//
+// inBlock is true if the node is in a oneof or extend block.
+func (m *fileMigrator) collectNode(node ast.Node, scope string, inBlock bool) error {
+	switch node := node.(type) {
+	case *ast.MessageNode:
+		m.hasMessages = true
+		return m.collectMessageBody(node.Decls, joinName(scope, node.Name.Val))
+	case *ast.EnumNode:
+		m.enumNodes = append(m.enumNodes, node)
+	case *ast.ExtendNode:
+		for _, decl := range node.Decls {
+			if err := m.collectNode(decl, scope, true); err != nil {
+				return err
+			}
+		}
+	case *ast.OneofNode:
+		for _, decl := range node.Decls {
+			if err := m.collectNode(decl, scope, true); err != nil {
+				return err
+			}
+		}
+	case *ast.FieldNode:
+		return m.addFieldElement(node, joinName(scope, node.Name.Val))
+	case *ast.MapFieldNode:
+		// Map entries are messages.
+		m.hasMessages = true
+		return m.addFieldElement(node, joinName(scope, node.Name.Val))
+	case *ast.GroupNode:
+		if inBlock {
+			return fmt.Errorf("group %q in a oneof or extend block cannot be migrated", node.Name.Val)
+		}
+		m.hasMessages = true
+		if err := m.addFieldElement(node, joinName(scope, strings.ToLower(node.Name.Val))); err != nil {
+			return err
+		}
+		return m.collectMessageBody(node.Decls, joinName(scope, node.Name.Val))
+	}
+	return nil
+}
+
+func (m *fileMigrator) collectMessageBody(decls []ast.MessageElement, scope string) error {
+	for _, decl := range decls {
+		if err := m.collectNode(decl, scope, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *fileMigrator) addFieldElement(node ast.Node, name string) error {
+	field, ok := m.nameToField[name]
+	if !ok {
+		return syserror.Newf("no descriptor for field %q", name)
+	}
+	m.fieldElements = append(m.fieldElements, &fieldElement{node: node, field: field})
+	return nil
+}
+
+// computeFeatures computes the features needed to keep the semantics of the file.
+func (m *fileMigrator) computeFeatures() {
+	var (
+		implicitPresence     []ast.Node
+		explicitPresence     []ast.Node
+		expandedEncoding     []ast.Node
+		packedEncoding       []ast.Node
+		noUTF8Validation     []ast.Node
+		forceImplicitForFile bool
+		forceNoUTF8ForFile   bool
+	)
+	for _, fieldElement := range m.fieldElements {
+		node := fieldElement.node
+		field := fieldElement.field
+		if mapFieldNode, ok := node.(*ast.MapFieldNode); ok {
+			// The fields of map entries cannot have options, so their
+			// features can only be set for the file.
+			if m.isProto3 {
+				forceImplicitForFile = true
+			} else if mapFieldNode.MapType.KeyType.Val == "string" || string(mapFieldNode.MapType.ValueType.AsIdentifier()) == "string" {
+				forceNoUTF8ForFile = true
+			}
+			continue
+		}
+		isMessage := field.GetType() == descriptorpb.FieldDescriptorProto_TYPE_MESSAGE ||
+			field.GetType() == descriptorpb.FieldDescriptorProto_TYPE_GROUP
+		switch field.GetLabel() {
+		case descriptorpb.FieldDescriptorProto_LABEL_REQUIRED:
+			m.nodeToFeatures[node] = append(m.nodeToFeatures[node], featureOption("field_presence", "LEGACY_REQUIRED"))
+		case descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL:
+			if m.isProto3 && !isMessage {
+				if field.GetProto3Optional() {
+					explicitPresence = append(explicitPresence, node)
+				} else if field.OneofIndex == nil && field.Extendee == nil {
+					implicitPresence = append(implicitPresence, node)
+				}
+			}
+		case descriptorpb.FieldDescriptorProto_LABEL_REPEATED:
+			if isPackable(field.GetType()) {
+				if isPacked(field, m.isProto3) {
+					packedEncoding = append(packedEncoding, node)
+				} else {
+					expandedEncoding = append(expandedEncoding, node)
+				}
+			}
+		}
+		if !m.isProto3 && field.GetType() == descriptorpb.FieldDescriptorProto_TYPE_STRING {
+			noUTF8Validation = append(noUTF8Validation, node)
+		}
+		if field.GetType() == descriptorpb.FieldDescriptorProto_TYPE_GROUP {
+			m.nodeToFeatures[node] = append(m.nodeToFeatures[node], featureOption("message_encoding", "DELIMITED"))
+		}
+	}
+	if m.isProto3 {
+		m.addFeature("field_presence", "IMPLICIT", "EXPLICIT", implicitPresence, explicitPresence, forceImplicitForFile)
+	}
+	m.addFeature("repeated_field_encoding", "EXPANDED", "PACKED", expandedEncoding, packedEncoding, false)
+	if !m.isProto3 {
+		m.addFeature("utf8_validation", "NONE", "VERIFY", noUTF8Validation, nil, forceNoUTF8ForFile)
+		enumNodes := make([]ast.Node, len(m.enumNodes))
+		for i, enumNode := range m.enumNodes {
+			enumNodes[i] = enumNode
+		}
+		m.addFeature("enum_type", "CLOSED", "OPEN", enumNodes, nil, false)
+		if m.hasMessages || len(m.enumNodes) > 0 {
+			m.fileFeatures = append(m.fileFeatures, featureOption("json_format", "LEGACY_BEST_EFFORT"))
+		}
+	}
+}
+
+// addFeature sets the feature to value on the nodes that need value, and to
+// defaultValue on the nodes that need the default value of edition 2023.
+//
+// The feature is set for the file instead if this needs fewer options, or if
+// forceFile is true.
+func (m *fileMigrator) addFeature(
+	name string,
+	value string,
+	defaultValue string,
+	valueNodes []ast.Node,
+	defaultValueNodes []ast.Node,
+	forceFile bool,
+) {
+	if len(valueNodes) == 0 && !forceFile {
+		return
+	}
+	if forceFile || len(defaultValueNodes)+1 < len(valueNodes) {
+		m.fileFeatures = append(m.fileFeatures, featureOption(name, value))
+		for _, node := range defaultValueNodes {
+			m.nodeToFeatures[node] = append(m.nodeToFeatures[node], featureOption(name, defaultValue))
+		}
+		return
+	}
+	for _, node := range valueNodes {
+		m.nodeToFeatures[node] = append(m.nodeToFeatures[node], featureOption(name, value))
+	}
+}
+
+func (m *fileMigrator) computeEdits() error {
+	editionDecl := fmt.Sprintf("edition = %q;", Edition)
+	var fileOptionsAnchor ast.Node
+	for _, decl := range m.fileNode.Decls {
+		switch decl.(type) {
+		case *ast.PackageNode, *ast.ImportNode, *ast.OptionNode:
+			fileOptionsAnchor = decl
+		}
+	}
+	var fileOptions string
+	for _, fileFeature := range m.fileFeatures {
+		fileOptions += "\n\noption " + fileFeature + ";"
+	}
+	switch {
+	case m.fileNode.Syntax != nil:
+		m.replace(m.fileNode.Syntax, editionDecl)
+		if fileOptionsAnchor == nil {
+			fileOptionsAnchor = m.fileNode.Syntax
+		}
+	case len(m.fileNode.Decls) > 0:
+		m.insert(m.start(m.fileNode.Decls[0]), editionDecl+"\n\n")
+	default:
+		m.insert(len(m.data), editionDecl+"\n")
+	}
+	if fileOptions != "" {
+		if fileOptionsAnchor != nil {
+			m.insert(m.end(fileOptionsAnchor), fileOptions)
+		} else {
+			m.insert(m.start(m.fileNode.Decls[0]), strings.TrimPrefix(fileOptions, "\n\n")+"\n\n")
+		}
+	}
+	for _, fieldElement := range m.fieldElements {
+		features := m.nodeToFeatures[fieldElement.node]
+		switch node := fieldElement.node.(type) {
+		case *ast.FieldNode:
+			if node.Label.KeywordNode != nil && !node.Label.Repeated {
+				m.edits = append(m.edits, &edit{start: m.start(node.Label.KeywordNode), end: m.start(node.FldType)})
+			}
+			m.editCompactOptions(node.Tag, node.Options, features)
+		case *ast.MapFieldNode:
+			m.editCompactOptions(node.Tag, node.Options, features)
+		case *ast.GroupNode:
+			m.editGroup(node, features)
+		default:
+			return syserror.Newf("unexpected field node type: %T", node)
+		}
+	}
+	for _, enumNode := range m.enumNodes {
+		for _, feature := range m.nodeToFeatures[enumNode] {
+			m.insert(m.end(enumNode.OpenBrace), "\noption "+feature+";")
+		}
+	}
+	return nil
+}
+
+// editCompactOptions removes the packed option from the options, and adds the features.
+//
+// The options are after the tag node.
+func (m *fileMigrator) editCompactOptions(tag ast.Node, options *ast.CompactOptionsNode, features []string) {
+	if options == nil {
+		if len(features) > 0 {
+			m.insert(m.end(tag), " ["+strings.Join(features, ", ")+"]")
+		}
+		return
+	}
+	keptOptions, removed := m.optionsWithoutPacked(options)
+	if !removed {
+		if len(features) > 0 {
+			m.insert(m.start(options.CloseBracket), ", "+strings.Join(features, ", "))
+		}
+		return
+	}
+	newOptions := append(keptOptions, features...)
+	if len(newOptions) == 0 {
+		m.edits = append(m.edits, &edit{start: m.end(tag), end: m.end(options)})
+		return
+	}
+	m.replace(options, "["+strings.Join(newOptions, ", ")+"]")
+}
+
+// editGroup replaces the group with a message and a delimited field of the message.
+func (m *fileMigrator) editGroup(groupNode *ast.GroupNode, features []string) {
+	var start int
+	if groupNode.Label.KeywordNode != nil {
+		start = m.start(groupNode.Label.KeywordNode)
+	} else {
+		start = m.start(groupNode.Keyword)
+	}
+	m.edits = append(
+		m.edits,
+		&edit{
+			start: start,
+			end:   m.start(groupNode.OpenBrace),
+			text:  "message " + groupNode.Name.Val + " ",
+		},
+	)
+	var options []string
+	if groupNode.Options != nil {
+		options, _ = m.optionsWithoutPacked(groupNode.Options)
+	}
+	options = append(options, features...)
+	var label string
+	if groupNode.Label.Repeated {
+		label = "repeated "
+	}
+	m.insert(
+		m.end(groupNode.CloseBrace),
+		fmt.Sprintf(
+			"\n%s%s %s = %s [%s];",
+			label,
+			groupNode.Name.Val,
+			strings.ToLower(groupNode.Name.Val),
+			m.text(groupNode.Tag),
+			strings.Join(options, ", "),
+		),
+	)
+}
+
+// optionsWithoutPacked returns the source of the options other than packed, which
+// is not allowed in editions, and whether packed was removed.
+func (m *fileMigrator) optionsWithoutPacked(options *ast.CompactOptionsNode) ([]string, bool) {
+	var keptOptions []string
+	var removed bool
+	for _, option := range options.Options {
+		if len(option.Name.Parts) == 1 &&
+			!option.Name.Parts[0].IsExtension() &&
+			string(option.Name.Parts[0].Name.AsIdentifier()) == "packed" {
+			removed = true
+			continue
+		}
+		keptOptions = append(keptOptions, m.text(option))
+	}
+	return keptOptions, removed
+}
+
+func (m *fileMigrator) apply() ([]byte, error) {
+	slices.SortStableFunc(
+		m.edits,
+		func(a *edit, b *edit) int {
+			return a.start - b.start
+		},
+	)
+	var builder strings.Builder
+	var offset int
+	for _, edit := range m.edits {
+		if edit.start < offset {
+			return nil, errors.New("overlapping edits")
+		}
+		builder.Write(m.data[offset:edit.start])
+		builder.WriteString(edit.text)
+		offset = edit.end
+	}
+	builder.Write(m.data[offset:])
+	return []byte(builder.String()), nil
+}
+
+func (m *fileMigrator) insert(offset int, text string) {
+	m.edits = append(m.edits, &edit{start: offset, end: offset, text: text})
+}
+
+func (m *fileMigrator) replace(node ast.Node, text string) {
+	m.edits = append(m.edits, &edit{start: m.start(node), end: m.end(node), text: text})
+}
+
+func (m *fileMigrator) text(node ast.Node) string {
+	return string(m.data[m.start(node):m.end(node)])
+}
+
+func (m *fileMigrator) start(node ast.Node) int {
+	return m.fileNode.NodeInfo(node).Start().Offset
+}
+
+func (m *fileMigrator) end(node ast.Node) int {
+	// The offset of End is the offset of the last character of the node,
+	// and all nodes that are edited are not empty.
+	return m.fileNode.NodeInfo(node).End().Offset + 1
+}
+
+func addFieldsForMessages(
+	nameToField map[string]*descriptorpb.FieldDescriptorProto,
+	scope string,
+	messages []*descriptorpb.DescriptorProto,
+) {
+	for _, message := range messages {
+		messageName := joinName(scope, message.GetName())
+		for _, field := range message.GetField() {
+			nameToField[joinName(messageName, field.GetName())] = field
+		}
+		for _, extension := range message.GetExtension() {
+			nameToField[joinName(messageName, extension.GetName())] = extension
+		}
+		addFieldsForMessages(nameToField, messageName, message.GetNestedType())
+	}
+}
+
+// isPackable returns true if repeated fields of the type can be packed.
+func isPackable(fieldType descriptorpb.FieldDescriptorProto_Type) bool {
+	switch fieldType {
+	case descriptorpb.FieldDescriptorProto_TYPE_STRING,
+		descriptorpb.FieldDescriptorProto_TYPE_BYTES,
+		descriptorpb.FieldDescriptorProto_TYPE_MESSAGE,
+		descriptorpb.FieldDescriptorProto_TYPE_GROUP:
+		return false
+	default:
+		return true
+	}
+}
+
+// isPacked returns true if the repeated field is packed. Fields are packed by
+// default in proto3, and expanded by default in proto2.
+func isPacked(field *descriptorpb.FieldDescriptorProto, isProto3 bool) bool {
+	if options := field.GetOptions(); options != nil && options.Packed != nil {
+		return options.GetPacked()
+	}
+	return isProto3
+}
+
+func featureOption(name string, value string) string {
+	return "features." + name + " = " + value
+}
+
+func joinName(scope string, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
@@ -42,6 +42,13 @@ const (
 	MessageEncodingTxtpb
 	// MessageEncodingYAML is the YAML message encoding.
 	MessageEncodingYAML
+	// MessageEncodingRaw is the raw message encoding.
+	//
+	// When reading, this is binary data that is decoded without a schema. When writing,
+	// this is the text representation of the binary data as printed by protoc --decode_raw.
+	//
+	// This is only supported by the MessageRefParser.
+	MessageEncodingRaw
 
 	useProtoNamesKey  = "use_proto_names"
 	useEnumNumbersKey = "use_enum_numbers"
@@ -141,6 +148,15 @@ type ModuleRef interface {
 	internalModuleRef() internal.ModuleRef
 }
 
+// ReflectRef is a reference to a server that supports gRPC server reflection.
+//
+// The schema is downloaded from the server and turned into an image.
+type ReflectRef interface {
+	Ref
+	// URL returns the http or https base URL of the server.
+	URL() string
+}
+
 // ProtoFileRef is a proto file reference.
 type ProtoFileRef interface {
 	SourceRef
@@ -163,6 +179,15 @@ type MessageRefParser interface {
 	) (MessageRef, error)
 }
 
+// ImageOutputRefParser is a ref parser for the outputs of images.
+type ImageOutputRefParser interface {
+	// GetImageOutputRef gets the reference for the image output.
+	//
+	// The reference is a MessageRef, or a DirRef if the format is dir, in which
+	// case the image is written as .proto source files.
+	GetImageOutputRef(ctx context.Context, value string) (Ref, error)
+}
+
 // SourceRefParser is a source ref parser for Buf.
 type SourceRefParser interface {
 	// GetSourceRef gets the reference for the source file.
@@ -252,6 +277,13 @@ func NewMessageRefParser(logger *slog.Logger, options ...MessageRefParserOption)
 	return newMessageRefParser(logger, options...)
 }
 
+// NewImageOutputRefParser returns a new ImageOutputRefParser.
+//
+// This defaults to binpb, the dir format must be specified explicitly.
+func NewImageOutputRefParser(logger *slog.Logger) ImageOutputRefParser {
+	return newImageOutputRefParser(logger)
+}
+
 // MessageRefParserOption is an option for a new MessageRefParser.
 type MessageRefParserOption func(*messageRefParserOptions)
 
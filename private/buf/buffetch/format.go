@@ -35,6 +35,12 @@ const (
 	formatZip = "zip"
 	// formatProtoFile is the proto file format.
 	formatProtoFile = "protofile"
+	// formatReflect is the gRPC server reflection format.
+	formatReflect = "reflect"
+	// formatRaw is the schema-less binary format.
+	//
+	// This is only supported by the MessageRefParser.
+	formatRaw = "raw"
 
 	// formatBin is the binary format's old form, now deprecated.
 	formatBin = "bin"
@@ -54,6 +60,18 @@ var (
 		formatBingz,
 		formatJSON,
 		formatJSONGZ,
+		formatRaw,
+		formatTxtpb,
+		formatYAML,
+	}
+	// sorted
+	imageOutputFormats = []string{
+		formatBin,
+		formatBinpb,
+		formatBingz,
+		formatDir,
+		formatJSON,
+		formatJSONGZ,
 		formatTxtpb,
 		formatYAML,
 	}
@@ -134,6 +152,7 @@ var (
 		formatJSONGZ,
 		formatMod,
 		formatProtoFile,
+		formatReflect,
 		formatTar,
 		formatTargz,
 		formatTxtpb,
@@ -148,6 +167,7 @@ var (
 		formatJSON,
 		formatMod,
 		formatProtoFile,
+		formatReflect,
 		formatTar,
 		formatTxtpb,
 		formatYAML,
@@ -165,5 +185,6 @@ var (
 		MessageEncodingJSON:  formatJSON,
 		MessageEncodingTxtpb: formatTxtpb,
 		MessageEncodingYAML:  formatYAML,
+		MessageEncodingRaw:   formatRaw,
 	}
 )
@@ -228,6 +228,19 @@ type ModuleRef interface {
 	moduleRef()
 }
 
+// ReflectRef is a reference to a server that supports gRPC server reflection.
+type ReflectRef interface {
+	Ref
+	// Path is the path to the reference, as given by the user.
+	Path() string
+	// URL is the http or https base URL of the server.
+	//
+	// This is the path with any grpc+reflect:// or grpcs+reflect:// scheme
+	// replaced by http:// or https:// respectively.
+	URL() string
+	reflectRef()
+}
+
 // HasFormat is an object that has a format.
 type HasFormat interface {
 	Format() string
@@ -384,17 +397,38 @@ func NewDirectParsedModuleRef(
 	)
 }
 
+// ParsedReflectRef is a parsed ReflectRef.
+type ParsedReflectRef interface {
+	ReflectRef
+	HasFormat
+}
+
+// NewDirectParsedReflectRef returns a new ParsedReflectRef with no validation checks.
+//
+// This should only be used for testing.
+func NewDirectParsedReflectRef(
+	format string,
+	path string,
+	url string,
+) ParsedReflectRef {
+	return newDirectReflectRef(
+		format,
+		path,
+		url,
+	)
+}
+
 // RefParser parses references.
 type RefParser interface {
 	// GetParsedRef gets the ParsedRef for the value.
 	//
-	// The returned ParsedRef will be either a ParsedSingleRef, ParsedArchiveRef, ParsedDirRef, ParsedGitRef, or ParsedModuleRef.
+	// The returned ParsedRef will be either a ParsedSingleRef, ParsedArchiveRef, ParsedDirRef, ParsedGitRef, ParsedModuleRef, or ParsedReflectRef.
 	//
 	// The options should be used to validate that you are getting one of the correct formats.
 	GetParsedRef(ctx context.Context, value string, options ...GetParsedRefOption) (ParsedRef, error)
 	// GetParsedRefForInputConfig gets the ParsedRef for the input config.
 	//
-	// The returned ParsedRef will be either a ParsedSingleRef, ParsedArchiveRef, ParsedDirRef, ParsedGitRef, or ParsedModuleRef.
+	// The returned ParsedRef will be either a ParsedSingleRef, ParsedArchiveRef, ParsedDirRef, ParsedGitRef, ParsedModuleRef, or ParsedReflectRef.
 	//
 	// The options should be used to validate that you are getting one of the correct formats.
 	GetParsedRefForInputConfig(ctx context.Context, inputConfig bufconfig.InputConfig, options ...GetParsedRefOption) (ParsedRef, error)
@@ -685,6 +719,23 @@ func WithProtoFileFormat(format string, options ...ProtoFileFormatOption) RefPar
 	}
 }
 
+// WithReflectFormat attaches the given format as a server reflection format.
+//
+// It is up to the user to not incorrectly attach a format twice.
+func WithReflectFormat(format string, options ...ReflectFormatOption) RefParserOption {
+	return func(refParser *refParser) {
+		format = normalizeFormat(format)
+		if format == "" {
+			return
+		}
+		reflectFormatInfo := newReflectFormatInfo()
+		for _, option := range options {
+			option(reflectFormatInfo)
+		}
+		refParser.reflectFormatToInfo[format] = reflectFormatInfo
+	}
+}
+
 // SingleFormatOption is a single format option.
 type SingleFormatOption func(*singleFormatInfo)
 
@@ -723,6 +774,9 @@ type GitFormatOption func(*gitFormatInfo)
 // ModuleFormatOption is a module format option.
 type ModuleFormatOption func(*moduleFormatInfo)
 
+// ReflectFormatOption is a server reflection format option.
+type ReflectFormatOption func(*reflectFormatInfo)
+
 // ReaderOption is a Reader option.
 type ReaderOption func(*reader)
 
@@ -929,6 +983,10 @@ func GetInputConfigForRef(ref Ref, value string) (bufconfig.InputConfig, error)
 			t.Path(),
 			t.IncludePackageFiles(),
 		)
+	case ReflectRef:
+		return bufconfig.NewReflectInputConfig(
+			t.Path(),
+		)
 	case GitRef:
 		return bufconfig.NewGitRepoInputConfig(
 			t.Path(),
@@ -37,6 +37,7 @@ type refParser struct {
 	gitFormatToInfo       map[string]*gitFormatInfo
 	moduleFormatToInfo    map[string]*moduleFormatInfo
 	protoFileFormatToInfo map[string]*protoFileFormatInfo
+	reflectFormatToInfo   map[string]*reflectFormatInfo
 }
 
 func newRefParser(logger *slog.Logger, options ...RefParserOption) *refParser {
@@ -48,6 +49,7 @@ func newRefParser(logger *slog.Logger, options ...RefParserOption) *refParser {
 		gitFormatToInfo:       make(map[string]*gitFormatInfo),
 		moduleFormatToInfo:    make(map[string]*moduleFormatInfo),
 		protoFileFormatToInfo: make(map[string]*protoFileFormatInfo),
+		reflectFormatToInfo:   make(map[string]*reflectFormatInfo),
 	}
 	for _, option := range options {
 		option(refParser)
@@ -233,6 +235,8 @@ func (a *refParser) getRawRefForInputConfig(
 		rawRef.Format = "txtpb"
 	case bufconfig.InputConfigTypeYAMLImage:
 		rawRef.Format = "yaml"
+	case bufconfig.InputConfigTypeReflect:
+		rawRef.Format = "reflect"
 	default:
 		return nil, syserror.Newf("unknown InputConfigType: %v", inputConfig.Type())
 	}
@@ -287,7 +291,8 @@ func (a *refParser) parseRawRef(
 	_, gitOK := a.gitFormatToInfo[rawRef.Format]
 	_, moduleOK := a.moduleFormatToInfo[rawRef.Format]
 	_, protoFileOK := a.protoFileFormatToInfo[rawRef.Format]
-	if !(singleOK || archiveOK || dirOK || gitOK || moduleOK || protoFileOK) {
+	_, reflectOK := a.reflectFormatToInfo[rawRef.Format]
+	if !(singleOK || archiveOK || dirOK || gitOK || moduleOK || protoFileOK || reflectOK) {
 		return nil, NewFormatUnknownError(rawRef.Format)
 	}
 	if len(allowedFormats) > 0 {
@@ -324,6 +329,9 @@ func (a *refParser) parseRawRef(
 	if moduleOK {
 		return getModuleRef(rawRef)
 	}
+	if reflectOK {
+		return getReflectRef(rawRef)
+	}
 	return nil, NewFormatUnknownError(rawRef.Format)
 }
 
@@ -531,6 +539,15 @@ func getModuleRef(
 	)
 }
 
+func getReflectRef(
+	rawRef *RawRef,
+) (ParsedReflectRef, error) {
+	return newReflectRef(
+		rawRef.Format,
+		rawRef.Path,
+	)
+}
+
 func getGitRefName(path string, branch string, commitOrTag string, ref string) (git.Name, error) {
 	if branch == "" && commitOrTag == "" && ref == "" {
 		return nil, nil
@@ -607,6 +624,12 @@ func newModuleFormatInfo() *moduleFormatInfo {
 	return &moduleFormatInfo{}
 }
 
+type reflectFormatInfo struct{}
+
+func newReflectFormatInfo() *reflectFormatInfo {
+	return &reflectFormatInfo{}
+}
+
 type getParsedRefOptions struct {
 	allowedFormats map[string]struct{}
 }
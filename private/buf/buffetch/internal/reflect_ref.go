@@ -0,0 +1,86 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"strings"
+)
+
+const (
+	reflectSchemeGRPC  = "grpc+reflect://"
+	reflectSchemeGRPCS = "grpcs+reflect://"
+)
+
+var (
+	_ ParsedReflectRef = &reflectRef{}
+)
+
+type reflectRef struct {
+	format string
+	path   string
+	url    string
+}
+
+func newReflectRef(
+	format string,
+	path string,
+) (*reflectRef, error) {
+	if path == "" {
+		return nil, NewNoPathError()
+	}
+	var url string
+	switch {
+	case strings.HasPrefix(path, reflectSchemeGRPC):
+		url = "http://" + strings.TrimPrefix(path, reflectSchemeGRPC)
+	case strings.HasPrefix(path, reflectSchemeGRPCS):
+		url = "https://" + strings.TrimPrefix(path, reflectSchemeGRPCS)
+	case strings.HasPrefix(path, "http://"), strings.HasPrefix(path, "https://"):
+		url = path
+	default:
+		return nil, NewInvalidPathError(format, path)
+	}
+	if strings.HasSuffix(url, "://") {
+		return nil, NewInvalidPathError(format, path)
+	}
+	return newDirectReflectRef(format, path, url), nil
+}
+
+func newDirectReflectRef(format string, path string, url string) *reflectRef {
+	return &reflectRef{
+		format: format,
+		path:   path,
+		url:    url,
+	}
+}
+
+func (r *reflectRef) Format() string {
+	return r.format
+}
+
+func (r *reflectRef) Path() string {
+	return r.path
+}
+
+func (r *reflectRef) URL() string {
+	return r.url
+}
+
+func (*reflectRef) ref()        {}
+func (*reflectRef) reflectRef() {}
+
+// HasReflectScheme returns true if the path starts with grpc+reflect:// or grpcs+reflect://.
+func HasReflectScheme(path string) bool {
+	return strings.HasPrefix(path, reflectSchemeGRPC) || strings.HasPrefix(path, reflectSchemeGRPCS)
+}
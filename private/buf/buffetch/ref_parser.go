@@ -85,6 +85,7 @@ func newRefParser(logger *slog.Logger) *refParser {
 			internal.WithDirFormat(formatDir),
 			internal.WithModuleFormat(formatMod),
 			internal.WithProtoFileFormat(formatProtoFile),
+			internal.WithReflectFormat(formatReflect),
 		),
 	}
 }
@@ -98,32 +99,23 @@ func newMessageRefParser(logger *slog.Logger, options ...MessageRefParserOption)
 		logger: logger,
 		fetchRefParser: internal.NewRefParser(
 			logger,
-			internal.WithRawRefProcessor(newProcessRawRefMessage(messageRefParserOptions.defaultMessageEncoding)),
-			internal.WithSingleFormat(formatBin),
-			internal.WithSingleFormat(formatBinpb),
-			internal.WithSingleFormat(
-				formatJSON,
-				internal.WithSingleCustomOptionKey(useProtoNamesKey),
-				internal.WithSingleCustomOptionKey(useEnumNumbersKey),
-			),
-			internal.WithSingleFormat(formatTxtpb),
-			internal.WithSingleFormat(
-				formatYAML,
-				internal.WithSingleCustomOptionKey(useProtoNamesKey),
-				internal.WithSingleCustomOptionKey(useEnumNumbersKey),
-			),
-			internal.WithSingleFormat(
-				formatBingz,
-				internal.WithSingleDefaultCompressionType(
-					internal.CompressionTypeGzip,
-				),
-			),
-			internal.WithSingleFormat(
-				formatJSONGZ,
-				internal.WithSingleDefaultCompressionType(
-					internal.CompressionTypeGzip,
-				),
-			),
+			append(
+				getMessageRefParserOptions(messageRefParserOptions.defaultMessageEncoding),
+				internal.WithSingleFormat(formatRaw),
+			)...,
+		),
+	}
+}
+
+func newImageOutputRefParser(logger *slog.Logger) *refParser {
+	return &refParser{
+		logger: logger,
+		fetchRefParser: internal.NewRefParser(
+			logger,
+			append(
+				getMessageRefParserOptions(MessageEncodingBinpb),
+				internal.WithDirFormat(formatDir),
+			)...,
 		),
 	}
 }
@@ -244,6 +236,8 @@ func (a *refParser) GetRef(
 		return newModuleRef(t), nil
 	case internal.ProtoFileRef:
 		return newProtoFileRef(t), nil
+	case internal.ParsedReflectRef:
+		return newReflectRef(t), nil
 	default:
 		return nil, fmt.Errorf("unknown ParsedRef type: %T", parsedRef)
 	}
@@ -274,6 +268,8 @@ func (a *refParser) GetRefForInputConfig(
 		return newModuleRef(t), nil
 	case internal.ProtoFileRef:
 		return newProtoFileRef(t), nil
+	case internal.ParsedReflectRef:
+		return newReflectRef(t), nil
 	default:
 		return nil, fmt.Errorf("unknown ParsedRef type: %T", parsedRef)
 	}
@@ -331,6 +327,28 @@ func (a *refParser) GetSourceOrModuleRefForInputConfig(
 	}
 }
 
+func (a *refParser) GetImageOutputRef(
+	ctx context.Context,
+	value string,
+) (Ref, error) {
+	parsedRef, err := a.getParsedRef(ctx, value, imageOutputFormats)
+	if err != nil {
+		return nil, err
+	}
+	switch t := parsedRef.(type) {
+	case internal.ParsedSingleRef:
+		messageEncoding, err := parseMessageEncoding(t.Format())
+		if err != nil {
+			return nil, err
+		}
+		return newMessageRef(t, messageEncoding)
+	case internal.ParsedDirRef:
+		return newDirRef(t), nil
+	default:
+		return nil, fmt.Errorf("invalid ParsedRef type for image output: %T", parsedRef)
+	}
+}
+
 func (a *refParser) GetMessageRef(
 	ctx context.Context,
 	value string,
@@ -553,6 +571,8 @@ func processRawRef(rawRef *internal.RawRef) error {
 	var compressionType internal.CompressionType
 	if rawRef.Path == "-" || app.IsDevPath(rawRef.Path) {
 		format = formatBinpb
+	} else if internal.HasReflectScheme(rawRef.Path) {
+		format = formatReflect
 	} else {
 		switch filepath.Ext(rawRef.Path) {
 		case ".bin", ".binpb":
@@ -811,6 +831,37 @@ func newProcessRawRefMessage(defaultMessageEncoding MessageEncoding) func(*inter
 	}
 }
 
+func getMessageRefParserOptions(defaultMessageEncoding MessageEncoding) []internal.RefParserOption {
+	return []internal.RefParserOption{
+		internal.WithRawRefProcessor(newProcessRawRefMessage(defaultMessageEncoding)),
+		internal.WithSingleFormat(formatBin),
+		internal.WithSingleFormat(formatBinpb),
+		internal.WithSingleFormat(
+			formatJSON,
+			internal.WithSingleCustomOptionKey(useProtoNamesKey),
+			internal.WithSingleCustomOptionKey(useEnumNumbersKey),
+		),
+		internal.WithSingleFormat(formatTxtpb),
+		internal.WithSingleFormat(
+			formatYAML,
+			internal.WithSingleCustomOptionKey(useProtoNamesKey),
+			internal.WithSingleCustomOptionKey(useEnumNumbersKey),
+		),
+		internal.WithSingleFormat(
+			formatBingz,
+			internal.WithSingleDefaultCompressionType(
+				internal.CompressionTypeGzip,
+			),
+		),
+		internal.WithSingleFormat(
+			formatJSONGZ,
+			internal.WithSingleDefaultCompressionType(
+				internal.CompressionTypeGzip,
+			),
+		),
+	}
+}
+
 func processRawRefModule(rawRef *internal.RawRef) error {
 	rawRef.Format = formatMod
 	return nil
@@ -826,6 +877,8 @@ func parseMessageEncoding(format string) (MessageEncoding, error) {
 		return MessageEncodingTxtpb, nil
 	case formatYAML:
 		return MessageEncodingYAML, nil
+	case formatRaw:
+		return MessageEncodingRaw, nil
 	default:
 		return 0, fmt.Errorf("invalid format for message: %q", format)
 	}
@@ -1298,11 +1298,53 @@ func TestGetParsedRefError(t *testing.T) {
 		internal.NewFormatOverrideNotAllowedForDevNullError(app.DevNullFilePath),
 		fmt.Sprintf("%s#format=bin", app.DevNullFilePath),
 	)
+	testGetParsedRefSuccess(
+		t,
+		internal.NewDirectParsedReflectRef(
+			formatReflect,
+			"grpc+reflect://localhost:8080",
+			"http://localhost:8080",
+		),
+		"grpc+reflect://localhost:8080",
+	)
+	testGetParsedRefSuccess(
+		t,
+		internal.NewDirectParsedReflectRef(
+			formatReflect,
+			"grpcs+reflect://api.example.com/prefix",
+			"https://api.example.com/prefix",
+		),
+		"grpcs+reflect://api.example.com/prefix",
+	)
+	testGetParsedRefSuccess(
+		t,
+		internal.NewDirectParsedReflectRef(
+			formatReflect,
+			"https://api.example.com",
+			"https://api.example.com",
+		),
+		"https://api.example.com#format=reflect",
+	)
 	testGetParsedRefError(
 		t,
 		internal.NewFormatUnknownError("bar"),
 		"path/to/foo#format=bar",
 	)
+	testGetParsedRefError(
+		t,
+		internal.NewInvalidPathError(formatReflect, "localhost:8080"),
+		"localhost:8080#format=reflect",
+	)
+	testGetParsedRefError(
+		t,
+		internal.NewInvalidPathError(formatReflect, "grpc+reflect://"),
+		"grpc+reflect://",
+	)
+	testGetParsedRefError(
+		t,
+		internal.NewOptionsInvalidForFormatError(formatReflect, "grpc+reflect://localhost:8080#compression=gzip", "compression set"),
+		"grpc+reflect://localhost:8080#compression=gzip",
+	)
 	testGetParsedRefError(
 		t,
 		internal.NewOptionsCouldNotParseStripComponentsError("foo"),
@@ -1360,6 +1402,37 @@ func TestGetParsedRefError(t *testing.T) {
 	)
 }
 
+func TestGetImageOutputRef(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	imageOutputRefParser := NewImageOutputRefParser(slogtestext.NewLogger(t))
+	ref, err := imageOutputRefParser.GetImageOutputRef(ctx, "path/to/out#format=dir")
+	require.NoError(t, err)
+	dirRef, ok := ref.(DirRef)
+	require.True(t, ok)
+	assert.Equal(t, "path/to/out", dirRef.DirPath())
+	ref, err = imageOutputRefParser.GetImageOutputRef(ctx, "path/to/out")
+	require.NoError(t, err)
+	messageRef, ok := ref.(MessageRef)
+	require.True(t, ok)
+	assert.Equal(t, MessageEncodingBinpb, messageRef.MessageEncoding())
+	ref, err = imageOutputRefParser.GetImageOutputRef(ctx, "path/to/out.json")
+	require.NoError(t, err)
+	messageRef, ok = ref.(MessageRef)
+	require.True(t, ok)
+	assert.Equal(t, MessageEncodingJSON, messageRef.MessageEncoding())
+	_, err = imageOutputRefParser.GetImageOutputRef(ctx, "-#format=dir")
+	assert.Equal(t, internal.NewInvalidPathError(formatDir, "-"), err)
+	_, err = imageOutputRefParser.GetImageOutputRef(ctx, "path/to/out#format=git")
+	assert.Error(t, err)
+	// The raw format is only supported for messages, an image cannot be written as raw.
+	_, err = imageOutputRefParser.GetImageOutputRef(ctx, "path/to/out#format=raw")
+	assert.Error(t, err)
+	messageRef, err = NewMessageRefParser(slogtestext.NewLogger(t)).GetMessageRef(ctx, "path/to/out#format=raw")
+	require.NoError(t, err)
+	assert.Equal(t, MessageEncodingRaw, messageRef.MessageEncoding())
+}
+
 func testGetParsedRefSuccess(
 	t *testing.T,
 	expectedRef internal.ParsedRef,
@@ -15,21 +15,28 @@
 package bufformat
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/pkg/licenseheader"
+	"github.com/bufbuild/buf/private/pkg/protoencoding"
 	"github.com/bufbuild/buf/private/pkg/storage"
 	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
+	"github.com/bufbuild/buf/private/pkg/syserror"
 	"github.com/bufbuild/buf/private/pkg/thread"
 	"github.com/bufbuild/protocompile/ast"
 	"github.com/bufbuild/protocompile/parser"
 	"github.com/bufbuild/protocompile/reporter"
+	"github.com/jhump/protoreflect/v2/protoprint"
 )
 
 // FormatModuleSet formats and writes the target files into a read bucket.
-func FormatModuleSet(ctx context.Context, moduleSet bufmodule.ModuleSet) (_ storage.ReadBucket, retErr error) {
+func FormatModuleSet(ctx context.Context, moduleSet bufmodule.ModuleSet, options ...FormatOption) (_ storage.ReadBucket, retErr error) {
 	return FormatBucket(
 		ctx,
 		bufmodule.ModuleReadBucketToStorageReadBucket(
@@ -37,11 +44,16 @@ func FormatModuleSet(ctx context.Context, moduleSet bufmodule.ModuleSet) (_ stor
 				bufmodule.ModuleSetToModuleReadBucketWithOnlyProtoFilesForTargetModules(moduleSet),
 			),
 		),
+		options...,
 	)
 }
 
 // FormatBucket formats the .proto files in the bucket and returns a new bucket with the formatted files.
-func FormatBucket(ctx context.Context, bucket storage.ReadBucket) (_ storage.ReadBucket, retErr error) {
+func FormatBucket(ctx context.Context, bucket storage.ReadBucket, options ...FormatOption) (_ storage.ReadBucket, retErr error) {
+	formatOptions := newFormatOptions()
+	for _, option := range options {
+		option(formatOptions)
+	}
 	readWriteBucket := storagemem.NewReadWriteBucket()
 	paths, err := storage.AllPaths(ctx, storage.FilterReadBucket(bucket, storage.MatchPathExt(".proto")), "")
 	if err != nil {
@@ -62,6 +74,17 @@ func FormatBucket(ctx context.Context, bucket storage.ReadBucket) (_ storage.Rea
 			if err != nil {
 				return err
 			}
+			buffer := bytes.NewBuffer(nil)
+			if err := FormatFileNode(buffer, fileNode); err != nil {
+				return err
+			}
+			data := buffer.Bytes()
+			if licenseHeaderTemplate, ok := formatOptions.pathToLicenseHeaderTemplate[path]; ok {
+				data, err = licenseHeaderTemplate.Modify(path, data)
+				if err != nil {
+					return err
+				}
+			}
 			writeObjectCloser, err := readWriteBucket.Put(ctx, path)
 			if err != nil {
 				return err
@@ -69,7 +92,7 @@ func FormatBucket(ctx context.Context, bucket storage.ReadBucket) (_ storage.Rea
 			defer func() {
 				retErr = errors.Join(retErr, writeObjectCloser.Close())
 			}()
-			if err := FormatFileNode(writeObjectCloser, fileNode); err != nil {
+			if _, err := writeObjectCloser.Write(data); err != nil {
 				return err
 			}
 			return writeObjectCloser.SetExternalPath(readObjectCloser.ExternalPath())
@@ -81,8 +104,79 @@ func FormatBucket(ctx context.Context, bucket storage.ReadBucket) (_ storage.Rea
 	return readWriteBucket, nil
 }
 
+// FormatOption is an option for formatting.
+type FormatOption func(*formatOptions)
+
+// FormatWithLicenseHeaderTemplates returns a new FormatOption that inserts or updates the
+// license headers of the formatted files.
+//
+// The map is from file path to the licenseheader.Template for the file. The license headers
+// of files not in the map are left as-is.
+func FormatWithLicenseHeaderTemplates(pathToLicenseHeaderTemplate map[string]licenseheader.Template) FormatOption {
+	return func(formatOptions *formatOptions) {
+		formatOptions.pathToLicenseHeaderTemplate = pathToLicenseHeaderTemplate
+	}
+}
+
 // FormatFileNode formats the given file node and writ the result to dest.
 func FormatFileNode(dest io.Writer, fileNode *ast.FileNode) error {
 	formatter := newFormatter(dest, fileNode)
 	return formatter.Run()
 }
+
+// FormatImage prints the files of the image as .proto source and returns a new bucket
+// with the printed files, formatted as with FormatFileNode.
+//
+// The printed files include options, custom options, reserved ranges and names, and
+// extensions. Comments are printed from the source code info of the files, if present.
+func FormatImage(ctx context.Context, image bufimage.Image) (storage.ReadBucket, error) {
+	// Image.Resolver may have been built before the custom options of the image were
+	// reparsed, in which case its descriptors do not carry the custom options, so a
+	// resolver is built from the current file descriptors instead.
+	resolver, err := protoencoding.NewResolver(bufimage.ImageToFileDescriptorProtos(image)...)
+	if err != nil {
+		return nil, err
+	}
+	readWriteBucket := storagemem.NewReadWriteBucket()
+	imageFiles := image.Files()
+	jobs := make([]func(context.Context) error, len(imageFiles))
+	for i, imageFile := range imageFiles {
+		path := imageFile.Path()
+		jobs[i] = func(ctx context.Context) error {
+			buffer := bytes.NewBuffer(nil)
+			if err := formatImageFile(buffer, resolver, path); err != nil {
+				return err
+			}
+			return storage.PutPath(ctx, readWriteBucket, path, buffer.Bytes())
+		}
+	}
+	if err := thread.Parallelize(ctx, jobs); err != nil {
+		return nil, err
+	}
+	return readWriteBucket, nil
+}
+
+func formatImageFile(dest io.Writer, resolver protoencoding.Resolver, path string) error {
+	fileDescriptor, err := resolver.FindFileByPath(path)
+	if err != nil {
+		return err
+	}
+	printed := bytes.NewBuffer(nil)
+	if err := (&protoprint.Printer{}).PrintProtoFile(fileDescriptor, printed); err != nil {
+		return fmt.Errorf("could not print %q: %w", path, err)
+	}
+	fileNode, err := parser.Parse(path, printed, reporter.NewHandler(nil))
+	if err != nil {
+		// The printer should always produce valid source.
+		return syserror.Newf("could not parse printed %q: %w", path, err)
+	}
+	return FormatFileNode(dest, fileNode)
+}
+
+type formatOptions struct {
+	pathToLicenseHeaderTemplate map[string]licenseheader.Template
+}
+
+func newFormatOptions() *formatOptions {
+	return &formatOptions{}
+}
@@ -22,8 +22,10 @@ import (
 
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
 	"github.com/bufbuild/buf/private/pkg/diff"
+	"github.com/bufbuild/buf/private/pkg/licenseheader"
 	"github.com/bufbuild/buf/private/pkg/slogtestext"
 	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"github.com/stretchr/testify/require"
 )
@@ -36,6 +38,42 @@ func TestFormatter(t *testing.T) {
 	testFormatProto3(t)
 }
 
+func TestFormatLicenseHeader(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	licenseHeaderTemplate, err := licenseheader.NewTemplate("", "Acme, Inc.", "2020-2024")
+	require.NoError(t, err)
+	bucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			"a.proto": []byte("// Package a is a package.\nsyntax  =  \"proto3\";\n\npackage a;\n"),
+			"b.proto": []byte("// Copyright 2020-2021 Acme, Inc.\n\nsyntax = \"proto3\";\n\npackage b;\n"),
+			"c.proto": []byte("syntax = \"proto3\";\n\npackage c;\n"),
+		},
+	)
+	require.NoError(t, err)
+	readBucket, err := FormatBucket(
+		ctx,
+		bucket,
+		FormatWithLicenseHeaderTemplates(
+			map[string]licenseheader.Template{
+				"a.proto": licenseHeaderTemplate,
+				"b.proto": licenseHeaderTemplate,
+			},
+		),
+	)
+	require.NoError(t, err)
+	for path, expected := range map[string]string{
+		"a.proto": "// Copyright 2020-2024 Acme, Inc.\n\n// Package a is a package.\nsyntax = \"proto3\";\n\npackage a;\n",
+		"b.proto": "// Copyright 2020-2024 Acme, Inc.\n\nsyntax = \"proto3\";\n\npackage b;\n",
+		// c.proto has no license header configured.
+		"c.proto": "syntax = \"proto3\";\n\npackage c;\n",
+	} {
+		data, err := storage.ReadPath(ctx, readBucket, path)
+		require.NoError(t, err)
+		require.Equal(t, expected, string(data), path)
+	}
+}
+
 func testFormatCustomOptions(t *testing.T) {
 	testFormatNoDiff(t, "testdata/customoptions")
 }
@@ -0,0 +1,83 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufformat
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduletesting"
+	"github.com/bufbuild/buf/private/gen/data/datawkt"
+	imagev1 "github.com/bufbuild/buf/private/gen/proto/go/buf/alpha/image/v1"
+	"github.com/bufbuild/buf/private/pkg/diff"
+	"github.com/bufbuild/buf/private/pkg/protoencoding"
+	"github.com/bufbuild/buf/private/pkg/slogtestext"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatImage(t *testing.T) {
+	t.Parallel()
+	image := testBuildImage(t, "testdata/image/input")
+	testFormatImageGolden(t, image, "testdata/image/golden")
+	// Images read from binary are unmarshaled without a resolver, so custom
+	// options are reparsed when the image is created.
+	protoImage, err := bufimage.ImageToProtoImage(image)
+	require.NoError(t, err)
+	data, err := protoencoding.NewWireMarshaler().Marshal(protoImage)
+	require.NoError(t, err)
+	protoImage = &imagev1.Image{}
+	require.NoError(t, protoencoding.NewWireUnmarshaler(nil).Unmarshal(data, protoImage))
+	image, err = bufimage.NewImageForProto(protoImage)
+	require.NoError(t, err)
+	testFormatImageGolden(t, image, "testdata/image/golden")
+	// The printed files must build to an image that prints the same.
+	testFormatImageGolden(t, testBuildImage(t, "testdata/image/golden"), "testdata/image/golden")
+}
+
+func testBuildImage(t *testing.T, dirPath string) bufimage.Image {
+	moduleSet, err := bufmoduletesting.NewModuleSetForDirPath(dirPath)
+	require.NoError(t, err)
+	image, err := bufimage.BuildImage(
+		context.Background(),
+		slogtestext.NewLogger(t),
+		bufmodule.ModuleSetToModuleReadBucketWithOnlyProtoFiles(moduleSet),
+	)
+	require.NoError(t, err)
+	return image
+}
+
+func testFormatImageGolden(t *testing.T, image bufimage.Image, goldenDirPath string) {
+	ctx := context.Background()
+	readBucket, err := FormatImage(ctx, image)
+	require.NoError(t, err)
+	goldenBucket, err := storageos.NewProvider().NewReadWriteBucket(goldenDirPath)
+	require.NoError(t, err)
+	for _, imageFile := range image.Files() {
+		if datawkt.Exists(imageFile.Path()) {
+			continue
+		}
+		formattedData, err := storage.ReadPath(ctx, readBucket, imageFile.Path())
+		require.NoError(t, err)
+		expectedData, err := storage.ReadPath(ctx, goldenBucket, imageFile.Path())
+		require.NoError(t, err)
+		fileDiff, err := diff.Diff(ctx, expectedData, formattedData, imageFile.Path(), imageFile.Path()+" (formatted)")
+		require.NoError(t, err)
+		require.Empty(t, string(fileDiff))
+	}
+}
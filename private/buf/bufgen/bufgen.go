@@ -28,6 +28,7 @@ import (
 	"github.com/bufbuild/buf/private/pkg/app"
 	"github.com/bufbuild/buf/private/pkg/connectclient"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"github.com/bufbuild/buf/private/pkg/wasm"
 )
 
 const (
@@ -87,6 +88,8 @@ type Generator interface {
 func NewGenerator(
 	logger *slog.Logger,
 	storageosProvider storageos.Provider,
+	// The wasmRuntime is used to run local plugins that are WebAssembly modules.
+	wasmRuntime wasm.Runtime,
 	// Pass a clientConfig instead of a CodeGenerationServiceClient because the
 	// plugins' remotes/registries is not known at this time, and remotes/registries
 	// may be different for different plugins.
@@ -95,6 +98,7 @@ func NewGenerator(
 	return newGenerator(
 		logger,
 		storageosProvider,
+		wasmRuntime,
 		clientConfig,
 	)
 }
@@ -37,6 +37,7 @@ import (
 	"github.com/bufbuild/buf/private/pkg/slicesext"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"github.com/bufbuild/buf/private/pkg/thread"
+	"github.com/bufbuild/buf/private/pkg/wasm"
 	"google.golang.org/protobuf/types/pluginpb"
 )
 
@@ -50,12 +51,13 @@ type generator struct {
 func newGenerator(
 	logger *slog.Logger,
 	storageosProvider storageos.Provider,
+	wasmRuntime wasm.Runtime,
 	clientConfig *connectclient.Config,
 ) *generator {
 	return &generator{
 		logger:              logger,
 		storageosProvider:   storageosProvider,
-		pluginexecGenerator: bufprotopluginexec.NewGenerator(logger, storageosProvider),
+		pluginexecGenerator: bufprotopluginexec.NewGenerator(logger, storageosProvider, wasmRuntime),
 		clientConfig:        clientConfig,
 	}
 }
@@ -244,6 +246,11 @@ func (g *generator) execPlugins(
 					includeWellKnownTypes,
 				)
 				if err != nil {
+					if ctxErr := ctx.Err(); ctxErr != nil {
+						// The run was cancelled, most likely because another plugin failed.
+						// Report the cancellation instead of how the plugin was stopped.
+						return ctxErr
+					}
 					return err
 				}
 				responses[index] = response
@@ -293,7 +300,7 @@ func (g *generator) execPlugins(
 		jobs,
 		thread.ParallelizeWithCancelOnFailure(),
 	); err != nil {
-		return nil, err
+		return nil, withoutCancellationErrors(err)
 	}
 	if err := validateResponses(responses, pluginConfigs); err != nil {
 		return nil, err
@@ -333,6 +340,9 @@ func (g *generator) execLocalPlugin(
 		requests,
 		bufprotopluginexec.GenerateWithPluginPath(pluginConfig.Path()...),
 		bufprotopluginexec.GenerateWithProtocPath(pluginConfig.ProtocPath()...),
+		bufprotopluginexec.GenerateWithTimeout(pluginConfig.Timeout()),
+		bufprotopluginexec.GenerateWithMaxOutputBytes(pluginConfig.MaxOutputBytes()),
+		bufprotopluginexec.GenerateWithEnv(pluginConfig.Env()),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("plugin %s: %v", pluginConfig.Name(), err)
@@ -484,3 +494,24 @@ type generateOptions struct {
 func newGenerateOptions() *generateOptions {
 	return &generateOptions{}
 }
+
+// withoutCancellationErrors removes the context cancellation errors of the plugins that
+// were cancelled from err, so that only the errors of the plugins that failed are reported.
+//
+// If every error is a cancellation error, err is returned as-is.
+func withoutCancellationErrors(err error) error {
+	joinedErr, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return err
+	}
+	var errs []error
+	for _, err := range joinedErr.Unwrap() {
+		if !errors.Is(err, context.Canceled) {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return err
+	}
+	return errors.Join(errs...)
+}
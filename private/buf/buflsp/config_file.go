@@ -0,0 +1,747 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file defines language features for buf.yaml, buf.gen.yaml and buf.lock files.
+
+package buflsp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"buf.build/go/bufplugin/check"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck"
+	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/slogext"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+	"gopkg.in/yaml.v3"
+)
+
+// bufGenYAMLFileName is the default name of buf.gen.yaml files.
+const bufGenYAMLFileName = "buf.gen.yaml"
+
+var (
+	// configErrorLinePattern matches the line numbers in errors from yaml.v3.
+	configErrorLinePattern = regexp.MustCompile(`line (\d+): ([^\n]*)`)
+	// configKeyPattern matches the key of a line of a configuration file.
+	configKeyPattern = regexp.MustCompile(`^([\w.\-]+)[ \t]*:(?:[ \t]|$)`)
+
+	configFileVersions = map[string]bufconfig.FileVersion{
+		bufconfig.FileVersionV1Beta1.String(): bufconfig.FileVersionV1Beta1,
+		bufconfig.FileVersionV1.String():      bufconfig.FileVersionV1,
+		bufconfig.FileVersionV2.String():      bufconfig.FileVersionV2,
+	}
+	configRuleListKeys = []string{"use", "except", "warn", "info"}
+)
+
+// configFile is a buf.yaml, buf.gen.yaml or buf.lock file that has been opened by the client.
+//
+// Diagnostics are computed from the parsed YAML of the file, but completion, hover and
+// definitions only look at the lines of the file, as the file is usually not valid YAML
+// while it is being edited.
+type configFile struct {
+	file     *file
+	fileName string
+
+	lines []string
+	// root is the root mapping of the file, or nil if the file is not a valid YAML mapping.
+	root *yaml.Node
+}
+
+// newConfigFile returns a new configFile for the file, or nil if the file is not a
+// configuration file.
+func newConfigFile(file *file) *configFile {
+	fileName := normalpath.Base(file.uri.Filename())
+	switch fileName {
+	case bufconfig.DefaultBufYAMLFileName, bufGenYAMLFileName, bufconfig.DefaultBufLockFileName:
+		return &configFile{
+			file:     file,
+			fileName: fileName,
+		}
+	default:
+		return nil
+	}
+}
+
+// Refresh reparses the file and sets the diagnostics of the file.
+func (c *configFile) Refresh(ctx context.Context) {
+	c.lines = strings.Split(c.file.text, "\n")
+	c.root = nil
+
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(c.file.text), &document); err != nil {
+		c.file.diagnostics, _ = c.newErrorDiagnostics(err)
+		return
+	}
+	if len(document.Content) > 0 && document.Content[0].Kind == yaml.MappingNode {
+		c.root = document.Content[0]
+	}
+
+	var diagnostics []protocol.Diagnostic
+	switch c.fileName {
+	case bufconfig.DefaultBufYAMLFileName:
+		diagnostics = c.checkBufYAMLFile(ctx)
+	case bufGenYAMLFileName:
+		diagnostics = c.checkBufGenYAMLFile()
+	}
+	if err := c.read(ctx); err != nil {
+		readDiagnostics, hasPosition := c.newErrorDiagnostics(err)
+		// Errors without a position are usually also found by the checks above, which
+		// report them with a position.
+		if hasPosition || len(diagnostics) == 0 {
+			diagnostics = append(readDiagnostics, diagnostics...)
+		}
+	}
+	c.file.diagnostics = diagnostics
+	c.file.lsp.logger.Debug(fmt.Sprintf("got %v diagnostic(s)", len(diagnostics)))
+}
+
+// Hover returns the documentation of the key or value at the position.
+func (c *configFile) Hover(ctx context.Context, position protocol.Position) *protocol.Hover {
+	cursor := c.cursorAt(position)
+	if cursor == nil {
+		return nil
+	}
+
+	var docs string
+	if ruleType, ok := c.ruleTypeAt(cursor); ok {
+		idToRuleOrCategory := c.getIDToRuleOrCategory(ctx, ruleType)
+		if ruleOrCategory, ok := idToRuleOrCategory[cursor.text]; ok {
+			docs = formatRuleOrCategoryDocs(ruleOrCategory)
+		}
+	} else if cursor.isKey {
+		if keyDocs, ok := configFileNameToKeyPathToDocs[c.fileName][cursor.docsKeyPath(c.fileName)]; ok {
+			docs = fmt.Sprintf("`%s`\n\n%s", cursor.text, keyDocs)
+		}
+	}
+	if docs == "" {
+		return nil
+	}
+
+	range_ := cursor.range_ // Need to spill this here because Hover.Range is a pointer.
+	return &protocol.Hover{
+		Contents: protocol.MarkupContent{
+			Kind:  protocol.Markdown,
+			Value: docs,
+		},
+		Range: &range_,
+	}
+}
+
+// Completion returns the completion items for the key or value at the position.
+func (c *configFile) Completion(ctx context.Context, position protocol.Position) *protocol.CompletionList {
+	cursor := c.cursorAt(position)
+	if cursor == nil {
+		return nil
+	}
+
+	var items []protocol.CompletionItem
+	if ruleType, ok := c.ruleTypeAt(cursor); ok {
+		idToRuleOrCategory := c.getIDToRuleOrCategory(ctx, ruleType)
+		for _, ruleOrCategory := range idToRuleOrCategory {
+			kind := protocol.CompletionItemKindEnumMember
+			if _, ok := ruleOrCategory.(bufcheck.Category); ok {
+				kind = protocol.CompletionItemKindEnum
+			}
+			items = append(items, protocol.CompletionItem{
+				Label:      ruleOrCategory.ID(),
+				Kind:       kind,
+				Detail:     ruleOrCategory.Purpose(),
+				Deprecated: ruleOrCategory.Deprecated(),
+			})
+		}
+	} else if cursor.isKey {
+		// Complete the sibling keys of the key.
+		prefix := strings.TrimSuffix(cursor.docsKeyPath(c.fileName), cursor.text)
+		for keyPath, docs := range configFileNameToKeyPathToDocs[c.fileName] {
+			key, ok := strings.CutPrefix(keyPath, prefix)
+			if !ok || strings.Contains(key, ".") {
+				continue
+			}
+			items = append(items, protocol.CompletionItem{
+				Label:         key,
+				Kind:          protocol.CompletionItemKindProperty,
+				Documentation: docs,
+			})
+		}
+	} else {
+		for _, value := range c.valuesAt(cursor) {
+			items = append(items, protocol.CompletionItem{
+				Label: value,
+				Kind:  protocol.CompletionItemKindValue,
+			})
+		}
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	sort.Slice(items, func(i, j int) bool { return items[i].Label < items[j].Label })
+	return &protocol.CompletionList{Items: items}
+}
+
+// Definition returns the location of the module directory at the position.
+func (c *configFile) Definition(position protocol.Position) []protocol.Location {
+	if c.fileName != bufconfig.DefaultBufYAMLFileName {
+		return nil
+	}
+	cursor := c.cursorAt(position)
+	if cursor == nil || cursor.isKey || cursor.text == "" || !slices.Equal(cursor.keyPath, []string{"modules", "path"}) {
+		return nil
+	}
+	return []protocol.Location{{URI: protocol.DocumentURI(uri.File(normalpath.Unnormalize(c.modulePath(cursor.text))))}}
+}
+
+// read reads the file with bufconfig.
+func (c *configFile) read(ctx context.Context) error {
+	reader := strings.NewReader(c.file.text)
+	var err error
+	switch c.fileName {
+	case bufconfig.DefaultBufYAMLFileName:
+		_, err = bufconfig.ReadBufYAMLFile(reader, c.fileName)
+	case bufGenYAMLFileName:
+		_, err = bufconfig.ReadBufGenYAMLFile(reader)
+	case bufconfig.DefaultBufLockFileName:
+		_, err = bufconfig.ReadBufLockFile(ctx, reader, c.fileName)
+	}
+	return err
+}
+
+// checkBufYAMLFile checks the rule and category IDs and the module paths of a buf.yaml file.
+func (c *configFile) checkBufYAMLFile(ctx context.Context) []protocol.Diagnostic {
+	fileVersion, ok := c.fileVersion()
+	if !ok {
+		return nil
+	}
+
+	var diagnostics []protocol.Diagnostic
+	// Rules of plugins are only known once the plugins are loaded, and extended files may
+	// configure plugins, so we only check the builtin rules if there are no plugins or extends.
+	if getYAMLMappingValue(c.root, "plugins") == nil && getYAMLMappingValue(c.root, "extends") == nil {
+		for _, checkNode := range c.getCheckNodes() {
+			idToRuleOrCategory := c.getIDToRuleOrCategory(ctx, checkNode.ruleType)
+			if idToRuleOrCategory == nil {
+				continue
+			}
+			for _, idNode := range getCheckIDNodes(checkNode.node) {
+				if diagnostic, ok := c.checkID(idToRuleOrCategory, idNode); ok {
+					diagnostics = append(diagnostics, diagnostic)
+				}
+			}
+		}
+	}
+	if fileVersion == bufconfig.FileVersionV2 {
+		modulesNode := getYAMLMappingValue(c.root, "modules")
+		for _, moduleNode := range getYAMLSequenceItems(modulesNode) {
+			pathNode := getYAMLMappingValue(moduleNode, "path")
+			if pathNode == nil || pathNode.Kind != yaml.ScalarNode || pathNode.Value == "" || filepath.IsAbs(pathNode.Value) {
+				continue
+			}
+			if fileInfo, err := os.Stat(c.modulePath(pathNode.Value)); err != nil || !fileInfo.IsDir() {
+				diagnostics = append(diagnostics, c.newNodeDiagnostic(
+					pathNode,
+					protocol.DiagnosticSeverityError,
+					fmt.Sprintf("module directory %q does not exist", pathNode.Value),
+				))
+			}
+		}
+	}
+	return diagnostics
+}
+
+// checkBufGenYAMLFile checks the managed options of a buf.gen.yaml file.
+func (c *configFile) checkBufGenYAMLFile() []protocol.Diagnostic {
+	if fileVersion, ok := c.fileVersion(); !ok || fileVersion != bufconfig.FileVersionV2 {
+		return nil
+	}
+
+	var diagnostics []protocol.Diagnostic
+	managedNode := getYAMLMappingValue(c.root, "managed")
+	for _, key := range []string{"disable", "override"} {
+		for _, ruleNode := range getYAMLSequenceItems(getYAMLMappingValue(managedNode, key)) {
+			if diagnostic, ok := c.checkOptionName(getYAMLMappingValue(ruleNode, "file_option"), "file option", getFileOptionNames()); ok {
+				diagnostics = append(diagnostics, diagnostic)
+			}
+			if diagnostic, ok := c.checkOptionName(getYAMLMappingValue(ruleNode, "field_option"), "field option", getFieldOptionNames()); ok {
+				diagnostics = append(diagnostics, diagnostic)
+			}
+		}
+	}
+	return diagnostics
+}
+
+// checkOptionName returns a diagnostic if the option node is not one of the option names.
+func (c *configFile) checkOptionName(optionNode *yaml.Node, optionKind string, optionNames []string) (protocol.Diagnostic, bool) {
+	if optionNode == nil || optionNode.Kind != yaml.ScalarNode || optionNode.Value == "" || slices.Contains(optionNames, optionNode.Value) {
+		return protocol.Diagnostic{}, false
+	}
+	return c.newNodeDiagnostic(
+		optionNode,
+		protocol.DiagnosticSeverityError,
+		fmt.Sprintf("%q is not a known %s", optionNode.Value, optionKind),
+	), true
+}
+
+// checkID returns a diagnostic if the ID node is not a known rule or category ID, or if the
+// rule or category is deprecated.
+func (c *configFile) checkID(idToRuleOrCategory map[string]bufcheck.RuleOrCategory, idNode *yaml.Node) (protocol.Diagnostic, bool) {
+	ruleOrCategory, ok := idToRuleOrCategory[idNode.Value]
+	if !ok {
+		return c.newNodeDiagnostic(
+			idNode,
+			protocol.DiagnosticSeverityError,
+			fmt.Sprintf("%q is not a known rule or category ID", idNode.Value),
+		), true
+	}
+	if !ruleOrCategory.Deprecated() {
+		return protocol.Diagnostic{}, false
+	}
+	message := fmt.Sprintf("%q is deprecated", idNode.Value)
+	if replacementIDs := ruleOrCategory.ReplacementIDs(); len(replacementIDs) > 0 {
+		message = fmt.Sprintf("%s, use %s instead", message, strings.Join(replacementIDs, ", "))
+	}
+	return c.newNodeDiagnostic(idNode, protocol.DiagnosticSeverityWarning, message), true
+}
+
+// checkNode is a lint or breaking section of a buf.yaml file.
+type checkNode struct {
+	ruleType check.RuleType
+	node     *yaml.Node
+}
+
+// getCheckNodes returns the lint and breaking sections of the file and its modules.
+func (c *configFile) getCheckNodes() []checkNode {
+	mappingNodes := []*yaml.Node{c.root}
+	mappingNodes = append(mappingNodes, getYAMLSequenceItems(getYAMLMappingValue(c.root, "modules"))...)
+	var checkNodes []checkNode
+	for _, mappingNode := range mappingNodes {
+		if lintNode := getYAMLMappingValue(mappingNode, "lint"); lintNode != nil {
+			checkNodes = append(checkNodes, checkNode{ruleType: check.RuleTypeLint, node: lintNode})
+		}
+		if breakingNode := getYAMLMappingValue(mappingNode, "breaking"); breakingNode != nil {
+			checkNodes = append(checkNodes, checkNode{ruleType: check.RuleTypeBreaking, node: breakingNode})
+		}
+	}
+	return checkNodes
+}
+
+// getCheckIDNodes returns the nodes of the rule and category IDs of a lint or breaking section.
+func getCheckIDNodes(node *yaml.Node) []*yaml.Node {
+	var idNodes []*yaml.Node
+	for _, key := range configRuleListKeys {
+		for _, itemNode := range getYAMLSequenceItems(getYAMLMappingValue(node, key)) {
+			if itemNode.Kind == yaml.ScalarNode {
+				idNodes = append(idNodes, itemNode)
+			}
+		}
+	}
+	if ignoreOnlyNode := getYAMLMappingValue(node, "ignore_only"); ignoreOnlyNode != nil && ignoreOnlyNode.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(ignoreOnlyNode.Content); i += 2 {
+			idNodes = append(idNodes, ignoreOnlyNode.Content[i])
+		}
+	}
+	return idNodes
+}
+
+// getIDToRuleOrCategory returns the builtin rules and categories of the rule type for the
+// version of the file, or nil if they could not be determined.
+func (c *configFile) getIDToRuleOrCategory(ctx context.Context, ruleType check.RuleType) map[string]bufcheck.RuleOrCategory {
+	fileVersion, ok := c.fileVersion()
+	if !ok {
+		return nil
+	}
+	rules, err := c.file.lsp.checkClient.AllRules(ctx, ruleType, fileVersion)
+	if err != nil {
+		c.file.lsp.logger.Warn("could not get rules", slog.String("uri", string(c.file.uri)), slogext.ErrorAttr(err))
+		return nil
+	}
+	idToRuleOrCategory := make(map[string]bufcheck.RuleOrCategory)
+	for _, rule := range rules {
+		idToRuleOrCategory[rule.ID()] = rule
+		// Categories are shared between rule types, so we only include the categories of
+		// the rules of the rule type.
+		for _, category := range rule.BufcheckCategories() {
+			idToRuleOrCategory[category.ID()] = category
+		}
+	}
+	return idToRuleOrCategory
+}
+
+// fileVersion returns the version of the file, based on its version key.
+//
+// Files without a version key are not checked, as bufconfig reports those.
+func (c *configFile) fileVersion() (bufconfig.FileVersion, bool) {
+	if c.root == nil {
+		// The file could not be parsed, so we look for the version line.
+		for _, line := range c.lines {
+			if value, ok := strings.CutPrefix(line, "version:"); ok {
+				fileVersion, ok := configFileVersions[strings.Trim(strings.TrimSpace(value), `"'`)]
+				return fileVersion, ok
+			}
+		}
+		return 0, false
+	}
+	versionNode := getYAMLMappingValue(c.root, "version")
+	if versionNode == nil {
+		return 0, false
+	}
+	fileVersion, ok := configFileVersions[versionNode.Value]
+	return fileVersion, ok
+}
+
+// ruleTypeAt returns the rule type if the cursor is on a rule or category ID.
+func (c *configFile) ruleTypeAt(cursor *configCursor) (check.RuleType, bool) {
+	if c.fileName != bufconfig.DefaultBufYAMLFileName {
+		return 0, false
+	}
+	keyPath := strings.Split(cursor.docsKeyPath(c.fileName), ".")
+	if len(keyPath) < 2 {
+		return 0, false
+	}
+	var ruleType check.RuleType
+	switch keyPath[0] {
+	case "lint":
+		ruleType = check.RuleTypeLint
+	case "breaking":
+		ruleType = check.RuleTypeBreaking
+	default:
+		return 0, false
+	}
+	if slices.Contains(configRuleListKeys, keyPath[1]) && len(keyPath) == 2 && !cursor.isKey {
+		return ruleType, true
+	}
+	// The keys of ignore_only are rule and category IDs.
+	if keyPath[1] == "ignore_only" && len(keyPath) == 3 && cursor.isKey {
+		return ruleType, true
+	}
+	return 0, false
+}
+
+// valuesAt returns the known values for the value at the cursor.
+func (c *configFile) valuesAt(cursor *configCursor) []string {
+	if c.fileName != bufGenYAMLFileName {
+		return nil
+	}
+	switch strings.Join(cursor.keyPath, ".") {
+	case "plugins.protoc_builtin":
+		var pluginNames []string
+		for pluginName := range bufconfig.ProtocProxyPluginNames {
+			pluginNames = append(pluginNames, pluginName)
+		}
+		return pluginNames
+	case "managed.disable.file_option", "managed.override.file_option":
+		return getFileOptionNames()
+	case "managed.disable.field_option", "managed.override.field_option":
+		return getFieldOptionNames()
+	}
+	return nil
+}
+
+// modulePath returns the absolute path of a module path of a buf.yaml file.
+func (c *configFile) modulePath(path string) string {
+	return normalpath.Join(normalpath.Dir(c.file.uri.Filename()), path)
+}
+
+// newErrorDiagnostics converts an error from yaml.v3 or bufconfig into diagnostics.
+//
+// The errors only contain the line numbers, so the diagnostics span the entire line. Returns
+// whether the error contained any positions.
+func (c *configFile) newErrorDiagnostics(err error) ([]protocol.Diagnostic, bool) {
+	var diagnostics []protocol.Diagnostic
+	for _, match := range configErrorLinePattern.FindAllStringSubmatch(err.Error(), -1) {
+		line, err := strconv.Atoi(match[1])
+		if err != nil {
+			continue
+		}
+		message := match[2]
+		// Remove the names of the internal types of bufconfig.
+		if index := strings.Index(message, " in type "); index >= 0 {
+			message = message[:index]
+		}
+		diagnostics = append(diagnostics, protocol.Diagnostic{
+			Range:    c.lineRange(line - 1),
+			Severity: protocol.DiagnosticSeverityError,
+			Message:  message,
+			Source:   serverName,
+		})
+	}
+	if len(diagnostics) > 0 {
+		return diagnostics, true
+	}
+	return []protocol.Diagnostic{
+		{
+			Range:    c.lineRange(0),
+			Severity: protocol.DiagnosticSeverityError,
+			Message:  err.Error(),
+			Source:   serverName,
+		},
+	}, false
+}
+
+// newNodeDiagnostic returns a new diagnostic spanning the scalar node.
+func (c *configFile) newNodeDiagnostic(node *yaml.Node, severity protocol.DiagnosticSeverity, message string) protocol.Diagnostic {
+	start := protocol.Position{
+		Line:      uint32(node.Line - 1),
+		Character: uint32(node.Column - 1),
+	}
+	end := start
+	end.Character += uint32(len(node.Value))
+	if node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+		end.Character += 2
+	}
+	return protocol.Diagnostic{
+		Range:    protocol.Range{Start: start, End: end},
+		Severity: severity,
+		Message:  message,
+		Source:   serverName,
+	}
+}
+
+// lineRange returns the range of the content of the line.
+func (c *configFile) lineRange(line int) protocol.Range {
+	if line < 0 || line >= len(c.lines) {
+		line = 0
+	}
+	var text string
+	if line < len(c.lines) {
+		text = strings.TrimRight(c.lines[line], " \t\r")
+	}
+	return protocol.Range{
+		Start: protocol.Position{Line: uint32(line), Character: uint32(len(text) - len(strings.TrimLeft(text, " \t")))},
+		End:   protocol.Position{Line: uint32(line), Character: uint32(len(text))},
+	}
+}
+
+// configCursor is the key or value under the cursor in a configuration file.
+type configCursor struct {
+	// keyPath is the path of mapping keys to the cursor, without sequence indices. If the
+	// cursor is on a key, the key is the last element.
+	keyPath []string
+	// isKey is whether the cursor is on a key, or on a value otherwise.
+	isKey bool
+	// text is the key or value under the cursor, without quotes.
+	text   string
+	range_ protocol.Range
+}
+
+// docsKeyPath returns the key path of the cursor in configFileNameToKeyPathToDocs.
+func (c *configCursor) docsKeyPath(fileName string) string {
+	keyPath := c.keyPath
+	if fileName == bufconfig.DefaultBufYAMLFileName && len(keyPath) > 2 && keyPath[0] == "modules" &&
+		(keyPath[1] == "lint" || keyPath[1] == "breaking") {
+		keyPath = keyPath[1:]
+	}
+	return strings.Join(keyPath, ".")
+}
+
+// cursorAt returns the cursor at the position, or nil if there is no key or value at the position.
+//
+// The key path is found by looking at the indentation of the lines preceding the position.
+func (c *configFile) cursorAt(position protocol.Position) *configCursor {
+	if int(position.Line) >= len(c.lines) {
+		return nil
+	}
+	line, ok := parseConfigLine(c.lines[position.Line])
+	if !ok {
+		return nil
+	}
+	column := int(position.Character)
+	if column < line.contentStart {
+		return nil
+	}
+
+	cursor := &configCursor{}
+	var start, end int
+	switch {
+	case line.key != "" && column <= line.contentStart+len(line.key):
+		cursor.isKey = true
+		cursor.text = line.key
+		start, end = line.contentStart, line.contentStart+len(line.key)
+		cursor.keyPath = []string{line.key}
+	case line.key == "" && !line.isItem && !strings.HasPrefix(line.value, "["):
+		// A key that is still being typed.
+		cursor.isKey = true
+		cursor.text = line.value
+		start, end = line.valueStart, line.valueStart+len(line.value)
+		cursor.keyPath = []string{line.value}
+	default:
+		var elementStart, elementEnd int
+		cursor.text, elementStart, elementEnd = getFlowElementAt(line.value, column-line.valueStart)
+		start, end = line.valueStart+elementStart, line.valueStart+elementEnd
+		if line.key != "" {
+			cursor.keyPath = []string{line.key}
+		}
+	}
+	cursor.range_ = protocol.Range{
+		Start: protocol.Position{Line: position.Line, Character: uint32(start)},
+		End:   protocol.Position{Line: position.Line, Character: uint32(end)},
+	}
+
+	// Walk up the lines to find the parent keys. A parent of a line is the closest preceding
+	// line with less indentation. Sequence items may have the same indentation as the key of
+	// the sequence, and keys of mappings in sequence items are not parents.
+	indent, inItem := line.indent, line.isItem
+	for i := int(position.Line) - 1; i >= 0 && (indent > 0 || inItem); i-- {
+		parentLine, ok := parseConfigLine(c.lines[i])
+		if !ok {
+			continue
+		}
+		if parentLine.indent > indent || parentLine.indent == indent && (!inItem || parentLine.isItem) {
+			continue
+		}
+		indent, inItem = parentLine.indent, parentLine.isItem
+		if parentLine.isItem {
+			continue
+		}
+		if parentLine.key == "" {
+			return nil
+		}
+		cursor.keyPath = append([]string{parentLine.key}, cursor.keyPath...)
+	}
+	return cursor
+}
+
+// configLine is a line of a configuration file.
+type configLine struct {
+	// indent is the indentation of the line, including the "-" of sequence items.
+	indent int
+	// isItem is whether the line starts a sequence item.
+	isItem bool
+	// contentStart is the offset of the content of the line after the indentation and
+	// the "-" of sequence items.
+	contentStart int
+	// key is the key of the line, if any.
+	key string
+	// valueStart is the offset of the value, which is the content after the key if any.
+	valueStart int
+	value      string
+}
+
+// parseConfigLine parses a line of a configuration file.
+//
+// Returns false if the line is empty or a comment.
+func parseConfigLine(text string) (configLine, bool) {
+	text = strings.TrimRight(text, "\r")
+	for i := 0; i < len(text); i++ {
+		if text[i] == '#' && (i == 0 || text[i-1] == ' ' || text[i-1] == '\t') {
+			text = text[:i]
+			break
+		}
+	}
+	text = strings.TrimRight(text, " \t")
+	content := strings.TrimLeft(text, " ")
+	if content == "" {
+		return configLine{}, false
+	}
+	line := configLine{indent: len(text) - len(content)}
+	if content == "-" || strings.HasPrefix(content, "- ") {
+		line.isItem = true
+		content = strings.TrimLeft(content[1:], " ")
+	}
+	line.contentStart = len(text) - len(content)
+	line.valueStart = line.contentStart
+	line.value = content
+	if match := configKeyPattern.FindStringSubmatch(content); match != nil {
+		line.key = match[1]
+		line.value = strings.TrimLeft(content[len(match[0]):], " \t")
+		line.valueStart = len(text) - len(line.value)
+	}
+	return line, true
+}
+
+// getFlowElementAt returns the element of the value at the offset, with its start and end
+// offsets. If the value is a flow sequence, this is the element of the sequence at the offset,
+// otherwise this is the value itself.
+func getFlowElementAt(value string, offset int) (string, int, int) {
+	start, end := 0, len(value)
+	if strings.HasPrefix(value, "[") {
+		offset = min(max(offset, 1), len(value))
+		// After the closing bracket, there is no element.
+		start = strings.LastIndexAny(value[:offset], "[,]") + 1
+		end = offset
+		if index := strings.IndexAny(value[offset:], ",]"); index >= 0 {
+			end += index
+		} else {
+			end = len(value)
+		}
+	}
+	element := value[start:end]
+	start += len(element) - len(strings.TrimLeft(element, " \t"))
+	element = strings.TrimSpace(element)
+	end = start + len(element)
+	return strings.Trim(element, `"'`), start, end
+}
+
+// formatRuleOrCategoryDocs formats the documentation of a rule or category.
+func formatRuleOrCategoryDocs(ruleOrCategory bufcheck.RuleOrCategory) string {
+	kind := "rule"
+	if _, ok := ruleOrCategory.(bufcheck.Category); ok {
+		kind = "category"
+	}
+	docs := fmt.Sprintf("`%s` (%s)\n\n%s", ruleOrCategory.ID(), kind, ruleOrCategory.Purpose())
+	if ruleOrCategory.Deprecated() {
+		docs += "\n\nDeprecated."
+		if replacementIDs := ruleOrCategory.ReplacementIDs(); len(replacementIDs) > 0 {
+			docs += fmt.Sprintf(" Use %s instead.", strings.Join(replacementIDs, ", "))
+		}
+	}
+	return docs
+}
+
+// getFileOptionNames returns the names of the file options of managed mode.
+func getFileOptionNames() []string {
+	var fileOptionNames []string
+	for fileOption := bufconfig.FileOptionJavaPackage; fileOption <= bufconfig.FileOptionRubyPackageSuffix; fileOption++ {
+		fileOptionNames = append(fileOptionNames, fileOption.String())
+	}
+	return fileOptionNames
+}
+
+// getFieldOptionNames returns the names of the field options of managed mode.
+func getFieldOptionNames() []string {
+	return []string{bufconfig.FieldOptionJSType.String()}
+}
+
+// getYAMLMappingValue returns the value of the key of the mapping node, or nil if the node
+// is not a mapping or does not have the key.
+func getYAMLMappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// getYAMLSequenceItems returns the items of the sequence node, or nil if the node is not
+// a sequence.
+func getYAMLSequenceItems(node *yaml.Node) []*yaml.Node {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	return node.Content
+}
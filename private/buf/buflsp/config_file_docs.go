@@ -0,0 +1,179 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file defines the documentation shown when hovering over the keys of configuration files.
+
+package buflsp
+
+import (
+	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
+)
+
+// configFileNameToKeyPathToDocs maps a configuration file name to the documentation of each of
+// its keys.
+//
+// Keys are identified by their path of mapping keys joined with ".", without sequence indices.
+// The lint and breaking keys of modules in buf.yaml files are documented under the top-level
+// lint and breaking keys; see configCursor.docsKeyPath.
+var configFileNameToKeyPathToDocs = map[string]map[string]string{
+	bufconfig.DefaultBufYAMLFileName: {
+		"version": "The version of the file. One of `v1beta1`, `v1` or `v2`.",
+		"name":    "The full name of the module in the form `remote/owner/module`. Only valid for `v1` files.",
+		"modules": "The modules of the workspace. Each module is a directory of `.proto` files.",
+		"modules.path": "The path of the directory of the module, relative to the `buf.yaml` file. " +
+			"Paths must be unique and must not overlap.",
+		"modules.name": "The full name of the module in the form `remote/owner/module`. " +
+			"Required to push the module to the BSR.",
+		"modules.includes": "The directories or glob patterns of files to include in the module, " +
+			"relative to the `buf.yaml` file. By default, all `.proto` files in the module directory are included.",
+		"modules.excludes": "The directories or glob patterns of files to exclude from the module, " +
+			"relative to the `buf.yaml` file.",
+		"modules.lint":     "The lint configuration of the module. Overrides the top-level `lint` configuration.",
+		"modules.breaking": "The breaking configuration of the module. Overrides the top-level `breaking` configuration.",
+		"deps":             "The BSR modules the workspace depends on, such as `buf.build/googleapis/googleapis`. Pinned in `buf.lock` by `buf dep update`.",
+		"extends": "Local `.yaml` files or BSR modules with a `v1` `buf.yaml` to inherit lint, breaking and plugin settings from. " +
+			"`use`, `warn` and `info` are unioned, `except` is overridden, and `ignore` and `ignore_only` are merged.",
+		"replace":         "Replacements of dependencies with a local directory or another module reference. Replacements are never written to `buf.lock`.",
+		"replace.dep":     "The full name of the dependency to replace.",
+		"replace.path":    "The directory to replace the dependency with, relative to the `buf.yaml` file.",
+		"replace.module":  "The module reference to replace the dependency with.",
+		"verify":          "The public keys trusted to sign the modules of an owner.",
+		"verify.owner":    "The remote and owner of the signed modules, such as `buf.build/acme`.",
+		"verify.keys":     "The trusted ed25519 public keys of the owner.",
+		"build":           "The build configuration. Only valid for `v1beta1` and `v1` files.",
+		"build.roots":     "The root directories of the module. Only valid for `v1beta1` files.",
+		"build.excludes":  "The directories to exclude from the module, relative to the `buf.yaml` file.",
+		"plugins":         "The check plugins that provide additional lint and breaking rules.",
+		"plugins.plugin":  "The path to a local check plugin, a command with arguments, or a BSR plugin reference.",
+		"plugins.options": "The options to pass to the check plugin.",
+		"lint":            "The lint configuration of `buf lint`.",
+		"lint.use":        "The lint rules and categories to use. Defaults to `STANDARD`.",
+		"lint.except":     "The lint rules and categories to remove from `use`.",
+		"lint.warn":       "The lint rules and categories to report as warnings instead of errors.",
+		"lint.info":       "The lint rules and categories to report as informational instead of errors.",
+		"lint.ignore":     "The directories or files to ignore for all lint rules, relative to the `buf.yaml` file.",
+		"lint.ignore_only": "The directories or files to ignore for specific lint rules and categories, " +
+			"as a map from rule or category ID to paths relative to the `buf.yaml` file.",
+		"lint.enum_zero_value_suffix": "The suffix that the zero value of every enum must have for `ENUM_ZERO_VALUE_SUFFIX`. " +
+			"Defaults to `_UNSPECIFIED`.",
+		"lint.rpc_allow_same_request_response":           "Allow RPCs to use the same message for their request and response.",
+		"lint.rpc_allow_google_protobuf_empty_requests":  "Allow RPCs to use `google.protobuf.Empty` as their request.",
+		"lint.rpc_allow_google_protobuf_empty_responses": "Allow RPCs to use `google.protobuf.Empty` as their response.",
+		"lint.service_suffix":                            "The suffix that every service must have for `SERVICE_SUFFIX`. Defaults to `Service`.",
+		"lint.allow_comment_ignores":                     "Allow `buf:lint:ignore` comments to ignore lint failures. Only valid for `v1beta1` and `v1` files.",
+		"lint.disallow_comment_ignores":                  "Disallow `buf:lint:ignore` comments to ignore lint failures.",
+		"lint.disable_builtin":                           "Disable the builtin lint rules, so that only the rules of plugins are used.",
+		"lint.license_header":                            "The license header that every `.proto` file must have for the `LICENSE_HEADER_*` rules.",
+		"lint.license_header.template": "The path of the license header template, relative to the `buf.yaml` file. " +
+			"The template may reference `{{.CopyrightHolder}}` and `{{.YearRange}}`.",
+		"lint.license_header.copyright_holder": "The copyright holder to insert into the license header template.",
+		"lint.license_header.year_range":       "The year range of the license header, such as `2020-2024`.",
+		"breaking":                             "The breaking configuration of `buf breaking`.",
+		"breaking.use":                         "The breaking rules and categories to use. Defaults to `FILE`.",
+		"breaking.except":                      "The breaking rules and categories to remove from `use`.",
+		"breaking.warn":                        "The breaking rules and categories to report as warnings instead of errors.",
+		"breaking.info":                        "The breaking rules and categories to report as informational instead of errors.",
+		"breaking.ignore":                      "The directories or files to ignore for all breaking rules, relative to the `buf.yaml` file.",
+		"breaking.ignore_only": "The directories or files to ignore for specific breaking rules and categories, " +
+			"as a map from rule or category ID to paths relative to the `buf.yaml` file.",
+		"breaking.ignore_unstable_packages":       "Ignore packages with a last component that is an unstable version, such as `v1alpha1` or `v1beta1`.",
+		"breaking.allow_comment_ignores":          "Allow `buf:breaking:ignore` comments to acknowledge breaking changes.",
+		"breaking.require_comment_ignore_reasons": "Require a reason after every `buf:breaking:ignore` comment.",
+		"breaking.disable_builtin":                "Disable the builtin breaking rules, so that only the rules of plugins are used.",
+	},
+	bufGenYAMLFileName: {
+		"version":                      "The version of the file. One of `v1beta1`, `v1` or `v2`.",
+		"clean":                        "Delete the output directories of the plugins before generating.",
+		"managed":                      "The managed mode configuration, which sets file and field options of the generated files.",
+		"managed.enabled":              "Enable managed mode.",
+		"managed.disable":              "Rules to disable managed mode for options, files, fields or modules.",
+		"managed.disable.file_option":  "The file option to disable, such as `java_package`.",
+		"managed.disable.field_option": "The field option to disable, such as `jstype`.",
+		"managed.disable.module":       "The module to disable managed mode for.",
+		"managed.disable.path":         "The directory or file to disable managed mode for.",
+		"managed.disable.field":        "The fully-qualified name of the field to disable managed mode for.",
+		"managed.override":             "Rules to override the values of file and field options.",
+		"managed.override.file_option": "The file option to override, such as `java_package`. " +
+			"Options ending in `_prefix` or `_suffix` set a prefix or suffix of the default value.",
+		"managed.override.field_option": "The field option to override, such as `jstype`.",
+		"managed.override.module":       "The module to override the option for.",
+		"managed.override.path":         "The directory or file to override the option for.",
+		"managed.override.field":        "The fully-qualified name of the field to override the option for.",
+		"managed.override.value":        "The value of the option.",
+		"plugins":                       "The plugins to generate code with.",
+		"plugins.remote":                "The BSR plugin to generate code with, such as `buf.build/protocolbuffers/go`.",
+		"plugins.name":                  "The name of the plugin, such as `go` for `protoc-gen-go`. Only valid for `v1beta1` and `v1` files.",
+		"plugins.plugin":                "The name of the plugin, or the BSR plugin to generate code with. Only valid for `v1` files.",
+		"plugins.path":                  "The path to the plugin binary, or a command with arguments. Only valid for `v1` files.",
+		"plugins.revision":              "The revision of the BSR plugin.",
+		"plugins.local":                 "The local plugin binary to generate code with, or a command with arguments. Paths ending in `.wasm` are run as WebAssembly modules.",
+		"plugins.protoc_builtin":        "The plugin built into `protoc` to generate code with, such as `java`.",
+		"plugins.protoc_path":           "The path to `protoc`, or a command with arguments, for `protoc_builtin` plugins.",
+		"plugins.out":                   "The output directory of the plugin, relative to the current directory.",
+		"plugins.opt":                   "The options to pass to the plugin.",
+		"plugins.strategy":              "The invocation strategy of the plugin. One of `directory` or `all`.",
+		"plugins.include_imports":       "Generate code for the imports of the input files.",
+		"plugins.include_wkt":           "Generate code for the well-known types imported by the input files. Requires `include_imports`.",
+		"plugins.types":                 "The types to generate code for.",
+		"plugins.exclude_types":         "The types to exclude from generation.",
+		"plugins.timeout":               "The maximum run time of a `local` plugin, such as `30s`.",
+		"plugins.max_output_bytes":      "The maximum number of bytes a `local` plugin may write.",
+		"plugins.env":                   "The environment variables passed to a `local` plugin.",
+		"inputs":                        "The inputs to generate code for. Defaults to the current directory.",
+		"inputs.directory":              "A local directory input.",
+		"inputs.module":                 "A BSR module input.",
+		"inputs.proto_file":             "A single `.proto` file input.",
+		"inputs.git_repo":               "A git repository input.",
+		"inputs.tarball":                "A tarball input.",
+		"inputs.zip_archive":            "A zip archive input.",
+		"inputs.binary_image":           "A binary Buf image input.",
+		"inputs.json_image":             "A JSON Buf image input.",
+		"inputs.text_image":             "A text Buf image input.",
+		"inputs.yaml_image":             "A YAML Buf image input.",
+		"inputs.reflect":                "The address of a server to read the schema from with gRPC server reflection.",
+		"inputs.types":                  "The types of the input to generate code for.",
+		"inputs.exclude_types":          "The types of the input to exclude from generation.",
+		"inputs.paths":                  "The paths of the input to generate code for.",
+		"inputs.exclude_paths":          "The paths of the input to exclude from generation.",
+		"inputs.include_package_files":  "Include all files of the packages of the `proto_file` input.",
+		"inputs.compression":            "The compression of the `tarball` input. One of `none`, `gzip` or `zstd`.",
+		"inputs.strip_components":       "The number of directories to strip from the paths of the `tarball` or `zip_archive` input.",
+		"inputs.subdir":                 "The subdirectory of the input to use.",
+		"inputs.branch":                 "The branch of the `git_repo` input.",
+		"inputs.tag":                    "The tag of the `git_repo` input.",
+		"inputs.ref":                    "The ref of the `git_repo` input.",
+		"inputs.depth":                  "The depth to clone the `git_repo` input with.",
+		"inputs.recurse_submodules":     "Clone the submodules of the `git_repo` input.",
+	},
+	bufconfig.DefaultBufLockFileName: {
+		"version":          "The version of the file. One of `v1beta1`, `v1` or `v2`. Managed by `buf dep update`.",
+		"deps":             "The pinned dependencies of the workspace.",
+		"deps.name":        "The full name of the dependency.",
+		"deps.commit":      "The pinned commit of the dependency.",
+		"deps.digest":      "The digest of the content of the dependency at the pinned commit.",
+		"plugins":          "The pinned check plugins of the workspace.",
+		"plugins.name":     "The full name of the plugin.",
+		"plugins.commit":   "The pinned commit of the plugin.",
+		"plugins.digest":   "The digest of the content of the plugin at the pinned commit.",
+		"extends":          "The pinned modules that the `buf.yaml` extends.",
+		"extends.name":     "The full name of the extended module.",
+		"extends.commit":   "The pinned commit of the extended module.",
+		"extends.digest":   "The digest of the content of the extended module at the pinned commit.",
+		"deps.remote":      "The remote of the dependency. Only valid for `v1beta1` and `v1` files.",
+		"deps.owner":       "The owner of the dependency. Only valid for `v1beta1` and `v1` files.",
+		"deps.repository":  "The repository of the dependency. Only valid for `v1beta1` and `v1` files.",
+		"deps.branch":      "The branch of the dependency. Only valid for `v1beta1` files.",
+		"deps.create_time": "The time the pinned commit was created. Only valid for `v1beta1` files.",
+	},
+}
@@ -0,0 +1,399 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buflsp
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"buf.build/go/bufplugin/check"
+	"github.com/bufbuild/buf/private/bufpkg/bufcheck"
+	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
+	"github.com/bufbuild/buf/private/pkg/slogtestext"
+	"github.com/bufbuild/buf/private/pkg/wasm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.lsp.dev/protocol"
+	"go.lsp.dev/uri"
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseConfigLine(t *testing.T) {
+	t.Parallel()
+	testParseConfigLine(t, "", configLine{}, false)
+	testParseConfigLine(t, "   ", configLine{}, false)
+	testParseConfigLine(t, "# comment", configLine{}, false)
+	testParseConfigLine(t, "  # comment", configLine{}, false)
+	testParseConfigLine(
+		t,
+		"version: v2",
+		configLine{key: "version", valueStart: 9, value: "v2"},
+		true,
+	)
+	testParseConfigLine(
+		t,
+		"lint:\r",
+		configLine{key: "lint", valueStart: 5},
+		true,
+	)
+	testParseConfigLine(
+		t,
+		"  use: [STANDARD, COMMENTS] # the rules",
+		configLine{indent: 2, contentStart: 2, key: "use", valueStart: 7, value: "[STANDARD, COMMENTS]"},
+		true,
+	)
+	testParseConfigLine(
+		t,
+		"    - STANDARD",
+		configLine{indent: 4, isItem: true, contentStart: 6, valueStart: 6, value: "STANDARD"},
+		true,
+	)
+	testParseConfigLine(
+		t,
+		"  - path: proto",
+		configLine{indent: 2, isItem: true, contentStart: 4, key: "path", valueStart: 10, value: "proto"},
+		true,
+	)
+	testParseConfigLine(
+		t,
+		"  -",
+		configLine{indent: 2, isItem: true, contentStart: 3, valueStart: 3},
+		true,
+	)
+	// A "#" within a value is not a comment.
+	testParseConfigLine(
+		t,
+		"name: buf.build/acme/a#b",
+		configLine{key: "name", valueStart: 6, value: "buf.build/acme/a#b"},
+		true,
+	)
+	// A key that is still being typed.
+	testParseConfigLine(
+		t,
+		"  ign",
+		configLine{indent: 2, contentStart: 2, valueStart: 2, value: "ign"},
+		true,
+	)
+}
+
+func TestGetFlowElementAt(t *testing.T) {
+	t.Parallel()
+	testGetFlowElementAt(t, "STANDARD", 3, "STANDARD", 0, 8)
+	testGetFlowElementAt(t, `"STANDARD"`, 0, "STANDARD", 0, 10)
+	testGetFlowElementAt(t, "[STANDARD, COMMENTS]", 0, "STANDARD", 1, 9)
+	testGetFlowElementAt(t, "[STANDARD, COMMENTS]", 4, "STANDARD", 1, 9)
+	testGetFlowElementAt(t, "[STANDARD, COMMENTS]", 12, "COMMENTS", 11, 19)
+	testGetFlowElementAt(t, "[STANDARD, COMMENTS]", 20, "", 20, 20)
+	testGetFlowElementAt(t, "[STANDARD, 'COMMENTS']", 13, "COMMENTS", 11, 21)
+	// A flow sequence that is still being typed.
+	testGetFlowElementAt(t, "[STANDARD, COMM", 15, "COMM", 11, 15)
+	testGetFlowElementAt(t, "[]", 1, "", 1, 1)
+}
+
+func TestConfigFileCursorAt(t *testing.T) {
+	t.Parallel()
+	configFile := &configFile{
+		fileName: bufconfig.DefaultBufYAMLFileName,
+		lines: strings.Split(
+			`version: v2
+modules:
+  - path: proto
+    lint:
+      use:
+        - STANDARD
+      except: [COMMENTS, PACKAGE_VERSION_SUFFIX]
+      ignore_only:
+        ENUM_ZERO_VALUE_SUFFIX:
+          - proto/foo.proto
+
+  # comment
+  - path: other
+breaking:
+  use:
+  - FILE
+    ex`,
+			"\n",
+		),
+	}
+	testConfigFileCursorAt(t, configFile, 0, 3, []string{"version"}, true, "version", 0, 7)
+	testConfigFileCursorAt(t, configFile, 0, 10, []string{"version"}, false, "v2", 9, 11)
+	testConfigFileCursorAt(t, configFile, 2, 6, []string{"modules", "path"}, true, "path", 4, 8)
+	testConfigFileCursorAt(t, configFile, 2, 12, []string{"modules", "path"}, false, "proto", 10, 15)
+	testConfigFileCursorAt(t, configFile, 4, 7, []string{"modules", "lint", "use"}, true, "use", 6, 9)
+	testConfigFileCursorAt(t, configFile, 5, 12, []string{"modules", "lint", "use"}, false, "STANDARD", 10, 18)
+	testConfigFileCursorAt(t, configFile, 6, 16, []string{"modules", "lint", "except"}, false, "COMMENTS", 15, 23)
+	testConfigFileCursorAt(t, configFile, 6, 30, []string{"modules", "lint", "except"}, false, "PACKAGE_VERSION_SUFFIX", 25, 47)
+	testConfigFileCursorAt(t, configFile, 8, 10, []string{"modules", "lint", "ignore_only", "ENUM_ZERO_VALUE_SUFFIX"}, true, "ENUM_ZERO_VALUE_SUFFIX", 8, 30)
+	testConfigFileCursorAt(t, configFile, 9, 14, []string{"modules", "lint", "ignore_only", "ENUM_ZERO_VALUE_SUFFIX"}, false, "proto/foo.proto", 12, 27)
+	// Empty lines and comments between items are skipped.
+	testConfigFileCursorAt(t, configFile, 12, 12, []string{"modules", "path"}, false, "other", 10, 15)
+	// Sequence items may have the same indentation as the key of the sequence.
+	testConfigFileCursorAt(t, configFile, 15, 4, []string{"breaking", "use"}, false, "FILE", 4, 8)
+	// A key that is still being typed.
+	testConfigFileCursorAt(t, configFile, 16, 6, []string{"breaking", "use", "ex"}, true, "ex", 4, 6)
+	// Indentation, empty lines, comments and positions after the end of the file have no cursor.
+	assert.Nil(t, configFile.cursorAt(protocol.Position{Line: 2, Character: 1}))
+	assert.Nil(t, configFile.cursorAt(protocol.Position{Line: 10, Character: 0}))
+	assert.Nil(t, configFile.cursorAt(protocol.Position{Line: 11, Character: 4}))
+	assert.Nil(t, configFile.cursorAt(protocol.Position{Line: 17, Character: 0}))
+}
+
+func TestConfigFileDefinition(t *testing.T) {
+	t.Parallel()
+	configFile := newTestConfigFile(
+		t,
+		bufconfig.DefaultBufYAMLFileName,
+		`version: v2
+modules:
+  - path: "my protos#1/ä"
+`,
+	)
+	locations := configFile.Definition(protocol.Position{Line: 2, Character: 14})
+	require.Len(t, locations, 1)
+	// Spaces, '#' and non-ASCII characters are percent-encoded.
+	assert.True(t, strings.HasSuffix(string(locations[0].URI), "/my%20protos%231/%C3%A4"), string(locations[0].URI))
+	assert.Equal(
+		t,
+		filepath.Join(filepath.Dir(configFile.file.uri.Filename()), "my protos#1", "ä"),
+		locations[0].URI.Filename(),
+	)
+	assert.Nil(t, configFile.Definition(protocol.Position{Line: 2, Character: 6}))
+}
+
+func TestConfigFileNewErrorDiagnostics(t *testing.T) {
+	t.Parallel()
+	configFile := &configFile{
+		lines: []string{
+			"version: v2",
+			"lint:",
+			"  use: STANDARD  ",
+			"  except: [",
+		},
+	}
+	var document yaml.Node
+	err := yaml.Unmarshal([]byte("version: v2\nlint:\n  use: STANDARD\n  except: [\n"), &document)
+	require.Error(t, err)
+	diagnostics, hasPosition := configFile.newErrorDiagnostics(err)
+	assert.True(t, hasPosition)
+	require.Len(t, diagnostics, 1)
+	assert.Equal(t, protocol.DiagnosticSeverityError, diagnostics[0].Severity)
+	assert.Equal(t, serverName, diagnostics[0].Source)
+	assert.NotContains(t, diagnostics[0].Message, "line")
+
+	// The internal types of bufconfig are removed from the messages, and the diagnostics
+	// span the content of the lines.
+	diagnostics, hasPosition = configFile.newErrorDiagnostics(
+		errors.New("yaml: unmarshal errors:\n  line 3: cannot unmarshal !!str `STANDARD` into []string in type bufconfig.externalBufYAMLFileLintV2\n  line 1: unknown field"),
+	)
+	assert.True(t, hasPosition)
+	assert.Equal(
+		t,
+		[]protocol.Diagnostic{
+			{
+				Range:    newTestRange(2, 2, 2, 15),
+				Severity: protocol.DiagnosticSeverityError,
+				Message:  "cannot unmarshal !!str `STANDARD` into []string",
+				Source:   serverName,
+			},
+			{
+				Range:    newTestRange(0, 0, 0, 11),
+				Severity: protocol.DiagnosticSeverityError,
+				Message:  "unknown field",
+				Source:   serverName,
+			},
+		},
+		diagnostics,
+	)
+
+	// Errors without a position span the first line.
+	diagnostics, hasPosition = configFile.newErrorDiagnostics(errors.New("invalid version"))
+	assert.False(t, hasPosition)
+	assert.Equal(
+		t,
+		[]protocol.Diagnostic{
+			{
+				Range:    newTestRange(0, 0, 0, 11),
+				Severity: protocol.DiagnosticSeverityError,
+				Message:  "invalid version",
+				Source:   serverName,
+			},
+		},
+		diagnostics,
+	)
+}
+
+func TestConfigFileCheckID(t *testing.T) {
+	t.Parallel()
+	// Deprecated rules are only part of the rules of v1 files.
+	configFile := newTestConfigFile(t, "buf.yaml", "version: v1\n")
+	idToRuleOrCategory := configFile.getIDToRuleOrCategory(context.Background(), check.RuleTypeBreaking)
+	require.NotNil(t, idToRuleOrCategory)
+
+	_, ok := configFile.checkID(idToRuleOrCategory, newTestIDNode("FILE", 1, 5, 0))
+	assert.False(t, ok)
+	_, ok = configFile.checkID(idToRuleOrCategory, newTestIDNode("FIELD_SAME_TYPE", 1, 5, 0))
+	assert.False(t, ok)
+	diagnostic, ok := configFile.checkID(idToRuleOrCategory, newTestIDNode("NOT_A_RULE", 3, 7, 0))
+	assert.True(t, ok)
+	assert.Equal(
+		t,
+		protocol.Diagnostic{
+			Range:    newTestRange(2, 6, 2, 16),
+			Severity: protocol.DiagnosticSeverityError,
+			Message:  `"NOT_A_RULE" is not a known rule or category ID`,
+			Source:   serverName,
+		},
+		diagnostic,
+	)
+	// Quotes are part of the range of the diagnostic.
+	diagnostic, ok = configFile.checkID(idToRuleOrCategory, newTestIDNode("FIELD_SAME_LABEL", 4, 7, yaml.DoubleQuotedStyle))
+	assert.True(t, ok)
+	assert.Equal(
+		t,
+		protocol.Diagnostic{
+			Range:    newTestRange(3, 6, 3, 24),
+			Severity: protocol.DiagnosticSeverityWarning,
+			Message:  `"FIELD_SAME_LABEL" is deprecated, use FIELD_SAME_CARDINALITY, FIELD_WIRE_COMPATIBLE_CARDINALITY, FIELD_WIRE_JSON_COMPATIBLE_CARDINALITY instead`,
+			Source:   serverName,
+		},
+		diagnostic,
+	)
+}
+
+func TestConfigFileRefreshRuleIDs(t *testing.T) {
+	t.Parallel()
+	configFile := newTestConfigFile(
+		t,
+		"buf.yaml",
+		`version: v2
+lint:
+  use:
+    - STANDARD
+    - NOT_A_RULE
+`,
+	)
+	configFile.Refresh(context.Background())
+	require.Len(t, configFile.file.diagnostics, 1)
+	assert.Equal(t, `"NOT_A_RULE" is not a known rule or category ID`, configFile.file.diagnostics[0].Message)
+	assert.Equal(t, newTestRange(4, 6, 4, 16), configFile.file.diagnostics[0].Range)
+
+	// Rules of plugins are not known, and plugins may be configured directly or in extended
+	// files, so rule IDs are not checked.
+	for _, text := range []string{
+		`version: v2
+lint:
+  use:
+    - STANDARD
+    - NOT_A_RULE
+plugins:
+  - plugin: buf-plugin-foo
+`,
+		`version: v2
+extends:
+  - ./base.yaml
+lint:
+  use:
+    - STANDARD
+    - NOT_A_RULE
+`,
+	} {
+		configFile := newTestConfigFile(t, "buf.yaml", text)
+		configFile.Refresh(context.Background())
+		assert.Empty(t, configFile.file.diagnostics, text)
+	}
+}
+
+func testParseConfigLine(t *testing.T, text string, expectedLine configLine, expectedOK bool) {
+	line, ok := parseConfigLine(text)
+	assert.Equal(t, expectedOK, ok, text)
+	assert.Equal(t, expectedLine, line, text)
+}
+
+func testGetFlowElementAt(t *testing.T, value string, offset int, expectedElement string, expectedStart int, expectedEnd int) {
+	element, start, end := getFlowElementAt(value, offset)
+	assert.Equal(t, expectedElement, element, "%q at %d", value, offset)
+	assert.Equal(t, expectedStart, start, "%q at %d", value, offset)
+	assert.Equal(t, expectedEnd, end, "%q at %d", value, offset)
+}
+
+func testConfigFileCursorAt(
+	t *testing.T,
+	configFile *configFile,
+	line uint32,
+	character uint32,
+	expectedKeyPath []string,
+	expectedIsKey bool,
+	expectedText string,
+	expectedStart uint32,
+	expectedEnd uint32,
+) {
+	cursor := configFile.cursorAt(protocol.Position{Line: line, Character: character})
+	require.NotNil(t, cursor, "%d:%d", line, character)
+	assert.Equal(
+		t,
+		&configCursor{
+			keyPath: expectedKeyPath,
+			isKey:   expectedIsKey,
+			text:    expectedText,
+			range_:  newTestRange(line, expectedStart, line, expectedEnd),
+		},
+		cursor,
+		"%d:%d",
+		line,
+		character,
+	)
+}
+
+// newTestConfigFile returns a new configFile for the text, with a check client for the
+// builtin rules.
+func newTestConfigFile(t *testing.T, fileName string, text string) *configFile {
+	logger := slogtestext.NewLogger(t)
+	checkClient, err := bufcheck.NewClient(logger, bufcheck.NewRunnerProvider(wasm.UnimplementedRuntime))
+	require.NoError(t, err)
+	configFile := newConfigFile(
+		&file{
+			lsp: &lsp{
+				logger:      logger,
+				checkClient: checkClient,
+			},
+			uri:  protocol.DocumentURI(uri.File(filepath.Join(t.TempDir(), fileName))),
+			text: text,
+		},
+	)
+	require.NotNil(t, configFile)
+	configFile.file.configFile = configFile
+	configFile.lines = strings.Split(text, "\n")
+	return configFile
+}
+
+func newTestIDNode(value string, line int, column int, style yaml.Style) *yaml.Node {
+	return &yaml.Node{
+		Kind:   yaml.ScalarNode,
+		Value:  value,
+		Line:   line,
+		Column: column,
+		Style:  style,
+	}
+}
+
+func newTestRange(startLine uint32, startCharacter uint32, endLine uint32, endCharacter uint32) protocol.Range {
+	return protocol.Range{
+		Start: protocol.Position{Line: startLine, Character: startCharacter},
+		End:   protocol.Position{Line: endLine, Character: endCharacter},
+	}
+}
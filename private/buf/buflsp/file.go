@@ -74,6 +74,10 @@ type file struct {
 	importToFile        map[string]*file
 	symbols             []*symbol
 	image, againstImage bufimage.Image
+
+	// configFile is set if this file is a buf.yaml, buf.gen.yaml or buf.lock file rather
+	// than a Protobuf file.
+	configFile *configFile
 }
 
 // IsWKT returns whether this file corresponds to a well-known type.
@@ -267,6 +271,12 @@ func getSetting[T, U any](f *file, settings []any, name string, index int, parse
 //
 // If deep is set, this will also load imports and refresh those, too.
 func (f *file) Refresh(ctx context.Context) {
+	if f.configFile != nil {
+		f.configFile.Refresh(ctx)
+		f.PublishDiagnostics(ctx)
+		return
+	}
+
 	var progress *progress
 	if f.IsOpenInEditor() {
 		// NOTE: Nil progress does nothing when methods are called. This helps
@@ -692,6 +702,13 @@ func (f *file) appendLintErrors(source string, err error) bool {
 	}
 
 	for _, annotation := range annotations.FileAnnotations() {
+		severity := protocol.DiagnosticSeverityError
+		switch annotation.Severity() {
+		case bufanalysis.SeverityWarning:
+			severity = protocol.DiagnosticSeverityWarning
+		case bufanalysis.SeverityInfo, bufanalysis.SeverityAcknowledged:
+			severity = protocol.DiagnosticSeverityInformation
+		}
 		f.diagnostics = append(f.diagnostics, protocol.Diagnostic{
 			Range: protocol.Range{
 				Start: protocol.Position{
@@ -704,7 +721,7 @@ func (f *file) appendLintErrors(source string, err error) bool {
 				},
 			},
 			Code:     annotation.Type(),
-			Severity: protocol.DiagnosticSeverityError,
+			Severity: severity,
 			Source:   source,
 			Message:  annotation.Message(),
 		})
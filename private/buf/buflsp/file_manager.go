@@ -43,6 +43,7 @@ func (fm *fileManager) Open(ctx context.Context, uri protocol.URI) *file {
 	if !found {
 		file.lsp = fm.lsp
 		file.uri = uri
+		file.configFile = newConfigFile(file)
 	}
 
 	return file
@@ -118,6 +118,7 @@ func (s *server) Initialize(
 					IncludeText: false,
 				},
 			},
+			CompletionProvider: &protocol.CompletionOptions{},
 			DefinitionProvider: &protocol.DefinitionOptions{
 				WorkDoneProgressOptions: protocol.WorkDoneProgressOptions{WorkDoneProgress: true},
 			},
@@ -254,6 +255,10 @@ func (s *server) Formatting(
 		// Format for a file we don't know about? Seems bad!
 		return nil, fmt.Errorf("received update for file that was not open: %q", params.TextDocument.URI)
 	}
+	if file.configFile != nil {
+		// Configuration files are not formatted.
+		return nil, nil
+	}
 
 	// We check the diagnostics on the file, if there are any build errors, we do not want
 	// to format an invalid AST, so we skip formatting and return an error for logging.
@@ -336,6 +341,9 @@ func (s *server) Hover(
 	if file == nil {
 		return nil, nil
 	}
+	if file.configFile != nil {
+		return file.configFile.Hover(ctx, params.Position), nil
+	}
 
 	symbol := file.SymbolAt(ctx, params.Position)
 	if symbol == nil {
@@ -370,6 +378,9 @@ func (s *server) Definition(
 	if file == nil {
 		return nil, nil
 	}
+	if file.configFile != nil {
+		return file.configFile.Definition(params.Position), nil
+	}
 
 	progress := newProgressFromClient(s.lsp, &params.WorkDoneProgressParams)
 	progress.Begin(ctx, "Searching")
@@ -396,6 +407,20 @@ func (s *server) Definition(
 	return nil, nil
 }
 
+// Completion is the entry point for code completion.
+//
+// Completion is only supported for buf.yaml and buf.gen.yaml files.
+func (s *server) Completion(
+	ctx context.Context,
+	params *protocol.CompletionParams,
+) (*protocol.CompletionList, error) {
+	file := s.fileManager.Get(params.TextDocument.URI)
+	if file == nil || file.configFile == nil {
+		return nil, nil
+	}
+	return file.configFile.Completion(ctx, params.Position), nil
+}
+
 // SemanticTokensFull is called to render semantic token information on the client.
 func (s *server) SemanticTokensFull(
 	ctx context.Context,
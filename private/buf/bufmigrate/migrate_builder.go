@@ -196,6 +196,7 @@ func (m *migrateBuilder) addModule(ctx context.Context, moduleDirPath string) (r
 				false,
 				"",
 				false,
+				nil,
 			),
 			bufconfig.NewBreakingConfig(
 				bufconfig.NewEnabledCheckConfigForUseIDsAndCategories(
@@ -204,6 +205,8 @@ func (m *migrateBuilder) addModule(ctx context.Context, moduleDirPath string) (r
 					false,
 				),
 				false,
+				false,
+				false,
 			),
 		)
 		if err != nil {
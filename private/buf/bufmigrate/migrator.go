@@ -401,6 +401,7 @@ func (m *migrator) buildBufYAMLAndBufLockFiles(
 				bufconfig.FileVersionV2,
 				resolvedLockEntries,
 				nil,
+				nil,
 			)
 			if err != nil {
 				return nil, nil, err
@@ -446,6 +447,7 @@ func (m *migrator) buildBufYAMLAndBufLockFiles(
 			bufconfig.FileVersionV2,
 			resolvedDepModuleKeys,
 			nil, // Plugins are not supported in v1.
+			nil, // Extends are not supported in v1.
 		)
 		if err != nil {
 			return nil, nil, err
@@ -662,6 +664,7 @@ func equivalentLintConfigInV2(
 		lintConfig.RPCAllowGoogleProtobufEmptyResponses(),
 		lintConfig.ServiceSuffix(),
 		lintConfig.AllowCommentIgnores(),
+		lintConfig.LicenseHeaderConfig(),
 	), nil
 }
 
@@ -682,6 +685,8 @@ func equivalentBreakingConfigInV2(
 	return bufconfig.NewBreakingConfig(
 		equivalentCheckConfigV2,
 		breakingConfig.IgnoreUnstablePackages(),
+		breakingConfig.AllowCommentIgnores(),
+		breakingConfig.RequireCommentIgnoreReasons(),
 	), nil
 }
 
@@ -721,6 +726,8 @@ func equivalentCheckConfigInV2(
 		bufconfig.FileVersionV2,
 		undeprecateSlice(checkConfig.UseIDsAndCategories(), deprecations),
 		undeprecateSlice(checkConfig.ExceptIDsAndCategories(), deprecations),
+		undeprecateSlice(checkConfig.WarnIDsAndCategories(), deprecations),
+		undeprecateSlice(checkConfig.InfoIDsAndCategories(), deprecations),
 		checkConfig.IgnorePaths(),
 		undeprecateMap(checkConfig.IgnoreIDOrCategoryToPaths(), deprecations),
 		checkConfig.DisableBuiltin(),
@@ -763,6 +770,8 @@ func equivalentCheckConfigInV2(
 		bufconfig.FileVersionV2,
 		append(simplyTranslatedCheckConfig.UseIDsAndCategories(), missingIDs...),
 		append(simplyTranslatedCheckConfig.ExceptIDsAndCategories(), extraIDs...),
+		simplyTranslatedCheckConfig.WarnIDsAndCategories(),
+		simplyTranslatedCheckConfig.InfoIDsAndCategories(),
 		simplyTranslatedCheckConfig.IgnorePaths(),
 		simplyTranslatedCheckConfig.IgnoreIDOrCategoryToPaths(),
 		simplyTranslatedCheckConfig.DisableBuiltin(),
@@ -28,6 +28,7 @@ import (
 	modulev1 "buf.build/gen/go/bufbuild/registry/protocolbuffers/go/buf/registry/module/v1"
 	ownerv1 "buf.build/gen/go/bufbuild/registry/protocolbuffers/go/buf/registry/owner/v1"
 	pluginv1beta1 "buf.build/gen/go/bufbuild/registry/protocolbuffers/go/buf/registry/plugin/v1beta1"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimageutil"
 	"github.com/bufbuild/buf/private/bufpkg/bufparse"
 	registryv1alpha1 "github.com/bufbuild/buf/private/gen/proto/go/buf/alpha/registry/v1alpha1"
 	"github.com/bufbuild/buf/private/pkg/protoencoding"
@@ -322,6 +323,16 @@ func NewStatsPrinter(writer io.Writer) StatsPrinter {
 	return newStatsPrinter(writer)
 }
 
+// NumbersPrinter is a printer of the used, reserved, and free numbers of messages and enums.
+type NumbersPrinter interface {
+	PrintNumbers(ctx context.Context, format Format, numbers ...*bufimageutil.Numbers) error
+}
+
+// NewNumbersPrinter returns a new NumbersPrinter.
+func NewNumbersPrinter(writer io.Writer) NumbersPrinter {
+	return newNumbersPrinter(writer)
+}
+
 // TabWriter is a tab writer.
 type TabWriter interface {
 	Write(values ...string) error
@@ -0,0 +1,107 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufprint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimageutil"
+)
+
+type numbersPrinter struct {
+	writer io.Writer
+}
+
+func newNumbersPrinter(writer io.Writer) *numbersPrinter {
+	return &numbersPrinter{
+		writer: writer,
+	}
+}
+
+func (p *numbersPrinter) PrintNumbers(ctx context.Context, format Format, numbers ...*bufimageutil.Numbers) error {
+	switch format {
+	case FormatText:
+		return WithTabWriter(
+			p.writer,
+			[]string{
+				"Name",
+				"Kind",
+				"Used",
+				"Reserved",
+				"Extensions",
+				"Free",
+				"Next",
+			},
+			func(tabWriter TabWriter) error {
+				for _, n := range numbers {
+					next := "none"
+					if n.Next != nil {
+						next = strconv.Itoa(int(*n.Next))
+					}
+					if err := tabWriter.Write(
+						n.Name,
+						n.Kind,
+						numberRangesString(n.Kind, usedToNumberRanges(n.Used)),
+						numberRangesString(n.Kind, n.Reserved),
+						numberRangesString(n.Kind, n.Extensions),
+						numberRangesString(n.Kind, n.Free),
+						next,
+					); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		)
+	case FormatJSON:
+		for _, n := range numbers {
+			if err := json.NewEncoder(p.writer).Encode(n); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown format: %v", format)
+	}
+}
+
+// usedToNumberRanges collapses the sorted numbers into ranges for display.
+func usedToNumberRanges(used []int32) []bufimageutil.NumberRange {
+	var ranges []bufimageutil.NumberRange
+	for _, number := range used {
+		if len(ranges) > 0 && ranges[len(ranges)-1].End+1 == number {
+			ranges[len(ranges)-1].End = number
+			continue
+		}
+		ranges = append(ranges, bufimageutil.NumberRange{Start: number, End: number})
+	}
+	return ranges
+}
+
+func numberRangesString(kind string, ranges []bufimageutil.NumberRange) string {
+	if len(ranges) == 0 {
+		return "-"
+	}
+	rangeStrings := make([]string, len(ranges))
+	for i, r := range ranges {
+		rangeStrings[i] = r.StringForKind(kind)
+	}
+	return strings.Join(rangeStrings, ", ")
+}
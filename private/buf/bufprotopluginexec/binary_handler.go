@@ -33,17 +33,20 @@ type binaryHandler struct {
 	logger     *slog.Logger
 	pluginPath string
 	pluginArgs []string
+	sandbox    *sandbox
 }
 
 func newBinaryHandler(
 	logger *slog.Logger,
 	pluginPath string,
 	pluginArgs []string,
+	sandbox *sandbox,
 ) *binaryHandler {
 	return &binaryHandler{
 		logger:     logger,
 		pluginPath: pluginPath,
 		pluginArgs: pluginArgs,
+		sandbox:    sandbox,
 	}
 }
 
@@ -60,20 +63,31 @@ func (h *binaryHandler) Handle(
 		return err
 	}
 	responseBuffer := bytes.NewBuffer(nil)
-	stderrWriteCloser := newStderrWriteCloser(pluginEnv.Stderr, h.pluginPath)
-	runOptions := []execext.RunOption{
-		execext.WithEnv(pluginEnv.Environ),
-		execext.WithStdin(bytes.NewReader(requestData)),
-		execext.WithStdout(responseBuffer),
-		execext.WithStderr(stderrWriteCloser),
-	}
-	if len(h.pluginArgs) > 0 {
-		runOptions = append(runOptions, execext.WithArgs(h.pluginArgs...))
-	}
-	if err := execext.Run(
+	if err := h.sandbox.run(
 		ctx,
-		h.pluginPath,
-		runOptions...,
+		responseBuffer,
+		pluginEnv.Stderr,
+		func(ctx context.Context, stdout io.Writer, stderr io.Writer) error {
+			runOptions := []execext.RunOption{
+				execext.WithEnv(h.sandbox.environ(pluginEnv.Environ)),
+				execext.WithStdin(bytes.NewReader(requestData)),
+				execext.WithStdout(stdout),
+				execext.WithStderr(newStderrWriteCloser(stderr, h.pluginPath)),
+			}
+			if len(h.pluginArgs) > 0 {
+				runOptions = append(runOptions, execext.WithArgs(h.pluginArgs...))
+			}
+			if h.sandbox != nil {
+				// Do not wait on child processes of the plugin that keep its
+				// output open once the plugin has been killed.
+				runOptions = append(runOptions, execext.WithWaitDelay(sandboxWaitDelay))
+			}
+			return execext.Run(
+				ctx,
+				h.pluginPath,
+				runOptions...,
+			)
+		},
 	); err != nil {
 		return err
 	}
@@ -24,10 +24,13 @@ import (
 	"fmt"
 	"log/slog"
 	"os/exec"
+	"path/filepath"
+	"time"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
 	"github.com/bufbuild/buf/private/pkg/app"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"github.com/bufbuild/buf/private/pkg/wasm"
 	"github.com/bufbuild/protoplugin"
 	"google.golang.org/protobuf/types/pluginpb"
 )
@@ -62,7 +65,8 @@ type Generator interface {
 	// Generate generates a CodeGeneratorResponse for the given pluginName. The
 	// pluginName must be available on the system's PATH or one of the plugins
 	// built-in to protoc. The plugin path can be overridden via the
-	// GenerateWithPluginPath option.
+	// GenerateWithPluginPath option. If the plugin path has a ".wasm" extension,
+	// the plugin is run as a WebAssembly module instead of as a binary.
 	Generate(
 		ctx context.Context,
 		container app.EnvStderrContainer,
@@ -73,11 +77,14 @@ type Generator interface {
 }
 
 // NewGenerator returns a new Generator.
+//
+// The wasm.Runtime is used to run plugins that are WebAssembly modules.
 func NewGenerator(
 	logger *slog.Logger,
 	storageosProvider storageos.Provider,
+	wasmRuntime wasm.Runtime,
 ) Generator {
-	return newGenerator(logger, storageosProvider)
+	return newGenerator(logger, storageosProvider, wasmRuntime)
 }
 
 // GenerateOption is an option for Generate.
@@ -100,18 +107,55 @@ func GenerateWithProtocPath(protocPath ...string) GenerateOption {
 	}
 }
 
+// GenerateWithTimeout returns a new GenerateOption that sets the maximum duration
+// of a single invocation of a local plugin.
+//
+// The default is no timeout.
+func GenerateWithTimeout(timeout time.Duration) GenerateOption {
+	return func(generateOptions *generateOptions) {
+		generateOptions.timeout = timeout
+	}
+}
+
+// GenerateWithMaxOutputBytes returns a new GenerateOption that sets the maximum number
+// of bytes a single invocation of a local plugin may write to stdout or stderr.
+//
+// The default is no limit.
+func GenerateWithMaxOutputBytes(maxOutputBytes int64) GenerateOption {
+	return func(generateOptions *generateOptions) {
+		generateOptions.maxOutputBytes = maxOutputBytes
+	}
+}
+
+// GenerateWithEnv returns a new GenerateOption that restricts the environment
+// variables passed to a local binary plugin to the given names.
+//
+// If env is nil, the plugin is passed the full environment, which is the default.
+// If env is non-nil but empty, the plugin is passed no environment variables.
+func GenerateWithEnv(env []string) GenerateOption {
+	return func(generateOptions *generateOptions) {
+		generateOptions.env = env
+	}
+}
+
 // NewHandler returns a new Handler based on the plugin name and optional path.
 //
 // protocPath and pluginPath are optional.
 //
-//   - If the plugin path is set, this returns a new binary handler for that path.
+//   - If the plugin path is set and has a ".wasm" extension, this returns a new Wasm handler for that path.
+//   - Else, if the plugin path is set, this returns a new binary handler for that path.
 //   - If the plugin path is unset, this does exec.LookPath for a binary named protoc-gen-pluginName,
 //     and if one is found, a new binary handler is returned for this.
 //   - Else, if the name is in ProtocProxyPluginNames, this returns a new protoc proxy handler.
 //   - Else, this returns error.
+//
+// The timeout, max output bytes and env options are enforced for plugins run with a binary
+// handler or a Wasm handler. A plugin that has any of these options set is considered sandboxed:
+// its stderr is captured instead of streamed, and is included in the error if the plugin fails.
 func NewHandler(
 	logger *slog.Logger,
 	storageosProvider storageos.Provider,
+	wasmRuntime wasm.Runtime,
 	pluginName string,
 	options ...HandlerOption,
 ) (protoplugin.Handler, error) {
@@ -123,12 +167,25 @@ func NewHandler(
 	// Initialize binary plugin handler when path is specified with optional args. Return
 	// on error as something is wrong with the supplied pluginPath option.
 	if len(handlerOptions.pluginPath) > 0 {
-		return NewBinaryHandler(logger, handlerOptions.pluginPath[0], handlerOptions.pluginPath[1:])
+		if isWasmPluginPath(handlerOptions.pluginPath[0]) {
+			return newWasmHandler(
+				logger,
+				wasmRuntime,
+				handlerOptions.pluginPath[0],
+				handlerOptions.pluginPath[1:],
+				handlerOptions.newSandbox(),
+			), nil
+		}
+		pluginPath, err := unsafeLookPath(handlerOptions.pluginPath[0])
+		if err != nil {
+			return nil, err
+		}
+		return newBinaryHandler(logger, pluginPath, handlerOptions.pluginPath[1:], handlerOptions.newSandbox()), nil
 	}
 
 	// Initialize binary plugin handler based on plugin name.
-	if handler, err := NewBinaryHandler(logger, "protoc-gen-"+pluginName, nil); err == nil {
-		return handler, nil
+	if pluginPath, err := unsafeLookPath("protoc-gen-" + pluginName); err == nil {
+		return newBinaryHandler(logger, pluginPath, nil, handlerOptions.newSandbox()), nil
 	}
 
 	// Initialize builtin protoc plugin handler. We always look for protoc-gen-X first,
@@ -175,6 +232,38 @@ func HandlerWithPluginPath(pluginPath ...string) HandlerOption {
 	}
 }
 
+// HandlerWithTimeout returns a new HandlerOption that sets the maximum duration
+// of a single invocation of the plugin.
+//
+// The default is no timeout.
+func HandlerWithTimeout(timeout time.Duration) HandlerOption {
+	return func(handlerOptions *handlerOptions) {
+		handlerOptions.timeout = timeout
+	}
+}
+
+// HandlerWithMaxOutputBytes returns a new HandlerOption that sets the maximum number
+// of bytes a single invocation of the plugin may write to stdout or stderr.
+//
+// The default is no limit.
+func HandlerWithMaxOutputBytes(maxOutputBytes int64) HandlerOption {
+	return func(handlerOptions *handlerOptions) {
+		handlerOptions.maxOutputBytes = maxOutputBytes
+	}
+}
+
+// HandlerWithEnv returns a new HandlerOption that restricts the environment
+// variables passed to a binary plugin to the given names.
+//
+// If env is nil, the plugin is passed the full environment, which is the default.
+// If env is non-nil but empty, the plugin is passed no environment variables.
+// Wasm plugins are never passed environment variables.
+func HandlerWithEnv(env []string) HandlerOption {
+	return func(handlerOptions *handlerOptions) {
+		handlerOptions.env = env
+	}
+}
+
 // NewBinaryHandler returns a new Handler that invokes the specific plugin
 // specified by pluginPath.
 func NewBinaryHandler(logger *slog.Logger, pluginPath string, pluginArgs []string) (protoplugin.Handler, error) {
@@ -182,18 +271,50 @@ func NewBinaryHandler(logger *slog.Logger, pluginPath string, pluginArgs []strin
 	if err != nil {
 		return nil, err
 	}
-	return newBinaryHandler(logger, pluginPath, pluginArgs), nil
+	return newBinaryHandler(logger, pluginPath, pluginArgs, nil), nil
+}
+
+// NewWasmHandler returns a new Handler that runs the WebAssembly module
+// specified by pluginPath with the given wasm.Runtime.
+//
+// The module must target WASI. The pluginPath is resolved relative to the current
+// directory first, and then on the PATH. The CodeGeneratorRequest is passed on stdin
+// and the CodeGeneratorResponse is read from stdout. The module has no access to the
+// filesystem, network, or environment variables.
+func NewWasmHandler(
+	logger *slog.Logger,
+	wasmRuntime wasm.Runtime,
+	pluginPath string,
+	pluginArgs []string,
+) (protoplugin.Handler, error) {
+	return newWasmHandler(logger, wasmRuntime, pluginPath, pluginArgs, nil), nil
 }
 
 type handlerOptions struct {
-	pluginPath []string
-	protocPath []string
+	pluginPath     []string
+	protocPath     []string
+	timeout        time.Duration
+	maxOutputBytes int64
+	env            []string
 }
 
 func newHandlerOptions() *handlerOptions {
 	return &handlerOptions{}
 }
 
+// newSandbox returns a new sandbox for the options, or nil if no limits are set.
+func (h *handlerOptions) newSandbox() *sandbox {
+	if h.timeout == 0 && h.maxOutputBytes == 0 && h.env == nil {
+		return nil
+	}
+	return newSandbox(h.timeout, h.maxOutputBytes, h.env)
+}
+
+// isWasmPluginPath returns true if the plugin path refers to a WebAssembly module.
+func isWasmPluginPath(pluginPath string) bool {
+	return filepath.Ext(pluginPath) == ".wasm"
+}
+
 // unsafeLookPath is a wrapper around exec.LookPath that restores the original
 // pre-Go 1.19 behavior of resolving queries that would use relative PATH
 // entries. We consider it acceptable for the use case of locating plugins.
@@ -17,25 +17,30 @@ package bufprotopluginexec
 import (
 	"context"
 	"log/slog"
+	"time"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufprotoplugin"
 	"github.com/bufbuild/buf/private/pkg/app"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"github.com/bufbuild/buf/private/pkg/wasm"
 	"google.golang.org/protobuf/types/pluginpb"
 )
 
 type generator struct {
 	logger            *slog.Logger
 	storageosProvider storageos.Provider
+	wasmRuntime       wasm.Runtime
 }
 
 func newGenerator(
 	logger *slog.Logger,
 	storageosProvider storageos.Provider,
+	wasmRuntime wasm.Runtime,
 ) *generator {
 	return &generator{
 		logger:            logger,
 		storageosProvider: storageosProvider,
+		wasmRuntime:       wasmRuntime,
 	}
 }
 
@@ -53,10 +58,14 @@ func (g *generator) Generate(
 	handlerOptions := []HandlerOption{
 		HandlerWithPluginPath(generateOptions.pluginPath...),
 		HandlerWithProtocPath(generateOptions.protocPath...),
+		HandlerWithTimeout(generateOptions.timeout),
+		HandlerWithMaxOutputBytes(generateOptions.maxOutputBytes),
+		HandlerWithEnv(generateOptions.env),
 	}
 	handler, err := NewHandler(
 		g.logger,
 		g.storageosProvider,
+		g.wasmRuntime,
 		pluginName,
 		handlerOptions...,
 	)
@@ -74,8 +83,11 @@ func (g *generator) Generate(
 }
 
 type generateOptions struct {
-	pluginPath []string
-	protocPath []string
+	pluginPath     []string
+	protocPath     []string
+	timeout        time.Duration
+	maxOutputBytes int64
+	env            []string
 }
 
 func newGenerateOptions() *generateOptions {
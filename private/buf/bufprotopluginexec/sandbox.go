@@ -0,0 +1,151 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufprotopluginexec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// sandboxWaitDelay is the maximum time to wait for the output of a sandboxed
+// plugin to be closed after the plugin has been killed.
+const sandboxWaitDelay = time.Second
+
+var errSandboxTimeout = errors.New("plugin timed out")
+
+// sandbox enforces the limits set for a plugin invocation.
+//
+// A nil sandbox has no limits.
+type sandbox struct {
+	timeout        time.Duration
+	maxOutputBytes int64
+	// env is the names of the environment variables passed to the plugin.
+	//
+	// If nil, all environment variables are passed.
+	env map[string]struct{}
+}
+
+func newSandbox(
+	timeout time.Duration,
+	maxOutputBytes int64,
+	env []string,
+) *sandbox {
+	var envMap map[string]struct{}
+	if env != nil {
+		envMap = make(map[string]struct{}, len(env))
+		for _, name := range env {
+			envMap[name] = struct{}{}
+		}
+	}
+	return &sandbox{
+		timeout:        timeout,
+		maxOutputBytes: maxOutputBytes,
+		env:            envMap,
+	}
+}
+
+// environ returns the subset of environ that is allowed to be passed to the plugin.
+func (s *sandbox) environ(environ []string) []string {
+	if s == nil || s.env == nil {
+		return environ
+	}
+	var filteredEnviron []string
+	for _, keyValue := range environ {
+		key, _, _ := strings.Cut(keyValue, "=")
+		if _, ok := s.env[key]; ok {
+			filteredEnviron = append(filteredEnviron, keyValue)
+		}
+	}
+	return filteredEnviron
+}
+
+// run calls f with the limits of the sandbox applied.
+//
+// The stderr of the plugin is captured, and only written to stderr if f succeeds.
+// If f fails, the captured stderr is added to the returned error instead.
+func (s *sandbox) run(
+	ctx context.Context,
+	stdout io.Writer,
+	stderr io.Writer,
+	f func(ctx context.Context, stdout io.Writer, stderr io.Writer) error,
+) error {
+	if s == nil {
+		return f(ctx, stdout, stderr)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if s.timeout > 0 {
+		var timeoutCancel context.CancelFunc
+		ctx, timeoutCancel = context.WithTimeoutCause(ctx, s.timeout, errSandboxTimeout)
+		defer timeoutCancel()
+	}
+	// The writers cancel the plugin as soon as the limit is exceeded, otherwise
+	// the plugin would block on a full pipe once we stopped reading its output.
+	limitedStdout := newLimitedWriter(stdout, s.maxOutputBytes, cancel)
+	stderrBuffer := bytes.NewBuffer(nil)
+	limitedStderr := newLimitedWriter(stderrBuffer, s.maxOutputBytes, cancel)
+	err := f(ctx, limitedStdout, limitedStderr)
+	switch {
+	case limitedStdout.exceeded:
+		err = fmt.Errorf("exceeded the maximum output of %d bytes on stdout", s.maxOutputBytes)
+	case limitedStderr.exceeded:
+		err = fmt.Errorf("exceeded the maximum output of %d bytes on stderr", s.maxOutputBytes)
+	case err != nil && errors.Is(context.Cause(ctx), errSandboxTimeout):
+		err = fmt.Errorf("timed out after %v", s.timeout)
+	}
+	if err != nil {
+		if capturedStderr := strings.TrimSpace(stderrBuffer.String()); capturedStderr != "" {
+			return fmt.Errorf("%w\nstderr:\n%s", err, capturedStderr)
+		}
+		return err
+	}
+	_, err = stderr.Write(stderrBuffer.Bytes())
+	return err
+}
+
+type limitedWriter struct {
+	delegate io.Writer
+	// limit is the maximum number of bytes to write. Zero means no limit.
+	limit    int64
+	onExceed func()
+	written  int64
+	exceeded bool
+}
+
+func newLimitedWriter(delegate io.Writer, limit int64, onExceed func()) *limitedWriter {
+	return &limitedWriter{
+		delegate: delegate,
+		limit:    limit,
+		onExceed: onExceed,
+	}
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if w.limit > 0 && w.written+int64(len(p)) > w.limit {
+		if !w.exceeded {
+			w.exceeded = true
+			w.onExceed()
+		}
+		return 0, fmt.Errorf("exceeded the maximum output of %d bytes", w.limit)
+	}
+	n, err := w.delegate.Write(p)
+	w.written += int64(n)
+	return n, err
+}
@@ -0,0 +1,132 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufprotopluginexec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSandboxEnviron(t *testing.T) {
+	t.Parallel()
+	environ := []string{"HOME=/home/foo", "PATH=/bin", "SECRET=bar", "EMPTY="}
+	var nilSandbox *sandbox
+	assert.Equal(t, environ, nilSandbox.environ(environ))
+	assert.Equal(t, environ, newSandbox(time.Second, 0, nil).environ(environ))
+	assert.Equal(t, []string{"PATH=/bin", "EMPTY="}, newSandbox(0, 0, []string{"PATH", "EMPTY", "OTHER"}).environ(environ))
+	assert.Empty(t, newSandbox(0, 0, []string{}).environ(environ))
+}
+
+func TestSandboxRunSuccess(t *testing.T) {
+	t.Parallel()
+	stdout := bytes.NewBuffer(nil)
+	stderr := bytes.NewBuffer(nil)
+	err := newSandbox(time.Minute, 16, nil).run(
+		context.Background(),
+		stdout,
+		stderr,
+		func(_ context.Context, stdout io.Writer, stderr io.Writer) error {
+			if _, err := stdout.Write([]byte("response")); err != nil {
+				return err
+			}
+			_, err := stderr.Write([]byte("warning\n"))
+			return err
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "response", stdout.String())
+	assert.Equal(t, "warning\n", stderr.String())
+}
+
+func TestSandboxRunFailureCapturesStderr(t *testing.T) {
+	t.Parallel()
+	stderr := bytes.NewBuffer(nil)
+	err := newSandbox(0, 0, []string{}).run(
+		context.Background(),
+		io.Discard,
+		stderr,
+		func(_ context.Context, _ io.Writer, stderr io.Writer) error {
+			_, _ = stderr.Write([]byte("something went wrong\n"))
+			return errors.New("exit status 1")
+		},
+	)
+	require.EqualError(t, err, "exit status 1\nstderr:\nsomething went wrong")
+	assert.Empty(t, stderr.String())
+}
+
+func TestSandboxRunMaxOutputBytes(t *testing.T) {
+	t.Parallel()
+	for _, useStderr := range []bool{false, true} {
+		err := newSandbox(0, 4, nil).run(
+			context.Background(),
+			io.Discard,
+			io.Discard,
+			func(ctx context.Context, stdout io.Writer, stderr io.Writer) error {
+				writer := stdout
+				if useStderr {
+					writer = stderr
+				}
+				if _, err := writer.Write([]byte("abc")); err != nil {
+					return err
+				}
+				if _, err := writer.Write([]byte("de")); err != nil {
+					return err
+				}
+				// The limit being exceeded cancels the plugin.
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		)
+		if useStderr {
+			require.ErrorContains(t, err, "exceeded the maximum output of 4 bytes on stderr")
+		} else {
+			require.ErrorContains(t, err, "exceeded the maximum output of 4 bytes on stdout")
+		}
+	}
+}
+
+func TestSandboxRunTimeout(t *testing.T) {
+	t.Parallel()
+	err := newSandbox(10*time.Millisecond, 0, nil).run(
+		context.Background(),
+		io.Discard,
+		io.Discard,
+		func(ctx context.Context, _ io.Writer, _ io.Writer) error {
+			<-ctx.Done()
+			return errors.New("signal: killed")
+		},
+	)
+	require.EqualError(t, err, "timed out after 10ms")
+	// A cancellation of the parent context is not reported as a timeout.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = newSandbox(time.Minute, 0, nil).run(
+		ctx,
+		io.Discard,
+		io.Discard,
+		func(ctx context.Context, _ io.Writer, _ io.Writer) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	)
+	require.ErrorIs(t, err, context.Canceled)
+}
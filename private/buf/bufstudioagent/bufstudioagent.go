@@ -31,7 +31,16 @@ func NewHandler(
 	disallowedHeaders map[string]struct{},
 	forwardHeaders map[string]string,
 	privateNetwork bool,
+	options ...HandlerOption,
 ) http.Handler {
+	handlerOptions := newHandlerOptions()
+	for _, option := range options {
+		option(handlerOptions)
+	}
+	var recorder *recorder
+	if handlerOptions.recordDirPath != "" {
+		recorder = newRecorder(logger, handlerOptions.recordDirPath, handlerOptions.recordCredentials)
+	}
 	corsHandlerOptions := cors.Options{
 		AllowedOrigins:   []string{origin},
 		AllowedMethods:   []string{http.MethodPost, http.MethodOptions},
@@ -41,7 +50,14 @@ func NewHandler(
 		corsHandlerOptions.AllowPrivateNetwork = true
 	}
 	corsHandler := cors.New(corsHandlerOptions)
-	plainHandler := corsHandler.Handler(newPlainPostHandler(logger, disallowedHeaders, forwardHeaders, tlsClientConfig))
+	plainHandler := corsHandler.Handler(newPlainPostHandler(
+		logger,
+		disallowedHeaders,
+		forwardHeaders,
+		tlsClientConfig,
+		handlerOptions.unixSocket,
+		recorder,
+	))
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
@@ -65,3 +81,47 @@ func NewHandler(
 	})
 	return mux
 }
+
+// HandlerOption is an option for a new Handler.
+type HandlerOption func(*handlerOptions)
+
+// HandlerWithUnixSocket returns a new HandlerOption that makes all connections to
+// upstream servers over the unix socket at the given path, instead of opening a TCP
+// connection to the host of the target.
+func HandlerWithUnixSocket(unixSocket string) HandlerOption {
+	return func(handlerOptions *handlerOptions) {
+		handlerOptions.unixSocket = unixSocket
+	}
+}
+
+// HandlerWithRecordDirPath returns a new HandlerOption that writes every proxied
+// request and its response to a JSON file in the given directory.
+//
+// The directory is created if it does not exist. The values of the Authorization,
+// Proxy-Authorization, Cookie and Set-Cookie headers are redacted, unless
+// HandlerWithRecordCredentials is set.
+func HandlerWithRecordDirPath(recordDirPath string) HandlerOption {
+	return func(handlerOptions *handlerOptions) {
+		handlerOptions.recordDirPath = recordDirPath
+	}
+}
+
+// HandlerWithRecordCredentials returns a new HandlerOption that writes the values of
+// credential headers to records as is, instead of redacting them.
+//
+// This has no effect unless HandlerWithRecordDirPath is set.
+func HandlerWithRecordCredentials() HandlerOption {
+	return func(handlerOptions *handlerOptions) {
+		handlerOptions.recordCredentials = true
+	}
+}
+
+type handlerOptions struct {
+	unixSocket        string
+	recordDirPath     string
+	recordCredentials bool
+}
+
+func newHandlerOptions() *handlerOptions {
+	return &handlerOptions{}
+}
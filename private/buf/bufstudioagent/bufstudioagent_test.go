@@ -19,11 +19,14 @@ import (
 	"context"
 	"crypto/x509"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"io"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strconv"
 	"testing"
 	"time"
@@ -104,6 +107,24 @@ func testPlainPostHandler(t *testing.T, upstreamServer *httptest.Server) {
 		assert.Equal(t, "foo-value", upstreamResponseHeaders.Get("Echo-Bar"))
 	})
 
+	t.Run("content_type_grpc_web_proto", func(t *testing.T) {
+		requestProto := &studiov1alpha1.InvokeRequest{
+			Target: upstreamServer.URL + echoPath,
+			Headers: goHeadersToProtoHeaders(http.Header{
+				"Content-Type": []string{"application/grpc-web+proto"},
+			}),
+			Body: []byte("echothis"),
+		}
+		invokeResponse := testInvoke(t, agentServer, requestProto)
+		upstreamResponseHeaders := make(http.Header)
+		addProtoHeadersToGoHeader(invokeResponse.Headers, upstreamResponseHeaders)
+		addProtoHeadersToGoHeader(invokeResponse.Trailers, upstreamResponseHeaders)
+		assert.Equal(t, "0", upstreamResponseHeaders.Get("grpc-status"))
+		assert.Equal(t, "application/grpc-web+proto", upstreamResponseHeaders.Get("Echo-Content-Type"))
+		assert.Equal(t, []byte("echo: echothis"), invokeResponse.Body)
+		assert.Equal(t, "foo-value", upstreamResponseHeaders.Get("Echo-Bar"))
+	})
+
 	t.Run("content_type_application_proto", func(t *testing.T) {
 		requestProto := &studiov1alpha1.InvokeRequest{
 			Target: upstreamServer.URL + echoPath,
@@ -250,7 +271,174 @@ func testPlainPostHandlerErrors(t *testing.T, upstreamServer *httptest.Server) {
 	})
 }
 
+func TestPlainPostHandlerUnixSocket(t *testing.T) {
+	t.Parallel()
+	// Unix socket paths are limited in length, so use a short temporary directory.
+	dirPath, err := os.MkdirTemp("", "agent")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(dirPath))
+	})
+	unixSocket := filepath.Join(dirPath, "upstream.sock")
+	listener, err := net.Listen("unix", unixSocket)
+	require.NoError(t, err)
+	upstreamServer := httptest.NewUnstartedServer(h2c.NewHandler(newTestConnectHandler(), &http2.Server{}))
+	upstreamServer.Listener = listener
+	upstreamServer.Start()
+	defer upstreamServer.Close()
+	agentServer := httptest.NewTLSServer(
+		NewHandler(
+			slogtestext.NewLogger(t),
+			"https://example.buf.build",
+			nil,
+			nil,
+			nil,
+			false,
+			HandlerWithUnixSocket(unixSocket),
+		),
+	)
+	defer agentServer.Close()
+
+	for _, contentType := range []string{"application/grpc", "application/grpc-web", "application/proto"} {
+		invokeResponse := testInvoke(
+			t,
+			agentServer,
+			&studiov1alpha1.InvokeRequest{
+				// The host is never resolved, as the unix socket is dialed instead.
+				Target: "http://upstream.invalid" + echoPath,
+				Headers: goHeadersToProtoHeaders(http.Header{
+					"Content-Type": []string{contentType},
+				}),
+				Body: []byte("echothis"),
+			},
+		)
+		assert.Equal(t, []byte("echo: echothis"), invokeResponse.Body, contentType)
+	}
+}
+
+func TestPlainPostHandlerRecord(t *testing.T) {
+	t.Parallel()
+	testPlainPostHandlerRecord(t, false)
+}
+
+func TestPlainPostHandlerRecordCredentials(t *testing.T) {
+	t.Parallel()
+	testPlainPostHandlerRecord(t, true)
+}
+
+func testPlainPostHandlerRecord(t *testing.T, recordCredentials bool) {
+	upstreamServer := newTestConnectServer(t, false)
+	defer upstreamServer.Close()
+	// The directory is created if it does not exist.
+	recordDirPath := filepath.Join(t.TempDir(), "records")
+	handlerOptions := []HandlerOption{
+		HandlerWithRecordDirPath(recordDirPath),
+	}
+	if recordCredentials {
+		handlerOptions = append(handlerOptions, HandlerWithRecordCredentials())
+	}
+	agentServer := httptest.NewTLSServer(
+		NewHandler(
+			slogtestext.NewLogger(t),
+			"https://example.buf.build",
+			nil,
+			nil,
+			nil,
+			false,
+			handlerOptions...,
+		),
+	)
+	defer agentServer.Close()
+
+	requestProto := &studiov1alpha1.InvokeRequest{
+		Target: upstreamServer.URL + echoPath,
+		Headers: goHeadersToProtoHeaders(http.Header{
+			"Content-Type":  []string{"application/proto"},
+			"Authorization": []string{"Bearer secret"},
+			"Cookie":        []string{"session=secret"},
+		}),
+		Body: []byte("echothis"),
+	}
+	invokeResponse := testInvoke(t, agentServer, requestProto)
+	assert.Equal(t, []byte("echo: echothis"), invokeResponse.Body)
+
+	dirEntries, err := os.ReadDir(recordDirPath)
+	require.NoError(t, err)
+	require.Len(t, dirEntries, 1)
+	data, err := os.ReadFile(filepath.Join(recordDirPath, dirEntries[0].Name()))
+	require.NoError(t, err)
+	var record struct {
+		Request  json.RawMessage `json:"request"`
+		Response json.RawMessage `json:"response"`
+		Error    string          `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(data, &record))
+	assert.Empty(t, record.Error)
+	if recordCredentials {
+		assert.Contains(t, string(record.Request), "Bearer secret")
+		assert.Contains(t, string(record.Request), "session=secret")
+	} else {
+		assert.NotContains(t, string(record.Request), "secret")
+	}
+	recordedRequest := &studiov1alpha1.InvokeRequest{}
+	require.NoError(t, protoencoding.NewJSONUnmarshaler(nil).Unmarshal(record.Request, recordedRequest))
+	assert.Equal(t, requestProto.Target, recordedRequest.Target)
+	assert.Equal(t, requestProto.Body, recordedRequest.Body)
+	recordedHeaders := make(http.Header)
+	addProtoHeadersToGoHeader(recordedRequest.Headers, recordedHeaders)
+	assert.Equal(t, "application/proto", recordedHeaders.Get("Content-Type"))
+	if recordCredentials {
+		assert.Equal(t, "Bearer secret", recordedHeaders.Get("Authorization"))
+	} else {
+		assert.Equal(t, "REDACTED", recordedHeaders.Get("Authorization"))
+		assert.Equal(t, "REDACTED", recordedHeaders.Get("Cookie"))
+	}
+	recordedResponse := &studiov1alpha1.InvokeResponse{}
+	require.NoError(t, protoencoding.NewJSONUnmarshaler(nil).Unmarshal(record.Response, recordedResponse))
+	assert.Equal(t, invokeResponse.Body, recordedResponse.Body)
+
+	// The recorded request can be replayed as is.
+	replayedResponse := testInvoke(t, agentServer, recordedRequest)
+	assert.Equal(t, invokeResponse.Body, replayedResponse.Body)
+}
+
+func testInvoke(
+	t *testing.T,
+	agentServer *httptest.Server,
+	requestProto *studiov1alpha1.InvokeRequest,
+) *studiov1alpha1.InvokeResponse {
+	requestBytes := protoMarshalBase64(t, requestProto)
+	request, err := http.NewRequest(http.MethodPost, agentServer.URL, bytes.NewReader(requestBytes))
+	require.NoError(t, err)
+	request.Header.Set("Content-Type", "text/plain")
+	request.Header.Set("Origin", "https://example.buf.build")
+	request.Header.Set("Foo", "foo-value")
+	response, err := agentServer.Client().Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	responseBytes, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, response.StatusCode, string(responseBytes))
+	invokeResponse := &studiov1alpha1.InvokeResponse{}
+	protoUnmarshalBase64(t, responseBytes, invokeResponse)
+	return invokeResponse
+}
+
 func newTestConnectServer(t *testing.T, tls bool) *httptest.Server {
+	mux := newTestConnectHandler()
+	if tls {
+		upstreamServerTLS := httptest.NewUnstartedServer(mux)
+		upstreamServerTLS.EnableHTTP2 = true
+		upstreamServerTLS.StartTLS()
+		certpool := x509.NewCertPool()
+		certpool.AddCert(upstreamServerTLS.Certificate())
+		upstreamServerTLS.TLS.RootCAs = certpool
+		return upstreamServerTLS
+	}
+	return httptest.NewServer(h2c.NewHandler(mux, &http2.Server{}))
+}
+
+func newTestConnectHandler() http.Handler {
 	mux := http.NewServeMux()
 	// echoPath echoes all incoming headers (prefixed with "Echo-") and the
 	// body bytes prefixed with "echo: "
@@ -275,16 +463,7 @@ func newTestConnectServer(t *testing.T, tls bool) *httptest.Server {
 		},
 		connect.WithCodec(&bufferCodec{name: "proto"}),
 	))
-	if tls {
-		upstreamServerTLS := httptest.NewUnstartedServer(mux)
-		upstreamServerTLS.EnableHTTP2 = true
-		upstreamServerTLS.StartTLS()
-		certpool := x509.NewCertPool()
-		certpool.AddCert(upstreamServerTLS.Certificate())
-		upstreamServerTLS.TLS.RootCAs = certpool
-		return upstreamServerTLS
-	}
-	return httptest.NewServer(h2c.NewHandler(mux, &http2.Server{}))
+	return mux
 }
 
 func protoMarshalBase64(t *testing.T, message proto.Message) []byte {
@@ -16,6 +16,7 @@ package bufstudioagent
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/base64"
 	"errors"
@@ -26,6 +27,7 @@ import (
 	"net/http"
 	"net/textproto"
 	"net/url"
+	"strings"
 
 	"connectrpc.com/connect"
 	studiov1alpha1 "github.com/bufbuild/buf/private/gen/proto/go/buf/alpha/studio/v1alpha1"
@@ -56,8 +58,12 @@ type plainPostHandler struct {
 	B64Encoding         *base64.Encoding
 	TLSClient           *http.Client
 	H2CClient           *http.Client
-	DisallowedHeaders   map[string]struct{}
-	ForwardHeaders      map[string]string
+	// HTTPClient is used for gRPC-Web, which may be served over HTTP/1.1.
+	HTTPClient        *http.Client
+	DisallowedHeaders map[string]struct{}
+	ForwardHeaders    map[string]string
+	// Recorder is optional.
+	Recorder *recorder
 }
 
 func newPlainPostHandler(
@@ -65,6 +71,8 @@ func newPlainPostHandler(
 	disallowedHeaders map[string]struct{},
 	forwardHeaders map[string]string,
 	tlsClientConfig *tls.Config,
+	unixSocket string,
+	recorder *recorder,
 ) *plainPostHandler {
 	canonicalDisallowedHeaders := make(map[string]struct{}, len(disallowedHeaders))
 	for k := range disallowedHeaders {
@@ -74,6 +82,24 @@ func newPlainPostHandler(
 	for k, v := range forwardHeaders {
 		canonicalForwardHeaders[textproto.CanonicalMIMEHeaderKey(k)] = v
 	}
+	dialContext := newDialContextFunc(unixSocket)
+	tlsTransport := &http2.Transport{
+		TLSClientConfig: tlsClientConfig,
+	}
+	if unixSocket != "" {
+		tlsTransport.DialTLSContext = func(ctx context.Context, network, addr string, config *tls.Config) (net.Conn, error) {
+			conn, err := dialContext(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			tlsConn := tls.Client(conn, config)
+			if err := tlsConn.HandshakeContext(ctx); err != nil {
+				_ = conn.Close()
+				return nil, err
+			}
+			return tlsConn, nil
+		}
+	}
 	return &plainPostHandler{
 		B64Encoding:       base64.StdEncoding,
 		DisallowedHeaders: canonicalDisallowedHeaders,
@@ -81,18 +107,24 @@ func newPlainPostHandler(
 		H2CClient: &http.Client{
 			Transport: &http2.Transport{
 				AllowHTTP: true,
-				DialTLS: func(netw, addr string, config *tls.Config) (net.Conn, error) {
-					return net.Dial(netw, addr)
+				DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+					return dialContext(ctx, network, addr)
 				},
 			},
 		},
+		HTTPClient: &http.Client{
+			Transport: &http.Transport{
+				DialContext:       dialContext,
+				TLSClientConfig:   tlsClientConfig,
+				ForceAttemptHTTP2: true,
+			},
+		},
 		Logger:              logger,
 		MaxMessageSizeBytes: MaxMessageSizeBytesDefault,
 		TLSClient: &http.Client{
-			Transport: &http2.Transport{
-				TLSClientConfig: tlsClientConfig,
-			},
+			Transport: tlsTransport,
 		},
+		Recorder: recorder,
 	}
 }
 
@@ -124,11 +156,34 @@ func (i *plainPostHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	upstreamRequest, envelopeResponse, err := i.invoke(r, envelopeRequest)
+	if i.Recorder != nil && upstreamRequest != nil {
+		i.Recorder.Record(upstreamRequest, envelopeResponse, err)
+	}
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if invokeErr := new(invokeError); errors.As(err, &invokeErr) {
+			statusCode = invokeErr.statusCode
+		}
+		http.Error(w, err.Error(), statusCode)
+		return
+	}
+	i.writeProtoMessage(w, envelopeResponse)
+}
+
+// invoke forwards the enveloped request to the upstream server.
+//
+// The returned InvokeRequest is the request as sent to the upstream server, after
+// the forwarded headers are applied. It is nil if the request was not sent.
+// Errors are of type *invokeError.
+func (i *plainPostHandler) invoke(
+	r *http.Request,
+	envelopeRequest *studiov1alpha1.InvokeRequest,
+) (*studiov1alpha1.InvokeRequest, *studiov1alpha1.InvokeResponse, error) {
 	request := connect.NewRequest(bytes.NewBuffer(envelopeRequest.GetBody()))
 	for _, header := range envelopeRequest.Headers {
 		if _, ok := i.DisallowedHeaders[textproto.CanonicalMIMEHeaderKey(header.Key)]; ok {
-			http.Error(w, fmt.Sprintf("header %q disallowed by agent", header.Key), http.StatusBadRequest)
-			return
+			return nil, nil, newInvokeErrorf(http.StatusBadRequest, "header %q disallowed by agent", header.Key)
 		}
 		for _, value := range header.Value {
 			request.Header().Add(header.Key, value)
@@ -145,9 +200,9 @@ func (i *plainPostHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 	targetURL, err := url.Parse(envelopeRequest.GetTarget())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
+		return nil, nil, newInvokeError(http.StatusBadRequest, err)
 	}
+	contentType := request.Header().Get("Content-Type")
 	var httpClient *http.Client
 	switch targetURL.Scheme {
 	case "http":
@@ -155,13 +210,19 @@ func (i *plainPostHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	case "https":
 		httpClient = i.TLSClient
 	default:
-		http.Error(w, fmt.Sprintf("must specify http or https url scheme, got %q", targetURL.Scheme), http.StatusBadRequest)
-		return
+		return nil, nil, newInvokeErrorf(http.StatusBadRequest, "must specify http or https url scheme, got %q", targetURL.Scheme)
+	}
+	if isGRPCWebContentType(contentType) {
+		httpClient = i.HTTPClient
 	}
-	clientOptions, err := connectClientOptionsFromContentType(request.Header().Get("Content-Type"))
+	clientOptions, err := connectClientOptionsFromContentType(contentType)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
+		return nil, nil, newInvokeError(http.StatusBadRequest, err)
+	}
+	upstreamRequest := &studiov1alpha1.InvokeRequest{
+		Target:  targetURL.String(),
+		Headers: goHeadersToProtoHeaders(request.Header()),
+		Body:    envelopeRequest.GetBody(),
 	}
 	client := connect.NewClient[bytes.Buffer, bytes.Buffer](
 		httpClient,
@@ -181,8 +242,7 @@ func (i *plainPostHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		// server. In those scenarios we trigger a `StatusBadGateway` to signal
 		// that the upstream server is unreachable or in a bad status...
 		if !connect.IsWireError(err) {
-			http.Error(w, err.Error(), http.StatusBadGateway)
-			return
+			return upstreamRequest, nil, newInvokeError(http.StatusBadGateway, err)
 		}
 		// ... but if a response was received from the server, we assume there's
 		// error information from the server we can surface to the user by including
@@ -190,28 +250,25 @@ func (i *plainPostHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		// marks any issues connecting with the `CodeUnknown` error.
 		if connectErr := new(connect.Error); errors.As(err, &connectErr) {
 			if connectErr.Code() == connect.CodeUnknown {
-				http.Error(w, err.Error(), http.StatusBadGateway)
-				return
+				return upstreamRequest, nil, newInvokeError(http.StatusBadGateway, err)
 			}
-			i.writeProtoMessage(w, &studiov1alpha1.InvokeResponse{
+			return upstreamRequest, &studiov1alpha1.InvokeResponse{
 				// connectErr.Meta contains the trailers for the
 				// caller to find out the error details.
 				Headers: goHeadersToProtoHeaders(connectErr.Meta()),
-			})
-			return
+			}, nil
 		}
 		i.Logger.Warn(
 			"non_connect_unary_error",
 			slogext.ErrorAttr(err),
 		)
-		http.Error(w, err.Error(), http.StatusBadGateway)
-		return
+		return upstreamRequest, nil, newInvokeError(http.StatusBadGateway, err)
 	}
-	i.writeProtoMessage(w, &studiov1alpha1.InvokeResponse{
+	return upstreamRequest, &studiov1alpha1.InvokeResponse{
 		Headers:  goHeadersToProtoHeaders(response.Header()),
 		Body:     response.Msg.Bytes(),
 		Trailers: goHeadersToProtoHeaders(response.Trailer()),
-	})
+	}, nil
 }
 
 func connectClientOptionsFromContentType(contentType string) ([]connect.ClientOption, error) {
@@ -226,6 +283,16 @@ func connectClientOptionsFromContentType(contentType string) ([]connect.ClientOp
 			connect.WithGRPC(),
 			connect.WithCodec(&bufferCodec{name: "json"}),
 		}, nil
+	case "application/grpc-web", "application/grpc-web+proto":
+		return []connect.ClientOption{
+			connect.WithGRPCWeb(),
+			connect.WithCodec(&bufferCodec{name: "proto"}),
+		}, nil
+	case "application/grpc-web+json":
+		return []connect.ClientOption{
+			connect.WithGRPCWeb(),
+			connect.WithCodec(&bufferCodec{name: "json"}),
+		}, nil
 	case "application/json":
 		return []connect.ClientOption{
 			connect.WithCodec(&bufferCodec{name: "json"}),
@@ -239,6 +306,22 @@ func connectClientOptionsFromContentType(contentType string) ([]connect.ClientOp
 	}
 }
 
+func isGRPCWebContentType(contentType string) bool {
+	return contentType == "application/grpc-web" || strings.HasPrefix(contentType, "application/grpc-web+")
+}
+
+// newDialContextFunc returns a function to dial upstream servers. If unixSocket
+// is set, all connections are made to the unix socket instead of the address.
+func newDialContextFunc(unixSocket string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	var dialer net.Dialer
+	if unixSocket == "" {
+		return dialer.DialContext
+	}
+	return func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return dialer.DialContext(ctx, "unix", unixSocket)
+	}
+}
+
 func (i *plainPostHandler) writeProtoMessage(w http.ResponseWriter, message proto.Message) {
 	responseProtoBytes, err := protoencoding.NewWireMarshaler().Marshal(message)
 	if err != nil {
@@ -268,3 +351,29 @@ func goHeadersToProtoHeaders(in http.Header) []*studiov1alpha1.Headers {
 	}
 	return out
 }
+
+// invokeError is an error for a request that could not be invoked, with the HTTP
+// status code to respond with.
+type invokeError struct {
+	statusCode int
+	err        error
+}
+
+func newInvokeError(statusCode int, err error) *invokeError {
+	return &invokeError{
+		statusCode: statusCode,
+		err:        err,
+	}
+}
+
+func newInvokeErrorf(statusCode int, format string, args ...any) *invokeError {
+	return newInvokeError(statusCode, fmt.Errorf(format, args...))
+}
+
+func (e *invokeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *invokeError) Unwrap() error {
+	return e.err
+}
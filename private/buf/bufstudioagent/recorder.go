@@ -0,0 +1,182 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufstudioagent
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	studiov1alpha1 "github.com/bufbuild/buf/private/gen/proto/go/buf/alpha/studio/v1alpha1"
+	"github.com/bufbuild/buf/private/pkg/protoencoding"
+	"github.com/bufbuild/buf/private/pkg/slogext"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// recordFileTimeLayout is the layout of the time prefix of record file names, so
+	// that the files sort in the order the requests were made.
+	recordFileTimeLayout = "20060102T150405.000000000Z"
+	// recordRedactedHeaderValue replaces the values of credential headers in records.
+	recordRedactedHeaderValue = "REDACTED"
+)
+
+// recordCredentialHeaderNames are the canonical names of the headers whose values are
+// redacted in records, unless credentials are included.
+var recordCredentialHeaderNames = map[string]struct{}{
+	"Authorization":       {},
+	"Cookie":              {},
+	"Proxy-Authorization": {},
+	"Set-Cookie":          {},
+}
+
+// recorder writes each proxied request and response to a JSON file in a directory.
+//
+// The request is the request as sent to the upstream server in the form of an
+// InvokeRequest, so it can be replayed by sending it to the agent again. The values
+// of credential headers are redacted unless includeCredentials is set.
+type recorder struct {
+	logger             *slog.Logger
+	dirPath            string
+	includeCredentials bool
+	// counter disambiguates records made at the same time.
+	counter atomic.Uint64
+}
+
+// newRecorder returns a new recorder while creating the directory.
+func newRecorder(logger *slog.Logger, dirPath string, includeCredentials bool) *recorder {
+	// Failures are logged once here, and writing each record fails with the same error.
+	if err := os.MkdirAll(dirPath, 0700); err != nil {
+		logger.Warn(
+			"record_error",
+			slog.String("path", dirPath),
+			slogext.ErrorAttr(err),
+		)
+	}
+	return &recorder{
+		logger:             logger,
+		dirPath:            dirPath,
+		includeCredentials: includeCredentials,
+	}
+}
+
+// Record writes the request and its response or error to a new file.
+//
+// Failures to write the file are logged and otherwise ignored, so that
+// recording never affects the proxied requests.
+func (r *recorder) Record(
+	request *studiov1alpha1.InvokeRequest,
+	response *studiov1alpha1.InvokeResponse,
+	invokeErr error,
+) {
+	now := time.Now().UTC()
+	filePath := filepath.Join(
+		r.dirPath,
+		fmt.Sprintf("%s-%06d.json", now.Format(recordFileTimeLayout), r.counter.Add(1)),
+	)
+	if err := r.writeRecord(filePath, now, request, response, invokeErr); err != nil {
+		r.logger.Warn(
+			"record_error",
+			slog.String("path", filePath),
+			slogext.ErrorAttr(err),
+		)
+	}
+}
+
+func (r *recorder) writeRecord(
+	filePath string,
+	now time.Time,
+	request *studiov1alpha1.InvokeRequest,
+	response *studiov1alpha1.InvokeResponse,
+	invokeErr error,
+) error {
+	externalRecord := externalRecord{
+		Time: now.Format(time.RFC3339Nano),
+	}
+	if !r.includeCredentials {
+		request = redactInvokeRequest(request)
+		response = redactInvokeResponse(response)
+	}
+	var err error
+	externalRecord.Request, err = marshalRecordMessage(request)
+	if err != nil {
+		return err
+	}
+	if response != nil {
+		externalRecord.Response, err = marshalRecordMessage(response)
+		if err != nil {
+			return err
+		}
+	}
+	if invokeErr != nil {
+		externalRecord.Error = invokeErr.Error()
+	}
+	data, err := json.MarshalIndent(externalRecord, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, append(data, '\n'), 0600)
+}
+
+// externalRecord is the JSON representation of a recorded request.
+type externalRecord struct {
+	Time     string          `json:"time"`
+	Request  json.RawMessage `json:"request"`
+	Response json.RawMessage `json:"response,omitempty"`
+	// Error is set if no response was received from the upstream server.
+	Error string `json:"error,omitempty"`
+}
+
+func marshalRecordMessage(message proto.Message) (json.RawMessage, error) {
+	return protoencoding.NewJSONMarshaler(nil).Marshal(message)
+}
+
+// redactInvokeRequest returns a copy of the request with the values of credential
+// headers redacted.
+func redactInvokeRequest(request *studiov1alpha1.InvokeRequest) *studiov1alpha1.InvokeRequest {
+	request, _ = proto.Clone(request).(*studiov1alpha1.InvokeRequest)
+	redactHeaders(request.GetHeaders())
+	return request
+}
+
+// redactInvokeResponse returns a copy of the response with the values of credential
+// headers and trailers redacted.
+//
+// Returns nil if the response is nil.
+func redactInvokeResponse(response *studiov1alpha1.InvokeResponse) *studiov1alpha1.InvokeResponse {
+	if response == nil {
+		return nil
+	}
+	response, _ = proto.Clone(response).(*studiov1alpha1.InvokeResponse)
+	redactHeaders(response.GetHeaders())
+	redactHeaders(response.GetTrailers())
+	return response
+}
+
+func redactHeaders(headers []*studiov1alpha1.Headers) {
+	for _, header := range headers {
+		if _, ok := recordCredentialHeaderNames[http.CanonicalHeaderKey(header.GetKey())]; !ok {
+			continue
+		}
+		for i := range header.Value {
+			header.Value[i] = recordRedactedHeaderValue
+		}
+	}
+}
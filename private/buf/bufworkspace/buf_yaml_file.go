@@ -0,0 +1,98 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufworkspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufparse"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/syserror"
+)
+
+// ResolveBufYAMLFile resolves the extends of the buf.yaml, see bufconfig.ResolveBufYAMLFile.
+//
+// The bucket must be rooted at the directory of the buf.yaml. Extended modules must be pinned
+// in the buf.lock in this directory by buf dep update, and must have a v1beta1 or v1 buf.yaml,
+// as the BSR does not store the buf.yaml files of v2 modules.
+func ResolveBufYAMLFile(
+	ctx context.Context,
+	bufYAMLFile bufconfig.BufYAMLFile,
+	bucket storage.ReadBucket,
+	moduleDataProvider bufmodule.ModuleDataProvider,
+) (bufconfig.BufYAMLFile, error) {
+	if len(bufYAMLFile.Extends()) == 0 {
+		return bufYAMLFile, nil
+	}
+	var extendsModuleKeys []bufmodule.ModuleKey
+	if len(bufYAMLFile.ConfiguredExtendsModuleRefs()) > 0 {
+		bufLockFile, err := bufconfig.GetBufLockFileForPrefix(ctx, bucket, ".")
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				return nil, err
+			}
+		} else {
+			extendsModuleKeys = bufLockFile.ExtendsModuleKeys()
+		}
+	}
+	return bufconfig.ResolveBufYAMLFile(
+		ctx,
+		bufYAMLFile,
+		bucket,
+		func(ctx context.Context, moduleRef bufparse.Ref) ([]byte, error) {
+			return getExtendedModuleBufYAMLData(ctx, moduleDataProvider, extendsModuleKeys, moduleRef)
+		},
+	)
+}
+
+// *** PRIVATE ***
+
+func getExtendedModuleBufYAMLData(
+	ctx context.Context,
+	moduleDataProvider bufmodule.ModuleDataProvider,
+	extendsModuleKeys []bufmodule.ModuleKey,
+	moduleRef bufparse.Ref,
+) ([]byte, error) {
+	var moduleKey bufmodule.ModuleKey
+	for _, extendsModuleKey := range extendsModuleKeys {
+		if bufparse.FullNameEqual(extendsModuleKey.FullName(), moduleRef.FullName()) {
+			moduleKey = extendsModuleKey
+			break
+		}
+	}
+	if moduleKey == nil {
+		return nil, fmt.Errorf(`module %s is extended by buf.yaml but is not pinned in buf.lock, run "buf dep update" to pin it`, moduleRef.FullName())
+	}
+	moduleDatas, err := moduleDataProvider.GetModuleDatasForModuleKeys(ctx, []bufmodule.ModuleKey{moduleKey})
+	if err != nil {
+		return nil, err
+	}
+	if len(moduleDatas) != 1 {
+		return nil, syserror.Newf("expected 1 ModuleData for extended module %s, got %d", moduleKey.String(), len(moduleDatas))
+	}
+	objectData, err := moduleDatas[0].V1Beta1OrV1BufYAMLObjectData()
+	if err != nil {
+		return nil, err
+	}
+	if objectData == nil {
+		return nil, fmt.Errorf("module %s cannot be extended, only modules with a v1beta1 or v1 buf.yaml can be extended", moduleRef.FullName())
+	}
+	return objectData.Data(), nil
+}
@@ -71,6 +71,16 @@ func WithIgnoreAndDisallowV1BufWorkYAMLs() WorkspaceBucketOption {
 	return &workspaceIgnoreAndDisallowV1BufWorkYAMLsOption{}
 }
 
+// WithIgnoreReplacements returns a new WorkspaceBucketOption that says to ignore the
+// dependency replacements of a v2 buf.yaml, and to use the dependencies from the buf.lock
+// instead.
+//
+// This is used for updates with buf dep prune and buf dep update, as replacements
+// are never written to buf.lock files.
+func WithIgnoreReplacements() WorkspaceBucketOption {
+	return &workspaceIgnoreReplacementsOption{}
+}
+
 // Note these paths need to have the path/to/module stripped, and then each new path
 // filtered to the specific module it applies to. If some modules do not have any
 // target paths, but we specified WorkspaceWithTargetPaths, then those modules
@@ -148,11 +158,18 @@ func (c *workspaceIgnoreAndDisallowV1BufWorkYAMLsOption) applyToWorkspaceBucketC
 	config.ignoreAndDisallowV1BufWorkYAMLs = true
 }
 
+type workspaceIgnoreReplacementsOption struct{}
+
+func (c *workspaceIgnoreReplacementsOption) applyToWorkspaceBucketConfig(config *workspaceBucketConfig) {
+	config.ignoreReplacements = true
+}
+
 type workspaceBucketConfig struct {
 	protoFileTargetPath             string
 	includePackageFiles             bool
 	configOverride                  string
 	ignoreAndDisallowV1BufWorkYAMLs bool
+	ignoreReplacements              bool
 }
 
 func newWorkspaceBucketConfig(options []WorkspaceBucketOption) (*workspaceBucketConfig, error) {
@@ -0,0 +1,263 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufworkspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufparse"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/slicesext"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"github.com/bufbuild/buf/private/pkg/syserror"
+	"github.com/google/uuid"
+)
+
+// addReplaceModules adds the Modules that replace dependencies to the ModuleSetBuilder.
+//
+// Dependencies replaced with local directories are added as local, non-target Modules, and
+// the ModuleConfigs for these Modules are added to bucketIDToModuleConfig. Dependencies
+// replaced with other modules are added as remote Modules, along with their transitive
+// dependencies.
+//
+// The caller is responsible for not adding the replaced dependencies from the buf.lock.
+func (w *workspaceProvider) addReplaceModules(
+	ctx context.Context,
+	moduleSetBuilder bufmodule.ModuleSetBuilder,
+	bucket storage.ReadBucket,
+	replaceConfigs []bufconfig.ReplaceConfig,
+	bucketIDToModuleConfig map[string]bufconfig.ModuleConfig,
+) error {
+	replacedModuleFullNameStrings := getReplacedModuleFullNameStrings(replaceConfigs)
+	var moduleRefs []bufparse.Ref
+	var rootDirPath string
+	for _, replaceConfig := range replaceConfigs {
+		if moduleRef := replaceConfig.ModuleRef(); moduleRef != nil {
+			moduleRefs = append(moduleRefs, moduleRef)
+			continue
+		}
+		if rootDirPath == "" {
+			var err error
+			rootDirPath, err = getLocalDirPathForBucket(ctx, bucket)
+			if err != nil {
+				return fmt.Errorf("cannot replace %s with path %q: %w", replaceConfig.FullName().String(), replaceConfig.DirPath(), err)
+			}
+		}
+		if err := w.addDirPathReplaceModule(
+			ctx,
+			moduleSetBuilder,
+			rootDirPath,
+			replaceConfig,
+			replacedModuleFullNameStrings,
+			bucketIDToModuleConfig,
+		); err != nil {
+			return err
+		}
+	}
+	if len(moduleRefs) == 0 {
+		return nil
+	}
+	moduleKeys, err := w.moduleKeyProvider.GetModuleKeysForModuleRefs(ctx, moduleRefs, bufmodule.DigestTypeB5)
+	if err != nil {
+		return err
+	}
+	replaceCommitIDs := slicesext.ToStructMap(slicesext.Map(moduleKeys, bufmodule.ModuleKey.CommitID))
+	graph, err := w.graphProvider.GetGraphForModuleKeys(ctx, moduleKeys)
+	if err != nil {
+		return err
+	}
+	return graph.WalkNodes(
+		func(node bufmodule.ModuleKey, _ []bufmodule.ModuleKey, _ []bufmodule.ModuleKey) error {
+			if _, ok := replaceCommitIDs[node.CommitID()]; !ok {
+				// Transitive dependencies of the replacements are replaced as well.
+				if _, ok := replacedModuleFullNameStrings[node.FullName().String()]; ok {
+					return nil
+				}
+			}
+			moduleSetBuilder.AddRemoteModule(node, false)
+			return nil
+		},
+	)
+}
+
+// addDirPathReplaceModule adds the local Module for a dependency replaced with a local directory.
+//
+// The dependencies in the buf.lock of the directory, if any, are added as remote Modules.
+func (w *workspaceProvider) addDirPathReplaceModule(
+	ctx context.Context,
+	moduleSetBuilder bufmodule.ModuleSetBuilder,
+	rootDirPath string,
+	replaceConfig bufconfig.ReplaceConfig,
+	replacedModuleFullNameStrings map[string]struct{},
+	bucketIDToModuleConfig map[string]bufconfig.ModuleConfig,
+) error {
+	moduleFullName := replaceConfig.FullName()
+	dirPath := normalpath.Unnormalize(replaceConfig.DirPath())
+	if !filepath.IsAbs(dirPath) {
+		dirPath = filepath.Join(rootDirPath, dirPath)
+	}
+	replaceBucket, err := w.storageosProvider.NewReadWriteBucket(
+		dirPath,
+		storageos.ReadWriteBucketWithSymlinksIfSupported(),
+	)
+	if err != nil {
+		return fmt.Errorf("cannot replace %s with path %q: %w", moduleFullName.String(), replaceConfig.DirPath(), err)
+	}
+	moduleConfig, err := getModuleConfigForReplaceBucket(ctx, replaceBucket, moduleFullName)
+	if err != nil {
+		return fmt.Errorf("cannot replace %s with path %q: %w", moduleFullName.String(), replaceConfig.DirPath(), err)
+	}
+	// Replace paths are relative to the workspace root, but usually outside of it. The bucketID
+	// is the replace path, which does not collide with the bucketIDs of the Modules in the
+	// workspace unless the replace path is a module directory of the workspace.
+	bucketID := replaceConfig.DirPath()
+	if _, ok := bucketIDToModuleConfig[bucketID]; ok {
+		return fmt.Errorf("cannot replace %s with path %q: path is a module directory of the workspace", moduleFullName.String(), replaceConfig.DirPath())
+	}
+	moduleBucket, _, err := getMappedModuleBucketAndModuleTargeting(
+		ctx,
+		nil,
+		replaceBucket,
+		nil,
+		moduleConfig.DirPath(),
+		moduleConfig,
+		false,
+		false,
+	)
+	if err != nil {
+		return err
+	}
+	moduleSetBuilder.AddLocalModule(
+		moduleBucket,
+		bucketID,
+		false,
+		bufmodule.LocalModuleWithFullName(moduleFullName),
+		bufmodule.LocalModuleWithDescription(
+			fmt.Sprintf("%s replaced with %s", moduleFullName.String(), getLocalModuleDescription(bucketID, moduleConfig)),
+		),
+	)
+	bucketIDToModuleConfig[bucketID] = moduleConfig
+	bufLockFile, err := bufconfig.GetBufLockFileForPrefix(
+		ctx,
+		replaceBucket,
+		// buf.lock files live next to the buf.yaml
+		".",
+		bufconfig.BufLockFileWithDigestResolver(w.getB4DigestForCommitID),
+	)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	for _, depModuleKey := range bufLockFile.DepModuleKeys() {
+		if _, ok := replacedModuleFullNameStrings[depModuleKey.FullName().String()]; ok {
+			continue
+		}
+		moduleSetBuilder.AddRemoteModule(depModuleKey, false)
+	}
+	return nil
+}
+
+// getB4DigestForCommitID is a digest resolver for v1 buf.lock files without digests.
+func (w *workspaceProvider) getB4DigestForCommitID(
+	ctx context.Context,
+	remote string,
+	commitID uuid.UUID,
+) (bufmodule.Digest, error) {
+	commitKey, err := bufmodule.NewCommitKey(remote, commitID, bufmodule.DigestTypeB4)
+	if err != nil {
+		return nil, err
+	}
+	commits, err := w.commitProvider.GetCommitsForCommitKeys(ctx, []bufmodule.CommitKey{commitKey})
+	if err != nil {
+		return nil, err
+	}
+	return commits[0].ModuleKey().Digest()
+}
+
+// getModuleConfigForReplaceBucket gets the ModuleConfig for the Module with the given FullName
+// in the bucket of a replace directory.
+//
+// If the directory does not have a buf.yaml, the default config is used. If the directory has a
+// v1beta1 or v1 buf.yaml, its config is used. If the directory has a v2 buf.yaml, the config of
+// the module with the FullName is used.
+func getModuleConfigForReplaceBucket(
+	ctx context.Context,
+	replaceBucket storage.ReadBucket,
+	moduleFullName bufparse.FullName,
+) (bufconfig.ModuleConfig, error) {
+	bufYAMLFile, err := bufconfig.GetBufYAMLFileForPrefix(ctx, replaceBucket, ".")
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return bufconfig.DefaultModuleConfigV1, nil
+		}
+		return nil, err
+	}
+	switch fileVersion := bufYAMLFile.FileVersion(); fileVersion {
+	case bufconfig.FileVersionV1Beta1, bufconfig.FileVersionV1:
+		moduleConfigs := bufYAMLFile.ModuleConfigs()
+		if len(moduleConfigs) != 1 {
+			// This is a system error. This should never happen.
+			return nil, syserror.Newf("received %d ModuleConfigs from a v1beta1 or v1 BufYAMLFile", len(moduleConfigs))
+		}
+		return moduleConfigs[0], nil
+	case bufconfig.FileVersionV2:
+		for _, moduleConfig := range bufYAMLFile.ModuleConfigs() {
+			if moduleConfigFullName := moduleConfig.FullName(); moduleConfigFullName != nil && moduleConfigFullName.String() == moduleFullName.String() {
+				return moduleConfig, nil
+			}
+		}
+		return nil, fmt.Errorf("no module named %s in buf.yaml", moduleFullName.String())
+	default:
+		return nil, syserror.Newf("unknown FileVersion: %v", fileVersion)
+	}
+}
+
+// getLocalDirPathForBucket returns the path on disk of the root of the bucket.
+//
+// The bucket must have a buf.yaml at its root that was read from disk.
+func getLocalDirPathForBucket(ctx context.Context, bucket storage.ReadBucket) (string, error) {
+	objectInfo, err := bucket.Stat(ctx, bufconfig.DefaultBufYAMLFileName)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", errors.New("replace paths can only be used with a buf.yaml in the workspace")
+		}
+		return "", err
+	}
+	localPath := objectInfo.LocalPath()
+	if localPath == "" {
+		return "", errors.New("replace paths can only be used with local directories")
+	}
+	return filepath.Dir(localPath), nil
+}
+
+func getReplacedModuleFullNameStrings(replaceConfigs []bufconfig.ReplaceConfig) map[string]struct{} {
+	return slicesext.ToStructMap(
+		slicesext.Map(
+			replaceConfigs,
+			func(replaceConfig bufconfig.ReplaceConfig) string {
+				return replaceConfig.FullName().String()
+			},
+		),
+	)
+}
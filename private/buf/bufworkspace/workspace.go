@@ -85,6 +85,19 @@ type Workspace interface {
 	//
 	// Sorted.
 	ConfiguredDepModuleRefs() []bufparse.Ref
+	// ReplaceConfigs returns the dependency replacements that were applied to the Workspace.
+	//
+	// These come from v2 buf.yaml files, and are empty if the replacements were ignored.
+	//
+	// Sorted by FullName.
+	ReplaceConfigs() []bufconfig.ReplaceConfig
+	// VerifyConfigs returns the signature verification policy of the Workspace.
+	//
+	// These come from v2 buf.yaml files. Remote Modules of an owner with a VerifyConfig
+	// must be signed by one of the public keys of the VerifyConfig.
+	//
+	// Sorted by owner.
+	VerifyConfigs() []bufconfig.VerifyConfig
 
 	// IsV2 signifies if this module was created from a v2 buf.yaml.
 	//
@@ -106,6 +119,8 @@ type workspace struct {
 	opaqueIDToBreakingConfig map[string]bufconfig.BreakingConfig
 	pluginConfigs            []bufconfig.PluginConfig
 	configuredDepModuleRefs  []bufparse.Ref
+	replaceConfigs           []bufconfig.ReplaceConfig
+	verifyConfigs            []bufconfig.VerifyConfig
 
 	// If true, the workspace was created from v2 buf.yamls.
 	// If false, the workspace was created from defaults, or v1beta1/v1 buf.yamls.
@@ -118,6 +133,8 @@ func newWorkspace(
 	opaqueIDToBreakingConfig map[string]bufconfig.BreakingConfig,
 	pluginConfigs []bufconfig.PluginConfig,
 	configuredDepModuleRefs []bufparse.Ref,
+	replaceConfigs []bufconfig.ReplaceConfig,
+	verifyConfigs []bufconfig.VerifyConfig,
 	isV2 bool,
 ) *workspace {
 	return &workspace{
@@ -126,6 +143,8 @@ func newWorkspace(
 		opaqueIDToBreakingConfig: opaqueIDToBreakingConfig,
 		pluginConfigs:            pluginConfigs,
 		configuredDepModuleRefs:  configuredDepModuleRefs,
+		replaceConfigs:           replaceConfigs,
+		verifyConfigs:            verifyConfigs,
 		isV2:                     isV2,
 	}
 }
@@ -146,6 +165,14 @@ func (w *workspace) ConfiguredDepModuleRefs() []bufparse.Ref {
 	return slicesext.Copy(w.configuredDepModuleRefs)
 }
 
+func (w *workspace) ReplaceConfigs() []bufconfig.ReplaceConfig {
+	return slicesext.Copy(w.replaceConfigs)
+}
+
+func (w *workspace) VerifyConfigs() []bufconfig.VerifyConfig {
+	return slicesext.Copy(w.verifyConfigs)
+}
+
 func (w *workspace) IsV2() bool {
 	return w.isV2
 }
@@ -44,11 +44,18 @@ type WorkspaceDepManager interface {
 	ExistingBufLockFileDepModuleKeys(ctx context.Context) ([]bufmodule.ModuleKey, error)
 	// ExistingBufLockFileRemotePluginKeys returns the PluginKeys from the buf.lock file.
 	ExistingBufLockFileRemotePluginKeys(ctx context.Context) ([]bufplugin.PluginKey, error)
+	// ExistingBufLockFileExtendsModuleKeys returns the ModuleKeys of the extended modules from the buf.lock file.
+	ExistingBufLockFileExtendsModuleKeys(ctx context.Context) ([]bufmodule.ModuleKey, error)
 	// UpdateBufLockFile updates the lock file that backs the Workspace to contain exactly
-	// the given ModuleKeys and PluginKeys.
+	// the given dependency ModuleKeys, PluginKeys, and extended ModuleKeys.
 	//
 	// If a buf.lock does not exist, one will be created.
-	UpdateBufLockFile(ctx context.Context, depModuleKeys []bufmodule.ModuleKey, remotePluginKeys []bufplugin.PluginKey) error
+	UpdateBufLockFile(
+		ctx context.Context,
+		depModuleKeys []bufmodule.ModuleKey,
+		remotePluginKeys []bufplugin.PluginKey,
+		extendsModuleKeys []bufmodule.ModuleKey,
+	) error
 	// ConfiguredDepModuleRefs returns the configured dependencies of the Workspace as ModuleRefs.
 	//
 	// These come from buf.yaml files.
@@ -71,6 +78,13 @@ type WorkspaceDepManager interface {
 	//
 	// Sorted.
 	ConfiguredRemotePluginRefs(ctx context.Context) ([]bufparse.Ref, error)
+	// ConfiguredExtendsModuleRefs returns the modules extended by the buf.yaml of the
+	// Workspace as ModuleRefs.
+	//
+	// These come from the extends of v2 buf.yaml files. For v1 buf.yaml files, this is always empty.
+	//
+	// Sorted.
+	ConfiguredExtendsModuleRefs(ctx context.Context) ([]bufparse.Ref, error)
 
 	isWorkspaceDepManager()
 }
@@ -157,6 +171,17 @@ func (w *workspaceDepManager) ConfiguredRemotePluginRefs(ctx context.Context) ([
 	default:
 		return nil, syserror.Newf("unknown FileVersion: %v", fileVersion)
 	}
+	// Include the plugins of the local files extended by the buf.yaml. Modules are only
+	// extended with v1beta1 or v1 buf.yaml files, which cannot configure plugins.
+	bufYAMLFile, err = bufconfig.ResolveBufYAMLFile(
+		ctx,
+		bufYAMLFile,
+		storage.MapReadBucket(w.bucket, storage.MapOnPrefix(w.targetSubDirPath)),
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
 	pluginRefs := slicesext.Filter(
 		slicesext.Map(
 			bufYAMLFile.PluginConfigs(),
@@ -177,6 +202,20 @@ func (w *workspaceDepManager) ConfiguredRemotePluginRefs(ctx context.Context) ([
 	return pluginRefs, nil
 }
 
+func (w *workspaceDepManager) ConfiguredExtendsModuleRefs(ctx context.Context) ([]bufparse.Ref, error) {
+	if !w.isV2 {
+		return nil, nil
+	}
+	bufYAMLFile, err := bufconfig.GetBufYAMLFileForPrefix(ctx, w.bucket, w.targetSubDirPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return bufYAMLFile.ConfiguredExtendsModuleRefs(), nil
+}
+
 func (w *workspaceDepManager) BufLockFileDigestType() bufmodule.DigestType {
 	if w.isV2 {
 		return bufmodule.DigestTypeB5
@@ -206,11 +245,27 @@ func (w *workspaceDepManager) ExistingBufLockFileRemotePluginKeys(ctx context.Co
 	return bufLockFile.RemotePluginKeys(), nil
 }
 
-func (w *workspaceDepManager) UpdateBufLockFile(ctx context.Context, depModuleKeys []bufmodule.ModuleKey, remotePluginKeys []bufplugin.PluginKey) error {
+func (w *workspaceDepManager) ExistingBufLockFileExtendsModuleKeys(ctx context.Context) ([]bufmodule.ModuleKey, error) {
+	bufLockFile, err := bufconfig.GetBufLockFileForPrefix(ctx, w.bucket, w.targetSubDirPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return bufLockFile.ExtendsModuleKeys(), nil
+}
+
+func (w *workspaceDepManager) UpdateBufLockFile(
+	ctx context.Context,
+	depModuleKeys []bufmodule.ModuleKey,
+	remotePluginKeys []bufplugin.PluginKey,
+	extendsModuleKeys []bufmodule.ModuleKey,
+) error {
 	var bufLockFile bufconfig.BufLockFile
 	var err error
 	if w.isV2 {
-		bufLockFile, err = bufconfig.NewBufLockFile(bufconfig.FileVersionV2, depModuleKeys, remotePluginKeys)
+		bufLockFile, err = bufconfig.NewBufLockFile(bufconfig.FileVersionV2, depModuleKeys, remotePluginKeys, extendsModuleKeys)
 		if err != nil {
 			return err
 		}
@@ -227,7 +282,10 @@ func (w *workspaceDepManager) UpdateBufLockFile(ctx context.Context, depModuleKe
 		if len(remotePluginKeys) > 0 {
 			return syserror.Newf("remote plugins are not supported for v1 buf.yaml files")
 		}
-		bufLockFile, err = bufconfig.NewBufLockFile(fileVersion, depModuleKeys, nil)
+		if len(extendsModuleKeys) > 0 {
+			return syserror.Newf("extends are not supported for v1 buf.yaml files")
+		}
+		bufLockFile, err = bufconfig.NewBufLockFile(fileVersion, depModuleKeys, nil, nil)
 		if err != nil {
 			return err
 		}
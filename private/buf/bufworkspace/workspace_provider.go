@@ -29,9 +29,9 @@ import (
 	"github.com/bufbuild/buf/private/pkg/slicesext"
 	"github.com/bufbuild/buf/private/pkg/slogext"
 	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"github.com/bufbuild/buf/private/pkg/stringutil"
 	"github.com/bufbuild/buf/private/pkg/syserror"
-	"github.com/google/uuid"
 )
 
 // WorkspaceProvider provides Workspaces and UpdateableWorkspaces.
@@ -72,17 +72,24 @@ type WorkspaceProvider interface {
 }
 
 // NewWorkspaceProvider returns a new WorkspaceProvider.
+//
+// The ModuleKeyProvider and storageos.Provider are used to resolve the dependency
+// replacements of v2 buf.yaml files.
 func NewWorkspaceProvider(
 	logger *slog.Logger,
 	graphProvider bufmodule.GraphProvider,
+	moduleKeyProvider bufmodule.ModuleKeyProvider,
 	moduleDataProvider bufmodule.ModuleDataProvider,
 	commitProvider bufmodule.CommitProvider,
+	storageosProvider storageos.Provider,
 ) WorkspaceProvider {
 	return newWorkspaceProvider(
 		logger,
 		graphProvider,
+		moduleKeyProvider,
 		moduleDataProvider,
 		commitProvider,
+		storageosProvider,
 	)
 }
 
@@ -91,21 +98,27 @@ func NewWorkspaceProvider(
 type workspaceProvider struct {
 	logger             *slog.Logger
 	graphProvider      bufmodule.GraphProvider
+	moduleKeyProvider  bufmodule.ModuleKeyProvider
 	moduleDataProvider bufmodule.ModuleDataProvider
 	commitProvider     bufmodule.CommitProvider
+	storageosProvider  storageos.Provider
 }
 
 func newWorkspaceProvider(
 	logger *slog.Logger,
 	graphProvider bufmodule.GraphProvider,
+	moduleKeyProvider bufmodule.ModuleKeyProvider,
 	moduleDataProvider bufmodule.ModuleDataProvider,
 	commitProvider bufmodule.CommitProvider,
+	storageosProvider storageos.Provider,
 ) *workspaceProvider {
 	return &workspaceProvider{
 		logger:             logger,
 		graphProvider:      graphProvider,
+		moduleKeyProvider:  moduleKeyProvider,
 		moduleDataProvider: moduleDataProvider,
 		commitProvider:     commitProvider,
+		storageosProvider:  storageosProvider,
 	}
 }
 
@@ -143,6 +156,10 @@ func (w *workspaceProvider) GetWorkspaceForModuleKey(
 		if err != nil {
 			return nil, err
 		}
+		if len(bufYAMLFile.Extends()) > 0 {
+			// There is no directory to resolve extends against for a module.
+			return nil, fmt.Errorf("extends cannot be used in the configuration for module %s", moduleKey.FullName())
+		}
 		moduleConfigs := bufYAMLFile.ModuleConfigs()
 		switch len(moduleConfigs) {
 		case 0:
@@ -210,6 +227,8 @@ func (w *workspaceProvider) GetWorkspaceForModuleKey(
 		opaqueIDToBreakingConfig,
 		pluginConfigs,
 		nil,
+		nil,
+		nil,
 		false,
 	), nil
 }
@@ -249,6 +268,7 @@ func (w *workspaceProvider) GetWorkspaceForBucket(
 			ctx,
 			bucket,
 			workspaceTargeting.v2,
+			config.ignoreReplacements,
 		)
 	}
 	return w.getWorkspaceForBucketAndModuleDirPathsV1Beta1OrV1(
@@ -271,19 +291,7 @@ func (w *workspaceProvider) getWorkspaceForBucketAndModuleDirPathsV1Beta1OrV1(
 			ctx,
 			bucket, // Need to use the non-mapped bucket since the mapped bucket excludes the buf.lock
 			moduleTargeting.moduleDirPath,
-			bufconfig.BufLockFileWithDigestResolver(
-				func(ctx context.Context, remote string, commitID uuid.UUID) (bufmodule.Digest, error) {
-					commitKey, err := bufmodule.NewCommitKey(remote, commitID, bufmodule.DigestTypeB4)
-					if err != nil {
-						return nil, err
-					}
-					commits, err := w.commitProvider.GetCommitsForCommitKeys(ctx, []bufmodule.CommitKey{commitKey})
-					if err != nil {
-						return nil, err
-					}
-					return commits[0].ModuleKey().Digest()
-				},
-			),
+			bufconfig.BufLockFileWithDigestResolver(w.getB4DigestForCommitID),
 		)
 		if err != nil {
 			if !errors.Is(err, fs.ErrNotExist) {
@@ -360,6 +368,8 @@ func (w *workspaceProvider) getWorkspaceForBucketAndModuleDirPathsV1Beta1OrV1(
 		v1WorkspaceTargeting.bucketIDToModuleConfig,
 		nil,
 		v1WorkspaceTargeting.allConfiguredDepModuleRefs,
+		nil,
+		nil,
 		false,
 	)
 }
@@ -368,7 +378,13 @@ func (w *workspaceProvider) getWorkspaceForBucketBufYAMLV2(
 	ctx context.Context,
 	bucket storage.ReadBucket,
 	v2Targeting *v2Targeting,
+	ignoreReplacements bool,
 ) (*workspace, error) {
+	var replaceConfigs []bufconfig.ReplaceConfig
+	if !ignoreReplacements {
+		replaceConfigs = v2Targeting.bufYAMLFile.ReplaceConfigs()
+	}
+	replacedModuleFullNameStrings := getReplacedModuleFullNameStrings(replaceConfigs)
 	moduleSetBuilder := bufmodule.NewModuleSetBuilder(ctx, w.logger, w.moduleDataProvider, w.commitProvider)
 	bufLockFile, err := bufconfig.GetBufLockFileForPrefix(
 		ctx,
@@ -391,6 +407,10 @@ func (w *workspaceProvider) getWorkspaceForBucketBufYAMLV2(
 			return nil, syserror.Newf("unknown FileVersion: %v", fileVersion)
 		}
 		for _, depModuleKey := range bufLockFile.DepModuleKeys() {
+			// Replaced dependencies are added with the replacements below.
+			if _, ok := replacedModuleFullNameStrings[depModuleKey.FullName().String()]; ok {
+				continue
+			}
 			// DepModuleKeys from a BufLockFile is expected to have all transitive dependencies,
 			// and we can rely on this property.
 			moduleSetBuilder.AddRemoteModule(
@@ -399,6 +419,19 @@ func (w *workspaceProvider) getWorkspaceForBucketBufYAMLV2(
 			)
 		}
 	}
+	// Resolve the extends of the buf.yaml. This only changes the lint, breaking, and plugin
+	// settings of the buf.yaml, so the targeting does not need to be recomputed.
+	bufYAMLFile, err := ResolveBufYAMLFile(ctx, v2Targeting.bufYAMLFile, bucket, w.moduleDataProvider)
+	if err != nil {
+		return nil, err
+	}
+	moduleConfigs := bufYAMLFile.ModuleConfigs()
+	// We always create a new map, as the ModuleConfigs of replacements are added to it.
+	bucketIDToModuleConfig := make(map[string]bufconfig.ModuleConfig, len(moduleConfigs)+len(replaceConfigs))
+	// bucketIDs have the same order as moduleConfigs.
+	for i, bucketID := range bucketIDsForModuleConfigsV2(moduleConfigs) {
+		bucketIDToModuleConfig[bucketID] = moduleConfigs[i]
+	}
 	// Only check for duplicate module description in v2, which would be an user error, i.e.
 	// This is not a system error:
 	// modules:
@@ -416,7 +449,7 @@ func (w *workspaceProvider) getWorkspaceForBucketBufYAMLV2(
 		// Each moduleBucketAndTargeting represents a local module that we want to add to the moduleSet,
 		// and we look up its moduleConfig by its bucketID, because that is guaranteed to be unique (moduleDirPaths
 		// are not in a v2 workspace).
-		moduleConfig, ok := v2Targeting.bucketIDToModuleConfig[moduleBucketAndTargeting.bucketID]
+		moduleConfig, ok := bucketIDToModuleConfig[moduleBucketAndTargeting.bucketID]
 		if !ok {
 			// This should not happen since moduleBucketAndTargeting is derived from the module
 			// configs, however, we return this error as a safety check
@@ -447,15 +480,26 @@ func (w *workspaceProvider) getWorkspaceForBucketBufYAMLV2(
 			bufmodule.LocalModuleWithDescription(moduleDescription),
 		)
 	}
+	if err := w.addReplaceModules(
+		ctx,
+		moduleSetBuilder,
+		bucket,
+		replaceConfigs,
+		bucketIDToModuleConfig,
+	); err != nil {
+		return nil, err
+	}
 	moduleSet, err := moduleSetBuilder.Build()
 	if err != nil {
 		return nil, err
 	}
 	return w.getWorkspaceForBucketModuleSet(
 		moduleSet,
-		v2Targeting.bucketIDToModuleConfig,
-		v2Targeting.bufYAMLFile.PluginConfigs(),
-		v2Targeting.bufYAMLFile.ConfiguredDepModuleRefs(),
+		bucketIDToModuleConfig,
+		bufYAMLFile.PluginConfigs(),
+		bufYAMLFile.ConfiguredDepModuleRefs(),
+		replaceConfigs,
+		bufYAMLFile.VerifyConfigs(),
 		true,
 	)
 }
@@ -467,6 +511,8 @@ func (w *workspaceProvider) getWorkspaceForBucketModuleSet(
 	pluginConfigs []bufconfig.PluginConfig,
 	// Expected to already be unique by FullName.
 	configuredDepModuleRefs []bufparse.Ref,
+	replaceConfigs []bufconfig.ReplaceConfig,
+	verifyConfigs []bufconfig.VerifyConfig,
 	isV2 bool,
 ) (*workspace, error) {
 	opaqueIDToLintConfig := make(map[string]bufconfig.LintConfig)
@@ -491,6 +537,8 @@ func (w *workspaceProvider) getWorkspaceForBucketModuleSet(
 		opaqueIDToBreakingConfig,
 		pluginConfigs,
 		configuredDepModuleRefs,
+		replaceConfigs,
+		verifyConfigs,
 		isV2,
 	), nil
 }
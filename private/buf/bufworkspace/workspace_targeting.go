@@ -598,7 +598,7 @@ func getMappedModuleBucketAndModuleTargeting(
 			for _, exclude := range excludes {
 				notOrMatchers = append(
 					notOrMatchers,
-					storage.MatchPathContained(exclude),
+					getIncludeOrExcludeMatcher(exclude),
 				)
 			}
 			matchers = append(
@@ -616,7 +616,7 @@ func getMappedModuleBucketAndModuleTargeting(
 			for _, include := range includes {
 				orMatchers = append(
 					orMatchers,
-					storage.MatchPathContained(include),
+					getIncludeOrExcludeMatcher(include),
 				)
 			}
 			matchers = append(
@@ -873,6 +873,16 @@ func bucketIDsForModuleConfigsV2(moduleConfigs []bufconfig.ModuleConfig) []strin
 	return bucketIDsForDirPaths(moduleDirPaths, true)
 }
 
+// getIncludeOrExcludeMatcher returns a Matcher for an include or exclude of a ModuleConfig.
+//
+// This must match the semantics of bufconfig.MatchIncludeOrExclude.
+func getIncludeOrExcludeMatcher(includeOrExclude string) storage.Matcher {
+	if normalpath.IsGlob(includeOrExclude) {
+		return storage.MatchPathGlobEqualOrContained(includeOrExclude)
+	}
+	return storage.MatchPathContained(includeOrExclude)
+}
+
 func bucketIDsForDirPaths(moduleDirPaths []string, firstIDHasSuffix bool) []string {
 	bucketIDs := make([]string, 0, len(moduleDirPaths))
 	// Use dirPathToRunningCount to keep track of how many modules of this path has been seen (before the
@@ -321,6 +321,8 @@ func testNewWorkspaceProvider(t *testing.T, testModuleDatas ...bufmoduletesting.
 		bsrProvider,
 		bsrProvider,
 		bsrProvider,
+		bsrProvider,
+		storageos.NewProvider(),
 	)
 }
 
@@ -33,7 +33,9 @@ import (
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/beta/bufpluginv1"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/beta/bufpluginv1beta1"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/beta/bufpluginv2"
+	"github.com/bufbuild/buf/private/buf/cmd/buf/command/beta/fieldnumbers"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/beta/lsp"
+	"github.com/bufbuild/buf/private/buf/cmd/buf/command/beta/migrateeditions"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/beta/price"
 	betaplugindelete "github.com/bufbuild/buf/private/buf/cmd/buf/command/beta/registry/plugin/plugindelete"
 	betapluginpush "github.com/bufbuild/buf/private/buf/cmd/buf/command/beta/registry/plugin/pluginpush"
@@ -46,14 +48,18 @@ import (
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/build"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/config/configinit"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/config/configlsbreakingrules"
+	"github.com/bufbuild/buf/private/buf/cmd/buf/command/config/configlsfiles"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/config/configlslintrules"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/config/configlsmodules"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/config/configmigrate"
+	"github.com/bufbuild/buf/private/buf/cmd/buf/command/config/configshow"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/convert"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/curl"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/dep/depgraph"
+	"github.com/bufbuild/buf/private/buf/cmd/buf/command/dep/depoutdated"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/dep/depprune"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/dep/depupdate"
+	"github.com/bufbuild/buf/private/buf/cmd/buf/command/dep/depwhy"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/export"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/format"
 	"github.com/bufbuild/buf/private/buf/cmd/buf/command/generate"
@@ -151,6 +157,8 @@ func NewRootCommand(name string) *appcmd.Command {
 					depgraph.NewCommand("graph", builder),
 					depprune.NewCommand("prune", builder, ``, false),
 					depupdate.NewCommand("update", builder, ``, false),
+					depoutdated.NewCommand("outdated", builder),
+					depwhy.NewCommand("why", builder),
 				},
 			},
 			{
@@ -162,6 +170,8 @@ func NewRootCommand(name string) *appcmd.Command {
 					configlslintrules.NewCommand("ls-lint-rules", builder),
 					configlsbreakingrules.NewCommand("ls-breaking-rules", builder),
 					configlsmodules.NewCommand("ls-modules", builder),
+					configlsfiles.NewCommand("ls-files", builder),
+					configshow.NewCommand("show", builder),
 				},
 			},
 			{
@@ -313,6 +323,8 @@ func NewRootCommand(name string) *appcmd.Command {
 				Short: "Beta commands. Unstable and likely to change",
 				SubCommands: []*appcmd.Command{
 					lsp.NewCommand("lsp", builder),
+					fieldnumbers.NewCommand("field-numbers", builder),
+					migrateeditions.NewCommand("migrate-editions", builder),
 					price.NewCommand("price", builder),
 					stats.NewCommand("stats", builder),
 					bufpluginv1beta1.NewCommand("buf-plugin-v1beta1", builder),
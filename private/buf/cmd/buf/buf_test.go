@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -35,6 +36,7 @@ import (
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck"
 	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulesign"
 	imagev1 "github.com/bufbuild/buf/private/gen/proto/go/buf/alpha/image/v1"
 	"github.com/bufbuild/buf/private/pkg/app/appcmd"
 	"github.com/bufbuild/buf/private/pkg/app/appcmd/appcmdtesting"
@@ -42,6 +44,7 @@ import (
 	"github.com/bufbuild/buf/private/pkg/protoencoding"
 	"github.com/bufbuild/buf/private/pkg/slicesext"
 	"github.com/bufbuild/buf/private/pkg/slogtestext"
+	"github.com/bufbuild/buf/private/pkg/storage"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"github.com/bufbuild/buf/private/pkg/storage/storagetesting"
 	"github.com/bufbuild/buf/private/pkg/wasm"
@@ -98,6 +101,8 @@ var (
 		{ID: "COMMENT_SERVICE", Categories: []string{"COMMENTS"}, Default: false, Purpose: "Checks that services have non-empty comments."},
 		{ID: "RPC_NO_CLIENT_STREAMING", Categories: []string{"UNARY_RPC"}, Default: false, Purpose: "Checks that RPCs are not client streaming."},
 		{ID: "RPC_NO_SERVER_STREAMING", Categories: []string{"UNARY_RPC"}, Default: false, Purpose: "Checks that RPCs are not server streaming."},
+		{ID: "LICENSE_HEADER_DEFINED", Categories: []string{}, Default: false, Purpose: "Checks that all files start with the configured license header."},
+		{ID: "LICENSE_HEADER_YEAR_RANGE", Categories: []string{}, Default: false, Purpose: "Checks that the license headers of all files have the configured year range."},
 		{ID: "STABLE_PACKAGE_NO_IMPORT_UNSTABLE", Categories: []string{}, Default: false, Purpose: "Checks that all files that have stable versioned packages do not import packages with unstable version packages."},
 	}
 	// ordered, contains non-default
@@ -964,6 +969,8 @@ COMMENT_RPC                        COMMENTS                           Checks tha
 COMMENT_SERVICE                    COMMENTS                           Checks that services have non-empty comments.
 RPC_NO_CLIENT_STREAMING            UNARY_RPC                          Checks that RPCs are not client streaming.
 RPC_NO_SERVER_STREAMING            UNARY_RPC                          Checks that RPCs are not server streaming.
+LICENSE_HEADER_DEFINED                                                Checks that all files start with the configured license header.
+LICENSE_HEADER_YEAR_RANGE                                             Checks that the license headers of all files have the configured year range.
 STABLE_PACKAGE_NO_IMPORT_UNSTABLE                                     Checks that all files that have stable versioned packages do not import packages with unstable version packages.
 		`
 	testRunStdout(
@@ -2504,6 +2511,65 @@ func TestImageConvertRoundtripJSONBinaryJSON(t *testing.T) {
 	require.Equal(t, json1, stdout.Bytes())
 }
 
+func TestImageConvertRoundtripBinaryDirBinary(t *testing.T) {
+	t.Parallel()
+
+	stdout := bytes.NewBuffer(nil)
+	testRun(
+		t,
+		0,
+		nil,
+		stdout,
+		"build",
+		"--exclude-source-info",
+		"-o",
+		"-",
+		filepath.Join("testdata", "customoptions1"),
+	)
+
+	binary1 := stdout.Bytes()
+	require.NotEmpty(t, binary1)
+
+	tempDir := t.TempDir()
+	testRun(
+		t,
+		0,
+		bytes.NewReader(binary1),
+		nil,
+		"build",
+		"-",
+		"-o",
+		tempDir+"#format=dir",
+	)
+	readWriteBucket, err := storageos.NewProvider().NewReadWriteBucket(tempDir)
+	require.NoError(t, err)
+	// The imported google/protobuf/descriptor.proto is not written.
+	storagetesting.AssertPaths(
+		t,
+		readWriteBucket,
+		"",
+		"a.proto",
+	)
+	data, err := storage.ReadPath(context.Background(), readWriteBucket, "a.proto")
+	require.NoError(t, err)
+	require.Contains(t, string(data), "string bar = 1 [(baz) = 42];")
+
+	stdout = bytes.NewBuffer(nil)
+	testRun(
+		t,
+		0,
+		nil,
+		stdout,
+		"build",
+		"--exclude-source-info",
+		"-o",
+		"-",
+		tempDir,
+	)
+
+	require.Equal(t, binary1, stdout.Bytes())
+}
+
 func TestModInitBasic(t *testing.T) {
 	t.Parallel()
 	testModInit(
@@ -2542,6 +2608,111 @@ testdata/paths/a/v3/foo/foo.proto`),
 	)
 }
 
+func TestConfigLsFilesExplainGlobs(t *testing.T) {
+	// Cannot be parallel since we chdir.
+	pwd, err := osext.Getwd()
+	require.NoError(t, err)
+	defer func() {
+		r := recover()
+		assert.NoError(t, osext.Chdir(pwd))
+		if r != nil {
+			panic(r)
+		}
+	}()
+	tempDir := t.TempDir()
+	pathToData := map[string]string{
+		"buf.yaml": `version: v2
+modules:
+  - path: proto
+    includes:
+      - proto/{foo,bar}
+    excludes:
+      - proto/**/internal
+      - proto/**/*_gen.proto
+`,
+	}
+	for _, path := range []string{
+		"proto/bar/v1/bar.proto",
+		"proto/baz/v1/baz.proto",
+		"proto/foo/internal/internal.proto",
+		"proto/foo/v1/foo.proto",
+		"proto/foo/v1/foo_gen.proto",
+	} {
+		pathToData[path] = "syntax = \"proto3\";\n"
+	}
+	writeTestFiles(t, tempDir, pathToData)
+	require.NoError(t, osext.Chdir(tempDir))
+
+	// The workspace, which matches includes and excludes with bufworkspace, and config ls-files,
+	// which matches them with bufconfig.MatchIncludeOrExclude, must agree on the files of the module.
+	testRunStdout(
+		t,
+		nil,
+		0,
+		filepath.FromSlash(`proto/bar/v1/bar.proto
+proto/foo/v1/foo.proto`),
+		"ls-files",
+	)
+	testRunStdout(
+		t,
+		nil,
+		0,
+		`proto/bar/v1/bar.proto	"proto"
+proto/foo/v1/foo.proto	"proto"`,
+		"config",
+		"ls-files",
+	)
+	testRunStdout(
+		t,
+		nil,
+		0,
+		`proto/bar/v1/bar.proto:
+  module "proto" claims the file: included by include "proto/{foo,bar}"
+    path within module: bar/v1/bar.proto
+The file belongs to module "proto".`,
+		"config",
+		"ls-files",
+		"--explain",
+		"proto/bar/v1/bar.proto",
+	)
+	testRunStdout(
+		t,
+		nil,
+		0,
+		`proto/baz/v1/baz.proto:
+  module "proto" does not claim the file: not matched by any include: "proto/{foo,bar}"
+No configured module claims the file.`,
+		"config",
+		"ls-files",
+		"--explain",
+		"proto/baz/v1/baz.proto",
+	)
+	testRunStdout(
+		t,
+		nil,
+		0,
+		`proto/foo/internal/internal.proto:
+  module "proto" does not claim the file: excluded by exclude "proto/**/internal"
+No configured module claims the file.`,
+		"config",
+		"ls-files",
+		"--explain",
+		"proto/foo/internal/internal.proto",
+	)
+	testRunStdout(
+		t,
+		nil,
+		0,
+		`proto/foo/v1/foo_gen.proto:
+  module "proto" does not claim the file: excluded by exclude "proto/**/*_gen.proto"
+No configured module claims the file.`,
+		"config",
+		"ls-files",
+		"--explain",
+		"proto/foo/v1/foo_gen.proto",
+	)
+}
+
 func TestBuildOverlappingPaths(t *testing.T) {
 	t.Parallel()
 	// This may differ from LsFilesOverlappingPaths as we do a build of an image here.
@@ -2688,6 +2859,104 @@ func TestExportPaths(t *testing.T) {
 	)
 }
 
+func TestExportExcludeType(t *testing.T) {
+	t.Parallel()
+	tempDir := t.TempDir()
+	testRunStdout(
+		t,
+		nil,
+		0,
+		``,
+		"export",
+		filepath.Join("testdata", "export_exclude"),
+		"--exclude-type",
+		"acme.internal.v1",
+		"-o",
+		tempDir,
+	)
+	readWriteBucket, err := storageos.NewProvider().NewReadWriteBucket(tempDir)
+	require.NoError(t, err)
+	data, err := storage.ReadPath(context.Background(), readWriteBucket, "user.proto")
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		`syntax = "proto3";
+
+package acme.v1;
+
+// User is a user.
+message User {
+  // The ID of the user.
+  string id = 1;
+}
+`,
+		string(data),
+	)
+	testRunStdoutStderrNoWarn(
+		t,
+		nil,
+		1,
+		``,
+		`Failure: excluding type "acme.internal.v1.Missing": not found`,
+		"export",
+		filepath.Join("testdata", "export_exclude"),
+		"--exclude-type",
+		"acme.internal.v1.Missing",
+		"-o",
+		tempDir,
+	)
+}
+
+func TestExportType(t *testing.T) {
+	t.Parallel()
+	tempDir := t.TempDir()
+	testRunStdout(
+		t,
+		nil,
+		0,
+		``,
+		"export",
+		filepath.Join("testdata", "export_exclude"),
+		"--type",
+		"acme.internal.v1.Debug",
+		"-o",
+		tempDir,
+	)
+	readWriteBucket, err := storageos.NewProvider().NewReadWriteBucket(tempDir)
+	require.NoError(t, err)
+	storagetesting.AssertPaths(
+		t,
+		readWriteBucket,
+		"",
+		"internal.proto",
+	)
+
+	tempDir = t.TempDir()
+	testRunStdout(
+		t,
+		nil,
+		0,
+		``,
+		"export",
+		filepath.Join("testdata", "export_exclude"),
+		"--type",
+		"acme.v1.User",
+		"--exclude-type",
+		"acme.internal.v1.Debug",
+		"-o",
+		tempDir,
+	)
+	readWriteBucket, err = storageos.NewProvider().NewReadWriteBucket(tempDir)
+	require.NoError(t, err)
+	// internal.proto is left empty, so it is dropped.
+	storagetesting.AssertPaths(
+		t,
+		readWriteBucket,
+		"",
+		"user.proto",
+	)
+}
+
 func TestExportPathsAndExcludes(t *testing.T) {
 	t.Parallel()
 	tempDir := t.TempDir()
@@ -2893,91 +3162,220 @@ func TestLintWithPaths(t *testing.T) {
 	)
 }
 
-func TestLintWithPlugins(t *testing.T) {
+func TestLintWarn(t *testing.T) {
 	t.Parallel()
-	// defaults only, comment ignores on.
 	testRunStdout(
 		t,
 		nil,
 		bufctl.ExitCodeFileAnnotation,
-		filepath.FromSlash(`
-testdata/check_plugins/current/proto/api/v1/service.proto:11:1:Service name "api.v1.FooServiceMock" has banned suffix "Mock". (buf-plugin-suffix)
-testdata/check_plugins/current/proto/api/v1/service.proto:12:14:RPC request type "GetFooMockRequest" should be named "GetFooRequest" or "FooServiceMockGetFooRequest".
-testdata/check_plugins/current/proto/api/v1/service.proto:12:42:RPC response type "GetFooMockResponse" should be named "GetFooResponse" or "FooServiceMockGetFooResponse".
-testdata/check_plugins/current/proto/api/v1/service.proto:16:9:Service name "FooServiceTest" should be suffixed with "Service".
-testdata/check_plugins/current/proto/api/v1/service.proto:17:14:RPC request type "GetFooTestRequest" should be named "GetFooRequest" or "FooServiceTestGetFooRequest".
-testdata/check_plugins/current/proto/api/v1/service.proto:17:42:RPC response type "GetFooTestResponse" should be named "GetFooResponse" or "FooServiceTestGetFooResponse".
-testdata/check_plugins/current/proto/api/v1/service.proto:26:1:"ListFooResponse" is a pagination response without a page token field named "page_token" (buf-plugin-rpc-ext)
-testdata/check_plugins/current/proto/common/v1alpha1/messages.proto:16:5:field "common.v1alpha1.Four.FourTwo.id" does not have rule (buf.validate.field).string.tuuid set (buf-plugin-protovalidate-ext)
-testdata/check_plugins/current/vendor/protovalidate/buf/validate/validate.proto:94:3:field "buf.validate.Constraint.id" does not have rule (buf.validate.field).string.tuuid set (buf-plugin-protovalidate-ext)
-		`),
+		filepath.FromSlash(`testdata/fail/buf/buf.proto:3:1:Files with package "other" must be within a directory "other" relative to root but were in directory "buf".
+        testdata/fail/buf/buf.proto:6:9:warning: Field name "oneTwo" should be lower_snake_case, such as "one_two".`),
 		"lint",
-		filepath.Join("testdata", "check_plugins", "current"),
+		filepath.Join("testdata", "fail"),
+		"--config",
+		`{"version":"v1","lint":{"use":["BASIC"],"warn":["FIELD_LOWER_SNAKE_CASE"]}}`,
 	)
-	// Defaults only, comment ignores off.
-	// Always ignore the vendored protovalidate module.
-	//
-	// There are still lint failures for protovalidate despite being set as an ignore path because
-	// proto imports these files, and paths outside of a module cannot be configured as ignore paths
-	// for the module -- this is expected behavior for now.
+	warnConfig := `{"version":"v1","lint":{"use":["BASIC"],"warn":["FIELD_LOWER_SNAKE_CASE","PACKAGE_DIRECTORY_MATCH"]}}`
 	testRunStdout(
 		t,
 		nil,
-		bufctl.ExitCodeFileAnnotation,
-		filepath.FromSlash(`
-testdata/check_plugins/current/proto/api/v1/service.proto:11:1:Service name "api.v1.FooServiceMock" has banned suffix "Mock". (buf-plugin-suffix)
-testdata/check_plugins/current/proto/api/v1/service.proto:11:9:Service name "FooServiceMock" should be suffixed with "Service".
-testdata/check_plugins/current/proto/api/v1/service.proto:12:14:RPC request type "GetFooMockRequest" should be named "GetFooRequest" or "FooServiceMockGetFooRequest".
-testdata/check_plugins/current/proto/api/v1/service.proto:12:42:RPC response type "GetFooMockResponse" should be named "GetFooResponse" or "FooServiceMockGetFooResponse".
-testdata/check_plugins/current/proto/api/v1/service.proto:16:1:Service name "api.v1.FooServiceTest" has banned suffix "Test". (buf-plugin-suffix)
-testdata/check_plugins/current/proto/api/v1/service.proto:16:9:Service name "FooServiceTest" should be suffixed with "Service".
-testdata/check_plugins/current/proto/api/v1/service.proto:17:14:RPC request type "GetFooTestRequest" should be named "GetFooRequest" or "FooServiceTestGetFooRequest".
-testdata/check_plugins/current/proto/api/v1/service.proto:17:42:RPC response type "GetFooTestResponse" should be named "GetFooResponse" or "FooServiceTestGetFooResponse".
-testdata/check_plugins/current/proto/api/v1/service.proto:26:1:"ListFooResponse" is a pagination response without a page token field named "page_token" (buf-plugin-rpc-ext)
-testdata/check_plugins/current/proto/common/v1alpha1/messages.proto:16:5:field "common.v1alpha1.Four.FourTwo.id" does not have rule (buf.validate.field).string.tuuid set (buf-plugin-protovalidate-ext)
-testdata/check_plugins/current/vendor/protovalidate/buf/validate/validate.proto:94:3:field "buf.validate.Constraint.id" does not have rule (buf.validate.field).string.tuuid set (buf-plugin-protovalidate-ext)
-		`),
+		0,
+		filepath.FromSlash(`testdata/fail/buf/buf.proto:3:1:warning: Files with package "other" must be within a directory "other" relative to root but were in directory "buf".
+        testdata/fail/buf/buf.proto:6:9:warning: Field name "oneTwo" should be lower_snake_case, such as "one_two".`),
 		"lint",
-		filepath.Join("testdata", "check_plugins", "current"),
+		filepath.Join("testdata", "fail"),
 		"--config",
-		`{
-			"version":"v2",
-			"modules": [
-				{"path": "testdata/check_plugins/current/proto"},
-				{"path": "testdata/check_plugins/current/vendor/protovalidate"}
-			],
-			"lint": {
-				"disallow_comment_ignores": true,
-				"ignore": [
-					"testdata/check_plugins/current/vendor/protovalidate",
-    				"testdata/check_plugins/current/proto/common/v1/breaking.proto",
-    				"testdata/check_plugins/current/proto/common/v1alpha1/breaking.proto"
-				]
-			},
-			"plugins":[
-				{
-					"plugin": "buf-plugin-suffix",
-					"options": {
-						"service_banned_suffixes": ["Mock", "Test"],
-						"rpc_banned_suffixes": ["Element"],
-						"field_banned_suffixes": ["_uuid"],
-						"enum_value_banned_suffixes": ["_invalid"],
-						"service_no_change_suffixes": ["Service"],
-						"message_no_change_suffixes": ["Request", "Response"],
-						"enum_no_change_suffixes": ["State"]
-					}
-				},
-				{"plugin": "buf-plugin-protovalidate-ext"},
-				{"plugin": "buf-plugin-rpc-ext"}
-			]
-		}`,
+		warnConfig,
 	)
-	// With specified use, ignore, and ignore_only configurations.
-	// Always ignore the vendored protovalidate module.
-	//
-	// There are still lint failures for protovalidate despite being set as an ignore path because
-	// proto imports these files, and paths outside of a module cannot be configured as ignore paths
-	// for the module -- this is expected behavior for now.
+	testRunStdout(
+		t,
+		nil,
+		0,
+		filepath.FromSlash(`::warning file=testdata/fail/buf/buf.proto,line=3,col=1,endLine=3,endColumn=15::Files with package "other" must be within a directory "other" relative to root but were in directory "buf".
+        ::warning file=testdata/fail/buf/buf.proto,line=6,col=9,endLine=6,endColumn=15::Field name "oneTwo" should be lower_snake_case, such as "one_two".`),
+		"lint",
+		filepath.Join("testdata", "fail"),
+		"--config",
+		warnConfig,
+		"--error-format",
+		"github-actions",
+	)
+	testRunStdout(
+		t,
+		nil,
+		bufctl.ExitCodeFileAnnotation,
+		filepath.FromSlash(`testdata/fail/buf/buf.proto:3:1:warning: Files with package "other" must be within a directory "other" relative to root but were in directory "buf".
+        testdata/fail/buf/buf.proto:6:9:warning: Field name "oneTwo" should be lower_snake_case, such as "one_two".`),
+		"lint",
+		filepath.Join("testdata", "fail"),
+		"--config",
+		warnConfig,
+		"--fail-on",
+		"warning",
+	)
+	testRunStderr(
+		t,
+		nil,
+		bufctl.ExitCodeFileAnnotation,
+		`Failure: found 2 warnings, which is more than --max-warnings 1`,
+		"lint",
+		filepath.Join("testdata", "fail"),
+		"--config",
+		warnConfig,
+		"--max-warnings",
+		"1",
+	)
+	testRunStdout(
+		t,
+		nil,
+		0,
+		filepath.FromSlash(`testdata/fail/buf/buf.proto:3:1:warning: Files with package "other" must be within a directory "other" relative to root but were in directory "buf".
+        testdata/fail/buf/buf.proto:6:9:warning: Field name "oneTwo" should be lower_snake_case, such as "one_two".`),
+		"lint",
+		filepath.Join("testdata", "fail"),
+		"--config",
+		warnConfig,
+		"--max-warnings",
+		"2",
+	)
+	infoConfig := `{"version":"v1","lint":{"use":["BASIC"],"warn":["PACKAGE_DIRECTORY_MATCH"],"info":["FIELD_LOWER_SNAKE_CASE"]}}`
+	testRunStdout(
+		t,
+		nil,
+		0,
+		filepath.FromSlash(`testdata/fail/buf/buf.proto:3:1:warning: Files with package "other" must be within a directory "other" relative to root but were in directory "buf".
+        testdata/fail/buf/buf.proto:6:9:info: Field name "oneTwo" should be lower_snake_case, such as "one_two".`),
+		"lint",
+		filepath.Join("testdata", "fail"),
+		"--config",
+		infoConfig,
+		"--max-warnings",
+		"1",
+	)
+	testRunStdout(
+		t,
+		nil,
+		0,
+		filepath.FromSlash(`::warning file=testdata/fail/buf/buf.proto,line=3,col=1,endLine=3,endColumn=15::Files with package "other" must be within a directory "other" relative to root but were in directory "buf".
+        ::notice file=testdata/fail/buf/buf.proto,line=6,col=9,endLine=6,endColumn=15::Field name "oneTwo" should be lower_snake_case, such as "one_two".`),
+		"lint",
+		filepath.Join("testdata", "fail"),
+		"--config",
+		infoConfig,
+		"--error-format",
+		"github-actions",
+	)
+	testRunStdout(
+		t,
+		nil,
+		bufctl.ExitCodeFileAnnotation,
+		filepath.FromSlash(`testdata/fail/buf/buf.proto:6:9:info: Field name "oneTwo" should be lower_snake_case, such as "one_two".`),
+		"lint",
+		filepath.Join("testdata", "fail"),
+		"--config",
+		`{"version":"v1","lint":{"use":["FIELD_LOWER_SNAKE_CASE"],"info":["FIELD_LOWER_SNAKE_CASE"]}}`,
+		"--fail-on",
+		"info",
+	)
+	testRunStdout(
+		t,
+		nil,
+		0,
+		filepath.FromSlash(`testdata/fail/buf/buf.proto:6:9:info: Field name "oneTwo" should be lower_snake_case, such as "one_two".`),
+		"lint",
+		filepath.Join("testdata", "fail"),
+		"--config",
+		`{"version":"v1","lint":{"use":["FIELD_LOWER_SNAKE_CASE"],"info":["FIELD_LOWER_SNAKE_CASE"]}}`,
+		"--fail-on",
+		"warning",
+	)
+}
+
+func TestLintWithPlugins(t *testing.T) {
+	t.Parallel()
+	// defaults only, comment ignores on.
+	testRunStdout(
+		t,
+		nil,
+		bufctl.ExitCodeFileAnnotation,
+		filepath.FromSlash(`
+testdata/check_plugins/current/proto/api/v1/service.proto:11:1:Service name "api.v1.FooServiceMock" has banned suffix "Mock". (buf-plugin-suffix)
+testdata/check_plugins/current/proto/api/v1/service.proto:12:14:RPC request type "GetFooMockRequest" should be named "GetFooRequest" or "FooServiceMockGetFooRequest".
+testdata/check_plugins/current/proto/api/v1/service.proto:12:42:RPC response type "GetFooMockResponse" should be named "GetFooResponse" or "FooServiceMockGetFooResponse".
+testdata/check_plugins/current/proto/api/v1/service.proto:16:9:Service name "FooServiceTest" should be suffixed with "Service".
+testdata/check_plugins/current/proto/api/v1/service.proto:17:14:RPC request type "GetFooTestRequest" should be named "GetFooRequest" or "FooServiceTestGetFooRequest".
+testdata/check_plugins/current/proto/api/v1/service.proto:17:42:RPC response type "GetFooTestResponse" should be named "GetFooResponse" or "FooServiceTestGetFooResponse".
+testdata/check_plugins/current/proto/api/v1/service.proto:26:1:"ListFooResponse" is a pagination response without a page token field named "page_token" (buf-plugin-rpc-ext)
+testdata/check_plugins/current/proto/common/v1alpha1/messages.proto:16:5:field "common.v1alpha1.Four.FourTwo.id" does not have rule (buf.validate.field).string.tuuid set (buf-plugin-protovalidate-ext)
+testdata/check_plugins/current/vendor/protovalidate/buf/validate/validate.proto:94:3:field "buf.validate.Constraint.id" does not have rule (buf.validate.field).string.tuuid set (buf-plugin-protovalidate-ext)
+		`),
+		"lint",
+		filepath.Join("testdata", "check_plugins", "current"),
+	)
+	// Defaults only, comment ignores off.
+	// Always ignore the vendored protovalidate module.
+	//
+	// There are still lint failures for protovalidate despite being set as an ignore path because
+	// proto imports these files, and paths outside of a module cannot be configured as ignore paths
+	// for the module -- this is expected behavior for now.
+	testRunStdout(
+		t,
+		nil,
+		bufctl.ExitCodeFileAnnotation,
+		filepath.FromSlash(`
+testdata/check_plugins/current/proto/api/v1/service.proto:11:1:Service name "api.v1.FooServiceMock" has banned suffix "Mock". (buf-plugin-suffix)
+testdata/check_plugins/current/proto/api/v1/service.proto:11:9:Service name "FooServiceMock" should be suffixed with "Service".
+testdata/check_plugins/current/proto/api/v1/service.proto:12:14:RPC request type "GetFooMockRequest" should be named "GetFooRequest" or "FooServiceMockGetFooRequest".
+testdata/check_plugins/current/proto/api/v1/service.proto:12:42:RPC response type "GetFooMockResponse" should be named "GetFooResponse" or "FooServiceMockGetFooResponse".
+testdata/check_plugins/current/proto/api/v1/service.proto:16:1:Service name "api.v1.FooServiceTest" has banned suffix "Test". (buf-plugin-suffix)
+testdata/check_plugins/current/proto/api/v1/service.proto:16:9:Service name "FooServiceTest" should be suffixed with "Service".
+testdata/check_plugins/current/proto/api/v1/service.proto:17:14:RPC request type "GetFooTestRequest" should be named "GetFooRequest" or "FooServiceTestGetFooRequest".
+testdata/check_plugins/current/proto/api/v1/service.proto:17:42:RPC response type "GetFooTestResponse" should be named "GetFooResponse" or "FooServiceTestGetFooResponse".
+testdata/check_plugins/current/proto/api/v1/service.proto:26:1:"ListFooResponse" is a pagination response without a page token field named "page_token" (buf-plugin-rpc-ext)
+testdata/check_plugins/current/proto/common/v1alpha1/messages.proto:16:5:field "common.v1alpha1.Four.FourTwo.id" does not have rule (buf.validate.field).string.tuuid set (buf-plugin-protovalidate-ext)
+testdata/check_plugins/current/vendor/protovalidate/buf/validate/validate.proto:94:3:field "buf.validate.Constraint.id" does not have rule (buf.validate.field).string.tuuid set (buf-plugin-protovalidate-ext)
+		`),
+		"lint",
+		filepath.Join("testdata", "check_plugins", "current"),
+		"--config",
+		`{
+			"version":"v2",
+			"modules": [
+				{"path": "testdata/check_plugins/current/proto"},
+				{"path": "testdata/check_plugins/current/vendor/protovalidate"}
+			],
+			"lint": {
+				"disallow_comment_ignores": true,
+				"ignore": [
+					"testdata/check_plugins/current/vendor/protovalidate",
+    				"testdata/check_plugins/current/proto/common/v1/breaking.proto",
+    				"testdata/check_plugins/current/proto/common/v1alpha1/breaking.proto"
+				]
+			},
+			"plugins":[
+				{
+					"plugin": "buf-plugin-suffix",
+					"options": {
+						"service_banned_suffixes": ["Mock", "Test"],
+						"rpc_banned_suffixes": ["Element"],
+						"field_banned_suffixes": ["_uuid"],
+						"enum_value_banned_suffixes": ["_invalid"],
+						"service_no_change_suffixes": ["Service"],
+						"message_no_change_suffixes": ["Request", "Response"],
+						"enum_no_change_suffixes": ["State"]
+					}
+				},
+				{"plugin": "buf-plugin-protovalidate-ext"},
+				{"plugin": "buf-plugin-rpc-ext"}
+			]
+		}`,
+	)
+	// With specified use, ignore, and ignore_only configurations.
+	// Always ignore the vendored protovalidate module.
+	//
+	// There are still lint failures for protovalidate despite being set as an ignore path because
+	// proto imports these files, and paths outside of a module cannot be configured as ignore paths
+	// for the module -- this is expected behavior for now.
 	testRunStdout(
 		t,
 		nil,
@@ -3067,88 +3465,974 @@ testdata/check_plugins/current/proto/common/v1/common.proto:8:5:Field name "comm
 		}`,
 	)
 
-	// tests that if a plugin panics, the buf CLI does not panic
-	require.NotPanics(
+	// tests that if a plugin panics, the buf CLI does not panic
+	require.NotPanics(
+		t,
+		func() {
+			appcmdtesting.RunCommandExitCodeStderrContains(
+				t,
+				func(use string) *appcmd.Command { return NewRootCommand(use) },
+				1,
+				[]string{
+					`panic: this panic is intentional`,
+					`Failure: plugin "buf-plugin-panic" failed: Exited with code 2: exit status 2`,
+				},
+				internaltesting.NewEnvFunc(t),
+				nil,
+				"lint",
+				filepath.Join("testdata", "check_plugins", "current"),
+				"--config",
+				`{
+					"version":"v2",
+					"modules": [
+						{"path": "testdata/check_plugins/current/proto"},
+						{"path": "testdata/check_plugins/current/vendor/protovalidate"}
+					],
+					"lint": {
+						"use": ["LINT_PANIC"]
+					},
+					"plugins":[
+						{"plugin": "buf-plugin-panic"}
+					]
+				}`,
+			)
+		},
+	)
+}
+
+func TestBreakingWithPaths(t *testing.T) {
+	t.Parallel()
+	tempDir := t.TempDir()
+	testRunStdout(t, nil, 0, ``, "build", filepath.Join("command", "generate", "testdata", "paths"), "-o", filepath.Join(tempDir, "previous.binpb"))
+	testRunStdout(t, nil, 0, ``, "build", filepath.Join("testdata", "paths"), "-o", filepath.Join(tempDir, "current.binpb"))
+	readWriteBucket, err := storageos.NewProvider().NewReadWriteBucket(tempDir)
+	require.NoError(t, err)
+	storagetesting.AssertPaths(
+		t,
+		readWriteBucket,
+		"",
+		"previous.binpb",
+		"current.binpb",
+	)
+	testRunStdoutStderrNoWarn(
+		t,
+		nil,
+		bufctl.ExitCodeFileAnnotation,
+		`a/v3/a.proto:6:3:Field "1" with name "key" on message "Foo" changed type from "string" to "int32".
+a/v3/a.proto:7:3:Field "2" with name "Value" on message "Foo" changed option "json_name" from "value" to "Value".
+a/v3/a.proto:7:10:Field "2" on message "Foo" changed name from "value" to "Value".`,
+		"",
+		"breaking",
+		filepath.Join(tempDir, "current.binpb"),
+		"--against",
+		filepath.Join(tempDir, "previous.binpb"),
+		"--path",
+		filepath.Join("a", "v3"),
+		"--exclude-path",
+		filepath.Join("a", "v3", "foo"),
+	)
+	testRunStdoutStderrNoWarn(
+		t,
+		nil,
+		bufctl.ExitCodeFileAnnotation,
+		`a/v3/a.proto:6:3:Field "1" with name "key" on message "Foo" changed type from "string" to "int32". See https://developers.google.com/protocol-buffers/docs/proto3#updating for wire compatibility rules.`,
+		"",
+		"breaking",
+		filepath.Join(tempDir, "current.binpb"),
+		"--against",
+		filepath.Join(tempDir, "previous.binpb"),
+		"--path",
+		filepath.Join("a", "v3"),
+		"--exclude-path",
+		filepath.Join("a", "v3", "foo"),
+		"--config",
+		`{"version":"v2","breaking":{"use":["WIRE"]}}`,
+	)
+}
+
+func TestBreakingSince(t *testing.T) {
+	t.Parallel()
+	dirPath := t.TempDir()
+	protoDirPath := filepath.Join(dirPath, "proto")
+	require.NoError(t, os.MkdirAll(protoDirPath, 0755))
+	runGit := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-C", dirPath, "-c", "user.name=Buf go tests", "-c", "user.email=tests@buf.build"}, args...)...)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+		return strings.TrimSpace(string(output))
+	}
+	runGit("init")
+	var hashes []string
+	for i, fields := range []string{
+		"string a = 1; string b = 2;",
+		// Not breaking.
+		"string a = 1; string b = 2; string c = 3;",
+		// Breaking.
+		"string a = 1; string c = 3;",
+		// Not breaking.
+		"string a = 1; string c = 3; string d = 4;",
+	} {
+		require.NoError(
+			t,
+			os.WriteFile(
+				filepath.Join(protoDirPath, "a.proto"),
+				[]byte(`syntax = "proto3";
+package a;
+message Foo {
+  `+fields+`
+}
+`),
+				0600,
+			),
+		)
+		runGit("add", ".")
+		runGit("commit", "-m", fmt.Sprintf("commit %d", i))
+		hashes = append(hashes, runGit("rev-parse", "HEAD"))
+	}
+	testRunStdoutStderrNoWarn(
+		t,
+		nil,
+		bufctl.ExitCodeFileAnnotation,
+		// Paths are relative to the root of the git repository.
+		`
+proto/a.proto:3:1:Previously present field "2" with name "b" on message "Foo" was deleted.
+proto/a.proto:3:1:Previously present field "2" with name "b" on message "Foo" was deleted.
+`,
+		fmt.Sprintf(
+			`
+Breaking changes in %s ("commit 2") compared to %s:
+Breaking changes in %s ("commit 3") compared to %s (HEAD~3):
+First commit with breaking changes compared to %s (HEAD~3): %s ("commit 2")
+`,
+			hashes[2][:7],
+			hashes[1][:7],
+			hashes[3][:7],
+			hashes[0][:7],
+			hashes[0][:7],
+			hashes[2][:7],
+		),
+		"breaking",
+		protoDirPath,
+		"--since",
+		"HEAD~3",
+	)
+	testRunStdoutStderrNoWarn(
+		t,
+		nil,
+		0,
+		``,
+		``,
+		"breaking",
+		protoDirPath,
+		"--since",
+		"HEAD~3",
+		"--until",
+		"HEAD~2",
+	)
+	// The .git entry of a linked worktree is a file that points to the main repository.
+	worktreeDirPath := filepath.Join(t.TempDir(), "worktree")
+	runGit("worktree", "add", "--detach", worktreeDirPath, "HEAD~1")
+	testRunStdoutStderrNoWarn(
+		t,
+		nil,
+		bufctl.ExitCodeFileAnnotation,
+		`
+proto/a.proto:3:1:Previously present field "2" with name "b" on message "Foo" was deleted.
+proto/a.proto:3:1:Previously present field "2" with name "b" on message "Foo" was deleted.
+`,
+		fmt.Sprintf(
+			`
+Breaking changes in %s ("commit 2") compared to %s:
+Breaking changes in %s ("commit 2") compared to %s (HEAD~1):
+First commit with breaking changes compared to %s (HEAD~1): %s ("commit 2")
+`,
+			hashes[2][:7],
+			hashes[1][:7],
+			hashes[2][:7],
+			hashes[1][:7],
+			hashes[1][:7],
+			hashes[2][:7],
+		),
+		"breaking",
+		filepath.Join(worktreeDirPath, "proto"),
+		"--since",
+		"HEAD~1",
+	)
+	testRunStderrContainsNoWarn(
+		t,
+		nil,
+		1,
+		[]string{`Failure: --against and --since cannot be set at the same time`},
+		"breaking",
+		protoDirPath,
+		"--since",
+		"HEAD~3",
+		"--against",
+		protoDirPath,
+	)
+	testRunStderrContainsNoWarn(
+		t,
+		nil,
+		1,
+		[]string{`Failure: --until can only be set if --since is set`},
+		"breaking",
+		protoDirPath,
+		"--against",
+		protoDirPath,
+		"--until",
+		"HEAD",
+	)
+}
+
+func TestBreakingCommentIgnores(t *testing.T) {
+	t.Parallel()
+	tempDir := t.TempDir()
+	previousDirPath := filepath.Join(tempDir, "previous")
+	currentDirPath := filepath.Join(tempDir, "current")
+	require.NoError(t, os.MkdirAll(previousDirPath, 0755))
+	require.NoError(t, os.MkdirAll(currentDirPath, 0755))
+	require.NoError(
+		t,
+		os.WriteFile(
+			filepath.Join(previousDirPath, "a.proto"),
+			[]byte(`syntax = "proto3";
+package a;
+message Foo {
+  string one = 1;
+  string two = 2;
+}
+`),
+			0600,
+		),
+	)
+	writeCurrent := func(comment string) {
+		require.NoError(
+			t,
+			os.WriteFile(
+				filepath.Join(currentDirPath, "a.proto"),
+				[]byte(`syntax = "proto3";
+package a;
+`+comment+`
+message Foo {
+  string one = 1;
+}
+`),
+				0600,
+			),
+		)
+	}
+	config := `{"version":"v2","breaking":{"use":["FIELD_NO_DELETE"],"allow_comment_ignores":true,"require_comment_ignore_reasons":true}}`
+	writeCurrent("// buf:breaking:ignore FIELD_NO_DELETE two is no longer used")
+	testRunStdout(
+		t,
+		nil,
+		0,
+		filepath.FromSlash(tempDir+`/current/a.proto:4:1:acknowledged: Previously present field "2" with name "two" on message "Foo" was deleted. (reason: two is no longer used)`),
+		"breaking",
+		currentDirPath,
+		"--against",
+		previousDirPath,
+		"--config",
+		config,
+	)
+	// Acknowledged breaking changes never fail.
+	testRunStderrContainsNoWarn(
+		t,
+		nil,
+		1,
+		[]string{`--fail-on: unknown severity: "acknowledged"`},
+		"breaking",
+		currentDirPath,
+		"--against",
+		previousDirPath,
+		"--config",
+		config,
+		"--fail-on",
+		"acknowledged",
+	)
+	// The comment ignore is for another rule that FIELD_NO_DELETE is a prefix of.
+	writeCurrent("// buf:breaking:ignore FIELD_NO_DELETE_UNLESS_NUMBER_RESERVED two is no longer used")
+	testRunStdout(
+		t,
+		nil,
+		bufctl.ExitCodeFileAnnotation,
+		filepath.FromSlash(tempDir+`/current/a.proto:4:1:Previously present field "2" with name "two" on message "Foo" was deleted.`),
+		"breaking",
+		currentDirPath,
+		"--against",
+		previousDirPath,
+		"--config",
+		config,
+	)
+	writeCurrent("// buf:breaking:ignore FIELD_NO_DELETE")
+	testRunStdout(
+		t,
+		nil,
+		bufctl.ExitCodeFileAnnotation,
+		filepath.FromSlash(tempDir+`/current/a.proto:4:1:Previously present field "2" with name "two" on message "Foo" was deleted. Comment ignores for FIELD_NO_DELETE must have a reason.`),
+		"breaking",
+		currentDirPath,
+		"--against",
+		previousDirPath,
+		"--config",
+		config,
+	)
+	// Comment ignores are not allowed by default.
+	writeCurrent("// buf:breaking:ignore FIELD_NO_DELETE two is no longer used")
+	testRunStdout(
+		t,
+		nil,
+		bufctl.ExitCodeFileAnnotation,
+		filepath.FromSlash(tempDir+`/current/a.proto:4:1:Previously present field "2" with name "two" on message "Foo" was deleted.`),
+		"breaking",
+		currentDirPath,
+		"--against",
+		previousDirPath,
+		"--config",
+		`{"version":"v2","breaking":{"use":["FIELD_NO_DELETE"]}}`,
+	)
+}
+
+func TestExtends(t *testing.T) {
+	t.Parallel()
+	tempDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(tempDir, "config"), 0755))
+	require.NoError(
+		t,
+		os.WriteFile(
+			filepath.Join(tempDir, "config", "buf.shared.yaml"),
+			[]byte(`version: v2
+lint:
+  use:
+    - STANDARD
+  except:
+    - PACKAGE_DIRECTORY_MATCH
+    - PACKAGE_VERSION_SUFFIX
+`),
+			0600,
+		),
+	)
+	require.NoError(
+		t,
+		os.WriteFile(
+			filepath.Join(tempDir, "buf.yaml"),
+			[]byte(`version: v2
+extends:
+  - config/buf.shared.yaml
+breaking:
+  use:
+    - FILE
+`),
+			0600,
+		),
+	)
+	require.NoError(
+		t,
+		os.WriteFile(
+			filepath.Join(tempDir, "a.proto"),
+			[]byte(`syntax = "proto3";
+package a;
+message foo {}
+`),
+			0600,
+		),
+	)
+	testRunStdout(
+		t,
+		nil,
+		bufctl.ExitCodeFileAnnotation,
+		filepath.FromSlash(tempDir+`/a.proto:3:9:Message name "foo" should be PascalCase, such as "Foo".`),
+		"lint",
+		tempDir,
+	)
+	testRunStdout(
+		t,
+		nil,
+		0,
+		`version: v2
+extends:
+  - config/buf.shared.yaml
+breaking:
+  use:
+    - FILE`,
+		"config",
+		"show",
+		tempDir,
+	)
+	testRunStdout(
+		t,
+		nil,
+		0,
+		`version: v2
+lint:
+  use:
+    - STANDARD
+  except:
+    - PACKAGE_DIRECTORY_MATCH
+    - PACKAGE_VERSION_SUFFIX
+breaking:
+  use:
+    - FILE`,
+		"config",
+		"show",
+		tempDir,
+		"--resolved",
+	)
+}
+
+func TestReplace(t *testing.T) {
+	t.Parallel()
+	tempDir := t.TempDir()
+	for path, data := range map[string]string{
+		"shared/buf.yaml": `version: v2
+modules:
+  - path: proto
+    name: buf.build/acme/shared
+`,
+		"shared/proto/acme/shared/v1/shared.proto": `syntax = "proto3";
+package acme.shared.v1;
+message Shared {}
+`,
+		"consumer/buf.yaml": `version: v2
+modules:
+  - path: proto
+    name: buf.build/acme/consumer
+deps:
+  - buf.build/acme/shared
+replace:
+  - dep: buf.build/acme/shared
+    path: ../shared
+`,
+		"consumer/proto/acme/consumer/v1/consumer.proto": `syntax = "proto3";
+package acme.consumer.v1;
+import "acme/shared/v1/shared.proto";
+message Consumer {
+  acme.shared.v1.Shared shared = 1;
+}
+`,
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(tempDir, path)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(tempDir, path), []byte(data), 0600))
+	}
+	testRunStdout(
+		t,
+		nil,
+		0,
+		``,
+		"build",
+		filepath.Join(tempDir, "consumer"),
+	)
+	testRunStdout(
+		t,
+		nil,
+		0,
+		filepath.FromSlash(tempDir+"/consumer/proto/acme/consumer/v1/consumer.proto"),
+		"ls-files",
+		filepath.Join(tempDir, "consumer"),
+	)
+	testRunStderrContainsNoWarn(
+		t,
+		nil,
+		1,
+		[]string{
+			"cannot push a workspace with replaced dependencies",
+			"buf.build/acme/shared",
+		},
+		"push",
+		filepath.Join(tempDir, "consumer"),
+	)
+}
+
+func TestPushDryRun(t *testing.T) {
+	t.Parallel()
+	tempDir := t.TempDir()
+	for path, data := range map[string]string{
+		"buf.yaml": `version: v2
+`,
+		"a/v1/a.proto": `syntax = "proto3";
+package a.v1;
+message A {}
+`,
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(tempDir, path)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(tempDir, path), []byte(data), 0600))
+	}
+	testRunStderrContainsNoWarn(
+		t,
+		nil,
+		1,
+		[]string{
+			"--diff requires --dry-run",
+		},
+		"push",
+		tempDir,
+		"--diff",
+	)
+	testRunStderrContainsNoWarn(
+		t,
+		nil,
+		1,
+		[]string{
+			"a name must be specified in buf.yaml to push module",
+		},
+		"push",
+		tempDir,
+		"--dry-run",
+	)
+	// Nothing would be pushed, so the registry is not called.
+	testRunStdout(
+		t,
+		nil,
+		0,
+		``,
+		"push",
+		tempDir,
+		"--dry-run",
+		"--exclude-unnamed",
+	)
+}
+
+func TestPushDryRunRegistry(t *testing.T) {
+	t.Parallel()
+	registry := newTestRegistry(t)
+	envFunc := registry.NewEnvFunc(t, nil)
+	tempDir := t.TempDir()
+	writeTestFiles(
+		t,
+		tempDir,
+		map[string]string{
+			"buf.yaml": `version: v2
+modules:
+  - path: dep
+    name: ` + registry.Host + `/acme/dep
+  - path: other
+    name: ` + registry.Host + `/acme/other
+  - path: app
+    name: ` + registry.Host + `/acme/app
+`,
+			"dep/dep/v1/dep.proto": `syntax = "proto3";
+package dep.v1;
+message Dep {}
+`,
+			"other/other/v1/other.proto": `syntax = "proto3";
+package other.v1;
+message Other {}
+`,
+			"app/app/v1/a.proto": `syntax = "proto3";
+package app.v1;
+import "dep/v1/dep.proto";
+import "other/v1/other.proto";
+message A {
+  dep.v1.Dep dep = 1;
+  other.v1.Other other = 2;
+}
+`,
+			"app/app/v1/c.proto": `syntax = "proto3";
+package app.v1;
+message C {}
+`,
+		},
+	)
+	// Nothing has been pushed yet.
+	stdout, _ := testRunRegistry(t, envFunc, 0, "push", tempDir, "--dry-run")
+	assert.Contains(t, stdout, registry.Host+"/acme/app: new commit, no current commit on the default label\n")
+	assert.Contains(t, stdout, "    A app/v1/a.proto\n    A app/v1/c.proto\n")
+	assert.Contains(t, stdout, "    + "+registry.Host+"/acme/dep:<new commit>\n")
+	testRunRegistry(t, envFunc, 0, "push", tempDir, "--create")
+	getCommitID := func(name string) string {
+		return registry.GetLabelCommitID(t, "acme", name, "main")
+	}
+	depCommitID := getCommitID("dep")
+	otherCommitID := getCommitID("other")
+	appCommitID := getCommitID("app")
+
+	// The b5 digests of the workspace match the current commits.
+	stdout, _ = testRunRegistry(t, envFunc, 0, "push", tempDir, "--dry-run")
+	assert.Equal(
+		t,
+		registry.Host+"/acme/app: no changes, current commit "+appCommitID+" on the default label\n"+
+			registry.Host+"/acme/dep: no changes, current commit "+depCommitID+" on the default label\n"+
+			registry.Host+"/acme/other: no changes, current commit "+otherCommitID+" on the default label\n",
+		stdout,
+	)
+	stdout, _ = testRunRegistry(t, envFunc, 0, "push", tempDir, "--dry-run", "--label", "v2")
+	assert.Contains(t, stdout, registry.Host+`/acme/app: new commit, no current commit on label "v2"`+"\n")
+
+	// Change dep, add a new module, stop importing other, and add, delete and modify files of app.
+	require.NoError(t, os.Remove(filepath.Join(tempDir, "app", "app", "v1", "c.proto")))
+	writeTestFiles(
 		t,
-		func() {
-			appcmdtesting.RunCommandExitCodeStderrContains(
-				t,
-				func(use string) *appcmd.Command { return NewRootCommand(use) },
-				1,
-				[]string{
-					`panic: this panic is intentional`,
-					`Failure: plugin "buf-plugin-panic" failed: Exited with code 2: exit status 2`,
-				},
-				internaltesting.NewEnvFunc(t),
-				nil,
-				"lint",
-				filepath.Join("testdata", "check_plugins", "current"),
-				"--config",
-				`{
-					"version":"v2",
-					"modules": [
-						{"path": "testdata/check_plugins/current/proto"},
-						{"path": "testdata/check_plugins/current/vendor/protovalidate"}
-					],
-					"lint": {
-						"use": ["LINT_PANIC"]
-					},
-					"plugins":[
-						{"plugin": "buf-plugin-panic"}
-					]
-				}`,
-			)
+		tempDir,
+		map[string]string{
+			"buf.yaml": `version: v2
+modules:
+  - path: dep
+    name: ` + registry.Host + `/acme/dep
+  - path: other
+    name: ` + registry.Host + `/acme/other
+  - path: extra
+    name: ` + registry.Host + `/acme/extra
+  - path: app
+    name: ` + registry.Host + `/acme/app
+`,
+			"dep/dep/v1/dep.proto": `syntax = "proto3";
+package dep.v1;
+message Dep {
+  string id = 1;
+}
+`,
+			"extra/extra/v1/extra.proto": `syntax = "proto3";
+package extra.v1;
+message Extra {}
+`,
+			"app/app/v1/a.proto": `syntax = "proto3";
+package app.v1;
+import "dep/v1/dep.proto";
+import "extra/v1/extra.proto";
+message A {
+  dep.v1.Dep dep = 1;
+  extra.v1.Extra extra = 2;
+}
+`,
+			"app/app/v1/b.proto": `syntax = "proto3";
+package app.v1;
+message B {}
+`,
 		},
 	)
+	stdout, _ = testRunRegistry(t, envFunc, 0, "push", tempDir, "--dry-run")
+	assert.Contains(t, stdout, registry.Host+"/acme/dep: new commit, current commit "+depCommitID+" on the default label\n  files:\n    M dep/v1/dep.proto\n")
+	assert.Contains(t, stdout, registry.Host+"/acme/other: no changes, current commit "+otherCommitID+" on the default label\n")
+	assert.Contains(t, stdout, registry.Host+"/acme/extra: new commit, no current commit on the default label\n")
+	assert.Contains(
+		t,
+		stdout,
+		registry.Host+"/acme/app: new commit, current commit "+appCommitID+" on the default label\n"+
+			"  files:\n"+
+			"    M app/v1/a.proto\n"+
+			"    A app/v1/b.proto\n"+
+			"    D app/v1/c.proto\n"+
+			"  deps:\n"+
+			"    ~ "+registry.Host+"/acme/dep:"+depCommitID+" -> <new commit>\n"+
+			"    + "+registry.Host+"/acme/extra:<new commit>\n"+
+			"    - "+registry.Host+"/acme/other:"+otherCommitID+"\n",
+	)
+	assert.NotContains(t, stdout, "@@")
+	stdout, _ = testRunRegistry(t, envFunc, 0, "push", tempDir, "--dry-run", "--diff")
+	assert.Contains(t, stdout, "+message B {}")
+	assert.Contains(t, stdout, "-message C {}")
+	assert.Contains(t, stdout, "+  string id = 1;")
+	// A dry run does not push anything.
+	assert.Equal(t, appCommitID, getCommitID("app"))
 }
 
-func TestBreakingWithPaths(t *testing.T) {
+func TestPushSigningKeyDepUpdate(t *testing.T) {
 	t.Parallel()
+	registry := newTestRegistry(t)
+	remoteCacheURL := newTestRemoteCacheServer(t)
+	privateKey, err := bufmodulesign.NewPrivateKey()
+	require.NoError(t, err)
+	privateKeyPEM, err := bufmodulesign.MarshalPrivateKeyPEM(privateKey)
+	require.NoError(t, err)
+	otherPrivateKey, err := bufmodulesign.NewPrivateKey()
+	require.NoError(t, err)
 	tempDir := t.TempDir()
-	testRunStdout(t, nil, 0, ``, "build", filepath.Join("command", "generate", "testdata", "paths"), "-o", filepath.Join(tempDir, "previous.binpb"))
-	testRunStdout(t, nil, 0, ``, "build", filepath.Join("testdata", "paths"), "-o", filepath.Join(tempDir, "current.binpb"))
-	readWriteBucket, err := storageos.NewProvider().NewReadWriteBucket(tempDir)
+	writeTestFiles(
+		t,
+		tempDir,
+		map[string]string{
+			"key.pem": string(privateKeyPEM),
+			"dep/buf.yaml": `version: v2
+name: ` + registry.Host + `/acme/dep
+`,
+			"dep/dep/v1/dep.proto": `syntax = "proto3";
+package dep.v1;
+message Dep {}
+`,
+		},
+	)
+	writeTestAppFiles := func(dirPath string, publicKey bufmodulesign.PublicKey) {
+		writeTestFiles(
+			t,
+			filepath.Join(tempDir, dirPath),
+			map[string]string{
+				"buf.yaml": `version: v2
+deps:
+  - ` + registry.Host + `/acme/dep
+verify:
+  - owner: ` + registry.Host + `/acme
+    keys:
+      - ` + publicKey.String() + `
+`,
+				"app/v1/app.proto": `syntax = "proto3";
+package app.v1;
+import "dep/v1/dep.proto";
+message App {
+  dep.v1.Dep dep = 1;
+}
+`,
+			},
+		)
+	}
+	writeTestAppFiles("app", privateKey.PublicKey())
+	writeTestAppFiles("other_key_app", otherPrivateKey.PublicKey())
+	remoteCacheEnv := map[string]string{"BUF_REMOTE_CACHE_URL": remoteCacheURL}
+
+	// The pusher shares the signature through the remote cache.
+	testRunRegistry(
+		t,
+		registry.NewEnvFunc(t, remoteCacheEnv),
+		0,
+		"push",
+		filepath.Join(tempDir, "dep"),
+		"--create",
+		"--signing-key",
+		filepath.Join(tempDir, "key.pem"),
+	)
+	// The dependent has its own cache directory, and reads the signature from the remote cache.
+	testRunRegistry(
+		t,
+		registry.NewEnvFunc(t, remoteCacheEnv),
+		0,
+		"dep",
+		"update",
+		filepath.Join(tempDir, "app"),
+	)
+	_, err = os.Stat(filepath.Join(tempDir, "app", "buf.lock"))
 	require.NoError(t, err)
-	storagetesting.AssertPaths(
+	_, stderr := testRunRegistry(
 		t,
-		readWriteBucket,
-		"",
-		"previous.binpb",
-		"current.binpb",
+		registry.NewEnvFunc(t, remoteCacheEnv),
+		1,
+		"dep",
+		"update",
+		filepath.Join(tempDir, "other_key_app"),
 	)
-	testRunStdoutStderrNoWarn(
+	assert.Contains(t, stderr, "module "+registry.Host+"/acme/dep could not be verified")
+	// Signatures are not distributed by the registry.
+	_, stderr = testRunRegistry(
+		t,
+		registry.NewEnvFunc(t, nil),
+		1,
+		"dep",
+		"update",
+		filepath.Join(tempDir, "app"),
+	)
+	assert.Contains(t, stderr, "module "+registry.Host+"/acme/dep is not signed")
+}
+
+func TestDepWhy(t *testing.T) {
+	t.Parallel()
+	tempDir := t.TempDir()
+	for path, data := range map[string]string{
+		"c/c/c.proto": `syntax = "proto3";
+package c;
+message C {}
+`,
+		"ws/buf.yaml": `version: v2
+modules:
+  - path: a
+    name: buf.build/acme/a
+  - path: b
+    name: buf.build/acme/b
+deps:
+  - buf.build/acme/c
+replace:
+  - dep: buf.build/acme/c
+    path: ../c
+`,
+		"ws/a/a/a.proto": `syntax = "proto3";
+package a;
+import "b/b.proto";
+message A {
+  b.B b = 1;
+}
+`,
+		"ws/b/b/b.proto": `syntax = "proto3";
+package b;
+import "c/c.proto";
+message B {
+  c.C c = 1;
+}
+`,
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(tempDir, path)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(tempDir, path), []byte(data), 0600))
+	}
+	testRunStdout(
 		t,
 		nil,
-		bufctl.ExitCodeFileAnnotation,
-		`a/v3/a.proto:6:3:Field "1" with name "key" on message "Foo" changed type from "string" to "int32".
-a/v3/a.proto:7:3:Field "2" with name "Value" on message "Foo" changed option "json_name" from "value" to "Value".
-a/v3/a.proto:7:10:Field "2" on message "Foo" changed name from "value" to "Value".`,
-		"",
-		"breaking",
-		filepath.Join(tempDir, "current.binpb"),
-		"--against",
-		filepath.Join(tempDir, "previous.binpb"),
-		"--path",
-		filepath.Join("a", "v3"),
-		"--exclude-path",
-		filepath.Join("a", "v3", "foo"),
+		0,
+		`Module chains:
+  buf.build/acme/a -> buf.build/acme/b -> buf.build/acme/c
+  buf.build/acme/b -> buf.build/acme/c
+
+Import chains:
+  a/a.proto (buf.build/acme/a) -> b/b.proto (buf.build/acme/b) -> c/c.proto (buf.build/acme/c)
+  b/b.proto (buf.build/acme/b) -> c/c.proto (buf.build/acme/c)`,
+		"dep",
+		"why",
+		"buf.build/acme/c",
+		filepath.Join(tempDir, "ws"),
 	)
-	testRunStdoutStderrNoWarn(
+	testRunStdout(
 		t,
 		nil,
-		bufctl.ExitCodeFileAnnotation,
-		`a/v3/a.proto:6:3:Field "1" with name "key" on message "Foo" changed type from "string" to "int32". See https://developers.google.com/protocol-buffers/docs/proto3#updating for wire compatibility rules.`,
-		"",
-		"breaking",
-		filepath.Join(tempDir, "current.binpb"),
-		"--against",
-		filepath.Join(tempDir, "previous.binpb"),
-		"--path",
-		filepath.Join("a", "v3"),
-		"--exclude-path",
-		filepath.Join("a", "v3", "foo"),
-		"--config",
-		`{"version":"v2","breaking":{"use":["WIRE"]}}`,
+		0,
+		`{"module":"buf.build/acme/c","module_chains":[["buf.build/acme/a","buf.build/acme/b","buf.build/acme/c"],["buf.build/acme/b","buf.build/acme/c"]],"import_chains":[[{"path":"a/a.proto","module":"buf.build/acme/a"},{"path":"b/b.proto","module":"buf.build/acme/b"},{"path":"c/c.proto","module":"buf.build/acme/c"}],[{"path":"b/b.proto","module":"buf.build/acme/b"},{"path":"c/c.proto","module":"buf.build/acme/c"}]]}`,
+		"dep",
+		"why",
+		"buf.build/acme/c",
+		filepath.Join(tempDir, "ws"),
+		"--format",
+		"json",
+	)
+	testRunStderrContainsNoWarn(
+		t,
+		nil,
+		1,
+		[]string{"buf.build/acme/b is a module in the workspace"},
+		"dep",
+		"why",
+		"buf.build/acme/b",
+		filepath.Join(tempDir, "ws"),
+	)
+	// There is no buf.lock, so there are no pinned dependencies to list.
+	testRunStdout(
+		t,
+		nil,
+		0,
+		``,
+		"dep",
+		"outdated",
+		filepath.Join(tempDir, "ws"),
+	)
+}
+
+func TestDepOutdatedRegistry(t *testing.T) {
+	t.Parallel()
+	registry := newTestRegistry(t)
+	envFunc := registry.NewEnvFunc(t, nil)
+	tempDir := t.TempDir()
+	bsrDirPath := filepath.Join(tempDir, "bsr")
+	writeTestFiles(
+		t,
+		bsrDirPath,
+		map[string]string{
+			"buf.yaml": `version: v2
+modules:
+  - path: dep
+    name: ` + registry.Host + `/acme/dep
+  - path: other
+    name: ` + registry.Host + `/acme/other
+  - path: third
+    name: ` + registry.Host + `/acme/third
+`,
+			"dep/dep.proto":     "syntax = \"proto3\";\npackage dep;\nmessage Dep {}\n",
+			"other/other.proto": "syntax = \"proto3\";\npackage other;\nmessage Other {}\n",
+			"third/third.proto": "syntax = \"proto3\";\npackage third;\nmessage Third {}\n",
+		},
+	)
+	testRunRegistry(t, envFunc, 0, "push", bsrDirPath, "--create", "--label", "main", "--label", "stable")
+	pinnedDepCommitID := registry.GetLabelCommitID(t, "acme", "dep", "stable")
+	pinnedOtherCommitID := registry.GetLabelCommitID(t, "acme", "other", "main")
+	pinnedThirdCommitID := registry.GetLabelCommitID(t, "acme", "third", "main")
+
+	// The dependencies are configured with a label, a commit and no ref.
+	appDirPath := filepath.Join(tempDir, "app")
+	writeTestFiles(
+		t,
+		appDirPath,
+		map[string]string{
+			"buf.yaml": `version: v2
+deps:
+  - ` + registry.Host + `/acme/dep:stable
+  - ` + registry.Host + `/acme/other:` + pinnedOtherCommitID + `
+  - ` + registry.Host + `/acme/third
+`,
+			"app.proto": `syntax = "proto3";
+package app;
+import "dep.proto";
+import "other.proto";
+import "third.proto";
+`,
+		},
+	)
+	testRunRegistry(t, envFunc, 0, "dep", "update", appDirPath)
+
+	// Push new commits for all modules, and deprecate one of them.
+	writeTestFiles(
+		t,
+		bsrDirPath,
+		map[string]string{
+			"dep/dep.proto":     "syntax = \"proto3\";\npackage dep;\nmessage Dep { string id = 1; }\n",
+			"other/other.proto": "syntax = \"proto3\";\npackage other;\nmessage Other { string id = 1; }\n",
+			"third/third.proto": "syntax = \"proto3\";\npackage third;\nmessage Third { string id = 1; }\n",
+		},
+	)
+	testRunRegistry(t, envFunc, 0, "push", bsrDirPath, "--label", "main", "--label", "stable")
+	latestDepCommitID := registry.GetLabelCommitID(t, "acme", "dep", "stable")
+	latestThirdCommitID := registry.GetLabelCommitID(t, "acme", "third", "main")
+	require.NotEqual(t, pinnedDepCommitID, latestDepCommitID)
+	require.NotEqual(t, pinnedOtherCommitID, registry.GetLabelCommitID(t, "acme", "other", "main"))
+	require.NotEqual(t, pinnedThirdCommitID, latestThirdCommitID)
+	registry.Deprecate(t, "acme", "third")
+
+	stdout, _ := testRunRegistry(t, envFunc, 0, "dep", "outdated", appDirPath, "--format", "json")
+	var outdatedDeps []map[string]any
+	decoder := json.NewDecoder(strings.NewReader(stdout))
+	for decoder.More() {
+		var outdatedDep map[string]any
+		require.NoError(t, decoder.Decode(&outdatedDep))
+		outdatedDeps = append(outdatedDeps, outdatedDep)
+	}
+	assert.Equal(
+		t,
+		[]map[string]any{
+			{
+				"name":       registry.Host + "/acme/dep",
+				"pinned":     pinnedDepCommitID,
+				"label":      "stable",
+				"latest":     latestDepCommitID,
+				"outdated":   true,
+				"direct":     true,
+				"deprecated": false,
+			},
+			{
+				// A dependency configured with a commit has no label, and stays on its commit.
+				"name":       registry.Host + "/acme/other",
+				"pinned":     pinnedOtherCommitID,
+				"latest":     pinnedOtherCommitID,
+				"outdated":   false,
+				"direct":     true,
+				"deprecated": false,
+			},
+			{
+				// A dependency configured without a ref uses the default label.
+				"name":       registry.Host + "/acme/third",
+				"pinned":     pinnedThirdCommitID,
+				"label":      "main",
+				"latest":     latestThirdCommitID,
+				"outdated":   true,
+				"direct":     true,
+				"deprecated": true,
+			},
+		},
+		outdatedDeps,
+	)
+	stdout, _ = testRunRegistry(t, envFunc, 0, "dep", "outdated", appDirPath)
+	assert.Equal(
+		t,
+		[][]string{
+			{"Name", "Pinned", "Label", "Latest", "Outdated", "Direct", "Deprecated"},
+			{registry.Host + "/acme/dep", pinnedDepCommitID, "stable", latestDepCommitID, "true", "true", "false"},
+			{registry.Host + "/acme/other", pinnedOtherCommitID, pinnedOtherCommitID, "false", "true", "false"},
+			{registry.Host + "/acme/third", pinnedThirdCommitID, "main", latestThirdCommitID, "true", "true", "true"},
+		},
+		slicesext.Map(
+			strings.Split(strings.TrimSpace(stdout), "\n"),
+			strings.Fields,
+		),
 	)
 }
 
@@ -4178,6 +5462,84 @@ func TestFormatInvalidInputDoesNotCreateDirectory(t *testing.T) {
 	assert.True(t, os.IsNotExist(err))
 }
 
+func TestMigrateEditions(t *testing.T) {
+	t.Parallel()
+	tempDir := t.TempDir()
+	filePath := filepath.Join(tempDir, "a.proto")
+	require.NoError(
+		t,
+		os.WriteFile(
+			filePath,
+			[]byte(`syntax = "proto3";
+
+package a;
+
+message Foo {
+  string a = 1;
+  optional string b = 2;
+  repeated int32 c = 3 [packed = false];
+}
+`),
+			0600,
+		),
+	)
+	stdout := bytes.NewBuffer(nil)
+	testRun(
+		t,
+		0,
+		nil,
+		stdout,
+		"beta",
+		"migrate-editions",
+		tempDir,
+		"-d",
+	)
+	assert.Contains(
+		t,
+		stdout.String(),
+		`
+-syntax = "proto3";
++edition = "2023";
+`,
+	)
+	testRunStdout(
+		t,
+		nil,
+		0,
+		``,
+		"beta",
+		"migrate-editions",
+		tempDir,
+	)
+	data, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		`edition = "2023";
+
+package a;
+
+message Foo {
+  string a = 1 [features.field_presence = IMPLICIT];
+  string b = 2;
+  repeated int32 c = 3 [features.repeated_field_encoding = EXPANDED];
+}
+`,
+		string(data),
+	)
+	// Files that already use editions are not migrated.
+	testRunStdout(
+		t,
+		nil,
+		0,
+		``,
+		"beta",
+		"migrate-editions",
+		tempDir,
+		"-d",
+	)
+}
+
 func TestConvertRoundTrip(t *testing.T) {
 	t.Parallel()
 	tempDir := t.TempDir()
@@ -4345,6 +5707,97 @@ func TestLintDisabledForModuleInWorkspace(t *testing.T) {
 	)
 }
 
+func TestFieldNumbers(t *testing.T) {
+	t.Parallel()
+	testRunStdout(
+		t,
+		nil,
+		0,
+		`
+Name    Kind     Used       Reserved  Extensions  Free                               Next
+a.Foo   message  1 to 2, 4  6 to 8    -           3, 5, 9 to 18999, 20000 to max     9
+a.Bar   enum     0 to 1     3         -           2, 4 to max                        4
+b.Baz   message  1          -         10 to 19    2 to 9, 20 to 18999, 20000 to max  20
+b.Full  enum     -1         0 to max  -           -                                  none
+`,
+		"beta",
+		"field-numbers",
+		filepath.Join("testdata", "fieldnumbers"),
+	)
+	testRunStdout(
+		t,
+		nil,
+		0,
+		`9`,
+		"beta",
+		"field-numbers",
+		filepath.Join("testdata", "fieldnumbers"),
+		"--next",
+		"a.Foo",
+	)
+	// The next number is greater than every extension number.
+	testRunStdout(
+		t,
+		nil,
+		0,
+		`20`,
+		"beta",
+		"field-numbers",
+		filepath.Join("testdata", "fieldnumbers"),
+		"--next",
+		"b.Baz",
+	)
+	testRunStdout(
+		t,
+		nil,
+		0,
+		`{"name":"a.Bar","kind":"enum","path":"a.proto","used":[0,1],"reserved":[{"start":3,"end":3}],"free":[{"start":2,"end":2},{"start":4,"end":2147483647}],"next":4}`,
+		"beta",
+		"field-numbers",
+		filepath.Join("testdata", "fieldnumbers"),
+		"--next",
+		"a.Bar",
+		"--format",
+		"json",
+	)
+	// An enum whose only value is negative still has no free numbers.
+	testRunStdout(
+		t,
+		nil,
+		0,
+		`{"name":"b.Full","kind":"enum","path":"b.proto","used":[-1],"reserved":[{"start":0,"end":2147483647}]}`,
+		"beta",
+		"field-numbers",
+		filepath.Join("testdata", "fieldnumbers"),
+		"--next",
+		"b.Full",
+		"--format",
+		"json",
+	)
+	testRunStderr(
+		t,
+		nil,
+		1,
+		`Failure: enum "b.Full" has no free numbers`,
+		"beta",
+		"field-numbers",
+		filepath.Join("testdata", "fieldnumbers"),
+		"--next",
+		"b.Full",
+	)
+	testRunStderr(
+		t,
+		nil,
+		1,
+		`Failure: message or enum "a.Baz" not found in input`,
+		"beta",
+		"field-numbers",
+		filepath.Join("testdata", "fieldnumbers"),
+		"--next",
+		"a.Baz",
+	)
+}
+
 // testBuildLsFilesFormatImport does effectively an ls-files, but via doing a build of an Image, and then
 // listing the files from the image as if --format=import was set.
 func testBuildLsFilesFormatImport(t *testing.T, expectedExitCode int, expectedFiles []string, buildArgs ...string) {
@@ -0,0 +1,144 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protoc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/protoencoding"
+	"github.com/bufbuild/buf/private/pkg/syserror"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// encode reads a message of the given type in the given message format from stdin,
+// and writes it in binary to stdout.
+func encode(container app.StdioContainer, image bufimage.Image, typeName string, messageFormat string) error {
+	message, err := newMessage(image, typeName)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(container.Stdin())
+	if err != nil {
+		return err
+	}
+	unmarshaler, err := newMessageFormatUnmarshaler(image.Resolver(), messageFormat)
+	if err != nil {
+		return err
+	}
+	if err := unmarshaler.Unmarshal(data, message); err != nil {
+		return fmt.Errorf("failed to parse input: %w", err)
+	}
+	data, err = protoencoding.NewWireMarshaler().Marshal(message)
+	if err != nil {
+		return err
+	}
+	_, err = container.Stdout().Write(data)
+	return err
+}
+
+// decode reads a binary message of the given type from stdin, and writes it in the given
+// message format to stdout.
+func decode(container app.StdioContainer, image bufimage.Image, typeName string, messageFormat string) error {
+	message, err := newMessage(image, typeName)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(container.Stdin())
+	if err != nil {
+		return err
+	}
+	if err := protoencoding.NewWireUnmarshaler(image.Resolver()).Unmarshal(data, message); err != nil {
+		return fmt.Errorf("failed to parse input: %w", err)
+	}
+	marshaler, err := newMessageFormatMarshaler(image.Resolver(), messageFormat)
+	if err != nil {
+		return err
+	}
+	data, err = marshaler.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return writeWithTrailingNewline(container.Stdout(), data)
+}
+
+// decodeRaw reads an arbitrary binary message from stdin, and writes the raw tag/value
+// pairs to stdout in the same format as protoc.
+func decodeRaw(container app.StdioContainer) error {
+	data, err := io.ReadAll(container.Stdin())
+	if err != nil {
+		return err
+	}
+	// An empty message retains all fields as unknown fields.
+	message := &emptypb.Empty{}
+	if err := protoencoding.NewWireUnmarshaler(nil).Unmarshal(data, message); err != nil {
+		return fmt.Errorf("failed to parse input: %w", err)
+	}
+	data, err = protoencoding.NewRawMarshaler().Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to parse input: %w", err)
+	}
+	_, err = container.Stdout().Write(data)
+	return err
+}
+
+func newMessage(image bufimage.Image, typeName string) (proto.Message, error) {
+	messageType, err := image.Resolver().FindMessageByName(protoreflect.FullName(typeName))
+	if err != nil {
+		return nil, newTypeNotDefinedError(typeName)
+	}
+	return messageType.New().Interface(), nil
+}
+
+func newMessageFormatMarshaler(resolver protoencoding.Resolver, messageFormat string) (protoencoding.Marshaler, error) {
+	switch messageFormat {
+	case messageFormatTxtpb:
+		return protoencoding.NewTxtpbMarshaler(resolver), nil
+	case messageFormatJSON:
+		return protoencoding.NewJSONMarshaler(resolver, protoencoding.JSONMarshalerWithIndent()), nil
+	case messageFormatYAML:
+		return protoencoding.NewYAMLMarshaler(resolver, protoencoding.YAMLMarshalerWithIndent()), nil
+	default:
+		// This is validated when building the env.
+		return nil, syserror.Newf("unknown message format: %q", messageFormat)
+	}
+}
+
+func newMessageFormatUnmarshaler(resolver protoencoding.Resolver, messageFormat string) (protoencoding.Unmarshaler, error) {
+	switch messageFormat {
+	case messageFormatTxtpb:
+		return protoencoding.NewTxtpbUnmarshaler(resolver), nil
+	case messageFormatJSON:
+		return protoencoding.NewJSONUnmarshaler(resolver), nil
+	case messageFormatYAML:
+		return protoencoding.NewYAMLUnmarshaler(resolver), nil
+	default:
+		// This is validated when building the env.
+		return nil, syserror.Newf("unknown message format: %q", messageFormat)
+	}
+}
+
+func writeWithTrailingNewline(writer io.Writer, data []byte) error {
+	if len(data) > 0 && !bytes.HasSuffix(data, []byte("\n")) {
+		data = append(data, '\n')
+	}
+	_, err := writer.Write(data)
+	return err
+}
@@ -0,0 +1,147 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protoc
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/protoencoding"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// newImageForDescriptorSetIn builds an Image from the FileDescriptorSets at the
+// given paths.
+//
+// Files with the same name across FileDescriptorSets must be identical. The given
+// file paths are the files to treat as non-imports, either as the names of the files
+// within the FileDescriptorSets, or as paths relative to one of the include
+// directories. If no file paths are given, all files are treated as non-imports.
+func newImageForDescriptorSetIn(
+	descriptorSetInPaths []string,
+	includeDirPaths []string,
+	filePaths []string,
+) (bufimage.Image, error) {
+	var fileNames []string
+	fileNameToFileDescriptorProto := make(map[string]*descriptorpb.FileDescriptorProto)
+	for _, descriptorSetInPath := range descriptorSetInPaths {
+		data, err := os.ReadFile(descriptorSetInPath)
+		if err != nil {
+			return nil, err
+		}
+		fileDescriptorSet := &descriptorpb.FileDescriptorSet{}
+		if err := protoencoding.NewWireUnmarshaler(nil).Unmarshal(data, fileDescriptorSet); err != nil {
+			return nil, fmt.Errorf("could not parse FileDescriptorSet %q: %w", descriptorSetInPath, err)
+		}
+		for _, fileDescriptorProto := range fileDescriptorSet.GetFile() {
+			fileName := fileDescriptorProto.GetName()
+			existing, ok := fileNameToFileDescriptorProto[fileName]
+			if !ok {
+				fileNames = append(fileNames, fileName)
+				fileNameToFileDescriptorProto[fileName] = fileDescriptorProto
+				continue
+			}
+			if !proto.Equal(existing, fileDescriptorProto) {
+				return nil, fmt.Errorf("%s: file appears multiple times in --%s with different contents", fileName, descriptorSetInFlagName)
+			}
+		}
+	}
+	sortedFileDescriptorProtos, err := sortFileDescriptorProtos(fileNames, fileNameToFileDescriptorProto)
+	if err != nil {
+		return nil, err
+	}
+	fileToGenerate := make([]string, 0, len(filePaths))
+	for _, filePath := range filePaths {
+		fileName, err := getDescriptorSetInFileName(filePath, includeDirPaths, fileNameToFileDescriptorProto)
+		if err != nil {
+			return nil, err
+		}
+		fileToGenerate = append(fileToGenerate, fileName)
+	}
+	if len(fileToGenerate) == 0 {
+		fileToGenerate = fileNames
+	}
+	return bufimage.NewImageForCodeGeneratorRequest(
+		&pluginpb.CodeGeneratorRequest{
+			FileToGenerate: fileToGenerate,
+			ProtoFile:      sortedFileDescriptorProtos,
+		},
+	)
+}
+
+// sortFileDescriptorProtos sorts the FileDescriptorProtos so that every file
+// appears after all of its dependencies, preserving the given order otherwise.
+func sortFileDescriptorProtos(
+	fileNames []string,
+	fileNameToFileDescriptorProto map[string]*descriptorpb.FileDescriptorProto,
+) ([]*descriptorpb.FileDescriptorProto, error) {
+	sorted := make([]*descriptorpb.FileDescriptorProto, 0, len(fileNames))
+	added := make(map[string]struct{}, len(fileNames))
+	visiting := make(map[string]struct{})
+	var add func(string) error
+	add = func(fileName string) error {
+		if _, ok := added[fileName]; ok {
+			return nil
+		}
+		if _, ok := visiting[fileName]; ok {
+			return fmt.Errorf("%s: import cycle detected in --%s", fileName, descriptorSetInFlagName)
+		}
+		visiting[fileName] = struct{}{}
+		fileDescriptorProto := fileNameToFileDescriptorProto[fileName]
+		for _, dependency := range fileDescriptorProto.GetDependency() {
+			if _, ok := fileNameToFileDescriptorProto[dependency]; !ok {
+				return fmt.Errorf("%s: import %q was not found in --%s", fileName, dependency, descriptorSetInFlagName)
+			}
+			if err := add(dependency); err != nil {
+				return err
+			}
+		}
+		delete(visiting, fileName)
+		added[fileName] = struct{}{}
+		sorted = append(sorted, fileDescriptorProto)
+		return nil
+	}
+	for _, fileName := range fileNames {
+		if err := add(fileName); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
+
+func getDescriptorSetInFileName(
+	filePath string,
+	includeDirPaths []string,
+	fileNameToFileDescriptorProto map[string]*descriptorpb.FileDescriptorProto,
+) (string, error) {
+	fileName := normalpath.Normalize(filePath)
+	if _, ok := fileNameToFileDescriptorProto[fileName]; ok {
+		return fileName, nil
+	}
+	for _, includeDirPath := range includeDirPaths {
+		relFilePath, err := normalpath.Rel(normalpath.Normalize(includeDirPath), fileName)
+		if err != nil {
+			continue
+		}
+		if _, ok := fileNameToFileDescriptorProto[relFilePath]; ok {
+			return relFilePath, nil
+		}
+	}
+	return "", fmt.Errorf("%s: file not found in --%s", filePath, descriptorSetInFlagName)
+}
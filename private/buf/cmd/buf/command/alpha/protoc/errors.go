@@ -17,11 +17,15 @@ package protoc
 import (
 	"errors"
 	"fmt"
+
+	"github.com/bufbuild/buf/private/pkg/stringutil"
 )
 
 var (
-	errNoInputFiles = errors.New("no input files specified")
-	errArgEmpty     = errors.New("empty argument specified")
+	errNoInputFiles            = errors.New("no input files specified")
+	errArgEmpty                = errors.New("empty argument specified")
+	errMultipleCodecModes      = fmt.Errorf("only one of --%s, --%s, and --%s can be specified", encodeFlagName, decodeFlagName, decodeRawFlagName)
+	errDecodeRawWithInputFiles = fmt.Errorf("no input files should be given when using --%s", decodeRawFlagName)
 )
 
 func newCannotSpecifyOptWithoutOutError(pluginName string) error {
@@ -60,44 +64,14 @@ func newDuplicatePluginPathError(pluginName string) error {
 	return fmt.Errorf("duplicate --%s for protoc-gen-%s", pluginPathValuesFlagName, pluginName)
 }
 
-func newEncodeNotSupportedError() error {
-	return fmt.Errorf(
-		`--%s is not supported by buf.
-
-Buf only handles the binary and JSON formats for now, however we can support this flag if there is sufficient demand.
-Please email us at support@buf.build if this is a need for your organization.`,
-		encodeFlagName,
-	)
-}
-
-func newDecodeNotSupportedError() error {
-	return fmt.Errorf(
-		`--%s is not supported by buf.
-
-Buf only handles the binary and JSON formats for now, however we can support this flag if there is sufficient demand.
-Please email us at support@buf.build if this is a need for your organization.`,
-		decodeFlagName,
-	)
+func newMessageFormatInvalidError(messageFormat string) error {
+	return fmt.Errorf("--%s must be one of %s but was %q", messageFormatFlagName, stringutil.SliceToString(allMessageFormats), messageFormat)
 }
 
-func newDecodeRawNotSupportedError() error {
-	return fmt.Errorf(
-		`--%s is not supported by buf.
-
-Buf only handles the binary and JSON formats for now, however we can support this flag if there is sufficient demand.
-Please email us at support@buf.build if this is a need for your organization.`,
-		decodeRawFlagName,
-	)
+func newTypeNotDefinedError(typeName string) error {
+	return fmt.Errorf("type not defined: %s", typeName)
 }
 
-func newDescriptorSetInNotSupportedError() error {
-	return fmt.Errorf(
-		`--%s is not supported by buf.
-
-Buf will work with cross-repository imports Buf Schema Registry, which will be based on source files, not pre-built Images.
-We think this is a much safer option that leads to less errors and more consistent results.
-
-Please email us at support@buf.build if this is a need for your organization.`,
-		descriptorSetInFlagName,
-	)
+func newCannotUseCodecWithOutputError() error {
+	return fmt.Errorf("cannot use --%s or --%s and generate code or descriptors at the same time", encodeFlagName, decodeFlagName)
 }
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 
@@ -44,11 +45,23 @@ const (
 	decodeFlagName          = "decode"
 	decodeRawFlagName       = "decode_raw"
 	descriptorSetInFlagName = "descriptor_set_in"
+	messageFormatFlagName   = "message_format"
+
+	messageFormatTxtpb = "txtpb"
+	messageFormatJSON  = "json"
+	messageFormatYAML  = "yaml"
 )
 
 var (
 	defaultIncludeDirPaths = []string{"."}
 	defaultErrorFormat     = "gcc"
+	defaultMessageFormat   = messageFormatTxtpb
+
+	allMessageFormats = []string{
+		messageFormatTxtpb,
+		messageFormatJSON,
+		messageFormatYAML,
+	}
 )
 
 type flags struct {
@@ -59,6 +72,11 @@ type flags struct {
 	Output                string
 	ErrorFormat           string
 	ByDir                 bool
+	Encode                string
+	Decode                string
+	DecodeRaw             bool
+	DescriptorSetIn       []string
+	MessageFormat         string
 }
 
 type env struct {
@@ -74,11 +92,6 @@ type flagsBuilder struct {
 
 	PluginPathValues []string
 
-	Encode          string
-	Decode          string
-	DecodeRaw       bool
-	DescriptorSetIn []string
-
 	pluginFake        []string
 	pluginNameToValue map[string]*pluginValue
 }
@@ -167,30 +180,42 @@ func (f *flagsBuilder) Bind(flagSet *pflag.FlagSet) {
 		&f.Encode,
 		encodeFlagName,
 		"",
-		`Not supported by buf.`,
+		`Read a text format message of the given type from stdin and write it in binary to stdout.`,
 	)
-	_ = flagSet.MarkHidden(encodeFlagName)
 	flagSet.StringVar(
 		&f.Decode,
 		decodeFlagName,
 		"",
-		`Not supported by buf.`,
+		`Read a binary message of the given type from stdin and write it in text format to stdout.`,
 	)
-	_ = flagSet.MarkHidden(decodeFlagName)
 	flagSet.BoolVar(
 		&f.DecodeRaw,
 		decodeRawFlagName,
 		false,
-		`Not supported by buf.`,
+		`Read an arbitrary binary message from stdin and write the raw tag/value pairs in text format to stdout.
+No input files should be given when using this flag.`,
 	)
-	_ = flagSet.MarkHidden(decodeRawFlagName)
 	flagSet.StringSliceVar(
 		&f.DescriptorSetIn,
 		descriptorSetInFlagName,
 		nil,
-		`Not supported by buf.`,
+		`The FileDescriptorSets to use as the schema instead of parsing .proto files from the include paths.`,
+	)
+	flagSet.StringVar(
+		&f.MessageFormat,
+		messageFormatFlagName,
+		// cannot set default due to recursive flag parsing
+		// no way to differentiate between default and set for now
+		// perhaps we could rework pflag usage somehow
+		"",
+		fmt.Sprintf(
+			`The format of the message read by --%s and written by --%s. Must be one of format %s. Defaults to %s.`,
+			encodeFlagName,
+			decodeFlagName,
+			stringutil.SliceToString(allMessageFormats),
+			defaultMessageFormat,
+		),
 	)
-	_ = flagSet.MarkHidden(descriptorSetInFlagName)
 }
 
 func (f *flagsBuilder) Normalize(flagSet *pflag.FlagSet, name string) string {
@@ -212,9 +237,6 @@ func (f *flagsBuilder) Build(args []string) (*env, error) {
 	if err != nil {
 		return nil, err
 	}
-	if err := f.checkUnsupported(); err != nil {
-		return nil, err
-	}
 	for pluginName, pluginInfo := range pluginNameToPluginInfo {
 		if pluginInfo.Out == "" && len(pluginInfo.Opt) > 0 {
 			return nil, newCannotSpecifyOptWithoutOutError(pluginName)
@@ -235,7 +257,25 @@ func (f *flagsBuilder) Build(args []string) (*env, error) {
 	if f.ErrorFormat == "" {
 		f.ErrorFormat = defaultErrorFormat
 	}
-	if len(filePaths) == 0 {
+	if f.MessageFormat == "" {
+		f.MessageFormat = defaultMessageFormat
+	} else if !slices.Contains(allMessageFormats, f.MessageFormat) {
+		return nil, newMessageFormatInvalidError(f.MessageFormat)
+	}
+	// protoc splits --descriptor_set_in in the same manner as --proto_path.
+	if len(f.DescriptorSetIn) > 0 {
+		f.DescriptorSetIn = splitIncludeDirPaths(f.DescriptorSetIn)
+	}
+	if f.Encode != "" && f.Decode != "" || (f.Encode != "" || f.Decode != "") && f.DecodeRaw {
+		return nil, errMultipleCodecModes
+	}
+	if f.DecodeRaw {
+		if len(filePaths) > 0 {
+			return nil, errDecodeRawWithInputFiles
+		}
+	} else if len(filePaths) == 0 && (len(f.DescriptorSetIn) == 0 || f.Encode == "" && f.Decode == "") {
+		// When using --descriptor_set_in with --encode or --decode, the schema
+		// is entirely defined by the FileDescriptorSets.
 		return nil, errNoInputFiles
 	}
 	return &env{
@@ -340,6 +380,9 @@ func (f *flagsBuilder) merge(subFlagsBuilder *flagsBuilder) error {
 		f.ByDir = true
 	}
 	f.PluginPathValues = append(f.PluginPathValues, subFlagsBuilder.PluginPathValues...)
+	if subFlagsBuilder.MessageFormat != "" {
+		f.MessageFormat = subFlagsBuilder.MessageFormat
+	}
 	if subFlagsBuilder.Encode != "" {
 		f.Encode = subFlagsBuilder.Encode
 	}
@@ -476,22 +519,6 @@ func (f *flagsBuilder) getPluginNamesSortedByOutIndex(
 	return pluginNames, nil
 }
 
-func (f *flagsBuilder) checkUnsupported() error {
-	if f.Encode != "" {
-		return newEncodeNotSupportedError()
-	}
-	if f.Decode != "" {
-		return newDecodeNotSupportedError()
-	}
-	if f.DecodeRaw {
-		return newDecodeRawNotSupportedError()
-	}
-	if len(f.DescriptorSetIn) > 0 {
-		return newDescriptorSetInNotSupportedError()
-	}
-	return nil
-}
-
 type pluginValue struct {
 	OutIndexes []int
 	OptIndexes []int
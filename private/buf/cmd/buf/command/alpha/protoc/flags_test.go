@@ -44,6 +44,7 @@ func TestParseFlags(t *testing.T) {
 				flags: flags{
 					IncludeDirPaths: defaultIncludeDirPaths,
 					ErrorFormat:     defaultErrorFormat,
+					MessageFormat:   defaultMessageFormat,
 				},
 				FilePaths: []string{
 					"foo.proto",
@@ -63,7 +64,8 @@ func TestParseFlags(t *testing.T) {
 					IncludeDirPaths: []string{
 						"proto",
 					},
-					ErrorFormat: "text",
+					ErrorFormat:   "text",
+					MessageFormat: defaultMessageFormat,
 				},
 				FilePaths: []string{
 					"foo.proto",
@@ -85,7 +87,8 @@ func TestParseFlags(t *testing.T) {
 					IncludeDirPaths: []string{
 						"proto",
 					},
-					ErrorFormat: "text",
+					ErrorFormat:   "text",
+					MessageFormat: defaultMessageFormat,
 				},
 				PluginNamesSortedByOutIndex: []string{
 					"go",
@@ -118,7 +121,8 @@ func TestParseFlags(t *testing.T) {
 					IncludeDirPaths: []string{
 						"proto",
 					},
-					ErrorFormat: "text",
+					ErrorFormat:   "text",
+					MessageFormat: defaultMessageFormat,
 				},
 				PluginNamesSortedByOutIndex: []string{
 					"go",
@@ -153,7 +157,8 @@ func TestParseFlags(t *testing.T) {
 					IncludeDirPaths: []string{
 						"proto",
 					},
-					ErrorFormat: "text",
+					ErrorFormat:   "text",
+					MessageFormat: defaultMessageFormat,
 				},
 				PluginNamesSortedByOutIndex: []string{
 					"go",
@@ -189,7 +194,8 @@ func TestParseFlags(t *testing.T) {
 					IncludeDirPaths: []string{
 						"proto",
 					},
-					ErrorFormat: "text",
+					ErrorFormat:   "text",
+					MessageFormat: defaultMessageFormat,
 				},
 				PluginNamesSortedByOutIndex: []string{
 					"go",
@@ -216,7 +222,8 @@ func TestParseFlags(t *testing.T) {
 					IncludeDirPaths: []string{
 						"proto",
 					},
-					ErrorFormat: "text",
+					ErrorFormat:   "text",
+					MessageFormat: defaultMessageFormat,
 				},
 				PluginNamesSortedByOutIndex: []string{
 					"go",
@@ -243,7 +250,8 @@ func TestParseFlags(t *testing.T) {
 					IncludeDirPaths: []string{
 						"proto",
 					},
-					ErrorFormat: "text",
+					ErrorFormat:   "text",
+					MessageFormat: defaultMessageFormat,
 				},
 				PluginNamesSortedByOutIndex: []string{
 					"go",
@@ -284,7 +292,8 @@ func TestParseFlags(t *testing.T) {
 					IncludeDirPaths: []string{
 						"proto",
 					},
-					ErrorFormat: "text",
+					ErrorFormat:   "text",
+					MessageFormat: defaultMessageFormat,
 				},
 				PluginNamesSortedByOutIndex: []string{
 					"go",
@@ -322,7 +331,8 @@ func TestParseFlags(t *testing.T) {
 					IncludeDirPaths: []string{
 						"proto",
 					},
-					ErrorFormat: "text",
+					ErrorFormat:   "text",
+					MessageFormat: defaultMessageFormat,
 				},
 				PluginNamesSortedByOutIndex: []string{
 					"go",
@@ -361,7 +371,8 @@ func TestParseFlags(t *testing.T) {
 					IncludeDirPaths: []string{
 						"proto",
 					},
-					ErrorFormat: "text",
+					ErrorFormat:   "text",
+					MessageFormat: defaultMessageFormat,
 				},
 				PluginNamesSortedByOutIndex: []string{
 					"go",
@@ -399,7 +410,8 @@ func TestParseFlags(t *testing.T) {
 					IncludeDirPaths: []string{
 						"proto",
 					},
-					ErrorFormat: "text",
+					ErrorFormat:   "text",
+					MessageFormat: defaultMessageFormat,
 				},
 				PluginNamesSortedByOutIndex: []string{
 					"go",
@@ -442,6 +454,7 @@ func TestParseFlags(t *testing.T) {
 				flags: flags{
 					IncludeDirPaths: defaultIncludeDirPaths,
 					ErrorFormat:     defaultErrorFormat,
+					MessageFormat:   defaultMessageFormat,
 				},
 				PluginNamesSortedByOutIndex: []string{
 					"foo",
@@ -480,7 +493,8 @@ func TestParseFlags(t *testing.T) {
 						"baz",
 						"bat",
 					},
-					ErrorFormat: defaultErrorFormat,
+					ErrorFormat:   defaultErrorFormat,
+					MessageFormat: defaultMessageFormat,
 				},
 				FilePaths: []string{
 					"foo.proto",
@@ -502,7 +516,8 @@ func TestParseFlags(t *testing.T) {
 						"baz",
 						"bat",
 					},
-					ErrorFormat: defaultErrorFormat,
+					ErrorFormat:   defaultErrorFormat,
+					MessageFormat: defaultMessageFormat,
 				},
 				FilePaths: []string{
 					"foo.proto",
@@ -524,7 +539,8 @@ func TestParseFlags(t *testing.T) {
 						"bar",
 						"bat",
 					},
-					ErrorFormat: defaultErrorFormat,
+					ErrorFormat:   defaultErrorFormat,
+					MessageFormat: defaultMessageFormat,
 				},
 				FilePaths: []string{
 					"foo.proto",
@@ -543,7 +559,8 @@ func TestParseFlags(t *testing.T) {
 					IncludeDirPaths: []string{
 						"proto",
 					},
-					ErrorFormat: "gcc",
+					ErrorFormat:   "gcc",
+					MessageFormat: defaultMessageFormat,
 				},
 				PluginNamesSortedByOutIndex: []string{
 					"go",
@@ -570,7 +587,8 @@ func TestParseFlags(t *testing.T) {
 					IncludeDirPaths: []string{
 						"proto",
 					},
-					ErrorFormat: "gcc",
+					ErrorFormat:   "gcc",
+					MessageFormat: defaultMessageFormat,
 				},
 				PluginNamesSortedByOutIndex: []string{
 					"go",
@@ -586,6 +604,73 @@ func TestParseFlags(t *testing.T) {
 				},
 			},
 		},
+		{
+			Args: []string{
+				"--encode",
+				"foo.Foo",
+				"--decode",
+				"foo.Foo",
+				"foo.proto",
+			},
+			ExpectedError: errMultipleCodecModes,
+		},
+		{
+			Args: []string{
+				"--decode_raw",
+				"foo.proto",
+			},
+			ExpectedError: errDecodeRawWithInputFiles,
+		},
+		{
+			Args: []string{
+				"--encode",
+				"foo.Foo",
+			},
+			ExpectedError: errNoInputFiles,
+		},
+		{
+			Args: []string{
+				"--message_format",
+				"binpb",
+				"foo.proto",
+			},
+			ExpectedError: newMessageFormatInvalidError("binpb"),
+		},
+		{
+			Args: []string{
+				"--descriptor_set_in",
+				"a.binpb" + string(filepath.ListSeparator) + "b.binpb",
+				"--decode",
+				"foo.Foo",
+				"--message_format",
+				"json",
+			},
+			Expected: &env{
+				flags: flags{
+					IncludeDirPaths: defaultIncludeDirPaths,
+					ErrorFormat:     defaultErrorFormat,
+					Decode:          "foo.Foo",
+					DescriptorSetIn: []string{
+						"a.binpb",
+						"b.binpb",
+					},
+					MessageFormat: messageFormatJSON,
+				},
+			},
+		},
+		{
+			Args: []string{
+				"--decode_raw",
+			},
+			Expected: &env{
+				flags: flags{
+					IncludeDirPaths: defaultIncludeDirPaths,
+					ErrorFormat:     defaultErrorFormat,
+					DecodeRaw:       true,
+					MessageFormat:   defaultMessageFormat,
+				},
+			},
+		},
 	}
 	for i, testCase := range testCases {
 		name := fmt.Sprintf("%d", i)
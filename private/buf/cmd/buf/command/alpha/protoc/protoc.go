@@ -36,6 +36,7 @@ import (
 	"github.com/bufbuild/buf/private/pkg/app/appext"
 	"github.com/bufbuild/buf/private/pkg/slogext"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"github.com/bufbuild/buf/private/pkg/wasm"
 )
 
 // NewCommand returns a new Command.
@@ -106,57 +107,32 @@ func run(
 		slog.Any("plugins", env.PluginNameToPluginInfo),
 	)
 
+	if env.DecodeRaw {
+		return decodeRaw(container)
+	}
+	if env.Encode != "" || env.Decode != "" {
+		if len(env.PluginNameToPluginInfo) > 0 || env.Output != "" || env.PrintFreeFieldNumbers {
+			return newCannotUseCodecWithOutputError()
+		}
+	}
+
 	storageosProvider := storageos.NewProvider(storageos.ProviderWithSymlinks())
-	moduleSet, err := bufprotoc.NewModuleSetForProtoc(
-		ctx,
-		logger,
-		storageosProvider,
-		env.IncludeDirPaths,
-		env.FilePaths,
-	)
+	image, err := buildImage(ctx, container, storageosProvider, env)
 	if err != nil {
 		return err
 	}
-	var buildOptions []bufimage.BuildImageOption
-	// we always need source code info if we are doing generation
-	if len(env.PluginNameToPluginInfo) == 0 && !env.IncludeSourceInfo {
-		buildOptions = append(buildOptions, bufimage.WithExcludeSourceCodeInfo())
+	if env.Encode != "" {
+		return encode(container, image, env.Encode, env.MessageFormat)
 	}
-	image, err := bufimage.BuildImage(
-		ctx,
-		logger,
-		bufmodule.ModuleSetToModuleReadBucketWithOnlyProtoFiles(moduleSet),
-		buildOptions...,
-	)
-	if err != nil {
-		var fileAnnotationSet bufanalysis.FileAnnotationSet
-		if errors.As(err, &fileAnnotationSet) {
-			if err := bufanalysis.PrintFileAnnotationSet(
-				container.Stderr(),
-				fileAnnotationSet,
-				env.ErrorFormat,
-			); err != nil {
-				return err
-			}
-			// we do this even though we're in protoc compatibility mode as we just need to do non-zero
-			// but this also makes us consistent with the rest of buf
-			return bufctl.ErrFileAnnotation
-		}
-		return err
+	if env.Decode != "" {
+		return decode(container, image, env.Decode, env.MessageFormat)
 	}
 	if env.PrintFreeFieldNumbers {
-		fileInfos, err := bufmodule.GetTargetFileInfos(
-			ctx,
-			bufmodule.ModuleSetToModuleReadBucketWithOnlyProtoFiles(
-				moduleSet,
-			),
-		)
-		if err != nil {
-			return err
-		}
 		var filePaths []string
-		for _, fileInfo := range fileInfos {
-			filePaths = append(filePaths, fileInfo.Path())
+		for _, imageFile := range image.Files() {
+			if !imageFile.IsImport() {
+				filePaths = append(filePaths, imageFile.Path())
+			}
 		}
 		s, err := bufimageutil.FreeMessageRangeStrings(ctx, filePaths, image)
 		if err != nil {
@@ -179,6 +155,15 @@ func run(
 				return err
 			}
 		}
+		wasmRuntimeCacheDir, err := bufcli.CreateWasmRuntimeCacheDir(container)
+		if err != nil {
+			return err
+		}
+		// The runtime is only created if a plugin is a WebAssembly module.
+		wasmRuntime := wasm.NewLazyRuntime(wasm.WithLocalCacheDir(wasmRuntimeCacheDir))
+		defer func() {
+			retErr = errors.Join(retErr, wasmRuntime.Close(ctx))
+		}()
 		pluginResponses := make([]*bufprotoplugin.PluginResponse, 0, len(env.PluginNamesSortedByOutIndex))
 		for _, pluginName := range env.PluginNamesSortedByOutIndex {
 			pluginInfo, ok := env.PluginNameToPluginInfo[pluginName]
@@ -189,6 +174,7 @@ func run(
 				ctx,
 				logger,
 				storageosProvider,
+				wasmRuntime,
 				container,
 				images,
 				pluginName,
@@ -241,3 +227,55 @@ func run(
 		bufctl.WithImageAsFileDescriptorSet(true),
 	)
 }
+
+// buildImage builds the Image either from the FileDescriptorSets given by
+// --descriptor_set_in, or by compiling the input files.
+func buildImage(
+	ctx context.Context,
+	container appext.Container,
+	storageosProvider storageos.Provider,
+	env *env,
+) (bufimage.Image, error) {
+	if len(env.DescriptorSetIn) > 0 {
+		return newImageForDescriptorSetIn(env.DescriptorSetIn, env.IncludeDirPaths, env.FilePaths)
+	}
+	logger := container.Logger()
+	moduleSet, err := bufprotoc.NewModuleSetForProtoc(
+		ctx,
+		logger,
+		storageosProvider,
+		env.IncludeDirPaths,
+		env.FilePaths,
+	)
+	if err != nil {
+		return nil, err
+	}
+	var buildOptions []bufimage.BuildImageOption
+	// we always need source code info if we are doing generation
+	if len(env.PluginNameToPluginInfo) == 0 && !env.IncludeSourceInfo {
+		buildOptions = append(buildOptions, bufimage.WithExcludeSourceCodeInfo())
+	}
+	image, err := bufimage.BuildImage(
+		ctx,
+		logger,
+		bufmodule.ModuleSetToModuleReadBucketWithOnlyProtoFiles(moduleSet),
+		buildOptions...,
+	)
+	if err != nil {
+		var fileAnnotationSet bufanalysis.FileAnnotationSet
+		if errors.As(err, &fileAnnotationSet) {
+			if err := bufanalysis.PrintFileAnnotationSet(
+				container.Stderr(),
+				fileAnnotationSet,
+				env.ErrorFormat,
+			); err != nil {
+				return nil, err
+			}
+			// we do this even though we're in protoc compatibility mode as we just need to do non-zero
+			// but this also makes us consistent with the rest of buf
+			return nil, bufctl.ErrFileAnnotation
+		}
+		return nil, err
+	}
+	return image, nil
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/bufbuild/buf/private/buf/buftesting"
@@ -472,3 +473,186 @@ func testGetBufProtocFileDescriptorSetBytes(t *testing.T, dirPath string) []byte
 	)
 	return stdout.Bytes()
 }
+
+func TestEncodeDecode(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "codec")
+	filePath := filepath.Join(dirPath, "a.proto")
+	encoded := bytes.NewBuffer(nil)
+	appcmdtesting.RunCommandSuccess(
+		t,
+		testNewCommand,
+		nil,
+		strings.NewReader(`name: "foo" b { value: 150 }`),
+		encoded,
+		"-I",
+		dirPath,
+		"--encode",
+		"codec.A",
+		filePath,
+	)
+	appcmdtesting.RunCommandSuccessStdout(
+		t,
+		testNewCommand,
+		`
+name: "foo"
+b: {
+  value: 150
+}
+`,
+		nil,
+		bytes.NewReader(encoded.Bytes()),
+		"-I",
+		dirPath,
+		"--decode",
+		"codec.A",
+		filePath,
+	)
+	appcmdtesting.RunCommandSuccessStdout(
+		t,
+		testNewCommand,
+		`
+{
+  "name": "foo",
+  "b": {
+    "value": 150
+  }
+}
+`,
+		nil,
+		bytes.NewReader(encoded.Bytes()),
+		"-I",
+		dirPath,
+		"--decode",
+		"codec.A",
+		"--message_format",
+		"json",
+		filePath,
+	)
+	appcmdtesting.RunCommandSuccessStdout(
+		t,
+		testNewCommand,
+		`
+1: "foo"
+2 {
+  1: 150
+}
+`,
+		nil,
+		bytes.NewReader(encoded.Bytes()),
+		"--decode_raw",
+	)
+	appcmdtesting.RunCommandExitCodeStderrContains(
+		t,
+		testNewCommand,
+		1,
+		[]string{"type not defined: codec.C"},
+		nil,
+		bytes.NewReader(encoded.Bytes()),
+		"-I",
+		dirPath,
+		"--decode",
+		"codec.C",
+		filePath,
+	)
+}
+
+func TestDescriptorSetIn(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "codec")
+	tempDirPath := t.TempDir()
+	descriptorSetAPath := filepath.Join(tempDirPath, "a.binpb")
+	descriptorSetBPath := filepath.Join(tempDirPath, "b.binpb")
+	appcmdtesting.RunCommandSuccess(
+		t,
+		testNewCommand,
+		nil,
+		nil,
+		nil,
+		"-I",
+		dirPath,
+		"-o",
+		descriptorSetAPath,
+		filepath.Join(dirPath, "a.proto"),
+	)
+	appcmdtesting.RunCommandSuccess(
+		t,
+		testNewCommand,
+		nil,
+		nil,
+		nil,
+		"-I",
+		dirPath,
+		"-o",
+		descriptorSetBPath,
+		filepath.Join(dirPath, "b.proto"),
+	)
+	encoded := bytes.NewBuffer(nil)
+	// Dependencies are resolved across FileDescriptorSets, and no input files are needed.
+	appcmdtesting.RunCommandSuccess(
+		t,
+		testNewCommand,
+		nil,
+		strings.NewReader(`{"name": "foo", "b": {"value": 150}}`),
+		encoded,
+		"--descriptor_set_in",
+		descriptorSetAPath+string(os.PathListSeparator)+descriptorSetBPath,
+		"--encode",
+		"codec.A",
+		"--message_format",
+		"json",
+	)
+	appcmdtesting.RunCommandSuccessStdout(
+		t,
+		testNewCommand,
+		`
+name: "foo"
+b: {
+  value: 150
+}
+`,
+		nil,
+		bytes.NewReader(encoded.Bytes()),
+		"--descriptor_set_in",
+		descriptorSetBPath,
+		"--descriptor_set_in",
+		descriptorSetAPath,
+		"--decode",
+		"codec.A",
+	)
+	appcmdtesting.RunCommandExitCodeStderrContains(
+		t,
+		testNewCommand,
+		1,
+		[]string{`a.proto: import "b.proto" was not found in --descriptor_set_in`},
+		nil,
+		bytes.NewReader(encoded.Bytes()),
+		"--descriptor_set_in",
+		descriptorSetAPath,
+		"--decode",
+		"codec.A",
+	)
+	// The input files may be given relative to an include directory.
+	appcmdtesting.RunCommandSuccessStdout(
+		t,
+		testNewCommand,
+		`codec.A                             free: 3-INF`,
+		nil,
+		nil,
+		"--descriptor_set_in",
+		descriptorSetAPath,
+		"--descriptor_set_in",
+		descriptorSetBPath,
+		"-I",
+		dirPath,
+		"--print_free_field_numbers",
+		filepath.Join(dirPath, "a.proto"),
+	)
+}
+
+func testNewCommand(name string) *appcmd.Command {
+	return NewCommand(
+		name,
+		appext.NewBuilder(name),
+	)
+}
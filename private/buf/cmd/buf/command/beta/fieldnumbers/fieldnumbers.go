@@ -0,0 +1,196 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fieldnumbers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/bufbuild/buf/private/buf/bufcli"
+	"github.com/bufbuild/buf/private/buf/bufctl"
+	"github.com/bufbuild/buf/private/buf/bufprint"
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimageutil"
+	"github.com/bufbuild/buf/private/pkg/app/appcmd"
+	"github.com/bufbuild/buf/private/pkg/app/appext"
+	"github.com/bufbuild/buf/private/pkg/stringutil"
+	"github.com/spf13/pflag"
+)
+
+const (
+	formatFlagName          = "format"
+	nextFlagName            = "next"
+	errorFormatFlagName     = "error-format"
+	configFlagName          = "config"
+	pathsFlagName           = "path"
+	excludePathsFlagName    = "exclude-path"
+	disableSymlinksFlagName = "disable-symlinks"
+)
+
+// NewCommand returns a new Command.
+func NewCommand(
+	name string,
+	builder appext.SubCommandBuilder,
+) *appcmd.Command {
+	flags := newFlags()
+	return &appcmd.Command{
+		Use:   name + " <input>",
+		Short: "Print the used, reserved, and free numbers of messages and enums",
+		Long: `This command prints the field numbers used, reserved, and free in every message,
+and the values used, reserved, and free in every enum, of the <input> location.
+
+Extension ranges are considered used. The field numbers 19000 to 19999, which are reserved
+for the Protocol Buffers implementation, are never free. The next number is the smallest free
+number greater than every used, reserved, or extension number, so that numbers of fields or
+values that were deleted without being reserved are not reused.
+
+Examples:
+
+Print the numbers of all messages and enums in the current directory:
+
+    $ buf beta field-numbers
+
+Print the next safe field number to use in a message:
+
+    $ buf beta field-numbers --next acme.weather.v1.Forecast
+
+` +
+			bufcli.GetInputLong(`the source, module, or image to print the numbers of`),
+		Args: appcmd.MaximumNArgs(1),
+		Run: builder.NewRunFunc(
+			func(ctx context.Context, container appext.Container) error {
+				return run(ctx, container, flags)
+			},
+		),
+		BindFlags: flags.Bind,
+	}
+}
+
+type flags struct {
+	Format          string
+	Next            string
+	ErrorFormat     string
+	Config          string
+	Paths           []string
+	ExcludePaths    []string
+	DisableSymlinks bool
+	// special
+	InputHashtag string
+}
+
+func newFlags() *flags {
+	return &flags{}
+}
+
+func (f *flags) Bind(flagSet *pflag.FlagSet) {
+	bufcli.BindInputHashtag(flagSet, &f.InputHashtag)
+	bufcli.BindPaths(flagSet, &f.Paths, pathsFlagName)
+	bufcli.BindExcludePaths(flagSet, &f.ExcludePaths, excludePathsFlagName)
+	bufcli.BindDisableSymlinks(flagSet, &f.DisableSymlinks, disableSymlinksFlagName)
+	flagSet.StringVar(
+		&f.Format,
+		formatFlagName,
+		bufprint.FormatText.String(),
+		fmt.Sprintf(`The output format to use. Must be one of %s`, bufprint.AllFormatsString),
+	)
+	flagSet.StringVar(
+		&f.Next,
+		nextFlagName,
+		"",
+		fmt.Sprintf(
+			`The fully-qualified name of a message or enum to print the next safe number of.
+If --%s is %s, the numbers of the message or enum are printed instead`,
+			formatFlagName,
+			bufprint.FormatJSON.String(),
+		),
+	)
+	flagSet.StringVar(
+		&f.ErrorFormat,
+		errorFormatFlagName,
+		"text",
+		fmt.Sprintf(
+			"The format for build errors printed to stderr. Must be one of %s",
+			stringutil.SliceToString(bufanalysis.AllFormatStrings),
+		),
+	)
+	flagSet.StringVar(
+		&f.Config,
+		configFlagName,
+		"",
+		`The buf.yaml file or data to use for configuration`,
+	)
+}
+
+func run(
+	ctx context.Context,
+	container appext.Container,
+	flags *flags,
+) error {
+	format, err := bufprint.ParseFormat(flags.Format)
+	if err != nil {
+		return appcmd.WrapInvalidArgumentError(err)
+	}
+	input, err := bufcli.GetInputValue(container, flags.InputHashtag, ".")
+	if err != nil {
+		return err
+	}
+	controller, err := bufcli.NewController(
+		container,
+		bufctl.WithDisableSymlinks(flags.DisableSymlinks),
+		bufctl.WithFileAnnotationErrorFormat(flags.ErrorFormat),
+	)
+	if err != nil {
+		return err
+	}
+	image, err := controller.GetImage(
+		ctx,
+		input,
+		bufctl.WithTargetPaths(flags.Paths, flags.ExcludePaths),
+		bufctl.WithImageExcludeSourceInfo(true),
+		bufctl.WithConfigOverride(flags.Config),
+	)
+	if err != nil {
+		return err
+	}
+	var filePaths []string
+	for _, imageFile := range image.Files() {
+		if !imageFile.IsImport() {
+			filePaths = append(filePaths, imageFile.Path())
+		}
+	}
+	numbers, err := bufimageutil.NumbersForFiles(filePaths, image)
+	if err != nil {
+		return err
+	}
+	numbersPrinter := bufprint.NewNumbersPrinter(container.Stdout())
+	if flags.Next == "" {
+		return numbersPrinter.PrintNumbers(ctx, format, numbers...)
+	}
+	for _, n := range numbers {
+		if n.Name != flags.Next {
+			continue
+		}
+		if format == bufprint.FormatJSON {
+			return numbersPrinter.PrintNumbers(ctx, format, n)
+		}
+		if n.Next == nil {
+			return fmt.Errorf("%s %q has no free numbers", n.Kind, n.Name)
+		}
+		_, err := fmt.Fprintln(container.Stdout(), strconv.Itoa(int(*n.Next)))
+		return err
+	}
+	return fmt.Errorf("message or enum %q not found in input", flags.Next)
+}
@@ -0,0 +1,234 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migrateeditions
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/bufbuild/buf/private/buf/bufcli"
+	"github.com/bufbuild/buf/private/buf/bufctl"
+	"github.com/bufbuild/buf/private/buf/bufeditions"
+	"github.com/bufbuild/buf/private/buf/buffetch"
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/pkg/app/appcmd"
+	"github.com/bufbuild/buf/private/pkg/app/appext"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/stringutil"
+	"github.com/spf13/pflag"
+)
+
+const (
+	diffFlagName            = "diff"
+	diffFlagShortName       = "d"
+	errorFormatFlagName     = "error-format"
+	configFlagName          = "config"
+	pathsFlagName           = "path"
+	excludePathsFlagName    = "exclude-path"
+	disableSymlinksFlagName = "disable-symlinks"
+)
+
+// NewCommand returns a new Command.
+func NewCommand(
+	name string,
+	builder appext.SubCommandBuilder,
+) *appcmd.Command {
+	flags := newFlags()
+	return &appcmd.Command{
+		Use:   name + " <source>",
+		Short: "Migrate proto2 and proto3 files to editions",
+		Long: `This command rewrites the proto2 and proto3 files of the <source> location in place to edition ` + bufeditions.Edition + `.
+
+The syntax declaration is replaced with an edition declaration, and optional and required labels,
+packed options and groups are replaced with features. Features are added where needed for the
+migrated files to have the same semantics as the original files, for the whole file if this
+needs fewer options than setting them on each field or enum. The migrated files are formatted
+as with buf format.
+
+The migrated files are built and checked against the original files with the breaking change
+rules of the WIRE_JSON category before they are written. Files that already use editions are
+not changed. Groups in oneofs and extend blocks cannot be migrated.
+
+Examples:
+
+Migrate the files in the current directory:
+
+    $ buf beta migrate-editions
+
+Display a diff between the original and migrated files instead of writing them:
+
+    $ buf beta migrate-editions -d
+
+The source must be a directory or a proto file.`,
+		Args: appcmd.MaximumNArgs(1),
+		Run: builder.NewRunFunc(
+			func(ctx context.Context, container appext.Container) error {
+				return run(ctx, container, flags)
+			},
+		),
+		BindFlags: flags.Bind,
+	}
+}
+
+type flags struct {
+	Diff            bool
+	ErrorFormat     string
+	Config          string
+	Paths           []string
+	ExcludePaths    []string
+	DisableSymlinks bool
+	// special
+	InputHashtag string
+}
+
+func newFlags() *flags {
+	return &flags{}
+}
+
+func (f *flags) Bind(flagSet *pflag.FlagSet) {
+	bufcli.BindInputHashtag(flagSet, &f.InputHashtag)
+	bufcli.BindPaths(flagSet, &f.Paths, pathsFlagName)
+	bufcli.BindExcludePaths(flagSet, &f.ExcludePaths, excludePathsFlagName)
+	bufcli.BindDisableSymlinks(flagSet, &f.DisableSymlinks, disableSymlinksFlagName)
+	flagSet.BoolVarP(
+		&f.Diff,
+		diffFlagName,
+		diffFlagShortName,
+		false,
+		"Display diffs instead of rewriting files",
+	)
+	flagSet.StringVar(
+		&f.ErrorFormat,
+		errorFormatFlagName,
+		"text",
+		fmt.Sprintf(
+			"The format for build errors printed to stderr. Must be one of %s",
+			stringutil.SliceToString(bufanalysis.AllFormatStrings),
+		),
+	)
+	flagSet.StringVar(
+		&f.Config,
+		configFlagName,
+		"",
+		`The buf.yaml file or data to use for configuration`,
+	)
+}
+
+func run(
+	ctx context.Context,
+	container appext.Container,
+	flags *flags,
+) (retErr error) {
+	source, err := bufcli.GetInputValue(container, flags.InputHashtag, ".")
+	if err != nil {
+		return err
+	}
+	// Files are rewritten in place, so the source must be a directory or proto file.
+	if _, err := buffetch.NewDirOrProtoFileRefParser(container.Logger()).GetDirOrProtoFileRef(ctx, source); err != nil {
+		if errors.Is(err, buffetch.ErrModuleFormatDetectedForDirOrProtoFileRef) {
+			return appcmd.NewInvalidArgumentErrorf("invalid input %q: must be a directory or proto file", source)
+		}
+		return appcmd.NewInvalidArgumentErrorf("invalid input %q: %v", source, err)
+	}
+	controller, err := bufcli.NewController(
+		container,
+		bufctl.WithDisableSymlinks(flags.DisableSymlinks),
+		bufctl.WithFileAnnotationErrorFormat(flags.ErrorFormat),
+	)
+	if err != nil {
+		return err
+	}
+	workspace, err := controller.GetWorkspace(
+		ctx,
+		source,
+		bufctl.WithTargetPaths(flags.Paths, flags.ExcludePaths),
+		bufctl.WithConfigOverride(flags.Config),
+	)
+	if err != nil {
+		return err
+	}
+	image, err := controller.GetImageForWorkspace(ctx, workspace)
+	if err != nil {
+		return err
+	}
+	originalReadBucket := bufmodule.ModuleReadBucketToStorageReadBucket(
+		bufmodule.ModuleReadBucketWithOnlyTargetFiles(
+			bufmodule.ModuleSetToModuleReadBucketWithOnlyProtoFilesForTargetModules(workspace),
+		),
+	)
+	migratedReadBucket, err := bufeditions.Migrate(ctx, image, originalReadBucket)
+	if err != nil {
+		return err
+	}
+	migratedImage, err := bufeditions.BuildMigratedImage(ctx, image, migratedReadBucket)
+	if err != nil {
+		return err
+	}
+	if err := bufeditions.CheckMigratedImage(ctx, container.Logger(), image, migratedImage); err != nil {
+		var fileAnnotationSet bufanalysis.FileAnnotationSet
+		if errors.As(err, &fileAnnotationSet) {
+			if err := bufanalysis.PrintFileAnnotationSet(container.Stderr(), fileAnnotationSet, flags.ErrorFormat); err != nil {
+				return err
+			}
+			return errors.New("migrated files do not have the same semantics as the original files, no files were changed")
+		}
+		return err
+	}
+	if flags.Diff {
+		migratedPaths, err := storage.AllPaths(ctx, migratedReadBucket, "")
+		if err != nil {
+			return err
+		}
+		matchers := make([]storage.Matcher, len(migratedPaths))
+		for i, migratedPath := range migratedPaths {
+			matchers[i] = storage.MatchPathEqual(migratedPath)
+		}
+		diffBuffer := bytes.NewBuffer(nil)
+		if _, err := storage.DiffWithFilenames(
+			ctx,
+			diffBuffer,
+			// Files that already use editions are not migrated, and are not part of the diff.
+			storage.FilterReadBucket(originalReadBucket, storage.MatchOr(matchers...)),
+			migratedReadBucket,
+			storage.DiffWithExternalPaths(),
+		); err != nil {
+			return err
+		}
+		_, err = io.Copy(container.Stdout(), diffBuffer)
+		return err
+	}
+	return storage.WalkReadObjects(
+		ctx,
+		migratedReadBucket,
+		"",
+		func(readObject storage.ReadObject) error {
+			objectInfo, err := originalReadBucket.Stat(ctx, readObject.Path())
+			if err != nil {
+				return err
+			}
+			data, err := io.ReadAll(readObject)
+			if err != nil {
+				return err
+			}
+			// Files are written to their external paths, the source is a directory or proto file.
+			return os.WriteFile(objectInfo.ExternalPath(), data, 0644)
+		},
+	)
+}
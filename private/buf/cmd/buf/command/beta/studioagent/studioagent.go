@@ -19,6 +19,7 @@ import (
 	"crypto/tls"
 	"fmt"
 	"net"
+	"os"
 
 	"github.com/bufbuild/buf/private/buf/bufstudioagent"
 	"github.com/bufbuild/buf/private/pkg/app/appcmd"
@@ -41,6 +42,9 @@ const (
 	serverCertFlagName        = "server-cert"
 	serverKeyFlagName         = "server-key"
 	privateNetworkFlagName    = "private-network"
+	unixSocketFlagName        = "unix-socket"
+	recordFlagName            = "record"
+	recordCredentialsFlagName = "record-credentials"
 )
 
 // NewCommand returns a new Command.
@@ -74,6 +78,9 @@ type flags struct {
 	ServerCert        string
 	ServerKey         string
 	PrivateNetwork    bool
+	UnixSocket        string
+	Record            string
+	RecordCredentials bool
 }
 
 func newFlags() *flags {
@@ -147,6 +154,30 @@ func (f *flags) Bind(flagSet *pflag.FlagSet) {
 		false,
 		`Use the agent with private network CORS`,
 	)
+	flagSet.StringVar(
+		&f.UnixSocket,
+		unixSocketFlagName,
+		"",
+		`The path to a unix socket that will be used to connect to all target servers, instead of opening a TCP socket to the host of the target`,
+	)
+	flagSet.StringVar(
+		&f.Record,
+		recordFlagName,
+		"",
+		fmt.Sprintf(
+			`The directory to write every proxied request and response to, as one JSON file per request. The directory is created if it does not exist. The values of the Authorization, Proxy-Authorization, Cookie and Set-Cookie headers are redacted, unless --%s is set`,
+			recordCredentialsFlagName,
+		),
+	)
+	flagSet.BoolVar(
+		&f.RecordCredentials,
+		recordCredentialsFlagName,
+		false,
+		fmt.Sprintf(
+			`Write the values of credential headers to the files of --%s as is, instead of redacting them. The files can then be replayed against servers that require credentials, but contain the credentials in plaintext`,
+			recordFlagName,
+		),
+	)
 }
 
 func run(
@@ -179,6 +210,21 @@ func run(
 			return fmt.Errorf("cannot create new server TLS config: %w", err)
 		}
 	}
+	var handlerOptions []bufstudioagent.HandlerOption
+	if flags.UnixSocket != "" {
+		handlerOptions = append(handlerOptions, bufstudioagent.HandlerWithUnixSocket(flags.UnixSocket))
+	}
+	if flags.Record != "" {
+		if err := os.MkdirAll(flags.Record, 0700); err != nil {
+			return err
+		}
+		handlerOptions = append(handlerOptions, bufstudioagent.HandlerWithRecordDirPath(flags.Record))
+		if flags.RecordCredentials {
+			handlerOptions = append(handlerOptions, bufstudioagent.HandlerWithRecordCredentials())
+		}
+	} else if flags.RecordCredentials {
+		return appcmd.NewInvalidArgumentErrorf("--%s requires --%s", recordCredentialsFlagName, recordFlagName)
+	}
 	mux := bufstudioagent.NewHandler(
 		container.Logger(),
 		flags.Origin,
@@ -186,6 +232,7 @@ func run(
 		slicesext.ToStructMap(flags.DisallowedHeaders),
 		flags.ForwardHeaders,
 		flags.PrivateNetwork,
+		handlerOptions...,
 	)
 	var httpListenConfig net.ListenConfig
 	httpListener, err := httpListenConfig.Listen(ctx, "tcp", fmt.Sprintf("%s:%s", flags.BindAddress, flags.Port))
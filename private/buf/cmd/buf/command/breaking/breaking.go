@@ -43,6 +43,10 @@ const (
 	againstConfigFlagName     = "against-config"
 	excludePathsFlagName      = "exclude-path"
 	disableSymlinksFlagName   = "disable-symlinks"
+	sinceFlagName             = "since"
+	untilFlagName             = "until"
+	failOnFlagName            = "fail-on"
+	maxWarningsFlagName       = "max-warnings"
 )
 
 // NewCommand returns a new Command.
@@ -56,6 +60,19 @@ func NewCommand(
 		Short: "Verify no breaking changes have been made",
 		Long: `This command makes sure that the <input> location has no breaking changes compared to the <against-input> location.
 
+Alternatively, --since checks a range of the git history of a local <input> directory. Each commit
+in the range is checked against its predecessor, and the last commit is checked against the start
+of the range. If the range as a whole has breaking changes, the first commit that introduced them
+is reported. Only the first parent of merge commits is followed.
+
+    $ buf breaking proto --since v1.0.0
+
+The <against-input> can also be a running server that supports gRPC server reflection, to check
+the schema of a deployed service against your repository. Use "grpc+reflect://" for plaintext
+connections and "grpcs+reflect://" for TLS:
+
+    $ buf breaking proto --against grpcs+reflect://api.acme.com
+
 ` +
 			bufcli.GetInputLong(`the source, module, or image to check for breaking changes`),
 		Args: appcmd.MaximumNArgs(1),
@@ -78,6 +95,10 @@ type flags struct {
 	AgainstConfig     string
 	ExcludePaths      []string
 	DisableSymlinks   bool
+	Since             string
+	Until             string
+	FailOn            string
+	MaxWarnings       int
 	// special
 	InputHashtag string
 }
@@ -91,6 +112,8 @@ func (f *flags) Bind(flagSet *pflag.FlagSet) {
 	bufcli.BindInputHashtag(flagSet, &f.InputHashtag)
 	bufcli.BindExcludePaths(flagSet, &f.ExcludePaths, excludePathsFlagName)
 	bufcli.BindDisableSymlinks(flagSet, &f.DisableSymlinks, disableSymlinksFlagName)
+	bufcli.BindFailOn(flagSet, &f.FailOn, failOnFlagName)
+	bufcli.BindMaxWarnings(flagSet, &f.MaxWarnings, maxWarningsFlagName)
 	flagSet.StringVar(
 		&f.ErrorFormat,
 		errorFormatFlagName,
@@ -128,7 +151,8 @@ Overrides --%s`,
 		againstFlagName,
 		"",
 		fmt.Sprintf(
-			`Required. The source, module, or image to check against. Must be one of format %s`,
+			`Required, unless --%s is set. The source, module, or image to check against. Must be one of format %s`,
+			sinceFlagName,
 			buffetch.AllFormatsString,
 		),
 	)
@@ -138,6 +162,29 @@ Overrides --%s`,
 		"",
 		`The buf.yaml file or data to use to configure the against source, module, or image`,
 	)
+	flagSet.StringVar(
+		&f.Since,
+		sinceFlagName,
+		"",
+		fmt.Sprintf(
+			`Check the git history of the input from the given ref instead of checking against --%s
+The input must be a local directory within a git repository. Every commit from the ref to --%s
+is checked against its predecessor, and the last commit is checked against the ref
+Only the first parent of merge commits is followed, so the ref must be on the first-parent history of --%s`,
+			againstFlagName,
+			untilFlagName,
+			untilFlagName,
+		),
+	)
+	flagSet.StringVar(
+		&f.Until,
+		untilFlagName,
+		"",
+		fmt.Sprintf(
+			`The git ref to end the history checked with --%s at. Defaults to HEAD`,
+			sinceFlagName,
+		),
+	)
 }
 
 func run(
@@ -145,6 +192,16 @@ func run(
 	container appext.Container,
 	flags *flags,
 ) (retErr error) {
+	failOn, err := bufcli.ParseFailOnFlag(flags.FailOn, failOnFlagName)
+	if err != nil {
+		return err
+	}
+	if flags.Since != "" {
+		return runSince(ctx, container, flags, failOn)
+	}
+	if flags.Until != "" {
+		return appcmd.NewInvalidArgumentErrorf("--%s can only be set if --%s is set", untilFlagName, sinceFlagName)
+	}
 	if err := bufcli.ValidateRequiredFlag(againstFlagName, flags.Against); err != nil {
 		return err
 	}
@@ -192,19 +249,6 @@ func run(
 	if err != nil {
 		return err
 	}
-	if len(imageWithConfigs) != len(againstImageWithConfigs) {
-		// If workspaces are being used as input, the number
-		// of images MUST match. Otherwise the results will
-		// be meaningless and yield false positives.
-		//
-		// And similar to the note above, if the roots change,
-		// we're torched.
-		return fmt.Errorf(
-			"input contained %d images, whereas against contained %d images",
-			len(imageWithConfigs),
-			len(againstImageWithConfigs),
-		)
-	}
 	wasmRuntimeCacheDir, err := bufcli.CreateWasmRuntimeCacheDir(container)
 	if err != nil {
 		return err
@@ -216,6 +260,59 @@ func run(
 	defer func() {
 		retErr = errors.Join(retErr, wasmRuntime.Close(ctx))
 	}()
+	allFileAnnotations, err := getBreakingFileAnnotations(
+		ctx,
+		container,
+		wasmRuntime,
+		flags,
+		imageWithConfigs,
+		againstImageWithConfigs,
+	)
+	if err != nil {
+		return err
+	}
+	if len(allFileAnnotations) > 0 {
+		allFileAnnotationSet := bufanalysis.NewFileAnnotationSet(allFileAnnotations...)
+		if err := bufanalysis.PrintFileAnnotationSet(
+			container.Stdout(),
+			allFileAnnotationSet,
+			flags.ErrorFormat,
+		); err != nil {
+			return err
+		}
+		return bufcli.GetFileAnnotationsError(
+			allFileAnnotationSet.FileAnnotations(),
+			failOn,
+			flags.MaxWarnings,
+			maxWarningsFlagName,
+		)
+	}
+	return nil
+}
+
+// getBreakingFileAnnotations runs the breaking change checks for each image against
+// the against image at the same index.
+func getBreakingFileAnnotations(
+	ctx context.Context,
+	container appext.Container,
+	wasmRuntime wasm.Runtime,
+	flags *flags,
+	imageWithConfigs []bufctl.ImageWithConfig,
+	againstImageWithConfigs []bufctl.ImageWithConfig,
+) ([]bufanalysis.FileAnnotation, error) {
+	if len(imageWithConfigs) != len(againstImageWithConfigs) {
+		// If workspaces are being used as input, the number
+		// of images MUST match. Otherwise the results will
+		// be meaningless and yield false positives.
+		//
+		// And similar to the note in run, if the roots change,
+		// we're torched.
+		return nil, fmt.Errorf(
+			"input contained %d images, whereas against contained %d images",
+			len(imageWithConfigs),
+			len(againstImageWithConfigs),
+		)
+	}
 	var allFileAnnotations []bufanalysis.FileAnnotation
 	for i, imageWithConfig := range imageWithConfigs {
 		client, err := bufcheck.NewClient(
@@ -224,7 +321,7 @@ func run(
 			bufcheck.ClientWithStderr(container.Stderr()),
 		)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		breakingOptions := []bufcheck.BreakingOption{
 			bufcheck.WithPluginConfigs(imageWithConfig.PluginConfigs()...),
@@ -243,22 +340,11 @@ func run(
 			if errors.As(err, &fileAnnotationSet) {
 				allFileAnnotations = append(allFileAnnotations, fileAnnotationSet.FileAnnotations()...)
 			} else {
-				return err
+				return nil, err
 			}
 		}
 	}
-	if len(allFileAnnotations) > 0 {
-		allFileAnnotationSet := bufanalysis.NewFileAnnotationSet(allFileAnnotations...)
-		if err := bufanalysis.PrintFileAnnotationSet(
-			container.Stdout(),
-			allFileAnnotationSet,
-			flags.ErrorFormat,
-		); err != nil {
-			return err
-		}
-		return bufctl.ErrFileAnnotation
-	}
-	return nil
+	return allFileAnnotations, nil
 }
 
 func getExternalPathsForImages[I bufimage.Image, S ~[]I](images S) ([]string, error) {
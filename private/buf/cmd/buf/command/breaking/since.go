@@ -0,0 +1,321 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package breaking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bufbuild/buf/private/buf/bufcli"
+	"github.com/bufbuild/buf/private/buf/bufctl"
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/pkg/app/appcmd"
+	"github.com/bufbuild/buf/private/pkg/app/appext"
+	"github.com/bufbuild/buf/private/pkg/git"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/wasm"
+)
+
+const defaultUntil = "HEAD"
+
+// runSince checks the git history of the input from --since to --until.
+//
+// Every commit is checked against its first parent, and the last commit is checked against
+// the first commit. If the last commit has breaking changes against the first commit, the
+// commit that introduced them is found by bisecting. Images are built at most once per commit.
+//
+// File annotations are printed to stdout in the error format, while the commits they
+// belong to are printed to stderr, so that stdout can still be parsed.
+func runSince(
+	ctx context.Context,
+	container appext.Container,
+	flags *flags,
+	failOn bufanalysis.Severity,
+) (retErr error) {
+	if flags.Against != "" {
+		return appcmd.NewInvalidArgumentErrorf("--%s and --%s cannot be set at the same time", againstFlagName, sinceFlagName)
+	}
+	if flags.AgainstConfig != "" {
+		return appcmd.NewInvalidArgumentErrorf("--%s and --%s cannot be set at the same time", againstConfigFlagName, sinceFlagName)
+	}
+	if flags.LimitToInputFiles {
+		return appcmd.NewInvalidArgumentErrorf("--%s and --%s cannot be set at the same time", limitToInputFilesFlagName, sinceFlagName)
+	}
+	until := flags.Until
+	if until == "" {
+		until = defaultUntil
+	}
+	input, err := bufcli.GetInputValue(container, flags.InputHashtag, ".")
+	if err != nil {
+		return err
+	}
+	if fileInfo, err := os.Stat(input); err != nil || !fileInfo.IsDir() {
+		return appcmd.NewInvalidArgumentErrorf("--%s requires the input to be a local directory within a git repository, but was %q", sinceFlagName, input)
+	}
+	gitDirPath, subDirPath, err := getGitDirPathAndSubDirPath(ctx, container, input)
+	if err != nil {
+		return err
+	}
+	commits, err := git.ListCommitsInRange(ctx, container, input, flags.Since, until)
+	if err != nil {
+		return err
+	}
+	controller, err := bufcli.NewController(
+		container,
+		bufctl.WithDisableSymlinks(flags.DisableSymlinks),
+		bufctl.WithFileAnnotationErrorFormat(flags.ErrorFormat),
+		bufctl.WithFileAnnotationsToStdout(),
+	)
+	if err != nil {
+		return err
+	}
+	wasmRuntimeCacheDir, err := bufcli.CreateWasmRuntimeCacheDir(container)
+	if err != nil {
+		return err
+	}
+	wasmRuntime, err := wasm.NewRuntime(ctx, wasm.WithLocalCacheDir(wasmRuntimeCacheDir))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		retErr = errors.Join(retErr, wasmRuntime.Close(ctx))
+	}()
+	checker := newCommitChecker(
+		func(ctx context.Context, commit git.Commit) ([]bufctl.ImageWithConfig, error) {
+			commitInput := fmt.Sprintf("%s#format=git,ref=%s,depth=1", gitDirPath, commit.Hash)
+			if subDirPath != "." {
+				commitInput += ",subdir=" + subDirPath
+			}
+			// Do not exclude imports here. bufcheck's Client requires all imports.
+			// Use bufcheck's BreakingWithExcludeImports.
+			return controller.GetTargetImageWithConfigs(
+				ctx,
+				commitInput,
+				bufctl.WithTargetPaths(flags.Paths, flags.ExcludePaths),
+				bufctl.WithConfigOverride(flags.Config),
+			)
+		},
+		func(
+			ctx context.Context,
+			imageWithConfigs []bufctl.ImageWithConfig,
+			againstImageWithConfigs []bufctl.ImageWithConfig,
+		) ([]bufanalysis.FileAnnotation, error) {
+			return getBreakingFileAnnotations(
+				ctx,
+				container,
+				wasmRuntime,
+				flags,
+				imageWithConfigs,
+				againstImageWithConfigs,
+			)
+		},
+	)
+	printFileAnnotations := func(header string, fileAnnotations []bufanalysis.FileAnnotation) error {
+		if _, err := fmt.Fprintln(container.Stderr(), header); err != nil {
+			return err
+		}
+		return bufanalysis.PrintFileAnnotationSet(
+			container.Stdout(),
+			bufanalysis.NewFileAnnotationSet(fileAnnotations...),
+			flags.ErrorFormat,
+		)
+	}
+	// Every breaking change is introduced in exactly one step, so the fail-on and
+	// max-warnings thresholds are evaluated on the per-step file annotations and the
+	// cumulative file annotations separately, to not count breaking changes twice.
+	var stepFileAnnotations []bufanalysis.FileAnnotation
+	// Per step.
+	for i := 1; i < len(commits); i++ {
+		fileAnnotations, err := checker.check(ctx, commits[i], commits[i-1])
+		if err != nil {
+			return err
+		}
+		if len(fileAnnotations) == 0 {
+			continue
+		}
+		stepFileAnnotations = append(stepFileAnnotations, fileAnnotations...)
+		if err := printFileAnnotations(
+			fmt.Sprintf(
+				"Breaking changes in %s compared to %s:",
+				commitString(commits[i]),
+				commits[i-1].ShortHash(),
+			),
+			fileAnnotations,
+		); err != nil {
+			return err
+		}
+	}
+	if len(commits) < 2 {
+		return nil
+	}
+	stepFileAnnotationsErr := bufcli.GetFileAnnotationsError(
+		stepFileAnnotations,
+		failOn,
+		flags.MaxWarnings,
+		maxWarningsFlagName,
+	)
+	// Cumulative.
+	first, last := commits[0], commits[len(commits)-1]
+	fileAnnotations, err := checker.check(ctx, last, first)
+	if err != nil {
+		return err
+	}
+	if len(fileAnnotations) > 0 {
+		if err := printFileAnnotations(
+			fmt.Sprintf(
+				"Breaking changes in %s compared to %s (%s):",
+				commitString(last),
+				first.ShortHash(),
+				flags.Since,
+			),
+			fileAnnotations,
+		); err != nil {
+			return err
+		}
+		// Bisect to find the first commit that has breaking changes against the first commit.
+		// This assumes that once a commit breaks against the first commit, all later commits
+		// do as well, in the same manner as git bisect.
+		low, high := 1, len(commits)-1
+		for low < high {
+			mid := low + (high-low)/2
+			fileAnnotations, err := checker.check(ctx, commits[mid], first)
+			if err != nil {
+				return err
+			}
+			if len(fileAnnotations) > 0 {
+				high = mid
+			} else {
+				low = mid + 1
+			}
+		}
+		if _, err := fmt.Fprintf(
+			container.Stderr(),
+			"First commit with breaking changes compared to %s (%s): %s\n",
+			first.ShortHash(),
+			flags.Since,
+			commitString(commits[low]),
+		); err != nil {
+			return err
+		}
+	}
+	if stepFileAnnotationsErr != nil {
+		return stepFileAnnotationsErr
+	}
+	return bufcli.GetFileAnnotationsError(
+		fileAnnotations,
+		failOn,
+		flags.MaxWarnings,
+		maxWarningsFlagName,
+	)
+}
+
+// commitChecker checks commits against each other, caching the images of each
+// commit and the result of each check.
+type commitChecker struct {
+	getImageWithConfigs    func(context.Context, git.Commit) ([]bufctl.ImageWithConfig, error)
+	getFileAnnotations     func(context.Context, []bufctl.ImageWithConfig, []bufctl.ImageWithConfig) ([]bufanalysis.FileAnnotation, error)
+	hashToImageWithConfigs map[string][]bufctl.ImageWithConfig
+	hashPairToAnnotations  map[[2]string][]bufanalysis.FileAnnotation
+}
+
+func newCommitChecker(
+	getImageWithConfigs func(context.Context, git.Commit) ([]bufctl.ImageWithConfig, error),
+	getFileAnnotations func(context.Context, []bufctl.ImageWithConfig, []bufctl.ImageWithConfig) ([]bufanalysis.FileAnnotation, error),
+) *commitChecker {
+	return &commitChecker{
+		getImageWithConfigs:    getImageWithConfigs,
+		getFileAnnotations:     getFileAnnotations,
+		hashToImageWithConfigs: make(map[string][]bufctl.ImageWithConfig),
+		hashPairToAnnotations:  make(map[[2]string][]bufanalysis.FileAnnotation),
+	}
+}
+
+func (c *commitChecker) check(ctx context.Context, commit git.Commit, againstCommit git.Commit) ([]bufanalysis.FileAnnotation, error) {
+	key := [2]string{commit.Hash, againstCommit.Hash}
+	if fileAnnotations, ok := c.hashPairToAnnotations[key]; ok {
+		return fileAnnotations, nil
+	}
+	imageWithConfigs, err := c.getCommitImageWithConfigs(ctx, commit)
+	if err != nil {
+		return nil, err
+	}
+	againstImageWithConfigs, err := c.getCommitImageWithConfigs(ctx, againstCommit)
+	if err != nil {
+		return nil, err
+	}
+	fileAnnotations, err := c.getFileAnnotations(ctx, imageWithConfigs, againstImageWithConfigs)
+	if err != nil {
+		return nil, fmt.Errorf("commit %s: %w", commit.ShortHash(), err)
+	}
+	c.hashPairToAnnotations[key] = fileAnnotations
+	return fileAnnotations, nil
+}
+
+func (c *commitChecker) getCommitImageWithConfigs(ctx context.Context, commit git.Commit) ([]bufctl.ImageWithConfig, error) {
+	if imageWithConfigs, ok := c.hashToImageWithConfigs[commit.Hash]; ok {
+		return imageWithConfigs, nil
+	}
+	imageWithConfigs, err := c.getImageWithConfigs(ctx, commit)
+	if err != nil {
+		return nil, fmt.Errorf("commit %s: %w", commit.ShortHash(), err)
+	}
+	c.hashToImageWithConfigs[commit.Hash] = imageWithConfigs
+	return imageWithConfigs, nil
+}
+
+// getGitDirPathAndSubDirPath returns the path to the common git directory of the repository
+// containing dirPath, and the path of dirPath relative to the root of the working tree.
+//
+// The common git directory is used instead of the .git entry of the working tree, as this
+// is a file that points elsewhere for linked worktrees and submodules.
+func getGitDirPathAndSubDirPath(
+	ctx context.Context,
+	container appext.Container,
+	dirPath string,
+) (string, string, error) {
+	rootDirPath, err := git.GetRootDir(ctx, container, dirPath)
+	if err != nil {
+		return "", "", err
+	}
+	// Resolve symlinks on both sides, as git returns the resolved root directory.
+	rootDirPath, err = filepath.EvalSymlinks(rootDirPath)
+	if err != nil {
+		return "", "", err
+	}
+	absDirPath, err := filepath.Abs(dirPath)
+	if err != nil {
+		return "", "", err
+	}
+	absDirPath, err = filepath.EvalSymlinks(absDirPath)
+	if err != nil {
+		return "", "", err
+	}
+	subDirPath, err := filepath.Rel(rootDirPath, absDirPath)
+	if err != nil {
+		return "", "", err
+	}
+	gitDirPath, err := git.GetCommonDir(ctx, container, dirPath)
+	if err != nil {
+		return "", "", err
+	}
+	return gitDirPath, normalpath.Normalize(subDirPath), nil
+}
+
+func commitString(commit git.Commit) string {
+	return fmt.Sprintf("%s (%q)", commit.ShortHash(), commit.Subject)
+}
@@ -43,6 +43,8 @@ const (
 	excludePathsFlagName                  = "exclude-path"
 	disableSymlinksFlagName               = "disable-symlinks"
 	typeFlagName                          = "type"
+	excludeTypeFlagName                   = "exclude-type"
+	excludeOptionFlagName                 = "exclude-option"
 )
 
 // NewCommand returns a new Command.
@@ -77,6 +79,8 @@ type flags struct {
 	ExcludePaths                  []string
 	DisableSymlinks               bool
 	Types                         []string
+	ExcludeTypes                  []string
+	ExcludeOptions                []string
 	// special
 	InputHashtag string
 }
@@ -93,6 +97,8 @@ func (f *flags) Bind(flagSet *pflag.FlagSet) {
 	bufcli.BindPaths(flagSet, &f.Paths, pathsFlagName)
 	bufcli.BindExcludePaths(flagSet, &f.ExcludePaths, excludePathsFlagName)
 	bufcli.BindDisableSymlinks(flagSet, &f.DisableSymlinks, disableSymlinksFlagName)
+	bufcli.BindExcludeTypes(flagSet, &f.ExcludeTypes, excludeTypeFlagName)
+	bufcli.BindExcludeOptions(flagSet, &f.ExcludeOptions, excludeOptionFlagName)
 	flagSet.BoolVar(
 		&f.ExcludeSourceRetentionOptions,
 		excludeSourceRetentionOptionsFlagName,
@@ -114,7 +120,7 @@ func (f *flags) Bind(flagSet *pflag.FlagSet) {
 		outputFlagShortName,
 		app.DevNullFilePath,
 		fmt.Sprintf(
-			`The output location for the built image. Must be one of format %s`,
+			`The output location for the built image. Must be one of format %s, or dir with an explicit format, such as out#format=dir, to print the image as .proto files`,
 			buffetch.MessageFormatsString,
 		),
 	)
@@ -159,6 +165,8 @@ func run(
 		bufctl.WithImageExcludeSourceInfo(flags.ExcludeSourceInfo),
 		bufctl.WithImageExcludeImports(flags.ExcludeImports),
 		bufctl.WithImageTypes(flags.Types),
+		bufctl.WithImageExcludeTypes(flags.ExcludeTypes),
+		bufctl.WithImageExcludeOptions(flags.ExcludeOptions),
 		bufctl.WithConfigOverride(flags.Config),
 	)
 	if err != nil {
@@ -155,6 +155,7 @@ func run(
 			"",
 			// We actually want comment ignores enabled by default
 			true,
+			nil,
 		),
 		bufconfig.NewBreakingConfig(
 			bufconfig.NewEnabledCheckConfigForUseIDsAndCategories(
@@ -163,6 +164,8 @@ func run(
 				false,
 			),
 			false,
+			false,
+			false,
 		),
 	)
 	if err != nil {
@@ -0,0 +1,364 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configlsfiles
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/bufbuild/buf/private/buf/bufcli"
+	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
+	"github.com/bufbuild/buf/private/pkg/app"
+	"github.com/bufbuild/buf/private/pkg/app/appcmd"
+	"github.com/bufbuild/buf/private/pkg/app/appext"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/slicesext"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storageos"
+	"github.com/bufbuild/buf/private/pkg/syserror"
+	"github.com/spf13/pflag"
+)
+
+const (
+	configFlagName  = "config"
+	explainFlagName = "explain"
+)
+
+// NewCommand returns a new Command.
+func NewCommand(
+	name string,
+	builder appext.SubCommandBuilder,
+) *appcmd.Command {
+	flags := newFlags()
+	return &appcmd.Command{
+		Use:   name,
+		Short: "List the .proto files of configured modules",
+		Long: `This command lists every .proto file that is part of a module configured in the current
+directory, along with the module that claims it. A file that is claimed by more than one module is
+listed once per module.
+
+Use --explain <path> to report, for each configured module, whether the module claims the file at
+the path, and via which include, or why the file is excluded. This is useful for debugging
+overlapping modules, and lint failures such as PACKAGE_DIRECTORY_MATCH that depend on the path of a
+file within its module.
+
+Paths are relative to the current directory.`,
+		Args: appcmd.NoArgs,
+		Run: builder.NewRunFunc(
+			func(ctx context.Context, container appext.Container) error {
+				return run(ctx, container, flags)
+			},
+		),
+		BindFlags: flags.Bind,
+	}
+}
+
+type flags struct {
+	Config  string
+	Explain string
+}
+
+func newFlags() *flags {
+	return &flags{}
+}
+
+func (f *flags) Bind(flagSet *pflag.FlagSet) {
+	flagSet.StringVar(
+		&f.Config,
+		configFlagName,
+		"",
+		`The buf.yaml file or data to use for configuration.`,
+	)
+	flagSet.StringVar(
+		&f.Explain,
+		explainFlagName,
+		"",
+		`Explain which module claims the file at the given path, or why no module claims it.`,
+	)
+}
+
+func run(
+	ctx context.Context,
+	container appext.Container,
+	flags *flags,
+) error {
+	configuredModules, err := getConfiguredModules(ctx, flags.Config)
+	if err != nil {
+		return err
+	}
+	if flags.Explain != "" {
+		path, err := normalpath.NormalizeAndValidate(flags.Explain)
+		if err != nil {
+			return appcmd.NewInvalidArgumentErrorf("--%s: %v", explainFlagName, err)
+		}
+		return explainPath(container, configuredModules, path)
+	}
+	return listFiles(ctx, container, configuredModules)
+}
+
+func listFiles(
+	ctx context.Context,
+	container app.StdoutContainer,
+	configuredModules []*configuredModule,
+) error {
+	bucket, err := storageos.NewProvider(storageos.ProviderWithSymlinks()).NewReadWriteBucket(
+		".",
+		storageos.ReadWriteBucketWithSymlinksIfSupported(),
+	)
+	if err != nil {
+		return err
+	}
+	pathToModuleDisplayNames := make(map[string][]string)
+	for _, configuredModule := range configuredModules {
+		if err := storage.FilterReadBucket(bucket, storage.MatchPathExt(".proto")).Walk(
+			ctx,
+			configuredModule.externalDirPath(),
+			func(objectInfo storage.ObjectInfo) error {
+				path := objectInfo.Path()
+				explanation, err := configuredModule.explain(path)
+				if err != nil {
+					return err
+				}
+				if explanation.claimed {
+					pathToModuleDisplayNames[path] = append(pathToModuleDisplayNames[path], configuredModule.displayName())
+				}
+				return nil
+			},
+		); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	for _, path := range slicesext.MapKeysToSortedSlice(pathToModuleDisplayNames) {
+		for _, moduleDisplayName := range pathToModuleDisplayNames[path] {
+			if _, err := fmt.Fprintf(container.Stdout(), "%s\t%s\n", path, moduleDisplayName); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func explainPath(
+	container app.StdoutContainer,
+	configuredModules []*configuredModule,
+	path string,
+) error {
+	var lines []string
+	var claimingModuleDisplayNames []string
+	for _, configuredModule := range configuredModules {
+		explanation, err := configuredModule.explain(path)
+		if err != nil {
+			return err
+		}
+		if explanation.claimed {
+			claimingModuleDisplayNames = append(claimingModuleDisplayNames, configuredModule.displayName())
+			lines = append(
+				lines,
+				fmt.Sprintf("  module %s claims the file: %s", configuredModule.displayName(), explanation.reason),
+				fmt.Sprintf("    path within module: %s", explanation.moduleFilePath),
+			)
+		} else {
+			lines = append(
+				lines,
+				fmt.Sprintf("  module %s does not claim the file: %s", configuredModule.displayName(), explanation.reason),
+			)
+		}
+	}
+	switch len(claimingModuleDisplayNames) {
+	case 0:
+		lines = append(lines, "No configured module claims the file.")
+	case 1:
+		lines = append(lines, fmt.Sprintf("The file belongs to module %s.", claimingModuleDisplayNames[0]))
+	default:
+		lines = append(
+			lines,
+			fmt.Sprintf(
+				"The file is claimed by multiple modules: %s. A file may only belong to one module, adjust the includes or excludes of these modules so that exactly one module claims it.",
+				strings.Join(claimingModuleDisplayNames, ", "),
+			),
+		)
+	}
+	_, err := fmt.Fprintf(container.Stdout(), "%s:\n%s\n", path, strings.Join(lines, "\n"))
+	return err
+}
+
+func getConfiguredModules(
+	ctx context.Context,
+	configOverride string,
+) ([]*configuredModule, error) {
+	// If an override is specified, read buf.yaml from it.
+	if configOverride != "" {
+		bufYAMLFile, err := bufconfig.GetBufYAMLFileForOverride(configOverride)
+		if err != nil {
+			return nil, err
+		}
+		return getConfiguredModulesForBufYAMLFile(bufYAMLFile), nil
+	}
+	// First, look for a buf.work.yaml file.
+	bufWorkYAMLFile, err := bufcli.GetBufWorkYAMLFileForDirPath(ctx, ".")
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		// We do not have a buf.work.yaml file, attempt to read a buf.yaml file.
+		bufYAMLFile, err := bufcli.GetBufYAMLFileForDirPath(ctx, ".")
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				return nil, err
+			}
+			// We do not have a buf.work.yaml or buf.yaml file, use the default.
+			bufYAMLFile, err = bufconfig.NewBufYAMLFile(
+				bufconfig.FileVersionV2,
+				[]bufconfig.ModuleConfig{
+					bufconfig.DefaultModuleConfigV2,
+				},
+				nil,
+				nil,
+			)
+			if err != nil {
+				return nil, err
+			}
+		}
+		// This handles both buf.yaml file and no file courtesy of the above logic.
+		return getConfiguredModulesForBufYAMLFile(bufYAMLFile), nil
+	}
+	// We did have a buf.work.yaml file, but before handling it, check there is not a buf.yaml.
+	_, err = bufcli.GetBufYAMLFileForDirPath(ctx, ".")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		return nil, errors.New("Both buf.work.yaml and buf.yaml found. It is not valid to have a buf.work.yaml and buf.yaml in the same directory, buf.work.yaml specifies a workspace of modules, while buf.yaml either specifies a single module or a workspace of modules itself.")
+	}
+	// Handle the buf.work.yaml.
+	return getConfiguredModulesForBufWorkYAMLFile(ctx, bufWorkYAMLFile)
+}
+
+// This preserves directory order from the bufWorkYAMLFile.
+func getConfiguredModulesForBufWorkYAMLFile(
+	ctx context.Context,
+	bufWorkYAMLFile bufconfig.BufWorkYAMLFile,
+) ([]*configuredModule, error) {
+	var configuredModules []*configuredModule
+	for _, dirPath := range bufWorkYAMLFile.DirPaths() {
+		bufYAMLFile, err := bufcli.GetBufYAMLFileForDirPath(ctx, dirPath)
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				return nil, err
+			}
+			configuredModules = append(
+				configuredModules,
+				newConfiguredModule(dirPath, bufconfig.DefaultModuleConfigV1),
+			)
+			continue
+		}
+		// This is a sanity check. Make sure we have what we expect.
+		switch bufYAMLFile.FileVersion() {
+		case bufconfig.FileVersionV1Beta1, bufconfig.FileVersionV1:
+			moduleConfigs := bufYAMLFile.ModuleConfigs()
+			if len(moduleConfigs) != 1 {
+				return nil, syserror.Newf("got BufYAMLFile at %q with FileVersion %v with %d ModuleConfigs", dirPath, bufYAMLFile.FileVersion(), len(moduleConfigs))
+			}
+			moduleConfig := moduleConfigs[0]
+			if moduleConfig.DirPath() != "." {
+				return nil, syserror.Newf("got BufYAMLFile at %q with FileVersion %v with ModuleConfig that had non-root DirPath %q", dirPath, bufYAMLFile.FileVersion(), moduleConfig.DirPath())
+			}
+			configuredModules = append(
+				configuredModules,
+				// The dirPath is the path specified in the buf.work.yaml.
+				// The DirPath for v1/v1beta1 ModuleConfigs is always ".".
+				newConfiguredModule(dirPath, moduleConfig),
+			)
+		case bufconfig.FileVersionV2:
+			return nil, fmt.Errorf("buf.work.yaml pointed to directory %q which has a v2 buf.yaml file", dirPath)
+		default:
+			return nil, syserror.Newf("unknown FileVersion: %v", bufYAMLFile.FileVersion())
+		}
+	}
+	return configuredModules, nil
+}
+
+// This preserves module config order from the bufYAMLFile.
+func getConfiguredModulesForBufYAMLFile(bufYAMLFile bufconfig.BufYAMLFile) []*configuredModule {
+	return slicesext.Map(
+		bufYAMLFile.ModuleConfigs(),
+		func(moduleConfig bufconfig.ModuleConfig) *configuredModule {
+			return newConfiguredModule(".", moduleConfig)
+		},
+	)
+}
+
+type configuredModule struct {
+	// bufYAMLDirPath is the directory of the buf.yaml file that contains the ModuleConfig,
+	// relative to the current directory.
+	bufYAMLDirPath string
+	moduleConfig   bufconfig.ModuleConfig
+}
+
+func newConfiguredModule(bufYAMLDirPath string, moduleConfig bufconfig.ModuleConfig) *configuredModule {
+	return &configuredModule{
+		bufYAMLDirPath: bufYAMLDirPath,
+		moduleConfig:   moduleConfig,
+	}
+}
+
+// externalDirPath returns the directory of the module relative to the current directory.
+func (c *configuredModule) externalDirPath() string {
+	return normalpath.Join(c.bufYAMLDirPath, c.moduleConfig.DirPath())
+}
+
+func (c *configuredModule) displayName() string {
+	displayName := fmt.Sprintf("%q", c.externalDirPath())
+	if moduleFullName := c.moduleConfig.FullName(); moduleFullName != nil {
+		displayName += " (" + moduleFullName.String() + ")"
+	}
+	return displayName
+}
+
+// explain explains whether the module claims the path, which is relative to the current directory.
+func (c *configuredModule) explain(path string) (*explanation, error) {
+	if c.bufYAMLDirPath != "." && !normalpath.ContainsPath(c.bufYAMLDirPath, path, normalpath.Relative) {
+		return &explanation{
+			reason: fmt.Sprintf("not within the module directory %q", c.bufYAMLDirPath),
+		}, nil
+	}
+	relPath, err := normalpath.Rel(c.bufYAMLDirPath, path)
+	if err != nil {
+		return nil, err
+	}
+	moduleConfigPathExplanation, err := bufconfig.ExplainModuleConfigPath(c.moduleConfig, relPath)
+	if err != nil {
+		return nil, err
+	}
+	reason := moduleConfigPathExplanation.Reason()
+	if c.bufYAMLDirPath != "." {
+		// Includes and excludes are relative to the buf.yaml, which is not in the current directory.
+		reason = fmt.Sprintf("%s (relative to %q)", reason, normalpath.Join(c.bufYAMLDirPath, bufconfig.DefaultBufYAMLFileName))
+	}
+	return &explanation{
+		claimed:        moduleConfigPathExplanation.Claimed(),
+		moduleFilePath: moduleConfigPathExplanation.ModuleFilePath(),
+		reason:         reason,
+	}, nil
+}
+
+type explanation struct {
+	claimed        bool
+	moduleFilePath string
+	reason         string
+}
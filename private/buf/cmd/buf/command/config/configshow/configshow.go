@@ -0,0 +1,109 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configshow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/bufbuild/buf/private/buf/bufcli"
+	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
+	"github.com/bufbuild/buf/private/pkg/app/appcmd"
+	"github.com/bufbuild/buf/private/pkg/app/appext"
+	"github.com/spf13/pflag"
+)
+
+const (
+	configFlagName   = "config"
+	resolvedFlagName = "resolved"
+)
+
+// NewCommand returns a new Command.
+func NewCommand(
+	name string,
+	builder appext.SubCommandBuilder,
+) *appcmd.Command {
+	flags := newFlags()
+	return &appcmd.Command{
+		Use:   name + " <directory>",
+		Short: "Print the buf.yaml configuration",
+		Long: `Print the buf.yaml in the directory.
+
+With --resolved, the lint, breaking, and plugin settings of the files and modules that the
+buf.yaml extends are merged into the printed configuration. Extended modules must be pinned
+in buf.lock with buf dep update.
+
+The first argument is the directory of the buf.yaml.
+Defaults to "." if no argument is specified.`,
+		Args: appcmd.MaximumNArgs(1),
+		Run: builder.NewRunFunc(
+			func(ctx context.Context, container appext.Container) error {
+				return run(ctx, container, flags)
+			},
+		),
+		BindFlags: flags.Bind,
+	}
+}
+
+type flags struct {
+	Config   string
+	Resolved bool
+}
+
+func newFlags() *flags {
+	return &flags{}
+}
+
+func (f *flags) Bind(flagSet *pflag.FlagSet) {
+	flagSet.StringVar(
+		&f.Config,
+		configFlagName,
+		"",
+		`The buf.yaml file or data to use for configuration`,
+	)
+	flagSet.BoolVar(
+		&f.Resolved,
+		resolvedFlagName,
+		false,
+		"Print the configuration with the settings of the extended files and modules merged in",
+	)
+}
+
+func run(
+	ctx context.Context,
+	container appext.Container,
+	flags *flags,
+) error {
+	dirPath := "."
+	if container.NumArgs() > 0 {
+		dirPath = container.Arg(0)
+	}
+	var bufYAMLFile bufconfig.BufYAMLFile
+	var err error
+	if flags.Resolved {
+		bufYAMLFile, err = bufcli.GetResolvedBufYAMLFileForDirPathOrOverride(ctx, container, dirPath, flags.Config)
+	} else {
+		bufYAMLFile, err = bufcli.GetBufYAMLFileForDirPathOrOverride(ctx, dirPath, flags.Config)
+	}
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("no buf.yaml found in %q", dirPath)
+		}
+		return err
+	}
+	return bufconfig.WriteBufYAMLFile(container.Stdout(), bufYAMLFile)
+}
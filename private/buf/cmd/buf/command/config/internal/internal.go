@@ -166,7 +166,7 @@ func lsRun(
 	if flags.Version != "" {
 		configOverride = fmt.Sprintf(`{"version":"%s"}`, flags.Version)
 	}
-	bufYAMLFile, err := bufcli.GetBufYAMLFileForDirPathOrOverride(ctx, ".", configOverride)
+	bufYAMLFile, err := bufcli.GetResolvedBufYAMLFileForDirPathOrOverride(ctx, container, ".", configOverride)
 	if err != nil {
 		if !errors.Is(err, fs.ErrNotExist) {
 			return err
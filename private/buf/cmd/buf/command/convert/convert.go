@@ -23,6 +23,7 @@ import (
 	"github.com/bufbuild/buf/private/buf/bufcli"
 	"github.com/bufbuild/buf/private/buf/bufconvert"
 	"github.com/bufbuild/buf/private/buf/bufctl"
+	"github.com/bufbuild/buf/private/buf/bufcurl"
 	"github.com/bufbuild/buf/private/buf/buffetch"
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
@@ -31,7 +32,9 @@ import (
 	"github.com/bufbuild/buf/private/gen/data/datawkt"
 	"github.com/bufbuild/buf/private/pkg/app/appcmd"
 	"github.com/bufbuild/buf/private/pkg/app/appext"
+	"github.com/bufbuild/buf/private/pkg/protoencoding"
 	"github.com/bufbuild/buf/private/pkg/stringutil"
+	"github.com/bufbuild/buf/private/pkg/verbose"
 	"github.com/spf13/pflag"
 )
 
@@ -42,6 +45,11 @@ const (
 	toFlagName              = "to"
 	validateFlagName        = "validate"
 	disableSymlinksFlagName = "disable-symlinks"
+	schemaFlagName          = "schema"
+	reflectURLFlagName      = "reflect-url"
+	reflectHeaderFlagName   = "reflect-header"
+	reflectProtocolFlagName = "reflect-protocol"
+	resolveTypeURLsFlagName = "resolve-type-urls"
 )
 
 // NewCommand returns a new Command.
@@ -83,6 +91,19 @@ Redirect from stdin to <input>:
 Use a module on the bsr:
 
     $ buf convert <buf.build/owner/repository> --type buf.Foo --from=payload.json
+
+Decode a binary payload without a schema, printing it in the same format as "protoc --decode_raw":
+
+    $ buf convert --from payload.binpb#format=raw
+
+Types that are not part of <input>, such as the contents of google.protobuf.Any values or
+extensions in binary payloads, can be resolved from additional sources. These are consulted in
+order after <input>: first any "--schema" inputs, then a server reflection endpoint, and finally
+BSR modules referenced by type URLs:
+
+    $ buf convert <input> --type buf.Foo --from=payload.json --schema=buf.build/acme/weather
+    $ buf convert <input> --type buf.Foo --from=payload.json --reflect-url=https://api.acme.com
+    $ buf convert <input> --type buf.Foo --from=payload.json --resolve-type-urls
 `,
 		Args: appcmd.MaximumNArgs(1),
 		Run: builder.NewRunFunc(
@@ -101,6 +122,11 @@ type flags struct {
 	To              string
 	Validate        bool
 	DisableSymlinks bool
+	Schemas         []string
+	ReflectURL      string
+	ReflectHeaders  []string
+	ReflectProtocol string
+	ResolveTypeURLs bool
 
 	// special
 	InputHashtag string
@@ -133,7 +159,8 @@ func (f *flags) Bind(flagSet *pflag.FlagSet) {
 		fromFlagName,
 		"-",
 		fmt.Sprintf(
-			`The location of the payload to be converted. Supported formats are %s`,
+			`The location of the payload to be converted. Supported formats are %s.
+The raw format can be used to decode binary payloads without a schema`,
 			buffetch.MessageFormatsString,
 		),
 	)
@@ -155,6 +182,52 @@ func (f *flags) Bind(flagSet *pflag.FlagSet) {
 			fromFlagName,
 		),
 	)
+	flagSet.StringSliceVar(
+		&f.Schemas,
+		schemaFlagName,
+		nil,
+		`Additional inputs whose types are used to resolve elements that are not part of <input>,
+such as message types referenced by google.protobuf.Any values and extensions. The format of
+this argument is the same as for <input>. This flag may be specified more than once, in which
+case the inputs are consulted in order.`,
+	)
+	flagSet.StringVar(
+		&f.ReflectURL,
+		reflectURLFlagName,
+		"",
+		`The base URL of a server that supports gRPC server reflection, which is used to resolve
+elements that are not part of <input> or any --schema input. If the URL scheme is "http",
+HTTP/2 without TLS (h2c) is used.`,
+	)
+	flagSet.StringSliceVar(
+		&f.ReflectHeaders,
+		reflectHeaderFlagName,
+		nil,
+		fmt.Sprintf(
+			`Request headers to include with reflection requests, in the form "Name: Value". This flag
+may only be used when --%s is set. This flag may be specified more than once.`,
+			reflectURLFlagName,
+		),
+	)
+	flagSet.StringVar(
+		&f.ReflectProtocol,
+		reflectProtocolFlagName,
+		"",
+		fmt.Sprintf(
+			`The reflection protocol to use for downloading information from the server. This flag
+may only be used when --%s is set. By default, all known reflection protocols are tried from
+newest to oldest. Supported values are "grpc-v1" and "grpc-v1alpha".`,
+			reflectURLFlagName,
+		),
+	)
+	flagSet.BoolVar(
+		&f.ResolveTypeURLs,
+		resolveTypeURLsFlagName,
+		false,
+		`Resolve google.protobuf.Any type URLs whose prefix is a BSR module reference by fetching
+the referenced module. For example, the type URL "buf.build/acme/weather/acme.weather.v1.Units"
+is resolved using the module "buf.build/acme/weather".`,
+	)
 }
 
 func run(
@@ -174,6 +247,16 @@ func run(
 	if err != nil {
 		return err
 	}
+	fromMessageRef, err := buffetch.NewMessageRefParser(
+		container.Logger(),
+		buffetch.MessageRefParserWithDefaultMessageEncoding(buffetch.MessageEncodingBinpb),
+	).GetMessageRef(ctx, flags.From)
+	if err != nil {
+		return fmt.Errorf("--%s: %w", fromFlagName, err)
+	}
+	if fromMessageRef.MessageEncoding() == buffetch.MessageEncodingRaw {
+		return runRaw(ctx, container, controller, flags)
+	}
 	schemaImage, schemaImageErr := controller.GetImage(
 		ctx,
 		input,
@@ -207,6 +290,12 @@ func run(
 	if schemaImageErr != nil && schemaImage == nil {
 		return schemaImageErr
 	}
+	additionalResolvers, closeAdditionalResolvers, err := getAdditionalResolvers(ctx, controller, flags)
+	if err != nil {
+		return err
+	}
+	defer closeAdditionalResolvers()
+	schemaImage = bufconvert.ImageWithAdditionalResolvers(schemaImage, additionalResolvers...)
 	// We can't correctly convert anything that uses message-set wire
 	// format. So we prevent that by having the resolver return an error
 	// if asked to resolve any type that uses it.
@@ -242,6 +331,131 @@ func run(
 	return nil
 }
 
+// runRaw converts a payload that is decoded without a schema.
+//
+// The only supported output formats are raw, which prints the payload in the same manner
+// as protoc --decode_raw, and binpb.
+func runRaw(
+	ctx context.Context,
+	container appext.Container,
+	controller bufctl.Controller,
+	flags *flags,
+) error {
+	if flags.Type != "" {
+		return appcmd.NewInvalidArgumentErrorf("--%s cannot be used when --%s has format raw", typeFlagName, fromFlagName)
+	}
+	toMessageRef, err := buffetch.NewMessageRefParser(
+		container.Logger(),
+		buffetch.MessageRefParserWithDefaultMessageEncoding(buffetch.MessageEncodingRaw),
+	).GetMessageRef(ctx, flags.To)
+	if err != nil {
+		return fmt.Errorf("--%s: %w", toFlagName, err)
+	}
+	switch toMessageEncoding := toMessageRef.MessageEncoding(); toMessageEncoding {
+	case buffetch.MessageEncodingRaw, buffetch.MessageEncodingBinpb:
+	default:
+		return appcmd.NewInvalidArgumentErrorf(
+			"--%s must have format raw or binpb when --%s has format raw",
+			toFlagName,
+			fromFlagName,
+		)
+	}
+	fromMessage, _, err := controller.GetMessage(
+		ctx,
+		nil,
+		flags.From,
+		"",
+		buffetch.MessageEncodingBinpb,
+	)
+	if err != nil {
+		return fmt.Errorf("--%s: %w", fromFlagName, err)
+	}
+	if err := controller.PutMessage(
+		ctx,
+		nil,
+		flags.To,
+		fromMessage,
+		buffetch.MessageEncodingRaw,
+	); err != nil {
+		return fmt.Errorf("--%s: %w", toFlagName, err)
+	}
+	return nil
+}
+
+// getAdditionalResolvers returns the resolvers, in order, that are consulted for elements
+// that are not part of the schema image.
+//
+// The returned function must be called to release any resources held by the resolvers.
+func getAdditionalResolvers(
+	ctx context.Context,
+	controller bufctl.Controller,
+	flags *flags,
+) ([]protoencoding.Resolver, func(), error) {
+	if flags.ReflectURL == "" && (len(flags.ReflectHeaders) > 0 || flags.ReflectProtocol != "") {
+		return nil, nil, appcmd.NewInvalidArgumentErrorf(
+			"--%s and --%s may only be used when --%s is set",
+			reflectHeaderFlagName,
+			reflectProtocolFlagName,
+			reflectURLFlagName,
+		)
+	}
+	var resolvers []protoencoding.Resolver
+	for _, schema := range flags.Schemas {
+		image, err := controller.GetImage(ctx, schema)
+		if err != nil {
+			return nil, nil, fmt.Errorf("--%s: %w", schemaFlagName, err)
+		}
+		resolvers = append(resolvers, bufconvert.ImageWithoutMessageSetWireFormatResolution(image).Resolver())
+	}
+	closeResolvers := func() {}
+	if flags.ReflectURL != "" {
+		resolver, closeResolver, err := newServerReflectionResolver(ctx, flags)
+		if err != nil {
+			return nil, nil, fmt.Errorf("--%s: %w", reflectURLFlagName, err)
+		}
+		resolvers = append(resolvers, resolver)
+		closeResolvers = closeResolver
+	}
+	if flags.ResolveTypeURLs {
+		resolvers = append(
+			resolvers,
+			bufconvert.NewModuleTypeURLResolver(
+				ctx,
+				func(ctx context.Context, moduleRef string) (bufimage.Image, error) {
+					return controller.GetImage(ctx, moduleRef)
+				},
+			),
+		)
+	}
+	return resolvers, closeResolvers, nil
+}
+
+// newServerReflectionResolver returns a new Resolver that downloads descriptors from the
+// server at --reflect-url.
+func newServerReflectionResolver(
+	ctx context.Context,
+	flags *flags,
+) (protoencoding.Resolver, func(), error) {
+	reflectProtocol, err := bufcurl.ParseReflectProtocol(flags.ReflectProtocol)
+	if err != nil {
+		return nil, nil, err
+	}
+	reflectHeaders, _, err := bufcurl.LoadHeaders(flags.ReflectHeaders, "", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return bufcurl.NewServerReflectionResolverForURL(
+		ctx,
+		flags.ReflectURL,
+		bufcurl.ReflectClientSettings{
+			Protocol:   reflectProtocol,
+			Headers:    reflectHeaders,
+			BufVersion: bufcli.Version,
+		},
+		verbose.NopPrinter,
+	)
+}
+
 // inverseEncoding returns the opposite encoding of the provided encoding,
 // which will be the default output encoding for a given payload encoding.
 func inverseEncoding(encoding buffetch.MessageEncoding) (buffetch.MessageEncoding, error) {
@@ -400,6 +400,117 @@ func TestConvertWKTImport(t *testing.T) {
 	)
 }
 
+func TestConvertAnyWithSchema(t *testing.T) {
+	t.Parallel()
+	appcmdtesting.RunCommandExitCodeStdout(
+		t,
+		testNewCommand,
+		0,
+		`{"payload":{"@type":"type.googleapis.com/extra.Extra","name":"foo"}}`,
+		nil,
+		nil,
+		"testdata/convert/any/schema",
+		"--type=wrapper.Wrapper",
+		"--from=testdata/convert/any/payload.json",
+		"--to=-#format=json",
+		"--schema=testdata/convert/any/extra",
+	)
+}
+
+func TestConvertAnyWithoutSchema(t *testing.T) {
+	t.Parallel()
+	appcmdtesting.RunCommandExitCodeStderrContains(
+		t,
+		testNewCommand,
+		1,
+		[]string{`unable to resolve "type.googleapis.com/extra.Extra"`},
+		nil,
+		nil,
+		"testdata/convert/any/schema",
+		"--type=wrapper.Wrapper",
+		"--from=testdata/convert/any/payload.json",
+		"--to=-#format=json",
+	)
+}
+
+func TestConvertReflectHeaderWithoutReflectURL(t *testing.T) {
+	t.Parallel()
+	appcmdtesting.RunCommandExitCodeStderrContains(
+		t,
+		testNewCommand,
+		1,
+		[]string{"--reflect-header and --reflect-protocol may only be used when --reflect-url is set"},
+		nil,
+		nil,
+		"testdata/convert/any/schema",
+		"--type=wrapper.Wrapper",
+		"--from=testdata/convert/any/payload.json",
+		"--reflect-header=Authorization: foo",
+	)
+}
+
+func TestConvertRaw(t *testing.T) {
+	t.Parallel()
+	appcmdtesting.RunCommandExitCodeStdout(
+		t,
+		testNewCommand,
+		0,
+		`1: 55`,
+		nil,
+		nil,
+		"--from",
+		"testdata/convert/bin_json/payload.binpb#format=raw",
+	)
+}
+
+func TestConvertRawEmpty(t *testing.T) {
+	t.Parallel()
+	appcmdtesting.RunCommandExitCodeStdout(
+		t,
+		testNewCommand,
+		0,
+		``,
+		nil,
+		strings.NewReader(""),
+		"--from",
+		"-#format=raw",
+	)
+}
+
+func TestConvertRawToJSON(t *testing.T) {
+	t.Parallel()
+	appcmdtesting.RunCommandExitCodeStderrContains(
+		t,
+		testNewCommand,
+		1,
+		[]string{"--to must have format raw or binpb when --from has format raw"},
+		nil,
+		nil,
+		"--from",
+		"testdata/convert/bin_json/payload.binpb#format=raw",
+		"--to",
+		"-#format=json",
+	)
+}
+
+func TestConvertToRaw(t *testing.T) {
+	t.Parallel()
+	appcmdtesting.RunCommandExitCodeStdout(
+		t,
+		testNewCommand,
+		0,
+		`1: 55`,
+		nil,
+		nil,
+		"--type",
+		"buf.Foo",
+		"--from",
+		"testdata/convert/bin_json/payload.json",
+		"--to",
+		"-#format=raw",
+	)
+}
+
 func testNewCommand(use string) *appcmd.Command {
 	return NewCommand("convert", appext.NewBuilder("convert"))
 }
@@ -27,6 +27,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -95,9 +96,19 @@ const (
 	dataFlagShortName      = "d"
 
 	// Output flags
-	outputFlagName       = "output"
-	outputFlagShortName  = "o"
-	emitDefaultsFlagName = "emit-defaults"
+	outputFlagName          = "output"
+	outputFlagShortName     = "o"
+	emitDefaultsFlagName    = "emit-defaults"
+	emitFlagName            = "emit"
+	emitCredentialsFlagName = "emit-credentials"
+	invokeFlagName          = "invoke"
+
+	// Load testing flags
+	concurrencyFlagName  = "concurrency"
+	requestsFlagName     = "requests"
+	durationFlagName     = "duration"
+	rateFlagName         = "rate"
+	reportFormatFlagName = "report-format"
 
 	verboseFlagName      = "verbose"
 	verboseFlagShortName = "v"
@@ -180,6 +191,18 @@ headers and request data are both in a heredoc:
     {"sentence": "If you were a fish, what of fish would you be?."}
     EOM
 
+Print an equivalent curl command for an RPC instead of invoking it, so that it can be reproduced
+without buf:
+
+    $ buf curl --emit curl --data '{"sentence": "Hello"}'  \
+		 https://demo.connectrpc.com/connectrpc.eliza.v1.ElizaService/Say
+
+Invoke an RPC 1000 times, with 10 RPCs in progress at a time, and report latency percentiles,
+throughput, status codes and errors:
+
+    $ buf curl --requests 1000 --concurrency 10 --data '{"sentence": "Hello"}'  \
+		 https://demo.connectrpc.com/connectrpc.eliza.v1.ElizaService/Say
+
 Note that server reflection (i.e. use of the --reflect flag) does not work with HTTP 1.1 since the
 protocol relies on bidirectional streaming. If server reflection is used, the assumed URL for the
 reflection service is the same as the given URL, but with the last two elements removed and
@@ -236,8 +259,18 @@ type flags struct {
 	Data      string
 
 	// Output options
-	Output       string
-	EmitDefaults bool
+	Output          string
+	EmitDefaults    bool
+	Emit            string
+	EmitCredentials bool
+	Invoke          bool
+
+	// Load testing
+	Concurrency  int
+	Requests     int
+	Duration     time.Duration
+	Rate         float64
+	ReportFormat string
 
 	Verbose bool
 
@@ -523,6 +556,94 @@ provided via stdin as a file descriptor set or image`,
 		false,
 		`Emit default values for JSON-encoded responses.`,
 	)
+	flagSet.StringVar(
+		&f.Emit,
+		emitFlagName,
+		"",
+		fmt.Sprintf(`Print an equivalent invocation of the RPC instead of invoking it. The output
+includes the protocol, headers, TLS flags and request data. Must be one of %s.
+The value of the Authorization header is replaced with $BUF_TOKEN unless --%s
+is set. The "go" format prints a program that uses a connect-go generated client, so the
+schema must have go_package options. The "grpcurl" format requires --%s=%s,
+and the "http" format requires a unary RPC with --%s=%s`,
+			stringutil.SliceToString(bufcurl.AllEmitFormatStrings),
+			emitCredentialsFlagName,
+			protocolFlagName, connect.ProtocolGRPC,
+			protocolFlagName, connect.ProtocolConnect,
+		),
+	)
+	flagSet.BoolVar(
+		&f.EmitCredentials,
+		emitCredentialsFlagName,
+		false,
+		fmt.Sprintf(`Print the value of the Authorization header when --%s is set. By default, the value
+is replaced with a reference to the $BUF_TOKEN environment variable, so that credentials
+from --%s, a .netrc file or a --%s flag are not printed`,
+			emitFlagName,
+			userFlagName,
+			headerFlagName,
+		),
+	)
+	flagSet.BoolVar(
+		&f.Invoke,
+		invokeFlagName,
+		false,
+		fmt.Sprintf(`Invoke the RPC in addition to printing it when --%s is set. The printed
+invocation is written to stderr so that the response data is unaffected`,
+			emitFlagName,
+		),
+	)
+
+	flagSet.IntVar(
+		&f.Concurrency,
+		concurrencyFlagName,
+		1,
+		fmt.Sprintf(`The number of RPCs to invoke at the same time when load testing. This flag may only
+be used when --%s or --%s is set`,
+			requestsFlagName, durationFlagName,
+		),
+	)
+	flagSet.IntVar(
+		&f.Requests,
+		requestsFlagName,
+		0,
+		fmt.Sprintf(`Load test the RPC by invoking it this many times, and print a report instead of the
+responses. The report includes latency percentiles, throughput, status codes, errors and
+the bytes sent and received in request and response bodies. If --%s is also set, the
+load test stops at whichever limit is reached first`,
+			durationFlagName,
+		),
+	)
+	flagSet.DurationVar(
+		&f.Duration,
+		durationFlagName,
+		0,
+		fmt.Sprintf(`Load test the RPC by invoking it repeatedly for this long, such as "30s", and print a
+report instead of the responses. RPCs in progress at the end are allowed to complete.
+If --%s is also set, the load test stops at whichever limit is reached first`,
+			requestsFlagName,
+		),
+	)
+	flagSet.Float64Var(
+		&f.Rate,
+		rateFlagName,
+		0,
+		fmt.Sprintf(`The maximum number of RPCs to start per second when load testing, across all
+concurrent callers. There is no limit if this flag is not present. This flag may only
+be used when --%s or --%s is set`,
+			requestsFlagName, durationFlagName,
+		),
+	)
+	flagSet.StringVar(
+		&f.ReportFormat,
+		reportFormatFlagName,
+		"text",
+		fmt.Sprintf(`The format of the load test report. Must be one of %s. This flag may only be used
+when --%s or --%s is set`,
+			stringutil.SliceToString(bufcurl.AllLoadReportFormatStrings),
+			requestsFlagName, durationFlagName,
+		),
+	)
 
 	flagSet.BoolVarP(
 		&f.Verbose,
@@ -553,6 +674,60 @@ func (f *flags) validate(hasURL, isSecure bool) error {
 		return fmt.Errorf("flags --%s and --%s are mutually exclusive", listServicesFlagName, listMethodsFlagName)
 	}
 
+	if f.Emit != "" {
+		if _, err := bufcurl.ParseEmitFormat(f.Emit); err != nil {
+			return fmt.Errorf(
+				"--%s value must be one of %s",
+				emitFlagName,
+				stringutil.SliceToHumanStringOrQuoted(bufcurl.AllEmitFormatStrings),
+			)
+		}
+		if f.ListServices || f.ListMethods {
+			return fmt.Errorf("--%s cannot be used with --%s or --%s", emitFlagName, listServicesFlagName, listMethodsFlagName)
+		}
+	}
+	if f.Invoke && f.Emit == "" {
+		return fmt.Errorf("--%s may only be used when --%s is set", invokeFlagName, emitFlagName)
+	}
+	if f.EmitCredentials && f.Emit == "" {
+		return fmt.Errorf("--%s may only be used when --%s is set", emitCredentialsFlagName, emitFlagName)
+	}
+
+	if f.Requests < 0 {
+		return fmt.Errorf("--%s value must be positive", requestsFlagName)
+	}
+	if f.Duration < 0 || (f.Duration == 0 && f.flagSet.Changed(durationFlagName)) {
+		return fmt.Errorf("--%s value must be positive", durationFlagName)
+	}
+	if f.isLoadTest() {
+		if f.Concurrency < 1 {
+			return fmt.Errorf("--%s value must be positive", concurrencyFlagName)
+		}
+		if f.Rate < 0 || (f.Rate == 0 && f.flagSet.Changed(rateFlagName)) {
+			return fmt.Errorf("--%s value must be positive", rateFlagName)
+		}
+		if !slices.Contains(bufcurl.AllLoadReportFormatStrings, f.ReportFormat) {
+			return fmt.Errorf(
+				"--%s value must be one of %s",
+				reportFormatFlagName,
+				stringutil.SliceToHumanStringOrQuoted(bufcurl.AllLoadReportFormatStrings),
+			)
+		}
+		if f.ListServices || f.ListMethods || f.Emit != "" || f.Verbose {
+			return fmt.Errorf(
+				"--%s and --%s cannot be used with --%s, --%s, --%s or --%s",
+				requestsFlagName, durationFlagName,
+				listServicesFlagName, listMethodsFlagName, emitFlagName, verboseFlagName,
+			)
+		}
+	} else if f.flagSet.Changed(concurrencyFlagName) || f.flagSet.Changed(rateFlagName) || f.flagSet.Changed(reportFormatFlagName) {
+		return fmt.Errorf(
+			"load testing flags (--%s, --%s, --%s) may only be used when --%s or --%s is set",
+			concurrencyFlagName, rateFlagName, reportFormatFlagName,
+			requestsFlagName, durationFlagName,
+		)
+	}
+
 	if (f.Key != "" || f.Cert != "" || f.CACert != "" || f.ServerName != "" || f.flagSet.Changed(insecureFlagName)) &&
 		!isSecure {
 		return fmt.Errorf(
@@ -658,6 +833,10 @@ func (f *flags) validate(hasURL, isSecure bool) error {
 	return nil
 }
 
+func (f *flags) isLoadTest() bool {
+	return f.Requests > 0 || f.Duration > 0
+}
+
 func (f *flags) determineCredentials(
 	ctx context.Context,
 	container app.Container,
@@ -920,8 +1099,12 @@ func run(ctx context.Context, container appext.Container, f *flags) (err error)
 	if userAgent == "" {
 		userAgent = bufcurl.DefaultUserAgent(f.Protocol, bufcli.Version)
 	}
+	// The default user agent identifies buf, so it is not included when
+	// printing an equivalent invocation.
+	var userAgentDefaulted bool
 	if len(requestHeaders.Values("user-agent")) == 0 {
 		requestHeaders.Set("user-agent", userAgent)
+		userAgentDefaulted = f.UserAgent == ""
 	}
 	var basicCreds *string
 	if len(requestHeaders.Values("authorization")) == 0 {
@@ -1075,12 +1258,96 @@ func run(ctx context.Context, container appext.Container, f *flags) (err error)
 		if err != nil {
 			return err
 		}
+		if f.isLoadTest() {
+			transport, err := makeTransportOnce()
+			if err != nil {
+				return err
+			}
+			// The request data is read once and sent with every RPC.
+			var data []byte
+			if dataReader != nil {
+				data, err = io.ReadAll(dataReader)
+				if err != nil {
+					return bufcurl.ErrorHasFilename(err, dataSource)
+				}
+			}
+			loadTester := bufcurl.NewLoadTester(methodDescriptor, res, transport, clientOptions, urlArg)
+			report, err := loadTester.Run(
+				ctx,
+				bufcurl.LoadSettings{
+					Concurrency: f.Concurrency,
+					Requests:    f.Requests,
+					Duration:    f.Duration,
+					Rate:        f.Rate,
+				},
+				dataSource,
+				data,
+				requestHeaders,
+			)
+			if err != nil {
+				return err
+			}
+			return bufcurl.PrintLoadReport(output, report, f.ReportFormat)
+		}
+		var invokeData io.Reader = dataReader
+		if f.Emit != "" {
+			emitFormat, err := bufcurl.ParseEmitFormat(f.Emit)
+			if err != nil {
+				return err
+			}
+			emitOutput := output
+			emitData := invokeData
+			if f.Invoke {
+				emitOutput = container.Stderr()
+				if dataReader != nil {
+					// The request data is read twice, once to print it and once to send it.
+					data, err := io.ReadAll(dataReader)
+					if err != nil {
+						return bufcurl.ErrorHasFilename(err, dataSource)
+					}
+					emitData, invokeData = bytes.NewReader(data), bytes.NewReader(data)
+				}
+			}
+			emitHeaders := requestHeaders.Clone()
+			if userAgentDefaulted {
+				emitHeaders.Del("user-agent")
+			}
+			if err := bufcurl.Emit(
+				emitOutput,
+				emitFormat,
+				bufcurl.EmitSettings{
+					URL:        urlArg,
+					Protocol:   f.Protocol,
+					UnixSocket: f.UnixSocket,
+					TLS: bufcurl.TLSSettings{
+						KeyFile:             f.Key,
+						CertFile:            f.Cert,
+						CACertFile:          f.CACert,
+						ServerName:          f.ServerName,
+						Insecure:            f.Insecure,
+						HTTP2PriorKnowledge: f.HTTP2PriorKnowledge,
+						HTTP3:               f.HTTP3,
+					},
+					IncludeCredentials: f.EmitCredentials,
+				},
+				methodDescriptor,
+				res,
+				dataSource,
+				emitData,
+				emitHeaders,
+			); err != nil {
+				return err
+			}
+			if !f.Invoke {
+				return nil
+			}
+		}
 		transport, err := makeTransportOnce()
 		if err != nil {
 			return err
 		}
 		invoker := bufcurl.NewInvoker(container, verbosePrinter, methodDescriptor, res, f.EmitDefaults, transport, clientOptions, urlArg, output)
-		return invoker.Invoke(ctx, dataSource, dataReader, requestHeaders)
+		return invoker.Invoke(ctx, dataSource, invokeData, requestHeaders)
 	}
 }
 
@@ -1157,7 +1424,9 @@ func makeHTTPRoundTripper(f *flags, isSecure bool, authority string, printer ver
 			DialContext:       dialFunc,
 			DialTLSContext:    dialTLSFunc,
 			ForceAttemptHTTP2: true,
-			MaxIdleConns:      1,
+			// Keep a connection for each concurrent RPC when load testing.
+			MaxIdleConns:        max(f.Concurrency, 1),
+			MaxIdleConnsPerHost: max(f.Concurrency, 1),
 		}
 	}
 	return transport, nil
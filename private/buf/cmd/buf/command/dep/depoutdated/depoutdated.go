@@ -0,0 +1,294 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package depoutdated
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	modulev1 "buf.build/gen/go/bufbuild/registry/protocolbuffers/go/buf/registry/module/v1"
+	"connectrpc.com/connect"
+	"github.com/bufbuild/buf/private/buf/bufcli"
+	"github.com/bufbuild/buf/private/buf/bufprint"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufparse"
+	"github.com/bufbuild/buf/private/bufpkg/bufregistryapi/bufregistryapimodule"
+	"github.com/bufbuild/buf/private/pkg/app/appcmd"
+	"github.com/bufbuild/buf/private/pkg/app/appext"
+	"github.com/bufbuild/buf/private/pkg/slicesext"
+	"github.com/bufbuild/buf/private/pkg/syserror"
+	"github.com/bufbuild/buf/private/pkg/uuidutil"
+	"github.com/spf13/pflag"
+)
+
+const (
+	formatFlagName = "format"
+)
+
+// NewCommand returns a new Command.
+func NewCommand(
+	name string,
+	builder appext.SubCommandBuilder,
+) *appcmd.Command {
+	flags := newFlags()
+	return &appcmd.Command{
+		Use:   name + " <directory>",
+		Short: "List the pinned module dependencies in a buf.lock and their latest commits",
+		Long: `For each dependency pinned in buf.lock, print the pinned commit, the label it is
+resolved from, the latest commit on this label, and whether the module is deprecated.
+
+The label is the label configured for the dependency in buf.yaml. Dependencies that are not
+configured in buf.yaml, such as transitive dependencies, and dependencies configured without
+a label use the default label of the module. Dependencies configured with a commit have no
+label, and their latest commit is the configured commit.
+
+Run "buf dep update" to update the dependencies to their latest commits.
+
+The first argument is the directory of the local module to list the dependencies for.
+Defaults to "." if no argument is specified.`,
+		Args: appcmd.MaximumNArgs(1),
+		Run: builder.NewRunFunc(
+			func(ctx context.Context, container appext.Container) error {
+				return run(ctx, container, flags)
+			},
+		),
+		BindFlags: flags.Bind,
+	}
+}
+
+type flags struct {
+	Format string
+}
+
+func newFlags() *flags {
+	return &flags{}
+}
+
+func (f *flags) Bind(flagSet *pflag.FlagSet) {
+	flagSet.StringVar(
+		&f.Format,
+		formatFlagName,
+		bufprint.FormatText.String(),
+		fmt.Sprintf(`The output format to use. Must be one of %s`, bufprint.AllFormatsString),
+	)
+}
+
+func run(
+	ctx context.Context,
+	container appext.Container,
+	flags *flags,
+) error {
+	dirPath := "."
+	if container.NumArgs() > 0 {
+		dirPath = container.Arg(0)
+	}
+	format, err := bufprint.ParseFormat(flags.Format)
+	if err != nil {
+		return appcmd.WrapInvalidArgumentError(err)
+	}
+	controller, err := bufcli.NewController(container)
+	if err != nil {
+		return err
+	}
+	workspaceDepManager, err := controller.GetWorkspaceDepManager(ctx, dirPath)
+	if err != nil {
+		return err
+	}
+	depModuleKeys, err := workspaceDepManager.ExistingBufLockFileDepModuleKeys(ctx)
+	if err != nil {
+		return err
+	}
+	if len(depModuleKeys) == 0 {
+		return nil
+	}
+	configuredDepModuleRefs, err := workspaceDepManager.ConfiguredDepModuleRefs(ctx)
+	if err != nil {
+		return err
+	}
+	fullNameStringToConfiguredDepModuleRef, err := bufparse.FullNameStringToUniqueValue(configuredDepModuleRefs)
+	if err != nil {
+		return err
+	}
+	fullNameStringToModule, err := getFullNameStringToModule(ctx, container, depModuleKeys)
+	if err != nil {
+		return err
+	}
+	outdatedDeps := make([]*outdatedDep, len(depModuleKeys))
+	latestModuleRefs := make([]bufparse.Ref, len(depModuleKeys))
+	for i, depModuleKey := range depModuleKeys {
+		fullNameString := depModuleKey.FullName().String()
+		module, ok := fullNameStringToModule[fullNameString]
+		if !ok {
+			return syserror.Newf("no module returned for %s", fullNameString)
+		}
+		label := module.GetDefaultLabelName()
+		var latestModuleRef bufparse.Ref
+		configuredDepModuleRef, direct := fullNameStringToConfiguredDepModuleRef[fullNameString]
+		if direct && configuredDepModuleRef.Ref() != "" {
+			label = configuredDepModuleRef.Ref()
+			if _, err := uuidutil.FromDashless(label); err == nil {
+				// The dependency is configured with a commit, not a label.
+				label = ""
+			}
+			latestModuleRef = configuredDepModuleRef
+		} else {
+			latestModuleRef, err = bufparse.NewRef(
+				depModuleKey.FullName().Registry(),
+				depModuleKey.FullName().Owner(),
+				depModuleKey.FullName().Name(),
+				"",
+			)
+			if err != nil {
+				return err
+			}
+		}
+		latestModuleRefs[i] = latestModuleRef
+		outdatedDeps[i] = &outdatedDep{
+			Name:       fullNameString,
+			Pinned:     uuidutil.ToDashless(depModuleKey.CommitID()),
+			Label:      label,
+			Direct:     direct,
+			Deprecated: module.GetState() == modulev1.ModuleState_MODULE_STATE_DEPRECATED,
+		}
+	}
+	moduleKeyProvider, err := bufcli.NewModuleKeyProvider(container)
+	if err != nil {
+		return err
+	}
+	latestModuleKeys, err := moduleKeyProvider.GetModuleKeysForModuleRefs(
+		ctx,
+		latestModuleRefs,
+		workspaceDepManager.BufLockFileDigestType(),
+	)
+	if err != nil {
+		return err
+	}
+	for i, latestModuleKey := range latestModuleKeys {
+		outdatedDeps[i].Latest = uuidutil.ToDashless(latestModuleKey.CommitID())
+		outdatedDeps[i].Outdated = outdatedDeps[i].Latest != outdatedDeps[i].Pinned
+	}
+	return printOutdatedDeps(container, format, outdatedDeps)
+}
+
+type outdatedDep struct {
+	Name string `json:"name,omitempty"`
+	// Dashless
+	Pinned string `json:"pinned,omitempty"`
+	// Empty if the dependency is configured with a commit.
+	Label string `json:"label,omitempty"`
+	// Dashless
+	Latest     string `json:"latest,omitempty"`
+	Outdated   bool   `json:"outdated"`
+	Direct     bool   `json:"direct"`
+	Deprecated bool   `json:"deprecated"`
+}
+
+func printOutdatedDeps(container appext.Container, format bufprint.Format, outdatedDeps []*outdatedDep) error {
+	switch format {
+	case bufprint.FormatText:
+		return bufprint.WithTabWriter(
+			container.Stdout(),
+			[]string{
+				"Name",
+				"Pinned",
+				"Label",
+				"Latest",
+				"Outdated",
+				"Direct",
+				"Deprecated",
+			},
+			func(tabWriter bufprint.TabWriter) error {
+				for _, outdatedDep := range outdatedDeps {
+					if err := tabWriter.Write(
+						outdatedDep.Name,
+						outdatedDep.Pinned,
+						outdatedDep.Label,
+						outdatedDep.Latest,
+						strconv.FormatBool(outdatedDep.Outdated),
+						strconv.FormatBool(outdatedDep.Direct),
+						strconv.FormatBool(outdatedDep.Deprecated),
+					); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		)
+	case bufprint.FormatJSON:
+		for _, outdatedDep := range outdatedDeps {
+			if err := json.NewEncoder(container.Stdout()).Encode(outdatedDep); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return syserror.Newf("unknown format: %v", format)
+	}
+}
+
+// getFullNameStringToModule gets the BSR modules for the ModuleKeys, with one request per registry.
+func getFullNameStringToModule(
+	ctx context.Context,
+	container appext.Container,
+	moduleKeys []bufmodule.ModuleKey,
+) (map[string]*modulev1.Module, error) {
+	clientConfig, err := bufcli.NewConnectClientConfig(container)
+	if err != nil {
+		return nil, err
+	}
+	moduleClientProvider := bufregistryapimodule.NewClientProvider(clientConfig)
+	registryToModuleKeys := slicesext.ToValuesMap(
+		moduleKeys,
+		func(moduleKey bufmodule.ModuleKey) string {
+			return moduleKey.FullName().Registry()
+		},
+	)
+	fullNameStringToModule := make(map[string]*modulev1.Module, len(moduleKeys))
+	for registry, registryModuleKeys := range registryToModuleKeys {
+		response, err := moduleClientProvider.V1ModuleServiceClient(registry).GetModules(
+			ctx,
+			connect.NewRequest(
+				&modulev1.GetModulesRequest{
+					ModuleRefs: slicesext.Map(
+						registryModuleKeys,
+						func(moduleKey bufmodule.ModuleKey) *modulev1.ModuleRef {
+							return &modulev1.ModuleRef{
+								Value: &modulev1.ModuleRef_Name_{
+									Name: &modulev1.ModuleRef_Name{
+										Owner:  moduleKey.FullName().Owner(),
+										Module: moduleKey.FullName().Name(),
+									},
+								},
+							}
+						},
+					),
+				},
+			),
+		)
+		if err != nil {
+			return nil, err
+		}
+		if len(response.Msg.Modules) != len(registryModuleKeys) {
+			return nil, syserror.Newf("expected %d modules returned from server, got %d", len(registryModuleKeys), len(response.Msg.Modules))
+		}
+		// Modules are returned in the same order as the request.
+		for i, module := range response.Msg.Modules {
+			fullNameStringToModule[registryModuleKeys[i].FullName().String()] = module
+		}
+	}
+	return fullNameStringToModule, nil
+}
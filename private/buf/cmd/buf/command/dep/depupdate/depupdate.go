@@ -49,6 +49,8 @@ func NewCommand(
 		Long: `Fetch the latest digests for the specified module references in buf.yaml,
 and write them and their transitive dependencies to buf.lock.
 
+The modules extended by the buf.yaml are also pinned in buf.lock.
+
 The first argument is the directory of the local module to update.
 Defaults to "." if no argument is specified.`,
 		Args:       appcmd.MaximumNArgs(1),
@@ -125,12 +127,38 @@ func run(
 		slog.Any("deps", slicesext.Map(configuredDepModuleKeys, bufmodule.ModuleKey.String)),
 	)
 
+	configuredExtendsModuleRefs, err := workspaceDepManager.ConfiguredExtendsModuleRefs(ctx)
+	if err != nil {
+		return err
+	}
+	var configuredExtendsModuleKeys []bufmodule.ModuleKey
+	if len(configuredExtendsModuleRefs) > 0 {
+		moduleKeyProvider, err := bufcli.NewModuleKeyProvider(container)
+		if err != nil {
+			return err
+		}
+		// Extended modules are only used for their configuration, so their dependencies are not pinned.
+		configuredExtendsModuleKeys, err = moduleKeyProvider.GetModuleKeysForModuleRefs(
+			ctx,
+			configuredExtendsModuleRefs,
+			workspaceDepManager.BufLockFileDigestType(),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Store the existing buf.lock data.
 	existingDepModuleKeys, err := workspaceDepManager.ExistingBufLockFileDepModuleKeys(ctx)
 	if err != nil {
 		return err
 	}
-	if configuredDepModuleKeys == nil && existingDepModuleKeys == nil {
+	existingExtendsModuleKeys, err := workspaceDepManager.ExistingBufLockFileExtendsModuleKeys(ctx)
+	if err != nil {
+		return err
+	}
+	if configuredDepModuleKeys == nil && existingDepModuleKeys == nil &&
+		configuredExtendsModuleKeys == nil && existingExtendsModuleKeys == nil {
 		// No new configured deps were found, and no existing buf.lock deps were found, so there
 		// is nothing to update, we can return here.
 		// This ensures we do not create an empty buf.lock when one did not exist in the first
@@ -152,14 +180,20 @@ func run(
 	// overlay the new buf.lock file in a union bucket.
 	defer func() {
 		if retErr != nil {
-			retErr = errors.Join(retErr, workspaceDepManager.UpdateBufLockFile(ctx, existingDepModuleKeys, existingRemotePluginKeys))
+			retErr = errors.Join(retErr, workspaceDepManager.UpdateBufLockFile(ctx, existingDepModuleKeys, existingRemotePluginKeys, existingExtendsModuleKeys))
 		}
 	}()
 	// Edit the buf.lock file with the unpruned dependencies.
-	if err := workspaceDepManager.UpdateBufLockFile(ctx, configuredDepModuleKeys, existingRemotePluginKeys); err != nil {
+	if err := workspaceDepManager.UpdateBufLockFile(ctx, configuredDepModuleKeys, existingRemotePluginKeys, configuredExtendsModuleKeys); err != nil {
 		return err
 	}
-	workspace, err := controller.GetWorkspace(ctx, dirPath, bufctl.WithIgnoreAndDisallowV1BufWorkYAMLs())
+	workspace, err := controller.GetWorkspace(
+		ctx,
+		dirPath,
+		bufctl.WithIgnoreAndDisallowV1BufWorkYAMLs(),
+		// Replacements are never written to buf.lock files.
+		bufctl.WithIgnoreReplacements(),
+	)
 	if err != nil {
 		return err
 	}
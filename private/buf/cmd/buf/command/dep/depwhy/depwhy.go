@@ -0,0 +1,330 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package depwhy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bufbuild/buf/private/buf/bufcli"
+	"github.com/bufbuild/buf/private/buf/bufctl"
+	"github.com/bufbuild/buf/private/buf/bufprint"
+	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufparse"
+	"github.com/bufbuild/buf/private/pkg/app/appcmd"
+	"github.com/bufbuild/buf/private/pkg/app/appext"
+	"github.com/bufbuild/buf/private/pkg/slicesext"
+	"github.com/bufbuild/buf/private/pkg/stringutil"
+	"github.com/bufbuild/buf/private/pkg/syserror"
+	"github.com/spf13/pflag"
+)
+
+const (
+	errorFormatFlagName     = "error-format"
+	disableSymlinksFlagName = "disable-symlinks"
+	formatFlagName          = "format"
+)
+
+// NewCommand returns a new Command.
+func NewCommand(
+	name string,
+	builder appext.SubCommandBuilder,
+) *appcmd.Command {
+	flags := newFlags()
+	return &appcmd.Command{
+		Use:   name + " <module> <directory>",
+		Short: "Print why a module is a dependency",
+		Long: `Print the chains of modules from the modules in the workspace to the given module,
+and the chains of imports from the files in the workspace to the files in the given module.
+
+For each module in the workspace, the shortest chain of module dependencies is printed. For each
+file in the workspace, the shortest chain of imports is printed. Modules and files that do not
+depend on the given module are not printed.
+
+The first argument is the name of the module, such as "buf.build/acme/weather".
+
+The second argument is the directory of the workspace.
+Defaults to "." if no argument is specified.`,
+		Args: appcmd.RangeArgs(1, 2),
+		Run: builder.NewRunFunc(
+			func(ctx context.Context, container appext.Container) error {
+				return run(ctx, container, flags)
+			},
+		),
+		BindFlags: flags.Bind,
+	}
+}
+
+type flags struct {
+	ErrorFormat     string
+	DisableSymlinks bool
+	Format          string
+}
+
+func newFlags() *flags {
+	return &flags{}
+}
+
+func (f *flags) Bind(flagSet *pflag.FlagSet) {
+	bufcli.BindDisableSymlinks(flagSet, &f.DisableSymlinks, disableSymlinksFlagName)
+	flagSet.StringVar(
+		&f.ErrorFormat,
+		errorFormatFlagName,
+		"text",
+		fmt.Sprintf(
+			"The format for build errors printed to stderr. Must be one of %s",
+			stringutil.SliceToString(bufanalysis.AllFormatStrings),
+		),
+	)
+	flagSet.StringVar(
+		&f.Format,
+		formatFlagName,
+		bufprint.FormatText.String(),
+		fmt.Sprintf(`The output format to use. Must be one of %s`, bufprint.AllFormatsString),
+	)
+}
+
+func run(
+	ctx context.Context,
+	container appext.Container,
+	flags *flags,
+) error {
+	moduleFullName, err := bufparse.ParseFullName(container.Arg(0))
+	if err != nil {
+		return appcmd.WrapInvalidArgumentError(err)
+	}
+	dirPath := "."
+	if container.NumArgs() > 1 {
+		dirPath = container.Arg(1)
+	}
+	format, err := bufprint.ParseFormat(flags.Format)
+	if err != nil {
+		return appcmd.WrapInvalidArgumentError(err)
+	}
+	controller, err := bufcli.NewController(
+		container,
+		bufctl.WithDisableSymlinks(flags.DisableSymlinks),
+		bufctl.WithFileAnnotationErrorFormat(flags.ErrorFormat),
+	)
+	if err != nil {
+		return err
+	}
+	workspace, err := controller.GetWorkspace(ctx, dirPath)
+	if err != nil {
+		return err
+	}
+	dependencyModule := workspace.GetModuleForFullName(moduleFullName)
+	if dependencyModule == nil {
+		return fmt.Errorf("%s is not a dependency of the workspace at %q", moduleFullName.String(), dirPath)
+	}
+	if dependencyModule.IsTarget() {
+		return fmt.Errorf("%s is a module in the workspace at %q", moduleFullName.String(), dirPath)
+	}
+	graph, err := bufmodule.ModuleSetToDAG(workspace)
+	if err != nil {
+		return err
+	}
+	var moduleChains [][]string
+	for _, targetModule := range bufmodule.ModuleSetTargetModules(workspace) {
+		moduleChain, err := getShortestChain(
+			targetModule,
+			bufmodule.Module.OpaqueID,
+			func(module bufmodule.Module) ([]bufmodule.Module, error) {
+				return graph.OutboundNodes(module.OpaqueID())
+			},
+			func(module bufmodule.Module) bool {
+				return module.OpaqueID() == dependencyModule.OpaqueID()
+			},
+		)
+		if err != nil {
+			return err
+		}
+		if moduleChain != nil {
+			moduleChains = append(moduleChains, slicesext.Map(moduleChain, moduleToString))
+		}
+	}
+	image, err := controller.GetImageForWorkspace(
+		ctx,
+		workspace,
+		// This is a performance optimization - we don't need source code info.
+		bufctl.WithImageExcludeSourceInfo(true),
+	)
+	if err != nil {
+		return err
+	}
+	var importChains [][]externalFile
+	for _, imageFile := range image.Files() {
+		if imageFile.IsImport() {
+			continue
+		}
+		importChain, err := getShortestChain(
+			imageFile,
+			bufimage.ImageFile.Path,
+			func(imageFile bufimage.ImageFile) ([]bufimage.ImageFile, error) {
+				return getImportImageFiles(image, imageFile)
+			},
+			func(imageFile bufimage.ImageFile) bool {
+				imageFileFullName := imageFile.FullName()
+				return imageFileFullName != nil && imageFileFullName.String() == moduleFullName.String()
+			},
+		)
+		if err != nil {
+			return err
+		}
+		if importChain != nil {
+			importChains = append(importChains, slicesext.Map(importChain, newExternalFile))
+		}
+	}
+	// Image files are sorted topologically, print the chains by the path of the workspace file.
+	sort.SliceStable(
+		importChains,
+		func(i int, j int) bool {
+			return importChains[i][0].Path < importChains[j][0].Path
+		},
+	)
+	return printWhy(
+		container,
+		format,
+		&externalWhy{
+			Module:       moduleFullName.String(),
+			ModuleChains: moduleChains,
+			ImportChains: importChains,
+		},
+	)
+}
+
+type externalWhy struct {
+	Module       string           `json:"module,omitempty"`
+	ModuleChains [][]string       `json:"module_chains,omitempty"`
+	ImportChains [][]externalFile `json:"import_chains,omitempty"`
+}
+
+type externalFile struct {
+	Path string `json:"path,omitempty"`
+	// Empty for files in modules without a name.
+	Module string `json:"module,omitempty"`
+}
+
+func newExternalFile(imageFile bufimage.ImageFile) externalFile {
+	externalFile := externalFile{
+		Path: imageFile.Path(),
+	}
+	if moduleFullName := imageFile.FullName(); moduleFullName != nil {
+		externalFile.Module = moduleFullName.String()
+	}
+	return externalFile
+}
+
+func (e externalFile) String() string {
+	if e.Module == "" {
+		return e.Path
+	}
+	return fmt.Sprintf("%s (%s)", e.Path, e.Module)
+}
+
+func printWhy(container appext.Container, format bufprint.Format, externalWhy *externalWhy) error {
+	switch format {
+	case bufprint.FormatText:
+		var lines []string
+		if len(externalWhy.ModuleChains) > 0 {
+			lines = append(lines, "Module chains:")
+			for _, moduleChain := range externalWhy.ModuleChains {
+				lines = append(lines, "  "+strings.Join(moduleChain, " -> "))
+			}
+		}
+		if len(externalWhy.ImportChains) > 0 {
+			if len(lines) > 0 {
+				lines = append(lines, "")
+			}
+			lines = append(lines, "Import chains:")
+			for _, importChain := range externalWhy.ImportChains {
+				lines = append(lines, "  "+strings.Join(slicesext.Map(importChain, externalFile.String), " -> "))
+			}
+		} else {
+			if len(lines) > 0 {
+				lines = append(lines, "")
+			}
+			lines = append(lines, fmt.Sprintf("No files in the workspace import files from %s.", externalWhy.Module))
+		}
+		_, err := fmt.Fprintln(container.Stdout(), strings.Join(lines, "\n"))
+		return err
+	case bufprint.FormatJSON:
+		return json.NewEncoder(container.Stdout()).Encode(externalWhy)
+	default:
+		return syserror.Newf("unknown format: %v", format)
+	}
+}
+
+// getShortestChain returns the shortest chain from start to a value that matches, including
+// both start and the matching value, using a breadth-first search.
+//
+// Returns nil if no value matches.
+func getShortestChain[Key comparable, Value any](
+	start Value,
+	getKey func(Value) Key,
+	getNext func(Value) ([]Value, error),
+	matches func(Value) bool,
+) ([]Value, error) {
+	keyToPrevious := map[Key]*Value{getKey(start): nil}
+	queue := []Value{start}
+	for len(queue) > 0 {
+		value := queue[0]
+		queue = queue[1:]
+		if matches(value) {
+			chain := []Value{value}
+			for previous := keyToPrevious[getKey(value)]; previous != nil; previous = keyToPrevious[getKey(*previous)] {
+				chain = append([]Value{*previous}, chain...)
+			}
+			return chain, nil
+		}
+		nextValues, err := getNext(value)
+		if err != nil {
+			return nil, err
+		}
+		for _, nextValue := range nextValues {
+			if _, ok := keyToPrevious[getKey(nextValue)]; ok {
+				continue
+			}
+			keyToPrevious[getKey(nextValue)] = &value
+			queue = append(queue, nextValue)
+		}
+	}
+	return nil, nil
+}
+
+func getImportImageFiles(image bufimage.Image, imageFile bufimage.ImageFile) ([]bufimage.ImageFile, error) {
+	dependencies := imageFile.FileDescriptorProto().GetDependency()
+	importImageFiles := make([]bufimage.ImageFile, 0, len(dependencies))
+	for _, dependency := range dependencies {
+		importImageFile := image.GetFile(dependency)
+		if importImageFile == nil {
+			return nil, syserror.Newf("%s imports %s, which is not in the image", imageFile.Path(), dependency)
+		}
+		importImageFiles = append(importImageFiles, importImageFile)
+	}
+	return importImageFiles, nil
+}
+
+func moduleToString(module bufmodule.Module) string {
+	if moduleFullName := module.FullName(); moduleFullName != nil {
+		return moduleFullName.String()
+	}
+	return module.Description()
+}
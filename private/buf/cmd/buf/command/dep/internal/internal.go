@@ -71,7 +71,13 @@ func Prune(
 	workspaceDepManager bufworkspace.WorkspaceDepManager,
 	dirPath string,
 ) error {
-	workspace, err := controller.GetWorkspace(ctx, dirPath, bufctl.WithIgnoreAndDisallowV1BufWorkYAMLs())
+	workspace, err := controller.GetWorkspace(
+		ctx,
+		dirPath,
+		bufctl.WithIgnoreAndDisallowV1BufWorkYAMLs(),
+		// Replacements are never written to buf.lock files.
+		bufctl.WithIgnoreReplacements(),
+	)
 	if err != nil {
 		return err
 	}
@@ -110,7 +116,11 @@ func Prune(
 	if err != nil {
 		return err
 	}
-	return workspaceDepManager.UpdateBufLockFile(ctx, depModuleKeys, existingRemotePluginKeys)
+	existingExtendsModuleKeys, err := workspaceDepManager.ExistingBufLockFileExtendsModuleKeys(ctx)
+	if err != nil {
+		return err
+	}
+	return workspaceDepManager.UpdateBufLockFile(ctx, depModuleKeys, existingRemotePluginKeys, existingExtendsModuleKeys)
 }
 
 // LogUnusedConfiugredDepsForWorkspace takes a workspace and logs the unused configured
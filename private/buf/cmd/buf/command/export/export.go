@@ -22,6 +22,8 @@ import (
 
 	"github.com/bufbuild/buf/private/buf/bufcli"
 	"github.com/bufbuild/buf/private/buf/bufctl"
+	"github.com/bufbuild/buf/private/buf/bufformat"
+	"github.com/bufbuild/buf/private/bufpkg/bufimage/bufimageutil"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
 	"github.com/bufbuild/buf/private/gen/data/datawkt"
 	"github.com/bufbuild/buf/private/pkg/app/appcmd"
@@ -30,6 +32,8 @@ import (
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"github.com/bufbuild/buf/private/pkg/syserror"
 	"github.com/spf13/pflag"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
 )
 
 const (
@@ -40,6 +44,9 @@ const (
 	configFlagName          = "config"
 	excludePathsFlagName    = "exclude-path"
 	disableSymlinksFlagName = "disable-symlinks"
+	excludeTypeFlagName     = "exclude-type"
+	excludeOptionFlagName   = "exclude-option"
+	typeFlagName            = "type"
 )
 
 // NewCommand returns a new Command.
@@ -74,6 +81,16 @@ Export a specific version of a remote module to a local directory.
 Export a git repo to a local directory.
 
     $ buf export https://github.com/owner/repository.git --output=<output-dir>
+
+Export only the proto files, types and imports required to describe a service. Files that
+are affected by the filter are re-printed from their descriptors, retaining comments and
+options, and formatted as with buf format.
+
+    $ buf export . --type=acme.v1.UserService --output=<output-dir>
+
+Export proto files without internal types, fields and methods.
+
+    $ buf export . --exclude-type=acme.v1.internal --exclude-option="(acme.visibility) = INTERNAL" --output=<output-dir>
 `,
 		Args: appcmd.MaximumNArgs(1),
 		Run: builder.NewRunFunc(
@@ -92,6 +109,9 @@ type flags struct {
 	Config          string
 	ExcludePaths    []string
 	DisableSymlinks bool
+	Types           []string
+	ExcludeTypes    []string
+	ExcludeOptions  []string
 
 	// special
 	InputHashtag string
@@ -107,6 +127,14 @@ func (f *flags) Bind(flagSet *pflag.FlagSet) {
 	bufcli.BindExcludeImports(flagSet, &f.ExcludeImports, excludeImportsFlagName)
 	bufcli.BindPaths(flagSet, &f.Paths, pathsFlagName)
 	bufcli.BindExcludePaths(flagSet, &f.ExcludePaths, excludePathsFlagName)
+	flagSet.StringSliceVar(
+		&f.Types,
+		typeFlagName,
+		nil,
+		"The types (package, message, enum, extension, service, method) that should be exported. When specified, only the descriptors required to describe the requested types are exported, and files that are left empty are dropped",
+	)
+	bufcli.BindExcludeTypes(flagSet, &f.ExcludeTypes, excludeTypeFlagName)
+	bufcli.BindExcludeOptions(flagSet, &f.ExcludeOptions, excludeOptionFlagName)
 	flagSet.StringVarP(
 		&f.Output,
 		outputFlagName,
@@ -169,7 +197,8 @@ func run(
 	// that may not have resolved imports (https://github.com/bufbuild/buf/issues/3002).
 	// Thus we do not need to build the image, and instead we can return the non-import files
 	// from the workspace.
-	if flags.ExcludeImports {
+	hasFilters := len(flags.Types) > 0 || len(flags.ExcludeTypes) > 0 || len(flags.ExcludeOptions) > 0
+	if flags.ExcludeImports && !hasFilters {
 		if err := moduleReadBucket.WalkFileInfos(
 			ctx,
 			func(fileInfo bufmodule.FileInfo) error {
@@ -189,20 +218,55 @@ func run(
 		return nil
 	}
 
+	var originalFileDescriptors map[string]*descriptorpb.FileDescriptorProto
+	if hasFilters {
+		// The image is also built without filters, to determine which files were
+		// changed by filtering.
+		originalImage, err := controller.GetImageForWorkspace(ctx, workspace)
+		if err != nil {
+			return err
+		}
+		if len(originalImage.Files()) == 0 {
+			return errors.New("no .proto target files found")
+		}
+		originalFileDescriptors = make(map[string]*descriptorpb.FileDescriptorProto, len(originalImage.Files()))
+		for _, imageFile := range originalImage.Files() {
+			originalFileDescriptors[imageFile.Path()] = imageFile.FileDescriptorProto()
+		}
+	}
 	image, err := controller.GetImageForWorkspace(
 		ctx,
 		workspace,
-		bufctl.WithImageExcludeSourceInfo(true),
-		bufctl.WithImageExcludeImports(flags.ExcludeImports),
+		// Source code info is needed to retain comments when printing files
+		// that were changed by filtering.
+		bufctl.WithImageExcludeSourceInfo(!hasFilters),
+		bufctl.WithImageTypes(flags.Types),
+		bufctl.WithImageExcludeTypes(flags.ExcludeTypes),
+		bufctl.WithImageExcludeOptions(flags.ExcludeOptions),
 	)
 	if err != nil {
 		return err
 	}
-	imageFiles := image.Files()
-	if len(imageFiles) == 0 {
+	var formattedReadBucket storage.ReadBucket
+	if hasFilters {
+		image, err = bufimageutil.ImageWithoutEmptyFiles(image)
+		if err != nil {
+			return err
+		}
+		if len(image.Files()) == 0 {
+			return errors.New("no .proto files left after filtering")
+		}
+		formattedReadBucket, err = bufformat.FormatImage(ctx, image)
+		if err != nil {
+			return err
+		}
+	} else if len(image.Files()) == 0 {
 		return errors.New("no .proto target files found")
 	}
 	for _, imageFile := range image.Files() {
+		if flags.ExcludeImports && imageFile.IsImport() {
+			continue
+		}
 		moduleFile, err := moduleReadBucket.GetFile(ctx, imageFile.Path())
 		if err != nil {
 			if errors.Is(err, fs.ErrNotExist) && datawkt.Exists(imageFile.Path()) {
@@ -217,6 +281,17 @@ func run(
 			}
 			return syserror.Wrap(err)
 		}
+		if originalFileDescriptor, ok := originalFileDescriptors[imageFile.Path()]; ok && !proto.Equal(originalFileDescriptor, imageFile.FileDescriptorProto()) {
+			// The file was changed by filtering, so we print it from its descriptor
+			// instead of copying it.
+			if err := moduleFile.Close(); err != nil {
+				return err
+			}
+			if err := storage.CopyPath(ctx, formattedReadBucket, imageFile.Path(), readWriteBucket, imageFile.Path()); err != nil {
+				return err
+			}
+			continue
+		}
 		if err := storage.CopyReadObject(ctx, readWriteBucket, moduleFile); err != nil {
 			return errors.Join(err, moduleFile.Close())
 		}
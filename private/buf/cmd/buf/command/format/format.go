@@ -157,6 +157,10 @@ Write a diff and rewrite the file(s) in-place:
     ...
 
 The -w and -o flags cannot be used together in a single invocation.
+
+If a license header is configured with lint.license_header in buf.yaml, the license header is
+inserted or updated as the first comment of each file. Comments attached to the syntax or package
+declaration are kept in place.
 `,
 		Args: appcmd.MaximumNArgs(1),
 		Run: builder.NewRunFunc(
@@ -315,7 +319,15 @@ func run(
 		bufmodule.ModuleSetToModuleReadBucketWithOnlyProtoFilesForTargetModules(workspace),
 	)
 	originalReadBucket := bufmodule.ModuleReadBucketToStorageReadBucket(moduleReadBucket)
-	formattedReadBucket, err := bufformat.FormatBucket(ctx, originalReadBucket)
+	pathToLicenseHeaderTemplate, err := bufcli.GetPathToLicenseHeaderTemplate(ctx, workspace, moduleReadBucket)
+	if err != nil {
+		return err
+	}
+	formattedReadBucket, err := bufformat.FormatBucket(
+		ctx,
+		originalReadBucket,
+		bufformat.FormatWithLicenseHeaderTemplates(pathToLicenseHeaderTemplate),
+	)
 	if err != nil {
 		return err
 	}
@@ -16,6 +16,7 @@ package generate
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -33,6 +34,7 @@ import (
 	"github.com/bufbuild/buf/private/pkg/app/appext"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"github.com/bufbuild/buf/private/pkg/stringutil"
+	"github.com/bufbuild/buf/private/pkg/wasm"
 	"github.com/spf13/pflag"
 )
 
@@ -49,6 +51,8 @@ const (
 	excludePathsFlagName        = "exclude-path"
 	disableSymlinksFlagName     = "disable-symlinks"
 	typeFlagName                = "type"
+	excludeTypeFlagName         = "exclude-type"
+	excludeOptionFlagName       = "exclude-option"
 	typeDeprecatedFlagName      = "include-types"
 )
 
@@ -223,7 +227,7 @@ func NewCommand(
     # The inputs to generate code for.
     # The inputs here are ignored if an input is specified as a command line argument.
     # Each input is one of "directory", "git_repo", "module", "tarball", "zip_archive",
-    # "proto_file", "binary_image", "json_image", "text_image" and "yaml_image".
+    # "proto_file", "binary_image", "json_image", "text_image", "yaml_image" and "reflect".
     # Optional.
     inputs:
         # The path to a directory.
@@ -248,6 +252,12 @@ func NewCommand(
         types:
           - "foo.v1.User"
           - "foo.v1.UserService"
+        # Do not generate code for these types, or for the fields, extensions and
+        # methods that refer to them. Imports that are no longer needed are dropped.
+        # Optional.
+        exclude_types:
+          - "foo.v1.internal"
+          - "foo.v1.User.debug_info"
         # Only generate code for files in these paths.
         # If empty, include all paths.
         paths:
@@ -293,6 +303,10 @@ func NewCommand(
         # Optional.
         compression: gzip
 
+        # A running server that supports gRPC server reflection.
+        # Use "grpc+reflect://" for plaintext connections and "grpcs+reflect://" for TLS.
+      - reflect: grpcs+reflect://api.acme.com
+
 As an example, here's a typical "buf.gen.yaml" go and grpc, assuming
 "protoc-gen-go" and "protoc-gen-go-grpc" are on your "$PATH":
 
@@ -388,6 +402,8 @@ type flags struct {
 	// want to find out what will break if we do.
 	Types           []string
 	TypesDeprecated []string
+	ExcludeTypes    []string
+	ExcludeOptions  []string
 	// special
 	InputHashtag string
 }
@@ -401,6 +417,8 @@ func (f *flags) Bind(flagSet *pflag.FlagSet) {
 	bufcli.BindInputHashtag(flagSet, &f.InputHashtag)
 	bufcli.BindPaths(flagSet, &f.Paths, pathsFlagName)
 	bufcli.BindExcludePaths(flagSet, &f.ExcludePaths, excludePathsFlagName)
+	bufcli.BindExcludeTypes(flagSet, &f.ExcludeTypes, excludeTypeFlagName)
+	bufcli.BindExcludeOptions(flagSet, &f.ExcludeOptions, excludeOptionFlagName)
 	bindBoolPointer(
 		flagSet,
 		includeImportsFlagName,
@@ -517,6 +535,8 @@ func run(
 		flags.Paths,
 		flags.ExcludePaths,
 		flags.Types,
+		flags.ExcludeTypes,
+		flags.ExcludeOptions,
 	)
 	if err != nil {
 		return err
@@ -542,9 +562,19 @@ func run(
 			bufgen.GenerateWithIncludeWellKnownTypesOverride(*flags.IncludeWKTOverride),
 		)
 	}
+	wasmRuntimeCacheDir, err := bufcli.CreateWasmRuntimeCacheDir(container)
+	if err != nil {
+		return err
+	}
+	// The runtime is only created if a plugin is a WebAssembly module.
+	wasmRuntime := wasm.NewLazyRuntime(wasm.WithLocalCacheDir(wasmRuntimeCacheDir))
+	defer func() {
+		retErr = errors.Join(retErr, wasmRuntime.Close(ctx))
+	}()
 	return bufgen.NewGenerator(
 		logger,
 		storageosProvider,
+		wasmRuntime,
 		clientConfig,
 	).Generate(
 		ctx,
@@ -591,6 +621,8 @@ func getInputImages(
 	targetPathsOverride []string,
 	excludePathsOverride []string,
 	includeTypesOverride []string,
+	excludeTypesOverride []string,
+	excludeOptions []string,
 ) ([]bufimage.Image, error) {
 	// If input is specified on the command line, we use that. If input is not
 	// specified on the command line, use the default input.
@@ -612,6 +644,8 @@ func getInputImages(
 			bufctl.WithConfigOverride(moduleConfigOverride),
 			bufctl.WithTargetPaths(targetPathsOverride, excludePathsOverride),
 			bufctl.WithImageTypes(includeTypes),
+			bufctl.WithImageExcludeTypes(excludeTypesOverride),
+			bufctl.WithImageExcludeOptions(excludeOptions),
 		)
 		if err != nil {
 			return nil, err
@@ -634,12 +668,18 @@ func getInputImages(
 		if len(includeTypesOverride) > 0 {
 			includeTypes = includeTypesOverride
 		}
+		excludeTypes := inputConfig.ExcludeTypes()
+		if len(excludeTypesOverride) > 0 {
+			excludeTypes = excludeTypesOverride
+		}
 		inputImage, err := controller.GetImageForInputConfig(
 			ctx,
 			inputConfig,
 			bufctl.WithConfigOverride(moduleConfigOverride),
 			bufctl.WithTargetPaths(targetPaths, excludePaths),
 			bufctl.WithImageTypes(includeTypes),
+			bufctl.WithImageExcludeTypes(excludeTypes),
+			bufctl.WithImageExcludeOptions(excludeOptions),
 		)
 		if err != nil {
 			return nil, err
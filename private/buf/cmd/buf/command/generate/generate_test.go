@@ -170,6 +170,46 @@ func TestGenerateV2LocalPluginBasic(t *testing.T) {
 	require.Empty(t, string(diff))
 }
 
+func TestGenerateV2LocalWasmPlugin(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping test in short mode")
+	}
+
+	tempDirPath := t.TempDir()
+	input := filepath.Join("testdata", "v2", "local_plugin")
+	template := filepath.Join("testdata", "v2", "local_plugin", "buf.wasm.gen.yaml")
+
+	testRunSuccess(
+		t,
+		"--output",
+		tempDirPath,
+		"--template",
+		template,
+		input,
+	)
+
+	expected, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			filepath.Join("gen", "a", "v1", "a.top-level-type-names.yaml"): []byte(`messages:
+    - a.v1.Bar
+    - a.v1.Foo
+`),
+			filepath.Join("gen", "b", "v1", "b.top-level-type-names.yaml"): []byte(`messages:
+    - b.v1.Bar
+    - b.v1.Foo
+`),
+		},
+	)
+	require.NoError(t, err)
+	actual, err := storageos.NewProvider().NewReadWriteBucket(tempDirPath)
+	require.NoError(t, err)
+
+	diff, err := storage.DiffBytes(context.Background(), expected, actual)
+	require.NoError(t, err)
+	require.Empty(t, string(diff))
+}
+
 func TestGenerateV2LocalPluginTypes(t *testing.T) {
 	t.Parallel()
 	testRunTypeArgs := func(t *testing.T, expect map[string][]byte, args ...string) {
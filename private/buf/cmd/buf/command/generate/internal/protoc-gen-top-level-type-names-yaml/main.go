@@ -12,61 +12,98 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+// Package main implements a protoc plugin that writes the names of the top-level types of each file.
+//
+// This plugin does not use protoplugin so that it can also be built for GOOS=wasip1
+// to test WebAssembly plugins.
 package main
 
 import (
-	"context"
+	"io"
+	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 
-	"github.com/bufbuild/protoplugin"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
 	"gopkg.in/yaml.v3"
 )
 
 const fileExt = ".top-level-type-names.yaml"
 
 func main() {
-	protoplugin.Main(protoplugin.HandlerFunc(handle))
+	if err := run(os.Stdin, os.Stdout); err != nil {
+		_, _ = os.Stderr.WriteString(err.Error() + "\n")
+		os.Exit(1)
+	}
 }
 
-func handle(
-	_ context.Context,
-	_ protoplugin.PluginEnv,
-	responseWriter protoplugin.ResponseWriter,
-	request protoplugin.Request,
-) error {
-	fileDescriptors, err := request.FileDescriptorsToGenerate()
+func run(stdin io.Reader, stdout io.Writer) error {
+	data, err := io.ReadAll(stdin)
 	if err != nil {
 		return err
 	}
-	for _, fileDescriptor := range fileDescriptors {
+	request := &pluginpb.CodeGeneratorRequest{}
+	if err := proto.Unmarshal(data, request); err != nil {
+		return err
+	}
+	response, err := handle(request)
+	if err != nil {
+		return err
+	}
+	data, err = proto.Marshal(response)
+	if err != nil {
+		return err
+	}
+	_, err = stdout.Write(data)
+	return err
+}
+
+func handle(request *pluginpb.CodeGeneratorRequest) (*pluginpb.CodeGeneratorResponse, error) {
+	fileToGenerate := make(map[string]struct{}, len(request.GetFileToGenerate()))
+	for _, path := range request.GetFileToGenerate() {
+		fileToGenerate[path] = struct{}{}
+	}
+	response := &pluginpb.CodeGeneratorResponse{}
+	for _, fileDescriptorProto := range request.GetProtoFile() {
+		if _, ok := fileToGenerate[fileDescriptorProto.GetName()]; !ok {
+			continue
+		}
 		externalFile := &externalFile{}
-		enumDescriptors := fileDescriptor.Enums()
-		for i := 0; i < enumDescriptors.Len(); i++ {
-			externalFile.Enums = append(externalFile.Enums, string(enumDescriptors.Get(i).FullName()))
+		for _, enumDescriptorProto := range fileDescriptorProto.GetEnumType() {
+			externalFile.Enums = append(externalFile.Enums, fullName(fileDescriptorProto.GetPackage(), enumDescriptorProto.GetName()))
 		}
-		messageDescriptors := fileDescriptor.Messages()
-		for i := 0; i < messageDescriptors.Len(); i++ {
-			externalFile.Messages = append(externalFile.Messages, string(messageDescriptors.Get(i).FullName()))
+		for _, descriptorProto := range fileDescriptorProto.GetMessageType() {
+			externalFile.Messages = append(externalFile.Messages, fullName(fileDescriptorProto.GetPackage(), descriptorProto.GetName()))
 		}
-		serviceDescriptors := fileDescriptor.Services()
-		for i := 0; i < serviceDescriptors.Len(); i++ {
-			externalFile.Services = append(externalFile.Services, string(serviceDescriptors.Get(i).FullName()))
+		for _, serviceDescriptorProto := range fileDescriptorProto.GetService() {
+			externalFile.Services = append(externalFile.Services, fullName(fileDescriptorProto.GetPackage(), serviceDescriptorProto.GetName()))
 		}
 		sort.Strings(externalFile.Enums)
 		sort.Strings(externalFile.Messages)
 		sort.Strings(externalFile.Services)
 		data, err := yaml.Marshal(externalFile)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		responseWriter.AddFile(
-			strings.TrimSuffix(fileDescriptor.Path(), filepath.Ext(filepath.FromSlash(fileDescriptor.Path())))+fileExt,
-			string(data),
+		path := fileDescriptorProto.GetName()
+		response.File = append(
+			response.File,
+			&pluginpb.CodeGeneratorResponse_File{
+				Name:    proto.String(strings.TrimSuffix(path, filepath.Ext(filepath.FromSlash(path))) + fileExt),
+				Content: proto.String(string(data)),
+			},
 		)
 	}
-	return nil
+	return response, nil
+}
+
+func fullName(packageName string, name string) string {
+	if packageName == "" {
+		return name
+	}
+	return packageName + "." + name
 }
 
 type externalFile struct {
@@ -15,16 +15,22 @@
 package lint
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"os"
 
 	"github.com/bufbuild/buf/private/buf/bufcli"
 	"github.com/bufbuild/buf/private/buf/bufctl"
+	"github.com/bufbuild/buf/private/buf/buffetch"
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
 	"github.com/bufbuild/buf/private/pkg/app/appcmd"
 	"github.com/bufbuild/buf/private/pkg/app/appext"
+	"github.com/bufbuild/buf/private/pkg/licenseheader"
 	"github.com/bufbuild/buf/private/pkg/stringutil"
 	"github.com/bufbuild/buf/private/pkg/wasm"
 	"github.com/spf13/pflag"
@@ -36,6 +42,9 @@ const (
 	pathsFlagName           = "path"
 	excludePathsFlagName    = "exclude-path"
 	disableSymlinksFlagName = "disable-symlinks"
+	failOnFlagName          = "fail-on"
+	maxWarningsFlagName     = "max-warnings"
+	fixFlagName             = "fix"
 )
 
 // NewCommand returns a new Command.
@@ -64,6 +73,9 @@ type flags struct {
 	Paths           []string
 	ExcludePaths    []string
 	DisableSymlinks bool
+	FailOn          string
+	MaxWarnings     int
+	Fix             bool
 	// special
 	InputHashtag string
 }
@@ -77,6 +89,8 @@ func (f *flags) Bind(flagSet *pflag.FlagSet) {
 	bufcli.BindPaths(flagSet, &f.Paths, pathsFlagName)
 	bufcli.BindExcludePaths(flagSet, &f.ExcludePaths, excludePathsFlagName)
 	bufcli.BindDisableSymlinks(flagSet, &f.DisableSymlinks, disableSymlinksFlagName)
+	bufcli.BindFailOn(flagSet, &f.FailOn, failOnFlagName)
+	bufcli.BindMaxWarnings(flagSet, &f.MaxWarnings, maxWarningsFlagName)
 	flagSet.StringVar(
 		&f.ErrorFormat,
 		errorFormatFlagName,
@@ -92,6 +106,17 @@ func (f *flags) Bind(flagSet *pflag.FlagSet) {
 		"",
 		`The buf.yaml file or data to use for configuration`,
 	)
+	flagSet.BoolVar(
+		&f.Fix,
+		fixFlagName,
+		false,
+		fmt.Sprintf(
+			`Fix lint failures that can be fixed automatically by rewriting the files in-place before linting.
+Currently, only license headers configured with lint.license_header are fixed.
+The input must be a directory or proto file when using --%s`,
+			fixFlagName,
+		),
+	)
 }
 
 func run(
@@ -102,6 +127,10 @@ func run(
 	if err := bufcli.ValidateErrorFormatFlagLint(flags.ErrorFormat, errorFormatFlagName); err != nil {
 		return err
 	}
+	failOn, err := bufcli.ParseFailOnFlag(flags.FailOn, failOnFlagName)
+	if err != nil {
+		return err
+	}
 	// Parse out if this is config-ignore-yaml.
 	// This is messed.
 	controllerErrorFormat := flags.ErrorFormat
@@ -121,6 +150,11 @@ func run(
 	if err != nil {
 		return err
 	}
+	if flags.Fix {
+		if err := fixLicenseHeaders(ctx, container, controller, input, flags); err != nil {
+			return err
+		}
+	}
 	imageWithConfigs, err := controller.GetTargetImageWithConfigs(
 		ctx,
 		input,
@@ -186,7 +220,81 @@ func run(
 				return err
 			}
 		}
-		return bufctl.ErrFileAnnotation
+		return bufcli.GetFileAnnotationsError(
+			allFileAnnotationSet.FileAnnotations(),
+			failOn,
+			flags.MaxWarnings,
+			maxWarningsFlagName,
+		)
 	}
 	return nil
 }
+
+// fixLicenseHeaders inserts or updates the license headers of the target files of the input
+// in-place, according to the license headers configured for their Modules.
+func fixLicenseHeaders(
+	ctx context.Context,
+	container appext.Container,
+	controller bufctl.Controller,
+	input string,
+	flags *flags,
+) error {
+	// We can only rewrite files in-place if the input is a directory or proto file.
+	// See the similar logic for buf format -w.
+	if _, err := buffetch.NewDirOrProtoFileRefParser(container.Logger()).GetDirOrProtoFileRef(ctx, input); err != nil {
+		if errors.Is(err, buffetch.ErrModuleFormatDetectedForDirOrProtoFileRef) {
+			return appcmd.NewInvalidArgumentErrorf("invalid input %q when using --%s: must be a directory or proto file", input, fixFlagName)
+		}
+		return appcmd.NewInvalidArgumentErrorf("invalid input %q when using --%s: %v", input, fixFlagName, err)
+	}
+	workspace, err := controller.GetWorkspace(
+		ctx,
+		input,
+		bufctl.WithTargetPaths(flags.Paths, flags.ExcludePaths),
+		bufctl.WithConfigOverride(flags.Config),
+	)
+	if err != nil {
+		return err
+	}
+	moduleReadBucket := bufmodule.ModuleReadBucketWithOnlyTargetFiles(
+		bufmodule.ModuleSetToModuleReadBucketWithOnlyProtoFilesForTargetModules(workspace),
+	)
+	pathToLicenseHeaderTemplate, err := bufcli.GetPathToLicenseHeaderTemplate(ctx, workspace, moduleReadBucket)
+	if err != nil {
+		return err
+	}
+	for path, licenseHeaderTemplate := range pathToLicenseHeaderTemplate {
+		if err := fixLicenseHeader(ctx, moduleReadBucket, path, licenseHeaderTemplate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fixLicenseHeader(
+	ctx context.Context,
+	moduleReadBucket bufmodule.ModuleReadBucket,
+	path string,
+	licenseHeaderTemplate licenseheader.Template,
+) (retErr error) {
+	file, err := moduleReadBucket.GetFile(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		retErr = errors.Join(retErr, file.Close())
+	}()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+	fixedData, err := licenseHeaderTemplate.Modify(path, data)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(data, fixedData) {
+		return nil
+	}
+	// We rely on the external path being writable, as buf format -w does.
+	return os.WriteFile(file.ExternalPath(), fixedData, 0644)
+}
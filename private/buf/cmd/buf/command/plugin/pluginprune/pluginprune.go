@@ -102,5 +102,10 @@ func prune(
 	if err != nil {
 		return err
 	}
-	return workspaceDepManager.UpdateBufLockFile(ctx, existingDepModuleKeys, prunedBufLockPluginKeys)
+	// We keep the existing extends module keys as-is.
+	existingExtendsModuleKeys, err := workspaceDepManager.ExistingBufLockFileExtendsModuleKeys(ctx)
+	if err != nil {
+		return err
+	}
+	return workspaceDepManager.UpdateBufLockFile(ctx, existingDepModuleKeys, prunedBufLockPluginKeys, existingExtendsModuleKeys)
 }
@@ -131,6 +131,10 @@ func run(
 	if err != nil {
 		return err
 	}
+	existingExtendsModuleKeys, err := workspaceDepManager.ExistingBufLockFileExtendsModuleKeys(ctx)
+	if err != nil {
+		return err
+	}
 
 	// We're about to edit the buf.lock file on disk. If we have a subsequent error,
 	// attempt to revert the buf.lock file.
@@ -140,11 +144,11 @@ func run(
 	// overlay the new buf.lock file in a union bucket.
 	defer func() {
 		if retErr != nil {
-			retErr = errors.Join(retErr, workspaceDepManager.UpdateBufLockFile(ctx, existingDepModuleKeys, existingRemotePluginKeys))
+			retErr = errors.Join(retErr, workspaceDepManager.UpdateBufLockFile(ctx, existingDepModuleKeys, existingRemotePluginKeys, existingExtendsModuleKeys))
 		}
 	}()
 	// Edit the buf.lock file with the updated remote plugins.
-	if err := workspaceDepManager.UpdateBufLockFile(ctx, existingDepModuleKeys, configuredRemotePluginKeys); err != nil {
+	if err := workspaceDepManager.UpdateBufLockFile(ctx, existingDepModuleKeys, configuredRemotePluginKeys, existingExtendsModuleKeys); err != nil {
 		return err
 	}
 	return nil
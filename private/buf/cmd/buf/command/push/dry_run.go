@@ -0,0 +1,350 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"sort"
+
+	"github.com/bufbuild/buf/private/buf/bufcli"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufparse"
+	"github.com/bufbuild/buf/private/pkg/app/appext"
+	"github.com/bufbuild/buf/private/pkg/slicesext"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
+	"github.com/bufbuild/buf/private/pkg/uuidutil"
+)
+
+// runDryRun prints what a push of the workspace with the UploadOptions would change on the
+// registry, without writing anything.
+//
+// The content of every Module that would be pushed is compared against the current commit
+// of the target label by b5 Digest. The target label is the first label of the push, or the
+// default label of the Module if no labels are set. Only Modules with a different Digest would
+// get a new commit.
+func runDryRun(
+	ctx context.Context,
+	container appext.Container,
+	workspace bufmodule.ModuleSet,
+	uploadOptions []bufmodule.UploadOption,
+	printDiff bool,
+) error {
+	options, err := bufmodule.NewUploadOptions(uploadOptions)
+	if err != nil {
+		return err
+	}
+	contentModules, excludedModules, err := bufmodule.UploadContentModules(workspace, options)
+	if err != nil {
+		return err
+	}
+	for _, excludedModule := range excludedModules {
+		container.Logger().Warn("Excluding unnamed module", slog.String("module", excludedModule.Description()))
+	}
+	if len(contentModules) == 0 {
+		return nil
+	}
+	var label string
+	if labels := options.Labels(); len(labels) > 0 {
+		label = labels[0]
+	}
+	moduleKeyProvider, err := bufcli.NewModuleKeyProvider(container)
+	if err != nil {
+		return err
+	}
+	moduleDataProvider, err := bufcli.NewModuleDataProvider(container)
+	if err != nil {
+		return err
+	}
+	dryRunModules := make([]*dryRunModule, len(contentModules))
+	for i, contentModule := range contentModules {
+		dryRunModule, err := newDryRunModule(ctx, moduleKeyProvider, moduleDataProvider, contentModule, label)
+		if err != nil {
+			return err
+		}
+		dryRunModules[i] = dryRunModule
+	}
+	opaqueIDToDryRunModule := make(map[string]*dryRunModule, len(dryRunModules))
+	for _, dryRunModule := range dryRunModules {
+		opaqueIDToDryRunModule[dryRunModule.module.OpaqueID()] = dryRunModule
+	}
+	buffer := bytes.NewBuffer(nil)
+	for _, dryRunModule := range dryRunModules {
+		if err := dryRunModule.print(ctx, buffer, opaqueIDToDryRunModule, label, printDiff); err != nil {
+			return err
+		}
+	}
+	_, err = container.Stdout().Write(buffer.Bytes())
+	return err
+}
+
+// dryRunModule is a Module that would be pushed, and the current commit of its target label.
+type dryRunModule struct {
+	module bufmodule.Module
+	// currentModuleKey and currentModuleData are nil if the Module or the label does not exist.
+	currentModuleKey  bufmodule.ModuleKey
+	currentModuleData bufmodule.ModuleData
+	// changed is true if a push would create a new commit for the Module.
+	changed bool
+}
+
+func newDryRunModule(
+	ctx context.Context,
+	moduleKeyProvider bufmodule.ModuleKeyProvider,
+	moduleDataProvider bufmodule.ModuleDataProvider,
+	module bufmodule.Module,
+	label string,
+) (*dryRunModule, error) {
+	moduleRef, err := bufparse.NewRef(
+		module.FullName().Registry(),
+		module.FullName().Owner(),
+		module.FullName().Name(),
+		label,
+	)
+	if err != nil {
+		return nil, err
+	}
+	// One request per Module, as a Module or label that does not exist fails the whole request.
+	moduleKeys, err := moduleKeyProvider.GetModuleKeysForModuleRefs(ctx, []bufparse.Ref{moduleRef}, bufmodule.DigestTypeB5)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return &dryRunModule{
+				module:  module,
+				changed: true,
+			}, nil
+		}
+		return nil, err
+	}
+	if len(moduleKeys) != 1 {
+		return nil, fmt.Errorf("expected 1 ModuleKey for %s, got %d", moduleRef, len(moduleKeys))
+	}
+	currentModuleKey := moduleKeys[0]
+	currentDigest, err := currentModuleKey.Digest()
+	if err != nil {
+		return nil, err
+	}
+	digest, err := module.Digest(bufmodule.DigestTypeB5)
+	if err != nil {
+		return nil, err
+	}
+	moduleDatas, err := moduleDataProvider.GetModuleDatasForModuleKeys(ctx, moduleKeys)
+	if err != nil {
+		return nil, err
+	}
+	if len(moduleDatas) != 1 {
+		return nil, fmt.Errorf("expected 1 ModuleData for %s, got %d", moduleRef, len(moduleDatas))
+	}
+	return &dryRunModule{
+		module:            module,
+		currentModuleKey:  currentModuleKey,
+		currentModuleData: moduleDatas[0],
+		changed:           !bufmodule.DigestEqual(currentDigest, digest),
+	}, nil
+}
+
+func (d *dryRunModule) print(
+	ctx context.Context,
+	writer io.Writer,
+	opaqueIDToDryRunModule map[string]*dryRunModule,
+	label string,
+	printDiff bool,
+) error {
+	labelDescription := "the default label"
+	if label != "" {
+		labelDescription = fmt.Sprintf("label %q", label)
+	}
+	moduleFullName := d.module.FullName().String()
+	switch {
+	case d.currentModuleKey == nil:
+		_, err := fmt.Fprintf(writer, "%s: new commit, no current commit on %s\n", moduleFullName, labelDescription)
+		if err != nil {
+			return err
+		}
+	case !d.changed:
+		_, err := fmt.Fprintf(
+			writer,
+			"%s: no changes, current commit %s on %s\n",
+			moduleFullName,
+			uuidutil.ToDashless(d.currentModuleKey.CommitID()),
+			labelDescription,
+		)
+		return err
+	default:
+		_, err := fmt.Fprintf(
+			writer,
+			"%s: new commit, current commit %s on %s\n",
+			moduleFullName,
+			uuidutil.ToDashless(d.currentModuleKey.CommitID()),
+			labelDescription,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	currentBucket, err := d.getCurrentBucket()
+	if err != nil {
+		return err
+	}
+	bucket := bufmodule.ModuleReadBucketToStorageReadBucket(d.module)
+	fileChanges, err := getFileChanges(ctx, currentBucket, bucket)
+	if err != nil {
+		return err
+	}
+	if len(fileChanges) > 0 {
+		if _, err := fmt.Fprintln(writer, "  files:"); err != nil {
+			return err
+		}
+		for _, fileChange := range fileChanges {
+			if _, err := fmt.Fprintf(writer, "    %s\n", fileChange); err != nil {
+				return err
+			}
+		}
+	}
+	depChanges, err := d.getDepChanges(opaqueIDToDryRunModule)
+	if err != nil {
+		return err
+	}
+	if len(depChanges) > 0 {
+		if _, err := fmt.Fprintln(writer, "  deps:"); err != nil {
+			return err
+		}
+		for _, depChange := range depChanges {
+			if _, err := fmt.Fprintf(writer, "    %s\n", depChange); err != nil {
+				return err
+			}
+		}
+	}
+	if printDiff && len(fileChanges) > 0 {
+		return storage.Diff(
+			ctx,
+			writer,
+			currentBucket,
+			bucket,
+			storage.DiffWithSuppressCommands(),
+			storage.DiffWithSuppressTimestamps(),
+		)
+	}
+	return nil
+}
+
+// getCurrentBucket returns the files of the current commit, or an empty bucket if there is no current commit.
+func (d *dryRunModule) getCurrentBucket() (storage.ReadBucket, error) {
+	if d.currentModuleData == nil {
+		return storagemem.NewReadWriteBucket(), nil
+	}
+	return d.currentModuleData.Bucket()
+}
+
+// getDepChanges returns the dependencies added, removed or changed against the current commit.
+//
+// Dependencies are compared by commit. A local dependency is pushed along with the Module, and
+// has the commit of the current commit of its target label if its content is unchanged.
+func (d *dryRunModule) getDepChanges(opaqueIDToDryRunModule map[string]*dryRunModule) ([]string, error) {
+	currentDepFullNameToCommit := make(map[string]string)
+	if d.currentModuleData != nil {
+		currentDepModuleKeys, err := d.currentModuleData.DeclaredDepModuleKeys()
+		if err != nil {
+			return nil, err
+		}
+		for _, currentDepModuleKey := range currentDepModuleKeys {
+			currentDepFullNameToCommit[currentDepModuleKey.FullName().String()] = uuidutil.ToDashless(currentDepModuleKey.CommitID())
+		}
+	}
+	moduleDeps, err := d.module.ModuleDeps()
+	if err != nil {
+		return nil, err
+	}
+	depFullNameToCommit := make(map[string]string, len(moduleDeps))
+	for _, moduleDep := range moduleDeps {
+		commit := "<new commit>"
+		if moduleDep.IsLocal() {
+			if depDryRunModule, ok := opaqueIDToDryRunModule[moduleDep.OpaqueID()]; ok && !depDryRunModule.changed {
+				commit = uuidutil.ToDashless(depDryRunModule.currentModuleKey.CommitID())
+			}
+		} else {
+			commit = uuidutil.ToDashless(moduleDep.CommitID())
+		}
+		// All dependencies are named, this is validated by UploadContentModules.
+		depFullNameToCommit[moduleDep.FullName().String()] = commit
+	}
+	var depChanges []string
+	for depFullName, commit := range depFullNameToCommit {
+		currentCommit, ok := currentDepFullNameToCommit[depFullName]
+		switch {
+		case !ok:
+			depChanges = append(depChanges, fmt.Sprintf("+ %s:%s", depFullName, commit))
+		case currentCommit != commit:
+			depChanges = append(depChanges, fmt.Sprintf("~ %s:%s -> %s", depFullName, currentCommit, commit))
+		}
+	}
+	for currentDepFullName, currentCommit := range currentDepFullNameToCommit {
+		if _, ok := depFullNameToCommit[currentDepFullName]; !ok {
+			depChanges = append(depChanges, fmt.Sprintf("- %s:%s", currentDepFullName, currentCommit))
+		}
+	}
+	sort.Slice(depChanges, func(i int, j int) bool { return depChanges[i][2:] < depChanges[j][2:] })
+	return depChanges, nil
+}
+
+// getFileChanges returns the files added (A), deleted (D) and modified (M) between the buckets,
+// sorted by path.
+func getFileChanges(ctx context.Context, one storage.ReadBucket, two storage.ReadBucket) ([]string, error) {
+	onePaths, err := storage.AllPaths(ctx, one, "")
+	if err != nil {
+		return nil, err
+	}
+	twoPaths, err := storage.AllPaths(ctx, two, "")
+	if err != nil {
+		return nil, err
+	}
+	pathToChange := make(map[string]string)
+	twoPathMap := make(map[string]struct{}, len(twoPaths))
+	for _, twoPath := range twoPaths {
+		twoPathMap[twoPath] = struct{}{}
+	}
+	for _, onePath := range onePaths {
+		if _, ok := twoPathMap[onePath]; !ok {
+			pathToChange[onePath] = "D"
+			continue
+		}
+		oneData, err := storage.ReadPath(ctx, one, onePath)
+		if err != nil {
+			return nil, err
+		}
+		twoData, err := storage.ReadPath(ctx, two, onePath)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(oneData, twoData) {
+			pathToChange[onePath] = "M"
+		}
+		delete(twoPathMap, onePath)
+	}
+	for twoPath := range twoPathMap {
+		pathToChange[twoPath] = "A"
+	}
+	paths := slicesext.MapKeysToSortedSlice(pathToChange)
+	fileChanges := make([]string, len(paths))
+	for i, path := range paths {
+		fileChanges[i] = pathToChange[path] + " " + path
+	}
+	return fileChanges, nil
+}
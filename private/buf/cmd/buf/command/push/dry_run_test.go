@@ -0,0 +1,184 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduletesting"
+	"github.com/bufbuild/buf/private/bufpkg/bufparse"
+	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
+	"github.com/bufbuild/buf/private/pkg/uuidutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFileChanges(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	one, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			"a.proto":     []byte(`syntax = "proto3";`),
+			"b.proto":     []byte(`syntax = "proto3";`),
+			"foo/c.proto": []byte(`syntax = "proto3";`),
+		},
+	)
+	require.NoError(t, err)
+	two, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			"a.proto":     []byte(`syntax = "proto3";`),
+			"b.proto":     []byte(`syntax = "proto2";`),
+			"foo/d.proto": []byte(`syntax = "proto3";`),
+		},
+	)
+	require.NoError(t, err)
+	fileChanges, err := getFileChanges(ctx, one, two)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		[]string{
+			"M b.proto",
+			"D foo/c.proto",
+			"A foo/d.proto",
+		},
+		fileChanges,
+	)
+	fileChanges, err = getFileChanges(ctx, storagemem.NewReadWriteBucket(), one)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		[]string{
+			"A a.proto",
+			"A b.proto",
+			"A foo/c.proto",
+		},
+		fileChanges,
+	)
+	fileChanges, err = getFileChanges(ctx, one, one)
+	require.NoError(t, err)
+	assert.Empty(t, fileChanges)
+}
+
+func TestGetDepChanges(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	// The current commits on the registry.
+	omniProvider, err := bufmoduletesting.NewOmniProvider(
+		bufmoduletesting.ModuleData{
+			Name: "buf.build/acme/app",
+			PathToData: map[string][]byte{
+				"app.proto": []byte(`syntax = "proto3"; import "unchanged.proto"; import "changed.proto"; import "removed.proto";`),
+			},
+		},
+		bufmoduletesting.ModuleData{
+			Name: "buf.build/acme/unchanged",
+			PathToData: map[string][]byte{
+				"unchanged.proto": []byte(`syntax = "proto3";`),
+			},
+		},
+		bufmoduletesting.ModuleData{
+			Name: "buf.build/acme/changed",
+			PathToData: map[string][]byte{
+				"changed.proto": []byte(`syntax = "proto3";`),
+			},
+		},
+		bufmoduletesting.ModuleData{
+			Name: "buf.build/acme/removed",
+			PathToData: map[string][]byte{
+				"removed.proto": []byte(`syntax = "proto3";`),
+			},
+		},
+	)
+	require.NoError(t, err)
+	// The workspace to push.
+	workspace, err := bufmoduletesting.NewModuleSet(
+		bufmoduletesting.ModuleData{
+			Name: "buf.build/acme/app",
+			PathToData: map[string][]byte{
+				"app.proto": []byte(`syntax = "proto3"; import "unchanged.proto"; import "changed.proto"; import "added.proto";`),
+			},
+		},
+		bufmoduletesting.ModuleData{
+			Name: "buf.build/acme/unchanged",
+			PathToData: map[string][]byte{
+				"unchanged.proto": []byte(`syntax = "proto3";`),
+			},
+		},
+		bufmoduletesting.ModuleData{
+			Name: "buf.build/acme/changed",
+			PathToData: map[string][]byte{
+				"changed.proto": []byte(`syntax = "proto3"; package changed;`),
+			},
+		},
+		bufmoduletesting.ModuleData{
+			Name: "buf.build/acme/added",
+			PathToData: map[string][]byte{
+				"added.proto": []byte(`syntax = "proto3";`),
+			},
+		},
+	)
+	require.NoError(t, err)
+	opaqueIDToDryRunModule := make(map[string]*dryRunModule)
+	fullNameToDryRunModule := make(map[string]*dryRunModule)
+	for _, module := range workspace.Modules() {
+		dryRunModule, err := newDryRunModule(ctx, omniProvider, omniProvider, module, "")
+		require.NoError(t, err)
+		opaqueIDToDryRunModule[module.OpaqueID()] = dryRunModule
+		fullNameToDryRunModule[module.FullName().String()] = dryRunModule
+	}
+	assert.True(t, fullNameToDryRunModule["buf.build/acme/app"].changed)
+	assert.False(t, fullNameToDryRunModule["buf.build/acme/unchanged"].changed)
+	assert.True(t, fullNameToDryRunModule["buf.build/acme/changed"].changed)
+	assert.True(t, fullNameToDryRunModule["buf.build/acme/added"].changed)
+	assert.Nil(t, fullNameToDryRunModule["buf.build/acme/added"].currentModuleKey)
+
+	getCommit := func(fullNameString string) string {
+		fullName, err := bufparse.ParseFullName(fullNameString)
+		require.NoError(t, err)
+		return uuidutil.ToDashless(omniProvider.GetModuleForFullName(fullName).CommitID())
+	}
+	depChanges, err := fullNameToDryRunModule["buf.build/acme/app"].getDepChanges(opaqueIDToDryRunModule)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		[]string{
+			"+ buf.build/acme/added:<new commit>",
+			"~ buf.build/acme/changed:" + getCommit("buf.build/acme/changed") + " -> <new commit>",
+			"- buf.build/acme/removed:" + getCommit("buf.build/acme/removed"),
+		},
+		depChanges,
+	)
+	depChanges, err = fullNameToDryRunModule["buf.build/acme/unchanged"].getDepChanges(opaqueIDToDryRunModule)
+	require.NoError(t, err)
+	assert.Empty(t, depChanges)
+
+	// Without a current commit, all dependencies are added. Dependencies that are unchanged
+	// keep their current commit.
+	appDryRunModule := *fullNameToDryRunModule["buf.build/acme/app"]
+	appDryRunModule.currentModuleKey = nil
+	appDryRunModule.currentModuleData = nil
+	depChanges, err = appDryRunModule.getDepChanges(opaqueIDToDryRunModule)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		[]string{
+			"+ buf.build/acme/added:<new commit>",
+			"+ buf.build/acme/changed:<new commit>",
+			"+ buf.build/acme/unchanged:" + getCommit("buf.build/acme/unchanged"),
+		},
+		depChanges,
+	)
+}
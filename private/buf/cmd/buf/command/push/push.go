@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/bufbuild/buf/private/buf/bufcli"
@@ -25,7 +26,9 @@ import (
 	"github.com/bufbuild/buf/private/buf/buffetch"
 	"github.com/bufbuild/buf/private/buf/bufworkspace"
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
+	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulesign"
 	"github.com/bufbuild/buf/private/pkg/app"
 	"github.com/bufbuild/buf/private/pkg/app/appcmd"
 	"github.com/bufbuild/buf/private/pkg/app/appext"
@@ -47,6 +50,9 @@ const (
 	sourceControlURLFlagName   = "source-control-url"
 	gitMetadataFlagName        = "git-metadata"
 	excludeUnnamedFlagName     = "exclude-unnamed"
+	signingKeyFlagName         = "signing-key"
+	dryRunFlagName             = "dry-run"
+	diffFlagName               = "diff"
 
 	// All deprecated.
 	tagFlagName      = "tag"
@@ -94,6 +100,9 @@ type flags struct {
 	SourceControlURL   string
 	ExcludeUnnamed     bool
 	GitMetadata        bool
+	SigningKey         string
+	DryRun             bool
+	Diff               bool
 	// special
 	InputHashtag string
 }
@@ -172,6 +181,30 @@ If you set the --%s flag and/or --%s flag yourself, then the value(s) will be us
 		false,
 		"Only push named modules to the BSR. Named modules must not have any unnamed dependencies.",
 	)
+	flagSet.StringVar(
+		&f.SigningKey,
+		signingKeyFlagName,
+		"",
+		`The path to a PEM-encoded ed25519 private key to sign the digests of the pushed modules with.
+The signatures are not pushed to the BSR. They are stored in the signature directory, which is within the cache directory
+unless $BUF_SIGNATURE_DIR is set, and in the remote cache if $BUF_REMOTE_CACHE_URL is set. Dependents verify the signatures
+with the verify section of their buf.yaml, and read them from the same signature directory or remote cache.`,
+	)
+	flagSet.BoolVar(
+		&f.DryRun,
+		dryRunFlagName,
+		false,
+		`Print what the push would change on the registry without pushing.
+For every module, prints whether the module would get a new commit against the current commit of the label,
+and the files and dependencies that were added (A, +), deleted (D, -) or modified (M, ~).
+The label is the first label set with --label, or the default label of the module.`,
+	)
+	flagSet.BoolVar(
+		&f.Diff,
+		diffFlagName,
+		false,
+		fmt.Sprintf("Print a diff of the changed files. Requires --%s.", dryRunFlagName),
+	)
 
 	flagSet.StringSliceVarP(&f.Tags, tagFlagName, tagFlagShortName, nil, useLabelInstead)
 	_ = flagSet.MarkHidden(tagFlagName)
@@ -212,13 +245,12 @@ func run(
 	if err := validateFlags(flags); err != nil {
 		return err
 	}
-
-	workspace, err := getBuildableWorkspace(ctx, container, flags)
+	signingKey, err := getSigningKey(flags)
 	if err != nil {
 		return err
 	}
 
-	uploader, err := bufcli.NewModuleUploader(container)
+	workspace, err := getBuildableWorkspace(ctx, container, flags)
 	if err != nil {
 		return err
 	}
@@ -257,6 +289,13 @@ func run(
 		uploadOptions = append(uploadOptions, bufmodule.UploadWithExcludeUnnamed())
 	}
 
+	if flags.DryRun {
+		return runDryRun(ctx, container, workspace, uploadOptions, flags.Diff)
+	}
+	uploader, err := bufcli.NewModuleUploader(container)
+	if err != nil {
+		return err
+	}
 	commits, err := uploader.Upload(ctx, workspace, uploadOptions...)
 	if err != nil {
 		return err
@@ -264,6 +303,11 @@ func run(
 	if len(commits) == 0 {
 		return nil
 	}
+	if signingKey != nil {
+		if err := signCommits(ctx, container, signingKey, commits); err != nil {
+			return err
+		}
+	}
 	if workspace.IsV2() {
 		_, err := container.Stdout().Write(
 			[]byte(
@@ -319,6 +363,22 @@ func getBuildableWorkspace(
 	if err != nil {
 		return nil, err
 	}
+	// Replacements are only for local development, the pushed modules must build against
+	// the dependencies in the buf.lock.
+	if replaceConfigs := workspace.ReplaceConfigs(); len(replaceConfigs) > 0 {
+		return nil, fmt.Errorf(
+			"cannot push a workspace with replaced dependencies, remove the replace section from your buf.yaml for: %s",
+			strings.Join(
+				slicesext.Map(
+					replaceConfigs,
+					func(replaceConfig bufconfig.ReplaceConfig) string {
+						return replaceConfig.FullName().String()
+					},
+				),
+				", ",
+			),
+		)
+	}
 	// Make sure the workspace builds.
 	if _, err := controller.GetImageForWorkspace(
 		ctx,
@@ -330,6 +390,50 @@ func getBuildableWorkspace(
 	return workspace, nil
 }
 
+// getSigningKey returns the key to sign the pushed modules with, or nil if --signing-key is not set.
+func getSigningKey(flags *flags) (bufmodulesign.PrivateKey, error) {
+	if flags.SigningKey == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(flags.SigningKey)
+	if err != nil {
+		return nil, err
+	}
+	signingKey, err := bufmodulesign.ParsePrivateKeyPEM(data)
+	if err != nil {
+		return nil, appcmd.NewInvalidArgumentErrorf("invalid --%s %q: %v", signingKeyFlagName, flags.SigningKey, err)
+	}
+	return signingKey, nil
+}
+
+// signCommits signs the digests of the pushed commits and stores the signatures.
+func signCommits(
+	ctx context.Context,
+	container appext.Container,
+	signingKey bufmodulesign.PrivateKey,
+	commits []bufmodule.Commit,
+) error {
+	signatureStore, err := bufcli.NewSignatureStore(container)
+	if err != nil {
+		return err
+	}
+	for _, commit := range commits {
+		moduleKey := commit.ModuleKey()
+		digest, err := moduleKey.Digest()
+		if err != nil {
+			return err
+		}
+		bundle, err := bufmodulesign.NewBundle(signingKey, moduleKey.FullName(), digest)
+		if err != nil {
+			return err
+		}
+		if err := signatureStore.PutBundle(ctx, bundle); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func validateFlags(flags *flags) error {
 	if err := validateCreateFlags(flags); err != nil {
 		return err
@@ -337,6 +441,9 @@ func validateFlags(flags *flags) error {
 	if err := validateLabelFlags(flags); err != nil {
 		return err
 	}
+	if err := validateDryRunFlags(flags); err != nil {
+		return err
+	}
 	return validateGitMetadataFlags(flags)
 }
 
@@ -414,6 +521,16 @@ func validateLabelFlagValues(flags *flags) error {
 	return nil
 }
 
+func validateDryRunFlags(flags *flags) error {
+	if flags.Diff && !flags.DryRun {
+		return appcmd.NewInvalidArgumentErrorf("--%s requires --%s", diffFlagName, dryRunFlagName)
+	}
+	if flags.SigningKey != "" && flags.DryRun {
+		return appcmd.NewInvalidArgumentErrorf("--%s cannot be used with --%s", signingKeyFlagName, dryRunFlagName)
+	}
+	return nil
+}
+
 // We do not allow users to set --source-control-url, --create-default-label, and --label
 // flags if the --git-metadata flag is set.
 func validateGitMetadataFlags(flags *flags) error {
@@ -0,0 +1,651 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"buf.build/gen/go/bufbuild/registry/connectrpc/go/buf/registry/module/v1/modulev1connect"
+	"buf.build/gen/go/bufbuild/registry/connectrpc/go/buf/registry/owner/v1/ownerv1connect"
+	modulev1 "buf.build/gen/go/bufbuild/registry/protocolbuffers/go/buf/registry/module/v1"
+	ownerv1 "buf.build/gen/go/bufbuild/registry/protocolbuffers/go/buf/registry/owner/v1"
+	"connectrpc.com/connect"
+	"github.com/bufbuild/buf/private/bufpkg/bufcas"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/pkg/app/appcmd"
+	"github.com/bufbuild/buf/private/pkg/app/appcmd/appcmdtesting"
+	"github.com/bufbuild/buf/private/pkg/uuidutil"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// testRegistryImportRegexp matches the import statements of .proto files.
+var testRegistryImportRegexp = regexp.MustCompile(`(?m)^\s*import\s+(?:public\s+|weak\s+)?"([^"]+)"\s*;`)
+
+// testRegistry is an in-process registry that implements the parts of the registry API
+// that are used by push and the dep commands.
+//
+// The b5 digests of commits are computed the same way as the CLI computes them, with the
+// dependencies of a commit being the dependencies and other uploaded contents its files
+// import, and their dependencies.
+type testRegistry struct {
+	modulev1connect.UnimplementedCommitServiceHandler
+	modulev1connect.UnimplementedDownloadServiceHandler
+	modulev1connect.UnimplementedGraphServiceHandler
+	modulev1connect.UnimplementedModuleServiceHandler
+	modulev1connect.UnimplementedUploadServiceHandler
+	ownerv1connect.UnimplementedOwnerServiceHandler
+
+	// Host is the registry, such as "127.0.0.1:1234".
+	Host string
+
+	lock sync.Mutex
+	// Dashless owner ID to owner name.
+	ownerIDToName map[string]string
+	// Dashless module ID to module.
+	moduleIDToModule map[string]*testRegistryModule
+	// Dashless commit ID to commit.
+	commitIDToCommit map[string]*testRegistryCommit
+}
+
+type testRegistryModule struct {
+	proto *modulev1.Module
+	// Label name to dashless commit ID.
+	labelNameToCommitID map[string]string
+}
+
+type testRegistryCommit struct {
+	proto *modulev1.Commit
+	files []*modulev1.File
+	// Dashless commit IDs of all dependencies, direct and transitive.
+	depCommitIDs []string
+}
+
+// newTestRegistry starts a new testRegistry that is stopped when the test ends.
+func newTestRegistry(t *testing.T) *testRegistry {
+	registry := &testRegistry{
+		ownerIDToName:    make(map[string]string),
+		moduleIDToModule: make(map[string]*testRegistryModule),
+		commitIDToCommit: make(map[string]*testRegistryCommit),
+	}
+	mux := http.NewServeMux()
+	mux.Handle(modulev1connect.NewCommitServiceHandler(registry))
+	mux.Handle(modulev1connect.NewDownloadServiceHandler(registry))
+	mux.Handle(modulev1connect.NewGraphServiceHandler(registry))
+	mux.Handle(modulev1connect.NewModuleServiceHandler(registry))
+	mux.Handle(modulev1connect.NewUploadServiceHandler(registry))
+	mux.Handle(ownerv1connect.NewOwnerServiceHandler(registry))
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	registry.Host = strings.TrimPrefix(server.URL, "http://")
+	return registry
+}
+
+// NewEnvFunc returns a new env func for running commands against the registry.
+//
+// The registry is served over plain HTTP, so TLS is disabled in the config directory.
+// The cache and config directories are shared between all commands run with the env func.
+// The extra env is added to the env of every command.
+func (r *testRegistry) NewEnvFunc(t *testing.T, extraEnv map[string]string) func(string) map[string]string {
+	tempDirPath := t.TempDir()
+	require.NoError(
+		t,
+		os.WriteFile(
+			filepath.Join(tempDirPath, "config.yaml"),
+			[]byte("version: v1\ntls:\n  use: false\n"),
+			0600,
+		),
+	)
+	return func(use string) map[string]string {
+		env := map[string]string{
+			useEnvVar(use, "CACHE_DIR"):  tempDirPath,
+			useEnvVar(use, "CONFIG_DIR"): tempDirPath,
+			"PATH":                       os.Getenv("PATH"),
+		}
+		for key, value := range extraEnv {
+			env[key] = value
+		}
+		return env
+	}
+}
+
+// GetLabelCommitID returns the dashless ID of the commit of the label of the module.
+func (r *testRegistry) GetLabelCommitID(t *testing.T, owner string, name string, labelName string) string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	module, err := r.getModuleForName(owner, name)
+	require.NoError(t, err)
+	commitID, ok := module.labelNameToCommitID[labelName]
+	require.True(t, ok, "label %q not found on %s/%s", labelName, owner, name)
+	return commitID
+}
+
+// Deprecate deprecates the module.
+func (r *testRegistry) Deprecate(t *testing.T, owner string, name string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	module, err := r.getModuleForName(owner, name)
+	require.NoError(t, err)
+	module.proto.State = modulev1.ModuleState_MODULE_STATE_DEPRECATED
+}
+
+func (r *testRegistry) GetCommits(
+	_ context.Context,
+	request *connect.Request[modulev1.GetCommitsRequest],
+) (*connect.Response[modulev1.GetCommitsResponse], error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	commits, err := r.getCommitsForResourceRefs(request.Msg.ResourceRefs)
+	if err != nil {
+		return nil, err
+	}
+	response := &modulev1.GetCommitsResponse{}
+	for _, commit := range commits {
+		response.Commits = append(response.Commits, commit.proto)
+	}
+	return connect.NewResponse(response), nil
+}
+
+func (r *testRegistry) Download(
+	_ context.Context,
+	request *connect.Request[modulev1.DownloadRequest],
+) (*connect.Response[modulev1.DownloadResponse], error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	response := &modulev1.DownloadResponse{}
+	for _, value := range request.Msg.Values {
+		commits, err := r.getCommitsForResourceRefs([]*modulev1.ResourceRef{value.ResourceRef})
+		if err != nil {
+			return nil, err
+		}
+		response.Contents = append(
+			response.Contents,
+			&modulev1.DownloadResponse_Content{
+				Commit: commits[0].proto,
+				Files:  commits[0].files,
+			},
+		)
+	}
+	return connect.NewResponse(response), nil
+}
+
+func (r *testRegistry) GetGraph(
+	_ context.Context,
+	request *connect.Request[modulev1.GetGraphRequest],
+) (*connect.Response[modulev1.GetGraphResponse], error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	commits, err := r.getCommitsForResourceRefs(request.Msg.ResourceRefs)
+	if err != nil {
+		return nil, err
+	}
+	graph := &modulev1.Graph{}
+	seenCommitIDs := make(map[string]struct{})
+	var addCommit func(*testRegistryCommit)
+	addCommit = func(commit *testRegistryCommit) {
+		if _, ok := seenCommitIDs[commit.proto.Id]; ok {
+			return
+		}
+		seenCommitIDs[commit.proto.Id] = struct{}{}
+		graph.Commits = append(graph.Commits, commit.proto)
+		for _, depCommitID := range commit.depCommitIDs {
+			graph.Edges = append(
+				graph.Edges,
+				&modulev1.Graph_Edge{
+					FromNode: &modulev1.Graph_Node{CommitId: commit.proto.Id},
+					ToNode:   &modulev1.Graph_Node{CommitId: depCommitID},
+				},
+			)
+			addCommit(r.commitIDToCommit[depCommitID])
+		}
+	}
+	for _, commit := range commits {
+		addCommit(commit)
+	}
+	return connect.NewResponse(&modulev1.GetGraphResponse{Graph: graph}), nil
+}
+
+func (r *testRegistry) GetModules(
+	_ context.Context,
+	request *connect.Request[modulev1.GetModulesRequest],
+) (*connect.Response[modulev1.GetModulesResponse], error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	response := &modulev1.GetModulesResponse{}
+	for _, moduleRef := range request.Msg.ModuleRefs {
+		var module *testRegistryModule
+		switch value := moduleRef.Value.(type) {
+		case *modulev1.ModuleRef_Id:
+			module = r.moduleIDToModule[value.Id]
+		case *modulev1.ModuleRef_Name_:
+			module, _ = r.getModuleForName(value.Name.Owner, value.Name.Module)
+		}
+		if module == nil {
+			return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("module %v not found", moduleRef))
+		}
+		response.Modules = append(response.Modules, module.proto)
+	}
+	return connect.NewResponse(response), nil
+}
+
+func (r *testRegistry) CreateModules(
+	_ context.Context,
+	request *connect.Request[modulev1.CreateModulesRequest],
+) (*connect.Response[modulev1.CreateModulesResponse], error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	response := &modulev1.CreateModulesResponse{}
+	for _, value := range request.Msg.Values {
+		ownerName := value.OwnerRef.GetName()
+		if module, _ := r.getModuleForName(ownerName, value.Name); module != nil {
+			return nil, connect.NewError(connect.CodeAlreadyExists, fmt.Errorf("module %s/%s already exists", ownerName, value.Name))
+		}
+		ownerID := r.getOrCreateOwnerID(ownerName)
+		defaultLabelName := value.DefaultLabelName
+		if defaultLabelName == "" {
+			defaultLabelName = "main"
+		}
+		module := &testRegistryModule{
+			proto: &modulev1.Module{
+				Id:               newTestRegistryID(),
+				CreateTime:       timestamppb.Now(),
+				UpdateTime:       timestamppb.Now(),
+				Name:             value.Name,
+				OwnerId:          ownerID,
+				Visibility:       value.Visibility,
+				State:            modulev1.ModuleState_MODULE_STATE_ACTIVE,
+				DefaultLabelName: defaultLabelName,
+			},
+			labelNameToCommitID: make(map[string]string),
+		}
+		r.moduleIDToModule[module.proto.Id] = module
+		response.Modules = append(response.Modules, module.proto)
+	}
+	return connect.NewResponse(response), nil
+}
+
+func (r *testRegistry) Upload(
+	_ context.Context,
+	request *connect.Request[modulev1.UploadRequest],
+) (*connect.Response[modulev1.UploadResponse], error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	availableDepCommitIDs, err := r.getDepCommitIDs(request.Msg.DepCommitIds)
+	if err != nil {
+		return nil, err
+	}
+	// Like the BSR, the dependencies of a content are detected with the imports of its files,
+	// either on the given dependencies or on the other contents.
+	pathToDepCommitID := make(map[string]string)
+	for _, depCommitID := range availableDepCommitIDs {
+		for _, file := range r.commitIDToCommit[depCommitID].files {
+			pathToDepCommitID[file.Path] = depCommitID
+		}
+	}
+	pathToContentIndex := make(map[string]int)
+	for i, content := range request.Msg.Contents {
+		for _, file := range content.Files {
+			pathToContentIndex[file.Path] = i
+		}
+	}
+	contentIndexToCommit := make(map[int]*testRegistryCommit)
+	var uploadContent func(int, map[int]struct{}) (*testRegistryCommit, error)
+	uploadContent = func(contentIndex int, visiting map[int]struct{}) (*testRegistryCommit, error) {
+		if commit, ok := contentIndexToCommit[contentIndex]; ok {
+			return commit, nil
+		}
+		if _, ok := visiting[contentIndex]; ok {
+			return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("import cycle between contents"))
+		}
+		visiting[contentIndex] = struct{}{}
+		content := request.Msg.Contents[contentIndex]
+		var directDepCommitIDs []string
+		for _, file := range content.Files {
+			for _, match := range testRegistryImportRegexp.FindAllSubmatch(file.Content, -1) {
+				importPath := string(match[1])
+				if depContentIndex, ok := pathToContentIndex[importPath]; ok {
+					if depContentIndex == contentIndex {
+						continue
+					}
+					depCommit, err := uploadContent(depContentIndex, visiting)
+					if err != nil {
+						return nil, err
+					}
+					directDepCommitIDs = append(directDepCommitIDs, depCommit.proto.Id)
+				} else if depCommitID, ok := pathToDepCommitID[importPath]; ok {
+					directDepCommitIDs = append(directDepCommitIDs, depCommitID)
+				}
+			}
+		}
+		commit, err := r.uploadContent(content, directDepCommitIDs)
+		if err != nil {
+			return nil, err
+		}
+		contentIndexToCommit[contentIndex] = commit
+		return commit, nil
+	}
+	response := &modulev1.UploadResponse{}
+	for i := range request.Msg.Contents {
+		commit, err := uploadContent(i, make(map[int]struct{}))
+		if err != nil {
+			return nil, err
+		}
+		response.Commits = append(response.Commits, commit.proto)
+	}
+	return connect.NewResponse(response), nil
+}
+
+func (r *testRegistry) GetOwners(
+	_ context.Context,
+	request *connect.Request[ownerv1.GetOwnersRequest],
+) (*connect.Response[ownerv1.GetOwnersResponse], error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	response := &ownerv1.GetOwnersResponse{}
+	for _, ownerRef := range request.Msg.OwnerRefs {
+		var ownerID string
+		switch value := ownerRef.Value.(type) {
+		case *ownerv1.OwnerRef_Id:
+			ownerID = value.Id
+		case *ownerv1.OwnerRef_Name:
+			for id, name := range r.ownerIDToName {
+				if name == value.Name {
+					ownerID = id
+				}
+			}
+		}
+		ownerName, ok := r.ownerIDToName[ownerID]
+		if !ok {
+			return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("owner %v not found", ownerRef))
+		}
+		response.Owners = append(
+			response.Owners,
+			&ownerv1.Owner{
+				Value: &ownerv1.Owner_Organization{
+					Organization: &ownerv1.Organization{
+						Id:   ownerID,
+						Name: ownerName,
+					},
+				},
+			},
+		)
+	}
+	return connect.NewResponse(response), nil
+}
+
+// getCommitsForResourceRefs resolves commit IDs, labels, and modules to commits.
+//
+// Modules resolve to the latest commit on their default label.
+func (r *testRegistry) getCommitsForResourceRefs(resourceRefs []*modulev1.ResourceRef) ([]*testRegistryCommit, error) {
+	commits := make([]*testRegistryCommit, 0, len(resourceRefs))
+	for _, resourceRef := range resourceRefs {
+		var commitID string
+		switch value := resourceRef.Value.(type) {
+		case *modulev1.ResourceRef_Id:
+			commitID = value.Id
+		case *modulev1.ResourceRef_Name_:
+			module, err := r.getModuleForName(value.Name.Owner, value.Name.Module)
+			if err != nil {
+				return nil, err
+			}
+			switch child := value.Name.Child.(type) {
+			case *modulev1.ResourceRef_Name_LabelName:
+				commitID = module.labelNameToCommitID[child.LabelName]
+			case *modulev1.ResourceRef_Name_Ref:
+				if child.Ref == "" {
+					commitID = module.labelNameToCommitID[module.proto.DefaultLabelName]
+				} else if labelCommitID, ok := module.labelNameToCommitID[child.Ref]; ok {
+					commitID = labelCommitID
+				} else {
+					commitID = child.Ref
+				}
+			default:
+				commitID = module.labelNameToCommitID[module.proto.DefaultLabelName]
+			}
+		}
+		commit, ok := r.commitIDToCommit[commitID]
+		if !ok {
+			return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("resource %v not found", resourceRef))
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+// uploadContent creates a commit for the content with the given direct dependencies, and
+// sets the labels of the content to it.
+func (r *testRegistry) uploadContent(
+	content *modulev1.UploadRequest_Content,
+	directDepCommitIDs []string,
+) (*testRegistryCommit, error) {
+	depCommitIDs, err := r.getDepCommitIDs(directDepCommitIDs)
+	if err != nil {
+		return nil, err
+	}
+	moduleRefName := content.ModuleRef.GetName()
+	module, err := r.getModuleForName(moduleRefName.GetOwner(), moduleRefName.GetModule())
+	if err != nil {
+		return nil, err
+	}
+	digest, err := r.getB5Digest(content.Files, depCommitIDs)
+	if err != nil {
+		return nil, err
+	}
+	labelNames := []string{module.proto.DefaultLabelName}
+	if len(content.ScopedLabelRefs) > 0 {
+		labelNames = nil
+		for _, scopedLabelRef := range content.ScopedLabelRefs {
+			labelNames = append(labelNames, scopedLabelRef.GetName())
+		}
+	}
+	// Like the BSR, pushing the content of the latest commit on the first label does
+	// not create a new commit.
+	if commitID, ok := module.labelNameToCommitID[labelNames[0]]; ok {
+		commit := r.commitIDToCommit[commitID]
+		if bytes.Equal(commit.proto.Digest.Value, digest.Value()) {
+			for _, labelName := range labelNames {
+				module.labelNameToCommitID[labelName] = commitID
+			}
+			return commit, nil
+		}
+	}
+	commit := &testRegistryCommit{
+		proto: &modulev1.Commit{
+			Id:         newTestRegistryID(),
+			CreateTime: timestamppb.Now(),
+			OwnerId:    module.proto.OwnerId,
+			ModuleId:   module.proto.Id,
+			Digest: &modulev1.Digest{
+				Type:  modulev1.DigestType_DIGEST_TYPE_B5,
+				Value: digest.Value(),
+			},
+			SourceControlUrl: content.SourceControlUrl,
+		},
+		files:        content.Files,
+		depCommitIDs: depCommitIDs,
+	}
+	r.commitIDToCommit[commit.proto.Id] = commit
+	for _, labelName := range labelNames {
+		module.labelNameToCommitID[labelName] = commit.proto.Id
+	}
+	return commit, nil
+}
+
+// getDepCommitIDs returns the commit IDs and the commit IDs of all their dependencies.
+func (r *testRegistry) getDepCommitIDs(commitIDs []string) ([]string, error) {
+	depCommitIDs := make(map[string]struct{})
+	for _, commitID := range commitIDs {
+		commit, ok := r.commitIDToCommit[commitID]
+		if !ok {
+			return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("commit %s not found", commitID))
+		}
+		depCommitIDs[commitID] = struct{}{}
+		for _, depCommitID := range commit.depCommitIDs {
+			depCommitIDs[depCommitID] = struct{}{}
+		}
+	}
+	sortedDepCommitIDs := make([]string, 0, len(depCommitIDs))
+	for depCommitID := range depCommitIDs {
+		sortedDepCommitIDs = append(sortedDepCommitIDs, depCommitID)
+	}
+	sort.Strings(sortedDepCommitIDs)
+	return sortedDepCommitIDs, nil
+}
+
+// getB5Digest computes the b5 digest of the files and the dependencies the same way as
+// bufmodule, that is the digest of the files digest and the sorted dependency digests.
+func (r *testRegistry) getB5Digest(files []*modulev1.File, depCommitIDs []string) (bufcas.Digest, error) {
+	fileNodes := make([]bufcas.FileNode, 0, len(files))
+	for _, file := range files {
+		digest, err := bufcas.NewDigestForContent(bytes.NewReader(file.Content))
+		if err != nil {
+			return nil, err
+		}
+		fileNode, err := bufcas.NewFileNode(file.Path, digest)
+		if err != nil {
+			return nil, err
+		}
+		fileNodes = append(fileNodes, fileNode)
+	}
+	manifest, err := bufcas.NewManifest(fileNodes)
+	if err != nil {
+		return nil, err
+	}
+	filesDigest, err := bufcas.ManifestToDigest(manifest)
+	if err != nil {
+		return nil, err
+	}
+	depDigestStrings := make([]string, 0, len(depCommitIDs))
+	for _, depCommitID := range depCommitIDs {
+		depBufcasDigest, err := bufcas.NewDigest(r.commitIDToCommit[depCommitID].proto.Digest.Value)
+		if err != nil {
+			return nil, err
+		}
+		depDigest, err := bufmodule.NewDigest(bufmodule.DigestTypeB5, depBufcasDigest)
+		if err != nil {
+			return nil, err
+		}
+		depDigestStrings = append(depDigestStrings, depDigest.String())
+	}
+	sort.Strings(depDigestStrings)
+	return bufcas.NewDigestForContent(
+		strings.NewReader(strings.Join(append([]string{filesDigest.String()}, depDigestStrings...), "\n")),
+	)
+}
+
+func (r *testRegistry) getModuleForName(owner string, name string) (*testRegistryModule, error) {
+	for _, module := range r.moduleIDToModule {
+		if r.ownerIDToName[module.proto.OwnerId] == owner && module.proto.Name == name {
+			return module, nil
+		}
+	}
+	return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("module %s/%s not found", owner, name))
+}
+
+func (r *testRegistry) getOrCreateOwnerID(ownerName string) string {
+	for id, name := range r.ownerIDToName {
+		if name == ownerName {
+			return id
+		}
+	}
+	id := newTestRegistryID()
+	r.ownerIDToName[id] = ownerName
+	return id
+}
+
+func newTestRegistryID() string {
+	return uuidutil.ToDashless(uuid.New())
+}
+
+// testRunRegistry runs the command with the env func of the registry and returns stdout
+// and stderr.
+func testRunRegistry(
+	t *testing.T,
+	envFunc func(string) map[string]string,
+	expectedExitCode int,
+	args ...string,
+) (string, string) {
+	stdout := bytes.NewBuffer(nil)
+	stderr := bytes.NewBuffer(nil)
+	appcmdtesting.RunCommandExitCode(
+		t,
+		func(use string) *appcmd.Command { return NewRootCommand(use) },
+		expectedExitCode,
+		envFunc,
+		nil,
+		stdout,
+		stderr,
+		args...,
+	)
+	return stdout.String(), stderr.String()
+}
+
+// writeTestFiles writes the files at the paths relative to the directory.
+func writeTestFiles(t *testing.T, dirPath string, pathToData map[string]string) {
+	for path, data := range pathToData {
+		filePath := filepath.Join(dirPath, filepath.FromSlash(path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		require.NoError(t, os.WriteFile(filePath, []byte(data), 0600))
+	}
+}
+
+// newTestRemoteCacheServer starts a new in-memory HTTP cache server that is stopped when
+// the test ends, and returns its URL.
+func newTestRemoteCacheServer(t *testing.T) string {
+	var lock sync.Mutex
+	pathToData := make(map[string][]byte)
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(responseWriter http.ResponseWriter, request *http.Request) {
+				lock.Lock()
+				defer lock.Unlock()
+				switch request.Method {
+				case http.MethodGet:
+					data, ok := pathToData[request.URL.Path]
+					if !ok {
+						http.NotFound(responseWriter, request)
+						return
+					}
+					_, _ = responseWriter.Write(data)
+				case http.MethodPut:
+					data, err := io.ReadAll(request.Body)
+					if err != nil {
+						http.Error(responseWriter, err.Error(), http.StatusBadRequest)
+						return
+					}
+					pathToData[request.URL.Path] = data
+				default:
+					http.Error(responseWriter, "", http.StatusMethodNotAllowed)
+				}
+			},
+		),
+	)
+	t.Cleanup(server.Close)
+	return server.URL
+}
@@ -19,6 +19,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"slices"
 	"strings"
 	"time"
 
@@ -130,6 +131,16 @@ func handle(
 					return err
 				}
 			}
+			// Warnings do not fail the plugin, and are printed to stderr instead.
+			if !slices.ContainsFunc(
+				fileAnnotationSet.FileAnnotations(),
+				func(fileAnnotation bufanalysis.FileAnnotation) bool {
+					return fileAnnotation.Severity() == bufanalysis.SeverityError
+				},
+			) {
+				_, err := pluginEnv.Stderr.Write(buffer.Bytes())
+				return err
+			}
 			responseWriter.AddError(strings.TrimSpace(buffer.String()))
 			return nil
 		}
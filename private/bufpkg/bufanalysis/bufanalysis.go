@@ -34,6 +34,24 @@ const (
 	//
 	// See https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions#setting-an-error-message.
 	FormatGithubActions
+	// FormatSARIF is the SARIF format for FileAnnotations.
+	//
+	// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html.
+	FormatSARIF
+)
+
+const (
+	// SeverityAcknowledged is the severity of a FileAnnotation that was acknowledged
+	// in the source, for example with a comment ignore, and is reported for information only.
+	SeverityAcknowledged Severity = iota + 1
+	// SeverityInfo is the severity of a FileAnnotation that is informational.
+	SeverityInfo
+	// SeverityWarning is the severity of a FileAnnotation that is a warning.
+	SeverityWarning
+	// SeverityError is the severity of a FileAnnotation that is an error.
+	//
+	// This is the default severity.
+	SeverityError
 )
 
 var (
@@ -46,6 +64,7 @@ var (
 		"msvs",
 		"junit",
 		"github-actions",
+		"sarif",
 	}
 	// AllFormatStringsWithAliases is all format strings with aliases.
 	//
@@ -57,6 +76,18 @@ var (
 		"msvs",
 		"junit",
 		"github-actions",
+		"sarif",
+	}
+	// AllSeverityStrings is all severity strings that can be parsed.
+	//
+	// SeverityAcknowledged cannot be parsed, as FileAnnotations that were acknowledged
+	// never result in a failure.
+	//
+	// Sorted in the order we want to display them.
+	AllSeverityStrings = []string{
+		"error",
+		"warning",
+		"info",
 	}
 
 	stringToFormat = map[string]Format{
@@ -67,6 +98,7 @@ var (
 		"msvs":           FormatMSVS,
 		"junit":          FormatJUnit,
 		"github-actions": FormatGithubActions,
+		"sarif":          FormatSARIF,
 	}
 	formatToString = map[Format]string{
 		FormatText:          "text",
@@ -74,6 +106,18 @@ var (
 		FormatMSVS:          "msvs",
 		FormatJUnit:         "junit",
 		FormatGithubActions: "github-actions",
+		FormatSARIF:         "sarif",
+	}
+	stringToSeverity = map[string]Severity{
+		"error":   SeverityError,
+		"warning": SeverityWarning,
+		"info":    SeverityInfo,
+	}
+	severityToString = map[Severity]string{
+		SeverityError:        "error",
+		SeverityWarning:      "warning",
+		SeverityInfo:         "info",
+		SeverityAcknowledged: "acknowledged",
 	}
 )
 
@@ -104,6 +148,35 @@ func ParseFormat(s string) (Format, error) {
 	return 0, fmt.Errorf("unknown format: %q", s)
 }
 
+// Severity is the severity of a FileAnnotation.
+//
+// Severities are ordered, that is a greater Severity is more severe.
+type Severity int
+
+// String implements fmt.Stringer.
+func (s Severity) String() string {
+	str, ok := severityToString[s]
+	if !ok {
+		return strconv.Itoa(int(s))
+	}
+	return str
+}
+
+// ParseSeverity parses the Severity.
+//
+// The empty string defaults to SeverityError.
+func ParseSeverity(s string) (Severity, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return SeverityError, nil
+	}
+	severity, ok := stringToSeverity[s]
+	if ok {
+		return severity, nil
+	}
+	return 0, fmt.Errorf("unknown severity: %q", s)
+}
+
 // FileInfo is a minimal FileInfo interface.
 type FileInfo interface {
 	Path() string
@@ -149,6 +222,10 @@ type FileAnnotation interface {
 	// May be empty if this annotation did not originate from a plugin.
 	// This may be added to the printed message field for certain printers.
 	PluginName() string
+	// Severity is the severity of the annotation.
+	//
+	// This is SeverityError unless otherwise specified.
+	Severity() Severity
 
 	isFileAnnotation()
 }
@@ -163,6 +240,7 @@ func NewFileAnnotation(
 	typeString string,
 	message string,
 	pluginName string,
+	options ...FileAnnotationOption,
 ) FileAnnotation {
 	return newFileAnnotation(
 		fileInfo,
@@ -173,9 +251,22 @@ func NewFileAnnotation(
 		typeString,
 		message,
 		pluginName,
+		options...,
 	)
 }
 
+// FileAnnotationOption is an option for a new FileAnnotation.
+type FileAnnotationOption func(*fileAnnotation)
+
+// WithSeverity returns a new FileAnnotationOption that sets the Severity of the FileAnnotation.
+//
+// The default is SeverityError.
+func WithSeverity(severity Severity) FileAnnotationOption {
+	return func(fileAnnotation *fileAnnotation) {
+		fileAnnotation.severity = severity
+	}
+}
+
 // FileAnnotationSet is a set of FileAnnotations.
 type FileAnnotationSet interface {
 	// Stringer returns the string representation for this FileAnnotationSet.
@@ -218,6 +309,8 @@ func PrintFileAnnotationSet(writer io.Writer, fileAnnotationSet FileAnnotationSe
 		return printAsJUnit(writer, fileAnnotationSet.FileAnnotations())
 	case FormatGithubActions:
 		return printAsGithubActions(writer, fileAnnotationSet.FileAnnotations())
+	case FormatSARIF:
+		return printAsSARIF(writer, fileAnnotationSet.FileAnnotations())
 	default:
 		return fmt.Errorf("unknown FileAnnotation Format: %v", format)
 	}
@@ -137,3 +137,218 @@ path/to/file.proto(2,1) : error FOO : Hello. (buf-plugin-foo)
 		sb.String(),
 	)
 }
+
+func TestSeverity(t *testing.T) {
+	t.Parallel()
+	fileAnnotationSet := bufanalysis.NewFileAnnotationSet(
+		bufanalysis.NewFileAnnotation(
+			newFileInfo("path/to/file.proto"),
+			1,
+			1,
+			1,
+			5,
+			"FOO",
+			"Hello.",
+			"",
+		),
+		bufanalysis.NewFileAnnotation(
+			newFileInfo("path/to/file.proto"),
+			2,
+			1,
+			2,
+			5,
+			"BAR",
+			"Goodbye.",
+			"",
+			bufanalysis.WithSeverity(bufanalysis.SeverityWarning),
+		),
+		bufanalysis.NewFileAnnotation(
+			newFileInfo("path/to/file.proto"),
+			3,
+			1,
+			3,
+			5,
+			"BAZ",
+			"Noted.",
+			"",
+			bufanalysis.WithSeverity(bufanalysis.SeverityAcknowledged),
+		),
+		bufanalysis.NewFileAnnotation(
+			newFileInfo("path/to/file.proto"),
+			4,
+			1,
+			4,
+			5,
+			"QUX",
+			"Consider.",
+			"",
+			bufanalysis.WithSeverity(bufanalysis.SeverityInfo),
+		),
+	)
+	testSeverity := func(format string, expected string) {
+		sb := &strings.Builder{}
+		err := bufanalysis.PrintFileAnnotationSet(sb, fileAnnotationSet, format)
+		require.NoError(t, err)
+		assert.Equal(t, expected, sb.String(), format)
+	}
+	testSeverity(
+		"text",
+		`path/to/file.proto:1:1:Hello.
+path/to/file.proto:2:1:warning: Goodbye.
+path/to/file.proto:3:1:acknowledged: Noted.
+path/to/file.proto:4:1:info: Consider.
+`,
+	)
+	testSeverity(
+		"json",
+		`{"path":"path/to/file.proto","start_line":1,"start_column":1,"end_line":1,"end_column":5,"type":"FOO","message":"Hello."}
+{"path":"path/to/file.proto","start_line":2,"start_column":1,"end_line":2,"end_column":5,"type":"BAR","message":"Goodbye.","severity":"warning"}
+{"path":"path/to/file.proto","start_line":3,"start_column":1,"end_line":3,"end_column":5,"type":"BAZ","message":"Noted.","severity":"acknowledged"}
+{"path":"path/to/file.proto","start_line":4,"start_column":1,"end_line":4,"end_column":5,"type":"QUX","message":"Consider.","severity":"info"}
+`,
+	)
+	testSeverity(
+		"msvs",
+		`path/to/file.proto(1,1) : error FOO : Hello.
+path/to/file.proto(2,1) : warning BAR : Goodbye.
+path/to/file.proto(3,1) : info BAZ : Noted.
+path/to/file.proto(4,1) : info QUX : Consider.
+`,
+	)
+	testSeverity(
+		"github-actions",
+		`::error file=path/to/file.proto,line=1,col=1,endLine=1,endColumn=5::Hello.
+::warning file=path/to/file.proto,line=2,col=1,endLine=2,endColumn=5::Goodbye.
+::notice file=path/to/file.proto,line=3,col=1,endLine=3,endColumn=5::Noted.
+::notice file=path/to/file.proto,line=4,col=1,endLine=4,endColumn=5::Consider.
+`,
+	)
+	testSeverity(
+		"junit",
+		`<testsuites>
+  <testsuite name="path/to/file" tests="4" failures="1" errors="0">
+    <testcase name="FOO_1_1">
+      <failure message="path/to/file.proto:1:1:Hello." type="FOO"></failure>
+    </testcase>
+    <testcase name="BAR_2_1">
+      <system-out>path/to/file.proto:2:1:warning: Goodbye.</system-out>
+    </testcase>
+    <testcase name="BAZ_3_1">
+      <system-out>path/to/file.proto:3:1:acknowledged: Noted.</system-out>
+    </testcase>
+    <testcase name="QUX_4_1">
+      <system-out>path/to/file.proto:4:1:info: Consider.</system-out>
+    </testcase>
+  </testsuite>
+</testsuites>
+`,
+	)
+	testSeverity(
+		"sarif",
+		`{
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "version": "2.1.0",
+  "runs": [
+    {
+      "tool": {
+        "driver": {
+          "name": "buf",
+          "informationUri": "https://github.com/bufbuild/buf"
+        }
+      },
+      "results": [
+        {
+          "ruleId": "FOO",
+          "level": "error",
+          "message": {
+            "text": "Hello."
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "path/to/file.proto"
+                },
+                "region": {
+                  "startLine": 1,
+                  "startColumn": 1,
+                  "endLine": 1,
+                  "endColumn": 5
+                }
+              }
+            }
+          ]
+        },
+        {
+          "ruleId": "BAR",
+          "level": "warning",
+          "message": {
+            "text": "Goodbye."
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "path/to/file.proto"
+                },
+                "region": {
+                  "startLine": 2,
+                  "startColumn": 1,
+                  "endLine": 2,
+                  "endColumn": 5
+                }
+              }
+            }
+          ]
+        },
+        {
+          "ruleId": "BAZ",
+          "level": "note",
+          "message": {
+            "text": "Noted."
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "path/to/file.proto"
+                },
+                "region": {
+                  "startLine": 3,
+                  "startColumn": 1,
+                  "endLine": 3,
+                  "endColumn": 5
+                }
+              }
+            }
+          ]
+        },
+        {
+          "ruleId": "QUX",
+          "level": "note",
+          "message": {
+            "text": "Consider."
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "path/to/file.proto"
+                },
+                "region": {
+                  "startLine": 4,
+                  "startColumn": 1,
+                  "endLine": 4,
+                  "endColumn": 5
+                }
+              }
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}
+`,
+	)
+}
@@ -28,6 +28,7 @@ type fileAnnotation struct {
 	typeString  string
 	message     string
 	pluginName  string
+	severity    Severity
 }
 
 func newFileAnnotation(
@@ -39,8 +40,9 @@ func newFileAnnotation(
 	typeString string,
 	message string,
 	pluginName string,
+	options ...FileAnnotationOption,
 ) *fileAnnotation {
-	return &fileAnnotation{
+	fileAnnotation := &fileAnnotation{
 		fileInfo:    fileInfo,
 		startLine:   startLine,
 		startColumn: startColumn,
@@ -49,7 +51,12 @@ func newFileAnnotation(
 		typeString:  typeString,
 		message:     message,
 		pluginName:  pluginName,
+		severity:    SeverityError,
 	}
+	for _, option := range options {
+		option(fileAnnotation)
+	}
+	return fileAnnotation
 }
 
 func (f *fileAnnotation) FileInfo() FileInfo {
@@ -84,6 +91,10 @@ func (f *fileAnnotation) PluginName() string {
 	return f.pluginName
 }
 
+func (f *fileAnnotation) Severity() Severity {
+	return f.severity
+}
+
 func (f *fileAnnotation) String() string {
 	if f == nil {
 		return ""
@@ -109,6 +120,11 @@ func (f *fileAnnotation) String() string {
 	_, _ = buffer.WriteRune(':')
 	_, _ = buffer.WriteString(strconv.Itoa(column))
 	_, _ = buffer.WriteRune(':')
+	if f.severity != SeverityError {
+		// Errors are not prefixed to keep the output stable for existing consumers.
+		_, _ = buffer.WriteString(f.severity.String())
+		_, _ = buffer.WriteString(": ")
+	}
 	_, _ = buffer.WriteString(message)
 	if f.pluginName != "" {
 		_, _ = buffer.WriteString(" (")
@@ -71,12 +71,18 @@ func printAsJUnit(writer io.Writer, fileAnnotations []FileAnnotation) error {
 			path = fileInfo.ExternalPath()
 		}
 		path = strings.TrimSuffix(path, ".proto")
+		var failures int
+		for _, annotation := range annotations {
+			if annotation.Severity() == SeverityError {
+				failures++
+			}
+		}
 		testsuite := xml.StartElement{
 			Name: xml.Name{Local: "testsuite"},
 			Attr: []xml.Attr{
 				{Name: xml.Name{Local: "name"}, Value: path},
 				{Name: xml.Name{Local: "tests"}, Value: strconv.Itoa(len(annotations))},
-				{Name: xml.Name{Local: "failures"}, Value: strconv.Itoa(len(annotations))},
+				{Name: xml.Name{Local: "failures"}, Value: strconv.Itoa(failures)},
 				{Name: xml.Name{Local: "errors"}, Value: "0"},
 			},
 		}
@@ -104,6 +110,32 @@ func printAsJUnit(writer io.Writer, fileAnnotations []FileAnnotation) error {
 	return nil
 }
 
+func printAsSARIF(writer io.Writer, fileAnnotations []FileAnnotation) error {
+	results := make([]externalSARIFResult, 0, len(fileAnnotations))
+	for _, fileAnnotation := range fileAnnotations {
+		results = append(results, newExternalSARIFResult(fileAnnotation))
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(
+		externalSARIFLog{
+			Schema:  sarifSchema,
+			Version: sarifVersion,
+			Runs: []externalSARIFRun{
+				{
+					Tool: externalSARIFTool{
+						Driver: externalSARIFDriver{
+							Name:           "buf",
+							InformationURI: "https://github.com/bufbuild/buf",
+						},
+					},
+					Results: results,
+				},
+			},
+		},
+	)
+}
+
 func printFileAnnotationAsJUnit(encoder *xml.Encoder, annotation FileAnnotation) error {
 	testcase := xml.StartElement{Name: xml.Name{Local: "testcase"}}
 	name := annotation.Type()
@@ -116,6 +148,14 @@ func printFileAnnotationAsJUnit(encoder *xml.Encoder, annotation FileAnnotation)
 	if err := encoder.EncodeToken(testcase); err != nil {
 		return err
 	}
+	if annotation.Severity() != SeverityError {
+		// JUnit has no concept of warnings. Non-error annotations are printed as passing
+		// test cases with the annotation as output, so that they do not fail the test suite.
+		if err := encoder.EncodeElement(annotation.String(), xml.StartElement{Name: xml.Name{Local: "system-out"}}); err != nil {
+			return err
+		}
+		return encoder.EncodeToken(xml.EndElement{Name: testcase.Name})
+	}
 	failure := xml.StartElement{
 		Name: xml.Name{Local: "failure"},
 		Attr: []xml.Attr{
@@ -190,7 +230,9 @@ func printFileAnnotationAsMSVS(buffer *bytes.Buffer, f FileAnnotation) error {
 		_, _ = buffer.WriteRune(',')
 		_, _ = buffer.WriteString(strconv.Itoa(column))
 	}
-	_, _ = buffer.WriteString(") : error ")
+	_, _ = buffer.WriteString(") : ")
+	_, _ = buffer.WriteString(severityStringForLevels(f.Severity(), "info"))
+	_, _ = buffer.WriteRune(' ')
 	_, _ = buffer.WriteString(typeString)
 	_, _ = buffer.WriteString(" : ")
 	_, _ = buffer.WriteString(message)
@@ -215,7 +257,12 @@ func printFileAnnotationAsGithubActions(buffer *bytes.Buffer, f FileAnnotation)
 	if f == nil {
 		return nil
 	}
-	_, _ = buffer.WriteString("::error ")
+	// GitHub Actions supports the error, warning, and notice commands. The error and
+	// warning commands match the names of our Severities, info and acknowledged
+	// FileAnnotations are printed as notices.
+	_, _ = buffer.WriteString("::")
+	_, _ = buffer.WriteString(severityStringForLevels(f.Severity(), "notice"))
+	_, _ = buffer.WriteRune(' ')
 
 	// file= is required for GitHub Actions, however it is possible to not have
 	// a path for a FileAnnotation. We still print something, however we need
@@ -268,6 +315,9 @@ type externalFileAnnotation struct {
 	Type        string `json:"type,omitempty" yaml:"type,omitempty"`
 	Message     string `json:"message,omitempty" yaml:"message,omitempty"`
 	Plugin      string `json:"plugin,omitempty" yaml:"plugin,omitempty"`
+	// Severity is only set for non-error FileAnnotations, so that the output of
+	// errors stays the same as before severities were introduced.
+	Severity string `json:"severity,omitempty" yaml:"severity,omitempty"`
 }
 
 func newExternalFileAnnotation(f FileAnnotation) externalFileAnnotation {
@@ -275,6 +325,10 @@ func newExternalFileAnnotation(f FileAnnotation) externalFileAnnotation {
 	if f.FileInfo() != nil {
 		path = f.FileInfo().ExternalPath()
 	}
+	var severity string
+	if f.Severity() != SeverityError {
+		severity = f.Severity().String()
+	}
 	return externalFileAnnotation{
 		Path:        path,
 		StartLine:   atLeast1(f.StartLine()),
@@ -284,9 +338,107 @@ func newExternalFileAnnotation(f FileAnnotation) externalFileAnnotation {
 		Type:        f.Type(),
 		Message:     f.Message(),
 		Plugin:      f.PluginName(),
+		Severity:    severity,
 	}
 }
 
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+// externalSARIFLog is the minimal subset of a SARIF log that we produce.
+type externalSARIFLog struct {
+	Schema  string             `json:"$schema"`
+	Version string             `json:"version"`
+	Runs    []externalSARIFRun `json:"runs"`
+}
+
+type externalSARIFRun struct {
+	Tool    externalSARIFTool     `json:"tool"`
+	Results []externalSARIFResult `json:"results"`
+}
+
+type externalSARIFTool struct {
+	Driver externalSARIFDriver `json:"driver"`
+}
+
+type externalSARIFDriver struct {
+	Name           string `json:"name"`
+	InformationURI string `json:"informationUri,omitempty"`
+}
+
+type externalSARIFResult struct {
+	RuleID    string                  `json:"ruleId,omitempty"`
+	Level     string                  `json:"level"`
+	Message   externalSARIFMessage    `json:"message"`
+	Locations []externalSARIFLocation `json:"locations,omitempty"`
+}
+
+type externalSARIFMessage struct {
+	Text string `json:"text"`
+}
+
+type externalSARIFLocation struct {
+	PhysicalLocation externalSARIFPhysicalLocation `json:"physicalLocation"`
+}
+
+type externalSARIFPhysicalLocation struct {
+	ArtifactLocation externalSARIFArtifactLocation `json:"artifactLocation"`
+	Region           *externalSARIFRegion          `json:"region,omitempty"`
+}
+
+type externalSARIFArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type externalSARIFRegion struct {
+	StartLine   int `json:"startLine,omitempty"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+func newExternalSARIFResult(f FileAnnotation) externalSARIFResult {
+	message := f.Message()
+	if message == "" {
+		message = f.Type()
+	}
+	if pluginName := f.PluginName(); pluginName != "" {
+		message += " (" + pluginName + ")"
+	}
+	result := externalSARIFResult{
+		RuleID: f.Type(),
+		// The SARIF levels error and warning match the names of our Severities, info
+		// and acknowledged FileAnnotations are printed as notes.
+		Level: severityStringForLevels(f.Severity(), "note"),
+		Message: externalSARIFMessage{
+			Text: message,
+		},
+	}
+	if f.FileInfo() == nil {
+		return result
+	}
+	location := externalSARIFLocation{
+		PhysicalLocation: externalSARIFPhysicalLocation{
+			ArtifactLocation: externalSARIFArtifactLocation{
+				URI: f.FileInfo().ExternalPath(),
+			},
+		},
+	}
+	// SARIF requires startLine if a region is present.
+	if startLine := f.StartLine(); startLine > 0 {
+		location.PhysicalLocation.Region = &externalSARIFRegion{
+			StartLine:   startLine,
+			StartColumn: f.StartColumn(),
+			EndLine:     f.EndLine(),
+			EndColumn:   f.EndColumn(),
+		}
+	}
+	result.Locations = []externalSARIFLocation{location}
+	return result
+}
+
 func printEachAnnotationOnNewLine(
 	writer io.Writer,
 	fileAnnotations []FileAnnotation,
@@ -305,3 +457,13 @@ func printEachAnnotationOnNewLine(
 	}
 	return nil
 }
+
+// severityStringForLevels returns the string for the Severity in formats that
+// have error and warning levels, and the given level for info and acknowledged
+// FileAnnotations.
+func severityStringForLevels(severity Severity, infoLevel string) string {
+	if severity == SeverityInfo || severity == SeverityAcknowledged {
+		return infoLevel
+	}
+	return severity.String()
+}
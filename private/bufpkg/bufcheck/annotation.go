@@ -15,6 +15,8 @@
 package bufcheck
 
 import (
+	"fmt"
+
 	"buf.build/go/bufplugin/check"
 	"github.com/bufbuild/buf/private/bufpkg/bufanalysis"
 	"github.com/bufbuild/buf/private/pkg/slicesext"
@@ -24,6 +26,14 @@ type annotation struct {
 	check.Annotation
 
 	pluginName string
+	// acknowledged is true if the annotation was acknowledged by a comment ignore.
+	acknowledged bool
+	// acknowledgedReason is the reason of the comment ignore that acknowledged the
+	// annotation, if any.
+	acknowledgedReason string
+	// commentIgnoreMissingReason is true if the annotation was not acknowledged by
+	// a comment ignore because the comment ignore did not have a reason.
+	commentIgnoreMissingReason bool
 }
 
 func newAnnotation(checkAnnotation check.Annotation, pluginName string) *annotation {
@@ -39,20 +49,42 @@ func (a *annotation) PluginName() string {
 
 func annotationsToFileAnnotations(
 	pathToExternalPath map[string]string,
+	warnRuleIDs map[string]struct{},
+	infoRuleIDs map[string]struct{},
 	annotations []*annotation,
 ) []bufanalysis.FileAnnotation {
 	return slicesext.Map(
 		annotations,
 		func(annotation *annotation) bufanalysis.FileAnnotation {
-			return annotationToFileAnnotation(pathToExternalPath, annotation)
+			return annotationToFileAnnotation(pathToExternalPath, warnRuleIDs, infoRuleIDs, annotation)
 		},
 	)
 }
 
 func annotationToFileAnnotation(
 	pathToExternalPath map[string]string,
+	warnRuleIDs map[string]struct{},
+	infoRuleIDs map[string]struct{},
 	annotation *annotation,
 ) bufanalysis.FileAnnotation {
+	severity := bufanalysis.SeverityError
+	// warn takes precedence over info, so that a rule that is in both is never
+	// reported with a lower severity than was asked for.
+	if _, ok := warnRuleIDs[annotation.RuleID()]; ok {
+		severity = bufanalysis.SeverityWarning
+	} else if _, ok := infoRuleIDs[annotation.RuleID()]; ok {
+		severity = bufanalysis.SeverityInfo
+	}
+	message := annotation.Message()
+	switch {
+	case annotation.acknowledged:
+		severity = bufanalysis.SeverityAcknowledged
+		if annotation.acknowledgedReason != "" {
+			message = fmt.Sprintf("%s (reason: %s)", message, annotation.acknowledgedReason)
+		}
+	case annotation.commentIgnoreMissingReason:
+		message = fmt.Sprintf("%s Comment ignores for %s must have a reason.", message, annotation.RuleID())
+	}
 	fileLocation := annotation.FileLocation()
 	if fileLocation == nil {
 		// We have to do this or we get a weird fileInfo != nil but it is nil thing.
@@ -63,8 +95,9 @@ func annotationToFileAnnotation(
 			0,
 			0,
 			annotation.RuleID(),
-			annotation.Message(),
+			message,
 			annotation.PluginName(),
+			bufanalysis.WithSeverity(severity),
 		)
 	}
 	path := fileLocation.FileDescriptor().ProtoreflectFileDescriptor().Path()
@@ -82,7 +115,8 @@ func annotationToFileAnnotation(
 		endLine,
 		endColumn,
 		annotation.RuleID(),
-		annotation.Message(),
+		message,
 		annotation.PluginName(),
+		bufanalysis.WithSeverity(severity),
 	)
 }
@@ -30,6 +30,7 @@ import (
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck"
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
 	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/pkg/slicesext"
 	"github.com/bufbuild/buf/private/pkg/slogtestext"
 	"github.com/bufbuild/buf/private/pkg/storage/storageos"
 	"github.com/bufbuild/buf/private/pkg/wasm"
@@ -997,6 +998,7 @@ func TestRunBreakingMessageSameRequiredFields(t *testing.T) {
 		bufanalysistesting.NewFileAnnotation(t, "1.proto", 29, 3, 29, 27, "MESSAGE_SAME_REQUIRED_FIELDS"),
 		bufanalysistesting.NewFileAnnotation(t, "2.proto", 5, 1, 7, 2, "MESSAGE_SAME_REQUIRED_FIELDS"),
 		bufanalysistesting.NewFileAnnotation(t, "2.proto", 6, 3, 6, 27, "MESSAGE_SAME_REQUIRED_FIELDS"),
+		bufanalysistesting.NewFileAnnotation(t, "3.proto", 5, 1, 8, 2, "MESSAGE_SAME_REQUIRED_FIELDS"),
 	)
 }
 
@@ -1250,6 +1252,56 @@ func TestRunBreakingMessageMessage(t *testing.T) {
 	)
 }
 
+func TestRunBreakingCommentIgnores(t *testing.T) {
+	t.Parallel()
+	fileAnnotations := testGetBreakingFileAnnotations(t, "breaking_comment_ignores")
+	bufanalysistesting.AssertFileAnnotationsEqual(
+		t,
+		[]bufanalysis.FileAnnotation{
+			bufanalysistesting.NewFileAnnotation(t, "1.proto", 6, 1, 8, 2, "FIELD_NO_DELETE"),
+			bufanalysistesting.NewFileAnnotation(t, "1.proto", 12, 3, 12, 8, "FIELD_SAME_TYPE"),
+			bufanalysistesting.NewFileAnnotation(t, "1.proto", 17, 3, 17, 8, "FIELD_SAME_TYPE"),
+		},
+		fileAnnotations,
+	)
+	// Comment ignores acknowledge the breaking changes for their rule only, instead of removing them.
+	assert.Equal(
+		t,
+		[]bufanalysis.Severity{
+			bufanalysis.SeverityAcknowledged,
+			bufanalysis.SeverityAcknowledged,
+			bufanalysis.SeverityError,
+		},
+		slicesext.Map(fileAnnotations, bufanalysis.FileAnnotation.Severity),
+	)
+	assert.Equal(t, "Previously present field \"2\" with name \"two\" on message \"One\" was deleted. (reason: two is no longer used)", fileAnnotations[0].Message())
+}
+
+func TestRunBreakingCommentIgnoresRequireReasons(t *testing.T) {
+	t.Parallel()
+	fileAnnotations := testGetBreakingFileAnnotations(t, "breaking_comment_ignores_require_reasons")
+	bufanalysistesting.AssertFileAnnotationsEqual(
+		t,
+		[]bufanalysis.FileAnnotation{
+			bufanalysistesting.NewFileAnnotation(t, "1.proto", 6, 1, 8, 2, "FIELD_NO_DELETE"),
+			bufanalysistesting.NewFileAnnotation(t, "1.proto", 12, 3, 12, 8, "FIELD_SAME_TYPE"),
+			bufanalysistesting.NewFileAnnotation(t, "1.proto", 17, 3, 17, 8, "FIELD_SAME_TYPE"),
+		},
+		fileAnnotations,
+	)
+	// Comment ignores without a reason do not acknowledge breaking changes.
+	assert.Equal(
+		t,
+		[]bufanalysis.Severity{
+			bufanalysis.SeverityAcknowledged,
+			bufanalysis.SeverityError,
+			bufanalysis.SeverityError,
+		},
+		slicesext.Map(fileAnnotations, bufanalysis.FileAnnotation.Severity),
+	)
+	assert.Contains(t, fileAnnotations[1].Message(), "Comment ignores for FIELD_SAME_TYPE must have a reason.")
+}
+
 func TestRunBreakingWithCustomPlugins(t *testing.T) {
 	t.Parallel()
 	testBreaking(
@@ -1266,6 +1318,22 @@ func testBreaking(
 	relDirPath string,
 	expectedFileAnnotations ...bufanalysis.FileAnnotation,
 ) {
+	fileAnnotations := testGetBreakingFileAnnotations(t, relDirPath)
+	if len(expectedFileAnnotations) == 0 {
+		assert.Empty(t, fileAnnotations)
+		return
+	}
+	bufanalysistesting.AssertFileAnnotationsEqual(
+		t,
+		expectedFileAnnotations,
+		fileAnnotations,
+	)
+}
+
+func testGetBreakingFileAnnotations(
+	t *testing.T,
+	relDirPath string,
+) []bufanalysis.FileAnnotation {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	logger := slogtestext.NewLogger(t)
@@ -1310,8 +1378,10 @@ func testBreaking(
 	workspaceProvider := bufworkspace.NewWorkspaceProvider(
 		logger,
 		bufmodule.NopGraphProvider,
+		bufmodule.NopModuleKeyProvider,
 		bufmodule.NopModuleDataProvider,
 		bufmodule.NopCommitProvider,
+		storageosProvider,
 	)
 	previousWorkspace, err := workspaceProvider.GetWorkspaceForBucket(
 		ctx,
@@ -1354,17 +1424,12 @@ func testBreaking(
 		bufcheck.BreakingWithExcludeImports(),
 		bufcheck.WithPluginConfigs(workspace.PluginConfigs()...),
 	)
-	if len(expectedFileAnnotations) == 0 {
-		assert.NoError(t, err)
-	} else {
-		var fileAnnotationSet bufanalysis.FileAnnotationSet
-		require.ErrorAs(t, err, &fileAnnotationSet)
-		bufanalysistesting.AssertFileAnnotationsEqual(
-			t,
-			expectedFileAnnotations,
-			fileAnnotationSet.FileAnnotations(),
-		)
+	if err == nil {
+		return nil
 	}
+	var fileAnnotationSet bufanalysis.FileAnnotationSet
+	require.ErrorAs(t, err, &fileAnnotationSet)
+	return fileAnnotationSet.FileAnnotations()
 }
 
 func testGetRootOpaqueID(workspace bufworkspace.Workspace, prefix string) (string, error) {
@@ -701,6 +701,20 @@ var (
 		Type:    check.RuleTypeLint,
 		Handler: bufcheckserverhandle.HandleLintImportUsed,
 	}
+	// LintLicenseHeaderDefinedRuleSpecBuilder is a rule spec builder.
+	LintLicenseHeaderDefinedRuleSpecBuilder = &bufcheckserverutil.RuleSpecBuilder{
+		ID:      "LICENSE_HEADER_DEFINED",
+		Purpose: "Checks that all files start with the configured license header.",
+		Type:    check.RuleTypeLint,
+		Handler: bufcheckserverhandle.HandleLintLicenseHeaderDefined,
+	}
+	// LintLicenseHeaderYearRangeRuleSpecBuilder is a rule spec builder.
+	LintLicenseHeaderYearRangeRuleSpecBuilder = &bufcheckserverutil.RuleSpecBuilder{
+		ID:      "LICENSE_HEADER_YEAR_RANGE",
+		Purpose: "Checks that the license headers of all files have the configured year range.",
+		Type:    check.RuleTypeLint,
+		Handler: bufcheckserverhandle.HandleLintLicenseHeaderYearRange,
+	}
 	// LintMessagePascalCaseRuleSpecBuilder is a rule spec builder.
 	LintMessagePascalCaseRuleSpecBuilder = &bufcheckserverutil.RuleSpecBuilder{
 		ID:      "MESSAGE_PASCAL_CASE",
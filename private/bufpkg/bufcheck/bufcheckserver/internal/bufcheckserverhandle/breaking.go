@@ -1797,17 +1797,11 @@ func handleBreakingMessageSameRequiredFields(
 	message bufprotosource.Message,
 	previousMessage bufprotosource.Message,
 ) error {
-	previousNumberToRequiredField, err := bufprotosource.NumberToMessageFieldForLabel(
-		previousMessage,
-		descriptorpb.FieldDescriptorProto_LABEL_REQUIRED,
-	)
+	previousNumberToRequiredField, err := getNumberToRequiredField(previousMessage)
 	if err != nil {
 		return err
 	}
-	numberToRequiredField, err := bufprotosource.NumberToMessageFieldForLabel(
-		message,
-		descriptorpb.FieldDescriptorProto_LABEL_REQUIRED,
-	)
+	numberToRequiredField, err := getNumberToRequiredField(message)
 	if err != nil {
 		return err
 	}
@@ -1838,6 +1832,27 @@ func handleBreakingMessageSameRequiredFields(
 	return nil
 }
 
+// getNumberToRequiredField returns the required fields of the message by number.
+//
+// The cardinality of the field descriptor is used rather than the label, as fields
+// in files using editions are required via the LEGACY_REQUIRED field presence feature.
+func getNumberToRequiredField(message bufprotosource.Message) (map[int]bufprotosource.Field, error) {
+	numberToField, err := bufprotosource.NumberToMessageField(message)
+	if err != nil {
+		return nil, err
+	}
+	for number, field := range numberToField {
+		fieldDescriptor, err := field.AsDescriptor()
+		if err != nil {
+			return nil, err
+		}
+		if fieldDescriptor.Cardinality() != protoreflect.Required {
+			delete(numberToField, number)
+		}
+	}
+	return numberToField, nil
+}
+
 // HandleBreakingReservedEnumNoDelete is a check function.
 var HandleBreakingReservedEnumNoDelete = bufcheckserverutil.NewBreakingEnumPairRuleHandler(handleBreakingReservedEnumNoDelete)
 
@@ -26,6 +26,7 @@ import (
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/bufcheckserver/internal/buflintvalidate"
 	"github.com/bufbuild/buf/private/bufpkg/bufcheck/internal/bufcheckopt"
 	"github.com/bufbuild/buf/private/bufpkg/bufprotosource"
+	"github.com/bufbuild/buf/private/pkg/licenseheader"
 	"github.com/bufbuild/buf/private/pkg/normalpath"
 	"github.com/bufbuild/buf/private/pkg/protodescriptor"
 	"github.com/bufbuild/buf/private/pkg/protoencoding"
@@ -498,6 +499,108 @@ func handleLintImportUsed(
 	return nil
 }
 
+// HandleLintLicenseHeaderDefined is a handle function.
+var HandleLintLicenseHeaderDefined = bufcheckserverutil.NewLintFileRuleHandler(handleLintLicenseHeaderDefined)
+
+func handleLintLicenseHeaderDefined(
+	responseWriter bufcheckserverutil.ResponseWriter,
+	request bufcheckserverutil.Request,
+	file bufprotosource.File,
+) error {
+	licenseTemplate, err := getLicenseHeaderTemplate(request)
+	if err != nil {
+		return err
+	}
+	if _, ok := licenseTemplate.Match(getLicenseHeaderComment(file)); !ok {
+		responseWriter.AddAnnotation(
+			check.WithFileName(file.Path()),
+			check.WithMessage("Files must start with the configured license header."),
+		)
+	}
+	return nil
+}
+
+// HandleLintLicenseHeaderYearRange is a handle function.
+var HandleLintLicenseHeaderYearRange = bufcheckserverutil.NewLintFileRuleHandler(handleLintLicenseHeaderYearRange)
+
+func handleLintLicenseHeaderYearRange(
+	responseWriter bufcheckserverutil.ResponseWriter,
+	request bufcheckserverutil.Request,
+	file bufprotosource.File,
+) error {
+	licenseTemplate, err := getLicenseHeaderTemplate(request)
+	if err != nil {
+		return err
+	}
+	// Files without a license header are reported by LICENSE_HEADER_DEFINED.
+	foundYearRange, ok := licenseTemplate.Match(getLicenseHeaderComment(file))
+	if ok && foundYearRange != licenseTemplate.YearRange() {
+		responseWriter.AddAnnotation(
+			check.WithFileName(file.Path()),
+			check.WithMessagef(
+				"License header has year range %q but should have year range %q.",
+				foundYearRange,
+				licenseTemplate.YearRange(),
+			),
+		)
+	}
+	return nil
+}
+
+func getLicenseHeaderTemplate(request bufcheckserverutil.Request) (licenseheader.Template, error) {
+	templateText, err := bufcheckopt.GetLicenseHeaderTemplate(request.Options())
+	if err != nil {
+		return nil, err
+	}
+	copyrightHolder, err := bufcheckopt.GetLicenseHeaderCopyrightHolder(request.Options())
+	if err != nil {
+		return nil, err
+	}
+	yearRange, err := bufcheckopt.GetLicenseHeaderYearRange(request.Options())
+	if err != nil {
+		return nil, err
+	}
+	if templateText == "" && copyrightHolder == "" && yearRange == "" {
+		return nil, errors.New("license header rules require a license header to be configured")
+	}
+	return licenseheader.NewTemplate(templateText, copyrightHolder, yearRange)
+}
+
+// getLicenseHeaderComment returns the comment that a license header is expected in, that is
+// the first comment before the first element of the file.
+//
+// This is the first leading detached comment of the first element, or the leading comment of
+// the first element if there are no leading detached comments.
+func getLicenseHeaderComment(file bufprotosource.File) string {
+	var firstLocation *descriptorpb.SourceCodeInfo_Location
+	for _, location := range file.FileDescriptor().GetSourceCodeInfo().GetLocation() {
+		// The location with an empty path is the entire file.
+		if len(location.GetPath()) == 0 || len(location.GetSpan()) < 2 {
+			continue
+		}
+		if firstLocation == nil || isSpanBefore(location.GetSpan(), firstLocation.GetSpan()) {
+			firstLocation = location
+		}
+	}
+	if firstLocation == nil {
+		return ""
+	}
+	if leadingDetachedComments := firstLocation.GetLeadingDetachedComments(); len(leadingDetachedComments) > 0 {
+		return leadingDetachedComments[0]
+	}
+	return firstLocation.GetLeadingComments()
+}
+
+// isSpanBefore returns true if the span starts before the other span.
+//
+// Both spans must have at least two elements.
+func isSpanBefore(span []int32, otherSpan []int32) bool {
+	if span[0] != otherSpan[0] {
+		return span[0] < otherSpan[0]
+	}
+	return span[1] < otherSpan[1]
+}
+
 // HandleLintMessagePascalCase is a handle function.
 var HandleLintMessagePascalCase = bufcheckserverutil.NewLintMessageRuleHandler(handleLintMessagePascalCase)
 
@@ -20,6 +20,7 @@ import (
 	"io"
 	"log/slog"
 	"strings"
+	"unicode"
 
 	"buf.build/go/bufplugin/check"
 	"buf.build/go/bufplugin/descriptor"
@@ -358,6 +359,8 @@ func annotationsToFilteredFileAnnotationSetOrError(
 			imageToPathToExternalPath(
 				image,
 			),
+			config.WarnRuleIDs,
+			config.InfoRuleIDs,
 			annotations,
 		)...,
 	)
@@ -379,22 +382,47 @@ func filterAnnotations(
 	)
 }
 
+// ignoreAnnotation returns true if the annotation should be ignored.
+//
+// If the annotation is acknowledged by a comment ignore instead, this returns false and
+// the annotation is marked as acknowledged.
 func ignoreAnnotation(
 	config *config,
 	annotation *annotation,
 ) (bool, error) {
-	if fileLocation := annotation.FileLocation(); fileLocation != nil {
-		ignore, err := ignoreFileLocation(config, annotation.RuleID(), fileLocation)
-		if err != nil {
-			return false, err
-		}
-		if ignore {
-			return true, nil
-		}
+	fileLocation := annotation.FileLocation()
+	if fileLocation != nil && ignoreFileLocation(config, annotation.RuleID(), fileLocation) {
+		return true, nil
+	}
+	if againstFileLocation := annotation.AgainstFileLocation(); againstFileLocation != nil && ignoreFileLocation(config, annotation.RuleID(), againstFileLocation) {
+		return true, nil
+	}
+	// Comment ignores are only read from the location in the current schema, as
+	// lint never has againstLocations.
+	if fileLocation == nil || !config.AllowCommentIgnores || config.CommentIgnorePrefix == "" {
+		return false, nil
+	}
+	found, reason, err := getCommentIgnoreForFileLocation(
+		config.CommentIgnorePrefix,
+		annotation.RuleID(),
+		fileLocation,
+		config.AcknowledgeCommentIgnores,
+	)
+	if err != nil {
+		return false, err
 	}
-	if againstFileLocation := annotation.AgainstFileLocation(); againstFileLocation != nil {
-		return ignoreFileLocation(config, annotation.RuleID(), againstFileLocation)
+	if !found {
+		return false, nil
 	}
+	if !config.AcknowledgeCommentIgnores {
+		return true, nil
+	}
+	if reason == "" && config.RequireCommentIgnoreReasons {
+		annotation.commentIgnoreMissingReason = true
+		return false, nil
+	}
+	annotation.acknowledged = true
+	annotation.acknowledgedReason = reason
 	return false, nil
 }
 
@@ -402,55 +430,78 @@ func ignoreFileLocation(
 	config *config,
 	ruleID string,
 	fileLocation descriptor.FileLocation,
-) (bool, error) {
+) bool {
 	fileDescriptor := fileLocation.FileDescriptor()
 	if config.ExcludeImports && fileDescriptor.IsImport() {
-		return true, nil
+		return true
 	}
 
 	protoreflectFileDescriptor := fileDescriptor.ProtoreflectFileDescriptor()
 	path := protoreflectFileDescriptor.Path()
 	if normalpath.MapHasEqualOrContainingPath(config.IgnoreRootPaths, path, normalpath.Relative) {
-		return true, nil
+		return true
 	}
 	// If the config says to ignore this specific rule for this path, ignore this location, otherwise we look for other forms of ignores.
 	if ignoreRootPaths, ok := config.IgnoreRuleIDToRootPaths[ruleID]; ok && normalpath.MapHasEqualOrContainingPath(ignoreRootPaths, path, normalpath.Relative) {
-		return true, nil
+		return true
 	}
 
 	// Not a great design, but will never be triggered by lint since this is never set.
 	if config.IgnoreUnstablePackages {
 		if packageVersion, ok := protoversion.NewPackageVersionForPackage(string(protoreflectFileDescriptor.Package())); ok {
 			if packageVersion.StabilityLevel() != protoversion.StabilityLevelStable {
-				return true, nil
+				return true
 			}
 		}
 	}
+	return false
+}
 
-	// Not a great design, but will never be triggered by breaking since this is never set.
-	// Therefore, never called for an againstLocation  (since lint never has againstLocations).
-	if config.AllowCommentIgnores && config.CommentIgnorePrefix != "" {
-		sourcePath := fileLocation.SourcePath()
-		if len(sourcePath) == 0 {
-			return false, nil
-		}
-		associatedSourcePaths, err := protosourcepath.GetAssociatedSourcePaths(sourcePath)
-		if err != nil {
-			return false, err
-		}
-		sourceLocations := protoreflectFileDescriptor.SourceLocations()
-		for _, associatedSourcePath := range associatedSourcePaths {
-			sourceLocation := sourceLocations.ByPath(associatedSourcePath)
-			if leadingComments := sourceLocation.LeadingComments; leadingComments != "" {
-				for _, line := range stringutil.SplitTrimLinesNoEmpty(leadingComments) {
-					if checkCommentLineForCheckIgnore(line, config.CommentIgnorePrefix, ruleID) {
-						return true, nil
-					}
+// getCommentIgnoreForFileLocation returns true if the leading comments of the element at the
+// file location, or of its associated elements, have a comment ignore for the ruleID, and the
+// reason of the comment ignore.
+//
+// If there are multiple comment ignores for the ruleID, a comment ignore with a reason is preferred.
+//
+// If exactRuleID is true, the ruleID must be followed by whitespace or the end of the comment
+// line, so that for example a comment ignore for FIELD_NO_DELETE_UNLESS_NUMBER_RESERVED is not
+// a comment ignore for FIELD_NO_DELETE. This is required when comment ignores are acknowledged,
+// as the rest of the comment line is the reason.
+func getCommentIgnoreForFileLocation(
+	commentIgnorePrefix string,
+	ruleID string,
+	fileLocation descriptor.FileLocation,
+	exactRuleID bool,
+) (bool, string, error) {
+	sourcePath := fileLocation.SourcePath()
+	if len(sourcePath) == 0 {
+		return false, "", nil
+	}
+	associatedSourcePaths, err := protosourcepath.GetAssociatedSourcePaths(sourcePath)
+	if err != nil {
+		return false, "", err
+	}
+	sourceLocations := fileLocation.FileDescriptor().ProtoreflectFileDescriptor().SourceLocations()
+	var found bool
+	for _, associatedSourcePath := range associatedSourcePaths {
+		sourceLocation := sourceLocations.ByPath(associatedSourcePath)
+		if leadingComments := sourceLocation.LeadingComments; leadingComments != "" {
+			for _, line := range stringutil.SplitTrimLinesNoEmpty(leadingComments) {
+				if !checkCommentLineForCheckIgnore(line, commentIgnorePrefix, ruleID) {
+					continue
+				}
+				reason, isExactRuleID := getCommentLineCheckIgnoreReason(line, commentIgnorePrefix, ruleID)
+				if exactRuleID && !isExactRuleID {
+					continue
 				}
+				if reason != "" {
+					return true, reason, nil
+				}
+				found = true
 			}
 		}
 	}
-	return false, nil
+	return found, "", nil
 }
 
 // checkCommentLineForCheckIgnore checks that the comment line starts with the configured
@@ -475,6 +526,32 @@ func checkCommentLineForCheckIgnore(
 	return strings.HasPrefix(commentLine, fullIgnorePrefix)
 }
 
+// getCommentLineCheckIgnoreReason returns the reason of a comment ignore, that is the
+// rest of the comment line after the ruleID, and whether the ruleID is followed by whitespace
+// or the end of the comment line.
+//
+// For example, the reason of the following comment is "moved to the v2 package":
+//
+//	// buf:breaking:ignore FIELD_NO_DELETE moved to the v2 package
+//
+// While the following comment has no reason for FIELD_NO_DELETE, as the ruleID is only a
+// prefix of another rule ID:
+//
+//	// buf:breaking:ignore FIELD_NO_DELETE_UNLESS_NUMBER_RESERVED moved to the v2 package
+//
+// Assumes that checkCommentLineForCheckIgnore returned true for the comment line.
+func getCommentLineCheckIgnoreReason(
+	commentLine string,
+	commentIgnorePrefix string,
+	ruleID string,
+) (string, bool) {
+	rest := strings.TrimPrefix(commentLine, commentIgnorePrefix+" "+ruleID)
+	if rest != "" && strings.TrimLeftFunc(rest, unicode.IsSpace) == rest {
+		return "", false
+	}
+	return strings.TrimSpace(rest), true
+}
+
 type lintOptions struct {
 	pluginConfigs []bufconfig.PluginConfig
 }
@@ -25,6 +25,9 @@ const (
 	rpcAllowGoogleProtobufEmptyResponsesKey = "rpc_allow_google_protobuf_empty_responses"
 	serviceSuffixKey                        = "service_suffix"
 	commentExcludesKey                      = "comment_excludes"
+	licenseHeaderTemplateKey                = "license_header_template"
+	licenseHeaderCopyrightHolderKey         = "license_header_copyright_holder"
+	licenseHeaderYearRangeKey               = "license_header_year_range"
 
 	defaultEnumZeroValueSuffix = "_UNSPECIFIED"
 	defaultServiceSuffix       = "Service"
@@ -63,11 +66,19 @@ type OptionsSpec struct {
 	//
 	// All elements must be non-empty.
 	CommentExcludes []string
+	// LicenseHeaderTemplate is the license header template text for the LICENSE_HEADER_* Rules.
+	//
+	// If empty, licenseheader.DefaultTemplateText is used.
+	LicenseHeaderTemplate string
+	// LicenseHeaderCopyrightHolder is the copyright holder for the LICENSE_HEADER_* Rules.
+	LicenseHeaderCopyrightHolder string
+	// LicenseHeaderYearRange is the year range for the LICENSE_HEADER_* Rules.
+	LicenseHeaderYearRange string
 }
 
 // ToOptions builds a option.Options.
 func (o *OptionsSpec) ToOptions() (option.Options, error) {
-	keyToValue := make(map[string]any, 5)
+	keyToValue := make(map[string]any, 9)
 	if value := o.EnumZeroValueSuffix; len(value) > 0 {
 		keyToValue[enumZeroValueSuffixKey] = value
 	}
@@ -86,6 +97,15 @@ func (o *OptionsSpec) ToOptions() (option.Options, error) {
 	if value := o.CommentExcludes; len(value) > 0 {
 		keyToValue[commentExcludesKey] = value
 	}
+	if value := o.LicenseHeaderTemplate; len(value) > 0 {
+		keyToValue[licenseHeaderTemplateKey] = value
+	}
+	if value := o.LicenseHeaderCopyrightHolder; len(value) > 0 {
+		keyToValue[licenseHeaderCopyrightHolderKey] = value
+	}
+	if value := o.LicenseHeaderYearRange; len(value) > 0 {
+		keyToValue[licenseHeaderYearRangeKey] = value
+	}
 	return option.NewOptions(keyToValue)
 }
 
@@ -148,3 +168,24 @@ func GetServiceSuffix(options option.Options) (string, error) {
 func GetCommentExcludes(options option.Options) ([]string, error) {
 	return option.GetStringSliceValue(options, commentExcludesKey)
 }
+
+// GetLicenseHeaderTemplate gets the license header template text.
+//
+// Returns empty if the option is not set.
+func GetLicenseHeaderTemplate(options option.Options) (string, error) {
+	return option.GetStringValue(options, licenseHeaderTemplateKey)
+}
+
+// GetLicenseHeaderCopyrightHolder gets the license header copyright holder.
+//
+// Returns empty if the option is not set.
+func GetLicenseHeaderCopyrightHolder(options option.Options) (string, error) {
+	return option.GetStringValue(options, licenseHeaderCopyrightHolderKey)
+}
+
+// GetLicenseHeaderYearRange gets the license header year range.
+//
+// Returns empty if the option is not set.
+func GetLicenseHeaderYearRange(options option.Options) (string, error) {
+	return option.GetStringValue(options, licenseHeaderYearRangeKey)
+}
@@ -975,6 +975,17 @@ func TestRunStablePackageNoImportUnstable(t *testing.T) {
 	)
 }
 
+func TestRunLicenseHeader(t *testing.T) {
+	t.Parallel()
+	testLint(
+		t,
+		"license_header",
+		bufanalysistesting.NewFileAnnotationNoLocation(t, "b.proto", "LICENSE_HEADER_DEFINED"),
+		bufanalysistesting.NewFileAnnotationNoLocation(t, "c.proto", "LICENSE_HEADER_YEAR_RANGE"),
+		bufanalysistesting.NewFileAnnotationNoLocation(t, "f.proto", "LICENSE_HEADER_DEFINED"),
+	)
+}
+
 func TestRunIgnores1(t *testing.T) {
 	t.Parallel()
 	testLint(
@@ -1316,8 +1327,10 @@ func testLintWithOptions(
 	workspace, err := bufworkspace.NewWorkspaceProvider(
 		logger,
 		bufmodule.NopGraphProvider,
+		bufmodule.NopModuleKeyProvider,
 		bufmodule.NopModuleDataProvider,
 		bufmodule.NopCommitProvider,
+		storageosProvider,
 	).GetWorkspaceForBucket(
 		ctx,
 		readWriteBucket,
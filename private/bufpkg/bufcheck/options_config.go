@@ -21,7 +21,10 @@ import (
 	"github.com/bufbuild/buf/private/bufpkg/bufconfig"
 )
 
-const lintCommentIgnorePrefix = "buf:lint:ignore"
+const (
+	lintCommentIgnorePrefix     = "buf:lint:ignore"
+	breakingCommentIgnorePrefix = "buf:breaking:ignore"
+)
 
 type optionsConfig struct {
 	// DefaultOptions are the options that should be passed to the default check.Client.
@@ -32,7 +35,11 @@ type optionsConfig struct {
 	AllowCommentIgnores    bool
 	IgnoreUnstablePackages bool
 	CommentIgnorePrefix    string
-	ExcludeImports         bool
+	// AcknowledgeCommentIgnores says to report annotations that are ignored by comments
+	// as acknowledged, instead of removing them.
+	AcknowledgeCommentIgnores   bool
+	RequireCommentIgnoreReasons bool
+	ExcludeImports              bool
 }
 
 func optionsConfigForLintConfig(
@@ -63,11 +70,16 @@ type optionsConfigSpec struct {
 	RPCAllowGoogleProtobufEmptyResponses bool
 	ServiceSuffix                        string
 	CommentIgnorePrefix                  string
+	AcknowledgeCommentIgnores            bool
+	RequireCommentIgnoreReasons          bool
 	ExcludeImports                       bool
+	LicenseHeaderTemplate                string
+	LicenseHeaderCopyrightHolder         string
+	LicenseHeaderYearRange               string
 }
 
 func optionsConfigSpecForLintConfig(lintConfig bufconfig.LintConfig) *optionsConfigSpec {
-	return &optionsConfigSpec{
+	optionsConfigSpec := &optionsConfigSpec{
 		AllowCommentIgnores:                  lintConfig.AllowCommentIgnores(),
 		IgnoreUnstablePackages:               false,
 		EnumZeroValueSuffix:                  lintConfig.EnumZeroValueSuffix(),
@@ -76,8 +88,16 @@ func optionsConfigSpecForLintConfig(lintConfig bufconfig.LintConfig) *optionsCon
 		RPCAllowGoogleProtobufEmptyResponses: lintConfig.RPCAllowGoogleProtobufEmptyResponses(),
 		ServiceSuffix:                        lintConfig.ServiceSuffix(),
 		CommentIgnorePrefix:                  lintCommentIgnorePrefix,
+		AcknowledgeCommentIgnores:            false,
+		RequireCommentIgnoreReasons:          false,
 		ExcludeImports:                       false,
 	}
+	if licenseHeaderConfig := lintConfig.LicenseHeaderConfig(); licenseHeaderConfig != nil {
+		optionsConfigSpec.LicenseHeaderTemplate = licenseHeaderConfig.TemplateText()
+		optionsConfigSpec.LicenseHeaderCopyrightHolder = licenseHeaderConfig.CopyrightHolder()
+		optionsConfigSpec.LicenseHeaderYearRange = licenseHeaderConfig.YearRange()
+	}
+	return optionsConfigSpec
 }
 
 func optionsConfigSpecForBreakingConfig(
@@ -85,14 +105,16 @@ func optionsConfigSpecForBreakingConfig(
 	excludeImports bool,
 ) *optionsConfigSpec {
 	return &optionsConfigSpec{
-		AllowCommentIgnores:                  false,
+		AllowCommentIgnores:                  breakingConfig.AllowCommentIgnores(),
 		IgnoreUnstablePackages:               breakingConfig.IgnoreUnstablePackages(),
 		EnumZeroValueSuffix:                  "",
 		RPCAllowSameRequestResponse:          false,
 		RPCAllowGoogleProtobufEmptyRequests:  false,
 		RPCAllowGoogleProtobufEmptyResponses: false,
 		ServiceSuffix:                        "",
-		CommentIgnorePrefix:                  "",
+		CommentIgnorePrefix:                  breakingCommentIgnorePrefix,
+		AcknowledgeCommentIgnores:            true,
+		RequireCommentIgnoreReasons:          breakingConfig.RequireCommentIgnoreReasons(),
 		ExcludeImports:                       excludeImports,
 	}
 }
@@ -104,8 +126,12 @@ func (b *optionsConfigSpec) newOptionsConfig(ruleType check.RuleType) (*optionsC
 		RPCAllowGoogleProtobufEmptyRequests:  b.RPCAllowGoogleProtobufEmptyRequests,
 		RPCAllowGoogleProtobufEmptyResponses: b.RPCAllowGoogleProtobufEmptyResponses,
 		ServiceSuffix:                        b.ServiceSuffix,
+		LicenseHeaderTemplate:                b.LicenseHeaderTemplate,
+		LicenseHeaderCopyrightHolder:         b.LicenseHeaderCopyrightHolder,
+		LicenseHeaderYearRange:               b.LicenseHeaderYearRange,
 	}
-	if b.CommentIgnorePrefix != "" {
+	// Comment ignores are only excluded from the comments checked by lint rules.
+	if b.CommentIgnorePrefix != "" && ruleType == check.RuleTypeLint {
 		optionsSpec.CommentExcludes = []string{b.CommentIgnorePrefix}
 	}
 	options, err := optionsSpec.ToOptions()
@@ -113,10 +139,12 @@ func (b *optionsConfigSpec) newOptionsConfig(ruleType check.RuleType) (*optionsC
 		return nil, err
 	}
 	return &optionsConfig{
-		DefaultOptions:         options,
-		AllowCommentIgnores:    b.AllowCommentIgnores,
-		IgnoreUnstablePackages: b.IgnoreUnstablePackages,
-		CommentIgnorePrefix:    b.CommentIgnorePrefix,
-		ExcludeImports:         b.ExcludeImports,
+		DefaultOptions:              options,
+		AllowCommentIgnores:         b.AllowCommentIgnores,
+		IgnoreUnstablePackages:      b.IgnoreUnstablePackages,
+		CommentIgnorePrefix:         b.CommentIgnorePrefix,
+		AcknowledgeCommentIgnores:   b.AcknowledgeCommentIgnores,
+		RequireCommentIgnoreReasons: b.RequireCommentIgnoreReasons,
+		ExcludeImports:              b.ExcludeImports,
 	}, nil
 }
@@ -37,6 +37,8 @@ func rulesConfigForCheckConfig(
 	return newRulesConfig(
 		checkConfig.UseIDsAndCategories(),
 		checkConfig.ExceptIDsAndCategories(),
+		checkConfig.WarnIDsAndCategories(),
+		checkConfig.InfoIDsAndCategories(),
 		checkConfig.IgnorePaths(),
 		checkConfig.IgnoreIDOrCategoryToPaths(),
 		allRules,
@@ -68,7 +70,21 @@ type rulesConfig struct {
 	//
 	// If no specific RuleIDs were configured, this will return all default RuleIDs that were of
 	// the specified RuleType.
-	RuleIDs         []string
+	RuleIDs []string
+	// WarnRuleIDs contains the RuleIDs that should produce warnings instead of errors.
+	//
+	// Will only contain non-deprecated RuleIDs.
+	// This will only contain RuleIDs of the given RuleType.
+	// This may contain RuleIDs that are not in RuleIDs, in which case they have no effect.
+	WarnRuleIDs map[string]struct{}
+	// InfoRuleIDs contains the RuleIDs that should produce informational FileAnnotations
+	// instead of errors.
+	//
+	// Will only contain non-deprecated RuleIDs.
+	// This will only contain RuleIDs of the given RuleType.
+	// This may contain RuleIDs that are not in RuleIDs, in which case they have no effect.
+	// RuleIDs that are also in WarnRuleIDs produce warnings.
+	InfoRuleIDs     map[string]struct{}
 	IgnoreRootPaths map[string]struct{}
 	// Will only contain non-deprecated RuleIDs.
 	// This will only contain RuleIDs of the given RuleType.
@@ -116,6 +132,10 @@ func newRulesConfig(
 	useRuleIDsAndCategoryIDs []string,
 	// May contain deprecated IDs.
 	exceptRuleIDsAndCategoryIDs []string,
+	// May contain deprecated IDs.
+	warnRuleIDsAndCategoryIDs []string,
+	// May contain deprecated IDs.
+	infoRuleIDsAndCategoryIDs []string,
 	ignoreRootPaths []string,
 	// May contain deprecated IDs.
 	ignoreRuleIDOrCategoryIDToRootPaths map[string][]string,
@@ -134,6 +154,8 @@ func newRulesConfig(
 		return &rulesConfig{
 			RuleType:                ruleType,
 			RuleIDs:                 make([]string, 0),
+			WarnRuleIDs:             make(map[string]struct{}),
+			InfoRuleIDs:             make(map[string]struct{}),
 			IgnoreRootPaths:         make(map[string]struct{}),
 			IgnoreRuleIDToRootPaths: make(map[string]map[string]struct{}),
 			ReferencedDeprecatedRuleIDToReplacementIDs:     make(map[string]map[string]struct{}),
@@ -173,6 +195,8 @@ func newRulesConfig(
 	for _, ids := range [][]string{
 		useRuleIDsAndCategoryIDs,
 		exceptRuleIDsAndCategoryIDs,
+		warnRuleIDsAndCategoryIDs,
+		infoRuleIDsAndCategoryIDs,
 		slicesext.MapKeysToSlice(ignoreRuleIDOrCategoryIDToRootPathMap),
 	} {
 		for _, id := range ids {
@@ -233,6 +257,22 @@ func newRulesConfig(
 	if err != nil {
 		return nil, err
 	}
+	warnRuleIDs, err := transformRuleOrCategoryIDsToRuleIDs(
+		stringutil.SliceToUniqueSortedSliceFilterEmptyStrings(warnRuleIDsAndCategoryIDs),
+		ruleIDToCategoryIDs,
+		categoryIDToRuleIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	infoRuleIDs, err := transformRuleOrCategoryIDsToRuleIDs(
+		stringutil.SliceToUniqueSortedSliceFilterEmptyStrings(infoRuleIDsAndCategoryIDs),
+		ruleIDToCategoryIDs,
+		categoryIDToRuleIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
 	ignoreRuleIDToRootPathMap, err := transformRuleOrCategoryIDToIgnoreRootPathsToRuleIDs(
 		ignoreRuleIDOrCategoryIDToRootPathMap,
 		ruleIDToCategoryIDs,
@@ -251,6 +291,14 @@ func newRulesConfig(
 		exceptRuleIDs,
 		deprecatedRuleIDToReplacementRuleIDs,
 	)
+	warnRuleIDs = transformRuleIDsToUndeprecated(
+		warnRuleIDs,
+		deprecatedRuleIDToReplacementRuleIDs,
+	)
+	infoRuleIDs = transformRuleIDsToUndeprecated(
+		infoRuleIDs,
+		deprecatedRuleIDToReplacementRuleIDs,
+	)
 	ignoreRuleIDToRootPathMap = transformRuleIDToIgnoreRootPathsToUndeprecated(
 		ignoreRuleIDToRootPathMap,
 		deprecatedRuleIDToReplacementRuleIDs,
@@ -316,6 +364,8 @@ func newRulesConfig(
 	return &rulesConfig{
 		RuleType:                ruleType,
 		RuleIDs:                 slicesext.Map(resultRules, Rule.ID),
+		WarnRuleIDs:             slicesext.ToStructMap(warnRuleIDs),
+		InfoRuleIDs:             slicesext.ToStructMap(infoRuleIDs),
 		IgnoreRootPaths:         slicesext.ToStructMap(ignoreRootPaths),
 		IgnoreRuleIDToRootPaths: ignoreRuleIDToRootPathMap,
 		ReferencedDeprecatedRuleIDToReplacementIDs:     referencedDeprecatedRuleIDToReplacementIDs,
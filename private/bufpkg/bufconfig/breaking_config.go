@@ -19,12 +19,16 @@ var (
 	DefaultBreakingConfigV1 BreakingConfig = NewBreakingConfig(
 		defaultCheckConfigV1,
 		false,
+		false,
+		false,
 	)
 
 	// DefaultBreakingConfigV2 is the default breaking config for v1.
 	DefaultBreakingConfigV2 BreakingConfig = NewBreakingConfig(
 		defaultCheckConfigV2,
 		false,
+		false,
+		false,
 	)
 )
 
@@ -33,6 +37,16 @@ type BreakingConfig interface {
 	CheckConfig
 
 	IgnoreUnstablePackages() bool
+	// AllowCommentIgnores returns true if breaking changes can be acknowledged with
+	// "buf:breaking:ignore RULE reason" comments on the element in the current schema.
+	//
+	// Acknowledged breaking changes are still reported, but do not result in a failure.
+	AllowCommentIgnores() bool
+	// RequireCommentIgnoreReasons returns true if comment ignores must have a reason
+	// after the rule ID to acknowledge a breaking change.
+	//
+	// Only used if AllowCommentIgnores is true.
+	RequireCommentIgnoreReasons() bool
 
 	isBreakingConfig()
 }
@@ -41,10 +55,14 @@ type BreakingConfig interface {
 func NewBreakingConfig(
 	checkConfig CheckConfig,
 	ignoreUnstablePackages bool,
+	allowCommentIgnores bool,
+	requireCommentIgnoreReasons bool,
 ) BreakingConfig {
 	return newBreakingConfig(
 		checkConfig,
 		ignoreUnstablePackages,
+		allowCommentIgnores,
+		requireCommentIgnoreReasons,
 	)
 }
 
@@ -53,16 +71,22 @@ func NewBreakingConfig(
 type breakingConfig struct {
 	CheckConfig
 
-	ignoreUnstablePackages bool
+	ignoreUnstablePackages      bool
+	allowCommentIgnores         bool
+	requireCommentIgnoreReasons bool
 }
 
 func newBreakingConfig(
 	checkConfig CheckConfig,
 	ignoreUnstablePackages bool,
+	allowCommentIgnores bool,
+	requireCommentIgnoreReasons bool,
 ) *breakingConfig {
 	return &breakingConfig{
-		CheckConfig:            checkConfig,
-		ignoreUnstablePackages: ignoreUnstablePackages,
+		CheckConfig:                 checkConfig,
+		ignoreUnstablePackages:      ignoreUnstablePackages,
+		allowCommentIgnores:         allowCommentIgnores,
+		requireCommentIgnoreReasons: requireCommentIgnoreReasons,
 	}
 }
 
@@ -70,4 +94,12 @@ func (b *breakingConfig) IgnoreUnstablePackages() bool {
 	return b.ignoreUnstablePackages
 }
 
+func (b *breakingConfig) AllowCommentIgnores() bool {
+	return b.allowCommentIgnores
+}
+
+func (b *breakingConfig) RequireCommentIgnoreReasons() bool {
+	return b.requireCommentIgnoreReasons
+}
+
 func (*breakingConfig) isBreakingConfig() {}
@@ -514,6 +514,13 @@ type externalGeneratePluginConfigV2 struct {
 	IncludeWKT     bool `json:"include_wkt,omitempty" yaml:"include_wkt,omitempty"`
 	// Strategy is only valid with ProtoBuiltin and Local.
 	Strategy *string `json:"strategy,omitempty" yaml:"strategy,omitempty"`
+	// Timeout is only valid with Local. This is a duration such as "30s" or "2m".
+	Timeout *string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// MaxOutputBytes is only valid with Local.
+	MaxOutputBytes *int64 `json:"max_output_bytes,omitempty" yaml:"max_output_bytes,omitempty"`
+	// Env is only valid with Local. This is a pointer so that an empty list, which
+	// means that no environment variables are passed, can be distinguished from unset.
+	Env *[]string `json:"env,omitempty" yaml:"env,omitempty"`
 }
 
 // externalGenerateManagedConfigV2 represents the managed mode config in a v2 buf.gen.yaml file.
@@ -553,7 +560,7 @@ type externalManagedOverrideConfigV2 struct {
 // externalInputConfigV2 is an external input configuration.
 type externalInputConfigV2 struct {
 	// One and only one of Module, Directory, ProtoFile, Tarball, ZipArchive, BinaryImage,
-	// JSONImage and GitRepo must be specified as the format.
+	// JSONImage, TextImage, YAMLImage, GitRepo and Reflect must be specified as the format.
 	Module      *string `json:"module,omitempty" yaml:"module,omitempty"`
 	Directory   *string `json:"directory,omitempty" yaml:"directory,omitempty"`
 	ProtoFile   *string `json:"proto_file,omitempty" yaml:"proto_file,omitempty"`
@@ -564,8 +571,10 @@ type externalInputConfigV2 struct {
 	TextImage   *string `json:"text_image,omitempty" yaml:"text_image,omitempty"`
 	YAMLImage   *string `json:"yaml_image,omitempty" yaml:"yaml_image,omitempty"`
 	GitRepo     *string `json:"git_repo,omitempty" yaml:"git_repo,omitempty"`
-	// Types, TargetPaths and ExcludePaths are available for all formats.
+	Reflect     *string `json:"reflect,omitempty" yaml:"reflect,omitempty"`
+	// Types, ExcludeTypes, TargetPaths and ExcludePaths are available for all formats.
 	Types        []string `json:"types,omitempty" yaml:"types,omitempty"`
+	ExcludeTypes []string `json:"exclude_types,omitempty" yaml:"exclude_types,omitempty"`
 	TargetPaths  []string `json:"paths,omitempty" yaml:"paths,omitempty"`
 	ExcludePaths []string `json:"exclude_paths,omitempty" yaml:"exclude_paths,omitempty"`
 	// The following options are available depending on input format.
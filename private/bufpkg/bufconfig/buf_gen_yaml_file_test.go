@@ -127,6 +127,37 @@ plugins:
 		t,
 		// input
 		`version: v2
+plugins:
+  - local: custom-gen-go
+    out: gen/go
+    timeout: 90s
+    max_output_bytes: 1048576
+    env:
+      - HOME
+      - PATH
+  - local: custom-gen-java
+    out: gen/java
+    env: []
+`,
+		// expected output
+		`version: v2
+plugins:
+  - local: custom-gen-go
+    out: gen/go
+    timeout: 1m30s
+    max_output_bytes: 1048576
+    env:
+      - HOME
+      - PATH
+  - local: custom-gen-java
+    out: gen/java
+    env: []
+`,
+	)
+	testReadWriteBufGenYAMLFileRoundTrip(
+		t,
+		// input
+		`version: v2
 managed:
   disable:
     - module: buf.build/googleapis/googleapis
@@ -196,6 +227,8 @@ inputs:
       - a/b/c/x.proto
       - a/b/d/y.proto
   - directory: x/y/z
+    exclude_types:
+      - "foo.v1.Internal"
   - tarball: a/b/x.tar.gz
   - tarball: c/d/x.tar.zst
     compression: zstd
@@ -207,6 +240,7 @@ inputs:
     include_package_files: true
   - binary_image: image.binpb.gz
     compression: gz
+  - reflect: grpcs+reflect://api.acme.com
 `,
 		// expected output
 		`version: v2
@@ -282,6 +316,8 @@ inputs:
       - a/b/c/x.proto
       - a/b/d/y.proto
   - directory: x/y/z
+    exclude_types:
+      - foo.v1.Internal
   - tarball: a/b/x.tar.gz
   - tarball: c/d/x.tar.zst
     compression: zstd
@@ -293,6 +329,7 @@ inputs:
     include_package_files: true
   - binary_image: image.binpb.gz
     compression: gz
+  - reflect: grpcs+reflect://api.acme.com
 `,
 	)
 }
@@ -433,6 +470,60 @@ plugins:
 `),
 	)
 	require.ErrorContains(t, err, "cannot specify strategy for remote plugin")
+	_, err = ReadBufGenYAMLFile(
+		strings.NewReader(`version: v2
+plugins:
+  - remote: buf.build/protocolbuffers/go
+    timeout: 10s
+    out: .
+`),
+	)
+	require.ErrorContains(t, err, "cannot specify timeout for remote plugin")
+	_, err = ReadBufGenYAMLFile(
+		strings.NewReader(`version: v2
+plugins:
+  - protoc_builtin: cpp
+    max_output_bytes: 1024
+    out: .
+`),
+	)
+	require.ErrorContains(t, err, "cannot specify max_output_bytes for protoc built-in plugin")
+	_, err = ReadBufGenYAMLFile(
+		strings.NewReader(`version: v2
+plugins:
+  - remote: buf.build/protocolbuffers/go
+    env: [HOME]
+    out: .
+`),
+	)
+	require.ErrorContains(t, err, "cannot specify env for remote plugin")
+	_, err = ReadBufGenYAMLFile(
+		strings.NewReader(`version: v2
+plugins:
+  - local: protoc-gen-go
+    timeout: forever
+    out: .
+`),
+	)
+	require.ErrorContains(t, err, "invalid timeout for local plugin protoc-gen-go")
+	_, err = ReadBufGenYAMLFile(
+		strings.NewReader(`version: v2
+plugins:
+  - local: protoc-gen-go
+    max_output_bytes: 0
+    out: .
+`),
+	)
+	require.ErrorContains(t, err, "max_output_bytes for local plugin protoc-gen-go must be positive")
+	_, err = ReadBufGenYAMLFile(
+		strings.NewReader(`version: v2
+plugins:
+  - local: protoc-gen-go
+    env: [HOME=/tmp]
+    out: .
+`),
+	)
+	require.ErrorContains(t, err, `invalid environment variable name "HOME=/tmp" in env`)
 
 	_, err = ReadBufGenYAMLFile(
 		strings.NewReader(`version: v2
@@ -81,6 +81,15 @@ type BufLockFile interface {
 	// Files with FileVersionV1Beta1 or FileVersionV1 will not have PluginKeys.
 	// Only files with FileVersionV2 will have PluginKeys with Digests of DigestTypeP1.
 	RemotePluginKeys() []bufplugin.PluginKey
+	// ExtendsModuleKeys returns the ModuleKeys representing the modules extended by the buf.yaml,
+	// as specified in the buf.lock file.
+	//
+	// All ModuleKeys will have unique FullNames.
+	// ModuleKeys are sorted by FullName.
+	//
+	// Files with FileVersionV1Beta1 or FileVersionV1 will not have extends ModuleKeys.
+	// Only files with FileVersionV2 will have extends ModuleKeys with Digests of DigestTypeB5.
+	ExtendsModuleKeys() []bufmodule.ModuleKey
 
 	isBufLockFile()
 }
@@ -89,8 +98,13 @@ type BufLockFile interface {
 //
 // Note that digests are lazily-loaded; if you need to ensure that all digests are valid, run
 // ValidateBufLockFileDigests().
-func NewBufLockFile(fileVersion FileVersion, depModuleKeys []bufmodule.ModuleKey, pluginKeys []bufplugin.PluginKey) (BufLockFile, error) {
-	return newBufLockFile(fileVersion, nil, depModuleKeys, pluginKeys)
+func NewBufLockFile(
+	fileVersion FileVersion,
+	depModuleKeys []bufmodule.ModuleKey,
+	pluginKeys []bufplugin.PluginKey,
+	extendsModuleKeys []bufmodule.ModuleKey,
+) (BufLockFile, error) {
+	return newBufLockFile(fileVersion, nil, depModuleKeys, pluginKeys, extendsModuleKeys)
 }
 
 // GetBufLockFileForPrefix gets the buf.lock file at the given bucket prefix.
@@ -190,10 +204,11 @@ func BufLockFileWithDigestResolver(
 // *** PRIVATE ***
 
 type bufLockFile struct {
-	fileVersion      FileVersion
-	objectData       ObjectData
-	depModuleKeys    []bufmodule.ModuleKey
-	remotePluginKeys []bufplugin.PluginKey
+	fileVersion       FileVersion
+	objectData        ObjectData
+	depModuleKeys     []bufmodule.ModuleKey
+	remotePluginKeys  []bufplugin.PluginKey
+	extendsModuleKeys []bufmodule.ModuleKey
 }
 
 func newBufLockFile(
@@ -201,10 +216,14 @@ func newBufLockFile(
 	objectData ObjectData,
 	depModuleKeys []bufmodule.ModuleKey,
 	remotePluginKeys []bufplugin.PluginKey,
+	extendsModuleKeys []bufmodule.ModuleKey,
 ) (*bufLockFile, error) {
 	if err := validateNoDuplicateModuleKeysByFullName(depModuleKeys); err != nil {
 		return nil, err
 	}
+	if err := validateNoDuplicateModuleKeysByFullName(extendsModuleKeys); err != nil {
+		return nil, err
+	}
 	if err := validateNoDuplicatePluginKeysByFullName(remotePluginKeys); err != nil {
 		return nil, err
 	}
@@ -216,6 +235,9 @@ func newBufLockFile(
 		if len(remotePluginKeys) > 0 {
 			return nil, errors.New("remote plugins are not supported in v1 or v1beta1 buf.lock files")
 		}
+		if len(extendsModuleKeys) > 0 {
+			return nil, errors.New("extends are not supported in v1 or v1beta1 buf.lock files")
+		}
 	case FileVersionV2:
 		if err := validateModuleExpectedDigestType(depModuleKeys, fileVersion, bufmodule.DigestTypeB5); err != nil {
 			return nil, err
@@ -223,6 +245,9 @@ func newBufLockFile(
 		if err := validatePluginExpectedDigestType(remotePluginKeys, fileVersion, bufplugin.DigestTypeP1); err != nil {
 			return nil, err
 		}
+		if err := validateModuleExpectedDigestType(extendsModuleKeys, fileVersion, bufmodule.DigestTypeB5); err != nil {
+			return nil, err
+		}
 	default:
 		return nil, syserror.Newf("unknown FileVersion: %v", fileVersion)
 	}
@@ -241,11 +266,19 @@ func newBufLockFile(
 			return remotePluginKeys[i].FullName().String() < remotePluginKeys[j].FullName().String()
 		},
 	)
+	extendsModuleKeys = slicesext.Copy(extendsModuleKeys)
+	sort.Slice(
+		extendsModuleKeys,
+		func(i int, j int) bool {
+			return extendsModuleKeys[i].FullName().String() < extendsModuleKeys[j].FullName().String()
+		},
+	)
 	bufLockFile := &bufLockFile{
-		fileVersion:      fileVersion,
-		objectData:       objectData,
-		depModuleKeys:    depModuleKeys,
-		remotePluginKeys: remotePluginKeys,
+		fileVersion:       fileVersion,
+		objectData:        objectData,
+		depModuleKeys:     depModuleKeys,
+		remotePluginKeys:  remotePluginKeys,
+		extendsModuleKeys: extendsModuleKeys,
 	}
 	if err := validateV1AndV1Beta1DepsHaveCommits(bufLockFile); err != nil {
 		return nil, err
@@ -273,6 +306,10 @@ func (l *bufLockFile) RemotePluginKeys() []bufplugin.PluginKey {
 	return l.remotePluginKeys
 }
 
+func (l *bufLockFile) ExtendsModuleKeys() []bufmodule.ModuleKey {
+	return l.extendsModuleKeys
+}
+
 func (*bufLockFile) isBufLockFile() {}
 func (*bufLockFile) isFile()        {}
 func (*bufLockFile) isFileInfo()    {}
@@ -347,47 +384,19 @@ func readBufLockFile(
 			}
 			depModuleKeys[i] = depModuleKey
 		}
-		return newBufLockFile(fileVersion, objectData, depModuleKeys, nil /* remotePluginKeys */)
+		return newBufLockFile(fileVersion, objectData, depModuleKeys, nil /* remotePluginKeys */, nil /* extendsModuleKeys */)
 	case FileVersionV2:
 		var externalBufLockFile externalBufLockFileV2
 		if err := getUnmarshalStrict(allowJSON)(data, &externalBufLockFile); err != nil {
 			return nil, fmt.Errorf("invalid as version %v: %w", fileVersion, err)
 		}
-		depModuleKeys := make([]bufmodule.ModuleKey, len(externalBufLockFile.Deps))
-		for i, dep := range externalBufLockFile.Deps {
-			dep := dep
-			if dep.Name == "" {
-				return nil, errors.New("no module name specified")
-			}
-			moduleFullName, err := bufparse.ParseFullName(dep.Name)
-			if err != nil {
-				return nil, fmt.Errorf("invalid module name: %w", err)
-			}
-			if dep.Commit == "" {
-				return nil, fmt.Errorf("no commit specified for module %s", moduleFullName.String())
-			}
-			if dep.Digest == "" {
-				return nil, fmt.Errorf("no digest specified for module %s", moduleFullName.String())
-			}
-			if deprecatedDigestType := getDeprecatedDigestTypeForExternalDigest(dep.Digest); deprecatedDigestType != "" {
-				// TODO: Add a message about downgrading the buf cli to a version that supports this.
-				return nil, fmt.Errorf(`%s digests are no longer supported as of v1.32.0, run "buf mod update" to update your buf.lock`, deprecatedDigestType)
-			}
-			commitID, err := uuidutil.FromDashless(dep.Commit)
-			if err != nil {
-				return nil, err
-			}
-			depModuleKey, err := bufmodule.NewModuleKey(
-				moduleFullName,
-				commitID,
-				func() (bufmodule.Digest, error) {
-					return bufmodule.ParseDigest(dep.Digest)
-				},
-			)
-			if err != nil {
-				return nil, err
-			}
-			depModuleKeys[i] = depModuleKey
+		depModuleKeys, err := getModuleKeysForExternalDepsV2(externalBufLockFile.Deps)
+		if err != nil {
+			return nil, err
+		}
+		extendsModuleKeys, err := getModuleKeysForExternalDepsV2(externalBufLockFile.Extends)
+		if err != nil {
+			return nil, err
 		}
 		remotePluginKeys := make([]bufplugin.PluginKey, len(externalBufLockFile.Plugins))
 		for i, plugin := range externalBufLockFile.Plugins {
@@ -421,7 +430,7 @@ func readBufLockFile(
 			}
 			remotePluginKeys[i] = pluginKey
 		}
-		return newBufLockFile(fileVersion, objectData, depModuleKeys, remotePluginKeys)
+		return newBufLockFile(fileVersion, objectData, depModuleKeys, remotePluginKeys, extendsModuleKeys)
 	default:
 		// This is a system error since we've already parsed.
 		return nil, syserror.Newf("unknown FileVersion: %v", fileVersion)
@@ -465,10 +474,12 @@ func writeBufLockFile(
 	case FileVersionV2:
 		depModuleKeys := bufLockFile.DepModuleKeys()
 		remotePluginKeys := bufLockFile.RemotePluginKeys()
+		extendsModuleKeys := bufLockFile.ExtendsModuleKeys()
 		externalBufLockFile := externalBufLockFileV2{
 			Version: fileVersion.String(),
 			Deps:    make([]externalBufLockFileDepV2, len(depModuleKeys)),
 			Plugins: make([]externalBufLockFileDepV2, len(remotePluginKeys)),
+			Extends: make([]externalBufLockFileDepV2, len(extendsModuleKeys)),
 		}
 		for i, depModuleKey := range depModuleKeys {
 			digest, err := depModuleKey.Digest()
@@ -492,6 +503,17 @@ func writeBufLockFile(
 				Digest: digest.String(),
 			}
 		}
+		for i, extendsModuleKey := range extendsModuleKeys {
+			digest, err := extendsModuleKey.Digest()
+			if err != nil {
+				return err
+			}
+			externalBufLockFile.Extends[i] = externalBufLockFileDepV2{
+				Name:   extendsModuleKey.FullName().String(),
+				Commit: uuidutil.ToDashless(extendsModuleKey.CommitID()),
+				Digest: digest.String(),
+			}
+		}
 		// No need to sort - depModuleKeys is already sorted by FullName
 		data, err := encoding.MarshalYAML(&externalBufLockFile)
 		if err != nil {
@@ -505,6 +527,46 @@ func writeBufLockFile(
 	}
 }
 
+func getModuleKeysForExternalDepsV2(externalDeps []externalBufLockFileDepV2) ([]bufmodule.ModuleKey, error) {
+	depModuleKeys := make([]bufmodule.ModuleKey, len(externalDeps))
+	for i, dep := range externalDeps {
+		dep := dep
+		if dep.Name == "" {
+			return nil, errors.New("no module name specified")
+		}
+		moduleFullName, err := bufparse.ParseFullName(dep.Name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid module name: %w", err)
+		}
+		if dep.Commit == "" {
+			return nil, fmt.Errorf("no commit specified for module %s", moduleFullName.String())
+		}
+		if dep.Digest == "" {
+			return nil, fmt.Errorf("no digest specified for module %s", moduleFullName.String())
+		}
+		if deprecatedDigestType := getDeprecatedDigestTypeForExternalDigest(dep.Digest); deprecatedDigestType != "" {
+			// TODO: Add a message about downgrading the buf cli to a version that supports this.
+			return nil, fmt.Errorf(`%s digests are no longer supported as of v1.32.0, run "buf mod update" to update your buf.lock`, deprecatedDigestType)
+		}
+		commitID, err := uuidutil.FromDashless(dep.Commit)
+		if err != nil {
+			return nil, err
+		}
+		depModuleKey, err := bufmodule.NewModuleKey(
+			moduleFullName,
+			commitID,
+			func() (bufmodule.Digest, error) {
+				return bufmodule.ParseDigest(dep.Digest)
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+		depModuleKeys[i] = depModuleKey
+	}
+	return depModuleKeys, nil
+}
+
 func isDeprecatedExternalDigest(externalDigest string) bool {
 	return getDeprecatedDigestTypeForExternalDigest(externalDigest) != ""
 }
@@ -637,6 +699,7 @@ type externalBufLockFileV2 struct {
 	Version string                     `json:"version,omitempty" yaml:"version,omitempty"`
 	Deps    []externalBufLockFileDepV2 `json:"deps,omitempty" yaml:"deps,omitempty"`
 	Plugins []externalBufLockFileDepV2 `json:"plugins,omitempty" yaml:"plugins,omitempty"`
+	Extends []externalBufLockFileDepV2 `json:"extends,omitempty" yaml:"extends,omitempty"`
 }
 
 // externalBufLockFileDepV2 represents a single dep within a v2 buf.lock file.
@@ -25,6 +25,7 @@ import (
 	"path/filepath"
 	"sort"
 
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulesign"
 	"github.com/bufbuild/buf/private/bufpkg/bufparse"
 	"github.com/bufbuild/buf/private/pkg/encoding"
 	"github.com/bufbuild/buf/private/pkg/normalpath"
@@ -111,6 +112,41 @@ type BufYAMLFile interface {
 	// The ModuleRefs in this list will be unique by FullName.
 	// Sorted by FullName.
 	ConfiguredDepModuleRefs() []bufparse.Ref
+	// Extends returns the configuration files that the File extends, in the order they
+	// are specified in the buf.yaml.
+	//
+	// Entries that end in .yaml or .yml are paths to local files, relative to the directory
+	// of the buf.yaml. All other entries are module references.
+	//
+	// The lint, breaking, and plugin settings of the extended files are not part of the
+	// File until it is resolved with ResolveBufYAMLFile.
+	//
+	// For v1 buf.yaml files, this will always return nil.
+	Extends() []string
+	// ConfiguredExtendsModuleRefs returns the module references in Extends as ModuleRefs.
+	//
+	// The ModuleRefs in this list will be unique by FullName.
+	// Sorted by FullName.
+	ConfiguredExtendsModuleRefs() []bufparse.Ref
+	// ReplaceConfigs returns the dependency replacements of the File.
+	//
+	// Replacements are only applied to local builds, and are never written to buf.lock files.
+	//
+	// The ReplaceConfigs in this list will be unique by FullName.
+	// Sorted by FullName.
+	//
+	// For v1 buf.yaml files, this will always return nil.
+	ReplaceConfigs() []ReplaceConfig
+	// VerifyConfigs returns the signature verification policy of the File.
+	//
+	// Remote dependencies of an owner with a VerifyConfig must be signed by one of
+	// the public keys of the VerifyConfig.
+	//
+	// The VerifyConfigs in this list will be unique by owner.
+	// Sorted by owner.
+	//
+	// For v1 buf.yaml files, this will always return nil.
+	VerifyConfigs() []VerifyConfig
 	//IncludeDocsLink specifies whether a top-level comment with a link to our public docs
 	// should be included at the top of the buf.yaml file.
 	IncludeDocsLink() bool
@@ -140,6 +176,9 @@ func NewBufYAMLFile(
 		nil, // Do not set top-level breaking config, use only module configs
 		pluginConfigs,
 		configuredDepModuleRefs,
+		nil,
+		nil,
+		nil,
 		bufYAMLFileOptions.includeDocsLink,
 	)
 }
@@ -244,6 +283,86 @@ func WriteBufYAMLFile(writer io.Writer, bufYAMLFile BufYAMLFile) error {
 	return writeFile(writer, bufYAMLFile, writeBufYAMLFile)
 }
 
+// ResolveBufYAMLFile returns the BufYAMLFile with the lint, breaking, and plugin settings of the
+// files it extends merged into its top-level settings.
+//
+// The extended files are merged in the order they are listed in Extends, and the settings of
+// the BufYAMLFile itself are merged last:
+//
+//   - use, warn and info are the union of the values of all files.
+//   - except is the value of the last file that sets except.
+//   - ignore is the union of the values of all files, and ignore_only is the union of the
+//     values of all files for each rule or category.
+//   - Booleans are true if any file sets them. Other values are the value of the last file
+//     that sets them.
+//   - plugins are the union of the plugins of all files. A plugin in a later file replaces
+//     the same plugin in an earlier file.
+//
+// Paths in extended files are relative to the directory of the BufYAMLFile, as if they had
+// been specified in the BufYAMLFile. Module-level lint and breaking sections replace the
+// resolved top-level sections, as they replace the top-level sections without extends.
+//
+// Local files are read from the bucket, which must be rooted at the directory of the BufYAMLFile.
+// The buf.yaml files of modules are read with getModuleBufYAMLData. If getModuleBufYAMLData
+// is nil, extended modules are skipped. Extended files cannot have extends themselves.
+//
+// The returned BufYAMLFile has no Extends. If the BufYAMLFile has no Extends, it is returned as-is.
+func ResolveBufYAMLFile(
+	ctx context.Context,
+	unresolvedBufYAMLFile BufYAMLFile,
+	bucket storage.ReadBucket,
+	getModuleBufYAMLData func(context.Context, bufparse.Ref) ([]byte, error),
+) (BufYAMLFile, error) {
+	extends := unresolvedBufYAMLFile.Extends()
+	if len(extends) == 0 {
+		return unresolvedBufYAMLFile, nil
+	}
+	c, ok := unresolvedBufYAMLFile.(*bufYAMLFile)
+	if !ok || c.externalBufYAMLFileV2 == nil {
+		return nil, syserror.Newf("cannot resolve BufYAMLFile of type %T that was not read from an external file", unresolvedBufYAMLFile)
+	}
+	var externalExtendedFiles []externalBufYAMLFileV2
+	for _, extend := range extends {
+		var data []byte
+		var err error
+		if isLocalExtends(extend) {
+			path, err := normalpath.NormalizeAndValidate(extend)
+			if err != nil {
+				return nil, fmt.Errorf("invalid extends path %q: %w", extend, err)
+			}
+			data, err = storage.ReadPath(ctx, bucket, path)
+			if err != nil {
+				return nil, fmt.Errorf("could not read extended file %q: %w", extend, err)
+			}
+		} else {
+			if getModuleBufYAMLData == nil {
+				continue
+			}
+			moduleRef, err := bufparse.ParseRef(extend)
+			if err != nil {
+				return nil, fmt.Errorf("invalid extends: %w", err)
+			}
+			data, err = getModuleBufYAMLData(ctx, moduleRef)
+			if err != nil {
+				return nil, err
+			}
+		}
+		externalExtendedFile, err := getExternalBufYAMLFileV2ForExtendedData(data)
+		if err != nil {
+			return nil, newDecodeError(extend, err)
+		}
+		externalExtendedFiles = append(externalExtendedFiles, externalExtendedFile)
+	}
+	externalBufYAMLFile := mergeExternalBufYAMLFilesV2(
+		append(externalExtendedFiles, *c.externalBufYAMLFileV2)...,
+	)
+	return readBufYAMLFileV2(
+		c.ObjectData(),
+		externalBufYAMLFile,
+		c.IncludeDocsLink(),
+	)
+}
+
 // *** PRIVATE ***
 
 type bufYAMLFile struct {
@@ -254,7 +373,16 @@ type bufYAMLFile struct {
 	topLevelBreakingConfig  BreakingConfig
 	pluginConfigs           []PluginConfig
 	configuredDepModuleRefs []bufparse.Ref
-	includeDocsLink         bool
+	extends                 []string
+	// Sorted by FullName.
+	configuredExtendsModuleRefs []bufparse.Ref
+	replaceConfigs              []ReplaceConfig
+	verifyConfigs               []VerifyConfig
+	includeDocsLink             bool
+	// externalBufYAMLFileV2 is the external file that this file was read from.
+	//
+	// This is only set for v2 files with extends, and is used to resolve the file.
+	externalBufYAMLFileV2 *externalBufYAMLFileV2
 }
 
 func newBufYAMLFile(
@@ -265,8 +393,20 @@ func newBufYAMLFile(
 	topLevelBreakingConfig BreakingConfig,
 	pluginConfigs []PluginConfig,
 	configuredDepModuleRefs []bufparse.Ref,
+	extends []string,
+	replaceConfigs []ReplaceConfig,
+	verifyConfigs []VerifyConfig,
 	includeDocsLink bool,
 ) (*bufYAMLFile, error) {
+	if (fileVersion == FileVersionV1Beta1 || fileVersion == FileVersionV1) && len(extends) > 0 {
+		return nil, fmt.Errorf("extends cannot be set on version %v", fileVersion)
+	}
+	if (fileVersion == FileVersionV1Beta1 || fileVersion == FileVersionV1) && len(replaceConfigs) > 0 {
+		return nil, fmt.Errorf("replace cannot be set on version %v", fileVersion)
+	}
+	if (fileVersion == FileVersionV1Beta1 || fileVersion == FileVersionV1) && len(verifyConfigs) > 0 {
+		return nil, fmt.Errorf("verify cannot be set on version %v", fileVersion)
+	}
 	if (fileVersion == FileVersionV1Beta1 || fileVersion == FileVersionV1) && len(moduleConfigs) > 1 {
 		return nil, fmt.Errorf("had %d ModuleConfigs passed to NewBufYAMLFile for FileVersion %v", len(moduleConfigs), fileVersion)
 	}
@@ -296,6 +436,19 @@ func newBufYAMLFile(
 	if _, err := bufparse.FullNameStringToUniqueValue(configuredDepModuleRefs); err != nil {
 		return nil, err
 	}
+	if _, err := bufparse.FullNameStringToUniqueValue(replaceConfigs); err != nil {
+		return nil, err
+	}
+	for _, replaceConfig := range replaceConfigs {
+		for _, moduleConfig := range moduleConfigs {
+			if moduleFullName := moduleConfig.FullName(); moduleFullName != nil && moduleFullName.String() == replaceConfig.FullName().String() {
+				return nil, fmt.Errorf("cannot replace %s as it is a module in the workspace", moduleFullName.String())
+			}
+		}
+	}
+	if _, err := slicesext.ToUniqueValuesMap(verifyConfigs, getVerifyConfigOwnerString); err != nil {
+		return nil, fmt.Errorf("verify owners must be unique: %w", err)
+	}
 	// Since multiple module configs with the same DirPath are allowed in v2, we need a stable sort
 	// so that the relative order among module configs with the same DirPath is preserved from the
 	// external buf.yaml, as specified in BufYAMLFile.ModuleConfigs' doc.
@@ -312,15 +465,37 @@ func newBufYAMLFile(
 				configuredDepModuleRefs[j].FullName().String()
 		},
 	)
+	sort.Slice(
+		replaceConfigs,
+		func(i int, j int) bool {
+			return replaceConfigs[i].FullName().String() <
+				replaceConfigs[j].FullName().String()
+		},
+	)
+	sort.Slice(
+		verifyConfigs,
+		func(i int, j int) bool {
+			return getVerifyConfigOwnerString(verifyConfigs[i]) <
+				getVerifyConfigOwnerString(verifyConfigs[j])
+		},
+	)
+	configuredExtendsModuleRefs, err := getConfiguredExtendsModuleRefsForExtends(extends)
+	if err != nil {
+		return nil, err
+	}
 	return &bufYAMLFile{
-		fileVersion:             fileVersion,
-		objectData:              objectData,
-		moduleConfigs:           moduleConfigs,
-		topLevelLintConfig:      topLevelLintConfig,
-		topLevelBreakingConfig:  topLevelBreakingConfig,
-		pluginConfigs:           pluginConfigs,
-		configuredDepModuleRefs: configuredDepModuleRefs,
-		includeDocsLink:         includeDocsLink,
+		fileVersion:                 fileVersion,
+		objectData:                  objectData,
+		moduleConfigs:               moduleConfigs,
+		topLevelLintConfig:          topLevelLintConfig,
+		topLevelBreakingConfig:      topLevelBreakingConfig,
+		pluginConfigs:               pluginConfigs,
+		configuredDepModuleRefs:     configuredDepModuleRefs,
+		extends:                     extends,
+		configuredExtendsModuleRefs: configuredExtendsModuleRefs,
+		replaceConfigs:              replaceConfigs,
+		verifyConfigs:               verifyConfigs,
+		includeDocsLink:             includeDocsLink,
 	}, nil
 }
 
@@ -356,6 +531,22 @@ func (c *bufYAMLFile) ConfiguredDepModuleRefs() []bufparse.Ref {
 	return slicesext.Copy(c.configuredDepModuleRefs)
 }
 
+func (c *bufYAMLFile) Extends() []string {
+	return c.extends
+}
+
+func (c *bufYAMLFile) ConfiguredExtendsModuleRefs() []bufparse.Ref {
+	return c.configuredExtendsModuleRefs
+}
+
+func (c *bufYAMLFile) ReplaceConfigs() []ReplaceConfig {
+	return slicesext.Copy(c.replaceConfigs)
+}
+
+func (c *bufYAMLFile) VerifyConfigs() []VerifyConfig {
+	return slicesext.Copy(c.verifyConfigs)
+}
+
 func (c *bufYAMLFile) IncludeDocsLink() bool {
 	return c.includeDocsLink
 }
@@ -455,6 +646,9 @@ func readBufYAMLFile(
 			breakingConfig,
 			nil,
 			configuredDepModuleRefs,
+			nil,
+			nil,
+			nil,
 			includeDocsLink,
 		)
 	case FileVersionV2:
@@ -462,212 +656,274 @@ func readBufYAMLFile(
 		if err := getUnmarshalStrict(allowJSON)(data, &externalBufYAMLFile); err != nil {
 			return nil, fmt.Errorf("invalid as version %v: %w", fileVersion, err)
 		}
-		externalModules := externalBufYAMLFile.Modules
-		if len(externalModules) == 0 {
-			externalModules = []externalBufYAMLFileModuleV2{
-				{
-					Path: ".",
-					Name: externalBufYAMLFile.Name,
-				},
-			}
-		} else if externalBufYAMLFile.Name != "" {
-			return nil, errors.New("top-level name key cannot be specified if modules are specified, you must specify the name on each individual module, the top-level name key is only for the default case where you have one module at path \".\".")
-		}
-		// If a module does not have its own lint section, then we use this as the default.
-		defaultExternalLintConfig := externalBufYAMLFile.Lint
-		defaultExternalBreakingConfig := externalBufYAMLFile.Breaking
-		var moduleConfigs []ModuleConfig
-		for _, externalModule := range externalModules {
-			dirPath := externalModule.Path
-			if dirPath == "" {
-				dirPath = "."
-			}
-			dirPath, err := normalpath.NormalizeAndValidate(dirPath)
+		bufYAMLFile, err := readBufYAMLFileV2(objectData, externalBufYAMLFile, includeDocsLink)
+		if err != nil {
+			return nil, err
+		}
+		if len(externalBufYAMLFile.Extends) > 0 {
+			bufYAMLFile.externalBufYAMLFileV2 = &externalBufYAMLFile
+		}
+		return bufYAMLFile, nil
+	default:
+		// This is a system error since we've already parsed.
+		return nil, syserror.Newf("unknown FileVersion: %v", fileVersion)
+	}
+}
+
+// readBufYAMLFileV2 reads the BufYAMLFile from the v2 external file.
+//
+// The extends of the external file are not resolved, see ResolveBufYAMLFile.
+func readBufYAMLFileV2(
+	objectData ObjectData,
+	externalBufYAMLFile externalBufYAMLFileV2,
+	includeDocsLink bool,
+) (*bufYAMLFile, error) {
+	fileVersion := FileVersionV2
+	externalModules := externalBufYAMLFile.Modules
+	if len(externalModules) == 0 {
+		externalModules = []externalBufYAMLFileModuleV2{
+			{
+				Path: ".",
+				Name: externalBufYAMLFile.Name,
+			},
+		}
+	} else if externalBufYAMLFile.Name != "" {
+		return nil, errors.New("top-level name key cannot be specified if modules are specified, you must specify the name on each individual module, the top-level name key is only for the default case where you have one module at path \".\".")
+	}
+	// If a module does not have its own lint section, then we use this as the default.
+	defaultExternalLintConfig := externalBufYAMLFile.Lint
+	defaultExternalBreakingConfig := externalBufYAMLFile.Breaking
+	var moduleConfigs []ModuleConfig
+	for _, externalModule := range externalModules {
+		dirPath := externalModule.Path
+		if dirPath == "" {
+			dirPath = "."
+		}
+		dirPath, err := normalpath.NormalizeAndValidate(dirPath)
+		if err != nil {
+			return nil, fmt.Errorf("invalid module path: %w", err)
+		}
+		var moduleFullName bufparse.FullName
+		if externalModule.Name != "" {
+			moduleFullName, err = bufparse.ParseFullName(externalModule.Name)
 			if err != nil {
-				return nil, fmt.Errorf("invalid module path: %w", err)
+				return nil, err
 			}
-			var moduleFullName bufparse.FullName
-			if externalModule.Name != "" {
-				moduleFullName, err = bufparse.ParseFullName(externalModule.Name)
+		}
+		// Makes sure that the given paths are normalized, validated, and contained within dirPath.
+		//
+		// Run this check for includes, excludes, and lint and breaking change paths.
+		//
+		// We first check that a given path is within a module before passing it to this function
+		// if the path came from defaultExternalLintConfig or defaultExternalBreakingConfig.
+		//
+		// Includes and excludes may be glob patterns, see normalpath.ValidateGlob.
+		normalIncludes, err := normalizeAndCheckPathsOrGlobs(externalModule.Includes, "include")
+		if err != nil {
+			// user error
+			return nil, err
+		}
+		relIncludes, err := slicesext.MapError(
+			normalIncludes,
+			func(normalInclude string) (string, error) {
+				if normalInclude == dirPath {
+					return "", fmt.Errorf("include path %q is equal to module directory %q", normalInclude, dirPath)
+				}
+				if !isPathOrGlobContainedInDirPath(dirPath, normalInclude) {
+					return "", fmt.Errorf("include path %q does not reside within module directory %q", normalInclude, dirPath)
+				}
+				// Glob patterns may match files, this is how .proto files are selected by pattern.
+				if !normalpath.IsGlob(normalInclude) && normalpath.Ext(normalInclude) == ".proto" {
+					return "", fmt.Errorf("includes can only be directories but file %q discovered", normalInclude)
+				}
+				// An include path must be made relative to its moduleDirPath.
+				return normalpath.Rel(dirPath, normalInclude)
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+		// The only root for v2 buf.yamls must be "." and relIncludes are already relative to the moduleDirPath.
+		rootToIncludes := map[string][]string{".": relIncludes}
+		relExcludes, err := slicesext.MapError(
+			externalModule.Excludes,
+			func(normalExclude string) (string, error) {
+				normalExclude, err := normalpath.NormalizeAndValidate(normalExclude)
 				if err != nil {
-					return nil, err
+					// user error
+					return "", fmt.Errorf("invalid exclude path: %w", err)
 				}
-			}
-			// Makes sure that the given paths are normalized, validated, and contained within dirPath.
-			//
-			// Run this check for includes, excludes, and lint and breaking change paths.
-			//
-			// We first check that a given path is within a module before passing it to this function
-			// if the path came from defaultExternalLintConfig or defaultExternalBreakingConfig.
-			normalIncludes, err := normalizeAndCheckPaths(externalModule.Includes, "include")
-			if err != nil {
-				// user error
-				return nil, err
-			}
-			relIncludes, err := slicesext.MapError(
-				normalIncludes,
-				func(normalInclude string) (string, error) {
-					if normalInclude == dirPath {
-						return "", fmt.Errorf("include path %q is equal to module directory %q", normalInclude, dirPath)
-					}
-					if !normalpath.EqualsOrContainsPath(dirPath, normalInclude, normalpath.Relative) {
-						return "", fmt.Errorf("include path %q does not reside within module directory %q", normalInclude, dirPath)
-					}
-					if normalpath.Ext(normalInclude) == ".proto" {
-						return "", fmt.Errorf("includes can only be directories but file %q discovered", normalInclude)
-					}
-					// An include path must be made relative to its moduleDirPath.
-					return normalpath.Rel(dirPath, normalInclude)
-				},
-			)
-			if err != nil {
-				return nil, err
-			}
-			// The only root for v2 buf.yamls must be "." and relIncludes are already relative to the moduleDirPath.
-			rootToIncludes := map[string][]string{".": relIncludes}
-			relExcludes, err := slicesext.MapError(
-				externalModule.Excludes,
-				func(normalExclude string) (string, error) {
-					normalExclude, err := normalpath.NormalizeAndValidate(normalExclude)
-					if err != nil {
-						// user error
-						return "", fmt.Errorf("invalid exclude path: %w", err)
-					}
-					if normalExclude == dirPath {
-						return "", fmt.Errorf("exclude path %q is equal to module directory %q", normalExclude, dirPath)
-					}
-					if !normalpath.EqualsOrContainsPath(dirPath, normalExclude, normalpath.Relative) {
-						return "", fmt.Errorf("exclude path %q does not reside within module directory %q", normalExclude, dirPath)
-					}
-					if len(normalIncludes) > 0 {
-						// Each exclude path must be contained in some include path. It is invalid to say include "proto/foo/v1"
-						// and also exclude "proto/foo/v2", because the exclude path is redundant.
-						var foundContainingInclude bool
-						// We iterate through normalIncludes instead of relIncludes so that when we compare an exclude
-						// path to an include path, they are both relative to the workspace root.
-						for _, normalInclude := range normalIncludes {
-							if normalInclude == normalExclude {
-								return "", fmt.Errorf("%q is both an include path and an exclude path", normalExclude)
-							}
-							if normalpath.ContainsPath(normalExclude, normalInclude, normalpath.Relative) {
-								return "", fmt.Errorf("%q (an include path) is a subdirectory of %q (an exclude path)", normalInclude, normalExclude)
-							}
-							if normalpath.ContainsPath(normalInclude, normalExclude, normalpath.Relative) {
-								foundContainingInclude = true
-								// Do not exit early here, continue to validate the exclude path against the rest of include paths,
-								// to make sure the exclude path does not equal to or contains any of the include path.
-							}
+				if normalExclude == dirPath {
+					return "", fmt.Errorf("exclude path %q is equal to module directory %q", normalExclude, dirPath)
+				}
+				if !isPathOrGlobContainedInDirPath(dirPath, normalExclude) {
+					return "", fmt.Errorf("exclude path %q does not reside within module directory %q", normalExclude, dirPath)
+				}
+				// We cannot know statically which paths a glob pattern matches, so the below checks
+				// only apply to exclude paths and include paths that are not glob patterns.
+				if len(normalIncludes) > 0 && !normalpath.IsGlob(normalExclude) {
+					// Each exclude path must be contained in some include path. It is invalid to say include "proto/foo/v1"
+					// and also exclude "proto/foo/v2", because the exclude path is redundant.
+					var foundContainingInclude bool
+					// We iterate through normalIncludes instead of relIncludes so that when we compare an exclude
+					// path to an include path, they are both relative to the workspace root.
+					for _, normalInclude := range normalIncludes {
+						if normalpath.IsGlob(normalInclude) {
+							// The glob pattern may match a directory containing the exclude path.
+							foundContainingInclude = true
+							continue
+						}
+						if normalInclude == normalExclude {
+							return "", fmt.Errorf("%q is both an include path and an exclude path", normalExclude)
 						}
-						if !foundContainingInclude {
-							return "", fmt.Errorf("include paths are specified but %q is not contained within any of them", normalExclude)
+						if normalpath.ContainsPath(normalExclude, normalInclude, normalpath.Relative) {
+							return "", fmt.Errorf("%q (an include path) is a subdirectory of %q (an exclude path)", normalInclude, normalExclude)
+						}
+						if normalpath.ContainsPath(normalInclude, normalExclude, normalpath.Relative) {
+							foundContainingInclude = true
+							// Do not exit early here, continue to validate the exclude path against the rest of include paths,
+							// to make sure the exclude path does not equal to or contains any of the include path.
 						}
 					}
-					// The only root for v2 buf.yamls must be ".", so we have to make the excludes relative first.
-					return normalpath.Rel(dirPath, normalExclude)
-				},
-			)
-			if err != nil {
-				return nil, err
-			}
-			rootToExcludes, err := getRootToExcludes([]string{"."}, relExcludes)
-			if err != nil {
-				return nil, err
-			}
-			externalLintConfig := defaultExternalLintConfig
-			lintRequirePathsToBeContainedWithinModuleDirPath := false
-			if !externalModule.Lint.isEmpty() {
-				externalLintConfig = externalModule.Lint
-				// We have a module-specific configuration, all paths must be within the module.
-				lintRequirePathsToBeContainedWithinModuleDirPath = true
-			}
-			lintConfig, err := getLintConfigForExternalLintV2(
-				fileVersion,
-				externalLintConfig,
-				dirPath,
-				lintRequirePathsToBeContainedWithinModuleDirPath,
-			)
-			if err != nil {
-				return nil, err
-			}
-			externalBreakingConfig := defaultExternalBreakingConfig
-			breakingRequirePathsToBeContainedWithinModuleDirPath := false
-			if !externalModule.Breaking.isEmpty() {
-				externalBreakingConfig = externalModule.Breaking
-				// We have a module-specific configuration, all paths must be within the module.
-				breakingRequirePathsToBeContainedWithinModuleDirPath = true
-			}
-			breakingConfig, err := getBreakingConfigForExternalBreaking(
-				fileVersion,
-				externalBreakingConfig,
-				dirPath,
-				breakingRequirePathsToBeContainedWithinModuleDirPath,
-			)
-			if err != nil {
-				return nil, err
-			}
-			moduleConfig, err := newModuleConfig(
-				dirPath,
-				moduleFullName,
-				rootToIncludes,
-				rootToExcludes,
-				lintConfig,
-				breakingConfig,
-			)
-			if err != nil {
-				return nil, err
-			}
-			moduleConfigs = append(moduleConfigs, moduleConfig)
-		}
-		var topLevelLintConfig LintConfig
-		if !defaultExternalLintConfig.isEmpty() {
-			topLevelLintConfig, err = getLintConfigForExternalLintV2(
-				fileVersion,
-				defaultExternalLintConfig,
-				".",   // The top-level module config always has the root "."
-				false, // Not module-specific configuration
-			)
-			if err != nil {
-				return nil, err
-			}
+					if !foundContainingInclude {
+						return "", fmt.Errorf("include paths are specified but %q is not contained within any of them", normalExclude)
+					}
+				}
+				// The only root for v2 buf.yamls must be ".", so we have to make the excludes relative first.
+				return normalpath.Rel(dirPath, normalExclude)
+			},
+		)
+		if err != nil {
+			return nil, err
 		}
-		var topLevelBreakingConfig BreakingConfig
-		if !defaultExternalBreakingConfig.isEmpty() {
-			topLevelBreakingConfig, err = getBreakingConfigForExternalBreaking(
-				fileVersion,
-				defaultExternalBreakingConfig,
-				".",   // The top-level module config always has the root "."
-				false, // Not module-specific configuration
-			)
-			if err != nil {
-				return nil, err
-			}
+		rootToExcludes, err := getRootToExcludes(
+			[]string{"."},
+			slicesext.Filter(relExcludes, func(relExclude string) bool { return !normalpath.IsGlob(relExclude) }),
+		)
+		if err != nil {
+			return nil, err
 		}
-		var pluginConfigs []PluginConfig
-		for _, externalPluginConfig := range externalBufYAMLFile.Plugins {
-			pluginConfig, err := newPluginConfigForExternalV2(externalPluginConfig)
-			if err != nil {
-				return nil, err
-			}
-			pluginConfigs = append(pluginConfigs, pluginConfig)
+		relGlobExcludes, err := normalizeAndCheckPathsOrGlobs(slicesext.Filter(relExcludes, normalpath.IsGlob), "exclude")
+		if err != nil {
+			return nil, err
 		}
-		configuredDepModuleRefs, err := getConfiguredDepModuleRefsForExternalDeps(externalBufYAMLFile.Deps)
+		if len(relGlobExcludes) > 0 {
+			excludes := append(rootToExcludes["."], relGlobExcludes...)
+			sort.Strings(excludes)
+			rootToExcludes["."] = excludes
+		}
+		externalLintConfig := defaultExternalLintConfig
+		lintRequirePathsToBeContainedWithinModuleDirPath := false
+		if !externalModule.Lint.isEmpty() {
+			externalLintConfig = externalModule.Lint
+			// We have a module-specific configuration, all paths must be within the module.
+			lintRequirePathsToBeContainedWithinModuleDirPath = true
+		}
+		lintConfig, err := getLintConfigForExternalLintV2(
+			fileVersion,
+			externalLintConfig,
+			dirPath,
+			lintRequirePathsToBeContainedWithinModuleDirPath,
+		)
 		if err != nil {
 			return nil, err
 		}
-		return newBufYAMLFile(
+		externalBreakingConfig := defaultExternalBreakingConfig
+		breakingRequirePathsToBeContainedWithinModuleDirPath := false
+		if !externalModule.Breaking.isEmpty() {
+			externalBreakingConfig = externalModule.Breaking
+			// We have a module-specific configuration, all paths must be within the module.
+			breakingRequirePathsToBeContainedWithinModuleDirPath = true
+		}
+		breakingConfig, err := getBreakingConfigForExternalBreaking(
 			fileVersion,
-			objectData,
-			moduleConfigs,
-			topLevelLintConfig,
-			topLevelBreakingConfig,
-			pluginConfigs,
-			configuredDepModuleRefs,
-			includeDocsLink,
+			externalBreakingConfig,
+			dirPath,
+			breakingRequirePathsToBeContainedWithinModuleDirPath,
 		)
-	default:
-		// This is a system error since we've already parsed.
-		return nil, syserror.Newf("unknown FileVersion: %v", fileVersion)
+		if err != nil {
+			return nil, err
+		}
+		moduleConfig, err := newModuleConfig(
+			dirPath,
+			moduleFullName,
+			rootToIncludes,
+			rootToExcludes,
+			lintConfig,
+			breakingConfig,
+		)
+		if err != nil {
+			return nil, err
+		}
+		moduleConfigs = append(moduleConfigs, moduleConfig)
+	}
+	var topLevelLintConfig LintConfig
+	if !defaultExternalLintConfig.isEmpty() {
+		var err error
+		topLevelLintConfig, err = getLintConfigForExternalLintV2(
+			fileVersion,
+			defaultExternalLintConfig,
+			".",   // The top-level module config always has the root "."
+			false, // Not module-specific configuration
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var topLevelBreakingConfig BreakingConfig
+	if !defaultExternalBreakingConfig.isEmpty() {
+		var err error
+		topLevelBreakingConfig, err = getBreakingConfigForExternalBreaking(
+			fileVersion,
+			defaultExternalBreakingConfig,
+			".",   // The top-level module config always has the root "."
+			false, // Not module-specific configuration
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var pluginConfigs []PluginConfig
+	for _, externalPluginConfig := range externalBufYAMLFile.Plugins {
+		pluginConfig, err := newPluginConfigForExternalV2(externalPluginConfig)
+		if err != nil {
+			return nil, err
+		}
+		pluginConfigs = append(pluginConfigs, pluginConfig)
+	}
+	configuredDepModuleRefs, err := getConfiguredDepModuleRefsForExternalDeps(externalBufYAMLFile.Deps)
+	if err != nil {
+		return nil, err
 	}
+	var replaceConfigs []ReplaceConfig
+	for _, externalReplaceConfig := range externalBufYAMLFile.Replace {
+		replaceConfig, err := newReplaceConfigForExternalV2(externalReplaceConfig)
+		if err != nil {
+			return nil, err
+		}
+		replaceConfigs = append(replaceConfigs, replaceConfig)
+	}
+	var verifyConfigs []VerifyConfig
+	for _, externalVerifyConfig := range externalBufYAMLFile.Verify {
+		verifyConfig, err := newVerifyConfigForExternalV2(externalVerifyConfig)
+		if err != nil {
+			return nil, err
+		}
+		verifyConfigs = append(verifyConfigs, verifyConfig)
+	}
+	return newBufYAMLFile(
+		fileVersion,
+		objectData,
+		moduleConfigs,
+		topLevelLintConfig,
+		topLevelBreakingConfig,
+		pluginConfigs,
+		configuredDepModuleRefs,
+		externalBufYAMLFile.Extends,
+		replaceConfigs,
+		verifyConfigs,
+		includeDocsLink,
+	)
 }
 
 func writeBufYAMLFile(writer io.Writer, bufYAMLFile BufYAMLFile) error {
@@ -765,6 +1021,34 @@ func writeBufYAMLFile(writer io.Writer, bufYAMLFile BufYAMLFile) error {
 				return moduleRef.String()
 			},
 		)
+		externalBufYAMLFile.Extends = bufYAMLFile.Extends()
+		// Already sorted.
+		externalBufYAMLFile.Replace = slicesext.Map(
+			bufYAMLFile.ReplaceConfigs(),
+			func(replaceConfig ReplaceConfig) externalBufYAMLFileReplaceV2 {
+				externalReplaceConfig := externalBufYAMLFileReplaceV2{
+					Dep:  replaceConfig.FullName().String(),
+					Path: replaceConfig.DirPath(),
+				}
+				if moduleRef := replaceConfig.ModuleRef(); moduleRef != nil {
+					externalReplaceConfig.Module = moduleRef.String()
+				}
+				return externalReplaceConfig
+			},
+		)
+		// Already sorted.
+		externalBufYAMLFile.Verify = slicesext.Map(
+			bufYAMLFile.VerifyConfigs(),
+			func(verifyConfig VerifyConfig) externalBufYAMLFileVerifyV2 {
+				return externalBufYAMLFileVerifyV2{
+					Owner: getVerifyConfigOwnerString(verifyConfig),
+					Keys: slicesext.Map(
+						verifyConfig.PublicKeys(),
+						bufmodulesign.PublicKey.String,
+					),
+				}
+			},
+		)
 		// Keep maps of the JSON-marshaled data to the external lint and breaking configs.
 		//
 		// If both of these maps are of length 0 or 1, we say that the user really just has a
@@ -975,6 +1259,225 @@ func getConfiguredDepModuleRefsForExternalDeps(
 	return configuredDepModuleRefs, nil
 }
 
+func getConfiguredExtendsModuleRefsForExtends(
+	extends []string,
+) ([]bufparse.Ref, error) {
+	var configuredExtendsModuleRefs []bufparse.Ref
+	for _, extend := range extends {
+		if isLocalExtends(extend) {
+			continue
+		}
+		moduleRef, err := bufparse.ParseRef(extend)
+		if err != nil {
+			return nil, fmt.Errorf("invalid extends, must be a path to a .yaml or .yml file or a module reference: %w", err)
+		}
+		configuredExtendsModuleRefs = append(configuredExtendsModuleRefs, moduleRef)
+	}
+	if _, err := bufparse.FullNameStringToUniqueValue(configuredExtendsModuleRefs); err != nil {
+		return nil, err
+	}
+	sort.Slice(
+		configuredExtendsModuleRefs,
+		func(i int, j int) bool {
+			return configuredExtendsModuleRefs[i].FullName().String() <
+				configuredExtendsModuleRefs[j].FullName().String()
+		},
+	)
+	return configuredExtendsModuleRefs, nil
+}
+
+// isLocalExtends returns true if the extends entry is a path to a local file.
+func isLocalExtends(extend string) bool {
+	switch normalpath.Ext(extend) {
+	case ".yaml", ".yml":
+		return true
+	default:
+		return false
+	}
+}
+
+// getExternalBufYAMLFileV2ForExtendedData gets the external v2 file for the data of an
+// extended buf.yaml file.
+//
+// Modules on the BSR only have the buf.yaml files of v1beta1 and v1 modules, so these are
+// converted to v2.
+func getExternalBufYAMLFileV2ForExtendedData(data []byte) (externalBufYAMLFileV2, error) {
+	fileVersion, err := getFileVersionForData(data, false, true, bufYAMLFileNameToSupportedFileVersions, FileVersionV2, defaultBufYAMLFileVersion)
+	if err != nil {
+		return externalBufYAMLFileV2{}, err
+	}
+	switch fileVersion {
+	case FileVersionV1Beta1, FileVersionV1:
+		var externalBufYAMLFile externalBufYAMLFileV1Beta1V1
+		if err := getUnmarshalStrict(false)(data, &externalBufYAMLFile); err != nil {
+			return externalBufYAMLFileV2{}, fmt.Errorf("invalid as version %v: %w", fileVersion, err)
+		}
+		externalLint := externalBufYAMLFile.Lint
+		return externalBufYAMLFileV2{
+			Lint: externalBufYAMLFileLintV2{
+				Use:                                  externalLint.Use,
+				Except:                               externalLint.Except,
+				Warn:                                 externalLint.Warn,
+				Info:                                 externalLint.Info,
+				Ignore:                               externalLint.Ignore,
+				IgnoreOnly:                           externalLint.IgnoreOnly,
+				EnumZeroValueSuffix:                  externalLint.EnumZeroValueSuffix,
+				RPCAllowSameRequestResponse:          externalLint.RPCAllowSameRequestResponse,
+				RPCAllowGoogleProtobufEmptyRequests:  externalLint.RPCAllowGoogleProtobufEmptyRequests,
+				RPCAllowGoogleProtobufEmptyResponses: externalLint.RPCAllowGoogleProtobufEmptyResponses,
+				ServiceSuffix:                        externalLint.ServiceSuffix,
+				// allow_comment_ignores is not carried over, as comment ignores are allowed by default in v2.
+				DisableBuiltin: externalLint.DisableBuiltin,
+			},
+			Breaking: externalBufYAMLFile.Breaking,
+		}, nil
+	case FileVersionV2:
+		var externalBufYAMLFile externalBufYAMLFileV2
+		if err := getUnmarshalStrict(false)(data, &externalBufYAMLFile); err != nil {
+			return externalBufYAMLFileV2{}, fmt.Errorf("invalid as version %v: %w", fileVersion, err)
+		}
+		if len(externalBufYAMLFile.Extends) > 0 {
+			return externalBufYAMLFileV2{}, errors.New("extended files cannot have extends")
+		}
+		// Only the lint, breaking, and plugin settings are inherited.
+		return externalBufYAMLFileV2{
+			Lint:     externalBufYAMLFile.Lint,
+			Breaking: externalBufYAMLFile.Breaking,
+			Plugins:  externalBufYAMLFile.Plugins,
+		}, nil
+	default:
+		// This is a system error since we've already parsed.
+		return externalBufYAMLFileV2{}, syserror.Newf("unknown FileVersion: %v", fileVersion)
+	}
+}
+
+// mergeExternalBufYAMLFilesV2 merges the lint, breaking, and plugin settings of the external
+// files into the last external file, in order.
+//
+// See ResolveBufYAMLFile for the merge semantics. The returned file has no extends.
+func mergeExternalBufYAMLFilesV2(externalBufYAMLFiles ...externalBufYAMLFileV2) externalBufYAMLFileV2 {
+	merged := externalBufYAMLFiles[len(externalBufYAMLFiles)-1]
+	merged.Extends = nil
+	var lint externalBufYAMLFileLintV2
+	var breaking externalBufYAMLFileBreakingV1Beta1V1V2
+	var plugins []externalBufYAMLFilePluginV2
+	for _, externalBufYAMLFile := range externalBufYAMLFiles {
+		lint = mergeExternalLintV2(lint, externalBufYAMLFile.Lint)
+		breaking = mergeExternalBreaking(breaking, externalBufYAMLFile.Breaking)
+		plugins = mergeExternalPluginsV2(plugins, externalBufYAMLFile.Plugins)
+	}
+	merged.Lint = lint
+	merged.Breaking = breaking
+	merged.Plugins = plugins
+	return merged
+}
+
+func mergeExternalLintV2(base externalBufYAMLFileLintV2, override externalBufYAMLFileLintV2) externalBufYAMLFileLintV2 {
+	return externalBufYAMLFileLintV2{
+		Use:                                  mergeExternalStrings(base.Use, override.Use),
+		Except:                               overrideExternalStrings(base.Except, override.Except),
+		Warn:                                 mergeExternalStrings(base.Warn, override.Warn),
+		Info:                                 mergeExternalStrings(base.Info, override.Info),
+		Ignore:                               mergeExternalStrings(base.Ignore, override.Ignore),
+		IgnoreOnly:                           mergeExternalIgnoreOnly(base.IgnoreOnly, override.IgnoreOnly),
+		EnumZeroValueSuffix:                  overrideExternalString(base.EnumZeroValueSuffix, override.EnumZeroValueSuffix),
+		RPCAllowSameRequestResponse:          base.RPCAllowSameRequestResponse || override.RPCAllowSameRequestResponse,
+		RPCAllowGoogleProtobufEmptyRequests:  base.RPCAllowGoogleProtobufEmptyRequests || override.RPCAllowGoogleProtobufEmptyRequests,
+		RPCAllowGoogleProtobufEmptyResponses: base.RPCAllowGoogleProtobufEmptyResponses || override.RPCAllowGoogleProtobufEmptyResponses,
+		ServiceSuffix:                        overrideExternalString(base.ServiceSuffix, override.ServiceSuffix),
+		DisallowCommentIgnores:               base.DisallowCommentIgnores || override.DisallowCommentIgnores,
+		DisableBuiltin:                       base.DisableBuiltin || override.DisableBuiltin,
+		LicenseHeader:                        overrideExternalLicenseHeaderV2(base.LicenseHeader, override.LicenseHeader),
+	}
+}
+
+func overrideExternalLicenseHeaderV2(
+	base *externalBufYAMLFileLicenseHeaderV2,
+	override *externalBufYAMLFileLicenseHeaderV2,
+) *externalBufYAMLFileLicenseHeaderV2 {
+	if override != nil {
+		return override
+	}
+	return base
+}
+
+func mergeExternalBreaking(base externalBufYAMLFileBreakingV1Beta1V1V2, override externalBufYAMLFileBreakingV1Beta1V1V2) externalBufYAMLFileBreakingV1Beta1V1V2 {
+	return externalBufYAMLFileBreakingV1Beta1V1V2{
+		Use:                         mergeExternalStrings(base.Use, override.Use),
+		Except:                      overrideExternalStrings(base.Except, override.Except),
+		Warn:                        mergeExternalStrings(base.Warn, override.Warn),
+		Info:                        mergeExternalStrings(base.Info, override.Info),
+		Ignore:                      mergeExternalStrings(base.Ignore, override.Ignore),
+		IgnoreOnly:                  mergeExternalIgnoreOnly(base.IgnoreOnly, override.IgnoreOnly),
+		IgnoreUnstablePackages:      base.IgnoreUnstablePackages || override.IgnoreUnstablePackages,
+		AllowCommentIgnores:         base.AllowCommentIgnores || override.AllowCommentIgnores,
+		RequireCommentIgnoreReasons: base.RequireCommentIgnoreReasons || override.RequireCommentIgnoreReasons,
+		DisableBuiltin:              base.DisableBuiltin || override.DisableBuiltin,
+	}
+}
+
+func mergeExternalPluginsV2(base []externalBufYAMLFilePluginV2, override []externalBufYAMLFilePluginV2) []externalBufYAMLFilePluginV2 {
+	overrideKeys := make(map[string]struct{}, len(override))
+	for _, externalPlugin := range override {
+		overrideKeys[getExternalPluginV2Key(externalPlugin)] = struct{}{}
+	}
+	var merged []externalBufYAMLFilePluginV2
+	for _, externalPlugin := range base {
+		if _, ok := overrideKeys[getExternalPluginV2Key(externalPlugin)]; !ok {
+			merged = append(merged, externalPlugin)
+		}
+	}
+	return append(merged, override...)
+}
+
+// getExternalPluginV2Key returns the key that identifies the plugin of an external plugin config.
+//
+// Remote plugins are identified by their FullName, so that a plugin with a different ref
+// replaces the plugin.
+func getExternalPluginV2Key(externalPlugin externalBufYAMLFilePluginV2) string {
+	path, err := encoding.InterfaceSliceOrStringToStringSlice(externalPlugin.Plugin)
+	if err != nil || len(path) == 0 {
+		// This is validated when the plugin config is read.
+		return fmt.Sprint(externalPlugin.Plugin)
+	}
+	if pluginRef, err := bufparse.ParseRef(path[0]); err == nil {
+		return pluginRef.FullName().String()
+	}
+	return path[0]
+}
+
+func mergeExternalStrings(base []string, override []string) []string {
+	return slicesext.Deduplicate(slicesext.Concat(base, override))
+}
+
+func overrideExternalStrings(base []string, override []string) []string {
+	if len(override) > 0 {
+		return override
+	}
+	return base
+}
+
+func overrideExternalString(base string, override string) string {
+	if override != "" {
+		return override
+	}
+	return base
+}
+
+func mergeExternalIgnoreOnly(base map[string][]string, override map[string][]string) map[string][]string {
+	if len(base) == 0 && len(override) == 0 {
+		return nil
+	}
+	merged := make(map[string][]string, len(base)+len(override))
+	for key, paths := range base {
+		merged[key] = paths
+	}
+	for key, paths := range override {
+		merged[key] = mergeExternalStrings(merged[key], paths)
+	}
+	return merged
+}
+
 func getLintConfigForExternalLintV1Beta1V1(
 	fileVersion FileVersion,
 	externalLint externalBufYAMLFileLintV1Beta1V1,
@@ -1007,6 +1510,8 @@ func getLintConfigForExternalLintV1Beta1V1(
 			fileVersion,
 			externalLint.Use,
 			externalLint.Except,
+			externalLint.Warn,
+			externalLint.Info,
 			ignore,
 			ignoreOnly,
 			externalLint.DisableBuiltin,
@@ -1023,6 +1528,7 @@ func getLintConfigForExternalLintV1Beta1V1(
 		externalLint.RPCAllowGoogleProtobufEmptyResponses,
 		externalLint.ServiceSuffix,
 		externalLint.AllowCommentIgnores,
+		nil,
 	), nil
 }
 
@@ -1058,6 +1564,8 @@ func getLintConfigForExternalLintV2(
 			fileVersion,
 			externalLint.Use,
 			externalLint.Except,
+			externalLint.Warn,
+			externalLint.Info,
 			ignore,
 			ignoreOnly,
 			externalLint.DisableBuiltin,
@@ -1066,6 +1574,10 @@ func getLintConfigForExternalLintV2(
 			return nil, err
 		}
 	}
+	licenseHeaderConfig, err := newLicenseHeaderConfigForExternalV2(externalLint.LicenseHeader)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lint.license_header: %w", err)
+	}
 	return newLintConfig(
 		checkConfig,
 		externalLint.EnumZeroValueSuffix,
@@ -1074,6 +1586,7 @@ func getLintConfigForExternalLintV2(
 		externalLint.RPCAllowGoogleProtobufEmptyResponses,
 		externalLint.ServiceSuffix,
 		!externalLint.DisallowCommentIgnores,
+		licenseHeaderConfig,
 	), nil
 }
 
@@ -1109,6 +1622,8 @@ func getBreakingConfigForExternalBreaking(
 			fileVersion,
 			externalBreaking.Use,
 			externalBreaking.Except,
+			externalBreaking.Warn,
+			externalBreaking.Info,
 			ignore,
 			ignoreOnly,
 			externalBreaking.DisableBuiltin,
@@ -1120,6 +1635,8 @@ func getBreakingConfigForExternalBreaking(
 	return newBreakingConfig(
 		checkConfig,
 		externalBreaking.IgnoreUnstablePackages,
+		externalBreaking.AllowCommentIgnores,
+		externalBreaking.RequireCommentIgnoreReasons,
 	), nil
 }
 
@@ -1198,6 +1715,8 @@ func getExternalLintV1Beta1V1ForLintConfig(lintConfig LintConfig, moduleDirPath
 	// All already sorted.
 	externalLint.Use = lintConfig.UseIDsAndCategories()
 	externalLint.Except = lintConfig.ExceptIDsAndCategories()
+	externalLint.Warn = lintConfig.WarnIDsAndCategories()
+	externalLint.Info = lintConfig.InfoIDsAndCategories()
 	externalLint.Ignore = slicesext.Map(lintConfig.IgnorePaths(), joinDirPath)
 	externalLint.IgnoreOnly = make(map[string][]string, len(lintConfig.IgnoreIDOrCategoryToPaths()))
 	for idOrCategory, importPaths := range lintConfig.IgnoreIDOrCategoryToPaths() {
@@ -1221,6 +1740,8 @@ func getExternalLintV2ForLintConfig(lintConfig LintConfig, moduleDirPath string)
 	// All already sorted.
 	externalLint.Use = lintConfig.UseIDsAndCategories()
 	externalLint.Except = lintConfig.ExceptIDsAndCategories()
+	externalLint.Warn = lintConfig.WarnIDsAndCategories()
+	externalLint.Info = lintConfig.InfoIDsAndCategories()
 	externalLint.Ignore = slicesext.Map(lintConfig.IgnorePaths(), joinDirPath)
 	externalLint.IgnoreOnly = make(map[string][]string, len(lintConfig.IgnoreIDOrCategoryToPaths()))
 	for idOrCategory, importPaths := range lintConfig.IgnoreIDOrCategoryToPaths() {
@@ -1233,6 +1754,7 @@ func getExternalLintV2ForLintConfig(lintConfig LintConfig, moduleDirPath string)
 	externalLint.ServiceSuffix = lintConfig.ServiceSuffix()
 	externalLint.DisallowCommentIgnores = !lintConfig.AllowCommentIgnores()
 	externalLint.DisableBuiltin = lintConfig.DisableBuiltin()
+	externalLint.LicenseHeader = getExternalLicenseHeaderV2ForLicenseHeaderConfig(lintConfig.LicenseHeaderConfig())
 	return externalLint
 }
 
@@ -1244,12 +1766,16 @@ func getExternalBreakingForBreakingConfig(breakingConfig BreakingConfig, moduleD
 	// All already sorted.
 	externalBreaking.Use = breakingConfig.UseIDsAndCategories()
 	externalBreaking.Except = breakingConfig.ExceptIDsAndCategories()
+	externalBreaking.Warn = breakingConfig.WarnIDsAndCategories()
+	externalBreaking.Info = breakingConfig.InfoIDsAndCategories()
 	externalBreaking.Ignore = slicesext.Map(breakingConfig.IgnorePaths(), joinDirPath)
 	externalBreaking.IgnoreOnly = make(map[string][]string, len(breakingConfig.IgnoreIDOrCategoryToPaths()))
 	for idOrCategory, importPaths := range breakingConfig.IgnoreIDOrCategoryToPaths() {
 		externalBreaking.IgnoreOnly[idOrCategory] = slicesext.Map(importPaths, joinDirPath)
 	}
 	externalBreaking.IgnoreUnstablePackages = breakingConfig.IgnoreUnstablePackages()
+	externalBreaking.AllowCommentIgnores = breakingConfig.AllowCommentIgnores()
+	externalBreaking.RequireCommentIgnoreReasons = breakingConfig.RequireCommentIgnoreReasons()
 	externalBreaking.DisableBuiltin = breakingConfig.DisableBuiltin()
 	return externalBreaking
 }
@@ -1277,11 +1803,29 @@ type externalBufYAMLFileV2 struct {
 	Name     string                                 `json:"name,omitempty" yaml:"name,omitempty"`
 	Modules  []externalBufYAMLFileModuleV2          `json:"modules,omitempty" yaml:"modules,omitempty"`
 	Deps     []string                               `json:"deps,omitempty" yaml:"deps,omitempty"`
+	Extends  []string                               `json:"extends,omitempty" yaml:"extends,omitempty"`
+	Replace  []externalBufYAMLFileReplaceV2         `json:"replace,omitempty" yaml:"replace,omitempty"`
+	Verify   []externalBufYAMLFileVerifyV2          `json:"verify,omitempty" yaml:"verify,omitempty"`
 	Lint     externalBufYAMLFileLintV2              `json:"lint,omitempty" yaml:"lint,omitempty"`
 	Breaking externalBufYAMLFileBreakingV1Beta1V1V2 `json:"breaking,omitempty" yaml:"breaking,omitempty"`
 	Plugins  []externalBufYAMLFilePluginV2          `json:"plugins,omitempty" yaml:"plugins,omitempty"`
 }
 
+// externalBufYAMLFileReplaceV2 represents a single dependency replacement within a v2 buf.yaml file.
+//
+// Exactly one of Path and Module is set.
+type externalBufYAMLFileReplaceV2 struct {
+	Dep    string `json:"dep,omitempty" yaml:"dep,omitempty"`
+	Path   string `json:"path,omitempty" yaml:"path,omitempty"`
+	Module string `json:"module,omitempty" yaml:"module,omitempty"`
+}
+
+// externalBufYAMLFileVerifyV2 represents the trusted public keys of an owner within a v2 buf.yaml file.
+type externalBufYAMLFileVerifyV2 struct {
+	Owner string   `json:"owner,omitempty" yaml:"owner,omitempty"`
+	Keys  []string `json:"keys,omitempty" yaml:"keys,omitempty"`
+}
+
 // externalBufYAMLFileModuleV2 represents a single module configuation within a v2 buf.yaml file.
 type externalBufYAMLFileModuleV2 struct {
 	Path     string                                 `json:"path,omitempty" yaml:"path,omitempty"`
@@ -1308,6 +1852,10 @@ type externalBufYAMLFileBuildV1Beta1V1 struct {
 type externalBufYAMLFileLintV1Beta1V1 struct {
 	Use    []string `json:"use,omitempty" yaml:"use,omitempty"`
 	Except []string `json:"except,omitempty" yaml:"except,omitempty"`
+	// Warn are the IDs/categories to report as warnings instead of errors.
+	Warn []string `json:"warn,omitempty" yaml:"warn,omitempty"`
+	// Info are the IDs/categories to report as informational instead of errors.
+	Info []string `json:"info,omitempty" yaml:"info,omitempty"`
 	// Ignore are the paths to ignore.
 	Ignore []string `json:"ignore,omitempty" yaml:"ignore,omitempty"`
 	/// IgnoreOnly are the ID/category to paths to ignore.
@@ -1327,6 +1875,8 @@ var _ = externalBufYAMLFileLintV1Beta1V1.isEmpty
 func (el externalBufYAMLFileLintV1Beta1V1) isEmpty() bool {
 	return len(el.Use) == 0 &&
 		len(el.Except) == 0 &&
+		len(el.Warn) == 0 &&
+		len(el.Info) == 0 &&
 		len(el.Ignore) == 0 &&
 		len(el.IgnoreOnly) == 0 &&
 		el.EnumZeroValueSuffix == "" &&
@@ -1345,6 +1895,10 @@ func (el externalBufYAMLFileLintV1Beta1V1) isEmpty() bool {
 type externalBufYAMLFileLintV2 struct {
 	Use    []string `json:"use,omitempty" yaml:"use,omitempty"`
 	Except []string `json:"except,omitempty" yaml:"except,omitempty"`
+	// Warn are the IDs/categories to report as warnings instead of errors.
+	Warn []string `json:"warn,omitempty" yaml:"warn,omitempty"`
+	// Info are the IDs/categories to report as informational instead of errors.
+	Info []string `json:"info,omitempty" yaml:"info,omitempty"`
 	// Ignore are the paths to ignore.
 	Ignore []string `json:"ignore,omitempty" yaml:"ignore,omitempty"`
 	/// IgnoreOnly are the ID/category to paths to ignore.
@@ -1356,11 +1910,15 @@ type externalBufYAMLFileLintV2 struct {
 	ServiceSuffix                        string              `json:"service_suffix,omitempty" yaml:"service_suffix,omitempty"`
 	DisallowCommentIgnores               bool                `json:"disallow_comment_ignores,omitempty" yaml:"disallow_comment_ignores,omitempty"`
 	DisableBuiltin                       bool                `json:"disable_builtin,omitempty" yaml:"disable_builtin,omitempty"`
+	// LicenseHeader is the license header every file must start with.
+	LicenseHeader *externalBufYAMLFileLicenseHeaderV2 `json:"license_header,omitempty" yaml:"license_header,omitempty"`
 }
 
 func (el externalBufYAMLFileLintV2) isEmpty() bool {
 	return len(el.Use) == 0 &&
 		len(el.Except) == 0 &&
+		len(el.Warn) == 0 &&
+		len(el.Info) == 0 &&
 		len(el.Ignore) == 0 &&
 		len(el.IgnoreOnly) == 0 &&
 		el.EnumZeroValueSuffix == "" &&
@@ -1369,7 +1927,17 @@ func (el externalBufYAMLFileLintV2) isEmpty() bool {
 		!el.RPCAllowGoogleProtobufEmptyResponses &&
 		el.ServiceSuffix == "" &&
 		!el.DisallowCommentIgnores &&
-		!el.DisableBuiltin
+		!el.DisableBuiltin &&
+		el.LicenseHeader == nil
+}
+
+// externalBufYAMLFileLicenseHeaderV2 represents the license header configuration within
+// the lint section of a v2 buf.yaml file.
+type externalBufYAMLFileLicenseHeaderV2 struct {
+	// Template is a text/template that may reference {{.CopyrightHolder}} and {{.YearRange}}.
+	Template        string `json:"template,omitempty" yaml:"template,omitempty"`
+	CopyrightHolder string `json:"copyright_holder,omitempty" yaml:"copyright_holder,omitempty"`
+	YearRange       string `json:"year_range,omitempty" yaml:"year_range,omitempty"`
 }
 
 // externalBufYAMLFileBreakingV1Beta1V1V2 represents breaking configuation within a v1beta1, v1,
@@ -1380,20 +1948,30 @@ func (el externalBufYAMLFileLintV2) isEmpty() bool {
 type externalBufYAMLFileBreakingV1Beta1V1V2 struct {
 	Use    []string `json:"use,omitempty" yaml:"use,omitempty"`
 	Except []string `json:"except,omitempty" yaml:"except,omitempty"`
+	// Warn are the IDs/categories to report as warnings instead of errors.
+	Warn []string `json:"warn,omitempty" yaml:"warn,omitempty"`
+	// Info are the IDs/categories to report as informational instead of errors.
+	Info []string `json:"info,omitempty" yaml:"info,omitempty"`
 	// Ignore are the paths to ignore.
 	Ignore []string `json:"ignore,omitempty" yaml:"ignore,omitempty"`
 	/// IgnoreOnly are the ID/category to paths to ignore.
-	IgnoreOnly             map[string][]string `json:"ignore_only,omitempty" yaml:"ignore_only,omitempty"`
-	IgnoreUnstablePackages bool                `json:"ignore_unstable_packages,omitempty" yaml:"ignore_unstable_packages,omitempty"`
-	DisableBuiltin         bool                `json:"disable_builtin,omitempty" yaml:"disable_builtin,omitempty"`
+	IgnoreOnly                  map[string][]string `json:"ignore_only,omitempty" yaml:"ignore_only,omitempty"`
+	IgnoreUnstablePackages      bool                `json:"ignore_unstable_packages,omitempty" yaml:"ignore_unstable_packages,omitempty"`
+	AllowCommentIgnores         bool                `json:"allow_comment_ignores,omitempty" yaml:"allow_comment_ignores,omitempty"`
+	RequireCommentIgnoreReasons bool                `json:"require_comment_ignore_reasons,omitempty" yaml:"require_comment_ignore_reasons,omitempty"`
+	DisableBuiltin              bool                `json:"disable_builtin,omitempty" yaml:"disable_builtin,omitempty"`
 }
 
 func (eb externalBufYAMLFileBreakingV1Beta1V1V2) isEmpty() bool {
 	return len(eb.Use) == 0 &&
 		len(eb.Except) == 0 &&
+		len(eb.Warn) == 0 &&
+		len(eb.Info) == 0 &&
 		len(eb.Ignore) == 0 &&
 		len(eb.IgnoreOnly) == 0 &&
 		!eb.IgnoreUnstablePackages &&
+		!eb.AllowCommentIgnores &&
+		!eb.RequireCommentIgnoreReasons &&
 		!eb.DisableBuiltin
 }
 
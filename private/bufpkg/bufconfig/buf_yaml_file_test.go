@@ -16,10 +16,13 @@ package bufconfig
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"strings"
 	"testing"
 
+	"github.com/bufbuild/buf/private/bufpkg/bufparse"
+	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -480,6 +483,351 @@ modules:
       - proto/foo
 `,
 	)
+	testReadWriteBufYAMLFileRoundTrip(
+		t,
+		// input
+		`version: v2
+modules:
+  - path: proto
+    includes:
+      - proto/*/v1
+      - proto/acme/**/*_service.proto
+      - proto/common
+    excludes:
+      - proto/**/internal
+      - proto/common/testdata
+`,
+		// expected output
+		`version: v2
+modules:
+  - path: proto
+    includes:
+      - proto/*/v1
+      - proto/acme/**/*_service.proto
+      - proto/common
+    excludes:
+      - proto/**/internal
+      - proto/common/testdata
+`,
+	)
+	testReadWriteBufYAMLFileRoundTrip(
+		t,
+		// input
+		`version: v1
+lint:
+  use:
+    - DEFAULT
+  warn:
+    - COMMENTS
+  info:
+    - PROTOVALIDATE
+breaking:
+  use:
+    - FILE
+  warn:
+    - FIELD_SAME_JSON_NAME
+  info:
+    - FIELD_SAME_CTYPE
+`,
+		// expected output
+		`version: v1
+lint:
+  use:
+    - DEFAULT
+  warn:
+    - COMMENTS
+  info:
+    - PROTOVALIDATE
+breaking:
+  use:
+    - FILE
+  warn:
+    - FIELD_SAME_JSON_NAME
+  info:
+    - FIELD_SAME_CTYPE
+`,
+	)
+	testReadWriteBufYAMLFileRoundTrip(
+		t,
+		// input
+		`version: v2
+lint:
+  use:
+    - STANDARD
+    - COMMENTS
+  warn:
+    - COMMENTS
+    - PROTOVALIDATE
+breaking:
+  warn:
+    - FIELD_SAME_JSON_NAME
+`,
+		// expected output
+		`version: v2
+lint:
+  use:
+    - COMMENTS
+    - STANDARD
+  warn:
+    - COMMENTS
+    - PROTOVALIDATE
+breaking:
+  warn:
+    - FIELD_SAME_JSON_NAME
+`,
+	)
+	testReadWriteBufYAMLFileRoundTrip(
+		t,
+		// input
+		`version: v2
+breaking:
+  use:
+    - FILE
+  allow_comment_ignores: true
+  require_comment_ignore_reasons: true
+`,
+		// expected output
+		`version: v2
+breaking:
+  use:
+    - FILE
+  allow_comment_ignores: true
+  require_comment_ignore_reasons: true
+`,
+	)
+	testReadWriteBufYAMLFileRoundTrip(
+		t,
+		// input
+		`version: v2
+extends:
+  - config/buf.shared.yaml
+  - buf.build/acme/config
+lint:
+  use:
+    - COMMENTS
+`,
+		// expected output
+		`version: v2
+extends:
+  - config/buf.shared.yaml
+  - buf.build/acme/config
+lint:
+  use:
+    - COMMENTS
+`,
+	)
+	testReadWriteBufYAMLFileRoundTrip(
+		t,
+		// input
+		`version: v2
+deps:
+  - buf.build/acme/shared
+  - buf.build/acme/payments
+replace:
+  - dep: buf.build/acme/shared
+    path: ../shared/./
+  - dep: buf.build/acme/payments
+    module: buf.build/acme/payments:feature
+`,
+		// expected output
+		`version: v2
+deps:
+  - buf.build/acme/payments
+  - buf.build/acme/shared
+replace:
+  - dep: buf.build/acme/payments
+    module: buf.build/acme/payments:feature
+  - dep: buf.build/acme/shared
+    path: ../shared
+`,
+	)
+	testReadWriteBufYAMLFileRoundTrip(
+		t,
+		// input
+		`version: v2
+deps:
+  - buf.build/acme/shared
+verify:
+  - owner: buf.build/other
+    keys:
+      - ed25519:AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE=
+  - owner: buf.build/acme
+    keys:
+      - ed25519:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=
+      - ed25519:AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE=
+`,
+		// expected output
+		`version: v2
+deps:
+  - buf.build/acme/shared
+verify:
+  - owner: buf.build/acme
+    keys:
+      - ed25519:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=
+      - ed25519:AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE=
+  - owner: buf.build/other
+    keys:
+      - ed25519:AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE=
+`,
+	)
+	testReadWriteBufYAMLFileRoundTrip(
+		t,
+		// input
+		`version: v2
+lint:
+  use:
+    - STANDARD
+    - LICENSE_HEADER_DEFINED
+  license_header:
+    template: |
+      SPDX-License-Identifier: Apache-2.0
+
+      Copyright {{.YearRange}} {{.CopyrightHolder}}
+    copyright_holder: Acme, Inc.
+    year_range: 2020-2024
+`,
+		// expected output
+		`version: v2
+lint:
+  use:
+    - LICENSE_HEADER_DEFINED
+    - STANDARD
+  license_header:
+    template: |
+      SPDX-License-Identifier: Apache-2.0
+
+      Copyright {{.YearRange}} {{.CopyrightHolder}}
+    copyright_holder: Acme, Inc.
+    year_range: 2020-2024
+`,
+	)
+}
+
+func TestResolveBufYAMLFile(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	bucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			"config/buf.shared.yaml": []byte(`version: v2
+lint:
+  use:
+    - STANDARD
+  except:
+    - PACKAGE_VERSION_SUFFIX
+  ignore_only:
+    ENUM_ZERO_VALUE_SUFFIX:
+      - proto/a.proto
+breaking:
+  use:
+    - FILE
+  ignore_unstable_packages: true
+plugins:
+  - plugin: buf-plugin-foo
+    options:
+      foo: shared
+  - plugin: buf-plugin-bar
+`),
+		},
+	)
+	require.NoError(t, err)
+	getModuleBufYAMLData := func(_ context.Context, moduleRef bufparse.Ref) ([]byte, error) {
+		require.Equal(t, "buf.build/acme/config", moduleRef.FullName().String())
+		return []byte(`version: v1
+lint:
+  use:
+    - DEFAULT
+  enum_zero_value_suffix: _NONE
+  allow_comment_ignores: true
+`), nil
+	}
+	bufYAMLFile := testReadBufYAMLFile(
+		t,
+		`version: v2
+modules:
+  - path: proto
+extends:
+  - config/buf.shared.yaml
+  - buf.build/acme/config
+lint:
+  use:
+    - COMMENTS
+  except:
+    - ENUM_VALUE_PREFIX
+  ignore_only:
+    ENUM_ZERO_VALUE_SUFFIX:
+      - proto/b.proto
+plugins:
+  - plugin: buf-plugin-foo
+    options:
+      foo: local
+`,
+	)
+	require.Equal(t, []string{"config/buf.shared.yaml", "buf.build/acme/config"}, bufYAMLFile.Extends())
+	require.Len(t, bufYAMLFile.ConfiguredExtendsModuleRefs(), 1)
+	require.Equal(t, "buf.build/acme/config", bufYAMLFile.ConfiguredExtendsModuleRefs()[0].FullName().String())
+	resolvedBufYAMLFile, err := ResolveBufYAMLFile(ctx, bufYAMLFile, bucket, getModuleBufYAMLData)
+	require.NoError(t, err)
+	require.Empty(t, resolvedBufYAMLFile.Extends())
+	buffer := bytes.NewBuffer(nil)
+	require.NoError(t, WriteBufYAMLFile(buffer, resolvedBufYAMLFile))
+	assert.Equal(
+		t,
+		testCleanYAMLData(`version: v2
+modules:
+  - path: proto
+lint:
+  use:
+    - COMMENTS
+    - DEFAULT
+    - STANDARD
+  except:
+    - ENUM_VALUE_PREFIX
+  ignore_only:
+    ENUM_ZERO_VALUE_SUFFIX:
+      - proto/a.proto
+      - proto/b.proto
+  enum_zero_value_suffix: _NONE
+breaking:
+  use:
+    - FILE
+  ignore_unstable_packages: true
+plugins:
+  - plugin: buf-plugin-bar
+  - plugin: buf-plugin-foo
+    options:
+      foo: local
+`),
+		testCleanYAMLData(buffer.String()),
+	)
+	// Extended modules are skipped without a getter.
+	resolvedBufYAMLFile, err = ResolveBufYAMLFile(ctx, bufYAMLFile, bucket, nil)
+	require.NoError(t, err)
+	require.NotContains(t, resolvedBufYAMLFile.TopLevelLintConfig().UseIDsAndCategories(), "DEFAULT")
+
+	// Extended files cannot have extends themselves.
+	bucket, err = storagemem.NewReadBucket(
+		map[string][]byte{
+			"buf.shared.yaml": []byte(`version: v2
+extends:
+  - buf.other.yaml
+`),
+		},
+	)
+	require.NoError(t, err)
+	_, err = ResolveBufYAMLFile(
+		ctx,
+		testReadBufYAMLFile(
+			t,
+			`version: v2
+extends:
+  - buf.shared.yaml
+`,
+		),
+		bucket,
+		nil,
+	)
+	require.ErrorContains(t, err, "extended files cannot have extends")
 }
 
 func TestBufYAMLFileLintDisabled(t *testing.T) {
@@ -686,6 +1034,235 @@ modules:
 `,
 		`"proto/foo/bar" (an include path) is a subdirectory of "proto/foo" (an exclude path)`,
 	)
+	testReadBufYAMLFileFail(
+		t,
+		`version: v2
+modules:
+  - path: proto
+    includes:
+      - other/*/v1
+`,
+		`"other/*/v1" does not reside within module directory`,
+	)
+	testReadBufYAMLFileFail(
+		t,
+		`version: v2
+modules:
+  - path: proto
+    includes:
+      - proto/foo**/v1
+`,
+		`"**" must be an entire path component`,
+	)
+	testReadBufYAMLFileFail(
+		t,
+		`version: v2
+modules:
+  - path: proto
+    includes:
+      - proto/{foo,bar/v1
+`,
+		`unmatched '{'`,
+	)
+	testReadBufYAMLFileFail(
+		t,
+		`version: v2
+modules:
+  - path: proto
+    excludes:
+      - proto/*/internal
+      - proto/*/internal
+`,
+		`duplicate exclude "*/internal"`,
+	)
+}
+
+func TestExplainModuleConfigPath(t *testing.T) {
+	t.Parallel()
+	bufYAMLFile := testReadBufYAMLFile(
+		t,
+		`version: v2
+modules:
+  - path: proto
+    includes:
+      - proto/*/v1
+      - proto/common
+    excludes:
+      - proto/**/internal
+  - path: vendor
+`,
+	)
+	moduleConfigs := bufYAMLFile.ModuleConfigs()
+	require.Len(t, moduleConfigs, 2)
+	protoModuleConfig := moduleConfigs[0]
+	vendorModuleConfig := moduleConfigs[1]
+	testExplainModuleConfigPath(t, protoModuleConfig, "proto/foo/v1/foo.proto", true, "foo/v1/foo.proto", `included by include "proto/*/v1"`)
+	testExplainModuleConfigPath(t, protoModuleConfig, "proto/common/common.proto", true, "common/common.proto", `included by include "proto/common"`)
+	testExplainModuleConfigPath(t, protoModuleConfig, "proto/foo/v1/internal/foo.proto", false, "", `excluded by exclude "proto/**/internal"`)
+	testExplainModuleConfigPath(t, protoModuleConfig, "proto/foo/v2/foo.proto", false, "", `not matched by any include: "proto/*/v1", "proto/common"`)
+	testExplainModuleConfigPath(t, protoModuleConfig, "proto/foo/v1/foo.txt", false, "", `only .proto files are part of modules`)
+	testExplainModuleConfigPath(t, protoModuleConfig, "vendor/foo.proto", false, "", `not within the module directory "proto"`)
+	testExplainModuleConfigPath(t, vendorModuleConfig, "vendor/foo.proto", true, "foo.proto", `within the module directory "vendor", which has no includes`)
+
+	bufYAMLFile = testReadBufYAMLFile(
+		t,
+		`version: v1beta1
+build:
+  roots:
+    - proto
+    - vendor
+  excludes:
+    - proto/internal
+`,
+	)
+	moduleConfigs = bufYAMLFile.ModuleConfigs()
+	require.Len(t, moduleConfigs, 1)
+	moduleConfig := moduleConfigs[0]
+	testExplainModuleConfigPath(t, moduleConfig, "proto/foo/v1/foo.proto", true, "foo/v1/foo.proto", `within root "proto"`)
+	testExplainModuleConfigPath(t, moduleConfig, "proto/internal/foo.proto", false, "", `excluded by exclude "proto/internal"`)
+	testExplainModuleConfigPath(t, moduleConfig, "other/foo.proto", false, "", `not within any root: "proto", "vendor"`)
+}
+
+func TestBufYAMLInvalidReplace(t *testing.T) {
+	t.Parallel()
+	testReadBufYAMLFileFail(
+		t,
+		`version: v2
+replace:
+  - dep: buf.build/acme/shared
+`,
+		`replace for buf.build/acme/shared must specify either a path or a module`,
+	)
+	testReadBufYAMLFileFail(
+		t,
+		`version: v2
+replace:
+  - dep: buf.build/acme/shared
+    path: ../shared
+    module: buf.build/acme/shared:main
+`,
+		`replace for buf.build/acme/shared cannot specify both a path and a module`,
+	)
+	testReadBufYAMLFileFail(
+		t,
+		`version: v2
+replace:
+  - dep: buf.build/acme/shared
+    path: ../shared
+  - dep: buf.build/acme/shared
+    path: ../other
+`,
+		`buf.build/acme/shared`,
+	)
+	testReadBufYAMLFileFail(
+		t,
+		`version: v2
+modules:
+  - path: proto
+    name: buf.build/acme/shared
+replace:
+  - dep: buf.build/acme/shared
+    path: ../shared
+`,
+		`cannot replace buf.build/acme/shared as it is a module in the workspace`,
+	)
+}
+
+func TestBufYAMLInvalidLicenseHeader(t *testing.T) {
+	t.Parallel()
+	testReadBufYAMLFileFail(
+		t,
+		`version: v2
+lint:
+  license_header:
+    copyright_holder: Acme, Inc.
+`,
+		`invalid lint.license_header: license header template references {{.YearRange}} but no year range was given`,
+	)
+	testReadBufYAMLFileFail(
+		t,
+		`version: v2
+lint:
+  license_header:
+    copyright_holder: Acme, Inc.
+    year_range: "24"
+`,
+		`invalid license header year range "24"`,
+	)
+	testReadBufYAMLFileFail(
+		t,
+		`version: v2
+lint:
+  license_header:
+    template: "Copyright {{.Owner}}"
+`,
+		`invalid license header template`,
+	)
+	testReadBufYAMLFileFail(
+		t,
+		`version: v1
+lint:
+  license_header:
+    copyright_holder: Acme, Inc.
+    year_range: "2024"
+`,
+		`license_header`,
+	)
+}
+
+func TestBufYAMLInvalidVerify(t *testing.T) {
+	t.Parallel()
+	testReadBufYAMLFileFail(
+		t,
+		`version: v2
+verify:
+  - owner: acme
+    keys:
+      - ed25519:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=
+`,
+		`invalid verify owner "acme": must be in the form registry/owner`,
+	)
+	testReadBufYAMLFileFail(
+		t,
+		`version: v2
+verify:
+  - owner: buf.build/acme
+`,
+		`verify for buf.build/acme must specify at least one key`,
+	)
+	testReadBufYAMLFileFail(
+		t,
+		`version: v2
+verify:
+  - owner: buf.build/acme
+    keys:
+      - rsa:AAAA
+`,
+		`invalid verify key for buf.build/acme`,
+	)
+	testReadBufYAMLFileFail(
+		t,
+		`version: v2
+verify:
+  - owner: buf.build/acme
+    keys:
+      - ed25519:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=
+  - owner: buf.build/acme
+    keys:
+      - ed25519:AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE=
+`,
+		`verify owners must be unique`,
+	)
+	testReadBufYAMLFileFail(
+		t,
+		`version: v1
+verify:
+  - owner: buf.build/acme
+    keys:
+      - ed25519:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=
+`,
+		`verify`,
+	)
 }
 
 func testReadWriteBufYAMLFileRoundTrip(
@@ -725,6 +1302,21 @@ func testReadBufYAMLFileFail(
 	require.ErrorContains(t, err, errorContains)
 }
 
+func testExplainModuleConfigPath(
+	t *testing.T,
+	moduleConfig ModuleConfig,
+	path string,
+	expectedClaimed bool,
+	expectedModuleFilePath string,
+	expectedReason string,
+) {
+	explanation, err := ExplainModuleConfigPath(moduleConfig, path)
+	require.NoError(t, err)
+	assert.Equal(t, expectedClaimed, explanation.Claimed(), path)
+	assert.Equal(t, expectedModuleFilePath, explanation.ModuleFilePath(), path)
+	assert.Equal(t, expectedReason, explanation.Reason(), path)
+}
+
 func testCleanYAMLData(data string) string {
 	// Just to deal with editor nonsense when writing tests.
 	return strings.TrimSpace(strings.ReplaceAll(data, "\t", "  "))
@@ -25,6 +25,8 @@ var (
 		nil,
 		nil,
 		nil,
+		nil,
+		nil,
 		false,
 	)
 	defaultCheckConfigV2 = newEnabledCheckConfigNoValidate(
@@ -33,6 +35,8 @@ var (
 		nil,
 		nil,
 		nil,
+		nil,
+		nil,
 		false,
 	)
 )
@@ -86,6 +90,20 @@ type CheckConfig interface {
 	UseIDsAndCategories() []string
 	// Sorted
 	ExceptIDsAndCategories() []string
+	// WarnIDsAndCategories are the IDs and categories of the rules that produce
+	// warnings instead of errors.
+	//
+	// Rules that are not also in the used rules have no effect.
+	// Sorted.
+	WarnIDsAndCategories() []string
+	// InfoIDsAndCategories are the IDs and categories of the rules that produce
+	// informational FileAnnotations instead of errors. These never fail a check
+	// unless --fail-on=info is set.
+	//
+	// Rules that are not also in the used rules have no effect. Rules that are
+	// also in WarnIDsAndCategories produce warnings.
+	// Sorted.
+	InfoIDsAndCategories() []string
 	// Paths are specific to the Module. Users cannot ignore paths outside of their modules for check
 	// configs, which includes any imports from outside of the module.
 	// Paths are relative to roots.
@@ -110,6 +128,8 @@ func NewEnabledCheckConfig(
 	fileVersion FileVersion,
 	use []string,
 	except []string,
+	warn []string,
+	info []string,
 	ignore []string,
 	ignoreOnly map[string][]string,
 	disableBuiltin bool,
@@ -118,6 +138,8 @@ func NewEnabledCheckConfig(
 		fileVersion,
 		use,
 		except,
+		warn,
+		info,
 		ignore,
 		ignoreOnly,
 		disableBuiltin,
@@ -136,6 +158,8 @@ func NewEnabledCheckConfigForUseIDsAndCategories(
 		nil,
 		nil,
 		nil,
+		nil,
+		nil,
 		disableBuiltin,
 	)
 }
@@ -147,6 +171,8 @@ type checkConfig struct {
 	disabled       bool
 	use            []string
 	except         []string
+	warn           []string
+	info           []string
 	ignore         []string
 	ignoreOnly     map[string][]string
 	disableBuiltin bool
@@ -156,12 +182,16 @@ func newEnabledCheckConfig(
 	fileVersion FileVersion,
 	use []string,
 	except []string,
+	warn []string,
+	info []string,
 	ignore []string,
 	ignoreOnly map[string][]string,
 	disableBuiltin bool,
 ) (*checkConfig, error) {
 	use = slicesext.ToUniqueSorted(use)
 	except = slicesext.ToUniqueSorted(except)
+	warn = slicesext.ToUniqueSorted(warn)
+	info = slicesext.ToUniqueSorted(info)
 	ignore = slicesext.ToUniqueSorted(ignore)
 	ignore, err := normalizeAndCheckPaths(ignore, "ignore")
 	if err != nil {
@@ -178,13 +208,15 @@ func newEnabledCheckConfig(
 	}
 	ignoreOnly = newIgnoreOnly
 
-	return newEnabledCheckConfigNoValidate(fileVersion, use, except, ignore, ignoreOnly, disableBuiltin), nil
+	return newEnabledCheckConfigNoValidate(fileVersion, use, except, warn, info, ignore, ignoreOnly, disableBuiltin), nil
 }
 
 func newEnabledCheckConfigNoValidate(
 	fileVersion FileVersion,
 	use []string,
 	except []string,
+	warn []string,
+	info []string,
 	ignore []string,
 	ignoreOnly map[string][]string,
 	disableBuiltin bool,
@@ -194,6 +226,8 @@ func newEnabledCheckConfigNoValidate(
 		disabled:       false,
 		use:            use,
 		except:         except,
+		warn:           warn,
+		info:           info,
 		ignore:         ignore,
 		ignoreOnly:     ignoreOnly,
 		disableBuiltin: disableBuiltin,
@@ -223,6 +257,14 @@ func (c *checkConfig) ExceptIDsAndCategories() []string {
 	return slicesext.Copy(c.except)
 }
 
+func (c *checkConfig) WarnIDsAndCategories() []string {
+	return slicesext.Copy(c.warn)
+}
+
+func (c *checkConfig) InfoIDsAndCategories() []string {
+	return slicesext.Copy(c.info)
+}
+
 func (c *checkConfig) IgnorePaths() []string {
 	return slicesext.Copy(c.ignore)
 }
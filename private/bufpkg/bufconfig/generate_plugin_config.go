@@ -20,6 +20,7 @@ import (
 	"math"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufremoteplugin/bufremotepluginref"
 	"github.com/bufbuild/buf/private/pkg/encoding"
@@ -112,6 +113,21 @@ type GeneratePluginConfig interface {
 	//
 	// This is not empty only when the plugin is remote.
 	Revision() int
+	// Timeout returns the maximum duration of a single invocation of the plugin.
+	//
+	// This is zero if there is no timeout, and is only set when the plugin is local.
+	Timeout() time.Duration
+	// MaxOutputBytes returns the maximum number of bytes a single invocation of the
+	// plugin may write to stdout or stderr.
+	//
+	// This is zero if there is no limit, and is only set when the plugin is local.
+	MaxOutputBytes() int64
+	// Env returns the names of the environment variables that are passed to the plugin.
+	//
+	// If this is nil, the plugin is passed the full environment. If this is non-nil but
+	// empty, the plugin is passed no environment variables. This is only set when the
+	// plugin is local.
+	Env() []string
 
 	isGeneratePluginConfig()
 }
@@ -163,6 +179,9 @@ func NewLocalGeneratePluginConfig(
 	includeWKT bool,
 	strategy *GenerateStrategy,
 	path []string,
+	timeout time.Duration,
+	maxOutputBytes int64,
+	env []string,
 ) (GeneratePluginConfig, error) {
 	return newLocalGeneratePluginConfig(
 		name,
@@ -172,6 +191,9 @@ func NewLocalGeneratePluginConfig(
 		includeWKT,
 		strategy,
 		path,
+		timeout,
+		maxOutputBytes,
+		env,
 	)
 }
 
@@ -232,6 +254,9 @@ type generatePluginConfig struct {
 	protocPath               []string
 	remoteHost               string
 	revision                 int
+	timeout                  time.Duration
+	maxOutputBytes           int64
+	env                      []string
 }
 
 func newGeneratePluginConfigFromExternalV1Beta1(
@@ -260,6 +285,9 @@ func newGeneratePluginConfigFromExternalV1Beta1(
 			false,
 			strategy,
 			[]string{externalConfig.Path},
+			0,
+			0,
+			nil,
 		)
 	}
 	return newLocalOrProtocBuiltinGeneratePluginConfig(
@@ -341,6 +369,9 @@ func newGeneratePluginConfigFromExternalV1(
 			false,
 			strategy,
 			path,
+			0,
+			0,
+			nil,
 		)
 	}
 	if externalConfig.ProtocPath != nil {
@@ -400,6 +431,23 @@ func newGeneratePluginConfigFromExternalV2(
 	if err != nil {
 		return nil, err
 	}
+	if externalConfig.Local == nil {
+		var pluginName string
+		if externalConfig.Remote != nil {
+			pluginName = "remote plugin " + *externalConfig.Remote
+		} else {
+			pluginName = "protoc built-in plugin " + *externalConfig.ProtocBuiltin
+		}
+		if externalConfig.Timeout != nil {
+			return nil, fmt.Errorf("cannot specify timeout for %s", pluginName)
+		}
+		if externalConfig.MaxOutputBytes != nil {
+			return nil, fmt.Errorf("cannot specify max_output_bytes for %s", pluginName)
+		}
+		if externalConfig.Env != nil {
+			return nil, fmt.Errorf("cannot specify env for %s", pluginName)
+		}
+	}
 	switch {
 	case externalConfig.Remote != nil:
 		var revision int
@@ -432,6 +480,29 @@ func newGeneratePluginConfigFromExternalV2(
 		if externalConfig.ProtocPath != nil {
 			return nil, fmt.Errorf("cannot specify protoc_path for local plugin %s", localPluginName)
 		}
+		var timeout time.Duration
+		if externalConfig.Timeout != nil {
+			timeout, err = time.ParseDuration(*externalConfig.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timeout for local plugin %s: %w", localPluginName, err)
+			}
+			if timeout <= 0 {
+				return nil, fmt.Errorf("timeout for local plugin %s must be positive", localPluginName)
+			}
+		}
+		var maxOutputBytes int64
+		if externalConfig.MaxOutputBytes != nil {
+			maxOutputBytes = *externalConfig.MaxOutputBytes
+			if maxOutputBytes <= 0 {
+				return nil, fmt.Errorf("max_output_bytes for local plugin %s must be positive", localPluginName)
+			}
+		}
+		var env []string
+		if externalConfig.Env != nil {
+			// Keep env non-nil even when empty, as an empty list means no
+			// environment variables are passed to the plugin.
+			env = append([]string{}, (*externalConfig.Env)...)
+		}
 		return newLocalGeneratePluginConfig(
 			strings.Join(path, " "),
 			externalConfig.Out,
@@ -440,6 +511,9 @@ func newGeneratePluginConfigFromExternalV2(
 			externalConfig.IncludeWKT,
 			parsedStrategy,
 			path,
+			timeout,
+			maxOutputBytes,
+			env,
 		)
 	case externalConfig.ProtocBuiltin != nil:
 		protocPath, err := encoding.InterfaceSliceOrStringToStringSlice(externalConfig.ProtocPath)
@@ -523,6 +597,9 @@ func newLocalGeneratePluginConfig(
 	includeWKT bool,
 	strategy *GenerateStrategy,
 	path []string,
+	timeout time.Duration,
+	maxOutputBytes int64,
+	env []string,
 ) (*generatePluginConfig, error) {
 	if len(path) == 0 {
 		return nil, errors.New("must specify a path to the plugin")
@@ -530,6 +607,11 @@ func newLocalGeneratePluginConfig(
 	if includeWKT && !includeImports {
 		return nil, errors.New("cannot include well-known types without including imports")
 	}
+	for _, envName := range env {
+		if envName == "" || strings.Contains(envName, "=") {
+			return nil, fmt.Errorf("invalid environment variable name %q in env", envName)
+		}
+	}
 	return &generatePluginConfig{
 		generatePluginConfigType: GeneratePluginConfigTypeLocal,
 		name:                     name,
@@ -539,6 +621,9 @@ func newLocalGeneratePluginConfig(
 		opts:                     opt,
 		includeImports:           includeImports,
 		includeWKT:               includeWKT,
+		timeout:                  timeout,
+		maxOutputBytes:           maxOutputBytes,
+		env:                      env,
 	}, nil
 }
 
@@ -613,6 +698,18 @@ func (p *generatePluginConfig) Revision() int {
 	return p.revision
 }
 
+func (p *generatePluginConfig) Timeout() time.Duration {
+	return p.timeout
+}
+
+func (p *generatePluginConfig) MaxOutputBytes() int64 {
+	return p.maxOutputBytes
+}
+
+func (p *generatePluginConfig) Env() []string {
+	return p.env
+}
+
 func (p *generatePluginConfig) isGeneratePluginConfig() {}
 
 func newExternalGeneratePluginConfigV2FromPluginConfig(
@@ -655,6 +752,15 @@ func newExternalGeneratePluginConfigV2FromPluginConfig(
 		case len(path) > 1:
 			externalPluginConfigV2.Local = path
 		}
+		if timeout := generatePluginConfig.Timeout(); timeout != 0 {
+			externalPluginConfigV2.Timeout = toPointer(timeout.String())
+		}
+		if maxOutputBytes := generatePluginConfig.MaxOutputBytes(); maxOutputBytes != 0 {
+			externalPluginConfigV2.MaxOutputBytes = &maxOutputBytes
+		}
+		if env := generatePluginConfig.Env(); env != nil {
+			externalPluginConfigV2.Env = &env
+		}
 	case GeneratePluginConfigTypeProtocBuiltin:
 		externalPluginConfigV2.ProtocBuiltin = toPointer(generatePluginConfig.Name())
 		if protocPath := generatePluginConfig.ProtocPath(); len(protocPath) > 0 {
@@ -48,6 +48,8 @@ const (
 	InputConfigTypeTextImage
 	// InputConfigTypeYAMLImage is the yaml image input type.
 	InputConfigTypeYAMLImage
+	// InputConfigTypeReflect is the server reflection input type.
+	InputConfigTypeReflect
 )
 
 // String implements fmt.Stringer.
@@ -109,6 +111,7 @@ var (
 		InputConfigTypeYAMLImage: {
 			compressionKey: {},
 		},
+		InputConfigTypeReflect: {},
 	}
 	inputConfigTypeToString = map[InputConfigType]string{
 		InputConfigTypeGitRepo:     "git_repo",
@@ -121,6 +124,7 @@ var (
 		InputConfigTypeJSONImage:   "json_image",
 		InputConfigTypeTextImage:   "text_image",
 		InputConfigTypeYAMLImage:   "yaml_image",
+		InputConfigTypeReflect:     "reflect",
 	}
 	allInputConfigTypeString = stringutil.SliceToHumanString(
 		slicesext.MapValuesToSortedSlice(inputConfigTypeToString),
@@ -162,6 +166,9 @@ type InputConfig interface {
 	ExcludePaths() []string
 	// IncludeTypes returns the types to generate. An empty slice means to generate for all types.
 	IncludeTypes() []string
+	// ExcludeTypes returns the types to exclude from generation, along with the
+	// fields, extensions and methods that refer to them.
+	ExcludeTypes() []string
 
 	isInputConfig()
 }
@@ -328,6 +335,20 @@ func NewYAMLImageInputConfig(
 	}, nil
 }
 
+// NewReflectInputConfig returns an input config for a server that supports
+// gRPC server reflection.
+func NewReflectInputConfig(
+	location string,
+) (InputConfig, error) {
+	if location == "" {
+		return nil, errors.New("empty location for server reflection")
+	}
+	return &inputConfig{
+		inputConfigType: InputConfigTypeReflect,
+		location:        location,
+	}, nil
+}
+
 // *** PRIVATE ***
 
 type inputConfig struct {
@@ -343,6 +364,7 @@ type inputConfig struct {
 	recurseSubmodules   bool
 	includePackageFiles bool
 	includeTypes        []string
+	excludeTypes        []string
 	targetPaths         []string
 	excludePaths        []string
 }
@@ -391,6 +413,10 @@ func newInputConfigFromExternalV2(externalConfig externalInputConfigV2) (InputCo
 		inputConfigTypes = append(inputConfigTypes, InputConfigTypeGitRepo)
 		inputConfig.location = *externalConfig.GitRepo
 	}
+	if externalConfig.Reflect != nil {
+		inputConfigTypes = append(inputConfigTypes, InputConfigTypeReflect)
+		inputConfig.location = *externalConfig.Reflect
+	}
 	if len(inputConfigTypes) == 0 {
 		return nil, fmt.Errorf("must specify one of %s", allInputConfigTypeString)
 	}
@@ -399,8 +425,9 @@ func newInputConfigFromExternalV2(externalConfig externalInputConfigV2) (InputCo
 	}
 	inputConfigType := inputConfigTypes[0]
 	inputConfig.inputConfigType = inputConfigType
-	// Types, TargetPaths, and ExcludePaths.
+	// Types, ExcludeTypes, TargetPaths, and ExcludePaths.
 	inputConfig.includeTypes = externalConfig.Types
+	inputConfig.excludeTypes = externalConfig.ExcludeTypes
 	inputConfig.targetPaths = externalConfig.TargetPaths
 	inputConfig.excludePaths = externalConfig.ExcludePaths
 	// Options depending on input format.
@@ -516,6 +543,10 @@ func (i *inputConfig) IncludeTypes() []string {
 	return i.includeTypes
 }
 
+func (i *inputConfig) ExcludeTypes() []string {
+	return i.excludeTypes
+}
+
 func (i *inputConfig) isInputConfig() {}
 
 func newExternalInputConfigV2FromInputConfig(
@@ -543,6 +574,8 @@ func newExternalInputConfigV2FromInputConfig(
 		externalInputConfigV2.TextImage = toPointer(inputConfig.Location())
 	case InputConfigTypeYAMLImage:
 		externalInputConfigV2.YAMLImage = toPointer(inputConfig.Location())
+	case InputConfigTypeReflect:
+		externalInputConfigV2.Reflect = toPointer(inputConfig.Location())
 	default:
 		return externalInputConfigV2, syserror.Newf("unknown input config type: %v", inputConfig.Type())
 	}
@@ -574,5 +607,6 @@ func newExternalInputConfigV2FromInputConfig(
 	externalInputConfigV2.TargetPaths = inputConfig.TargetPaths()
 	externalInputConfigV2.ExcludePaths = inputConfig.ExcludePaths()
 	externalInputConfigV2.Types = inputConfig.IncludeTypes()
+	externalInputConfigV2.ExcludeTypes = inputConfig.ExcludeTypes()
 	return externalInputConfigV2, nil
 }
@@ -0,0 +1,118 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"github.com/bufbuild/buf/private/pkg/licenseheader"
+)
+
+// LicenseHeaderConfig is the configuration for the license header that every .proto file
+// of a Module should start with.
+type LicenseHeaderConfig interface {
+	// TemplateText returns the text of the license header template.
+	//
+	// The template may reference {{.CopyrightHolder}} and {{.YearRange}}.
+	// If empty, licenseheader.DefaultTemplateText is used.
+	TemplateText() string
+	// CopyrightHolder returns the copyright holder.
+	CopyrightHolder() string
+	// YearRange returns the year range, of the form YYYY or YYYY-YYYY.
+	YearRange() string
+	// Template returns the licenseheader.Template for this configuration.
+	//
+	// This is never nil.
+	Template() licenseheader.Template
+
+	isLicenseHeaderConfig()
+}
+
+// NewLicenseHeaderConfig returns a new LicenseHeaderConfig.
+func NewLicenseHeaderConfig(
+	templateText string,
+	copyrightHolder string,
+	yearRange string,
+) (LicenseHeaderConfig, error) {
+	return newLicenseHeaderConfig(templateText, copyrightHolder, yearRange)
+}
+
+// *** PRIVATE ***
+
+type licenseHeaderConfig struct {
+	templateText    string
+	copyrightHolder string
+	yearRange       string
+	template        licenseheader.Template
+}
+
+func newLicenseHeaderConfigForExternalV2(
+	externalConfig *externalBufYAMLFileLicenseHeaderV2,
+) (LicenseHeaderConfig, error) {
+	if externalConfig == nil {
+		return nil, nil
+	}
+	return newLicenseHeaderConfig(
+		externalConfig.Template,
+		externalConfig.CopyrightHolder,
+		externalConfig.YearRange,
+	)
+}
+
+func newLicenseHeaderConfig(
+	templateText string,
+	copyrightHolder string,
+	yearRange string,
+) (*licenseHeaderConfig, error) {
+	template, err := licenseheader.NewTemplate(templateText, copyrightHolder, yearRange)
+	if err != nil {
+		return nil, err
+	}
+	return &licenseHeaderConfig{
+		templateText:    templateText,
+		copyrightHolder: copyrightHolder,
+		yearRange:       yearRange,
+		template:        template,
+	}, nil
+}
+
+func (l *licenseHeaderConfig) TemplateText() string {
+	return l.templateText
+}
+
+func (l *licenseHeaderConfig) CopyrightHolder() string {
+	return l.copyrightHolder
+}
+
+func (l *licenseHeaderConfig) YearRange() string {
+	return l.yearRange
+}
+
+func (l *licenseHeaderConfig) Template() licenseheader.Template {
+	return l.template
+}
+
+func (*licenseHeaderConfig) isLicenseHeaderConfig() {}
+
+func getExternalLicenseHeaderV2ForLicenseHeaderConfig(
+	licenseHeaderConfig LicenseHeaderConfig,
+) *externalBufYAMLFileLicenseHeaderV2 {
+	if licenseHeaderConfig == nil {
+		return nil
+	}
+	return &externalBufYAMLFileLicenseHeaderV2{
+		Template:        licenseHeaderConfig.TemplateText(),
+		CopyrightHolder: licenseHeaderConfig.CopyrightHolder(),
+		YearRange:       licenseHeaderConfig.YearRange(),
+	}
+}
@@ -24,6 +24,7 @@ var (
 		false,
 		"",
 		false,
+		nil,
 	)
 
 	// DefaultLintConfigV2 is the default lint config for v2.
@@ -35,6 +36,7 @@ var (
 		false,
 		"",
 		true, // We default to allowing comment ignores in v2
+		nil,
 	)
 )
 
@@ -48,6 +50,10 @@ type LintConfig interface {
 	RPCAllowGoogleProtobufEmptyResponses() bool
 	ServiceSuffix() string
 	AllowCommentIgnores() bool
+	// LicenseHeaderConfig returns the license header configuration.
+	//
+	// May be nil, in which case no license header is configured.
+	LicenseHeaderConfig() LicenseHeaderConfig
 
 	isLintConfig()
 }
@@ -61,6 +67,7 @@ func NewLintConfig(
 	rpcAllowGoogleProtobufEmptyResponses bool,
 	serviceSuffix string,
 	allowCommentIgnores bool,
+	licenseHeaderConfig LicenseHeaderConfig,
 ) LintConfig {
 	return newLintConfig(
 		checkConfig,
@@ -70,6 +77,7 @@ func NewLintConfig(
 		rpcAllowGoogleProtobufEmptyResponses,
 		serviceSuffix,
 		allowCommentIgnores,
+		licenseHeaderConfig,
 	)
 }
 
@@ -84,6 +92,7 @@ type lintConfig struct {
 	rpcAllowGoogleProtobufEmptyResponses bool
 	serviceSuffix                        string
 	allowCommentIgnores                  bool
+	licenseHeaderConfig                  LicenseHeaderConfig
 }
 
 func newLintConfig(
@@ -94,6 +103,7 @@ func newLintConfig(
 	rpcAllowGoogleProtobufEmptyResponses bool,
 	serviceSuffix string,
 	allowCommentIgnores bool,
+	licenseHeaderConfig LicenseHeaderConfig,
 ) *lintConfig {
 	return &lintConfig{
 		CheckConfig:                          checkConfig,
@@ -103,6 +113,7 @@ func newLintConfig(
 		rpcAllowGoogleProtobufEmptyResponses: rpcAllowGoogleProtobufEmptyResponses,
 		serviceSuffix:                        serviceSuffix,
 		allowCommentIgnores:                  allowCommentIgnores,
+		licenseHeaderConfig:                  licenseHeaderConfig,
 	}
 }
 
@@ -130,4 +141,8 @@ func (l *lintConfig) AllowCommentIgnores() bool {
 	return l.allowCommentIgnores
 }
 
+func (l *lintConfig) LicenseHeaderConfig() LicenseHeaderConfig {
+	return l.licenseHeaderConfig
+}
+
 func (*lintConfig) isLintConfig() {}
@@ -116,6 +116,10 @@ type ModuleConfig interface {
 	// For v1beta1, this may contain multiple keys but the values for these keys are empty slices.
 	// For v1, this will contain a single key "." with an empty slice as its value.
 	// For v2, this will contain a single key ".", with potentially some includes.
+	//
+	// For v2, includes may also be glob patterns, as determined by normalpath.IsGlob. A glob
+	// include matches a proto file if the pattern matches the file, or any directory containing
+	// the file. Glob patterns are not checked for overlap. See MatchIncludeOrExclude.
 	RootToIncludes() map[string][]string
 	// RootToExcludes contains a map from root to the excludes for that root.
 	// The keys in RootToExcludes are always the same as those in RootToIncludes.
@@ -136,6 +140,8 @@ type ModuleConfig interface {
 	//
 	// For v1beta1, this may contain multiple keys.
 	// For v1 and v2, this will contain a single key ".", with potentially some excludes.
+	//
+	// For v2, excludes may also be glob patterns, with the same semantics as for RootToIncludes.
 	RootToExcludes() map[string][]string
 	// LintConfig returns the lint configuration.
 	//
@@ -0,0 +1,161 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/slicesext"
+	"github.com/bufbuild/buf/private/pkg/stringutil"
+)
+
+// ModuleConfigPathExplanation explains whether a ModuleConfig claims a path, that is
+// whether the file at the path is part of the module.
+type ModuleConfigPathExplanation interface {
+	// Claimed returns true if the ModuleConfig claims the path.
+	Claimed() bool
+	// Reason returns a human-readable reason for why the ModuleConfig does or does not claim the path.
+	//
+	// This references includes and excludes as they are written in the buf.yaml file.
+	Reason() string
+	// ModuleFilePath returns the path of the file within the module if the ModuleConfig
+	// claims the path, and empty otherwise.
+	//
+	// This is the path that lint rules such as PACKAGE_DIRECTORY_MATCH operate on.
+	ModuleFilePath() string
+
+	isModuleConfigPathExplanation()
+}
+
+// MatchIncludeOrExclude returns true if the include or exclude of a ModuleConfig matches the path.
+//
+// The include or exclude and the path must be relative to the same root. If the include or exclude
+// is a glob pattern, it matches if the pattern matches the path or any directory containing the path.
+// Otherwise, it matches if the path is contained within the include or exclude directory.
+func MatchIncludeOrExclude(includeOrExclude string, path string) bool {
+	if normalpath.IsGlob(includeOrExclude) {
+		return normalpath.GlobEqualsOrContainsPath(includeOrExclude, path)
+	}
+	return normalpath.ContainsPath(includeOrExclude, path, normalpath.Relative)
+}
+
+// ExplainModuleConfigPath explains whether the ModuleConfig claims the path.
+//
+// The path must be normalized and validated, and relative to the directory of the buf.yaml
+// file that contains the ModuleConfig.
+func ExplainModuleConfigPath(moduleConfig ModuleConfig, path string) (ModuleConfigPathExplanation, error) {
+	dirPath := moduleConfig.DirPath()
+	if !normalpath.ContainsPath(dirPath, path, normalpath.Relative) {
+		return newModuleConfigPathExplanation(false, "", "not within the module directory %q", dirPath), nil
+	}
+	if normalpath.Ext(path) != ".proto" {
+		return newModuleConfigPathExplanation(false, "", "only .proto files are part of modules"), nil
+	}
+	relPath, err := normalpath.Rel(dirPath, path)
+	if err != nil {
+		return nil, err
+	}
+	rootToIncludes := moduleConfig.RootToIncludes()
+	rootToExcludes := moduleConfig.RootToExcludes()
+	roots := slicesext.MapKeysToSortedSlice(rootToExcludes)
+	root, ok := findFirst(roots, func(root string) bool {
+		return normalpath.EqualsOrContainsPath(root, relPath, normalpath.Relative)
+	})
+	if !ok {
+		return newModuleConfigPathExplanation(
+			false,
+			"",
+			"not within any root: %s",
+			stringutil.JoinSliceQuoted(slicesext.Map(roots, func(root string) string { return normalpath.Join(dirPath, root) }), ", "),
+		), nil
+	}
+	rootRelPath, err := normalpath.Rel(root, relPath)
+	if err != nil {
+		return nil, err
+	}
+	// Includes and excludes are written in buf.yaml files relative to the directory of the buf.yaml file.
+	toExternal := func(includeOrExclude string) string {
+		return normalpath.Join(dirPath, root, includeOrExclude)
+	}
+	excludes := slicesext.ToUniqueSorted(slicesext.Copy(rootToExcludes[root]))
+	if exclude, ok := findFirst(excludes, func(exclude string) bool {
+		return MatchIncludeOrExclude(exclude, rootRelPath)
+	}); ok {
+		return newModuleConfigPathExplanation(false, "", "excluded by exclude %q", toExternal(exclude)), nil
+	}
+	includes := rootToIncludes[root]
+	if len(includes) == 0 {
+		if root != "." {
+			return newModuleConfigPathExplanation(true, rootRelPath, "within root %q", normalpath.Join(dirPath, root)), nil
+		}
+		return newModuleConfigPathExplanation(true, rootRelPath, "within the module directory %q, which has no includes", dirPath), nil
+	}
+	includes = slicesext.ToUniqueSorted(slicesext.Copy(includes))
+	if include, ok := findFirst(includes, func(include string) bool {
+		return MatchIncludeOrExclude(include, rootRelPath)
+	}); ok {
+		return newModuleConfigPathExplanation(true, rootRelPath, "included by include %q", toExternal(include)), nil
+	}
+	return newModuleConfigPathExplanation(
+		false,
+		"",
+		"not matched by any include: %s",
+		stringutil.JoinSliceQuoted(slicesext.Map(includes, toExternal), ", "),
+	), nil
+}
+
+// *** PRIVATE ***
+
+type moduleConfigPathExplanation struct {
+	claimed        bool
+	moduleFilePath string
+	reason         string
+}
+
+func newModuleConfigPathExplanation(
+	claimed bool,
+	moduleFilePath string,
+	format string,
+	args ...any,
+) *moduleConfigPathExplanation {
+	return &moduleConfigPathExplanation{
+		claimed:        claimed,
+		moduleFilePath: moduleFilePath,
+		reason:         fmt.Sprintf(format, args...),
+	}
+}
+
+func (m *moduleConfigPathExplanation) Claimed() bool {
+	return m.claimed
+}
+
+func (m *moduleConfigPathExplanation) Reason() string {
+	return m.reason
+}
+
+func (m *moduleConfigPathExplanation) ModuleFilePath() string {
+	return m.moduleFilePath
+}
+
+func (*moduleConfigPathExplanation) isModuleConfigPathExplanation() {}
+
+func findFirst(values []string, f func(string) bool) (string, bool) {
+	if i := slices.IndexFunc(values, f); i >= 0 {
+		return values[i], true
+	}
+	return "", false
+}
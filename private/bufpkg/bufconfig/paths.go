@@ -19,6 +19,7 @@ import (
 	"sort"
 
 	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/slicesext"
 )
 
 // normalizeAndCheckPaths verifies that:
@@ -64,3 +65,52 @@ func normalizeAndCheckPaths(paths []string, name string) ([]string, error) {
 	}
 	return outputs, nil
 }
+
+// normalizeAndCheckPathsOrGlobs is normalizeAndCheckPaths for paths that may also be
+// glob patterns, as determined by normalpath.IsGlob.
+//
+// Glob patterns are normalized and validated, and must be unique, but are not checked for
+// overlap with other paths or glob patterns, as we cannot know which paths they match
+// statically.
+//
+// Normalizes and sorts the paths and glob patterns.
+func normalizeAndCheckPathsOrGlobs(pathsOrGlobs []string, name string) ([]string, error) {
+	if len(pathsOrGlobs) == 0 {
+		return pathsOrGlobs, nil
+	}
+	paths, err := normalizeAndCheckPaths(
+		slicesext.Filter(pathsOrGlobs, func(pathOrGlob string) bool { return !normalpath.IsGlob(pathOrGlob) }),
+		name,
+	)
+	if err != nil {
+		return nil, err
+	}
+	globs := make(map[string]struct{})
+	for _, glob := range slicesext.Filter(pathsOrGlobs, normalpath.IsGlob) {
+		glob, err := normalpath.NormalizeAndValidate(glob)
+		if err != nil {
+			// user error
+			return nil, err
+		}
+		if err := normalpath.ValidateGlob(glob); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		if _, ok := globs[glob]; ok {
+			return nil, fmt.Errorf("duplicate %s %q", name, glob)
+		}
+		globs[glob] = struct{}{}
+	}
+	outputs := append(paths, slicesext.MapKeysToSlice(globs)...)
+	sort.Strings(outputs)
+	return outputs, nil
+}
+
+// isPathOrGlobContainedInDirPath returns true if the path is equal to or contained within
+// the dirPath, or, if the path is a glob pattern, if the leading components of the glob pattern
+// without glob syntax are equal to or contained within the dirPath.
+func isPathOrGlobContainedInDirPath(dirPath string, pathOrGlob string) bool {
+	if normalpath.IsGlob(pathOrGlob) {
+		pathOrGlob = normalpath.GlobLiteralPrefix(pathOrGlob)
+	}
+	return normalpath.EqualsOrContainsPath(dirPath, pathOrGlob, normalpath.Relative)
+}
@@ -0,0 +1,136 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufparse"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+)
+
+// ReplaceConfig is a configuration that replaces a dependency with a local directory
+// or another module.
+//
+// Replacements only apply to local builds. They are never written to buf.lock files,
+// and workspaces with replacements cannot be pushed.
+type ReplaceConfig interface {
+	// FullName returns the FullName of the dependency that is replaced.
+	//
+	// This is never nil.
+	FullName() bufparse.FullName
+	// DirPath returns the path of the directory the dependency is replaced with,
+	// relative to the directory of the buf.yaml, or absolute.
+	//
+	// The directory may contain a buf.yaml. If it contains a v2 buf.yaml, the module
+	// with the replaced FullName in this buf.yaml is used.
+	//
+	// This is not empty only when the dependency is replaced with a local directory.
+	DirPath() string
+	// ModuleRef returns the reference to the module the dependency is replaced with.
+	//
+	// This is only non-nil when the dependency is replaced with a module.
+	ModuleRef() bufparse.Ref
+
+	isReplaceConfig()
+}
+
+// NewReplaceConfigForDirPath returns a new ReplaceConfig that replaces a dependency
+// with a local directory.
+func NewReplaceConfigForDirPath(
+	moduleFullName bufparse.FullName,
+	dirPath string,
+) (ReplaceConfig, error) {
+	return newReplaceConfig(moduleFullName, dirPath, nil)
+}
+
+// NewReplaceConfigForModuleRef returns a new ReplaceConfig that replaces a dependency
+// with another module.
+func NewReplaceConfigForModuleRef(
+	moduleFullName bufparse.FullName,
+	moduleRef bufparse.Ref,
+) (ReplaceConfig, error) {
+	return newReplaceConfig(moduleFullName, "", moduleRef)
+}
+
+// *** PRIVATE ***
+
+type replaceConfig struct {
+	moduleFullName bufparse.FullName
+	dirPath        string
+	moduleRef      bufparse.Ref
+}
+
+func newReplaceConfigForExternalV2(
+	externalConfig externalBufYAMLFileReplaceV2,
+) (ReplaceConfig, error) {
+	if externalConfig.Dep == "" {
+		return nil, errors.New("replace must specify a dep")
+	}
+	moduleFullName, err := bufparse.ParseFullName(externalConfig.Dep)
+	if err != nil {
+		return nil, fmt.Errorf("invalid replace dep: %w", err)
+	}
+	switch {
+	case externalConfig.Path != "" && externalConfig.Module != "":
+		return nil, fmt.Errorf("replace for %s cannot specify both a path and a module", moduleFullName.String())
+	case externalConfig.Path != "":
+		return newReplaceConfig(moduleFullName, externalConfig.Path, nil)
+	case externalConfig.Module != "":
+		moduleRef, err := bufparse.ParseRef(externalConfig.Module)
+		if err != nil {
+			return nil, fmt.Errorf("invalid replace module for %s: %w", moduleFullName.String(), err)
+		}
+		return newReplaceConfig(moduleFullName, "", moduleRef)
+	default:
+		return nil, fmt.Errorf("replace for %s must specify either a path or a module", moduleFullName.String())
+	}
+}
+
+func newReplaceConfig(
+	moduleFullName bufparse.FullName,
+	dirPath string,
+	moduleRef bufparse.Ref,
+) (*replaceConfig, error) {
+	if moduleFullName == nil {
+		return nil, errors.New("replace must specify a dep")
+	}
+	if (dirPath == "") == (moduleRef == nil) {
+		return nil, fmt.Errorf("replace for %s must specify exactly one of a path or a module", moduleFullName.String())
+	}
+	if dirPath != "" {
+		dirPath = normalpath.Normalize(dirPath)
+	}
+	return &replaceConfig{
+		moduleFullName: moduleFullName,
+		dirPath:        dirPath,
+		moduleRef:      moduleRef,
+	}, nil
+}
+
+func (r *replaceConfig) FullName() bufparse.FullName {
+	return r.moduleFullName
+}
+
+func (r *replaceConfig) DirPath() string {
+	return r.dirPath
+}
+
+func (r *replaceConfig) ModuleRef() bufparse.Ref {
+	return r.moduleRef
+}
+
+func (*replaceConfig) isReplaceConfig() {}
@@ -0,0 +1,119 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulesign"
+	"github.com/bufbuild/buf/private/bufpkg/bufparse"
+	"github.com/bufbuild/buf/private/pkg/slicesext"
+)
+
+// VerifyConfig is a configuration that lists the public keys trusted to sign the modules
+// of an owner.
+//
+// Every remote dependency of the owner must be signed by one of the public keys.
+type VerifyConfig interface {
+	// Registry returns the registry of the owner.
+	//
+	// This is never empty.
+	Registry() string
+	// Owner returns the name of the owner.
+	//
+	// This is never empty.
+	Owner() string
+	// PublicKeys returns the public keys trusted to sign the modules of the owner.
+	//
+	// This is never empty.
+	PublicKeys() []bufmodulesign.PublicKey
+	// Matches returns true if the module with the given FullName belongs to the owner.
+	Matches(moduleFullName bufparse.FullName) bool
+
+	isVerifyConfig()
+}
+
+// NewVerifyConfig returns a new VerifyConfig.
+//
+// The owner is of the form registry/owner.
+func NewVerifyConfig(
+	owner string,
+	publicKeys []bufmodulesign.PublicKey,
+) (VerifyConfig, error) {
+	return newVerifyConfig(owner, publicKeys)
+}
+
+// *** PRIVATE ***
+
+type verifyConfig struct {
+	registry   string
+	owner      string
+	publicKeys []bufmodulesign.PublicKey
+}
+
+func newVerifyConfigForExternalV2(
+	externalConfig externalBufYAMLFileVerifyV2,
+) (VerifyConfig, error) {
+	publicKeys, err := slicesext.MapError(externalConfig.Keys, bufmodulesign.ParsePublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid verify key for %s: %w", externalConfig.Owner, err)
+	}
+	return newVerifyConfig(externalConfig.Owner, publicKeys)
+}
+
+func newVerifyConfig(
+	owner string,
+	publicKeys []bufmodulesign.PublicKey,
+) (*verifyConfig, error) {
+	if owner == "" {
+		return nil, errors.New("verify must specify an owner")
+	}
+	registry, ownerName, ok := strings.Cut(owner, "/")
+	if !ok || registry == "" || ownerName == "" || strings.Contains(ownerName, "/") {
+		return nil, fmt.Errorf("invalid verify owner %q: must be in the form registry/owner", owner)
+	}
+	if len(publicKeys) == 0 {
+		return nil, fmt.Errorf("verify for %s must specify at least one key", owner)
+	}
+	return &verifyConfig{
+		registry:   registry,
+		owner:      ownerName,
+		publicKeys: publicKeys,
+	}, nil
+}
+
+func (v *verifyConfig) Registry() string {
+	return v.registry
+}
+
+func (v *verifyConfig) Owner() string {
+	return v.owner
+}
+
+func (v *verifyConfig) PublicKeys() []bufmodulesign.PublicKey {
+	return slicesext.Copy(v.publicKeys)
+}
+
+func (v *verifyConfig) Matches(moduleFullName bufparse.FullName) bool {
+	return moduleFullName.Registry() == v.registry && moduleFullName.Owner() == v.owner
+}
+
+func (*verifyConfig) isVerifyConfig() {}
+
+func getVerifyConfigOwnerString(verifyConfig VerifyConfig) string {
+	return verifyConfig.Registry() + "/" + verifyConfig.Owner()
+}
@@ -233,6 +233,152 @@ func TestTypesFromMainModule(t *testing.T) {
 	assert.ErrorIs(t, err, ErrImageFilterTypeNotFound)
 }
 
+func TestExclude(t *testing.T) {
+	t.Parallel()
+	t.Run("message", func(t *testing.T) {
+		t.Parallel()
+		runExcludeDiffTest(t, "testdata/excludes", []string{"pkg.Audit"}, "message.txtar")
+	})
+	t.Run("package", func(t *testing.T) {
+		t.Parallel()
+		runExcludeDiffTest(t, "testdata/excludes", []string{"pkg.internal"}, "package.txtar")
+	})
+	t.Run("imported", func(t *testing.T) {
+		t.Parallel()
+		runExcludeDiffTest(t, "testdata/excludes", []string{"google.protobuf.Timestamp"}, "imported.txtar")
+	})
+	t.Run("elements", func(t *testing.T) {
+		t.Parallel()
+		runExcludeDiffTest(t, "testdata/excludes", []string{"pkg.Foo.note", "pkg.STATUS_OK", "pkg.FooService.GetFoo"}, "elements.txtar")
+	})
+	t.Run("extension", func(t *testing.T) {
+		t.Parallel()
+		runExcludeDiffTest(t, "testdata/excludes", []string{"acme.visibility"}, "extension.txtar")
+	})
+	t.Run("options", func(t *testing.T) {
+		t.Parallel()
+		var opts []ImageExcludeOption
+		for _, predicate := range []string{
+			"(acme.visibility) = VISIBILITY_INTERNAL",
+			"acme.field_visibility=2",
+			"(acme.internal_value)",
+			"(acme.internal_method) = true",
+		} {
+			opt, err := ParseExcludeByOption(predicate)
+			require.NoError(t, err)
+			opts = append(opts, opt)
+		}
+		runExcludeDiffTest(t, "testdata/excludes", nil, "options.txtar", opts...)
+	})
+}
+
+func TestImageWithoutEmptyFiles(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	moduleSet, err := bufmoduletesting.NewModuleSetForPathToData(
+		map[string][]byte{
+			"a.proto": []byte(`syntax = "proto3";package a;import "b.proto";import "c.proto";message Foo{ c.Baz baz = 1; }`),
+			"b.proto": []byte(`syntax = "proto3";package b;message Bar{}`),
+			"c.proto": []byte(`syntax = "proto3";package c;message Baz{}`),
+		},
+	)
+	require.NoError(t, err)
+	image, err := bufimage.BuildImage(
+		ctx,
+		slogtestext.NewLogger(t),
+		bufmodule.ModuleSetToModuleReadBucketWithOnlyProtoFiles(moduleSet),
+	)
+	require.NoError(t, err)
+
+	// The unused import of b.proto is retained by ImageWithoutTypes.
+	image, err = ImageWithoutTypes(image, []string{"b.Bar"})
+	require.NoError(t, err)
+	require.NotNil(t, image.GetFile("b.proto"))
+	assert.Equal(t, []string{"b.proto", "c.proto"}, image.GetFile("a.proto").FileDescriptorProto().GetDependency())
+
+	image, err = ImageWithoutEmptyFiles(image)
+	require.NoError(t, err)
+	assert.Nil(t, image.GetFile("b.proto"))
+	assert.Equal(t, []string{"c.proto"}, image.GetFile("a.proto").FileDescriptorProto().GetDependency())
+	_, err = protodesc.NewFiles(bufimage.ImageToFileDescriptorSet(image))
+	require.NoError(t, err)
+}
+
+func TestImageWithoutEmptyFilesPublicImports(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	moduleSet, err := bufmoduletesting.NewModuleSetForPathToData(
+		map[string][]byte{
+			// a.proto refers to c.Baz through the public import of c.proto in b.proto.
+			"a.proto": []byte(`syntax = "proto3";package a;import "b.proto";message Foo{ c.Baz baz = 1; }`),
+			"b.proto": []byte(`syntax = "proto3";package b;import public "c.proto";import public "d.proto";`),
+			"c.proto": []byte(`syntax = "proto3";package c;message Baz{}`),
+			"d.proto": []byte(`syntax = "proto3";package d;message Qux{}`),
+			// e.proto only forwards d.proto, which is left empty.
+			"e.proto": []byte(`syntax = "proto3";package e;import public "d.proto";`),
+		},
+	)
+	require.NoError(t, err)
+	image, err := bufimage.BuildImage(
+		ctx,
+		slogtestext.NewLogger(t),
+		bufmodule.ModuleSetToModuleReadBucketWithOnlyProtoFiles(moduleSet),
+	)
+	require.NoError(t, err)
+
+	image, err = ImageWithoutTypes(image, []string{"d.Qux"})
+	require.NoError(t, err)
+	image, err = ImageWithoutEmptyFiles(image)
+	require.NoError(t, err)
+	assert.Nil(t, image.GetFile("d.proto"))
+	assert.Nil(t, image.GetFile("e.proto"))
+	forwardingFile := image.GetFile("b.proto")
+	require.NotNil(t, forwardingFile)
+	assert.Equal(t, []string{"c.proto"}, forwardingFile.FileDescriptorProto().GetDependency())
+	assert.Equal(t, []int32{0}, forwardingFile.FileDescriptorProto().GetPublicDependency())
+	assert.Equal(t, []string{"b.proto"}, image.GetFile("a.proto").FileDescriptorProto().GetDependency())
+	_, err = protodesc.NewFiles(bufimage.ImageToFileDescriptorSet(image))
+	require.NoError(t, err)
+}
+
+func TestExcludeErrors(t *testing.T) {
+	t.Parallel()
+	_, image, err := getImage(context.Background(), slogtestext.NewLogger(t), "testdata/excludes")
+	require.NoError(t, err)
+
+	_, err = ImageWithoutTypes(image, []string{"pkg.Nonexisting"})
+	assert.ErrorIs(t, err, ErrImageFilterTypeNotFound)
+	_, err = ImageWithoutTypes(image, nil, WithExcludeByOption("acme.nonexisting", ""))
+	assert.ErrorIs(t, err, ErrImageFilterTypeNotFound)
+	_, err = ImageWithoutTypes(image, []string{"pkg.STATUS_UNSPECIFIED"})
+	assert.ErrorContains(t, err, "first value of open enum")
+	_, err = ImageWithoutTypes(image, []string{"pkg.STATUS_UNSPECIFIED", "pkg.STATUS_OK", "pkg.STATUS_DEBUG"})
+	assert.ErrorContains(t, err, "all values of enum")
+	_, err = ParseExcludeByOption("(acme.visibility")
+	assert.Error(t, err)
+}
+
+func TestExcludeSourceCodeInfo(t *testing.T) {
+	t.Parallel()
+	_, image, err := getImage(context.Background(), slogtestext.NewLogger(t), "testdata/sourcecodeinfo")
+	require.NoError(t, err)
+	originalData, err := protoencoding.NewWireMarshaler().Marshal(bufimage.ImageToFileDescriptorSet(image))
+	require.NoError(t, err)
+
+	filteredImage, err := ImageWithoutTypes(image, []string{"foo.bar.Foo.NestedFoo", "foo.bar.Svc.Do"})
+	require.NoError(t, err)
+	resolver, err := protoencoding.NewResolver(bufimage.ImageToFileDescriptorProtos(filteredImage)...)
+	require.NoError(t, err)
+	file, err := resolver.FindFileByPath("test.proto")
+	require.NoError(t, err)
+	examineComments(t, file)
+
+	// The original image is not mutated.
+	data, err := protoencoding.NewWireMarshaler().Marshal(bufimage.ImageToFileDescriptorSet(image))
+	require.NoError(t, err)
+	assert.Equal(t, originalData, data)
+}
+
 func getImage(ctx context.Context, logger *slog.Logger, testdataDir string, options ...bufimage.BuildImageOption) (storage.ReadWriteBucket, bufimage.Image, error) {
 	bucket, err := storageos.NewProvider().NewReadWriteBucket(testdataDir)
 	if err != nil {
@@ -262,6 +408,20 @@ func runDiffTest(t *testing.T, testdataDir string, typenames []string, expectedF
 	filteredImage, err := ImageFilteredByTypesWithOptions(image, typenames, opts...)
 	require.NoError(t, err)
 	assert.NotNil(t, image)
+	checkImageExpectation(t, ctx, filteredImage, bucket, expectedFile)
+}
+
+func runExcludeDiffTest(t *testing.T, testdataDir string, typenames []string, expectedFile string, opts ...ImageExcludeOption) {
+	ctx := context.Background()
+	bucket, image, err := getImage(ctx, slogtestext.NewLogger(t), testdataDir, bufimage.WithExcludeSourceCodeInfo())
+	require.NoError(t, err)
+
+	filteredImage, err := ImageWithoutTypes(image, typenames, opts...)
+	require.NoError(t, err)
+	checkImageExpectation(t, ctx, filteredImage, bucket, expectedFile)
+}
+
+func checkImageExpectation(t *testing.T, ctx context.Context, filteredImage bufimage.Image, bucket storage.ReadWriteBucket, expectedFile string) {
 	assert.True(t, imageIsDependencyOrdered(filteredImage), "image files not in dependency order")
 
 	// We may have filtered out custom options from the set in the step above. However, the options messages
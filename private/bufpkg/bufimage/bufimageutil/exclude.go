@@ -0,0 +1,824 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimageutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/protocompile/walk"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// ImageExcludeOption is an option that can be passed to ImageWithoutTypes.
+type ImageExcludeOption func(*imageExcludeOptions)
+
+// WithExcludeByOption returns an option that will cause ImageWithoutTypes to also
+// exclude every element that has the custom option with the given fully-qualified
+// name set to the given value.
+//
+// Enum values are matched by name or by number, and repeated options match if any
+// element matches. If value is empty, elements match if the option is set at all
+// (and, for bool options, is true).
+func WithExcludeByOption(optionName string, value string) ImageExcludeOption {
+	return func(opts *imageExcludeOptions) {
+		opts.optionPredicates = append(
+			opts.optionPredicates,
+			&optionPredicate{
+				optionName: optionName,
+				value:      value,
+			},
+		)
+	}
+}
+
+// ParseExcludeByOption parses an option predicate of the form
+// "(acme.visibility) = INTERNAL" and returns the corresponding ImageExcludeOption.
+//
+// The parentheses around the option name are optional. If no value is given, as in
+// "(acme.internal)", elements match if the option is set (and, for bool options, is true).
+func ParseExcludeByOption(predicate string) (ImageExcludeOption, error) {
+	optionName, value, _ := strings.Cut(predicate, "=")
+	optionName = strings.TrimSpace(optionName)
+	if strings.HasPrefix(optionName, "(") && strings.HasSuffix(optionName, ")") {
+		optionName = strings.TrimSpace(optionName[1 : len(optionName)-1])
+	}
+	optionName = strings.TrimPrefix(optionName, ".")
+	if optionName == "" || strings.ContainsAny(optionName, "() \t") {
+		return nil, fmt.Errorf("invalid option predicate %q: expected the form (option.name) = value", predicate)
+	}
+	value = strings.TrimSpace(value)
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		value = unquoted
+	}
+	return WithExcludeByOption(optionName, value), nil
+}
+
+// ImageWithoutTypes returns a new image with the given types, and any elements matching
+// the given exclude options, removed.
+//
+// The names may refer to packages, messages, enums, services, methods, fields, extensions
+// and enum values. Excluding a package also excludes all of its sub-packages.
+//
+// Removing an element also removes the elements that can no longer be described without it:
+//
+//	Fields         - fields whose type is an excluded message or enum
+//	Extensions     - extensions whose extendee or type is excluded
+//	Methods        - methods whose request or response type is excluded
+//	Oneofs         - oneofs left without any fields
+//	Custom options - option values set using an excluded extension
+//
+// Imports that are no longer needed after pruning are removed. Files are never removed,
+// even if all of their contents are excluded.
+//
+// Unlike ImageFilteredByTypes, the given image is not mutated.
+func ImageWithoutTypes(image bufimage.Image, types []string, options ...ImageExcludeOption) (bufimage.Image, error) {
+	opts := &imageExcludeOptions{}
+	for _, option := range options {
+		option(opts)
+	}
+	excluder, err := newImageExcluder(image)
+	if err != nil {
+		return nil, err
+	}
+	if err := excluder.addExcludedNames(types); err != nil {
+		return nil, err
+	}
+	for _, predicate := range opts.optionPredicates {
+		if err := excluder.addExcludedByOption(predicate); err != nil {
+			return nil, err
+		}
+	}
+	if len(excluder.excludedNames) == 0 {
+		return image, nil
+	}
+	excluder.addExcludedMapEntries()
+	newImageFiles := make([]bufimage.ImageFile, 0, len(image.Files()))
+	for _, imageFile := range image.Files() {
+		newImageFile, err := excluder.excludeFromImageFile(imageFile)
+		if err != nil {
+			return nil, err
+		}
+		newImageFiles = append(newImageFiles, newImageFile)
+	}
+	return bufimage.NewImage(newImageFiles)
+}
+
+// ImageWithoutEmptyFiles returns a new image without the files that do not declare
+// any messages, enums, services or extensions, such as the files left empty by
+// ImageWithoutTypes. Imports of the removed files are removed from the remaining files.
+//
+// Files that forward the types of other files with public imports are only removed
+// if all of their public imports are removed.
+//
+// The given image is not mutated.
+func ImageWithoutEmptyFiles(image bufimage.Image) (bufimage.Image, error) {
+	emptyFilePaths := make(map[string]struct{})
+	// A file that is found to be empty may make files that publicly import it empty,
+	// so we iterate until no new empty files are found.
+	for {
+		numEmptyFilePaths := len(emptyFilePaths)
+		for _, imageFile := range image.Files() {
+			if _, ok := emptyFilePaths[imageFile.Path()]; ok {
+				continue
+			}
+			if isFileDescriptorEmpty(imageFile.FileDescriptorProto(), emptyFilePaths) {
+				emptyFilePaths[imageFile.Path()] = struct{}{}
+			}
+		}
+		if len(emptyFilePaths) == numEmptyFilePaths {
+			break
+		}
+	}
+	if len(emptyFilePaths) == 0 {
+		return image, nil
+	}
+	newImageFiles := make([]bufimage.ImageFile, 0, len(image.Files())-len(emptyFilePaths))
+	for _, imageFile := range image.Files() {
+		if _, ok := emptyFilePaths[imageFile.Path()]; ok {
+			continue
+		}
+		importsEmptyFile := false
+		for _, importPath := range imageFile.FileDescriptorProto().GetDependency() {
+			if _, ok := emptyFilePaths[importPath]; ok {
+				importsEmptyFile = true
+				break
+			}
+		}
+		if !importsEmptyFile {
+			newImageFiles = append(newImageFiles, imageFile)
+			continue
+		}
+		fileDescriptor, ok := proto.Clone(imageFile.FileDescriptorProto()).(*descriptorpb.FileDescriptorProto)
+		if !ok {
+			return nil, fmt.Errorf("unexpected type when cloning descriptor for %q", imageFile.Path())
+		}
+		var sourcePathRemapper *sourcePathsRemapTrie
+		if len(fileDescriptor.SourceCodeInfo.GetLocation()) > 0 {
+			sourcePathRemapper = &sourcePathsRemapTrie{}
+		}
+		unusedDependencyIndexes := filterDependencies(fileDescriptor, func(_ int32, importPath string) bool {
+			_, ok := emptyFilePaths[importPath]
+			return !ok
+		}, imageFile.UnusedDependencyIndexes(), sourcePathRemapper)
+		remapSourceCodeInfo(fileDescriptor, sourcePathRemapper)
+		newImageFile, err := bufimage.NewImageFile(
+			fileDescriptor,
+			imageFile.FullName(),
+			imageFile.CommitID(),
+			imageFile.ExternalPath(),
+			imageFile.LocalPath(),
+			imageFile.IsImport(),
+			imageFile.IsSyntaxUnspecified(),
+			unusedDependencyIndexes,
+		)
+		if err != nil {
+			return nil, err
+		}
+		newImageFiles = append(newImageFiles, newImageFile)
+	}
+	return bufimage.NewImage(newImageFiles)
+}
+
+// *** PRIVATE ***
+
+// isFileDescriptorEmpty returns true if the file does not declare any messages, enums,
+// services or extensions, and all of its public imports are in emptyFilePaths.
+func isFileDescriptorEmpty(fileDescriptor *descriptorpb.FileDescriptorProto, emptyFilePaths map[string]struct{}) bool {
+	if len(fileDescriptor.GetMessageType()) > 0 ||
+		len(fileDescriptor.GetEnumType()) > 0 ||
+		len(fileDescriptor.GetService()) > 0 ||
+		len(fileDescriptor.GetExtension()) > 0 {
+		return false
+	}
+	dependencies := fileDescriptor.GetDependency()
+	for _, index := range fileDescriptor.GetPublicDependency() {
+		if index < 0 || int(index) >= len(dependencies) {
+			continue
+		}
+		if _, ok := emptyFilePaths[dependencies[index]]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+type imageExcludeOptions struct {
+	optionPredicates []*optionPredicate
+}
+
+type optionPredicate struct {
+	optionName string
+	value      string
+}
+
+// imageExcluder removes elements from copies of the files of an image.
+type imageExcluder struct {
+	// fileDescriptors maps file paths to copies of the file descriptors
+	// of the image, which are pruned in place.
+	fileDescriptors map[string]*descriptorpb.FileDescriptorProto
+	// byName maps fully-qualified names of all elements, including fields
+	// and enum values, to their descriptors.
+	byName map[string]proto.Message
+	// nameToFile maps fully-qualified names of all elements to the path of
+	// the file that declares them.
+	nameToFile map[string]string
+	// packages is the set of all packages in the image, including the
+	// parent packages of nested packages.
+	packages map[string]struct{}
+	// excludedNames is the set of fully-qualified names of excluded elements
+	// and packages. Everything nested within an excluded name is excluded too.
+	excludedNames map[string]struct{}
+}
+
+func newImageExcluder(image bufimage.Image) (*imageExcluder, error) {
+	excluder := &imageExcluder{
+		fileDescriptors: make(map[string]*descriptorpb.FileDescriptorProto),
+		byName:          make(map[string]proto.Message),
+		nameToFile:      make(map[string]string),
+		packages:        make(map[string]struct{}),
+		excludedNames:   make(map[string]struct{}),
+	}
+	for _, imageFile := range image.Files() {
+		fileDescriptor, ok := proto.Clone(imageFile.FileDescriptorProto()).(*descriptorpb.FileDescriptorProto)
+		if !ok {
+			return nil, fmt.Errorf("unexpected type when cloning descriptor for %q", imageFile.Path())
+		}
+		excluder.fileDescriptors[imageFile.Path()] = fileDescriptor
+		for pkg := fileDescriptor.GetPackage(); pkg != ""; pkg = parentName(pkg) {
+			excluder.packages[pkg] = struct{}{}
+		}
+		if err := walk.DescriptorProtos(fileDescriptor, func(name protoreflect.FullName, descriptor proto.Message) error {
+			excluder.byName[string(name)] = descriptor
+			excluder.nameToFile[string(name)] = imageFile.Path()
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return excluder, nil
+}
+
+func (e *imageExcluder) addExcludedNames(names []string) error {
+	for _, name := range names {
+		if _, ok := e.byName[name]; !ok {
+			if _, ok := e.packages[name]; !ok {
+				return fmt.Errorf("excluding type %q: %w", name, ErrImageFilterTypeNotFound)
+			}
+		}
+		e.excludedNames[name] = struct{}{}
+	}
+	return nil
+}
+
+func (e *imageExcluder) addExcludedByOption(predicate *optionPredicate) error {
+	extension, ok := e.byName[predicate.optionName].(*descriptorpb.FieldDescriptorProto)
+	if !ok || extension.GetExtendee() == "" {
+		return fmt.Errorf("excluding by option %q: %w", predicate.optionName, ErrImageFilterTypeNotFound)
+	}
+	for _, fileDescriptor := range e.fileDescriptors {
+		if optionsMatch(fileDescriptor.GetOptions(), predicate) {
+			// A match on the file options excludes all top-level elements of the file.
+			prefix := fileDescriptor.GetPackage()
+			if prefix != "" {
+				prefix += "."
+			}
+			for _, descriptor := range fileDescriptor.GetMessageType() {
+				e.excludedNames[prefix+descriptor.GetName()] = struct{}{}
+			}
+			for _, descriptor := range fileDescriptor.GetEnumType() {
+				e.excludedNames[prefix+descriptor.GetName()] = struct{}{}
+			}
+			for _, descriptor := range fileDescriptor.GetService() {
+				e.excludedNames[prefix+descriptor.GetName()] = struct{}{}
+			}
+			for _, descriptor := range fileDescriptor.GetExtension() {
+				e.excludedNames[prefix+descriptor.GetName()] = struct{}{}
+			}
+		}
+	}
+	for name, descriptor := range e.byName {
+		if optionsMatch(getOptions(descriptor), predicate) {
+			e.excludedNames[name] = struct{}{}
+		}
+	}
+	return nil
+}
+
+// addExcludedMapEntries excludes the synthetic map entry messages of map fields
+// that are excluded, or whose value type is excluded, so that the entries are
+// removed together with their fields.
+func (e *imageExcluder) addExcludedMapEntries() {
+	for name, descriptor := range e.byName {
+		field, ok := descriptor.(*descriptorpb.FieldDescriptorProto)
+		if !ok || field.GetTypeName() == "" {
+			continue
+		}
+		entryName := strings.TrimPrefix(field.GetTypeName(), ".")
+		entry, ok := e.byName[entryName].(*descriptorpb.DescriptorProto)
+		if !ok || !entry.GetOptions().GetMapEntry() {
+			continue
+		}
+		if e.isFieldExcluded(name, field) {
+			e.excludedNames[entryName] = struct{}{}
+			continue
+		}
+		for _, entryField := range entry.GetField() {
+			if entryField.GetTypeName() != "" && e.isExcluded(entryField.GetTypeName()) {
+				e.excludedNames[entryName] = struct{}{}
+			}
+		}
+	}
+}
+
+// isExcluded returns true if the element with the given fully-qualified name,
+// or any of its enclosing elements or packages, is excluded.
+func (e *imageExcluder) isExcluded(name string) bool {
+	for name = strings.TrimPrefix(name, "."); name != ""; name = parentName(name) {
+		if _, ok := e.excludedNames[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isFieldExcluded returns true if the given field or extension is excluded, or if
+// the field refers to an excluded type.
+func (e *imageExcluder) isFieldExcluded(name string, field *descriptorpb.FieldDescriptorProto) bool {
+	if e.isExcluded(name) {
+		return true
+	}
+	if field.GetTypeName() != "" && e.isExcluded(field.GetTypeName()) {
+		return true
+	}
+	return field.GetExtendee() != "" && e.isExcluded(field.GetExtendee())
+}
+
+func (e *imageExcluder) excludeFromImageFile(imageFile bufimage.ImageFile) (bufimage.ImageFile, error) {
+	fileDescriptor := e.fileDescriptors[imageFile.Path()]
+	requiredBefore := e.requiredFiles(fileDescriptor)
+
+	var sourcePathRemapper *sourcePathsRemapTrie
+	if len(fileDescriptor.SourceCodeInfo.GetLocation()) > 0 {
+		sourcePathRemapper = &sourcePathsRemapTrie{}
+	}
+	prefix := fileDescriptor.GetPackage()
+	if prefix != "" {
+		prefix += "."
+	}
+	e.clearExcludedOptions(fileDescriptor.GetOptions())
+	var err error
+	fileDescriptor.MessageType, err = e.excludeFromMessages(fileDescriptor, prefix, fileDescriptor.MessageType, sourcePathRemapper, []int32{fileMessagesTag})
+	if err != nil {
+		return nil, err
+	}
+	fileDescriptor.EnumType, err = e.excludeFromEnums(fileDescriptor, prefix, fileDescriptor.EnumType, sourcePathRemapper, []int32{fileEnumsTag})
+	if err != nil {
+		return nil, err
+	}
+	fileDescriptor.Extension = e.excludeFromFields(prefix, fileDescriptor.Extension, sourcePathRemapper, []int32{fileExtensionsTag})
+	if len(fileDescriptor.Extension) == 0 {
+		sourcePathRemapper.markDeleted([]int32{fileExtensionsTag})
+	}
+	for index, serviceDescriptor := range fileDescriptor.Service {
+		serviceName := prefix + serviceDescriptor.GetName()
+		methodsPath := []int32{fileServicesTag, int32(index), serviceMethodsTag}
+		serviceDescriptor.Method = filterSlice(serviceDescriptor.Method, func(methodDescriptor *descriptorpb.MethodDescriptorProto) bool {
+			return !e.isExcluded(serviceName+"."+methodDescriptor.GetName()) &&
+				!e.isExcluded(methodDescriptor.GetInputType()) &&
+				!e.isExcluded(methodDescriptor.GetOutputType())
+		}, sourcePathRemapper, methodsPath)
+		e.clearExcludedOptions(serviceDescriptor.GetOptions())
+		for _, methodDescriptor := range serviceDescriptor.Method {
+			e.clearExcludedOptions(methodDescriptor.GetOptions())
+		}
+	}
+	fileDescriptor.Service = filterSlice(fileDescriptor.Service, func(serviceDescriptor *descriptorpb.ServiceDescriptorProto) bool {
+		return !e.isExcluded(prefix + serviceDescriptor.GetName())
+	}, sourcePathRemapper, []int32{fileServicesTag})
+
+	// Remove the imports that were only needed for the excluded elements. Public
+	// imports are kept, as other files may depend on the re-exported types.
+	requiredAfter := e.requiredFiles(fileDescriptor)
+	isPublic := make(map[int32]struct{}, len(fileDescriptor.PublicDependency))
+	for _, index := range fileDescriptor.PublicDependency {
+		isPublic[index] = struct{}{}
+	}
+	unusedDependencyIndexes := filterDependencies(fileDescriptor, func(index int32, importPath string) bool {
+		_, public := isPublic[index]
+		_, wasRequired := requiredBefore[importPath]
+		_, isRequired := requiredAfter[importPath]
+		return public || !wasRequired || isRequired
+	}, imageFile.UnusedDependencyIndexes(), sourcePathRemapper)
+	remapSourceCodeInfo(fileDescriptor, sourcePathRemapper)
+	return bufimage.NewImageFile(
+		fileDescriptor,
+		imageFile.FullName(),
+		imageFile.CommitID(),
+		imageFile.ExternalPath(),
+		imageFile.LocalPath(),
+		imageFile.IsImport(),
+		imageFile.IsSyntaxUnspecified(),
+		unusedDependencyIndexes,
+	)
+}
+
+func (e *imageExcluder) excludeFromMessages(
+	fileDescriptor *descriptorpb.FileDescriptorProto,
+	prefix string,
+	in []*descriptorpb.DescriptorProto,
+	sourcePathRemapper *sourcePathsRemapTrie,
+	pathSoFar []int32,
+) ([]*descriptorpb.DescriptorProto, error) {
+	// We must iterate through the messages *before* we filter the slice. That way the
+	// index we see is for the "old path", which we need to know to mark elements as
+	// moved or deleted with the sourcePathRemapper.
+	for index, messageDescriptor := range in {
+		messageName := prefix + messageDescriptor.GetName()
+		if e.isExcluded(messageName) {
+			continue
+		}
+		path := append(pathSoFar, int32(index))
+		messagePrefix := messageName + "."
+		var err error
+		messageDescriptor.NestedType, err = e.excludeFromMessages(fileDescriptor, messagePrefix, messageDescriptor.NestedType, sourcePathRemapper, append(path, messageNestedMessagesTag))
+		if err != nil {
+			return nil, err
+		}
+		messageDescriptor.EnumType, err = e.excludeFromEnums(fileDescriptor, messagePrefix, messageDescriptor.EnumType, sourcePathRemapper, append(path, messageEnumsTag))
+		if err != nil {
+			return nil, err
+		}
+		extsPath := append(path, messageExtensionsTag)
+		messageDescriptor.Extension = e.excludeFromFields(messagePrefix, messageDescriptor.Extension, sourcePathRemapper, extsPath)
+		if len(messageDescriptor.Extension) == 0 {
+			sourcePathRemapper.markDeleted(extsPath)
+		}
+		messageDescriptor.Field = e.excludeFromFields(messagePrefix, messageDescriptor.Field, sourcePathRemapper, append(path, messageFieldsTag))
+		// Remove the oneofs that no longer have any fields, and renumber the
+		// oneof indexes of the remaining fields.
+		oneofFieldCounts := make(map[int32]int)
+		for _, fieldDescriptor := range messageDescriptor.Field {
+			if fieldDescriptor.OneofIndex != nil {
+				oneofFieldCounts[fieldDescriptor.GetOneofIndex()]++
+			}
+		}
+		oneofIndexFromTo := make(map[int32]int32)
+		oneofsPath := append(path, messageOneofsTag)
+		i := 0
+		for indexFrom, oneofDescriptor := range messageDescriptor.OneofDecl {
+			oneofPath := append(oneofsPath, int32(indexFrom))
+			if oneofFieldCounts[int32(indexFrom)] == 0 {
+				sourcePathRemapper.markDeleted(oneofPath)
+				continue
+			}
+			sourcePathRemapper.markMoved(oneofPath, int32(i))
+			oneofIndexFromTo[int32(indexFrom)] = int32(i)
+			messageDescriptor.OneofDecl[i] = oneofDescriptor
+			e.clearExcludedOptions(oneofDescriptor.GetOptions())
+			i++
+		}
+		messageDescriptor.OneofDecl = messageDescriptor.OneofDecl[:i]
+		for _, fieldDescriptor := range messageDescriptor.Field {
+			if fieldDescriptor.OneofIndex != nil {
+				fieldDescriptor.OneofIndex = proto.Int32(oneofIndexFromTo[fieldDescriptor.GetOneofIndex()])
+			}
+		}
+		e.clearExcludedOptions(messageDescriptor.GetOptions())
+		for _, extensionRange := range messageDescriptor.ExtensionRange {
+			e.clearExcludedOptions(extensionRange.GetOptions())
+		}
+	}
+	return filterSlice(in, func(messageDescriptor *descriptorpb.DescriptorProto) bool {
+		return !e.isExcluded(prefix + messageDescriptor.GetName())
+	}, sourcePathRemapper, pathSoFar), nil
+}
+
+func (e *imageExcluder) excludeFromEnums(
+	fileDescriptor *descriptorpb.FileDescriptorProto,
+	prefix string,
+	in []*descriptorpb.EnumDescriptorProto,
+	sourcePathRemapper *sourcePathsRemapTrie,
+	pathSoFar []int32,
+) ([]*descriptorpb.EnumDescriptorProto, error) {
+	for index, enumDescriptor := range in {
+		enumName := prefix + enumDescriptor.GetName()
+		if e.isExcluded(enumName) {
+			continue
+		}
+		// Enum values are scoped to the enclosing element of the enum, not the enum itself.
+		enumDescriptor.Value = filterSlice(enumDescriptor.Value, func(enumValueDescriptor *descriptorpb.EnumValueDescriptorProto) bool {
+			return !e.isExcluded(prefix + enumValueDescriptor.GetName())
+		}, sourcePathRemapper, append(pathSoFar, int32(index), enumValuesTag))
+		if len(enumDescriptor.Value) == 0 {
+			return nil, fmt.Errorf("excluding types: all values of enum %q were excluded", enumName)
+		}
+		if isOpenEnum(fileDescriptor, enumDescriptor) && enumDescriptor.Value[0].GetNumber() != 0 {
+			return nil, fmt.Errorf("excluding types: the first value of open enum %q must be zero but %q was excluded", enumName, prefix+enumDescriptor.Value[0].GetName())
+		}
+		e.clearExcludedOptions(enumDescriptor.GetOptions())
+		for _, enumValueDescriptor := range enumDescriptor.Value {
+			e.clearExcludedOptions(enumValueDescriptor.GetOptions())
+		}
+	}
+	return filterSlice(in, func(enumDescriptor *descriptorpb.EnumDescriptorProto) bool {
+		return !e.isExcluded(prefix + enumDescriptor.GetName())
+	}, sourcePathRemapper, pathSoFar), nil
+}
+
+func (e *imageExcluder) excludeFromFields(
+	prefix string,
+	in []*descriptorpb.FieldDescriptorProto,
+	sourcePathRemapper *sourcePathsRemapTrie,
+	pathSoFar []int32,
+) []*descriptorpb.FieldDescriptorProto {
+	out := filterSlice(in, func(fieldDescriptor *descriptorpb.FieldDescriptorProto) bool {
+		return !e.isFieldExcluded(prefix+fieldDescriptor.GetName(), fieldDescriptor)
+	}, sourcePathRemapper, pathSoFar)
+	for _, fieldDescriptor := range out {
+		e.clearExcludedOptions(fieldDescriptor.GetOptions())
+	}
+	return out
+}
+
+// clearExcludedOptions clears the custom options whose extensions are excluded.
+func (e *imageExcluder) clearExcludedOptions(options proto.Message) {
+	if options == nil || !options.ProtoReflect().IsValid() {
+		return
+	}
+	optionsMessage := options.ProtoReflect()
+	var toClear []protoreflect.FieldDescriptor
+	optionsMessage.Range(func(fieldDescriptor protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if !fieldDescriptor.IsExtension() {
+			return true
+		}
+		name := string(fieldDescriptor.FullName())
+		extension, ok := e.byName[name].(*descriptorpb.FieldDescriptorProto)
+		if ok && e.isFieldExcluded(name, extension) {
+			toClear = append(toClear, fieldDescriptor)
+		}
+		return true
+	})
+	for _, fieldDescriptor := range toClear {
+		optionsMessage.Clear(fieldDescriptor)
+	}
+}
+
+// requiredFiles returns the set of paths of the other files that declare the
+// elements referenced by the given file.
+func (e *imageExcluder) requiredFiles(fileDescriptor *descriptorpb.FileDescriptorProto) map[string]struct{} {
+	requiredFiles := make(map[string]struct{})
+	addName := func(name string) {
+		if name == "" {
+			return
+		}
+		if path, ok := e.nameToFile[strings.TrimPrefix(name, ".")]; ok && path != fileDescriptor.GetName() {
+			requiredFiles[path] = struct{}{}
+		}
+	}
+	addOptions := func(options proto.Message) {
+		if options == nil || !options.ProtoReflect().IsValid() {
+			return
+		}
+		options.ProtoReflect().Range(func(fieldDescriptor protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+			if fieldDescriptor.IsExtension() {
+				addName(string(fieldDescriptor.FullName()))
+			}
+			return true
+		})
+	}
+	addOptions(fileDescriptor.GetOptions())
+	_ = walk.DescriptorProtos(fileDescriptor, func(_ protoreflect.FullName, descriptor proto.Message) error {
+		switch descriptor := descriptor.(type) {
+		case *descriptorpb.DescriptorProto:
+			for _, extensionRange := range descriptor.GetExtensionRange() {
+				addOptions(extensionRange.GetOptions())
+			}
+		case *descriptorpb.FieldDescriptorProto:
+			addName(descriptor.GetTypeName())
+			addName(descriptor.GetExtendee())
+		case *descriptorpb.MethodDescriptorProto:
+			addName(descriptor.GetInputType())
+			addName(descriptor.GetOutputType())
+		}
+		addOptions(getOptions(descriptor))
+		return nil
+	})
+	return requiredFiles
+}
+
+// filterSlice removes the elements from a slice for which keep returns false,
+// marking the elements as moved or deleted in the sourcePathRemapper.
+func filterSlice[T any](
+	in []T,
+	keep func(T) bool,
+	sourcePathRemapper *sourcePathsRemapTrie,
+	pathSoFar []int32,
+) []T {
+	i := 0
+	for index, element := range in {
+		path := append(pathSoFar, int32(index))
+		if keep(element) {
+			sourcePathRemapper.markMoved(path, int32(i))
+			in[i] = element
+			i++
+		} else {
+			sourcePathRemapper.markDeleted(path)
+		}
+	}
+	return in[:i]
+}
+
+// filterDependencies removes the dependencies of the file for which keep returns false,
+// and remaps the public and weak dependencies of the file. It returns the remapped
+// indexes of the given unused dependencies.
+func filterDependencies(
+	fileDescriptor *descriptorpb.FileDescriptorProto,
+	keep func(index int32, importPath string) bool,
+	unusedDependencyIndexes []int32,
+	sourcePathRemapper *sourcePathsRemapTrie,
+) []int32 {
+	indexFromTo := make(map[int32]int32)
+	indexTo := 0
+	for indexFrom, importPath := range fileDescriptor.GetDependency() {
+		path := []int32{fileDependencyTag, int32(indexFrom)}
+		if !keep(int32(indexFrom), importPath) {
+			sourcePathRemapper.markDeleted(path)
+			continue
+		}
+		sourcePathRemapper.markMoved(path, int32(indexTo))
+		indexFromTo[int32(indexFrom)] = int32(indexTo)
+		fileDescriptor.Dependency[indexTo] = importPath
+		indexTo++
+	}
+	fileDescriptor.Dependency = fileDescriptor.Dependency[:indexTo]
+	fileDescriptor.PublicDependency = remapDependencyIndexes(fileDescriptor.PublicDependency, indexFromTo, sourcePathRemapper, filePublicDependencyTag)
+	fileDescriptor.WeakDependency = remapDependencyIndexes(fileDescriptor.WeakDependency, indexFromTo, sourcePathRemapper, fileWeakDependencyTag)
+	var newUnusedDependencyIndexes []int32
+	for _, indexFrom := range unusedDependencyIndexes {
+		if indexTo, ok := indexFromTo[indexFrom]; ok {
+			newUnusedDependencyIndexes = append(newUnusedDependencyIndexes, indexTo)
+		}
+	}
+	return newUnusedDependencyIndexes
+}
+
+// remapSourceCodeInfo rewrites the source code info of the file for the moves
+// and deletions recorded in the sourcePathRemapper.
+func remapSourceCodeInfo(fileDescriptor *descriptorpb.FileDescriptorProto, sourcePathRemapper *sourcePathsRemapTrie) {
+	if sourcePathRemapper == nil {
+		return
+	}
+	i := 0
+	for _, location := range fileDescriptor.SourceCodeInfo.Location {
+		// This function returns newPath==nil if the element at the given path
+		// was marked for deletion (so this location should be omitted).
+		newPath, noComment := sourcePathRemapper.newPath(location.Path)
+		if newPath != nil {
+			fileDescriptor.SourceCodeInfo.Location[i] = location
+			location.Path = newPath
+			if noComment {
+				location.LeadingDetachedComments = nil
+				location.LeadingComments = nil
+				location.TrailingComments = nil
+			}
+			i++
+		}
+	}
+	fileDescriptor.SourceCodeInfo.Location = fileDescriptor.SourceCodeInfo.Location[:i]
+}
+
+// remapDependencyIndexes remaps the indexes of a file's public or weak dependencies
+// after the dependencies were filtered.
+func remapDependencyIndexes(
+	indexes []int32,
+	indexFromTo map[int32]int32,
+	sourcePathRemapper *sourcePathsRemapTrie,
+	tag int32,
+) []int32 {
+	i := 0
+	for _, indexFrom := range indexes {
+		path := []int32{tag, indexFrom}
+		if indexTo, ok := indexFromTo[indexFrom]; ok {
+			sourcePathRemapper.markMoved(path, indexTo)
+			indexes[i] = indexTo
+			i++
+		} else {
+			sourcePathRemapper.markDeleted(path)
+		}
+	}
+	return indexes[:i]
+}
+
+// getOptions returns the options of the given descriptor, or nil if the
+// descriptor has no options.
+func getOptions(descriptor proto.Message) proto.Message {
+	switch descriptor := descriptor.(type) {
+	case *descriptorpb.FileDescriptorProto:
+		return descriptor.GetOptions()
+	case *descriptorpb.DescriptorProto:
+		return descriptor.GetOptions()
+	case *descriptorpb.FieldDescriptorProto:
+		return descriptor.GetOptions()
+	case *descriptorpb.OneofDescriptorProto:
+		return descriptor.GetOptions()
+	case *descriptorpb.EnumDescriptorProto:
+		return descriptor.GetOptions()
+	case *descriptorpb.EnumValueDescriptorProto:
+		return descriptor.GetOptions()
+	case *descriptorpb.ServiceDescriptorProto:
+		return descriptor.GetOptions()
+	case *descriptorpb.MethodDescriptorProto:
+		return descriptor.GetOptions()
+	default:
+		return nil
+	}
+}
+
+// optionsMatch returns true if the given options message has the option of the
+// predicate set to the value of the predicate.
+func optionsMatch(options proto.Message, predicate *optionPredicate) bool {
+	if options == nil || !options.ProtoReflect().IsValid() {
+		return false
+	}
+	var matches bool
+	options.ProtoReflect().Range(func(fieldDescriptor protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		if !fieldDescriptor.IsExtension() || string(fieldDescriptor.FullName()) != predicate.optionName {
+			return true
+		}
+		switch {
+		case fieldDescriptor.IsMap():
+			matches = predicate.value == ""
+		case fieldDescriptor.IsList():
+			list := value.List()
+			for i := 0; i < list.Len() && !matches; i++ {
+				matches = optionValueMatches(fieldDescriptor, list.Get(i), predicate.value)
+			}
+		default:
+			matches = optionValueMatches(fieldDescriptor, value, predicate.value)
+		}
+		return false
+	})
+	return matches
+}
+
+func optionValueMatches(fieldDescriptor protoreflect.FieldDescriptor, value protoreflect.Value, want string) bool {
+	if want == "" {
+		return fieldDescriptor.Kind() != protoreflect.BoolKind || value.Bool()
+	}
+	switch fieldDescriptor.Kind() {
+	case protoreflect.EnumKind:
+		if enumValue := fieldDescriptor.Enum().Values().ByNumber(value.Enum()); enumValue != nil && string(enumValue.Name()) == want {
+			return true
+		}
+		return strconv.FormatInt(int64(value.Enum()), 10) == want
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return false
+	case protoreflect.BytesKind:
+		return string(value.Bytes()) == want
+	default:
+		return value.String() == want
+	}
+}
+
+// isOpenEnum returns true if the given enum is open, in which case its
+// first value must be zero.
+func isOpenEnum(fileDescriptor *descriptorpb.FileDescriptorProto, enumDescriptor *descriptorpb.EnumDescriptorProto) bool {
+	switch fileDescriptor.GetSyntax() {
+	case "proto3":
+		return true
+	case "editions":
+		if enumType := enumDescriptor.GetOptions().GetFeatures().EnumType; enumType != nil {
+			return *enumType == descriptorpb.FeatureSet_OPEN
+		}
+		if enumType := fileDescriptor.GetOptions().GetFeatures().EnumType; enumType != nil {
+			return *enumType == descriptorpb.FeatureSet_OPEN
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+func parentName(name string) string {
+	if index := strings.LastIndexByte(name, '.'); index >= 0 {
+		return name[:index]
+	}
+	return ""
+}
@@ -0,0 +1,283 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimageutil
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const (
+	// NumbersKindMessage is the kind for the Numbers of a message.
+	NumbersKindMessage = "message"
+	// NumbersKindEnum is the kind for the Numbers of an enum.
+	NumbersKindEnum = "enum"
+
+	enumRangeInclusiveMax = math.MaxInt32
+)
+
+// The field numbers reserved for the Protocol Buffers implementation, which
+// are never free.
+var implementationReservedRange = NumberRange{Start: 19000, End: 19999}
+
+// NumberRange is an inclusive range of numbers.
+type NumberRange struct {
+	Start int32 `json:"start" yaml:"start"`
+	End   int32 `json:"end" yaml:"end"`
+}
+
+// StringForKind returns the range as it would be written in a reserved statement of
+// a message or enum of the kind, such as "5", "5 to 10", or "5 to max".
+//
+// The kind is either NumbersKindMessage or NumbersKindEnum, and determines the
+// maximum number that is written as "max".
+func (r NumberRange) StringForKind(kind string) string {
+	switch {
+	case r.Start == r.End:
+		return strconv.Itoa(int(r.Start))
+	case kind == NumbersKindMessage && r.End == messageRangeInclusiveMax,
+		kind == NumbersKindEnum && r.End == enumRangeInclusiveMax:
+		return fmt.Sprintf("%d to max", r.Start)
+	default:
+		return fmt.Sprintf("%d to %d", r.Start, r.End)
+	}
+}
+
+// Numbers are the numbers used, reserved, and free within a message or enum.
+type Numbers struct {
+	// Name is the fully-qualified name of the message or enum.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Kind is either NumbersKindMessage or NumbersKindEnum.
+	Kind string `json:"kind,omitempty" yaml:"kind,omitempty"`
+	// Path is the path of the file the message or enum is defined in.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+	// Used are the numbers of the fields of a message, or the values of an enum, sorted.
+	Used []int32 `json:"used,omitempty" yaml:"used,omitempty"`
+	// Reserved are the reserved ranges, sorted.
+	Reserved []NumberRange `json:"reserved,omitempty" yaml:"reserved,omitempty"`
+	// ReservedNames are the reserved names.
+	ReservedNames []string `json:"reserved_names,omitempty" yaml:"reserved_names,omitempty"`
+	// Extensions are the extension ranges of a message, sorted.
+	Extensions []NumberRange `json:"extensions,omitempty" yaml:"extensions,omitempty"`
+	// Free are the ranges of numbers that are not used, reserved, or part of
+	// an extension range.
+	//
+	// For messages, this never includes the numbers 19000 to 19999, which are
+	// reserved for the Protocol Buffers implementation. For enums, this only
+	// includes non-negative numbers.
+	Free []NumberRange `json:"free,omitempty" yaml:"free,omitempty"`
+	// Next is the next safe number to use.
+	//
+	// This is the smallest free number greater than every used, reserved, and
+	// extension number, so that numbers of deleted fields or values that were
+	// not reserved are never reused. If there is no such number, this is the
+	// smallest free number. If there are no free numbers, this is nil.
+	Next *int32 `json:"next,omitempty" yaml:"next,omitempty"`
+}
+
+// NumbersForFiles gets the Numbers of all messages and enums in the given files,
+// including nested messages and enums.
+//
+// Messages and enums are returned in the order they are declared, with nested
+// messages and enums following their parent message.
+func NumbersForFiles(
+	filePaths []string,
+	image bufimage.Image,
+) ([]*Numbers, error) {
+	var numbers []*Numbers
+	for _, filePath := range filePaths {
+		imageFile := image.GetFile(filePath)
+		if imageFile == nil {
+			return nil, fmt.Errorf("unexpected nil image file: %q", filePath)
+		}
+		fileDescriptorProto := imageFile.FileDescriptorProto()
+		prefix := fileDescriptorProto.GetPackage()
+		if prefix != "" {
+			prefix += "."
+		}
+		for _, message := range fileDescriptorProto.GetMessageType() {
+			numbers = messageNumbersRec(numbers, filePath, prefix+message.GetName(), message)
+		}
+		for _, enum := range fileDescriptorProto.GetEnumType() {
+			numbers = append(numbers, enumNumbers(filePath, prefix+enum.GetName(), enum))
+		}
+	}
+	return numbers, nil
+}
+
+// *** PRIVATE ***
+
+func messageNumbersRec(
+	numbers []*Numbers,
+	filePath string,
+	fullName string,
+	message *descriptorpb.DescriptorProto,
+) []*Numbers {
+	used := make([]int32, 0, len(message.GetField()))
+	for _, field := range message.GetField() {
+		used = append(used, field.GetNumber())
+	}
+	slices.Sort(used)
+	reserved := make([]NumberRange, 0, len(message.GetReservedRange()))
+	for _, reservedRange := range message.GetReservedRange() {
+		// we subtract one because ranges in the proto have exclusive end
+		reserved = append(reserved, NumberRange{Start: reservedRange.GetStart(), End: reservedRange.GetEnd() - 1})
+	}
+	sortNumberRanges(reserved)
+	extensions := make([]NumberRange, 0, len(message.GetExtensionRange()))
+	for _, extensionRange := range message.GetExtensionRange() {
+		// we subtract one because ranges in the proto have exclusive end
+		extensions = append(extensions, NumberRange{Start: extensionRange.GetStart(), End: extensionRange.GetEnd() - 1})
+	}
+	sortNumberRanges(extensions)
+	var free []NumberRange
+	for _, freeRange := range freeMessageRanges(message) {
+		free = append(free, subtractNumberRange(NumberRange{Start: freeRange.start, End: freeRange.end}, implementationReservedRange)...)
+	}
+	numbers = append(
+		numbers,
+		&Numbers{
+			Name:          fullName,
+			Kind:          NumbersKindMessage,
+			Path:          filePath,
+			Used:          used,
+			Reserved:      reserved,
+			ReservedNames: message.GetReservedName(),
+			Extensions:    extensions,
+			Free:          free,
+			Next:          nextNumber(free, used, reserved, extensions),
+		},
+	)
+	for _, nestedMessage := range message.GetNestedType() {
+		numbers = messageNumbersRec(numbers, filePath, fullName+"."+nestedMessage.GetName(), nestedMessage)
+	}
+	for _, nestedEnum := range message.GetEnumType() {
+		numbers = append(numbers, enumNumbers(filePath, fullName+"."+nestedEnum.GetName(), nestedEnum))
+	}
+	return numbers
+}
+
+func enumNumbers(
+	filePath string,
+	fullName string,
+	enum *descriptorpb.EnumDescriptorProto,
+) *Numbers {
+	used := make([]int32, 0, len(enum.GetValue()))
+	for _, value := range enum.GetValue() {
+		// Aliases may use the same number multiple times.
+		if !slices.Contains(used, value.GetNumber()) {
+			used = append(used, value.GetNumber())
+		}
+	}
+	slices.Sort(used)
+	reserved := make([]NumberRange, 0, len(enum.GetReservedRange()))
+	for _, reservedRange := range enum.GetReservedRange() {
+		// enum reserved ranges have inclusive ends
+		reserved = append(reserved, NumberRange{Start: reservedRange.GetStart(), End: reservedRange.GetEnd()})
+	}
+	sortNumberRanges(reserved)
+	allUsed := make([]NumberRange, 0, len(used)+len(reserved))
+	for _, number := range used {
+		allUsed = append(allUsed, NumberRange{Start: number, End: number})
+	}
+	allUsed = append(allUsed, reserved...)
+	free := freeNumberRanges(allUsed, 0, enumRangeInclusiveMax)
+	return &Numbers{
+		Name:          fullName,
+		Kind:          NumbersKindEnum,
+		Path:          filePath,
+		Used:          used,
+		Reserved:      reserved,
+		ReservedNames: enum.GetReservedName(),
+		Free:          free,
+		Next:          nextNumber(free, used, reserved, nil),
+	}
+}
+
+// freeNumberRanges returns the ranges between minNumber and maxNumber inclusive that
+// are not in any of the used ranges.
+func freeNumberRanges(used []NumberRange, minNumber int32, maxNumber int32) []NumberRange {
+	sortNumberRanges(used)
+	var free []NumberRange
+	// int64 so that we do not overflow when maxNumber is math.MaxInt32.
+	next := int64(minNumber)
+	for _, r := range used {
+		if int64(r.End) < next {
+			continue
+		}
+		if int64(r.Start) > next {
+			free = append(free, NumberRange{Start: int32(next), End: r.Start - 1})
+		}
+		next = int64(r.End) + 1
+	}
+	if next <= int64(maxNumber) {
+		free = append(free, NumberRange{Start: int32(next), End: maxNumber})
+	}
+	return free
+}
+
+// subtractNumberRange returns the parts of r that are not in other.
+func subtractNumberRange(r NumberRange, other NumberRange) []NumberRange {
+	if other.End < r.Start || other.Start > r.End {
+		return []NumberRange{r}
+	}
+	var result []NumberRange
+	if r.Start < other.Start {
+		result = append(result, NumberRange{Start: r.Start, End: other.Start - 1})
+	}
+	if r.End > other.End {
+		result = append(result, NumberRange{Start: other.End + 1, End: r.End})
+	}
+	return result
+}
+
+// nextNumber returns the smallest free number greater than all used, reserved, and
+// extension numbers, falling back to the smallest free number.
+//
+// Returns nil if there are no free numbers.
+func nextNumber(free []NumberRange, used []int32, reserved []NumberRange, extensions []NumberRange) *int32 {
+	if len(free) == 0 {
+		return nil
+	}
+	var highest int32 = math.MinInt32
+	for _, number := range used {
+		highest = max(highest, number)
+	}
+	for _, r := range reserved {
+		highest = max(highest, r.End)
+	}
+	for _, r := range extensions {
+		highest = max(highest, r.End)
+	}
+	for _, r := range free {
+		if r.End > highest {
+			return proto.Int32(max(r.Start, highest+1))
+		}
+	}
+	return proto.Int32(free[0].Start)
+}
+
+func sortNumberRanges(ranges []NumberRange) {
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].Start < ranges[j].Start
+	})
+}
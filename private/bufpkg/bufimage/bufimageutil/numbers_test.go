@@ -0,0 +1,156 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufimageutil
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduletesting"
+	"github.com/bufbuild/buf/private/pkg/slogtestext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestNumbersForFiles(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	moduleSet, err := bufmoduletesting.NewModuleSetForPathToData(
+		map[string][]byte{
+			"a.proto": []byte(`syntax = "proto2";
+package a;
+message Foo {
+  optional string one = 1;
+  optional string three = 3;
+  reserved 5 to 7, 50;
+  reserved "six";
+  extensions 100 to max;
+  message Bar {
+    optional string two = 2;
+  }
+  enum Baz {
+    BAZ_ZERO = 0;
+    BAZ_ONE = 1;
+    reserved 3 to 4;
+  }
+}
+message Empty {}
+enum Alias {
+  option allow_alias = true;
+  ALIAS_ZERO = 0;
+  ALIAS_ALSO_ZERO = 0;
+  ALIAS_NEGATIVE = -1;
+}
+enum Negative {
+  NEGATIVE_MIN = -2147483648;
+  NEGATIVE_ONE = -1;
+  reserved 0 to max;
+}
+`),
+		},
+	)
+	require.NoError(t, err)
+	image, err := bufimage.BuildImage(
+		ctx,
+		slogtestext.NewLogger(t),
+		bufmodule.ModuleSetToModuleReadBucketWithOnlyProtoFiles(moduleSet),
+		bufimage.WithExcludeSourceCodeInfo(),
+	)
+	require.NoError(t, err)
+	numbers, err := NumbersForFiles([]string{"a.proto"}, image)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		[]*Numbers{
+			{
+				Name:          "a.Foo",
+				Kind:          NumbersKindMessage,
+				Path:          "a.proto",
+				Used:          []int32{1, 3},
+				Reserved:      []NumberRange{{Start: 5, End: 7}, {Start: 50, End: 50}},
+				ReservedNames: []string{"six"},
+				Extensions:    []NumberRange{{Start: 100, End: messageRangeInclusiveMax}},
+				Free:          []NumberRange{{Start: 2, End: 2}, {Start: 4, End: 4}, {Start: 8, End: 49}, {Start: 51, End: 99}},
+				// The extension range extends to max, so the lowest free number is used.
+				Next: proto.Int32(2),
+			},
+			{
+				Name:     "a.Foo.Bar",
+				Kind:     NumbersKindMessage,
+				Path:     "a.proto",
+				Used:     []int32{2},
+				Reserved: []NumberRange{},
+				Free: []NumberRange{
+					{Start: 1, End: 1},
+					{Start: 3, End: 18999},
+					{Start: 20000, End: messageRangeInclusiveMax},
+				},
+				Extensions: []NumberRange{},
+				Next:       proto.Int32(3),
+			},
+			{
+				Name:     "a.Foo.Baz",
+				Kind:     NumbersKindEnum,
+				Path:     "a.proto",
+				Used:     []int32{0, 1},
+				Reserved: []NumberRange{{Start: 3, End: 4}},
+				Free:     []NumberRange{{Start: 2, End: 2}, {Start: 5, End: enumRangeInclusiveMax}},
+				Next:     proto.Int32(5),
+			},
+			{
+				Name:     "a.Empty",
+				Kind:     NumbersKindMessage,
+				Path:     "a.proto",
+				Used:     []int32{},
+				Reserved: []NumberRange{},
+				Free: []NumberRange{
+					{Start: 1, End: 18999},
+					{Start: 20000, End: messageRangeInclusiveMax},
+				},
+				Extensions: []NumberRange{},
+				Next:       proto.Int32(1),
+			},
+			{
+				Name:     "a.Alias",
+				Kind:     NumbersKindEnum,
+				Path:     "a.proto",
+				Used:     []int32{-1, 0},
+				Reserved: []NumberRange{},
+				Free:     []NumberRange{{Start: 1, End: enumRangeInclusiveMax}},
+				Next:     proto.Int32(1),
+			},
+			{
+				Name:     "a.Negative",
+				Kind:     NumbersKindEnum,
+				Path:     "a.proto",
+				Used:     []int32{-2147483648, -1},
+				Reserved: []NumberRange{{Start: 0, End: enumRangeInclusiveMax}},
+				// Only non-negative numbers are free, so there is no next number.
+				Free: nil,
+				Next: nil,
+			},
+		},
+		numbers,
+	)
+	assert.Equal(t, "5", NumberRange{Start: 5, End: 5}.StringForKind(NumbersKindMessage))
+	assert.Equal(t, "5 to 7", NumberRange{Start: 5, End: 7}.StringForKind(NumbersKindMessage))
+	assert.Equal(t, "100 to max", NumberRange{Start: 100, End: messageRangeInclusiveMax}.StringForKind(NumbersKindMessage))
+	assert.Equal(t, "100 to 2147483647", NumberRange{Start: 100, End: enumRangeInclusiveMax}.StringForKind(NumbersKindMessage))
+	assert.Equal(t, "100 to max", NumberRange{Start: 100, End: enumRangeInclusiveMax}.StringForKind(NumbersKindEnum))
+	assert.Equal(t, "100 to 536870911", NumberRange{Start: 100, End: messageRangeInclusiveMax}.StringForKind(NumbersKindEnum))
+}
@@ -104,45 +104,12 @@ func (a *uploader) Upload(
 		return nil, err
 	}
 
-	contentModules, err := bufmodule.ModuleSetTargetLocalModulesAndTransitiveLocalDeps(moduleSet)
+	contentModules, excludedModules, err := bufmodule.UploadContentModules(moduleSet, uploadOptions)
 	if err != nil {
 		return nil, err
 	}
-	// Only push named modules to the registry. Any dependencies for named modules must have a name.
-	// Local unnamed modules can be excluded if the UploadWithExcludeUnnamed option is set.
-	contentModules, err = slicesext.FilterError(contentModules, func(module bufmodule.Module) (bool, error) {
-		moduleName := module.FullName()
-		if moduleName == nil {
-			moduleDescription := module.Description()
-			if uploadOptions.ExcludeUnnamed() {
-				a.logger.Warn("Excluding unnamed module", slog.String("module", moduleDescription))
-				return false, nil
-			}
-			return false, fmt.Errorf("a name must be specified in buf.yaml to push module: %s", moduleDescription)
-		}
-		deps, err := module.ModuleDeps()
-		if err != nil {
-			return false, err
-		}
-		if allDepModuleDescriptions := slicesext.Reduce(deps, func(allDepModuleDescriptions []string, dep bufmodule.ModuleDep) []string {
-			if moduleName := dep.FullName(); moduleName == nil {
-				return append(allDepModuleDescriptions, dep.Description())
-			}
-			return allDepModuleDescriptions
-		}, nil); len(allDepModuleDescriptions) > 0 {
-			return false, fmt.Errorf(
-				"all dependencies for module %q must be named but these modules are not:\n%s",
-				moduleName.String(),
-				strings.Join(
-					slicesext.Map(allDepModuleDescriptions, func(moduleDescription string) string { return "  " + moduleDescription }),
-					"\n",
-				),
-			)
-		}
-		return true, nil
-	})
-	if err != nil {
-		return nil, err
+	for _, excludedModule := range excludedModules {
+		a.logger.Warn("Excluding unnamed module", slog.String("module", excludedModule.Description()))
 	}
 	if len(contentModules) == 0 {
 		// Nothing to upload.
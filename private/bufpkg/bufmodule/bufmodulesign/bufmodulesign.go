@@ -0,0 +1,24 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bufmodulesign signs and verifies the digests of modules.
+//
+// A signature covers the FullName and b5 Digest of a module, so a signature for
+// one module cannot be reused for another module, or for other content of the same
+// module. Signatures are stored as Bundles in a SignatureStore.
+//
+// The BSR does not store signatures, so signatures are not distributed with the module data.
+// Signers and verifiers must share a SignatureStore, for example a directory that is checked
+// into a repository, or a remote cache.
+package bufmodulesign
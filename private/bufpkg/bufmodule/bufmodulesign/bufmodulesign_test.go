@@ -0,0 +1,229 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodulesign_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufcas"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmodulesign"
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule/bufmoduletesting"
+	"github.com/bufbuild/buf/private/bufpkg/bufparse"
+	"github.com/bufbuild/buf/private/pkg/slogtestext"
+	"github.com/bufbuild/buf/private/pkg/storage/storagemem"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyModules(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	// This acts as the registry that the modules are pushed to.
+	bsrProvider, err := bufmoduletesting.NewOmniProvider(
+		bufmoduletesting.ModuleData{
+			Name: "buf.build/acme/signed",
+			PathToData: map[string][]byte{
+				"signed.proto": []byte(`syntax = "proto3"; package signed;`),
+			},
+		},
+		bufmoduletesting.ModuleData{
+			Name: "buf.build/acme/unsigned",
+			PathToData: map[string][]byte{
+				"unsigned.proto": []byte(`syntax = "proto3"; package unsigned;`),
+			},
+		},
+		bufmoduletesting.ModuleData{
+			Name: "buf.build/acme/missigned",
+			PathToData: map[string][]byte{
+				"missigned.proto": []byte(`syntax = "proto3"; package missigned;`),
+			},
+		},
+		bufmoduletesting.ModuleData{
+			Name: "buf.build/other/unverified",
+			PathToData: map[string][]byte{
+				"unverified.proto": []byte(`syntax = "proto3"; package unverified;`),
+			},
+		},
+	)
+	require.NoError(t, err)
+	moduleSetBuilder := bufmodule.NewModuleSetBuilder(ctx, slogtestext.NewLogger(t), bsrProvider, bsrProvider)
+	moduleKeys, err := bsrProvider.GetModuleKeysForModuleRefs(
+		ctx,
+		[]bufparse.Ref{
+			testNewRef(t, "acme", "signed"),
+			testNewRef(t, "acme", "unsigned"),
+			testNewRef(t, "acme", "missigned"),
+			testNewRef(t, "other", "unverified"),
+		},
+		bufmodule.DigestTypeB5,
+	)
+	require.NoError(t, err)
+	for _, moduleKey := range moduleKeys {
+		moduleSetBuilder.AddRemoteModule(moduleKey, false)
+	}
+	// Local modules are never required to be signed, even if their owner has trusted keys.
+	localBucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			"local.proto": []byte(`syntax = "proto3"; package local; import "signed.proto";`),
+		},
+	)
+	require.NoError(t, err)
+	moduleSetBuilder.AddLocalModule(
+		localBucket,
+		"local",
+		true,
+		bufmodule.LocalModuleWithFullName(testParseFullName(t, "buf.build/acme/local")),
+	)
+	moduleSet, err := moduleSetBuilder.Build()
+	require.NoError(t, err)
+
+	trustedKey, err := bufmodulesign.NewPrivateKey()
+	require.NoError(t, err)
+	untrustedKey, err := bufmodulesign.NewPrivateKey()
+	require.NoError(t, err)
+	signatureStore := bufmodulesign.NewSignatureStore(slogtestext.NewLogger(t), storagemem.NewReadWriteBucket())
+	sign := func(key bufmodulesign.PrivateKey, moduleFullNameString string) {
+		module := moduleSet.GetModuleForFullName(testParseFullName(t, moduleFullNameString))
+		require.NotNil(t, module)
+		digest, err := module.Digest(bufmodule.DigestTypeB5)
+		require.NoError(t, err)
+		bundle, err := bufmodulesign.NewBundle(key, module.FullName(), digest)
+		require.NoError(t, err)
+		require.NoError(t, signatureStore.PutBundle(ctx, bundle))
+	}
+	sign(trustedKey, "buf.build/acme/signed")
+	sign(untrustedKey, "buf.build/acme/missigned")
+
+	getTrustedPublicKeys := func(moduleFullName bufparse.FullName) []bufmodulesign.PublicKey {
+		if moduleFullName.Owner() == "acme" {
+			return []bufmodulesign.PublicKey{trustedKey.PublicKey()}
+		}
+		return nil
+	}
+	err = bufmodulesign.VerifyModules(ctx, signatureStore, moduleSet.Modules(), getTrustedPublicKeys)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "module buf.build/acme/unsigned is not signed")
+	require.Contains(t, err.Error(), "module buf.build/acme/missigned could not be verified: signature was made with untrusted key "+untrustedKey.PublicKey().String())
+	require.NotContains(t, err.Error(), "buf.build/acme/signed")
+	require.NotContains(t, err.Error(), "buf.build/other/unverified")
+	require.NotContains(t, err.Error(), "buf.build/acme/local")
+
+	sign(trustedKey, "buf.build/acme/unsigned")
+	sign(trustedKey, "buf.build/acme/missigned")
+	require.NoError(t, bufmodulesign.VerifyModules(ctx, signatureStore, moduleSet.Modules(), getTrustedPublicKeys))
+}
+
+func TestBundle(t *testing.T) {
+	t.Parallel()
+
+	privateKey, err := bufmodulesign.NewPrivateKey()
+	require.NoError(t, err)
+	privateKeyData, err := bufmodulesign.MarshalPrivateKeyPEM(privateKey)
+	require.NoError(t, err)
+	privateKey, err = bufmodulesign.ParsePrivateKeyPEM(privateKeyData)
+	require.NoError(t, err)
+	publicKey, err := bufmodulesign.ParsePublicKey(privateKey.PublicKey().String())
+	require.NoError(t, err)
+	require.True(t, bufmodulesign.PublicKeyEqual(privateKey.PublicKey(), publicKey))
+
+	moduleFullName := testParseFullName(t, "buf.build/acme/weather")
+	digest, err := bufmodule.ParseDigest("b5:" + testHexValue("ab"))
+	require.NoError(t, err)
+	otherDigest, err := bufmodule.ParseDigest("b5:" + testHexValue("cd"))
+	require.NoError(t, err)
+	bundle, err := bufmodulesign.NewBundle(privateKey, moduleFullName, digest)
+	require.NoError(t, err)
+	data, err := bufmodulesign.MarshalBundle(bundle)
+	require.NoError(t, err)
+	bundle, err = bufmodulesign.UnmarshalBundle(data)
+	require.NoError(t, err)
+	trustedPublicKeys := []bufmodulesign.PublicKey{publicKey}
+	require.NoError(t, bufmodulesign.VerifyBundle(bundle, moduleFullName, digest, trustedPublicKeys))
+	require.EqualError(
+		t,
+		bufmodulesign.VerifyBundle(bundle, moduleFullName, otherDigest, trustedPublicKeys),
+		"signature is for digest "+digest.String()+", not "+otherDigest.String(),
+	)
+	require.EqualError(
+		t,
+		bufmodulesign.VerifyBundle(bundle, testParseFullName(t, "buf.build/acme/other"), digest, trustedPublicKeys),
+		"signature is for module buf.build/acme/weather, not buf.build/acme/other",
+	)
+
+	// A Bundle that claims to be for another digest does not verify.
+	tamperedBundle, err := bufmodulesign.UnmarshalBundle(
+		[]byte(`{"version":"v1","module":"buf.build/acme/weather","digest":"` + otherDigest.String() +
+			`","public_key":"` + publicKey.String() +
+			`","signature":"` + testBase64Signature(t, bundle) + `"}`),
+	)
+	require.NoError(t, err)
+	require.EqualError(
+		t,
+		bufmodulesign.VerifyBundle(tamperedBundle, moduleFullName, otherDigest, trustedPublicKeys),
+		"signature does not match key "+publicKey.String(),
+	)
+
+	bufcasDigest, err := bufcas.NewDigest(digest.Value())
+	require.NoError(t, err)
+	b4Digest, err := bufmodule.NewDigest(bufmodule.DigestTypeB4, bufcasDigest)
+	require.NoError(t, err)
+	_, err = bufmodulesign.NewBundle(privateKey, moduleFullName, b4Digest)
+	require.Error(t, err)
+}
+
+func TestParsePublicKey(t *testing.T) {
+	t.Parallel()
+
+	_, err := bufmodulesign.ParsePublicKey("ed25519:abc")
+	require.Error(t, err)
+	_, err = bufmodulesign.ParsePublicKey("rsa:AAAA")
+	require.Error(t, err)
+	_, err = bufmodulesign.ParsePublicKey("ed25519:AAAA")
+	require.Error(t, err)
+}
+
+func testNewRef(t *testing.T, owner string, name string) bufparse.Ref {
+	moduleRef, err := bufparse.NewRef("buf.build", owner, name, "")
+	require.NoError(t, err)
+	return moduleRef
+}
+
+func testParseFullName(t *testing.T, moduleFullNameString string) bufparse.FullName {
+	moduleFullName, err := bufparse.ParseFullName(moduleFullNameString)
+	require.NoError(t, err)
+	return moduleFullName
+}
+
+// testHexValue returns a 64-byte hex value made of the repeated byte.
+func testHexValue(byteHex string) string {
+	var value string
+	for range 64 {
+		value += byteHex
+	}
+	return value
+}
+
+func testBase64Signature(t *testing.T, bundle bufmodulesign.Bundle) string {
+	data, err := bufmodulesign.MarshalBundle(bundle)
+	require.NoError(t, err)
+	var externalBundle struct {
+		Signature string `json:"signature"`
+	}
+	require.NoError(t, json.Unmarshal(data, &externalBundle))
+	return externalBundle.Signature
+}
@@ -0,0 +1,219 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodulesign
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufparse"
+)
+
+const (
+	externalBundleVersion = "v1"
+	// signedMessagePrefix separates module signatures from any other use of the same key.
+	signedMessagePrefix = "buf-module-signature-v1\n"
+)
+
+// Bundle is a signature of the b5 Digest of a module, along with the information
+// needed to verify it.
+type Bundle interface {
+	// FullName returns the FullName of the signed module.
+	//
+	// Always present.
+	FullName() bufparse.FullName
+	// Digest returns the signed b5 Digest of the module.
+	//
+	// Always present.
+	Digest() bufmodule.Digest
+	// PublicKey returns the PublicKey of the key that made the signature.
+	//
+	// Always present.
+	PublicKey() PublicKey
+	// Signature returns the signature.
+	//
+	// Always non-empty.
+	Signature() []byte
+
+	isBundle()
+}
+
+// NewBundle signs the FullName and b5 Digest of a module with the PrivateKey.
+func NewBundle(
+	key PrivateKey,
+	moduleFullName bufparse.FullName,
+	digest bufmodule.Digest,
+) (Bundle, error) {
+	if err := validateFullNameAndDigest(moduleFullName, digest); err != nil {
+		return nil, err
+	}
+	return newBundle(
+		moduleFullName,
+		digest,
+		key.PublicKey(),
+		key.sign(getSignedMessage(moduleFullName, digest)),
+	), nil
+}
+
+// VerifyBundle verifies that the Bundle is a valid signature of the FullName and Digest
+// by one of the trusted PublicKeys.
+func VerifyBundle(
+	bundle Bundle,
+	moduleFullName bufparse.FullName,
+	digest bufmodule.Digest,
+	trustedPublicKeys []PublicKey,
+) error {
+	if bundle.FullName().String() != moduleFullName.String() {
+		return fmt.Errorf("signature is for module %s, not %s", bundle.FullName().String(), moduleFullName.String())
+	}
+	if !bufmodule.DigestEqual(bundle.Digest(), digest) {
+		return fmt.Errorf("signature is for digest %s, not %s", bundle.Digest().String(), digest.String())
+	}
+	var trusted bool
+	for _, trustedPublicKey := range trustedPublicKeys {
+		if PublicKeyEqual(bundle.PublicKey(), trustedPublicKey) {
+			trusted = true
+			break
+		}
+	}
+	if !trusted {
+		return fmt.Errorf("signature was made with untrusted key %s", bundle.PublicKey().String())
+	}
+	if !bundle.PublicKey().verify(getSignedMessage(moduleFullName, digest), bundle.Signature()) {
+		return fmt.Errorf("signature does not match key %s", bundle.PublicKey().String())
+	}
+	return nil
+}
+
+// MarshalBundle marshals the Bundle to JSON.
+func MarshalBundle(bundle Bundle) ([]byte, error) {
+	return json.MarshalIndent(
+		externalBundle{
+			Version:   externalBundleVersion,
+			Module:    bundle.FullName().String(),
+			Digest:    bundle.Digest().String(),
+			PublicKey: bundle.PublicKey().String(),
+			Signature: base64.StdEncoding.EncodeToString(bundle.Signature()),
+		},
+		"",
+		"  ",
+	)
+}
+
+// UnmarshalBundle unmarshals a Bundle from JSON.
+//
+// This reverses MarshalBundle. The signature is not verified.
+func UnmarshalBundle(data []byte) (Bundle, error) {
+	var externalBundle externalBundle
+	if err := json.Unmarshal(data, &externalBundle); err != nil {
+		return nil, fmt.Errorf("could not parse signature bundle: %w", err)
+	}
+	if externalBundle.Version != externalBundleVersion {
+		return nil, fmt.Errorf("unknown signature bundle version: %q", externalBundle.Version)
+	}
+	moduleFullName, err := bufparse.ParseFullName(externalBundle.Module)
+	if err != nil {
+		return nil, err
+	}
+	digest, err := bufmodule.ParseDigest(externalBundle.Digest)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateFullNameAndDigest(moduleFullName, digest); err != nil {
+		return nil, err
+	}
+	publicKey, err := ParsePublicKey(externalBundle.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := base64.StdEncoding.DecodeString(externalBundle.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse signature: %w", err)
+	}
+	if len(signature) == 0 {
+		return nil, errors.New("signature bundle has an empty signature")
+	}
+	return newBundle(moduleFullName, digest, publicKey, signature), nil
+}
+
+// *** PRIVATE ***
+
+type bundle struct {
+	moduleFullName bufparse.FullName
+	digest         bufmodule.Digest
+	publicKey      PublicKey
+	signature      []byte
+}
+
+func newBundle(
+	moduleFullName bufparse.FullName,
+	digest bufmodule.Digest,
+	publicKey PublicKey,
+	signature []byte,
+) *bundle {
+	return &bundle{
+		moduleFullName: moduleFullName,
+		digest:         digest,
+		publicKey:      publicKey,
+		signature:      signature,
+	}
+}
+
+func (b *bundle) FullName() bufparse.FullName {
+	return b.moduleFullName
+}
+
+func (b *bundle) Digest() bufmodule.Digest {
+	return b.digest
+}
+
+func (b *bundle) PublicKey() PublicKey {
+	return b.publicKey
+}
+
+func (b *bundle) Signature() []byte {
+	return b.signature
+}
+
+func (*bundle) isBundle() {}
+
+// externalBundle is the JSON representation of a Bundle.
+type externalBundle struct {
+	Version   string `json:"version,omitempty" yaml:"version,omitempty"`
+	Module    string `json:"module,omitempty" yaml:"module,omitempty"`
+	Digest    string `json:"digest,omitempty" yaml:"digest,omitempty"`
+	PublicKey string `json:"public_key,omitempty" yaml:"public_key,omitempty"`
+	Signature string `json:"signature,omitempty" yaml:"signature,omitempty"`
+}
+
+func validateFullNameAndDigest(moduleFullName bufparse.FullName, digest bufmodule.Digest) error {
+	if moduleFullName == nil {
+		return errors.New("a module name is required to sign a module")
+	}
+	if digest == nil {
+		return fmt.Errorf("a digest is required to sign module %s", moduleFullName.String())
+	}
+	if digest.Type() != bufmodule.DigestTypeB5 {
+		return fmt.Errorf("only %v digests can be signed, but got a %v digest for module %s", bufmodule.DigestTypeB5, digest.Type(), moduleFullName.String())
+	}
+	return nil
+}
+
+func getSignedMessage(moduleFullName bufparse.FullName, digest bufmodule.Digest) []byte {
+	return []byte(signedMessagePrefix + moduleFullName.String() + "\n" + digest.String())
+}
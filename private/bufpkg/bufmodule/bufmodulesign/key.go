@@ -0,0 +1,205 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodulesign
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufparse"
+	"github.com/bufbuild/buf/private/pkg/syserror"
+)
+
+const (
+	ed25519KeyPrefix  = "ed25519:"
+	pemTypePrivateKey = "PRIVATE KEY"
+)
+
+// PublicKey is a public key that is trusted to sign modules.
+type PublicKey interface {
+	// String returns the string representation of the PublicKey.
+	//
+	// This is of the form ed25519:base64Value.
+	String() string
+
+	verify(message []byte, signature []byte) bool
+	isPublicKey()
+}
+
+// ParsePublicKey parses a PublicKey from its string representation.
+//
+// A PublicKey string is of the form ed25519:base64Value, where base64Value is the
+// standard base64 encoding of the raw 32-byte ed25519 public key.
+//
+// This reverses PublicKey.String().
+//
+// Returns an error of type *bufparse.ParseError if the string could not be parsed.
+func ParsePublicKey(s string) (PublicKey, error) {
+	base64Value, ok := strings.CutPrefix(s, ed25519KeyPrefix)
+	if !ok {
+		return nil, bufparse.NewParseError(
+			"public key",
+			s,
+			errors.New(`must be in the form "ed25519:base64_value"`),
+		)
+	}
+	value, err := base64.StdEncoding.DecodeString(base64Value)
+	if err != nil {
+		return nil, bufparse.NewParseError(
+			"public key",
+			s,
+			errors.New(`could not parse base64: must be in the form "ed25519:base64_value"`),
+		)
+	}
+	if len(value) != ed25519.PublicKeySize {
+		return nil, bufparse.NewParseError(
+			"public key",
+			s,
+			fmt.Errorf("expected an ed25519 public key of %d bytes but got %d bytes", ed25519.PublicKeySize, len(value)),
+		)
+	}
+	return newPublicKey(ed25519.PublicKey(value)), nil
+}
+
+// PublicKeyEqual returns true if the given PublicKeys are considered equal.
+func PublicKeyEqual(a PublicKey, b PublicKey) bool {
+	if (a == nil) != (b == nil) {
+		return false
+	}
+	if a == nil {
+		return true
+	}
+	return a.String() == b.String()
+}
+
+// PrivateKey is a private key used to sign modules.
+type PrivateKey interface {
+	// PublicKey returns the PublicKey for the PrivateKey.
+	PublicKey() PublicKey
+
+	sign(message []byte) []byte
+	isPrivateKey()
+}
+
+// NewPrivateKey generates a new ed25519 PrivateKey.
+func NewPrivateKey() (PrivateKey, error) {
+	_, value, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return newPrivateKey(value), nil
+}
+
+// ParsePrivateKeyPEM parses a PrivateKey from PEM-encoded PKCS #8 data.
+//
+// This is the format produced by "openssl genpkey -algorithm ed25519".
+func ParsePrivateKeyPEM(data []byte) (PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found for private key")
+	}
+	if block.Type != pemTypePrivateKey {
+		return nil, fmt.Errorf("expected PEM block of type %q for private key but got %q", pemTypePrivateKey, block.Type)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse private key: %w", err)
+	}
+	value, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("expected an ed25519 private key but got %T", key)
+	}
+	return newPrivateKey(value), nil
+}
+
+// MarshalPrivateKeyPEM marshals the PrivateKey to PEM-encoded PKCS #8 data.
+//
+// This reverses ParsePrivateKeyPEM.
+func MarshalPrivateKeyPEM(key PrivateKey) ([]byte, error) {
+	value, err := getEd25519PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := x509.MarshalPKCS8PrivateKey(value)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(
+		&pem.Block{
+			Type:  pemTypePrivateKey,
+			Bytes: data,
+		},
+	), nil
+}
+
+// *** PRIVATE ***
+
+type publicKey struct {
+	value       ed25519.PublicKey
+	stringValue string
+}
+
+func newPublicKey(value ed25519.PublicKey) *publicKey {
+	return &publicKey{
+		value:       value,
+		stringValue: ed25519KeyPrefix + base64.StdEncoding.EncodeToString(value),
+	}
+}
+
+func (p *publicKey) String() string {
+	return p.stringValue
+}
+
+func (p *publicKey) verify(message []byte, signature []byte) bool {
+	return ed25519.Verify(p.value, message, signature)
+}
+
+func (*publicKey) isPublicKey() {}
+
+type privateKey struct {
+	value     ed25519.PrivateKey
+	publicKey *publicKey
+}
+
+func newPrivateKey(value ed25519.PrivateKey) *privateKey {
+	return &privateKey{
+		value:     value,
+		publicKey: newPublicKey(value.Public().(ed25519.PublicKey)),
+	}
+}
+
+func (p *privateKey) PublicKey() PublicKey {
+	return p.publicKey
+}
+
+func (p *privateKey) sign(message []byte) []byte {
+	return ed25519.Sign(p.value, message)
+}
+
+func (*privateKey) isPrivateKey() {}
+
+func getEd25519PrivateKey(key PrivateKey) (ed25519.PrivateKey, error) {
+	p, ok := key.(*privateKey)
+	if !ok {
+		return nil, syserror.Newf("unknown PrivateKey type: %T", key)
+	}
+	return p.value, nil
+}
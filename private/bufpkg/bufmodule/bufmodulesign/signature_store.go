@@ -0,0 +1,118 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodulesign
+
+import (
+	"context"
+	"encoding/hex"
+	"log/slog"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufparse"
+	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/storage"
+)
+
+// SignatureStore reads and writes Bundles.
+type SignatureStore interface {
+	// GetBundle gets the Bundle for the module with the FullName and Digest.
+	//
+	// Returns an error that fulfills fs.ErrNotExist if there is no Bundle for the
+	// module with the FullName and Digest.
+	GetBundle(
+		ctx context.Context,
+		moduleFullName bufparse.FullName,
+		digest bufmodule.Digest,
+	) (Bundle, error)
+	// PutBundle puts the Bundle to the store.
+	//
+	// This replaces any existing Bundle for the FullName and Digest of the Bundle.
+	PutBundle(ctx context.Context, bundle Bundle) error
+}
+
+// NewSignatureStore returns a new SignatureStore for the given bucket.
+//
+// It is assumed that the SignatureStore has complete control of the bucket.
+//
+// This is typically used to interact with a cache directory.
+func NewSignatureStore(
+	logger *slog.Logger,
+	bucket storage.ReadWriteBucket,
+) SignatureStore {
+	return newSignatureStore(logger, bucket)
+}
+
+// *** PRIVATE ***
+
+type signatureStore struct {
+	logger *slog.Logger
+	bucket storage.ReadWriteBucket
+}
+
+func newSignatureStore(
+	logger *slog.Logger,
+	bucket storage.ReadWriteBucket,
+) *signatureStore {
+	return &signatureStore{
+		logger: logger,
+		bucket: bucket,
+	}
+}
+
+func (s *signatureStore) GetBundle(
+	ctx context.Context,
+	moduleFullName bufparse.FullName,
+	digest bufmodule.Digest,
+) (Bundle, error) {
+	path := getSignatureStoreFilePath(moduleFullName, digest)
+	data, err := storage.ReadPath(ctx, s.bucket, path)
+	s.logger.DebugContext(
+		ctx,
+		"signature store get file",
+		slog.String("path", path),
+		slog.Bool("found", err == nil),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return UnmarshalBundle(data)
+}
+
+func (s *signatureStore) PutBundle(ctx context.Context, bundle Bundle) error {
+	data, err := MarshalBundle(bundle)
+	if err != nil {
+		return err
+	}
+	path := getSignatureStoreFilePath(bundle.FullName(), bundle.Digest())
+	s.logger.DebugContext(
+		ctx,
+		"signature store put file",
+		slog.String("path", path),
+	)
+	return storage.PutPath(ctx, s.bucket, path, data, storage.PutWithAtomic())
+}
+
+// Returns the path for a Bundle within the store.
+//
+// This is "registry/owner/name/digestType/digestHexValue.json".
+func getSignatureStoreFilePath(moduleFullName bufparse.FullName, digest bufmodule.Digest) string {
+	return normalpath.Join(
+		moduleFullName.Registry(),
+		moduleFullName.Owner(),
+		moduleFullName.Name(),
+		digest.Type().String(),
+		hex.EncodeToString(digest.Value())+".json",
+	)
+}
@@ -0,0 +1,67 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodulesign
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufmodule"
+	"github.com/bufbuild/buf/private/bufpkg/bufparse"
+)
+
+// VerifyModules verifies that the remote Modules are signed by trusted PublicKeys.
+//
+// getTrustedPublicKeys returns the PublicKeys trusted to sign the module with the
+// given FullName. If it returns no PublicKeys, the module is not required to be signed.
+// Local Modules are never required to be signed.
+//
+// Returns an error for every module that is unsigned or not signed by a trusted PublicKey.
+func VerifyModules(
+	ctx context.Context,
+	signatureStore SignatureStore,
+	modules []bufmodule.Module,
+	getTrustedPublicKeys func(bufparse.FullName) []PublicKey,
+) error {
+	var errs []error
+	for _, module := range modules {
+		moduleFullName := module.FullName()
+		if module.IsLocal() || moduleFullName == nil {
+			continue
+		}
+		trustedPublicKeys := getTrustedPublicKeys(moduleFullName)
+		if len(trustedPublicKeys) == 0 {
+			continue
+		}
+		digest, err := module.Digest(bufmodule.DigestTypeB5)
+		if err != nil {
+			return err
+		}
+		bundle, err := signatureStore.GetBundle(ctx, moduleFullName, digest)
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			errs = append(errs, fmt.Errorf("module %s is not signed for digest %s", moduleFullName.String(), digest.String()))
+			continue
+		}
+		if err := VerifyBundle(bundle, moduleFullName, digest, trustedPublicKeys); err != nil {
+			errs = append(errs, fmt.Errorf("module %s could not be verified: %w", moduleFullName.String(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -16,6 +16,7 @@ package bufmodulestore
 
 import (
 	"context"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io/fs"
@@ -100,6 +101,23 @@ func ModuleDataStoreWithTar() ModuleDataStoreOption {
 	}
 }
 
+// ModuleDataStoreWithDigestPaths returns a new ModuleDataStoreOption that reads and stores
+// tar files at paths keyed by the Digest of the ModuleKey, instead of by the commit of the
+// ModuleKey.
+//
+// Module data read from the store is checked against the Digest of the ModuleKey before it
+// is returned, and module data that does not match is treated as not found. This allows the
+// bucket to be shared with writers that are not trusted, as module data is only read if the
+// Digest of the ModuleKey, for example from a buf.lock file, matches.
+//
+// This implies ModuleDataStoreWithTar.
+func ModuleDataStoreWithDigestPaths() ModuleDataStoreOption {
+	return func(moduleDataStore *moduleDataStore) {
+		moduleDataStore.tar = true
+		moduleDataStore.digestPaths = true
+	}
+}
+
 /// *** PRIVATE ***
 
 type moduleDataStore struct {
@@ -107,7 +125,8 @@ type moduleDataStore struct {
 	bucket storage.ReadWriteBucket
 	locker filelock.Locker
 
-	tar bool
+	tar         bool
+	digestPaths bool
 }
 
 func newModuleDataStore(
@@ -196,7 +215,7 @@ func (p *moduleDataStore) getModuleDataForModuleKey(
 			if !errors.Is(err, fs.ErrNotExist) {
 				// If there is an error fetching the tar bucket that is not because the path does
 				// not exist, we assume this is corrupted and delete the tar.
-				tarPath, err := getModuleDataStoreTarPath(moduleKey)
+				tarPath, err := p.getTarPath(moduleKey)
 				if err != nil {
 					return nil, err
 				}
@@ -304,7 +323,7 @@ func (p *moduleDataStore) getModuleDataForModuleKey(
 	// We rely on the module.yaml file being the last file to be written in the store.
 	// If module.yaml does not exist, we act as if there is no value in the store, which will
 	// result in bad data being overwritten.
-	return bufmodule.NewModuleData(
+	moduleData := bufmodule.NewModuleData(
 		ctx,
 		moduleKey,
 		func() (storage.ReadBucket, error) {
@@ -324,7 +343,23 @@ func (p *moduleDataStore) getModuleDataForModuleKey(
 		func() (bufmodule.ObjectData, error) {
 			return v1BufLockObjectData, nil
 		},
-	), nil
+	)
+	if p.digestPaths {
+		// The bucket may be written to by anyone, so the module data is checked against the
+		// Digest of the ModuleKey now, instead of when the module data is accessed, so that
+		// module data that does not match is treated as not found and overwritten.
+		if _, err := moduleData.Bucket(); err != nil {
+			tarPath, tarPathErr := p.getTarPath(moduleKey)
+			if tarPathErr != nil {
+				return nil, tarPathErr
+			}
+			if deleteErr := p.bucket.Delete(ctx, tarPath); deleteErr != nil && !errors.Is(deleteErr, fs.ErrNotExist) {
+				return nil, deleteErr
+			}
+			return nil, err
+		}
+	}
+	return moduleData, nil
 }
 
 // putModuleData puts the module data into the module cache.
@@ -540,7 +575,7 @@ func (p *moduleDataStore) getReadBucketForTar(
 	ctx context.Context,
 	moduleKey bufmodule.ModuleKey,
 ) (_ storage.ReadBucket, retErr error) {
-	tarPath, err := getModuleDataStoreTarPath(moduleKey)
+	tarPath, err := p.getTarPath(moduleKey)
 	if err != nil {
 		return nil, err
 	}
@@ -577,7 +612,7 @@ func (p *moduleDataStore) getWriteBucketAndCallbackForTar(
 ) (storage.ReadWriteBucket, func(context.Context) error) {
 	readWriteBucket := storagemem.NewReadWriteBucket()
 	return readWriteBucket, func(ctx context.Context) (retErr error) {
-		tarPath, err := getModuleDataStoreTarPath(moduleKey)
+		tarPath, err := p.getTarPath(moduleKey)
 		if err != nil {
 			return err
 		}
@@ -634,6 +669,14 @@ func getModuleDataStoreDirPath(moduleKey bufmodule.ModuleKey) (string, error) {
 	), nil
 }
 
+// getTarPath returns the module's path within the store if storing tar files.
+func (p *moduleDataStore) getTarPath(moduleKey bufmodule.ModuleKey) (string, error) {
+	if p.digestPaths {
+		return getModuleDataStoreDigestTarPath(moduleKey)
+	}
+	return getModuleDataStoreTarPath(moduleKey)
+}
+
 // Returns the module's path within the store if storing tar files.
 //
 // This is "registry/owner/name/dashlessCommitID.tar",
@@ -653,6 +696,21 @@ func getModuleDataStoreTarPath(moduleKey bufmodule.ModuleKey) (string, error) {
 	), nil
 }
 
+// Returns the module's path within the store if storing tar files at paths keyed by digest.
+//
+// This is "digestType/hexDigestValue.tar", e.g. the module with digest "b5:1234abcd"
+// will return "b5/1234abcd.tar".
+func getModuleDataStoreDigestTarPath(moduleKey bufmodule.ModuleKey) (string, error) {
+	digest, err := moduleKey.Digest()
+	if err != nil {
+		return "", err
+	}
+	return normalpath.Join(
+		digest.Type().String(),
+		hex.EncodeToString(digest.Value())+".tar",
+	), nil
+}
+
 func getDeclaredDepModuleKeyForExternalModuleDataDep(dep externalModuleDataDep) (bufmodule.ModuleKey, error) {
 	if dep.Name == "" {
 		return nil, errors.New("no module name specified")
@@ -46,6 +46,63 @@ func TestModuleDataStoreOS(t *testing.T) {
 	testModuleDataStoreOS(t)
 }
 
+func TestModuleDataStoreDigestPaths(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	bucket := storagemem.NewReadWriteBucket()
+	moduleDataStore := NewModuleDataStore(
+		slogtestext.NewLogger(t),
+		bucket,
+		filelock.NewNopLocker(),
+		ModuleDataStoreWithDigestPaths(),
+	)
+	moduleKeys, moduleDatas := testGetModuleKeysAndModuleDatas(t, ctx)
+	require.NoError(t, moduleDataStore.PutModuleDatas(ctx, moduleDatas))
+	foundModuleDatas, notFoundModuleKeys, err := moduleDataStore.GetModuleDatasForModuleKeys(ctx, moduleKeys)
+	require.NoError(t, err)
+	testRequireModuleDataNamesEqual(
+		t,
+		[]string{
+			"buf.build/foo/mod1",
+			"buf.build/foo/mod3",
+			"buf.build/foo/mod2",
+		},
+		foundModuleDatas,
+	)
+	testRequireModuleKeyNamesEqual(t, nil, notFoundModuleKeys)
+
+	// Replace the valid tar of mod1 with the valid tar of mod3, as a writer of a shared
+	// bucket could. The module data does not match the digest of mod1, so it is not read.
+	mod1TarPath, err := getModuleDataStoreDigestTarPath(moduleKeys[0])
+	require.NoError(t, err)
+	mod3TarPath, err := getModuleDataStoreDigestTarPath(moduleKeys[1])
+	require.NoError(t, err)
+	mod3TarData, err := storage.ReadPath(ctx, bucket, mod3TarPath)
+	require.NoError(t, err)
+	require.NoError(t, storage.PutPath(ctx, bucket, mod1TarPath, mod3TarData))
+	foundModuleDatas, notFoundModuleKeys, err = moduleDataStore.GetModuleDatasForModuleKeys(ctx, moduleKeys)
+	require.NoError(t, err)
+	testRequireModuleDataNamesEqual(
+		t,
+		[]string{
+			"buf.build/foo/mod3",
+			"buf.build/foo/mod2",
+		},
+		foundModuleDatas,
+	)
+	testRequireModuleKeyNamesEqual(
+		t,
+		[]string{
+			"buf.build/foo/mod1",
+		},
+		notFoundModuleKeys,
+	)
+	// The invalid tar is deleted so that it is overwritten.
+	exists, err := storage.Exists(ctx, bucket, mod1TarPath)
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
 func testModuleDataStoreBasic(t *testing.T, tar bool) {
 	bucket := storagemem.NewReadWriteBucket()
 	filelocker := filelock.NewNopLocker()
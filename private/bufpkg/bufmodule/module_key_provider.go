@@ -16,10 +16,16 @@ package bufmodule
 
 import (
 	"context"
+	"io/fs"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufparse"
 )
 
+var (
+	// NopModuleKeyProvider is a no-op ModuleKeyProvider.
+	NopModuleKeyProvider ModuleKeyProvider = nopModuleKeyProvider{}
+)
+
 // ModuleKeyProvider provides ModuleKeys for ModuleRefs.
 type ModuleKeyProvider interface {
 	// GetModuleKeysForModuleRefs gets the ModuleKeys for the given ModuleRefs.
@@ -34,3 +40,15 @@ type ModuleKeyProvider interface {
 	// If any ModuleRef is not found, an error with fs.ErrNotExist will be returned.
 	GetModuleKeysForModuleRefs(context.Context, []bufparse.Ref, DigestType) ([]ModuleKey, error)
 }
+
+// *** PRIVATE ***
+
+type nopModuleKeyProvider struct{}
+
+func (nopModuleKeyProvider) GetModuleKeysForModuleRefs(
+	context.Context,
+	[]bufparse.Ref,
+	DigestType,
+) ([]ModuleKey, error) {
+	return nil, fs.ErrNotExist
+}
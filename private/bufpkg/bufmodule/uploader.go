@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"strings"
 
 	"github.com/bufbuild/buf/private/pkg/slicesext"
 	"github.com/bufbuild/buf/private/pkg/syserror"
@@ -134,6 +135,64 @@ func NewUploadOptions(options []UploadOption) (UploadOptions, error) {
 	return uploadOptions, nil
 }
 
+// UploadContentModules returns the Modules of the ModuleSet that an Upload with the
+// UploadOptions uploads content for.
+//
+// These are the target local Modules and their transitive local dependencies that are named.
+// All dependencies of these Modules must be named. Unnamed Modules result in an error, unless
+// UploadOptions.ExcludeUnnamed() is true, in which case they are returned as excluded Modules
+// instead.
+//
+// This is used by Uploader implementations, and to determine what would be uploaded without
+// uploading.
+//
+// Sorted by OpaqueID.
+func UploadContentModules(
+	moduleSet ModuleSet,
+	uploadOptions UploadOptions,
+) (contentModules []Module, excludedModules []Module, _ error) {
+	contentModules, err := ModuleSetTargetLocalModulesAndTransitiveLocalDeps(moduleSet)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Only push named modules to the registry. Any dependencies for named modules must have a name.
+	// Local unnamed modules can be excluded if the UploadWithExcludeUnnamed option is set.
+	contentModules, err = slicesext.FilterError(contentModules, func(module Module) (bool, error) {
+		moduleName := module.FullName()
+		if moduleName == nil {
+			if uploadOptions.ExcludeUnnamed() {
+				excludedModules = append(excludedModules, module)
+				return false, nil
+			}
+			return false, fmt.Errorf("a name must be specified in buf.yaml to push module: %s", module.Description())
+		}
+		deps, err := module.ModuleDeps()
+		if err != nil {
+			return false, err
+		}
+		if allDepModuleDescriptions := slicesext.Reduce(deps, func(allDepModuleDescriptions []string, dep ModuleDep) []string {
+			if moduleName := dep.FullName(); moduleName == nil {
+				return append(allDepModuleDescriptions, dep.Description())
+			}
+			return allDepModuleDescriptions
+		}, nil); len(allDepModuleDescriptions) > 0 {
+			return false, fmt.Errorf(
+				"all dependencies for module %q must be named but these modules are not:\n%s",
+				moduleName.String(),
+				strings.Join(
+					slicesext.Map(allDepModuleDescriptions, func(moduleDescription string) string { return "  " + moduleDescription }),
+					"\n",
+				),
+			)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return contentModules, excludedModules, nil
+}
+
 // *** PRIVATE ***
 
 type nopUploader struct{}
@@ -16,12 +16,14 @@ package bufpluginstore
 
 import (
 	"context"
+	"encoding/hex"
 	"errors"
 	"io/fs"
 	"log/slog"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufplugin"
 	"github.com/bufbuild/buf/private/pkg/normalpath"
+	"github.com/bufbuild/buf/private/pkg/slogext"
 	"github.com/bufbuild/buf/private/pkg/storage"
 	"github.com/bufbuild/buf/private/pkg/uuidutil"
 )
@@ -49,8 +51,26 @@ type PluginDataStore interface {
 func NewPluginDataStore(
 	logger *slog.Logger,
 	bucket storage.ReadWriteBucket,
+	options ...PluginDataStoreOption,
 ) PluginDataStore {
-	return newPluginDataStore(logger, bucket)
+	return newPluginDataStore(logger, bucket, options...)
+}
+
+// PluginDataStoreOption is an option for a new PluginDataStore.
+type PluginDataStoreOption func(*pluginDataStore)
+
+// PluginDataStoreWithDigestPaths returns a new PluginDataStoreOption that reads and stores
+// plugin data at paths keyed by the Digest of the PluginKey, instead of by the commit of
+// the PluginKey.
+//
+// Plugin data read from the store is checked against the Digest of the PluginKey before it
+// is returned, and plugin data that does not match is treated as not found. This allows the
+// bucket to be shared with writers that are not trusted, as plugin data is only read if the
+// Digest of the PluginKey, for example from a buf.lock file, matches.
+func PluginDataStoreWithDigestPaths() PluginDataStoreOption {
+	return func(pluginDataStore *pluginDataStore) {
+		pluginDataStore.digestPaths = true
+	}
 }
 
 /// *** PRIVATE ***
@@ -58,16 +78,23 @@ func NewPluginDataStore(
 type pluginDataStore struct {
 	logger *slog.Logger
 	bucket storage.ReadWriteBucket
+
+	digestPaths bool
 }
 
 func newPluginDataStore(
 	logger *slog.Logger,
 	bucket storage.ReadWriteBucket,
+	options ...PluginDataStoreOption,
 ) *pluginDataStore {
-	return &pluginDataStore{
+	pluginDataStore := &pluginDataStore{
 		logger: logger,
 		bucket: bucket,
 	}
+	for _, option := range options {
+		option(pluginDataStore)
+	}
+	return pluginDataStore
 }
 
 func (p *pluginDataStore) GetPluginDatasForPluginKeys(
@@ -107,7 +134,7 @@ func (p *pluginDataStore) getPluginDataForPluginKey(
 	ctx context.Context,
 	pluginKey bufplugin.PluginKey,
 ) (bufplugin.PluginData, error) {
-	pluginDataStorePath, err := getPluginDataStorePath(pluginKey)
+	pluginDataStorePath, err := p.getPath(pluginKey)
 	if err != nil {
 		return nil, err
 	}
@@ -116,7 +143,7 @@ func (p *pluginDataStore) getPluginDataForPluginKey(
 	} else if !exists {
 		return nil, fs.ErrNotExist
 	}
-	return bufplugin.NewPluginData(
+	pluginData, err := bufplugin.NewPluginData(
 		ctx,
 		pluginKey,
 		func() ([]byte, error) {
@@ -124,6 +151,27 @@ func (p *pluginDataStore) getPluginDataForPluginKey(
 			return storage.ReadPath(ctx, p.bucket, pluginDataStorePath)
 		},
 	)
+	if err != nil {
+		return nil, err
+	}
+	if p.digestPaths {
+		// The bucket may be written to by anyone, so the plugin data is checked against the
+		// Digest of the PluginKey now, instead of when the plugin data is accessed, so that
+		// plugin data that does not match is treated as not found and overwritten.
+		if _, err := pluginData.Data(); err != nil {
+			p.logger.DebugContext(
+				ctx,
+				"plugin data store ignoring invalid plugin data",
+				slog.String("path", pluginDataStorePath),
+				slogext.ErrorAttr(err),
+			)
+			if err := p.bucket.Delete(ctx, pluginDataStorePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return nil, err
+			}
+			return nil, &fs.PathError{Op: "read", Path: pluginDataStorePath, Err: fs.ErrNotExist}
+		}
+	}
+	return pluginData, nil
 }
 
 // putPluginData puts the plugin data into the plugin cache.
@@ -132,7 +180,7 @@ func (p *pluginDataStore) putPluginData(
 	pluginData bufplugin.PluginData,
 ) error {
 	pluginKey := pluginData.PluginKey()
-	pluginDataStorePath, err := getPluginDataStorePath(pluginKey)
+	pluginDataStorePath, err := p.getPath(pluginKey)
 	if err != nil {
 		return err
 	}
@@ -144,6 +192,14 @@ func (p *pluginDataStore) putPluginData(
 	return storage.PutPath(ctx, p.bucket, pluginDataStorePath, data)
 }
 
+// getPath returns the path for the plugin data store for the plugin key.
+func (p *pluginDataStore) getPath(pluginKey bufplugin.PluginKey) (string, error) {
+	if p.digestPaths {
+		return getPluginDataStoreDigestPath(pluginKey)
+	}
+	return getPluginDataStorePath(pluginKey)
+}
+
 // getPluginDataStorePath returns the path for the plugin data store for the plugin key.
 //
 // This is "digestType/registry/owner/name/dashlessCommitID", e.g. the plugin
@@ -163,3 +219,19 @@ func getPluginDataStorePath(pluginKey bufplugin.PluginKey) (string, error) {
 		uuidutil.ToDashless(pluginKey.CommitID())+".wasm",
 	), nil
 }
+
+// getPluginDataStoreDigestPath returns the path for the plugin data store for the plugin key
+// if storing plugin data at paths keyed by digest.
+//
+// This is "digestType/hexDigestValue.wasm", e.g. the plugin with digest "p1:1234abcd"
+// will return "p1/1234abcd.wasm".
+func getPluginDataStoreDigestPath(pluginKey bufplugin.PluginKey) (string, error) {
+	digest, err := pluginKey.Digest()
+	if err != nil {
+		return "", err
+	}
+	return normalpath.Join(
+		digest.Type().String(),
+		hex.EncodeToString(digest.Value())+".wasm",
+	), nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bufremotecache provides buckets for the module and plugin caches that are
+// shared between machines through an HTTP cache server.
+package bufremotecache
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/bufbuild/buf/private/pkg/storage"
+)
+
+// NewHTTPBucket returns a new storage.ReadWriteBucket that stores objects on the HTTP cache
+// server at the base URL.
+//
+// Similar to the remote cache protocol of Bazel, every object is stored as two entries:
+//
+//   - The content of the object is stored at "<baseURL>/cas/<hex value of the bufcas.Digest of the content>".
+//   - The bufcas.Digest of the content is stored at "<baseURL>/ac/<hex value of the bufcas.Digest of the path>".
+//
+// Entries are read with GET requests and written with PUT requests. The base URL may contain
+// user information to authenticate with basic authentication.
+//
+// The content of an object is verified against the bufcas.Digest of its "ac" entry when read.
+// This only detects corrupted content, as the "ac" entries are not verified: anyone who can write
+// to the cache server can point a path at any content. Callers that read from cache servers that
+// are not trusted must verify the content themselves, for example by storing objects at paths
+// keyed by the expected digest of the content, and checking the content against this digest.
+//
+// The cache server cannot be listed, so Walk and DeleteAll are no-ops.
+func NewHTTPBucket(client *http.Client, baseURL string) (storage.ReadWriteBucket, error) {
+	return newHTTPBucket(client, baseURL)
+}
+
+// NewCacheBucket returns a new storage.ReadWriteBucket that caches the objects of the remote
+// bucket in the local bucket.
+//
+// Objects are read from the local bucket first, and then from the remote bucket. Objects read
+// from the remote bucket are written to the local bucket. Objects are written to both buckets.
+//
+// The remote bucket is a cache, and errors from the remote bucket are logged as warnings and
+// otherwise treated as if the object did not exist on the remote bucket.
+//
+// Walk and DeleteAll only operate on the local bucket.
+func NewCacheBucket(
+	logger *slog.Logger,
+	localBucket storage.ReadWriteBucket,
+	remoteBucket storage.ReadWriteBucket,
+) storage.ReadWriteBucket {
+	return newCacheBucket(logger, localBucket, remoteBucket)
+}
@@ -0,0 +1,145 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufremotecache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"log/slog"
+
+	"github.com/bufbuild/buf/private/pkg/slogext"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storageutil"
+)
+
+type cacheBucket struct {
+	logger       *slog.Logger
+	localBucket  storage.ReadWriteBucket
+	remoteBucket storage.ReadWriteBucket
+}
+
+func newCacheBucket(
+	logger *slog.Logger,
+	localBucket storage.ReadWriteBucket,
+	remoteBucket storage.ReadWriteBucket,
+) *cacheBucket {
+	return &cacheBucket{
+		logger:       logger,
+		localBucket:  localBucket,
+		remoteBucket: remoteBucket,
+	}
+}
+
+func (b *cacheBucket) Get(ctx context.Context, path string) (storage.ReadObjectCloser, error) {
+	readObjectCloser, err := b.localBucket.Get(ctx, path)
+	if err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return readObjectCloser, err
+	}
+	data, err := b.getRemote(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if err := storage.PutPath(ctx, b.localBucket, path, data, storage.PutWithAtomic()); err != nil {
+		return nil, err
+	}
+	return b.localBucket.Get(ctx, path)
+}
+
+func (b *cacheBucket) Stat(ctx context.Context, path string) (storage.ObjectInfo, error) {
+	objectInfo, err := b.localBucket.Stat(ctx, path)
+	if err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return objectInfo, err
+	}
+	objectInfo, err = b.remoteBucket.Stat(ctx, path)
+	if err != nil {
+		return nil, b.handleRemoteError(path, err)
+	}
+	return objectInfo, nil
+}
+
+func (b *cacheBucket) Walk(ctx context.Context, prefix string, f func(storage.ObjectInfo) error) error {
+	return b.localBucket.Walk(ctx, prefix, f)
+}
+
+func (b *cacheBucket) Put(ctx context.Context, path string, options ...storage.PutOption) (storage.WriteObjectCloser, error) {
+	path, err := storageutil.ValidatePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return newWriteObjectCloser(
+		func(data []byte) error {
+			if err := storage.PutPath(ctx, b.localBucket, path, data, options...); err != nil {
+				return err
+			}
+			if err := storage.PutPath(ctx, b.remoteBucket, path, data); err != nil {
+				b.logger.Warn("Failed to write to remote cache", slog.String("path", path), slogext.ErrorAttr(err))
+			}
+			return nil
+		},
+	), nil
+}
+
+func (b *cacheBucket) Delete(ctx context.Context, path string) error {
+	localErr := b.localBucket.Delete(ctx, path)
+	if localErr != nil && !errors.Is(localErr, fs.ErrNotExist) {
+		return localErr
+	}
+	remoteErr := b.handleRemoteError(path, b.remoteBucket.Delete(ctx, path))
+	if localErr != nil && remoteErr != nil {
+		// Not found in either bucket.
+		return localErr
+	}
+	return nil
+}
+
+func (b *cacheBucket) DeleteAll(ctx context.Context, prefix string) error {
+	return b.localBucket.DeleteAll(ctx, prefix)
+}
+
+func (*cacheBucket) SetExternalAndLocalPathsSupported() bool {
+	return false
+}
+
+// getRemote reads the path from the remote bucket.
+//
+// Returns an error with fs.ErrNotExist if the path could not be read.
+func (b *cacheBucket) getRemote(ctx context.Context, path string) (_ []byte, retErr error) {
+	readObjectCloser, err := b.remoteBucket.Get(ctx, path)
+	if err != nil {
+		return nil, b.handleRemoteError(path, err)
+	}
+	defer func() {
+		retErr = errors.Join(retErr, readObjectCloser.Close())
+	}()
+	data, err := io.ReadAll(readObjectCloser)
+	if err != nil {
+		return nil, b.handleRemoteError(path, err)
+	}
+	return data, nil
+}
+
+// handleRemoteError logs errors from the remote bucket that are not fs.ErrNotExist as warnings,
+// and returns an error with fs.ErrNotExist for any error.
+func (b *cacheBucket) handleRemoteError(path string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		b.logger.Warn("Failed to read from remote cache", slog.String("path", path), slogext.ErrorAttr(err))
+	}
+	return &fs.PathError{Op: "read", Path: path, Err: fs.ErrNotExist}
+}
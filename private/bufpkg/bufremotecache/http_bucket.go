@@ -0,0 +1,223 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufremotecache
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufcas"
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storageutil"
+)
+
+const (
+	httpBucketCASDir = "cas"
+	httpBucketACDir  = "ac"
+)
+
+type httpBucket struct {
+	client  *http.Client
+	baseURL string
+}
+
+func newHTTPBucket(client *http.Client, baseURL string) (*httpBucket, error) {
+	// The base URL may contain credentials, so errors never contain the base URL unredacted.
+	// The error from url.Parse contains the base URL, so it is not wrapped.
+	parsedURL, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, errors.New("invalid remote cache URL: could not parse URL")
+	}
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return nil, fmt.Errorf("invalid remote cache URL %q: scheme must be http or https", parsedURL.Redacted())
+	}
+	return &httpBucket{
+		client:  client,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}, nil
+}
+
+func (b *httpBucket) Get(ctx context.Context, path string) (storage.ReadObjectCloser, error) {
+	path, err := storageutil.ValidatePath(path)
+	if err != nil {
+		return nil, err
+	}
+	digest, err := b.getDigest(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := b.do(ctx, http.MethodGet, b.getCASURL(digest), nil)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, &fs.PathError{Op: "read", Path: path, Err: fs.ErrNotExist}
+		}
+		return nil, err
+	}
+	actualDigest, err := bufcas.NewDigestForContent(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if !bufcas.DigestEqual(digest, actualDigest) {
+		return nil, fmt.Errorf("remote cache returned content with digest %s for %q, expected %s", actualDigest, path, digest)
+	}
+	return newReadObjectCloser(path, data), nil
+}
+
+func (b *httpBucket) Stat(ctx context.Context, path string) (storage.ObjectInfo, error) {
+	path, err := storageutil.ValidatePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := b.getDigest(ctx, path); err != nil {
+		return nil, err
+	}
+	return storageutil.NewObjectInfo(path, path, ""), nil
+}
+
+func (*httpBucket) Walk(ctx context.Context, prefix string, f func(storage.ObjectInfo) error) error {
+	if _, err := storageutil.ValidatePrefix(prefix); err != nil {
+		return err
+	}
+	// The cache server cannot be listed.
+	return nil
+}
+
+func (b *httpBucket) Put(ctx context.Context, path string, _ ...storage.PutOption) (storage.WriteObjectCloser, error) {
+	path, err := storageutil.ValidatePath(path)
+	if err != nil {
+		return nil, err
+	}
+	// The action cache entry is only written after the content, so writes are always atomic.
+	return newWriteObjectCloser(
+		func(data []byte) error {
+			return b.put(ctx, path, data)
+		},
+	), nil
+}
+
+func (b *httpBucket) Delete(ctx context.Context, path string) error {
+	path, err := storageutil.ValidatePath(path)
+	if err != nil {
+		return err
+	}
+	acURL, err := b.getACURL(path)
+	if err != nil {
+		return err
+	}
+	// Only the action cache entry is deleted, as the content may be shared with other paths.
+	if _, err := b.do(ctx, http.MethodDelete, acURL, nil); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return &fs.PathError{Op: "stat", Path: path, Err: fs.ErrNotExist}
+		}
+		return err
+	}
+	return nil
+}
+
+func (*httpBucket) DeleteAll(ctx context.Context, prefix string) error {
+	if _, err := storageutil.ValidatePrefix(prefix); err != nil {
+		return err
+	}
+	// The cache server cannot be listed.
+	return nil
+}
+
+func (*httpBucket) SetExternalAndLocalPathsSupported() bool {
+	return false
+}
+
+// getDigest gets the bufcas.Digest of the content of the path from the action cache.
+func (b *httpBucket) getDigest(ctx context.Context, path string) (bufcas.Digest, error) {
+	acURL, err := b.getACURL(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := b.do(ctx, http.MethodGet, acURL, nil)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, &fs.PathError{Op: "read", Path: path, Err: fs.ErrNotExist}
+		}
+		return nil, err
+	}
+	digest, err := bufcas.ParseDigest(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("remote cache returned an invalid digest for %q: %w", path, err)
+	}
+	return digest, nil
+}
+
+func (b *httpBucket) put(ctx context.Context, path string, data []byte) error {
+	digest, err := bufcas.NewDigestForContent(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if _, err := b.do(ctx, http.MethodPut, b.getCASURL(digest), data); err != nil {
+		return err
+	}
+	acURL, err := b.getACURL(path)
+	if err != nil {
+		return err
+	}
+	_, err = b.do(ctx, http.MethodPut, acURL, []byte(digest.String()))
+	return err
+}
+
+// do does the request, and returns the response body.
+//
+// Returns an error with fs.ErrNotExist if the response has status 404.
+func (b *httpBucket) do(ctx context.Context, method string, requestURL string, body []byte) (_ []byte, retErr error) {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, requestURL, bodyReader)
+	if err != nil {
+		return nil, err
+	}
+	response, err := b.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		retErr = errors.Join(retErr, response.Body.Close())
+	}()
+	switch {
+	case response.StatusCode == http.StatusNotFound:
+		return nil, fs.ErrNotExist
+	case response.StatusCode < 200 || response.StatusCode >= 300:
+		return nil, fmt.Errorf("remote cache returned status %q for %s %s", response.Status, method, request.URL.Redacted())
+	}
+	return io.ReadAll(response.Body)
+}
+
+func (b *httpBucket) getCASURL(digest bufcas.Digest) string {
+	return b.baseURL + "/" + httpBucketCASDir + "/" + hex.EncodeToString(digest.Value())
+}
+
+func (b *httpBucket) getACURL(path string) (string, error) {
+	digest, err := bufcas.NewDigestForContent(strings.NewReader(path))
+	if err != nil {
+		return "", err
+	}
+	return b.baseURL + "/" + httpBucketACDir + "/" + hex.EncodeToString(digest.Value()), nil
+}
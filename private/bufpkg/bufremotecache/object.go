@@ -0,0 +1,79 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufremotecache
+
+import (
+	"bytes"
+
+	"github.com/bufbuild/buf/private/pkg/storage"
+	"github.com/bufbuild/buf/private/pkg/storage/storageutil"
+)
+
+type readObjectCloser struct {
+	storageutil.ObjectInfo
+
+	reader *bytes.Reader
+}
+
+func newReadObjectCloser(path string, data []byte) *readObjectCloser {
+	return &readObjectCloser{
+		ObjectInfo: storageutil.NewObjectInfo(path, path, ""),
+		reader:     bytes.NewReader(data),
+	}
+}
+
+func (r *readObjectCloser) Read(p []byte) (int, error) {
+	return r.reader.Read(p)
+}
+
+func (*readObjectCloser) Close() error {
+	return nil
+}
+
+// writeObjectCloser buffers the written data, and calls onClose with the data on close.
+type writeObjectCloser struct {
+	buffer  bytes.Buffer
+	onClose func([]byte) error
+	closed  bool
+}
+
+func newWriteObjectCloser(onClose func([]byte) error) *writeObjectCloser {
+	return &writeObjectCloser{
+		onClose: onClose,
+	}
+}
+
+func (w *writeObjectCloser) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, storage.ErrClosed
+	}
+	return w.buffer.Write(p)
+}
+
+func (*writeObjectCloser) SetExternalPath(string) error {
+	return storage.ErrSetExternalPathUnsupported
+}
+
+func (*writeObjectCloser) SetLocalPath(string) error {
+	return storage.ErrSetLocalPathUnsupported
+}
+
+func (w *writeObjectCloser) Close() error {
+	if w.closed {
+		return storage.ErrClosed
+	}
+	w.closed = true
+	return w.onClose(w.buffer.Bytes())
+}
@@ -19,6 +19,7 @@ import (
 	"io"
 	"os/exec"
 	"slices"
+	"time"
 )
 
 var emptyEnv = []string{"__EMPTY_ENV__=1"}
@@ -132,6 +133,16 @@ func WithDir(dir string) RunStartOption {
 	return &dirOption{dir: dir}
 }
 
+// WithWaitDelay returns a new option that sets the maximum time to wait for the
+// stdio of the command to be closed after the Context is cancelled and the command
+// is killed. This bounds the wait when the command has started child processes that
+// keep its stdout or stderr open.
+//
+// The default is to wait until the stdio is closed. See [exec.Cmd.WaitDelay].
+func WithWaitDelay(waitDelay time.Duration) RunStartOption {
+	return &waitDelayOption{waitDelay: waitDelay}
+}
+
 // *** PRIVATE ***
 
 type argsOption struct {
@@ -206,13 +217,26 @@ func (d *dirOption) applyStart(runStartOptions *runStartOptions) {
 	runStartOptions.dir = d.dir
 }
 
+type waitDelayOption struct {
+	waitDelay time.Duration
+}
+
+func (w *waitDelayOption) applyRun(runStartOptions *runStartOptions) {
+	runStartOptions.waitDelay = w.waitDelay
+}
+
+func (w *waitDelayOption) applyStart(runStartOptions *runStartOptions) {
+	runStartOptions.waitDelay = w.waitDelay
+}
+
 type runStartOptions struct {
-	args   []string
-	env    []string
-	stdin  io.Reader
-	stdout io.Writer
-	stderr io.Writer
-	dir    string
+	args      []string
+	env       []string
+	stdin     io.Reader
+	stdout    io.Writer
+	stderr    io.Writer
+	dir       string
+	waitDelay time.Duration
 }
 
 func newRunStartOptions() *runStartOptions {
@@ -250,6 +274,7 @@ func (rs *runStartOptions) applyCmd(cmd *exec.Cmd) {
 	// The default behavior for dir is what we want already, i.e. the current
 	// working directory.
 	cmd.Dir = rs.dir
+	cmd.WaitDelay = rs.waitDelay
 }
 
 type discardReader struct{}
@@ -17,8 +17,10 @@
 package execext
 
 import (
+	"bytes"
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -47,3 +49,21 @@ func TestStartDoubleWait(t *testing.T) {
 	_ = process.Wait()
 	require.Equal(t, process.Wait(), errWaitAlreadyCalled)
 }
+
+func TestRunWaitDelay(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	// The sleep child process keeps stdout open after sh is killed.
+	err := Run(
+		ctx,
+		"sh",
+		WithArgs("-c", "sleep 10; echo done"),
+		WithStdout(bytes.NewBuffer(nil)),
+		WithWaitDelay(100*time.Millisecond),
+	)
+	require.Error(t, err)
+	require.Less(t, time.Since(start), 5*time.Second)
+}
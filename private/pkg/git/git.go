@@ -38,6 +38,7 @@ const (
 	tagsPrefix      = "refs/tags/"
 	headsPrefix     = "refs/heads/"
 	psuedoRefSuffix = "^{}"
+	shortHashLength = 7
 )
 
 var (
@@ -382,6 +383,173 @@ func IsValidRef(
 	return nil
 }
 
+// Commit is a git commit.
+type Commit struct {
+	// Hash is the full hash of the commit.
+	Hash string
+	// Subject is the first line of the commit message.
+	Subject string
+}
+
+// ShortHash returns the abbreviated hash of the commit.
+func (c Commit) ShortHash() string {
+	if len(c.Hash) > shortHashLength {
+		return c.Hash[:shortHashLength]
+	}
+	return c.Hash
+}
+
+// GetRootDir returns the root directory of the git checkout containing the given directory.
+func GetRootDir(
+	ctx context.Context,
+	envContainer app.EnvContainer,
+	dir string,
+) (string, error) {
+	stdout := bytes.NewBuffer(nil)
+	stderr := bytes.NewBuffer(nil)
+	if err := execext.Run(
+		ctx,
+		gitCommand,
+		execext.WithArgs("rev-parse", "--show-toplevel"),
+		execext.WithStdout(stdout),
+		execext.WithStderr(stderr),
+		execext.WithDir(dir),
+		execext.WithEnv(app.Environ(envContainer)),
+	); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			if exitErr.ExitCode() == 128 {
+				return "", fmt.Errorf("dir %s: %w", dir, ErrInvalidGitCheckout)
+			}
+		}
+		return "", fmt.Errorf("failed to get root directory: %w: %s", err, stderr.String())
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// GetCommonDir gets the absolute path of the common git directory of the repository
+// that contains dir.
+//
+// This is the .git directory of the main working tree, and also contains the objects
+// and refs of linked worktrees. For submodules, this is the git directory of the
+// submodule within the git directory of the superproject.
+func GetCommonDir(
+	ctx context.Context,
+	envContainer app.EnvContainer,
+	dir string,
+) (string, error) {
+	stdout := bytes.NewBuffer(nil)
+	stderr := bytes.NewBuffer(nil)
+	if err := execext.Run(
+		ctx,
+		gitCommand,
+		execext.WithArgs("rev-parse", "--git-common-dir"),
+		execext.WithStdout(stdout),
+		execext.WithStderr(stderr),
+		execext.WithDir(dir),
+		execext.WithEnv(app.Environ(envContainer)),
+	); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			if exitErr.ExitCode() == 128 {
+				return "", fmt.Errorf("dir %s: %w", dir, ErrInvalidGitCheckout)
+			}
+		}
+		return "", fmt.Errorf("failed to get common git directory: %w: %s", err, stderr.String())
+	}
+	// The common directory is printed relative to dir if dir is within the main working tree.
+	commonDir := strings.TrimSpace(stdout.String())
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(dir, commonDir)
+	}
+	return filepath.Abs(commonDir)
+}
+
+// ListCommitsInRange returns the commits from since to until, both inclusive, oldest
+// first, for the git repository that contains dir.
+//
+// Only the first parent of merge commits is followed, so that the commits are the
+// sequence of states that until went through, and every commit is the first parent of
+// the next commit. since must be on the first-parent history of until, that is since
+// must not only be reachable through a merged branch.
+func ListCommitsInRange(
+	ctx context.Context,
+	envContainer app.EnvContainer,
+	dir string,
+	since string,
+	until string,
+) ([]Commit, error) {
+	for _, ref := range []string{since, until} {
+		if err := IsValidRef(ctx, envContainer, dir, ref); err != nil {
+			return nil, err
+		}
+	}
+	stderr := bytes.NewBuffer(nil)
+	if err := execext.Run(
+		ctx,
+		gitCommand,
+		execext.WithArgs("merge-base", "--is-ancestor", since, until),
+		execext.WithStderr(stderr),
+		execext.WithDir(dir),
+		execext.WithEnv(app.Environ(envContainer)),
+	); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return nil, fmt.Errorf("%s is not an ancestor of %s", since, until)
+		}
+		return nil, fmt.Errorf("failed to check ancestry of %s: %w: %s", since, err, stderr.String())
+	}
+	// The first commit is since itself, the rest are the commits after since.
+	sinceCommits, err := logCommits(ctx, envContainer, dir, "-1", since)
+	if err != nil {
+		return nil, err
+	}
+	commits, err := logCommits(ctx, envContainer, dir, "--first-parent", "--reverse", since+".."+until)
+	if err != nil {
+		return nil, err
+	}
+	if len(commits) > 0 {
+		// If since is only reachable through a merged branch, the oldest commit on the
+		// first-parent history of until that is not reachable from since is a commit
+		// before the merge, whose first parent is not since.
+		firstParentHash, err := revParse(ctx, envContainer, dir, commits[0].Hash+"^1")
+		if err != nil {
+			return nil, err
+		}
+		if firstParentHash != sinceCommits[0].Hash {
+			return nil, fmt.Errorf(
+				"%s is not on the first-parent history of %s, it is only reachable through a merged branch",
+				since,
+				until,
+			)
+		}
+	}
+	return append(sinceCommits, commits...), nil
+}
+
+// revParse returns the full hash of the commit for the ref.
+func revParse(
+	ctx context.Context,
+	envContainer app.EnvContainer,
+	dir string,
+	ref string,
+) (string, error) {
+	stdout := bytes.NewBuffer(nil)
+	stderr := bytes.NewBuffer(nil)
+	if err := execext.Run(
+		ctx,
+		gitCommand,
+		execext.WithArgs("rev-parse", "--verify", ref+"^{commit}"),
+		execext.WithStdout(stdout),
+		execext.WithStderr(stderr),
+		execext.WithDir(dir),
+		execext.WithEnv(app.Environ(envContainer)),
+	); err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w: %s", ref, err, stderr.String())
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
 // ReadFileAtRef will read the file at path rolled back to the given ref, if
 // it exists at that ref.
 //
@@ -440,6 +608,36 @@ func ReadFileAtRef(
 	return stdout.Bytes(), nil
 }
 
+func logCommits(
+	ctx context.Context,
+	envContainer app.EnvContainer,
+	dir string,
+	args ...string,
+) ([]Commit, error) {
+	stdout := bytes.NewBuffer(nil)
+	stderr := bytes.NewBuffer(nil)
+	if err := execext.Run(
+		ctx,
+		gitCommand,
+		execext.WithArgs(append([]string{"--no-pager", "log", "--format=%H %s"}, args...)...),
+		execext.WithStdout(stdout),
+		execext.WithStderr(stderr),
+		execext.WithDir(dir),
+		execext.WithEnv(app.Environ(envContainer)),
+	); err != nil {
+		return nil, fmt.Errorf("failed to list commits: %w: %s", err, stderr.String())
+	}
+	var commits []Commit
+	for _, line := range getAllTrimmedLinesFromBuffer(stdout) {
+		if line == "" {
+			continue
+		}
+		hash, subject, _ := strings.Cut(line, " ")
+		commits = append(commits, Commit{Hash: hash, Subject: subject})
+	}
+	return commits, nil
+}
+
 func getAllTrimmedLinesFromBuffer(buffer *bytes.Buffer) []string {
 	scanner := bufio.NewScanner(buffer)
 	var lines []string
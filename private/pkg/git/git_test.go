@@ -300,6 +300,101 @@ func TestGitCloner(t *testing.T) {
 	})
 }
 
+func TestListCommitsInRange(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	container, err := app.NewContainerForOS()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	runCommand(ctx, t, container, "git", "-C", dir, "init")
+	runCommand(ctx, t, container, "git", "-C", dir, "config", "user.email", "tests@buf.build")
+	runCommand(ctx, t, container, "git", "-C", dir, "config", "user.name", "Buf go tests")
+	runCommand(ctx, t, container, "git", "-C", dir, "checkout", "-b", "main")
+	var hashes []string
+	for i := 0; i < 4; i++ {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "test.proto"), []byte(fmt.Sprintf("// commit %d", i)), 0600))
+		runCommand(ctx, t, container, "git", "-C", dir, "add", "test.proto")
+		runCommand(ctx, t, container, "git", "-C", dir, "commit", "-m", fmt.Sprintf("commit %d", i))
+		revParseBytes, err := runStdout(ctx, container, "git", "-C", dir, "rev-parse", "HEAD")
+		require.NoError(t, err)
+		hashes = append(hashes, strings.TrimSpace(string(revParseBytes)))
+	}
+
+	rootDir, err := GetRootDir(ctx, container, dir)
+	require.NoError(t, err)
+	expectedRootDir, err := filepath.EvalSymlinks(dir)
+	require.NoError(t, err)
+	actualRootDir, err := filepath.EvalSymlinks(rootDir)
+	require.NoError(t, err)
+	assert.Equal(t, expectedRootDir, actualRootDir)
+
+	commonDir, err := GetCommonDir(ctx, container, dir)
+	require.NoError(t, err)
+	actualCommonDir, err := filepath.EvalSymlinks(commonDir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(expectedRootDir, ".git"), actualCommonDir)
+	// The .git entry of a linked worktree is a file, the common directory is that of the main working tree.
+	worktreeDir := filepath.Join(t.TempDir(), "worktree")
+	runCommand(ctx, t, container, "git", "-C", dir, "worktree", "add", "--detach", worktreeDir, "HEAD")
+	commonDir, err = GetCommonDir(ctx, container, worktreeDir)
+	require.NoError(t, err)
+	actualCommonDir, err = filepath.EvalSymlinks(commonDir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(expectedRootDir, ".git"), actualCommonDir)
+
+	commits, err := ListCommitsInRange(ctx, container, dir, "HEAD~2", "HEAD")
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		[]Commit{
+			{Hash: hashes[1], Subject: "commit 1"},
+			{Hash: hashes[2], Subject: "commit 2"},
+			{Hash: hashes[3], Subject: "commit 3"},
+		},
+		commits,
+	)
+	assert.Equal(t, hashes[1][:7], commits[0].ShortHash())
+
+	commits, err = ListCommitsInRange(ctx, container, dir, "HEAD", "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, []Commit{{Hash: hashes[3], Subject: "commit 3"}}, commits)
+
+	_, err = ListCommitsInRange(ctx, container, dir, "HEAD", "HEAD~1")
+	require.Error(t, err)
+	_, err = ListCommitsInRange(ctx, container, dir, "nonexistent", "HEAD")
+	assert.ErrorIs(t, err, ErrInvalidRef)
+
+	// Create a side branch off commit 1 and merge it into main.
+	runCommand(ctx, t, container, "git", "-C", dir, "checkout", "-b", "side", hashes[1])
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "side.proto"), []byte("// side"), 0600))
+	runCommand(ctx, t, container, "git", "-C", dir, "add", "side.proto")
+	runCommand(ctx, t, container, "git", "-C", dir, "commit", "-m", "side")
+	runCommand(ctx, t, container, "git", "-C", dir, "checkout", "main")
+	runCommand(ctx, t, container, "git", "-C", dir, "merge", "--no-ff", "-m", "merge side", "side")
+	revParseBytes, err := runStdout(ctx, container, "git", "-C", dir, "rev-parse", "HEAD")
+	require.NoError(t, err)
+	mergeHash := strings.TrimSpace(string(revParseBytes))
+
+	// Commits before the merge are on the first-parent history of the merge, and the
+	// merge commit follows its first parent.
+	commits, err = ListCommitsInRange(ctx, container, dir, hashes[2], "main")
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		[]Commit{
+			{Hash: hashes[2], Subject: "commit 2"},
+			{Hash: hashes[3], Subject: "commit 3"},
+			{Hash: mergeHash, Subject: "merge side"},
+		},
+		commits,
+	)
+	// The side commit is an ancestor of main, but is only reachable through the merge.
+	_, err = ListCommitsInRange(ctx, container, dir, "side", "main")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not on the first-parent history")
+}
+
 func readBucketForName(ctx context.Context, t *testing.T, path string, depth uint32, name Name, recurseSubmodules bool) storage.ReadBucket {
 	t.Helper()
 	storageosProvider := storageos.NewProvider(storageos.ProviderWithSymlinks())
@@ -209,15 +209,7 @@ func getLicenseHeader(
 	); err != nil {
 		return "", err
 	}
-	lines := strings.Split(buffer.String(), "\n")
-	for i, line := range lines {
-		if line == "" {
-			lines[i] = prefix
-		} else {
-			lines[i] = prefix + " " + line
-		}
-	}
-	return strings.Join(lines, "\n"), nil
+	return addPrefix(buffer.String(), prefix), nil
 }
 
 func doLinesContainALicense(lines []string) bool {
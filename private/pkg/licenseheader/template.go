@@ -0,0 +1,318 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package licenseheader
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+const (
+	// DefaultTemplateText is the template text used if no template text is given to NewTemplate.
+	DefaultTemplateText = "Copyright {{.YearRange}} {{.CopyrightHolder}}"
+
+	// sentinel values used to determine which fields a template references.
+	yearRangeSentinel       = "\x00yearrange\x00"
+	copyrightHolderSentinel = "\x00copyrightholder\x00"
+)
+
+var (
+	yearRangeRegexp        = regexp.MustCompile(`^\d{4}(-\d{4})?$`)
+	yearRangeCaptureRegexp = `(\d{4}(?:\s*-\s*\d{4})?)`
+	// copyrightLineRegexp matches a normalized comment line that starts an existing license
+	// header that does not match the Template, such as "Copyright 2019-2021 Other, Inc.".
+	copyrightLineRegexp = regexp.MustCompile(`^(?:Copyright|COPYRIGHT)\s+(?:(?:\([cC]\)|©)\s*)?` + yearRangeCaptureRegexp + `(?:[\s,.].*)?$`)
+	// licenseTextPhrases are used to determine if a paragraph of a comment that follows a
+	// license header is license text. These are phrases of common licenses, and not single
+	// words, so that file comments that mention licenses are not treated as license text.
+	licenseTextPhrases = []string{
+		"all rights reserved",
+		"licensed under",
+		"licenses/license-",
+		"spdx-license-identifier",
+		"unless required by applicable law",
+		"distributed under the license",
+		"without warranties or conditions",
+		"limitations under the license",
+		"permission is hereby granted",
+		"redistribution and use in source and binary forms",
+		"the above copyright notice",
+		"this program is free software",
+		"the software is provided \"as is\"",
+	}
+)
+
+// Template is a license header template.
+//
+// Templates are text/templates that may reference {{.CopyrightHolder}} and {{.YearRange}}.
+type Template interface {
+	// Text returns the rendered license header, without any comment prefixes.
+	Text() string
+	// YearRange returns the year range the Template was created with.
+	//
+	// May be empty if the template does not reference {{.YearRange}}.
+	YearRange() string
+	// Match checks if the comment text is a license header for this Template,
+	// regardless of the year range in the comment.
+	//
+	// The comment text must have comment markers already removed, as is the case for
+	// comments in SourceCodeInfo. Whitespace at the start and end of each line is ignored.
+	//
+	// If the comment matches and the template references {{.YearRange}}, the year range
+	// found in the comment is returned.
+	Match(comment string) (foundYearRange string, ok bool)
+	// Modify inserts or updates the license header for the filename and data.
+	//
+	// If the file starts with a license header, it is replaced. A file starts with a license
+	// header if the start of its first comment matches the Template, or if the first line of
+	// its first comment is a copyright line such as "Copyright 2019-2021 Other, Inc.".
+	// The existing license header ends at the first paragraph of the comment that does not
+	// contain a phrase of a common license, so that file comments following the license header,
+	// with or without a blank line in between, are kept. If the file does not start with a
+	// license header, the license header is inserted before the existing content, separated
+	// by a blank line, and no existing comment is removed.
+	//
+	// Returns the modified data, or the unmodified data if no modifications.
+	// If the filename extension is not handled, returns the unmodified data.
+	//
+	// Note this only works with UTF-8 data with lines split by '\n'.
+	Modify(filename string, data []byte) ([]byte, error)
+
+	isTemplate()
+}
+
+// NewTemplate returns a new Template.
+//
+// If templateText is empty, DefaultTemplateText is used. The copyrightHolder and yearRange
+// are required if the template references them. The yearRange must be of the form
+// "YYYY" or "YYYY-YYYY".
+func NewTemplate(templateText string, copyrightHolder string, yearRange string) (Template, error) {
+	return newTemplate(templateText, copyrightHolder, yearRange)
+}
+
+// *** PRIVATE ***
+
+type licenseTemplate struct {
+	text      string
+	yearRange string
+	// matchRegexp matches normalized comment text. If the template references the year range,
+	// the first submatch is the year range.
+	matchRegexp         *regexp.Regexp
+	referencesYearRange bool
+}
+
+func newTemplate(templateText string, copyrightHolder string, yearRange string) (*licenseTemplate, error) {
+	if templateText == "" {
+		templateText = DefaultTemplateText
+	}
+	tmpl, err := template.New("license_header").Option("missingkey=error").Parse(templateText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid license header template: %w", err)
+	}
+	sentinelText, err := executeTemplate(tmpl, copyrightHolderSentinel, yearRangeSentinel)
+	if err != nil {
+		return nil, err
+	}
+	referencesYearRange := strings.Contains(sentinelText, yearRangeSentinel)
+	if strings.Contains(sentinelText, copyrightHolderSentinel) && copyrightHolder == "" {
+		return nil, errors.New("license header template references {{.CopyrightHolder}} but no copyright holder was given")
+	}
+	if referencesYearRange && yearRange == "" {
+		return nil, errors.New("license header template references {{.YearRange}} but no year range was given")
+	}
+	if yearRange != "" && !yearRangeRegexp.MatchString(yearRange) {
+		return nil, fmt.Errorf("invalid license header year range %q: must be of the form YYYY or YYYY-YYYY", yearRange)
+	}
+	text, err := executeTemplate(tmpl, copyrightHolder, yearRange)
+	if err != nil {
+		return nil, err
+	}
+	text = strings.Join(trimEmptyLines(strings.Split(text, "\n"), strings.TrimRightFunc), "\n")
+	if text == "" {
+		return nil, errors.New("license header template must not render to empty text")
+	}
+	// Only the year range is left variable in the regexp, everything else must match exactly.
+	matchText, err := executeTemplate(tmpl, copyrightHolder, yearRangeSentinel)
+	if err != nil {
+		return nil, err
+	}
+	matchRegexpString := strings.ReplaceAll(
+		regexp.QuoteMeta(strings.Join(normalizeLines(matchText), "\n")),
+		yearRangeSentinel,
+		yearRangeCaptureRegexp,
+	)
+	matchRegexp, err := regexp.Compile("^" + matchRegexpString + "$")
+	if err != nil {
+		return nil, err
+	}
+	return &licenseTemplate{
+		text:                text,
+		yearRange:           yearRange,
+		matchRegexp:         matchRegexp,
+		referencesYearRange: referencesYearRange,
+	}, nil
+}
+
+func (t *licenseTemplate) Text() string {
+	return t.text
+}
+
+func (t *licenseTemplate) YearRange() string {
+	return t.yearRange
+}
+
+func (t *licenseTemplate) Match(comment string) (string, bool) {
+	submatches := t.matchRegexp.FindStringSubmatch(strings.Join(normalizeLines(comment), "\n"))
+	if submatches == nil {
+		return "", false
+	}
+	if !t.referencesYearRange {
+		return "", true
+	}
+	return strings.Join(strings.Fields(submatches[1]), ""), true
+}
+
+func (t *licenseTemplate) Modify(filename string, data []byte) ([]byte, error) {
+	prefix, ok := getPrefix(filename)
+	if !ok {
+		return data, nil
+	}
+	licenseHeader := addPrefix(t.text, prefix)
+	lines := strings.Split(string(data), "\n")
+	var commentLines []string
+	for _, line := range lines {
+		if !strings.HasPrefix(line, prefix) {
+			break
+		}
+		commentLines = append(commentLines, strings.TrimPrefix(line, prefix))
+	}
+	headerLineCount := t.getHeaderLineCount(commentLines)
+	if headerLineCount > 0 {
+		// Drop the empty comment lines between the license header and the rest of the comment.
+		for headerLineCount < len(commentLines) && isEmptyCommentLine(commentLines[headerLineCount]) {
+			headerLineCount++
+		}
+	}
+	remainder := strings.TrimLeft(strings.Join(lines[headerLineCount:], "\n"), "\n")
+	if len(remainder) == 0 {
+		return []byte(licenseHeader + "\n"), nil
+	}
+	return []byte(licenseHeader + "\n\n" + remainder), nil
+}
+
+// getHeaderLineCount returns the number of comment lines at the start of the file that
+// are an existing license header, or 0 if the file does not start with a license header.
+//
+// The license header starts with text matching the Template, or with a copyright line.
+// Paragraphs of license text that follow, such as the rest of a license that the Template
+// only matches the start of, are part of the license header. The comment lines must have
+// comment prefixes removed.
+func (t *licenseTemplate) getHeaderLineCount(commentLines []string) int {
+	var headerLineCount int
+	for i := 1; i <= len(commentLines); i++ {
+		if _, ok := t.Match(strings.Join(commentLines[:i], "\n")); ok {
+			headerLineCount = i
+			break
+		}
+	}
+	if headerLineCount == 0 {
+		if len(commentLines) == 0 || !copyrightLineRegexp.MatchString(strings.TrimSpace(commentLines[0])) {
+			return 0
+		}
+		headerLineCount = 1
+	}
+	for i := headerLineCount; ; {
+		for i < len(commentLines) && isEmptyCommentLine(commentLines[i]) {
+			i++
+		}
+		start := i
+		for i < len(commentLines) && !isEmptyCommentLine(commentLines[i]) {
+			i++
+		}
+		if start == i || !doLinesContainLicenseText(commentLines[start:i]) {
+			return headerLineCount
+		}
+		headerLineCount = i
+	}
+}
+
+func (*licenseTemplate) isTemplate() {}
+
+// doLinesContainLicenseText returns true if any of the lines contain a phrase of a
+// common license.
+func doLinesContainLicenseText(lines []string) bool {
+	for _, line := range lines {
+		line = strings.ToLower(line)
+		for _, licenseTextPhrase := range licenseTextPhrases {
+			if strings.Contains(line, licenseTextPhrase) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isEmptyCommentLine returns true if the comment line, with the comment prefix removed,
+// only contains whitespace.
+func isEmptyCommentLine(commentLine string) bool {
+	return strings.TrimSpace(commentLine) == ""
+}
+
+func executeTemplate(tmpl *template.Template, copyrightHolder string, yearRange string) (string, error) {
+	buffer := bytes.NewBuffer(nil)
+	if err := tmpl.Execute(buffer, newLicenseData(copyrightHolder, yearRange)); err != nil {
+		return "", fmt.Errorf("invalid license header template: %w", err)
+	}
+	return buffer.String(), nil
+}
+
+// normalizeLines splits the text into lines, trims whitespace from each line, and
+// removes leading and trailing empty lines.
+func normalizeLines(text string) []string {
+	return trimEmptyLines(strings.Split(text, "\n"), strings.TrimFunc)
+}
+
+// trimEmptyLines trims whitespace from each line with the trim function, and removes
+// leading and trailing empty lines.
+func trimEmptyLines(lines []string, trim func(string, func(rune) bool) string) []string {
+	for i, line := range lines {
+		lines[i] = trim(line, unicode.IsSpace)
+	}
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// addPrefix adds the comment prefix to each line of the text.
+func addPrefix(text string, prefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = prefix
+		} else {
+			lines[i] = prefix + " " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,179 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package licenseheader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateModify(t *testing.T) {
+	t.Parallel()
+
+	licenseTemplate, err := NewTemplate("", "Foo Bar, Inc.", "2020-2024")
+	require.NoError(t, err)
+	header := "// Copyright 2020-2024 Foo Bar, Inc."
+	testTemplateModify(
+		t,
+		licenseTemplate,
+		`syntax = "proto3";`,
+		header+"\n\n"+`syntax = "proto3";`,
+	)
+	testTemplateModify(
+		t,
+		licenseTemplate,
+		"// Copyright 2019-2021 Foo Bar, Inc.\n\nsyntax = \"proto3\";",
+		header+"\n\n"+`syntax = "proto3";`,
+	)
+	testTemplateModify(
+		t,
+		licenseTemplate,
+		"// Copyright 2019 Other, Inc.\nsyntax = \"proto3\";",
+		header+"\n\n"+`syntax = "proto3";`,
+	)
+	testTemplateModify(
+		t,
+		licenseTemplate,
+		"// Package foo has foos.\npackage foo;",
+		header+"\n\n// Package foo has foos.\npackage foo;",
+	)
+	testTemplateModify(
+		t,
+		licenseTemplate,
+		header+"\n\n// Package foo has foos.\npackage foo;",
+		header+"\n\n// Package foo has foos.\npackage foo;",
+	)
+	testTemplateModify(
+		t,
+		licenseTemplate,
+		header+"\n// Package foo has foos.\npackage foo;",
+		header+"\n\n// Package foo has foos.\npackage foo;",
+	)
+	testTemplateModify(
+		t,
+		licenseTemplate,
+		"// Copyright 2019-2021 Foo Bar, Inc.\n// Package foo has foos.\n//\n// Foos are great.\npackage foo;",
+		header+"\n\n// Package foo has foos.\n//\n// Foos are great.\npackage foo;",
+	)
+	testTemplateModify(
+		t,
+		licenseTemplate,
+		"// Copyright 2019 Other, Inc.\n//\n// All rights reserved.\n//\n// Package foo has foos.\npackage foo;",
+		header+"\n\n// Package foo has foos.\npackage foo;",
+	)
+	testTemplateModify(
+		t,
+		licenseTemplate,
+		testApacheGoHeader+"\n//\n// Package foo has foos.\npackage foo;",
+		header+"\n\n// Package foo has foos.\npackage foo;",
+	)
+	// File comments that mention licenses are not license headers.
+	testTemplateModify(
+		t,
+		licenseTemplate,
+		"// The licensing API lets callers check a license for a seat.\npackage foo;",
+		header+"\n\n// The licensing API lets callers check a license for a seat.\npackage foo;",
+	)
+	testTemplateModify(
+		t,
+		licenseTemplate,
+		"// Copyright checks for the warranty service.\n//\n// All rights reserved.\npackage foo;",
+		header+"\n\n// Copyright checks for the warranty service.\n//\n// All rights reserved.\npackage foo;",
+	)
+	testTemplateModify(
+		t,
+		licenseTemplate,
+		"// Copyright 2019 Other, Inc.\n//\n// The licensing API lets callers check a license for a seat.\npackage foo;",
+		header+"\n\n// The licensing API lets callers check a license for a seat.\npackage foo;",
+	)
+	testTemplateModify(
+		t,
+		licenseTemplate,
+		"",
+		header+"\n",
+	)
+
+	proprietaryTemplate, err := NewTemplate(
+		"Copyright {{.YearRange}} {{.CopyrightHolder}}\n\nAll rights reserved.",
+		"Foo Bar, Inc.",
+		"2020-2024",
+	)
+	require.NoError(t, err)
+	testTemplateModify(
+		t,
+		proprietaryTemplate,
+		testProprietaryGoHeader+"\n// Package foo has foos.\npackage foo;",
+		"// Copyright 2020-2024 Foo Bar, Inc.\n//\n// All rights reserved.\n\n// Package foo has foos.\npackage foo;",
+	)
+	modifiedData, err := licenseTemplate.Modify("foo/bar.unknown", []byte("package foo"))
+	require.NoError(t, err)
+	require.Equal(t, "package foo", string(modifiedData))
+}
+
+func TestTemplateMatch(t *testing.T) {
+	t.Parallel()
+
+	licenseTemplate, err := NewTemplate(
+		"SPDX-License-Identifier: Apache-2.0\n\nCopyright {{.YearRange}} {{.CopyrightHolder}}",
+		"Foo Bar, Inc.",
+		"2020-2024",
+	)
+	require.NoError(t, err)
+	foundYearRange, ok := licenseTemplate.Match(" SPDX-License-Identifier: Apache-2.0\n\n Copyright 2020-2021 Foo Bar, Inc.\n")
+	require.True(t, ok)
+	require.Equal(t, "2020-2021", foundYearRange)
+	foundYearRange, ok = licenseTemplate.Match("SPDX-License-Identifier: Apache-2.0\nCopyright 2024 Foo Bar, Inc.")
+	require.False(t, ok)
+	require.Empty(t, foundYearRange)
+	_, ok = licenseTemplate.Match(" SPDX-License-Identifier: Apache-2.0\n\n Copyright 2020-2024 Other, Inc.\n")
+	require.False(t, ok)
+
+	licenseTemplate, err = NewTemplate("SPDX-License-Identifier: MIT", "", "")
+	require.NoError(t, err)
+	foundYearRange, ok = licenseTemplate.Match(" SPDX-License-Identifier: MIT")
+	require.True(t, ok)
+	require.Empty(t, foundYearRange)
+}
+
+func TestNewTemplateError(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewTemplate("", "", "2020")
+	require.Error(t, err)
+	_, err = NewTemplate("", "Foo Bar, Inc.", "")
+	require.Error(t, err)
+	_, err = NewTemplate("", "Foo Bar, Inc.", "20-24")
+	require.Error(t, err)
+	_, err = NewTemplate("Copyright {{.Owner}}", "Foo Bar, Inc.", "2020")
+	require.Error(t, err)
+	_, err = NewTemplate("Copyright {{", "Foo Bar, Inc.", "2020")
+	require.Error(t, err)
+}
+
+func testTemplateModify(
+	t *testing.T,
+	licenseTemplate Template,
+	input string,
+	expected string,
+) {
+	modifiedData, err := licenseTemplate.Modify("foo/bar.proto", []byte(input))
+	require.NoError(t, err)
+	require.Equal(t, expected, string(modifiedData))
+	// Modify should be idempotent.
+	modifiedData, err = licenseTemplate.Modify("foo/bar.proto", modifiedData)
+	require.NoError(t, err)
+	require.Equal(t, expected, string(modifiedData))
+}
@@ -0,0 +1,249 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package normalpath
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+const (
+	globChars     = "*?[{"
+	globStarStar  = "**"
+	globSeparator = "/"
+)
+
+// IsGlob returns true if the path contains glob syntax, that is any of the characters "*?[{".
+func IsGlob(path string) bool {
+	return strings.ContainsAny(path, globChars)
+}
+
+// ValidateGlob validates the glob pattern.
+//
+// The pattern is expected to be normalized. Patterns support the following syntax:
+//
+//   - "*" matches any sequence of characters within a path component.
+//   - "?" matches any single character within a path component.
+//   - "[...]" matches a character class, as with path.Match.
+//   - "{a,b}" matches any of the comma-separated alternatives.
+//   - "**" matches zero or more path components, and must be an entire path component.
+func ValidateGlob(pattern string) error {
+	patterns, err := expandGlobBraces(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid glob %q: %w", pattern, err)
+	}
+	for _, pattern := range patterns {
+		for _, component := range strings.Split(pattern, globSeparator) {
+			if component == globStarStar {
+				continue
+			}
+			if strings.Contains(component, globStarStar) {
+				return fmt.Errorf("invalid glob %q: %q must be an entire path component", pattern, globStarStar)
+			}
+			if _, err := path.Match(component, ""); err != nil {
+				return fmt.Errorf("invalid glob %q: %w", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Glob is a compiled glob pattern.
+//
+// Braces are expanded once when the Glob is created, so a Glob should be created once
+// and reused when matching many paths against the same pattern.
+type Glob interface {
+	// Match returns true if the normalized path matches the glob pattern.
+	Match(path string) bool
+	// EqualsOrContainsPath returns true if the glob pattern matches the normalized path
+	// or any of the parent directories of the path.
+	EqualsOrContainsPath(path string) bool
+
+	isGlob()
+}
+
+// NewGlob returns a new Glob for the glob pattern.
+//
+// See ValidateGlob for the supported syntax.
+func NewGlob(pattern string) (Glob, error) {
+	if err := ValidateGlob(pattern); err != nil {
+		return nil, err
+	}
+	return newGlob(pattern)
+}
+
+// MatchGlob returns true if the normalized path matches the glob pattern.
+//
+// See ValidateGlob for the supported syntax. Invalid patterns never match.
+//
+// Use NewGlob to match many paths against the same pattern.
+func MatchGlob(pattern string, path string) bool {
+	glob, err := newGlob(pattern)
+	if err != nil {
+		return false
+	}
+	return glob.Match(path)
+}
+
+// GlobEqualsOrContainsPath returns true if the glob pattern matches the normalized path
+// or any of the parent directories of the path.
+//
+// This is the equivalent of EqualsOrContainsPath for glob patterns. Invalid patterns never match.
+//
+// Use NewGlob to match many paths against the same pattern.
+func GlobEqualsOrContainsPath(pattern string, path string) bool {
+	glob, err := newGlob(pattern)
+	if err != nil {
+		return false
+	}
+	return glob.EqualsOrContainsPath(path)
+}
+
+// GlobLiteralPrefix returns the leading path components of the glob pattern that do not
+// contain any glob syntax.
+//
+// Returns "." if the first component of the pattern contains glob syntax.
+func GlobLiteralPrefix(pattern string) string {
+	var literalComponents []string
+	for _, component := range strings.Split(pattern, globSeparator) {
+		if IsGlob(component) {
+			break
+		}
+		literalComponents = append(literalComponents, component)
+	}
+	if len(literalComponents) == 0 {
+		return "."
+	}
+	return strings.Join(literalComponents, globSeparator)
+}
+
+// *** PRIVATE ***
+
+type glob struct {
+	// The path components of each pattern, after brace expansion.
+	patternsComponents [][]string
+}
+
+func newGlob(pattern string) (*glob, error) {
+	patterns, err := expandGlobBraces(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patternsComponents := make([][]string, len(patterns))
+	for i, pattern := range patterns {
+		patternsComponents[i] = strings.Split(pattern, globSeparator)
+	}
+	return &glob{
+		patternsComponents: patternsComponents,
+	}, nil
+}
+
+func (g *glob) Match(path string) bool {
+	pathComponents := strings.Split(path, globSeparator)
+	for _, patternComponents := range g.patternsComponents {
+		if matchGlobComponents(patternComponents, pathComponents) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *glob) EqualsOrContainsPath(path string) bool {
+	for {
+		if g.Match(path) {
+			return true
+		}
+		parent := Dir(path)
+		if parent == path || parent == "." {
+			return false
+		}
+		path = parent
+	}
+}
+
+func (*glob) isGlob() {}
+
+func matchGlobComponents(patternComponents []string, pathComponents []string) bool {
+	for len(patternComponents) > 0 {
+		patternComponent := patternComponents[0]
+		if patternComponent == globStarStar {
+			// "**" matches zero or more path components.
+			for i := 0; i <= len(pathComponents); i++ {
+				if matchGlobComponents(patternComponents[1:], pathComponents[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(pathComponents) == 0 {
+			return false
+		}
+		matched, err := path.Match(patternComponent, pathComponents[0])
+		if err != nil || !matched {
+			return false
+		}
+		patternComponents = patternComponents[1:]
+		pathComponents = pathComponents[1:]
+	}
+	return len(pathComponents) == 0
+}
+
+// expandGlobBraces expands the first set of braces in the pattern, recursively.
+//
+// For example, "a/{b,c}/{d,e}" expands to "a/b/d", "a/b/e", "a/c/d", "a/c/e".
+func expandGlobBraces(pattern string) ([]string, error) {
+	start := strings.IndexByte(pattern, '{')
+	if start < 0 {
+		if strings.IndexByte(pattern, '}') >= 0 {
+			return nil, errors.New("unmatched '}'")
+		}
+		return []string{pattern}, nil
+	}
+	depth := 0
+	end := -1
+	alternativeStart := start + 1
+	var alternatives []string
+	for i := start; i < len(pattern) && end < 0; i++ {
+		switch pattern[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				alternatives = append(alternatives, pattern[alternativeStart:i])
+				end = i
+			}
+		case ',':
+			if depth == 1 {
+				alternatives = append(alternatives, pattern[alternativeStart:i])
+				alternativeStart = i + 1
+			}
+		}
+	}
+	if end < 0 {
+		return nil, errors.New("unmatched '{'")
+	}
+	var expanded []string
+	for _, alternative := range alternatives {
+		subPatterns, err := expandGlobBraces(pattern[:start] + alternative + pattern[end+1:])
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, subPatterns...)
+	}
+	return expanded, nil
+}
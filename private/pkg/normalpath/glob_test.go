@@ -0,0 +1,92 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package normalpath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchGlob(t *testing.T) {
+	t.Parallel()
+	testMatchGlob(t, true, "a/*.proto", "a/b.proto")
+	testMatchGlob(t, false, "a/*.proto", "a/b/c.proto")
+	testMatchGlob(t, true, "a/**/*.proto", "a/b.proto")
+	testMatchGlob(t, true, "a/**/*.proto", "a/b/c/d.proto")
+	testMatchGlob(t, false, "a/**/*.proto", "b/c.proto")
+	testMatchGlob(t, true, "**", "a/b/c.proto")
+	testMatchGlob(t, true, "**/gen", "a/b/gen")
+	testMatchGlob(t, true, "**/gen", "gen")
+	testMatchGlob(t, false, "**/gen", "a/gen/c.proto")
+	testMatchGlob(t, true, "a/?/c.proto", "a/b/c.proto")
+	testMatchGlob(t, false, "a/?/c.proto", "a/bb/c.proto")
+	testMatchGlob(t, true, "a/[bc]/d.proto", "a/c/d.proto")
+	testMatchGlob(t, true, "a/{b,c}/*.proto", "a/c/d.proto")
+	testMatchGlob(t, true, "a/{b,c/{d,e}}/*.proto", "a/c/e/f.proto")
+	testMatchGlob(t, false, "a/{b,c}/*.proto", "a/d/e.proto")
+	testMatchGlob(t, false, "a/{b,c/*.proto", "a/b/c.proto")
+}
+
+func TestGlobEqualsOrContainsPath(t *testing.T) {
+	t.Parallel()
+	assert.True(t, GlobEqualsOrContainsPath("**/gen", "a/gen/c.proto"))
+	assert.True(t, GlobEqualsOrContainsPath("a/*", "a/b/c/d.proto"))
+	assert.True(t, GlobEqualsOrContainsPath("**/*_gen.proto", "a/b/c_gen.proto"))
+	assert.False(t, GlobEqualsOrContainsPath("**/*_gen.proto", "a/b/c.proto"))
+	assert.False(t, GlobEqualsOrContainsPath("b/*", "a/b/c.proto"))
+}
+
+func TestNewGlob(t *testing.T) {
+	t.Parallel()
+	glob, err := NewGlob("a/{b,c/{d,e}}/**/*_gen.proto")
+	require.NoError(t, err)
+	assert.True(t, glob.Match("a/b/f_gen.proto"))
+	assert.True(t, glob.Match("a/c/e/f/g_gen.proto"))
+	assert.False(t, glob.Match("a/c/f_gen.proto"))
+	assert.True(t, glob.EqualsOrContainsPath("a/b/f_gen.proto"))
+	assert.False(t, glob.EqualsOrContainsPath("a/b/f.proto"))
+	glob, err = NewGlob("**/gen")
+	require.NoError(t, err)
+	assert.False(t, glob.Match("a/gen/c.proto"))
+	assert.True(t, glob.EqualsOrContainsPath("a/gen/c.proto"))
+	_, err = NewGlob("a/{b,c")
+	assert.Error(t, err)
+	_, err = NewGlob("a/**.proto")
+	assert.Error(t, err)
+}
+
+func TestValidateGlob(t *testing.T) {
+	t.Parallel()
+	assert.NoError(t, ValidateGlob("a/**/*.proto"))
+	assert.NoError(t, ValidateGlob("a/{b,c}/[de]?.proto"))
+	assert.Error(t, ValidateGlob("a/**.proto"))
+	assert.Error(t, ValidateGlob("a/{b,c"))
+	assert.Error(t, ValidateGlob("a/b,c}"))
+	assert.Error(t, ValidateGlob("a/[b"))
+}
+
+func TestGlobLiteralPrefix(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "a/b", GlobLiteralPrefix("a/b/**/*.proto"))
+	assert.Equal(t, "a", GlobLiteralPrefix("a/{b,c}/d"))
+	assert.Equal(t, ".", GlobLiteralPrefix("**/gen"))
+	assert.Equal(t, "a/b", GlobLiteralPrefix("a/b"))
+}
+
+func testMatchGlob(t *testing.T, expected bool, pattern string, path string) {
+	assert.Equal(t, expected, MatchGlob(pattern, path), "pattern %q path %q", pattern, path)
+}
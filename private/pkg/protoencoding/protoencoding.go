@@ -120,6 +120,15 @@ func NewTxtpbMarshaler(resolver Resolver) Marshaler {
 	return newTxtpbMarshaler(resolver)
 }
 
+// NewRawMarshaler returns a new Marshaler that prints the wire format of messages
+// without using a schema, in the same manner as protoc --decode_raw.
+//
+// All fields are printed by field number. Length-delimited fields are printed
+// as embedded messages if they can be parsed as such, and as strings otherwise.
+func NewRawMarshaler() Marshaler {
+	return newRawMarshaler()
+}
+
 // NewYAMLMarshaler returns a new Marshaler for YAML.
 //
 // If the resolver is nil, EmptyResolver will be used.
@@ -0,0 +1,197 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protoencoding
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// rawParseRecursionLimit is the default recursion limit of protoc's CodedInputStream,
+	// which is used when parsing the top-level data.
+	rawParseRecursionLimit = 100
+	// rawPrintRecursionLimit is protoc's kUnknownFieldRecursionLimit, which is used
+	// by TextFormat::Printer when printing unknown fields.
+	rawPrintRecursionLimit = 10
+)
+
+type rawMarshaler struct{}
+
+func newRawMarshaler() Marshaler {
+	return &rawMarshaler{}
+}
+
+func (m *rawMarshaler) Marshal(message proto.Message) ([]byte, error) {
+	data, err := newWireMarshaler().Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+	return marshalRaw(data)
+}
+
+func marshalRaw(data []byte) ([]byte, error) {
+	fields, _, err := parseRawFields(data, rawParseRecursionLimit, 0)
+	if err != nil {
+		return nil, err
+	}
+	buffer := bytes.NewBuffer(nil)
+	printRawFields(buffer, fields, 0, rawPrintRecursionLimit)
+	return buffer.Bytes(), nil
+}
+
+type rawField struct {
+	number protowire.Number
+	typ    protowire.Type
+	// value is set for varint, fixed32, and fixed64 fields.
+	value uint64
+	// data is set for length-delimited fields.
+	data []byte
+	// group is set for group fields.
+	group []*rawField
+}
+
+// parseRawFields parses the data as a sequence of fields in the same manner as
+// protoc's UnknownFieldSet.
+//
+// If endGroupNumber is non-zero, this parses the contents of a group, and stops at the
+// matching end-group tag. The number of bytes consumed, including the end-group tag,
+// is returned.
+func parseRawFields(data []byte, recursionBudget int, endGroupNumber protowire.Number) ([]*rawField, int, error) {
+	var fields []*rawField
+	offset := 0
+	for offset < len(data) {
+		number, typ, n := protowire.ConsumeTag(data[offset:])
+		if n < 0 {
+			return nil, 0, protowire.ParseError(n)
+		}
+		offset += n
+		field := &rawField{
+			number: number,
+			typ:    typ,
+		}
+		switch typ {
+		case protowire.VarintType:
+			field.value, n = protowire.ConsumeVarint(data[offset:])
+		case protowire.Fixed32Type:
+			var value uint32
+			value, n = protowire.ConsumeFixed32(data[offset:])
+			field.value = uint64(value)
+		case protowire.Fixed64Type:
+			field.value, n = protowire.ConsumeFixed64(data[offset:])
+		case protowire.BytesType:
+			field.data, n = protowire.ConsumeBytes(data[offset:])
+		case protowire.StartGroupType:
+			if recursionBudget <= 0 {
+				return nil, 0, errors.New("exceeded maximum recursion depth")
+			}
+			var err error
+			field.group, n, err = parseRawFields(data[offset:], recursionBudget-1, number)
+			if err != nil {
+				return nil, 0, err
+			}
+		case protowire.EndGroupType:
+			if endGroupNumber == 0 || number != endGroupNumber {
+				return nil, 0, fmt.Errorf("unexpected end group for field %d", number)
+			}
+			return fields, offset, nil
+		default:
+			return nil, 0, fmt.Errorf("invalid wire type %d for field %d", typ, number)
+		}
+		if n < 0 {
+			return nil, 0, protowire.ParseError(n)
+		}
+		offset += n
+		fields = append(fields, field)
+	}
+	if endGroupNumber != 0 {
+		return nil, 0, fmt.Errorf("missing end group for field %d", endGroupNumber)
+	}
+	return fields, offset, nil
+}
+
+// printRawFields prints the fields in the same manner as protoc's TextFormat::Printer
+// prints unknown fields.
+func printRawFields(buffer *bytes.Buffer, fields []*rawField, indent int, recursionBudget int) {
+	prefix := strings.Repeat("  ", indent)
+	for _, field := range fields {
+		buffer.WriteString(prefix)
+		buffer.WriteString(strconv.Itoa(int(field.number)))
+		switch field.typ {
+		case protowire.VarintType:
+			buffer.WriteString(": ")
+			buffer.WriteString(strconv.FormatUint(field.value, 10))
+			buffer.WriteString("\n")
+		case protowire.Fixed32Type:
+			fmt.Fprintf(buffer, ": 0x%08x\n", field.value)
+		case protowire.Fixed64Type:
+			fmt.Fprintf(buffer, ": 0x%016x\n", field.value)
+		case protowire.BytesType:
+			// If the data can be parsed as a message, it is probably an embedded message,
+			// otherwise it is probably a string.
+			if len(field.data) > 0 && recursionBudget > 0 {
+				if embeddedFields, _, err := parseRawFields(field.data, recursionBudget, 0); err == nil {
+					buffer.WriteString(" {\n")
+					printRawFields(buffer, embeddedFields, indent+1, recursionBudget-1)
+					buffer.WriteString(prefix)
+					buffer.WriteString("}\n")
+					continue
+				}
+			}
+			buffer.WriteString(": \"")
+			buffer.WriteString(cEscape(field.data))
+			buffer.WriteString("\"\n")
+		case protowire.StartGroupType:
+			buffer.WriteString(" {\n")
+			printRawFields(buffer, field.group, indent+1, recursionBudget-1)
+			buffer.WriteString(prefix)
+			buffer.WriteString("}\n")
+		}
+	}
+}
+
+// cEscape escapes the data in the same manner as absl::CEscape.
+func cEscape(data []byte) string {
+	var builder strings.Builder
+	for _, c := range data {
+		switch c {
+		case '\n':
+			builder.WriteString(`\n`)
+		case '\r':
+			builder.WriteString(`\r`)
+		case '\t':
+			builder.WriteString(`\t`)
+		case '"':
+			builder.WriteString(`\"`)
+		case '\'':
+			builder.WriteString(`\'`)
+		case '\\':
+			builder.WriteString(`\\`)
+		default:
+			if c < 0x20 || c >= 0x7f {
+				fmt.Fprintf(&builder, `\%03o`, c)
+			} else {
+				builder.WriteByte(c)
+			}
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,99 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protoencoding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestRawMarshaler(t *testing.T) {
+	t.Parallel()
+
+	var embedded []byte
+	embedded = protowire.AppendTag(embedded, 1, protowire.VarintType)
+	embedded = protowire.AppendVarint(embedded, 1)
+
+	var data []byte
+	data = protowire.AppendTag(data, 1, protowire.VarintType)
+	data = protowire.AppendVarint(data, 150)
+	data = protowire.AppendTag(data, 2, protowire.BytesType)
+	data = protowire.AppendString(data, "testing")
+	data = protowire.AppendTag(data, 3, protowire.BytesType)
+	data = protowire.AppendBytes(data, embedded)
+	data = protowire.AppendTag(data, 4, protowire.Fixed32Type)
+	data = protowire.AppendFixed32(data, 1)
+	data = protowire.AppendTag(data, 5, protowire.Fixed64Type)
+	data = protowire.AppendFixed64(data, 1)
+	data = protowire.AppendTag(data, 6, protowire.StartGroupType)
+	data = protowire.AppendTag(data, 1, protowire.VarintType)
+	data = protowire.AppendVarint(data, 2)
+	data = protowire.AppendTag(data, 6, protowire.EndGroupType)
+	data = protowire.AppendTag(data, 7, protowire.BytesType)
+	data = protowire.AppendBytes(data, []byte{0, 1, '\n', '"', 0xff})
+	data = protowire.AppendTag(data, 8, protowire.BytesType)
+	data = protowire.AppendBytes(data, nil)
+	data = protowire.AppendTag(data, 9, protowire.VarintType)
+	data = protowire.AppendVarint(data, 18446744073709551615)
+
+	message := &emptypb.Empty{}
+	require.NoError(t, NewWireUnmarshaler(nil).Unmarshal(data, message))
+	output, err := NewRawMarshaler().Marshal(message)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		`1: 150
+2: "testing"
+3 {
+  1: 1
+}
+4: 0x00000001
+5: 0x0000000000000001
+6 {
+  1: 2
+}
+7: "\000\001\n\"\377"
+8: ""
+9: 18446744073709551615
+`,
+		string(output),
+	)
+}
+
+func TestRawMarshalerInvalid(t *testing.T) {
+	t.Parallel()
+
+	var data []byte
+	data = protowire.AppendTag(data, 1, protowire.EndGroupType)
+	_, err := marshalRaw(data)
+	require.Error(t, err)
+
+	data = nil
+	data = protowire.AppendTag(data, 1, protowire.StartGroupType)
+	data = protowire.AppendTag(data, 2, protowire.VarintType)
+	data = protowire.AppendVarint(data, 1)
+	_, err = marshalRaw(data)
+	require.Error(t, err)
+
+	data = nil
+	data = protowire.AppendTag(data, 1, protowire.BytesType)
+	data = protowire.AppendVarint(data, 10)
+	_, err = marshalRaw(data)
+	require.Error(t, err)
+}
@@ -66,6 +66,21 @@ func MatchPathContained(containingDir string) Matcher {
 	})
 }
 
+// MatchPathGlobEqualOrContained returns a Matcher for the glob pattern that matches
+// on paths matched by the pattern, or contained by a directory matched by the pattern.
+//
+// See normalpath.ValidateGlob for the supported glob syntax. The pattern is compiled once
+// when the Matcher is created. Invalid patterns never match.
+func MatchPathGlobEqualOrContained(pattern string) Matcher {
+	glob, err := normalpath.NewGlob(pattern)
+	if err != nil {
+		return pathMatcherFunc(func(string) bool {
+			return false
+		})
+	}
+	return pathMatcherFunc(glob.EqualsOrContainsPath)
+}
+
 // MatchOr returns an Or of the Matchers.
 func MatchOr(matchers ...Matcher) Matcher {
 	return orMatcher(matchers)
@@ -0,0 +1,70 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wasm
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+type lazyRuntime struct {
+	options []RuntimeOption
+
+	lock sync.Mutex
+	// runtime is nil until the first call to Compile.
+	runtime *runtime
+	closed  bool
+}
+
+func newLazyRuntime(options ...RuntimeOption) *lazyRuntime {
+	return &lazyRuntime{
+		options: options,
+	}
+}
+
+func (l *lazyRuntime) Compile(ctx context.Context, moduleName string, moduleWasm []byte) (CompiledModule, error) {
+	runtime, err := l.getOrCreateRuntime(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return runtime.Compile(ctx, moduleName, moduleWasm)
+}
+
+func (l *lazyRuntime) Close(ctx context.Context) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.closed = true
+	if l.runtime == nil {
+		return nil
+	}
+	return l.runtime.Close(ctx)
+}
+
+func (l *lazyRuntime) getOrCreateRuntime(ctx context.Context) (*runtime, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.closed {
+		return nil, errors.New("Wasm runtime is closed")
+	}
+	if l.runtime == nil {
+		runtime, err := newRuntime(ctx, l.options...)
+		if err != nil {
+			return nil, err
+		}
+		l.runtime = runtime
+	}
+	return l.runtime, nil
+}
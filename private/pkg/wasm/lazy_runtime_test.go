@@ -0,0 +1,48 @@
+// Copyright 2020-2024 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wasm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLazyRuntime(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	// The runtime is not created if Compile is never called.
+	lazyRuntime := newLazyRuntime()
+	require.NoError(t, lazyRuntime.Close(ctx))
+	assert.Nil(t, lazyRuntime.runtime)
+
+	lazyRuntime = newLazyRuntime()
+	_, err := lazyRuntime.Compile(ctx, "invalid", []byte("not wasm"))
+	assert.Error(t, err)
+	assert.NotNil(t, lazyRuntime.runtime)
+	require.NoError(t, lazyRuntime.Close(ctx))
+	_, err = lazyRuntime.Compile(ctx, "invalid", []byte("not wasm"))
+	assert.ErrorContains(t, err, "closed")
+
+	// Errors creating the runtime are returned from Compile.
+	lazyRuntime = newLazyRuntime(WithMaxMemoryBytes(1))
+	_, err = lazyRuntime.Compile(ctx, "invalid", []byte("not wasm"))
+	assert.ErrorContains(t, err, "too small")
+	assert.Nil(t, lazyRuntime.runtime)
+	require.NoError(t, lazyRuntime.Close(ctx))
+}
@@ -54,6 +54,15 @@ func NewRuntime(ctx context.Context, options ...RuntimeOption) (Runtime, error)
 	return newRuntime(ctx, options...)
 }
 
+// NewLazyRuntime creates a new Wasm Runtime that creates the underlying Runtime on the
+// first call to Compile.
+//
+// This avoids the cost of creating a Runtime for commands that only need one for some
+// inputs. If Compile is never called, Close is a no-op.
+func NewLazyRuntime(options ...RuntimeOption) Runtime {
+	return newLazyRuntime(options...)
+}
+
 // RuntimeOption is an option for Runtime.
 type RuntimeOption func(*runtimeOptions)
 